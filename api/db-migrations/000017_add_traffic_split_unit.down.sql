@@ -0,0 +1,2 @@
+-- Remove the request field used for weighted traffic splitting
+ALTER TABLE router_versions DROP COLUMN traffic_split_unit;
@@ -0,0 +1,2 @@
+-- Add the request field used for weighted traffic splitting
+ALTER TABLE router_versions ADD traffic_split_unit jsonb;
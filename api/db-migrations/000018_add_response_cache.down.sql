@@ -0,0 +1,2 @@
+-- Remove the configuration of the router's response cache
+ALTER TABLE router_versions DROP COLUMN response_cache;
@@ -0,0 +1,2 @@
+-- Add the configuration of the router's response cache
+ALTER TABLE router_versions ADD response_cache jsonb;
@@ -0,0 +1,3 @@
+-- Removes builtin_config from table.
+-- Hence, this migration involves data loss for ensemblers with type that is "builtin"
+ALTER TABLE ensembler_configs DROP COLUMN builtin_config;
@@ -0,0 +1,2 @@
+-- Adds builtin_config to table, for the ensemblers that run within the router
+ALTER TABLE ensembler_configs ADD builtin_config jsonb;
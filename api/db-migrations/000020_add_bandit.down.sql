@@ -0,0 +1,2 @@
+-- Remove the configuration of the multi-armed bandit routing strategy
+ALTER TABLE router_versions DROP COLUMN bandit;
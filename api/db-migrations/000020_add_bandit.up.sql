@@ -0,0 +1,2 @@
+-- Add the configuration of the multi-armed bandit routing strategy
+ALTER TABLE router_versions ADD bandit jsonb;
@@ -0,0 +1,2 @@
+-- Remove the ordered pre / post-processing stages of the router
+ALTER TABLE router_versions DROP COLUMN processing_stages;
@@ -0,0 +1,2 @@
+-- Add the ordered pre / post-processing stages of the router
+ALTER TABLE router_versions ADD processing_stages jsonb;
@@ -0,0 +1,2 @@
+-- Remove the authentication of the router's callers
+ALTER TABLE router_versions DROP COLUMN auth;
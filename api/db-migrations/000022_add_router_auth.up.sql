@@ -0,0 +1,2 @@
+-- Add the authentication of the router's callers
+ALTER TABLE router_versions ADD auth jsonb;
@@ -0,0 +1,2 @@
+-- Remove the rate limits of the router's clients
+ALTER TABLE router_versions DROP COLUMN rate_limit;
@@ -0,0 +1,2 @@
+-- Add the rate limits of the router's clients
+ALTER TABLE router_versions ADD rate_limit jsonb;
@@ -0,0 +1,2 @@
+-- Remove the static response, that the router serves when its routes, ensembler or experiment engine fail
+ALTER TABLE router_versions DROP COLUMN fallback_response;
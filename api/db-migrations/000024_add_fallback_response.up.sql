@@ -0,0 +1,2 @@
+-- Add the static response, that the router serves when its routes, ensembler or experiment engine fail
+ALTER TABLE router_versions ADD fallback_response jsonb;
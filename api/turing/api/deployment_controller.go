@@ -195,22 +195,9 @@ func (c RouterDeploymentController) deployRouterVersion(
 	}
 
 	// Retrieve pyfunc ensembler if pyfunc ensembler is specified
-	var pyfuncEnsembler *models.PyFuncEnsembler
-	if routerVersion.Ensembler != nil && routerVersion.Ensembler.Type == models.EnsemblerPyFuncType {
-		ensembler, err := c.EnsemblersService.FindByID(
-			*routerVersion.Ensembler.PyfuncConfig.EnsemblerID,
-			service.EnsemblersFindByIDOptions{
-				ProjectID: routerVersion.Ensembler.PyfuncConfig.ProjectID,
-			})
-		if err != nil {
-			return "", fmt.Errorf("failed to find specified ensembler: %w", err)
-		}
-
-		castedEnsembler, ok := ensembler.(*models.PyFuncEnsembler)
-		if !ok {
-			return "", fmt.Errorf("failed to cast ensembler: %w", err)
-		}
-		pyfuncEnsembler = castedEnsembler
+	pyfuncEnsembler, err := c.getPyFuncEnsembler(routerVersion)
+	if err != nil {
+		return "", err
 	}
 
 	router, err := c.RoutersService.FindByID(routerVersion.RouterID)
@@ -252,6 +239,85 @@ func (c RouterDeploymentController) deployRouterVersion(
 	return endpoint, err
 }
 
+// planRouterVersion renders the cluster resources that deployRouterVersion would apply for the given router
+// version, without deploying it or updating its status. The values of the secrets are redacted.
+func (c RouterDeploymentController) planRouterVersion(
+	project *mlp.Project,
+	router *models.Router,
+	routerVersion *models.RouterVersion,
+) (*service.RouterDeploymentPlan, error) {
+	environment, err := c.MLPService.GetEnvironment(router.EnvironmentName)
+	if err != nil {
+		return nil, err
+	}
+
+	// The secrets are retrieved to ensure that they exist, and are redacted by the deployment service
+	secretMap, err := c.getMLPSecrets(routerVersion, project)
+	if err != nil {
+		return nil, err
+	}
+
+	var experimentConfig json.RawMessage
+	if routerVersion.ExperimentEngine.Type != models.ExperimentEngineTypeNop {
+		// The passkey is not decrypted, since the experiment config is rendered in the router's config
+		experimentConfig, err = c.buildExperimentConfig(routerVersion, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if routerVersion.ExperimentEngine.ServiceAccountKeyFilePath != nil {
+			secretMap[servicebuilder.SecretKeyNameExpEngine] = ""
+		}
+	}
+
+	pyfuncEnsembler, err := c.getPyFuncEnsembler(routerVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var currRouterVersion *models.RouterVersion
+	if router.CurrRouterVersion != nil {
+		currRouterVersion, err = c.RouterVersionsService.FindByID(router.CurrRouterVersion.ID)
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting router version: %w", err)
+		}
+	}
+
+	return c.DeploymentService.PlanRouterVersion(
+		project,
+		environment,
+		currRouterVersion,
+		routerVersion,
+		secretMap,
+		pyfuncEnsembler,
+		experimentConfig,
+	)
+}
+
+// getPyFuncEnsembler retrieves the pyfunc ensembler of the given router version, if it's configured with one
+func (c RouterDeploymentController) getPyFuncEnsembler(
+	routerVersion *models.RouterVersion,
+) (*models.PyFuncEnsembler, error) {
+	if routerVersion.Ensembler == nil || routerVersion.Ensembler.Type != models.EnsemblerPyFuncType {
+		return nil, nil
+	}
+
+	ensembler, err := c.EnsemblersService.FindByID(
+		*routerVersion.Ensembler.PyfuncConfig.EnsemblerID,
+		service.EnsemblersFindByIDOptions{
+			ProjectID: routerVersion.Ensembler.PyfuncConfig.ProjectID,
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find specified ensembler: %w", err)
+	}
+
+	pyfuncEnsembler, ok := ensembler.(*models.PyFuncEnsembler)
+	if !ok {
+		return nil, fmt.Errorf("failed to cast ensembler: %w", err)
+	}
+	return pyfuncEnsembler, nil
+}
+
 func (c RouterDeploymentController) undeployRouter(
 	project *mlp.Project,
 	router *models.Router,
@@ -424,45 +490,53 @@ func (c RouterDeploymentController) updateRouterVersionStatusToFailed(
 }
 
 func (c RouterDeploymentController) getExperimentConfig(routerVersion *models.RouterVersion) (json.RawMessage, error) {
+	experimentConfig, err := c.buildExperimentConfig(routerVersion, true)
+	if err != nil {
+		return nil, c.updateRouterVersionStatusToFailed(err, routerVersion)
+	}
+	return experimentConfig, nil
+}
+
+// buildExperimentConfig returns the deployable router config of the experiment engine. If decryptPasskey
+// is set, the passkey of the experiment engine's client is decrypted.
+func (c RouterDeploymentController) buildExperimentConfig(
+	routerVersion *models.RouterVersion,
+	decryptPasskey bool,
+) (json.RawMessage, error) {
 	var experimentConfig json.RawMessage
 	experimentConfig = routerVersion.ExperimentEngine.Config
 	isClientSelectionEnabled, err := c.BaseController.AppContext.ExperimentsService.IsClientSelectionEnabled(
 		routerVersion.ExperimentEngine.Type,
 	)
 	if err != nil {
-		return nil, c.updateRouterVersionStatusToFailed(err, routerVersion)
+		return nil, err
 	}
-	if isClientSelectionEnabled {
+	if isClientSelectionEnabled && decryptPasskey {
 		// Convert the config to the standard type
 		standardExperimentConfig, err := manager.ParseStandardExperimentConfig(experimentConfig)
 		if err != nil {
-			return nil, c.updateRouterVersionStatusToFailed(err, routerVersion)
+			return nil, err
 		}
 		// If passkey has been set, decrypt it
 		if standardExperimentConfig.Client.Passkey != "" {
 			standardExperimentConfig.Client.Passkey, err =
 				c.CryptoService.Decrypt(standardExperimentConfig.Client.Passkey)
 			if err != nil {
-				return nil, c.updateRouterVersionStatusToFailed(err, routerVersion)
+				return nil, err
 			}
 
 			experimentConfig, err = json.Marshal(standardExperimentConfig)
 			if err != nil {
-				return nil, c.updateRouterVersionStatusToFailed(err, routerVersion)
+				return nil, err
 			}
 		}
 	}
 
 	// Get the deployable Router Config for the experiment
-	experimentConfig, err = c.ExperimentsService.GetExperimentRunnerConfig(
+	return c.ExperimentsService.GetExperimentRunnerConfig(
 		routerVersion.ExperimentEngine.Type,
 		experimentConfig,
 	)
-	if err != nil {
-		return nil, c.updateRouterVersionStatusToFailed(err, routerVersion)
-	}
-
-	return experimentConfig, nil
 }
 
 func (c RouterDeploymentController) getMLPSecrets(
@@ -471,7 +545,7 @@ func (c RouterDeploymentController) getMLPSecrets(
 ) (map[string]string, error) {
 	secretMap := make(map[string]string)
 
-	if routerVersion.LogConfig.ResultLoggerType == models.BigQueryLogger {
+	if routerVersion.LogConfig.HasResultLogger(models.BigQueryLogger) {
 		routerSecrets, err := c.getSecretsForComponent(
 			routerVersion.LogConfig.BigQueryConfig.ServiceAccountSecret,
 			servicebuilder.SecretKeyNameRouter,
@@ -484,6 +558,15 @@ func (c RouterDeploymentController) getMLPSecrets(
 		maps.Copy(secretMap, routerSecrets)
 	}
 
+	if routerVersion.LogConfig.HasHashRedaction() {
+		hashKey, err := c.MLPService.GetSecret(models.ID(project.ID), routerVersion.LogConfig.HashKeySecret)
+		if err != nil {
+			return nil, fmt.Errorf("result log hash key secret %s is not found within %s project: %w",
+				routerVersion.LogConfig.HashKeySecret, project.Name, err)
+		}
+		secretMap[servicebuilder.SecretKeyNameRouterHashKey] = hashKey
+	}
+
 	if routerVersion.Enricher != nil {
 		enricherSecrets, err := c.getSecretsForComponent(
 			routerVersion.Enricher.ServiceAccount,
@@ -523,6 +606,43 @@ func (c RouterDeploymentController) getMLPSecrets(
 		maps.Copy(secretMap, ensemblerSecrets)
 	}
 
+	if routerVersion.Auth != nil {
+		authSecrets, err := c.getAuthSecrets(routerVersion.Auth, project)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(secretMap, authSecrets)
+	}
+
+	return secretMap, nil
+}
+
+// getAuthSecrets retrieves the API keys of the router's callers from MLP, and returns them together with
+// the inline JWKS (if any), as the files that are mounted in the router
+func (c RouterDeploymentController) getAuthSecrets(
+	auth *models.AuthConfig,
+	project *mlp.Project,
+) (map[string]string, error) {
+	secretMap := make(map[string]string)
+	if len(auth.APIKeys) > 0 {
+		apiKeys := make(map[string]string, len(auth.APIKeys))
+		for _, apiKey := range auth.APIKeys {
+			key, err := c.MLPService.GetSecret(models.ID(project.ID), apiKey.MLPSecretName)
+			if err != nil {
+				return nil, fmt.Errorf("API key secret %s is not found within %s project: %w",
+					apiKey.MLPSecretName, project.Name, err)
+			}
+			apiKeys[apiKey.Caller] = key
+		}
+		apiKeysFile, err := json.Marshal(apiKeys)
+		if err != nil {
+			return nil, err
+		}
+		secretMap[servicebuilder.SecretKeyNameRouterAPIKeys] = string(apiKeysFile)
+	}
+	if auth.JWT != nil && len(auth.JWT.JWKS) > 0 {
+		secretMap[servicebuilder.SecretKeyNameRouterJWKS] = string(auth.JWT.JWKS)
+	}
 	return secretMap, nil
 }
 
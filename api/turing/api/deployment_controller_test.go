@@ -20,6 +20,8 @@ import (
 	"github.com/caraml-dev/turing/api/turing/service"
 	"github.com/caraml-dev/turing/api/turing/service/mocks"
 	"github.com/caraml-dev/turing/engines/experiment/manager"
+	expRequest "github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	routerConfig "github.com/caraml-dev/turing/engines/router/missionctl/config"
 )
 
 func TestDeployVersionSuccess(t *testing.T) {
@@ -425,3 +427,60 @@ func TestUndeployRouterSuccess(t *testing.T) {
 	ds.AssertCalled(t, "DeleteRouterEndpoint", project, environment, &models.RouterVersion{Router: router})
 	rs.AssertCalled(t, "Save", modifiedRouter)
 }
+
+func TestGetMLPSecretsAuth(t *testing.T) {
+	project := &mlp.Project{ID: 1, Name: "test-project"}
+	routerVersion := &models.RouterVersion{
+		LogConfig: &models.LogConfig{ResultLoggerType: models.NopLogger},
+		Auth: &models.AuthConfig{
+			APIKeys: []*models.APIKey{
+				{Caller: "team-a", MLPSecretName: "team-a-key"},
+				{Caller: "team-b", MLPSecretName: "team-b-key"},
+			},
+			JWT: &models.JWTAuthConfig{JWKS: json.RawMessage(`{"keys":[{"kty":"RSA"}]}`)},
+		},
+	}
+
+	mlps := &mocks.MLPService{}
+	mlps.On("GetSecret", models.ID(project.ID), "team-a-key").Return("key-a", nil)
+	mlps.On("GetSecret", models.ID(project.ID), "team-b-key").Return("", errors.New("not found"))
+	ctrl := RouterDeploymentController{BaseController{AppContext: &AppContext{MLPService: mlps}}}
+
+	_, err := ctrl.getMLPSecrets(routerVersion, project)
+	assert.EqualError(t, err, "API key secret team-b-key is not found within test-project project: not found")
+
+	routerVersion.Auth.APIKeys = routerVersion.Auth.APIKeys[:1]
+	secretMap, err := ctrl.getMLPSecrets(routerVersion, project)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		servicebuilder.SecretKeyNameRouterAPIKeys: `{"team-a":"key-a"}`,
+		servicebuilder.SecretKeyNameRouterJWKS:    `{"keys":[{"kty":"RSA"}]}`,
+	}, secretMap)
+}
+
+func TestGetMLPSecretsResultLogHashKey(t *testing.T) {
+	project := &mlp.Project{ID: 1, Name: "test-project"}
+	routerVersion := &models.RouterVersion{
+		LogConfig: &models.LogConfig{
+			ResultLoggerType: models.NopLogger,
+			Redactions: []models.ResultLogRedaction{
+				{FieldSource: expRequest.HeaderFieldSource, Field: "X-Email", Action: routerConfig.HashRedactionAction},
+			},
+			HashKeySecret: "hash-key",
+		},
+	}
+
+	mlps := &mocks.MLPService{}
+	mlps.On("GetSecret", models.ID(project.ID), "hash-key").Return("secret", nil)
+	ctrl := RouterDeploymentController{BaseController{AppContext: &AppContext{MLPService: mlps}}}
+
+	secretMap, err := ctrl.getMLPSecrets(routerVersion, project)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{servicebuilder.SecretKeyNameRouterHashKey: "secret"}, secretMap)
+
+	// The secret is not retrieved, if no field is hashed
+	routerVersion.LogConfig.Redactions[0].Action = routerConfig.DropRedactionAction
+	secretMap, err = ctrl.getMLPSecrets(routerVersion, project)
+	require.NoError(t, err)
+	assert.Empty(t, secretMap)
+}
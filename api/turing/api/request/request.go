@@ -22,10 +22,12 @@ type CreateOrUpdateRouterRequest struct {
 
 // RouterConfig defines the properties of the specific router version
 type RouterConfig struct {
-	Routes             models.Routes              `json:"routes" validate:"required"`
+	Routes             models.Routes              `json:"routes" validate:"required,dive"`
 	DefaultRouteID     *string                    `json:"default_route_id"`
 	DefaultTrafficRule *models.DefaultTrafficRule `json:"default_traffic_rule,omitempty"`
 	TrafficRules       models.TrafficRules        `json:"rules" validate:"unique=Name,dive"`
+	TrafficSplitUnit   *models.TrafficSplitUnit   `json:"traffic_split_unit,omitempty" validate:"omitempty"`
+	Bandit             *models.BanditConfig       `json:"bandit,omitempty" validate:"omitempty"`
 	ExperimentEngine   *ExperimentEngineConfig    `json:"experiment_engine" validate:"required,dive"`
 	ResourceRequest    *models.ResourceRequest    `json:"resource_request"`
 	AutoscalingPolicy  *models.AutoscalingPolicy  `json:"autoscaling_policy" validate:"omitempty,dive"`
@@ -34,6 +36,16 @@ type RouterConfig struct {
 
 	LogConfig *LogConfig `json:"log_config" validate:"required"`
 
+	ResponseCache *models.ResponseCacheConfig `json:"response_cache,omitempty" validate:"omitempty"`
+
+	ProcessingStages models.ProcessingStages `json:"processing_stages,omitempty" validate:"unique=Name,dive"`
+
+	Auth *models.AuthConfig `json:"auth,omitempty" validate:"omitempty"`
+
+	RateLimit *models.RateLimitConfig `json:"rate_limit,omitempty" validate:"omitempty"`
+
+	FallbackResponse *models.FallbackResponse `json:"fallback_response,omitempty" validate:"omitempty"`
+
 	Enricher  *EnricherEnsemblerConfig `json:"enricher,omitempty" validate:"omitempty,dive"`
 	Ensembler *models.Ensembler        `json:"ensembler,omitempty" validate:"omitempty,dive"`
 }
@@ -47,8 +59,17 @@ type ExperimentEngineConfig struct {
 // LogConfig defines the logging configs
 type LogConfig struct {
 	ResultLoggerType models.ResultLogger `json:"result_logger_type"`
-	BigQueryConfig   *BigQueryConfig     `json:"bigquery_config,omitempty"`
-	KafkaConfig      *KafkaConfig        `json:"kafka_config,omitempty"`
+	// ResultLoggerTypes log the results to several destinations at once, each with its associated config
+	ResultLoggerTypes    []models.ResultLogger `json:"result_logger_types,omitempty"`
+	BigQueryConfig       *BigQueryConfig       `json:"bigquery_config,omitempty"`
+	KafkaConfig          *KafkaConfig          `json:"kafka_config,omitempty"`
+	DebugResponseEnabled bool                  `json:"debug_response_enabled,omitempty"`
+	// SamplingRate, SamplingUnit and Redactions sample and redact the results before they are logged
+	SamplingRate *float64                      `json:"sampling_rate,omitempty" validate:"omitempty,gt=0,lte=1"`
+	SamplingUnit *models.ResultLogSamplingUnit `json:"sampling_unit,omitempty" validate:"omitempty"`
+	Redactions   []models.ResultLogRedaction   `json:"redactions,omitempty" validate:"dive"`
+	// HashKeySecret is the MLP secret name of the secret key of the hashed fields
+	HashKeySecret string `json:"hash_key_secret,omitempty"`
 }
 
 // BigQueryConfig defines the configs for logging to BQ
@@ -115,6 +136,74 @@ func (r CreateOrUpdateRouterRequest) BuildRouter(projectID models.ID) *models.Ro
 	}
 }
 
+// NewRouterConfig rebuilds the router config from the given router version, so that it can be validated again
+func NewRouterConfig(routerVersion *models.RouterVersion) *RouterConfig {
+	cfg := &RouterConfig{
+		Routes:             routerVersion.Routes,
+		DefaultTrafficRule: routerVersion.DefaultTrafficRule,
+		TrafficRules:       routerVersion.TrafficRules,
+		TrafficSplitUnit:   routerVersion.TrafficSplitUnit,
+		Bandit:             routerVersion.Bandit,
+		ResourceRequest:    routerVersion.ResourceRequest,
+		AutoscalingPolicy:  routerVersion.AutoscalingPolicy,
+		Timeout:            routerVersion.Timeout,
+		Protocol:           &routerVersion.Protocol,
+		ResponseCache:      routerVersion.ResponseCache,
+		ProcessingStages:   routerVersion.ProcessingStages,
+		Auth:               routerVersion.Auth,
+		RateLimit:          routerVersion.RateLimit,
+		FallbackResponse:   routerVersion.FallbackResponse,
+		Ensembler:          routerVersion.Ensembler,
+	}
+	if routerVersion.DefaultRouteID != "" {
+		cfg.DefaultRouteID = &routerVersion.DefaultRouteID
+	}
+	if routerVersion.ExperimentEngine != nil {
+		cfg.ExperimentEngine = &ExperimentEngineConfig{
+			Type:   routerVersion.ExperimentEngine.Type,
+			Config: routerVersion.ExperimentEngine.Config,
+		}
+	}
+	if routerVersion.LogConfig != nil {
+		cfg.LogConfig = &LogConfig{
+			ResultLoggerType:     routerVersion.LogConfig.ResultLoggerType,
+			ResultLoggerTypes:    routerVersion.LogConfig.ResultLoggerTypes,
+			DebugResponseEnabled: routerVersion.LogConfig.DebugResponseEnabled,
+			SamplingRate:         routerVersion.LogConfig.SamplingRate,
+			SamplingUnit:         routerVersion.LogConfig.SamplingUnit,
+			Redactions:           routerVersion.LogConfig.Redactions,
+			HashKeySecret:        routerVersion.LogConfig.HashKeySecret,
+		}
+		if bqConfig := routerVersion.LogConfig.BigQueryConfig; bqConfig != nil {
+			cfg.LogConfig.BigQueryConfig = &BigQueryConfig{
+				Table:                bqConfig.Table,
+				ServiceAccountSecret: bqConfig.ServiceAccountSecret,
+			}
+		}
+		if kafkaConfig := routerVersion.LogConfig.KafkaConfig; kafkaConfig != nil {
+			cfg.LogConfig.KafkaConfig = &KafkaConfig{
+				Brokers:             kafkaConfig.Brokers,
+				Topic:               kafkaConfig.Topic,
+				SerializationFormat: kafkaConfig.SerializationFormat,
+			}
+		}
+	}
+	if enricher := routerVersion.Enricher; enricher != nil {
+		cfg.Enricher = &EnricherEnsemblerConfig{
+			Image:             enricher.Image,
+			ResourceRequest:   enricher.ResourceRequest,
+			AutoscalingPolicy: enricher.AutoscalingPolicy,
+			Endpoint:          enricher.Endpoint,
+			Timeout:           enricher.Timeout,
+			Port:              enricher.Port,
+			Env:               enricher.Env,
+			Secrets:           enricher.Secrets,
+			ServiceAccount:    enricher.ServiceAccount,
+		}
+	}
+	return cfg
+}
+
 // BuildRouterVersion builds the router version model from the entire request payload
 func (r RouterConfig) BuildRouterVersion(
 	projectName string,
@@ -147,6 +236,8 @@ func (r RouterConfig) BuildRouterVersion(
 		DefaultRouteID:     defaultRouteID,
 		DefaultTrafficRule: r.DefaultTrafficRule,
 		TrafficRules:       r.TrafficRules,
+		TrafficSplitUnit:   r.TrafficSplitUnit,
+		Bandit:             r.Bandit,
 		ExperimentEngine: &models.ExperimentEngine{
 			Type: r.ExperimentEngine.Type,
 		},
@@ -154,14 +245,29 @@ func (r RouterConfig) BuildRouterVersion(
 		AutoscalingPolicy: getAutoscalingPolicyOrDefault(r.AutoscalingPolicy),
 		Timeout:           r.Timeout,
 		Protocol:          routerProtocol,
+		ResponseCache:     r.ResponseCache,
+		ProcessingStages:  r.ProcessingStages,
+		Auth:              r.Auth,
+		RateLimit:         r.RateLimit,
+		FallbackResponse:  r.FallbackResponse,
 		LogConfig: &models.LogConfig{
 			LogLevel:             routerConfig.LogLevel(defaults.LogLevel),
 			CustomMetricsEnabled: defaults.CustomMetricsEnabled,
 			FiberDebugLogEnabled: defaults.FiberDebugLogEnabled,
 			JaegerEnabled:        defaults.JaegerEnabled,
 			ResultLoggerType:     r.LogConfig.ResultLoggerType,
+			ResultLoggerTypes:    r.LogConfig.ResultLoggerTypes,
+			DebugResponseEnabled: r.LogConfig.DebugResponseEnabled,
+			SamplingRate:         r.LogConfig.SamplingRate,
+			SamplingUnit:         r.LogConfig.SamplingUnit,
+			Redactions:           r.LogConfig.Redactions,
+			HashKeySecret:        r.LogConfig.HashKeySecret,
 		},
 	}
+	// The single result logger type is kept for the clients, that only know about one result logger
+	if rv.LogConfig.ResultLoggerType == "" && len(rv.LogConfig.ResultLoggerTypes) > 0 {
+		rv.LogConfig.ResultLoggerType = rv.LogConfig.ResultLoggerTypes[0]
+	}
 	if r.Enricher != nil {
 		rv.Enricher = r.Enricher.BuildEnricher()
 	}
@@ -177,6 +283,9 @@ func (r RouterConfig) BuildRouterVersion(
 		if r.Ensembler.Type == models.EnsemblerStandardType && r.Ensembler.StandardConfig == nil {
 			return nil, errors.New("missing ensembler standard config")
 		}
+		if r.Ensembler.Type == models.EnsemblerBuiltinType && r.Ensembler.BuiltinConfig == nil {
+			return nil, errors.New("missing ensembler builtin config")
+		}
 		if r.Ensembler.Type == models.EnsemblerPyFuncType {
 			if r.Ensembler.PyfuncConfig == nil {
 				return nil, errors.New("missing ensembler pyfunc config")
@@ -205,24 +314,26 @@ func (r RouterConfig) BuildRouterVersion(
 		}
 		rv.Ensembler = r.Ensembler
 	}
-	switch rv.LogConfig.ResultLoggerType {
-	case models.BigQueryLogger:
-		rv.LogConfig.BigQueryConfig = &models.BigQueryConfig{
-			Table:                r.LogConfig.BigQueryConfig.Table,
-			ServiceAccountSecret: r.LogConfig.BigQueryConfig.ServiceAccountSecret,
-			BatchLoad:            true, // default for now
-		}
-	case models.KafkaLogger:
-		rv.LogConfig.KafkaConfig = &models.KafkaConfig{
-			Brokers:             r.LogConfig.KafkaConfig.Brokers,
-			Topic:               r.LogConfig.KafkaConfig.Topic,
-			SerializationFormat: r.LogConfig.KafkaConfig.SerializationFormat,
-		}
-	case models.UPILogger:
-		rv.LogConfig.KafkaConfig = &models.KafkaConfig{
-			Brokers:             defaults.UPIConfig.KafkaBrokers,
-			Topic:               fmt.Sprintf("caraml-%s-%s-router-log", projectName, router.Name),
-			SerializationFormat: models.ProtobufSerializationFormat,
+	for _, resultLogger := range rv.LogConfig.ResultLoggers() {
+		switch resultLogger {
+		case models.BigQueryLogger:
+			rv.LogConfig.BigQueryConfig = &models.BigQueryConfig{
+				Table:                r.LogConfig.BigQueryConfig.Table,
+				ServiceAccountSecret: r.LogConfig.BigQueryConfig.ServiceAccountSecret,
+				BatchLoad:            true, // default for now
+			}
+		case models.KafkaLogger:
+			rv.LogConfig.KafkaConfig = &models.KafkaConfig{
+				Brokers:             r.LogConfig.KafkaConfig.Brokers,
+				Topic:               r.LogConfig.KafkaConfig.Topic,
+				SerializationFormat: r.LogConfig.KafkaConfig.SerializationFormat,
+			}
+		case models.UPILogger:
+			rv.LogConfig.KafkaConfig = &models.KafkaConfig{
+				Brokers:             defaults.UPIConfig.KafkaBrokers,
+				Topic:               fmt.Sprintf("caraml-%s-%s-router-log", projectName, router.Name),
+				SerializationFormat: models.ProtobufSerializationFormat,
+			}
 		}
 	}
 	if rv.ExperimentEngine.Type != models.ExperimentEngineTypeNop {
@@ -235,6 +235,11 @@ func TestRequestBuildRouter(t *testing.T) {
 }
 
 func TestRequestBuildRouterVersionLoggerConfiguration(t *testing.T) {
+	samplingRate := 0.1
+	samplingUnit := &models.ResultLogSamplingUnit{FieldSource: request.HeaderFieldSource, Field: "X-User-ID"}
+	redactions := []models.ResultLogRedaction{
+		{FieldSource: request.PayloadFieldSource, Field: "customer.phone", Action: routerConfig.MaskRedactionAction},
+	}
 	baseRequest := CreateOrUpdateRouterRequest{
 		Environment: "env",
 		Name:        "router",
@@ -326,6 +331,61 @@ func TestRequestBuildRouterVersionLoggerConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			testName: "Test Multiple Loggers",
+			logConfig: &LogConfig{
+				ResultLoggerTypes: []models.ResultLogger{"kafka", "bigquery"},
+				KafkaConfig: &KafkaConfig{
+					Brokers:             "10:11",
+					Topic:               "2222",
+					SerializationFormat: "json",
+				},
+				BigQueryConfig: &BigQueryConfig{
+					Table:                "project.dataset.table",
+					ServiceAccountSecret: "service_account",
+				},
+			},
+			expectedLogConfig: &models.LogConfig{
+				ResultLoggerType:  "kafka",
+				ResultLoggerTypes: []models.ResultLogger{"kafka", "bigquery"},
+				KafkaConfig: &models.KafkaConfig{
+					Brokers:             "10:11",
+					Topic:               "2222",
+					SerializationFormat: "json",
+				},
+				BigQueryConfig: &models.BigQueryConfig{
+					Table:                "project.dataset.table",
+					ServiceAccountSecret: "service_account",
+					BatchLoad:            true,
+				},
+			},
+		},
+		{
+			testName: "Test Debug Response Enabled",
+			logConfig: &LogConfig{
+				ResultLoggerType:     "nop",
+				DebugResponseEnabled: true,
+			},
+			expectedLogConfig: &models.LogConfig{
+				ResultLoggerType:     "nop",
+				DebugResponseEnabled: true,
+			},
+		},
+		{
+			testName: "Test Sampling And Redactions",
+			logConfig: &LogConfig{
+				ResultLoggerType: "nop",
+				SamplingRate:     &samplingRate,
+				SamplingUnit:     samplingUnit,
+				Redactions:       redactions,
+			},
+			expectedLogConfig: &models.LogConfig{
+				ResultLoggerType: "nop",
+				SamplingRate:     &samplingRate,
+				SamplingUnit:     samplingUnit,
+				Redactions:       redactions,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.testName, func(t *testing.T) {
@@ -112,6 +112,36 @@ func (c RouterVersionsController) GetRouterVersion(
 	return Ok(routerVersion)
 }
 
+// DiffRouterVersions returns the field-level diff between the configurations of two versions of the router.
+func (c RouterVersionsController) DiffRouterVersions(
+	_ *http.Request,
+	vars RequestVars,
+	_ interface{},
+) *Response {
+	// Parse request vars
+	var (
+		errResp     *Response
+		fromVersion *models.RouterVersion
+	)
+	if fromVersion, errResp = c.getRouterVersionFromRequestVars(vars); errResp != nil {
+		return errResp
+	}
+	otherVersionNum, err := getIntFromVars(vars, "other_version")
+	if err != nil {
+		return BadRequest("invalid router version value", err.Error())
+	}
+	toVersion, err := c.RouterVersionsService.FindByRouterIDAndVersion(fromVersion.RouterID, uint(otherVersionNum))
+	if err != nil {
+		return NotFound("router version not found", err.Error())
+	}
+
+	diff, err := models.DiffRouterVersions(fromVersion, toVersion)
+	if err != nil {
+		return InternalServerError("unable to compare router versions", err.Error())
+	}
+	return Ok(diff)
+}
+
 // DeleteRouterVersion deletes the config for the given version number.
 func (c RouterVersionsController) DeleteRouterVersion(
 	req *http.Request,
@@ -222,6 +252,100 @@ func (c RouterVersionsController) DeployRouterVersion(
 		"version":   int(routerVersion.Version),
 	})
 }
+
+// PlanRouterVersion renders the cluster resources that the deployment of the given router version would
+// apply, without deploying it.
+func (c RouterVersionsController) PlanRouterVersion(
+	_ *http.Request,
+	vars RequestVars,
+	_ interface{},
+) *Response {
+	// Parse request vars
+	var (
+		errResp       *Response
+		project       *mlp.Project
+		router        *models.Router
+		routerVersion *models.RouterVersion
+	)
+
+	if project, errResp = c.getProjectFromRequestVars(vars); errResp != nil {
+		return errResp
+	}
+	if router, errResp = c.getRouterFromRequestVars(vars); errResp != nil {
+		return errResp
+	}
+	if routerVersion, errResp = c.getRouterVersionFromRequestVars(vars); errResp != nil {
+		return errResp
+	}
+
+	// Validate the router version again, as the validation rules may have changed since it was created
+	if err := c.validator.Struct(request.NewRouterConfig(routerVersion)); err != nil {
+		return BadRequest("invalid router version", err.Error())
+	}
+
+	plan, err := c.planRouterVersion(project, router, routerVersion)
+	if err != nil {
+		return InternalServerError("unable to plan router version deployment", err.Error())
+	}
+	return Ok(plan)
+}
+
+// PlanRouterConfig renders the cluster resources that the deployment of a new version of the router, with the
+// provided configuration, would apply. The router version is neither created nor deployed.
+func (c RouterVersionsController) PlanRouterConfig(
+	_ *http.Request,
+	vars RequestVars,
+	body interface{},
+) *Response {
+	// Parse request vars
+	var (
+		errResp *Response
+		router  *models.Router
+		project *mlp.Project
+	)
+
+	if project, errResp = c.getProjectFromRequestVars(vars); errResp != nil {
+		return errResp
+	}
+	if router, errResp = c.getRouterFromRequestVars(vars); errResp != nil {
+		return errResp
+	}
+
+	request := body.(*request.RouterConfig)
+	if request == nil {
+		return InternalServerError("unable to plan router version deployment", "router config is empty")
+	}
+
+	routerVersion, err := request.BuildRouterVersion(
+		project.Name,
+		router,
+		c.RouterDefaults,
+		c.AppContext.CryptoService,
+		c.AppContext.ExperimentsService,
+		c.EnsemblersService)
+	if err != nil {
+		return InternalServerError("unable to plan router version deployment", err.Error())
+	}
+
+	// Use the version number that the router version would be created with
+	routerVersions, err := c.RouterVersionsService.ListRouterVersions(router.ID)
+	if err != nil {
+		return InternalServerError("unable to retrieve router versions", err.Error())
+	}
+	routerVersion.Version = 1
+	for _, version := range routerVersions {
+		if version.Version >= routerVersion.Version {
+			routerVersion.Version = version.Version + 1
+		}
+	}
+
+	plan, err := c.planRouterVersion(project, router, routerVersion)
+	if err != nil {
+		return InternalServerError("unable to plan router version deployment", err.Error())
+	}
+	return Ok(plan)
+}
+
 func (c RouterVersionsController) ListRouterVersionsWithFilter(
 	_ *http.Request,
 	vars RequestVars,
@@ -264,11 +388,27 @@ func (c RouterVersionsController) Routes() []Route {
 			path:    "/projects/{project_id}/routers/{router_id}/versions/{version}",
 			handler: c.DeleteRouterVersion,
 		},
+		{
+			method:  http.MethodGet,
+			path:    "/projects/{project_id}/routers/{router_id}/versions/{version}/diff/{other_version}",
+			handler: c.DiffRouterVersions,
+		},
 		{
 			method:  http.MethodPost,
 			path:    "/projects/{project_id}/routers/{router_id}/versions/{version}/deploy",
 			handler: c.DeployRouterVersion,
 		},
+		{
+			method:  http.MethodPost,
+			path:    "/projects/{project_id}/routers/{router_id}/versions/{version}/plan",
+			handler: c.PlanRouterVersion,
+		},
+		{
+			method:  http.MethodPost,
+			path:    "/projects/{project_id}/routers/{router_id}/versions/plan",
+			body:    request.RouterConfig{},
+			handler: c.PlanRouterConfig,
+		},
 		{
 			method:  http.MethodGet,
 			path:    "/projects/{project_id}/router-versions",
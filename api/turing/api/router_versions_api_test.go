@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"testing"
@@ -14,7 +15,9 @@ import (
 	"github.com/caraml-dev/turing/api/turing/api/request"
 	"github.com/caraml-dev/turing/api/turing/config"
 	"github.com/caraml-dev/turing/api/turing/models"
+	"github.com/caraml-dev/turing/api/turing/service"
 	"github.com/caraml-dev/turing/api/turing/service/mocks"
+	"github.com/caraml-dev/turing/api/turing/validation"
 	"github.com/caraml-dev/turing/api/turing/webhook"
 	webhookMock "github.com/caraml-dev/turing/api/turing/webhook/mocks"
 	routerConfig "github.com/caraml-dev/turing/engines/router/missionctl/config"
@@ -271,6 +274,69 @@ func TestGetRouterVersion(t *testing.T) {
 	}
 }
 
+func TestDiffRouterVersions(t *testing.T) {
+	// Create mock services
+	version1 := &models.RouterVersion{RouterID: 1, Version: 1, Timeout: "10ms"}
+	version2 := &models.RouterVersion{RouterID: 1, Version: 2, Timeout: "20ms"}
+	routerVersionSvc := &mocks.RouterVersionsService{}
+	routerVersionSvc.
+		On("FindByRouterIDAndVersion", models.ID(1), uint(1)).
+		Return(version1, nil)
+	routerVersionSvc.
+		On("FindByRouterIDAndVersion", models.ID(1), uint(2)).
+		Return(version2, nil)
+	routerVersionSvc.
+		On("FindByRouterIDAndVersion", models.ID(1), uint(3)).
+		Return(nil, errors.New("test router version error"))
+
+	// Define tests
+	tests := map[string]struct {
+		vars     RequestVars
+		expected *Response
+	}{
+		"failure | bad request (missing version)": {
+			vars:     RequestVars{"router_id": {"1"}, "project_id": {"1"}},
+			expected: BadRequest("invalid router version value", "key version not found in vars"),
+		},
+		"failure | bad request (missing other version)": {
+			vars:     RequestVars{"router_id": {"1"}, "version": {"1"}, "project_id": {"1"}},
+			expected: BadRequest("invalid router version value", "key other_version not found in vars"),
+		},
+		"failure | get other router version": {
+			vars:     RequestVars{"router_id": {"1"}, "version": {"1"}, "other_version": {"3"}, "project_id": {"1"}},
+			expected: NotFound("router version not found", "test router version error"),
+		},
+		"success": {
+			vars: RequestVars{"router_id": {"1"}, "version": {"1"}, "other_version": {"2"}, "project_id": {"1"}},
+			expected: Ok(&models.RouterVersionDiff{
+				From: 1,
+				To:   2,
+				Changes: []models.FieldChange{
+					{Path: "timeout", Type: models.FieldModified, From: "10ms", To: "20ms"},
+				},
+			}),
+		},
+	}
+
+	// Run tests
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := &RouterVersionsController{
+				RouterDeploymentController{
+					BaseController{
+						AppContext: &AppContext{
+							RouterVersionsService: routerVersionSvc,
+						},
+					},
+				},
+			}
+			// Run test method and validate
+			response := ctrl.DiffRouterVersions(nil, data.vars, nil)
+			assert.Equal(t, data.expected, response)
+		})
+	}
+}
+
 func TestDeleteRouterVersion(t *testing.T) {
 	// Create mock services
 	// Router versions service
@@ -614,3 +680,221 @@ func TestDeployRouterVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestPlanRouterVersion(t *testing.T) {
+	// Create mock services
+	// MLP service
+	mlpSvc := &mocks.MLPService{}
+	mlpSvc.On("GetProject", models.ID(2)).Return(&mlp.Project{ID: 2, Name: "project"}, nil)
+	mlpSvc.On("GetEnvironment", "dev").Return(&merlin.Environment{Name: "dev"}, nil)
+
+	// Router Service
+	router2 := &models.Router{
+		Name:            "router2",
+		ProjectID:       2,
+		EnvironmentName: "dev",
+		Model: models.Model{
+			ID: 2,
+		},
+	}
+	routerSvc := &mocks.RoutersService{}
+	routerSvc.On("FindByID", models.ID(2)).Return(router2, nil)
+
+	// Router Version Service
+	routeID := "route-a"
+	routerVersion := &models.RouterVersion{
+		Router:   router2,
+		RouterID: 2,
+		Version:  1,
+		Routes: models.Routes{
+			{
+				ID:       routeID,
+				Type:     "PROXY",
+				Endpoint: "http://example.com/a",
+				Timeout:  "10ms",
+			},
+		},
+		DefaultRouteID: routeID,
+		ExperimentEngine: &models.ExperimentEngine{
+			Type: models.ExperimentEngineTypeNop,
+		},
+		Timeout:  "20s",
+		Protocol: routerConfig.HTTP,
+		LogConfig: &models.LogConfig{
+			ResultLoggerType: models.NopLogger,
+		},
+	}
+	invalidRouterVersion := &models.RouterVersion{
+		Router:         router2,
+		RouterID:       2,
+		Version:        2,
+		Routes:         routerVersion.Routes,
+		DefaultRouteID: routeID,
+		ExperimentEngine: &models.ExperimentEngine{
+			Type: models.ExperimentEngineTypeNop,
+		},
+		Protocol:  routerConfig.HTTP,
+		LogConfig: routerVersion.LogConfig,
+	}
+	routerVersionSvc := &mocks.RouterVersionsService{}
+	routerVersionSvc.On("FindByRouterIDAndVersion", models.ID(2), uint(1)).Return(routerVersion, nil)
+	routerVersionSvc.On("FindByRouterIDAndVersion", models.ID(2), uint(2)).Return(invalidRouterVersion, nil)
+	routerVersionSvc.
+		On("FindByRouterIDAndVersion", models.ID(2), uint(3)).
+		Return(nil, errors.New("test router version error"))
+
+	// Deployment Service
+	plan := &service.RouterDeploymentPlan{}
+	deploymentSvc := &mocks.DeploymentService{}
+	deploymentSvc.
+		On("PlanRouterVersion", &mlp.Project{ID: 2, Name: "project"}, &merlin.Environment{Name: "dev"},
+			(*models.RouterVersion)(nil), routerVersion, map[string]string{}, (*models.PyFuncEnsembler)(nil),
+			json.RawMessage(nil)).
+		Return(plan, nil)
+
+	// Define tests
+	tests := map[string]struct {
+		vars     RequestVars
+		expected *Response
+	}{
+		"failure | router version not found": {
+			vars:     RequestVars{"project_id": {"2"}, "router_id": {"2"}, "version": {"3"}},
+			expected: NotFound("router version not found", "test router version error"),
+		},
+		"failure | invalid router version": {
+			vars: RequestVars{"project_id": {"2"}, "router_id": {"2"}, "version": {"2"}},
+			expected: BadRequest("invalid router version",
+				"Key: 'RouterConfig.Timeout' Error:Field validation for 'Timeout' failed on the 'required' tag"),
+		},
+		"success": {
+			vars:     RequestVars{"project_id": {"2"}, "router_id": {"2"}, "version": {"1"}},
+			expected: Ok(plan),
+		},
+	}
+
+	// Run tests
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			validator, _ := validation.NewValidator(nil)
+			ctrl := &RouterVersionsController{
+				RouterDeploymentController{
+					BaseController{
+						AppContext: &AppContext{
+							MLPService:            mlpSvc,
+							RoutersService:        routerSvc,
+							RouterVersionsService: routerVersionSvc,
+							DeploymentService:     deploymentSvc,
+							RouterDefaults:        &config.RouterDefaults{},
+						},
+						validator: validator,
+					},
+				},
+			}
+			// Run test method and validate
+			response := ctrl.PlanRouterVersion(&http.Request{}, data.vars, nil)
+			assert.Equal(t, data.expected, response)
+		})
+	}
+}
+
+func TestPlanRouterConfig(t *testing.T) {
+	// Create mock services
+	// MLP service
+	mlpSvc := &mocks.MLPService{}
+	mlpSvc.On("GetProject", models.ID(2)).Return(&mlp.Project{ID: 2}, nil)
+	mlpSvc.On("GetEnvironment", "dev").Return(&merlin.Environment{}, nil)
+
+	// Router Service
+	router2 := &models.Router{
+		Name:            "router2",
+		ProjectID:       2,
+		EnvironmentName: "dev",
+		Model: models.Model{
+			ID: 2,
+		},
+	}
+	router3 := &models.Router{
+		Name:            "router3",
+		ProjectID:       2,
+		EnvironmentName: "dev",
+		Model: models.Model{
+			ID: 3,
+		},
+	}
+	routerSvc := &mocks.RoutersService{}
+	routerSvc.On("FindByID", models.ID(2)).Return(router2, nil)
+	routerSvc.On("FindByID", models.ID(3)).Return(router3, nil)
+
+	// Router Version Service
+	routerVersionSvc := &mocks.RouterVersionsService{}
+	routerVersionSvc.
+		On("ListRouterVersions", models.ID(2)).
+		Return([]*models.RouterVersion{{Version: 1}, {Version: 3}, {Version: 2}}, nil)
+	routerVersionSvc.
+		On("ListRouterVersions", models.ID(3)).
+		Return(nil, errors.New("test router versions error"))
+
+	// Deployment Service, that is expected to plan a new version of the router
+	plan := &service.RouterDeploymentPlan{}
+	deploymentSvc := &mocks.DeploymentService{}
+	deploymentSvc.
+		On("PlanRouterVersion", mock.Anything, mock.Anything, mock.Anything,
+			mock.MatchedBy(func(rv *models.RouterVersion) bool {
+				return rv.RouterID == 2 && rv.Version == 4
+			}),
+			mock.Anything, mock.Anything, mock.Anything).
+		Return(plan, nil)
+
+	routerConfigBody := &request.RouterConfig{
+		ExperimentEngine: &request.ExperimentEngineConfig{
+			Type: "nop",
+		},
+		LogConfig: &request.LogConfig{
+			ResultLoggerType: models.NopLogger,
+		},
+	}
+
+	// Define tests
+	tests := map[string]struct {
+		vars     RequestVars
+		body     *request.RouterConfig
+		expected *Response
+	}{
+		"failure | build router version": {
+			vars:     RequestVars{"project_id": {"2"}, "router_id": {"2"}},
+			expected: InternalServerError("unable to plan router version deployment", "router config is empty"),
+		},
+		"failure | list router versions": {
+			vars:     RequestVars{"project_id": {"2"}, "router_id": {"3"}},
+			body:     routerConfigBody,
+			expected: InternalServerError("unable to retrieve router versions", "test router versions error"),
+		},
+		"success": {
+			vars:     RequestVars{"project_id": {"2"}, "router_id": {"2"}},
+			body:     routerConfigBody,
+			expected: Ok(plan),
+		},
+	}
+
+	// Run tests
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := &RouterVersionsController{
+				RouterDeploymentController{
+					BaseController{
+						AppContext: &AppContext{
+							MLPService:            mlpSvc,
+							RoutersService:        routerSvc,
+							RouterVersionsService: routerVersionSvc,
+							DeploymentService:     deploymentSvc,
+							RouterDefaults:        &config.RouterDefaults{},
+						},
+					},
+				},
+			}
+			// Run test method and validate
+			response := ctrl.PlanRouterConfig(&http.Request{}, data.vars, data.body)
+			assert.Equal(t, data.expected, response)
+		})
+	}
+}
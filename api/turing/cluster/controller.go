@@ -22,7 +22,6 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	policyv1cfg "k8s.io/client-go/applyconfigurations/policy/v1"
 	"k8s.io/client-go/kubernetes"
 	appsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
 	batchv1 "k8s.io/client-go/kubernetes/typed/batch/v1"
@@ -224,23 +223,14 @@ func (c *controller) CreateNamespace(ctx context.Context, name string) error {
 // If the config map already exists, ApplyConfigMap will update the configuration with the given
 // data.
 func (c *controller) ApplyConfigMap(ctx context.Context, namespace string, configMap *ConfigMap) error {
-	cm := apicorev1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      configMap.Name,
-			Namespace: namespace,
-			Labels:    configMap.Labels,
-		},
-		Data: map[string]string{
-			configMap.FileName: configMap.Data,
-		},
-	}
+	cm := configMap.BuildConfigMap(namespace)
 	_, err := c.k8sCoreClient.ConfigMaps(namespace).Get(ctx, cm.Name, metav1.GetOptions{})
 	if err == nil {
 		// exists, we update instead
-		_, err = c.k8sCoreClient.ConfigMaps(namespace).Update(ctx, &cm, metav1.UpdateOptions{})
+		_, err = c.k8sCoreClient.ConfigMaps(namespace).Update(ctx, cm, metav1.UpdateOptions{})
 		return err
 	}
-	_, err = c.k8sCoreClient.ConfigMaps(namespace).Create(ctx, &cm, metav1.CreateOptions{})
+	_, err = c.k8sCoreClient.ConfigMaps(namespace).Create(ctx, cm, metav1.CreateOptions{})
 	return err
 }
 
@@ -683,15 +673,11 @@ func (c *controller) ApplyPodDisruptionBudget(
 	ctx context.Context,
 	pdb PodDisruptionBudget,
 ) (*apipolicyv1.PodDisruptionBudget, error) {
-	pdbSpec, err := pdb.BuildPDBSpec()
+	pdbCfg, err := pdb.BuildPDB()
 	if err != nil {
 		return nil, err
 	}
 
-	pdbCfg := policyv1cfg.PodDisruptionBudget(pdb.Name, pdb.Namespace)
-	pdbCfg.WithLabels(pdb.Labels)
-	pdbCfg.WithSpec(pdbSpec)
-
 	pdbObj, err := c.k8sPolicyClient.PodDisruptionBudgets(pdb.Namespace).Apply(
 		ctx,
 		pdbCfg,
@@ -5,6 +5,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -141,6 +142,20 @@ type ConfigMap struct {
 	Labels   map[string]string `json:"labels"`
 }
 
+// BuildConfigMap builds a kubernetes config map in the given namespace, from the given config.
+func (cfg *ConfigMap) BuildConfigMap(namespace string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cfg.Name,
+			Namespace: namespace,
+			Labels:    cfg.Labels,
+		},
+		Data: map[string]string{
+			cfg.FileName: cfg.Data,
+		},
+	}
+}
+
 // Ref:
 // https://github.com/knative/serving/blob/release-0.14/pkg/reconciler/revision/resources/queue.go#L115
 func ComputeResource(resourceQuantity resource.Quantity, fraction float64) resource.Quantity {
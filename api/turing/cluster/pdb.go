@@ -43,3 +43,16 @@ func (cfg PodDisruptionBudget) BuildPDBSpec() (*policyv1cfg.PodDisruptionBudgetS
 
 	return pdbSpec, nil
 }
+
+// BuildPDB builds the apply configuration of a kubernetes pod disruption budget from the given config.
+func (cfg PodDisruptionBudget) BuildPDB() (*policyv1cfg.PodDisruptionBudgetApplyConfiguration, error) {
+	pdbSpec, err := cfg.BuildPDBSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	pdbCfg := policyv1cfg.PodDisruptionBudget(cfg.Name, cfg.Namespace)
+	pdbCfg.WithLabels(cfg.Labels)
+	pdbCfg.WithSpec(pdbSpec)
+	return pdbCfg, nil
+}
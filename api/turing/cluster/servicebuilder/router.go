@@ -19,6 +19,7 @@ import (
 	"github.com/caraml-dev/turing/api/turing/models"
 	"github.com/caraml-dev/turing/api/turing/utils"
 	"github.com/caraml-dev/turing/engines/router"
+	routerBandit "github.com/caraml-dev/turing/engines/router/missionctl/bandit"
 	routeConfig "github.com/caraml-dev/turing/engines/router/missionctl/config"
 	"github.com/caraml-dev/turing/engines/router/missionctl/fiberapi"
 )
@@ -40,6 +41,8 @@ const (
 	envSentryEnabled                   = "APP_SENTRY_ENABLED"
 	envSentryDSN                       = "APP_SENTRY_DSN"
 	envResultLogger                    = "APP_RESULT_LOGGER"
+	envResultLoggers                   = "APP_RESULT_LOGGERS"
+	envResultLogPolicy                 = "APP_RESULT_LOG_POLICY"
 	envGcpProject                      = "APP_GCP_PROJECT"
 	envBQDataset                       = "APP_BQ_DATASET"
 	envBQTable                         = "APP_BQ_TABLE"
@@ -54,6 +57,28 @@ const (
 	envKafkaCompressionType            = "APP_KAFKA_COMPRESSION_TYPE"
 	envRouterConfigFile                = "ROUTER_CONFIG_FILE"
 	envRouterProtocol                  = "ROUTER_PROTOCOL"
+	envRouterCacheEnabled              = "ROUTER_CACHE_ENABLED"
+	envRouterCacheMaxEntries           = "ROUTER_CACHE_MAX_ENTRIES"
+	envRouterCacheTTL                  = "ROUTER_CACHE_TTL"
+	envRouterCacheKeyFields            = "ROUTER_CACHE_KEY_FIELDS"
+	envRouterDebugResponseEnabled      = "ROUTER_DEBUG_RESPONSE_ENABLED"
+	envRouterProcessingStages          = "PROCESSING_STAGES"
+	envRouterAuthAPIKeysFile           = "ROUTER_AUTH_API_KEYS_FILE"
+	envRouterAuthJWKSFile              = "ROUTER_AUTH_JWT_JWKS_FILE"
+	envRouterAuthJWKSURL               = "ROUTER_AUTH_JWT_JWKS_URL"
+	envRouterAuthIssuer                = "ROUTER_AUTH_JWT_ISSUER"
+	envRouterAuthAudience              = "ROUTER_AUTH_JWT_AUDIENCE"
+	envRouterAuthRequiredClaims        = "ROUTER_AUTH_JWT_REQUIRED_CLAIMS"
+	envRouterAuthIdentityClaim         = "ROUTER_AUTH_JWT_IDENTITY_CLAIM"
+	envRouterRateLimitFieldSource      = "ROUTER_RATE_LIMIT_FIELD_SOURCE"
+	envRouterRateLimitField            = "ROUTER_RATE_LIMIT_FIELD"
+	envRouterRateLimitRequestsPerSec   = "ROUTER_RATE_LIMIT_REQUESTS_PER_SEC"
+	envRouterRateLimitBurst            = "ROUTER_RATE_LIMIT_BURST"
+	envRouterRateLimitMaxInFlight      = "ROUTER_RATE_LIMIT_MAX_IN_FLIGHT"
+	envRouterRateLimitMetricKeys       = "ROUTER_RATE_LIMIT_METRIC_KEYS"
+	envRouterFallbackResponses         = "ROUTER_FALLBACK_RESPONSES"
+	envRouterBanditSnapshotFile        = "ROUTER_BANDIT_SNAPSHOT_FILE"
+	envRouterBanditSnapshotInterval    = "ROUTER_BANDIT_SNAPSHOT_INTERVAL"
 	envGoogleApplicationCredentials    = "GOOGLE_APPLICATION_CREDENTIALS"
 	envExpGoogleApplicationCredentials = "GOOGLE_APPLICATION_CREDENTIALS_EXPERIMENT_ENGINE"
 	envPluginName                      = "PLUGIN_NAME"
@@ -74,8 +99,14 @@ const (
 	routerConfigStrategyTypeDefault          = "fiber.DefaultTuringRoutingStrategy"
 	routerConfigStrategyTypeFanIn            = "fiber.EnsemblingFanIn"
 	routerConfigStrategyTypeTrafficSplitting = "fiber.TrafficSplittingStrategy"
+	routerConfigStrategyTypeWeightedSplit    = "fiber.WeightedTrafficSplittingStrategy"
+	routerConfigStrategyTypeShadow           = "fiber.ShadowRoutingStrategy"
+	routerConfigStrategyTypeBandit           = "fiber.BanditRoutingStrategy"
 
 	routerPluginBinaryConfigKey = "plugin_binary"
+	// ID of the fiber component serving the primary (i.e. not shadow) routes,
+	// when the router has shadow routes
+	shadowPrimaryRouteID = "shadow-primary"
 )
 
 // Router endpoint constants
@@ -91,6 +122,13 @@ const (
 	pluginsVolumeName = "plugins-volume"
 )
 
+// Bandit volume constants
+const (
+	banditMountPath        = "/app/bandit/"
+	banditVolumeName       = "bandit-volume"
+	banditSnapshotFileName = "snapshot.json"
+)
+
 var defaultMatchURIPrefixes = []string{"/v1/predict", "/v1/batch_predict"}
 
 // NewRouterService creates a new cluster Service object with the required config
@@ -254,6 +292,78 @@ func (sb *clusterSvcBuilder) buildRouterEnvs(
 		})
 	}
 
+	// Add response cache config, if enabled
+	if ver.ResponseCache != nil {
+		keyFields, err := json.Marshal(ver.ResponseCache.KeyFields)
+		if err != nil {
+			return envs, err
+		}
+		envs = mergeEnvVars(envs, []corev1.EnvVar{
+			{Name: envRouterCacheEnabled, Value: strconv.FormatBool(true)},
+			{Name: envRouterCacheMaxEntries, Value: strconv.Itoa(ver.ResponseCache.MaxEntries)},
+			{Name: envRouterCacheTTL, Value: ver.ResponseCache.TTL},
+			{Name: envRouterCacheKeyFields, Value: string(keyFields)},
+		})
+	}
+
+	// Add the pre / post-processing stages, if any
+	if len(ver.ProcessingStages) > 0 {
+		stages, err := json.Marshal(ver.ProcessingStages)
+		if err != nil {
+			return envs, err
+		}
+		envs = mergeEnvVars(envs, []corev1.EnvVar{
+			{Name: envRouterProcessingStages, Value: string(stages)},
+		})
+	}
+
+	// Add the authentication of the router's callers, if enabled
+	if ver.Auth != nil {
+		authEnvs, err := buildRouterAuthEnvs(ver.Auth)
+		if err != nil {
+			return envs, err
+		}
+		envs = mergeEnvVars(envs, authEnvs)
+	}
+
+	// Add the rate limits of the router's clients, if any
+	if ver.RateLimit != nil {
+		envs = mergeEnvVars(envs, []corev1.EnvVar{
+			{Name: envRouterRateLimitFieldSource, Value: string(ver.RateLimit.FieldSource)},
+			{Name: envRouterRateLimitField, Value: ver.RateLimit.Field},
+			{
+				Name:  envRouterRateLimitRequestsPerSec,
+				Value: strconv.FormatFloat(ver.RateLimit.RequestsPerSecond, 'f', -1, 64),
+			},
+			{Name: envRouterRateLimitBurst, Value: strconv.Itoa(ver.RateLimit.Burst)},
+			{Name: envRouterRateLimitMaxInFlight, Value: strconv.Itoa(ver.RateLimit.MaxInFlight)},
+		})
+		if len(ver.RateLimit.MetricKeys) > 0 {
+			envs = mergeEnvVars(envs, []corev1.EnvVar{
+				{Name: envRouterRateLimitMetricKeys, Value: strings.Join(ver.RateLimit.MetricKeys, ",")},
+			})
+		}
+	}
+
+	// Save the bandit state to the router's volume, if enabled
+	if ver.Bandit != nil && ver.Bandit.SnapshotInterval != "" {
+		envs = mergeEnvVars(envs, []corev1.EnvVar{
+			{Name: envRouterBanditSnapshotFile, Value: banditMountPath + banditSnapshotFileName},
+			{Name: envRouterBanditSnapshotInterval, Value: ver.Bandit.SnapshotInterval},
+		})
+	}
+
+	// Add the fallback responses of the router and its traffic rules, if any
+	if fallbackCfg := buildRouterFallbackConfig(ver); fallbackCfg != nil {
+		fallbackResponses, err := json.Marshal(fallbackCfg)
+		if err != nil {
+			return envs, err
+		}
+		envs = mergeEnvVars(envs, []corev1.EnvVar{
+			{Name: envRouterFallbackResponses, Value: string(fallbackResponses)},
+		})
+	}
+
 	// Process Log config
 	logConfig := ver.LogConfig
 	envs = mergeEnvVars(envs, []corev1.EnvVar{
@@ -263,43 +373,160 @@ func (sb *clusterSvcBuilder) buildRouterEnvs(
 		{Name: envResultLogger, Value: string(logConfig.ResultLoggerType)},
 		{Name: envFiberDebugLog, Value: strconv.FormatBool(logConfig.FiberDebugLogEnabled)},
 	})
-
-	// Add BQ config
-	switch logConfig.ResultLoggerType {
-	case models.BigQueryLogger:
-		if logConfig.BigQueryConfig == nil {
-			return envs, errors.New("Missing BigQuery logger config")
+	// Log the results to several destinations at once, if configured
+	if len(logConfig.ResultLoggerTypes) > 0 {
+		resultLoggers := make([]string, len(logConfig.ResultLoggerTypes))
+		for idx, resultLogger := range logConfig.ResultLoggerTypes {
+			resultLoggers[idx] = string(resultLogger)
 		}
-		bqFQN := strings.Split(logConfig.BigQueryConfig.Table, ".")
-		if len(bqFQN) != 3 {
-			return envs, fmt.Errorf("Invalid BigQuery table name %s",
-				logConfig.BigQueryConfig.Table)
+		envs = mergeEnvVars(envs, []corev1.EnvVar{
+			{Name: envResultLoggers, Value: strings.Join(resultLoggers, ",")},
+		})
+	}
+	// Sample and redact the results before they are logged, if configured
+	if logPolicy := buildResultLogPolicy(logConfig); logPolicy != nil {
+		resultLogPolicy, err := json.Marshal(logPolicy)
+		if err != nil {
+			return envs, err
 		}
 		envs = mergeEnvVars(envs, []corev1.EnvVar{
-			{Name: envGcpProject, Value: bqFQN[0]},
-			{Name: envBQDataset, Value: bqFQN[1]},
-			{Name: envBQTable, Value: bqFQN[2]},
-			{Name: envBQBatchLoad, Value: strconv.FormatBool(logConfig.BigQueryConfig.BatchLoad)},
-			{Name: envGoogleApplicationCredentials, Value: secretMountPathRouter + SecretKeyNameRouter},
+			{Name: envResultLogPolicy, Value: string(resultLogPolicy)},
+		})
+	}
+	// Allow the clients to request the debug trace of a request, if enabled
+	if logConfig.DebugResponseEnabled {
+		envs = mergeEnvVars(envs, []corev1.EnvVar{
+			{Name: envRouterDebugResponseEnabled, Value: strconv.FormatBool(true)},
 		})
-		if logConfig.BigQueryConfig.BatchLoad {
+	}
+
+	// Add the config of each result logger
+	for _, resultLogger := range logConfig.ResultLoggers() {
+		switch resultLogger {
+		case models.BigQueryLogger:
+			if logConfig.BigQueryConfig == nil {
+				return envs, errors.New("Missing BigQuery logger config")
+			}
+			bqFQN := strings.Split(logConfig.BigQueryConfig.Table, ".")
+			if len(bqFQN) != 3 {
+				return envs, fmt.Errorf("Invalid BigQuery table name %s",
+					logConfig.BigQueryConfig.Table)
+			}
 			envs = mergeEnvVars(envs, []corev1.EnvVar{
-				{Name: envFluentdHost, Value: buildFluentdHost(ver, namespace)},
-				{Name: envFluentdPort, Value: strconv.Itoa(fluentdPort)},
-				{Name: envFluentdTag, Value: routerDefaults.FluentdConfig.Tag},
+				{Name: envGcpProject, Value: bqFQN[0]},
+				{Name: envBQDataset, Value: bqFQN[1]},
+				{Name: envBQTable, Value: bqFQN[2]},
+				{Name: envBQBatchLoad, Value: strconv.FormatBool(logConfig.BigQueryConfig.BatchLoad)},
+				{Name: envGoogleApplicationCredentials, Value: secretMountPathRouter + SecretKeyNameRouter},
+			})
+			if logConfig.BigQueryConfig.BatchLoad {
+				envs = mergeEnvVars(envs, []corev1.EnvVar{
+					{Name: envFluentdHost, Value: buildFluentdHost(ver, namespace)},
+					{Name: envFluentdPort, Value: strconv.Itoa(fluentdPort)},
+					{Name: envFluentdTag, Value: routerDefaults.FluentdConfig.Tag},
+				})
+			}
+		case models.KafkaLogger, models.UPILogger:
+			// UPILogger's kafka details are created in BuildRouterVersion so that information are persisted in DB
+			envs = mergeEnvVars(envs, []corev1.EnvVar{
+				{Name: envKafkaBrokers, Value: logConfig.KafkaConfig.Brokers},
+				{Name: envKafkaTopic, Value: logConfig.KafkaConfig.Topic},
+				{Name: envKafkaSerializationFormat, Value: string(logConfig.KafkaConfig.SerializationFormat)},
+				{Name: envKafkaMaxMessageBytes, Value: strconv.Itoa(routerDefaults.KafkaConfig.MaxMessageBytes)},
+				{Name: envKafkaCompressionType, Value: routerDefaults.KafkaConfig.CompressionType},
 			})
 		}
-	case models.KafkaLogger, models.UPILogger:
-		// UPILogger's kafka details are created in BuildRouterVersion so that information are persisted in DB
-		envs = mergeEnvVars(envs, []corev1.EnvVar{
-			{Name: envKafkaBrokers, Value: logConfig.KafkaConfig.Brokers},
-			{Name: envKafkaTopic, Value: logConfig.KafkaConfig.Topic},
-			{Name: envKafkaSerializationFormat, Value: string(logConfig.KafkaConfig.SerializationFormat)},
-			{Name: envKafkaMaxMessageBytes, Value: strconv.Itoa(routerDefaults.KafkaConfig.MaxMessageBytes)},
-			{Name: envKafkaCompressionType, Value: routerDefaults.KafkaConfig.CompressionType},
+	}
+
+	return envs, nil
+}
+
+// buildRouterFallbackConfig collects the fallback responses of the router and its traffic rules, keyed
+// by the traffic rule names, which are the IDs of the rules' routes in the router. It returns nil, if the
+// router version has no fallback response.
+func buildRouterFallbackConfig(ver *models.RouterVersion) *routeConfig.FallbackConfig {
+	fallbackCfg := &routeConfig.FallbackConfig{TrafficRules: map[string]*routeConfig.FallbackResponse{}}
+	if ver.FallbackResponse != nil {
+		fallbackCfg.Default = &routeConfig.FallbackResponse{
+			Status: ver.FallbackResponse.Status,
+			Body:   ver.FallbackResponse.Body,
+		}
+	}
+	for _, rule := range ver.TrafficRules {
+		if rule.FallbackResponse != nil {
+			fallbackCfg.TrafficRules[rule.Name] = &routeConfig.FallbackResponse{
+				Status: rule.FallbackResponse.Status,
+				Body:   rule.FallbackResponse.Body,
+			}
+		}
+	}
+	if fallbackCfg.Default == nil && len(fallbackCfg.TrafficRules) == 0 {
+		return nil
+	}
+	return fallbackCfg
+}
+
+// buildResultLogPolicy builds the router's config of the sampling and the redaction of the result logs.
+// It returns nil, if the results are neither sampled nor redacted.
+func buildResultLogPolicy(logConfig *models.LogConfig) *routeConfig.ResultLogPolicy {
+	if logConfig.SamplingRate == nil && len(logConfig.Redactions) == 0 {
+		return nil
+	}
+	logPolicy := &routeConfig.ResultLogPolicy{SamplingRate: logConfig.SamplingRate}
+	if logConfig.SamplingUnit != nil {
+		logPolicy.SamplingUnit = &routeConfig.ResultLogSamplingUnit{
+			FieldSource: logConfig.SamplingUnit.FieldSource,
+			Field:       logConfig.SamplingUnit.Field,
+		}
+	}
+	for _, redaction := range logConfig.Redactions {
+		logPolicy.Redactions = append(logPolicy.Redactions, routeConfig.ResultLogRedaction{
+			FieldSource: redaction.FieldSource,
+			Field:       redaction.Field,
+			Action:      redaction.Action,
+		})
+	}
+	if logConfig.HasHashRedaction() {
+		logPolicy.HashKeyFile = secretMountPathResultLog + SecretKeyNameRouterHashKey
+	}
+	return logPolicy
+}
+
+// buildRouterAuthEnvs builds the env vars, that configure the authentication of the router's callers.
+// The API keys and the inline JWKS are read from the files, that are mounted from the router's secret.
+func buildRouterAuthEnvs(auth *models.AuthConfig) ([]corev1.EnvVar, error) {
+	envs := []corev1.EnvVar{}
+	if len(auth.APIKeys) > 0 {
+		envs = append(envs, corev1.EnvVar{
+			Name:  envRouterAuthAPIKeysFile,
+			Value: secretMountPathAuth + SecretKeyNameRouterAPIKeys,
 		})
 	}
+	if auth.JWT == nil {
+		return envs, nil
+	}
 
+	if len(auth.JWT.JWKS) > 0 {
+		envs = append(envs, corev1.EnvVar{Name: envRouterAuthJWKSFile, Value: secretMountPathAuth + SecretKeyNameRouterJWKS})
+	} else {
+		envs = append(envs, corev1.EnvVar{Name: envRouterAuthJWKSURL, Value: auth.JWT.JWKSURL})
+	}
+	if auth.JWT.Issuer != "" {
+		envs = append(envs, corev1.EnvVar{Name: envRouterAuthIssuer, Value: auth.JWT.Issuer})
+	}
+	if auth.JWT.Audience != "" {
+		envs = append(envs, corev1.EnvVar{Name: envRouterAuthAudience, Value: auth.JWT.Audience})
+	}
+	if len(auth.JWT.RequiredClaims) > 0 {
+		requiredClaims, err := json.Marshal(auth.JWT.RequiredClaims)
+		if err != nil {
+			return envs, err
+		}
+		envs = append(envs, corev1.EnvVar{Name: envRouterAuthRequiredClaims, Value: string(requiredClaims)})
+	}
+	if auth.JWT.IdentityClaim != "" {
+		envs = append(envs, corev1.EnvVar{Name: envRouterAuthIdentityClaim, Value: auth.JWT.IdentityClaim})
+	}
 	return envs, nil
 }
 
@@ -364,7 +591,7 @@ func buildRouterVolumes(
 	}
 
 	// Service account
-	if routerVersion.LogConfig.ResultLoggerType == models.BigQueryLogger {
+	if routerVersion.LogConfig.HasResultLogger(models.BigQueryLogger) {
 		volumes = append(volumes, corev1.Volume{
 			Name: secretVolumeRouter,
 			VolumeSource: corev1.VolumeSource{
@@ -384,6 +611,65 @@ func buildRouterVolumes(
 			MountPath: secretMountPathRouter,
 		})
 	}
+
+	// Bandit state, which is kept across the restarts of the router's container
+	if routerVersion.Bandit != nil && routerVersion.Bandit.SnapshotInterval != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name: banditVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      banditVolumeName,
+			MountPath: banditMountPath,
+		})
+	}
+
+	// Secret key of the hashed fields of the result logs
+	if routerVersion.LogConfig.HasHashRedaction() {
+		volumes = append(volumes, corev1.Volume{
+			Name: secretVolumeResultLog,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: secretName,
+					Items: []corev1.KeyToPath{
+						{Key: SecretKeyNameRouterHashKey, Path: SecretKeyNameRouterHashKey},
+					},
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      secretVolumeResultLog,
+			MountPath: secretMountPathResultLog,
+		})
+	}
+
+	// API keys and JWKS of the router's callers
+	if routerVersion.Auth != nil {
+		items := []corev1.KeyToPath{}
+		if len(routerVersion.Auth.APIKeys) > 0 {
+			items = append(items, corev1.KeyToPath{Key: SecretKeyNameRouterAPIKeys, Path: SecretKeyNameRouterAPIKeys})
+		}
+		if routerVersion.Auth.JWT != nil && len(routerVersion.Auth.JWT.JWKS) > 0 {
+			items = append(items, corev1.KeyToPath{Key: SecretKeyNameRouterJWKS, Path: SecretKeyNameRouterJWKS})
+		}
+		if len(items) > 0 {
+			volumes = append(volumes, corev1.Volume{
+				Name: secretVolumeAuth,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: secretName,
+						Items:      items,
+					},
+				},
+			})
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{
+				Name:      secretVolumeAuth,
+				MountPath: secretMountPathAuth,
+			})
+		}
+	}
 	return volumes, volumeMounts
 }
 
@@ -422,6 +708,7 @@ func buildTrafficSplittingFiberConfig(
 	name string,
 	routes models.Routes,
 	rules models.TrafficRules,
+	unit *models.TrafficSplitUnit,
 	ensembler *models.Ensembler,
 	fiberProperties json.RawMessage,
 	protocol fiberProtocol.Protocol,
@@ -443,24 +730,39 @@ func buildTrafficSplittingFiberConfig(
 		}
 	}
 
-	defaultRouteID := "traffic-split-default"
+	isWeighted := rules.IsWeighted()
 
-	// build fiber target component, that is active if request
-	// doesn't match any of traffic rules
-	defaultRouteConfig, err := buildFiberConfig(
-		defaultRouteID,
-		alwaysActiveRoutes,
-		ensembler,
-		fiberProperties,
-		protocol)
-	if err != nil {
-		return nil, err
-	}
+	var splitRoutes []fiberConfig.Config
+	splitStrategy := fiberapi.TrafficSplittingStrategy{}
+	weightedSplitStrategy := fiberapi.WeightedTrafficSplittingStrategy{}
 
-	splitRoutes := []fiberConfig.Config{defaultRouteConfig}
-	splitStrategy := fiberapi.TrafficSplittingStrategy{
-		DefaultRouteID: defaultRouteID,
-		Rules:          nil,
+	if isWeighted {
+		// the request is assigned to one of the weighted rules by the value
+		// of the unit field, so there is no need for a separate default route
+		if unit == nil {
+			return nil, fmt.Errorf("failed to build fiber config, traffic split unit is not set")
+		}
+		weightedSplitStrategy.Unit = &fiberapi.TrafficSplitUnit{
+			FieldSource: unit.FieldSource,
+			Field:       unit.Field,
+		}
+	} else {
+		defaultRouteID := "traffic-split-default"
+
+		// build fiber target component, that is active if request
+		// doesn't match any of traffic rules
+		defaultRouteConfig, err := buildFiberConfig(
+			defaultRouteID,
+			alwaysActiveRoutes,
+			ensembler,
+			fiberProperties,
+			protocol)
+		if err != nil {
+			return nil, err
+		}
+
+		splitRoutes = append(splitRoutes, defaultRouteConfig)
+		splitStrategy.DefaultRouteID = defaultRouteID
 	}
 
 	// iterate over traffic rules and generated nested routed and
@@ -492,16 +794,36 @@ func buildTrafficSplittingFiberConfig(
 		splitRoutes = append(splitRoutes, routeConfig)
 
 		// append new rule to the traffic splitting strategy
-		splitStrategy.Rules = append(
-			splitStrategy.Rules,
-			&fiberapi.TrafficSplittingStrategyRule{
-				RouteID:    routeID,
-				Conditions: rule.Conditions,
-			})
+		switch {
+		case rule.Weight != nil:
+			weightedSplitStrategy.Rules = append(
+				weightedSplitStrategy.Rules,
+				&fiberapi.WeightedTrafficSplittingStrategyRule{
+					RouteID: routeID,
+					Weight:  *rule.Weight,
+				})
+		case isWeighted:
+			// the rule without weight (i.e. the default traffic rule) is used
+			// for the requests, that don't have the unit field
+			weightedSplitStrategy.DefaultRouteID = routeID
+		default:
+			splitStrategy.Rules = append(
+				splitStrategy.Rules,
+				&fiberapi.TrafficSplittingStrategyRule{
+					RouteID:    routeID,
+					Conditions: rule.Conditions,
+				})
+		}
 	}
 
 	// serialize properties of traffic-splitting strategy
-	splitStrategyProps, err := json.Marshal(&splitStrategy)
+	strategyType := routerConfigStrategyTypeTrafficSplitting
+	var strategy interface{} = &splitStrategy
+	if isWeighted {
+		strategyType = routerConfigStrategyTypeWeightedSplit
+		strategy = &weightedSplitStrategy
+	}
+	splitStrategyProps, err := json.Marshal(strategy)
 	if err != nil {
 		return nil, err
 	}
@@ -515,7 +837,7 @@ func buildTrafficSplittingFiberConfig(
 			Routes: splitRoutes,
 		},
 		Strategy: fiberConfig.StrategyConfig{
-			Type:       routerConfigStrategyTypeTrafficSplitting,
+			Type:       strategyType,
 			Properties: splitStrategyProps,
 		},
 	}
@@ -543,10 +865,12 @@ func buildFiberConfig(
 	}
 
 	// Select router type (eager or combiner) based on the ensembler config.
-	// If ensembler uses a DockerConfig to run, use "combiner" router
-	// Else, "eager" router is used.
+	// If ensembler uses a DockerConfig to run, or is a built-in ensembler, use "combiner" router
+	// Else, "eager" router is used. UPI routers always use a routing strategy, since
+	// the ensembler is invoked by the router on the response of the selected route.
 	var routerConfig fiberConfig.Config
-	if ensembler != nil && ensembler.DockerConfig != nil {
+	if ensembler != nil && (ensembler.DockerConfig != nil || ensembler.BuiltinConfig != nil) &&
+		protocol != fiberProtocol.GRPC {
 		multiRouteConfig.Type = routerConfigTypeCombiner
 		routerConfig = &fiberConfig.CombinerConfig{
 			MultiRouteConfig: multiRouteConfig,
@@ -574,6 +898,84 @@ func buildFiberConfig(
 	return routerConfig, nil
 }
 
+// buildBanditFiberConfig creates a lazy router, that only dispatches the request to the
+// route selected by the multi-armed bandit
+func buildBanditFiberConfig(
+	name string,
+	routes models.Routes,
+	bandit *models.BanditConfig,
+	defaultRouteID string,
+	protocol fiberProtocol.Protocol,
+) (fiberConfig.Config, error) {
+	fiberRoutes, err := routes.ToFiberRoutes(protocol)
+	if err != nil {
+		return nil, err
+	}
+
+	strategy := fiberapi.BanditRoutingStrategy{
+		DefaultRouteID: defaultRouteID,
+		RouteIDs:       bandit.Routes,
+		Algorithm:      routerBandit.Algorithm(bandit.Algorithm),
+		Epsilon:        bandit.Epsilon,
+	}
+	strategyProps, err := json.Marshal(strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fiberConfig.RouterConfig{
+		MultiRouteConfig: fiberConfig.MultiRouteConfig{
+			ComponentConfig: fiberConfig.ComponentConfig{
+				ID:   name,
+				Type: routerConfigTypeLazyRouter,
+			},
+			Routes: *fiberRoutes,
+		},
+		Strategy: fiberConfig.StrategyConfig{
+			Type:       routerConfigStrategyTypeBandit,
+			Properties: strategyProps,
+		},
+	}, nil
+}
+
+// buildShadowFiberConfig wraps the given primary fiber component, that is expected to have
+// the ID shadowPrimaryRouteID, into a lazy router,
+// that also dispatches every request to the shadow routes, without waiting for their responses
+func buildShadowFiberConfig(
+	name string,
+	primary fiberConfig.Config,
+	shadowRoutes models.Routes,
+	protocol fiberProtocol.Protocol,
+) (fiberConfig.Config, error) {
+	fiberRoutes, err := shadowRoutes.ToFiberRoutes(protocol)
+	if err != nil {
+		return nil, err
+	}
+
+	strategy := fiberapi.ShadowRoutingStrategy{PrimaryRouteID: shadowPrimaryRouteID}
+	for _, route := range shadowRoutes {
+		strategy.ShadowRouteIDs = append(strategy.ShadowRouteIDs, route.ID)
+	}
+	strategyProps, err := json.Marshal(strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fiberConfig.RouterConfig{
+		MultiRouteConfig: fiberConfig.MultiRouteConfig{
+			ComponentConfig: fiberConfig.ComponentConfig{
+				ID:   name,
+				Type: routerConfigTypeLazyRouter,
+			},
+			Routes: append(fiberConfig.Routes{primary}, *fiberRoutes...),
+		},
+		Strategy: fiberConfig.StrategyConfig{
+			Type:       routerConfigStrategyTypeShadow,
+			Properties: strategyProps,
+		},
+	}, nil
+}
+
 func buildFiberConfigMap(
 	ver *models.RouterVersion,
 	project *mlp.Project,
@@ -615,6 +1017,10 @@ func buildFiberConfigMap(
 			propsMap["route_name_path"] = ver.Ensembler.StandardConfig.RouteNamePath
 		}
 	}
+	// The built-in ensembler combines the route responses in the router's fan in
+	if ver.Ensembler != nil && ver.Ensembler.Type == models.EnsemblerBuiltinType {
+		propsMap["builtin_ensembler"] = ver.Ensembler.BuiltinConfig
+	}
 
 	properties, err := json.Marshal(propsMap)
 	if err != nil {
@@ -627,11 +1033,28 @@ func buildFiberConfigMap(
 		routeProtocol = fiberProtocol.GRPC
 	}
 
+	// shadow routes are not part of the routing, and are only dispatched to
+	// by the top-level shadow router, if any
+	routes, shadowRoutes := ver.Routes.SplitShadowRoutes()
+	routerName := ver.Router.Name
+	if len(shadowRoutes) > 0 {
+		routerName = shadowPrimaryRouteID
+	}
+
 	var routerConfig fiberConfig.Config
-	// if the version is configured with traffic splitting rules on it,
+	// if the version is configured with a multi-armed bandit, then define
+	// root-level fiber component as a lazy router with a bandit strategy,
+	// else if the version is configured with traffic splitting rules on it,
 	// then define root-level fiber component as a lazy router with
 	// a traffic-splitting strategy based on these rules
-	if ver.TrafficRules != nil && len(ver.TrafficRules) > 0 {
+	if ver.Bandit != nil {
+		routerConfig, err = buildBanditFiberConfig(
+			routerName,
+			routes,
+			ver.Bandit,
+			ver.DefaultRouteID,
+			routeProtocol)
+	} else if ver.TrafficRules != nil && len(ver.TrafficRules) > 0 {
 		// TrafficRule struct used requires the name and conditions field to be specified. But
 		// Default Traffic Rule has no name and a hardcoded name can be used instead since
 		// the name field is not used for traffic splitting strategy. Likewise, an empty slice
@@ -645,21 +1068,26 @@ func buildFiberConfigMap(
 			},
 		)
 		routerConfig, err = buildTrafficSplittingFiberConfig(
-			ver.Router.Name,
-			ver.Routes,
+			routerName,
+			routes,
 			rules,
+			ver.TrafficSplitUnit,
 			ver.Ensembler,
 			properties,
 			routeProtocol)
 	} else {
 		routerConfig, err = buildFiberConfig(
-			ver.Router.Name,
-			ver.Routes,
+			routerName,
+			routes,
 			ver.Ensembler,
 			properties,
 			routeProtocol)
 	}
 
+	if err == nil && len(shadowRoutes) > 0 {
+		routerConfig, err = buildShadowFiberConfig(ver.Router.Name, routerConfig, shadowRoutes, routeProtocol)
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -684,6 +1112,11 @@ func buildPrePostProcessorEndpoint(
 	relativeEndpoint string,
 ) string {
 	componentName := GetComponentName(routerVersion, componentType)
+	// UPI enricher / ensembler are called through the PredictValues gRPC method,
+	// so only the host and port of the service are required.
+	if routerVersion.Protocol == routeConfig.UPI {
+		return fmt.Sprintf("%s.%s.svc.cluster.local:80", componentName, namespace)
+	}
 	// Trim leading slash, if present
 	relativeEndpoint = strings.TrimPrefix(relativeEndpoint, "/")
 	return fmt.Sprintf("http://%s.%s.svc.cluster.local/%s",
@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"path/filepath"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 
 	mlp "github.com/caraml-dev/mlp/api/client"
+	fiberConfig "github.com/gojek/fiber/config"
+	fiberProtocol "github.com/gojek/fiber/protocol"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -16,7 +19,10 @@ import (
 	"github.com/caraml-dev/turing/api/turing/config"
 	tu "github.com/caraml-dev/turing/api/turing/internal/testutils"
 	"github.com/caraml-dev/turing/api/turing/models"
+	expRequest "github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router"
 	routerConfig "github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/fiberapi"
 )
 
 func TestNewRouterService(t *testing.T) {
@@ -1126,6 +1132,7 @@ func TestBuildRouterEnvsResultLogger(t *testing.T) {
 		ver             *models.RouterVersion
 	}
 	namespace := "testnamespace"
+	samplingRate := 0.25
 	tests := []struct {
 		name string
 		args args
@@ -1232,6 +1239,427 @@ func TestBuildRouterEnvsResultLogger(t *testing.T) {
 				{Name: "APP_KAFKA_COMPRESSION_TYPE", Value: "gzip"},
 			},
 		},
+		{
+			name: "MultipleResultLoggers",
+			args: args{
+				namespace: "testnamespace",
+				routerDefaults: &config.RouterDefaults{
+					FluentdConfig: &config.FluentdConfig{Tag: "tag"},
+					KafkaConfig: &config.KafkaConfig{
+						MaxMessageBytes: 123,
+						CompressionType: "gzip",
+					},
+				},
+				ver: &models.RouterVersion{
+					Router:   &models.Router{Name: "test1"},
+					Version:  1,
+					Timeout:  "10s",
+					Protocol: routerConfig.HTTP,
+					LogConfig: &models.LogConfig{
+						ResultLoggerType:  models.KafkaLogger,
+						ResultLoggerTypes: []models.ResultLogger{models.KafkaLogger, models.BigQueryLogger},
+						KafkaConfig: &models.KafkaConfig{
+							Brokers:             "1.1.1.1:1111",
+							Topic:               "kafkatopic",
+							SerializationFormat: "json",
+						},
+						BigQueryConfig: &models.BigQueryConfig{
+							Table:     "project.dataset.table",
+							BatchLoad: true,
+						},
+					},
+				},
+			},
+			want: []corev1.EnvVar{
+				{Name: "APP_NAME", Value: "test1-1.testnamespace"},
+				{Name: "APP_ENVIRONMENT", Value: ""},
+				{Name: "ROUTER_TIMEOUT", Value: "10s"},
+				{Name: "APP_JAEGER_COLLECTOR_ENDPOINT", Value: ""},
+				{Name: "ROUTER_CONFIG_FILE", Value: "/app/config/fiber.yml"},
+				{Name: "ROUTER_PROTOCOL", Value: string(routerConfig.HTTP)},
+				{Name: "APP_SENTRY_ENABLED", Value: "false"},
+				{Name: "APP_SENTRY_DSN", Value: ""},
+				{Name: "APP_LOGLEVEL", Value: ""},
+				{Name: "APP_CUSTOM_METRICS", Value: "false"},
+				{Name: "APP_JAEGER_ENABLED", Value: "false"},
+				{Name: "APP_RESULT_LOGGER", Value: "kafka"},
+				{Name: "APP_FIBER_DEBUG_LOG", Value: "false"},
+				{Name: "APP_RESULT_LOGGERS", Value: "kafka,bigquery"},
+				{Name: "APP_KAFKA_BROKERS", Value: "1.1.1.1:1111"},
+				{Name: "APP_KAFKA_TOPIC", Value: "kafkatopic"},
+				{Name: "APP_KAFKA_SERIALIZATION_FORMAT", Value: "json"},
+				{Name: "APP_KAFKA_MAX_MESSAGE_BYTES", Value: "123"},
+				{Name: "APP_KAFKA_COMPRESSION_TYPE", Value: "gzip"},
+				{Name: "APP_GCP_PROJECT", Value: "project"},
+				{Name: "APP_BQ_DATASET", Value: "dataset"},
+				{Name: "APP_BQ_TABLE", Value: "table"},
+				{Name: "APP_BQ_BATCH_LOAD", Value: "true"},
+				{Name: "GOOGLE_APPLICATION_CREDENTIALS", Value: "/var/secret/router/router-service-account.json"},
+				{Name: "APP_FLUENTD_HOST", Value: "test1-turing-fluentd-logger-1.testnamespace.svc.cluster.local"},
+				{Name: "APP_FLUENTD_PORT", Value: "24224"},
+				{Name: "APP_FLUENTD_TAG", Value: "tag"},
+			},
+		},
+		{
+			name: "ResponseCache",
+			args: args{
+				namespace:      "testnamespace",
+				routerDefaults: &config.RouterDefaults{},
+				ver: &models.RouterVersion{
+					Router:   &models.Router{Name: "test1"},
+					Version:  1,
+					Timeout:  "10s",
+					Protocol: routerConfig.HTTP,
+					ResponseCache: &models.ResponseCacheConfig{
+						MaxEntries: 500,
+						TTL:        "30s",
+						KeyFields: []models.ResponseCacheKeyField{
+							{FieldSource: "header", Field: "X-Customer-ID"},
+							{FieldSource: "payload", Field: "features.location"},
+						},
+					},
+					LogConfig: &models.LogConfig{
+						ResultLoggerType: models.NopLogger,
+					},
+				},
+			},
+			want: []corev1.EnvVar{
+				{Name: "APP_NAME", Value: "test1-1.testnamespace"},
+				{Name: "APP_ENVIRONMENT", Value: ""},
+				{Name: "ROUTER_TIMEOUT", Value: "10s"},
+				{Name: "APP_JAEGER_COLLECTOR_ENDPOINT", Value: ""},
+				{Name: "ROUTER_CONFIG_FILE", Value: "/app/config/fiber.yml"},
+				{Name: "ROUTER_PROTOCOL", Value: string(routerConfig.HTTP)},
+				{Name: "APP_SENTRY_ENABLED", Value: "false"},
+				{Name: "APP_SENTRY_DSN", Value: ""},
+				{Name: "ROUTER_CACHE_ENABLED", Value: "true"},
+				{Name: "ROUTER_CACHE_MAX_ENTRIES", Value: "500"},
+				{Name: "ROUTER_CACHE_TTL", Value: "30s"},
+				{
+					Name: "ROUTER_CACHE_KEY_FIELDS",
+					Value: `[{"field_source":"header","field":"X-Customer-ID"},` +
+						`{"field_source":"payload","field":"features.location"}]`,
+				},
+				{Name: "APP_LOGLEVEL", Value: ""},
+				{Name: "APP_CUSTOM_METRICS", Value: "false"},
+				{Name: "APP_JAEGER_ENABLED", Value: "false"},
+				{Name: "APP_RESULT_LOGGER", Value: "nop"},
+				{Name: "APP_FIBER_DEBUG_LOG", Value: "false"},
+			},
+		},
+		{
+			name: "ProcessingStages",
+			args: args{
+				namespace:      "testnamespace",
+				routerDefaults: &config.RouterDefaults{},
+				ver: &models.RouterVersion{
+					Router:   &models.Router{Name: "test1"},
+					Version:  1,
+					Timeout:  "10s",
+					Protocol: routerConfig.HTTP,
+					ProcessingStages: models.ProcessingStages{
+						{
+							Name:         "calibration",
+							Phase:        router.PostprocessingPhase,
+							Endpoint:     "http://calibration.example.com/calibrate",
+							Timeout:      fiberConfig.Duration(50 * time.Millisecond),
+							ErrorPolicy:  router.UsePreviousPayloadErrorPolicy,
+							ResultLogKey: "calibrated",
+						},
+					},
+					LogConfig: &models.LogConfig{
+						ResultLoggerType: models.NopLogger,
+					},
+				},
+			},
+			want: []corev1.EnvVar{
+				{Name: "APP_NAME", Value: "test1-1.testnamespace"},
+				{Name: "APP_ENVIRONMENT", Value: ""},
+				{Name: "ROUTER_TIMEOUT", Value: "10s"},
+				{Name: "APP_JAEGER_COLLECTOR_ENDPOINT", Value: ""},
+				{Name: "ROUTER_CONFIG_FILE", Value: "/app/config/fiber.yml"},
+				{Name: "ROUTER_PROTOCOL", Value: string(routerConfig.HTTP)},
+				{Name: "APP_SENTRY_ENABLED", Value: "false"},
+				{Name: "APP_SENTRY_DSN", Value: ""},
+				{
+					Name: "PROCESSING_STAGES",
+					Value: `[{"name":"calibration","phase":"postprocessing",` +
+						`"endpoint":"http://calibration.example.com/calibrate","timeout":"50ms",` +
+						`"error_policy":"use_previous_payload","result_log_key":"calibrated"}]`,
+				},
+				{Name: "APP_LOGLEVEL", Value: ""},
+				{Name: "APP_CUSTOM_METRICS", Value: "false"},
+				{Name: "APP_JAEGER_ENABLED", Value: "false"},
+				{Name: "APP_RESULT_LOGGER", Value: "nop"},
+				{Name: "APP_FIBER_DEBUG_LOG", Value: "false"},
+			},
+		},
+		{
+			name: "Auth",
+			args: args{
+				namespace:      "testnamespace",
+				routerDefaults: &config.RouterDefaults{},
+				ver: &models.RouterVersion{
+					Router:   &models.Router{Name: "test1"},
+					Version:  1,
+					Timeout:  "10s",
+					Protocol: routerConfig.HTTP,
+					Auth: &models.AuthConfig{
+						APIKeys: []*models.APIKey{{Caller: "team-a", MLPSecretName: "team-a-key"}},
+						JWT: &models.JWTAuthConfig{
+							JWKSURL:        "https://auth.example.com/.well-known/jwks.json",
+							Audience:       "turing-router",
+							RequiredClaims: map[string]string{"scope": "predict"},
+							IdentityClaim:  "client_id",
+						},
+					},
+					LogConfig: &models.LogConfig{
+						ResultLoggerType: models.NopLogger,
+					},
+				},
+			},
+			want: []corev1.EnvVar{
+				{Name: "APP_NAME", Value: "test1-1.testnamespace"},
+				{Name: "APP_ENVIRONMENT", Value: ""},
+				{Name: "ROUTER_TIMEOUT", Value: "10s"},
+				{Name: "APP_JAEGER_COLLECTOR_ENDPOINT", Value: ""},
+				{Name: "ROUTER_CONFIG_FILE", Value: "/app/config/fiber.yml"},
+				{Name: "ROUTER_PROTOCOL", Value: string(routerConfig.HTTP)},
+				{Name: "APP_SENTRY_ENABLED", Value: "false"},
+				{Name: "APP_SENTRY_DSN", Value: ""},
+				{Name: "ROUTER_AUTH_API_KEYS_FILE", Value: "/var/secret/router-auth/router-api-keys.json"},
+				{Name: "ROUTER_AUTH_JWT_JWKS_URL", Value: "https://auth.example.com/.well-known/jwks.json"},
+				{Name: "ROUTER_AUTH_JWT_AUDIENCE", Value: "turing-router"},
+				{Name: "ROUTER_AUTH_JWT_REQUIRED_CLAIMS", Value: `{"scope":"predict"}`},
+				{Name: "ROUTER_AUTH_JWT_IDENTITY_CLAIM", Value: "client_id"},
+				{Name: "APP_LOGLEVEL", Value: ""},
+				{Name: "APP_CUSTOM_METRICS", Value: "false"},
+				{Name: "APP_JAEGER_ENABLED", Value: "false"},
+				{Name: "APP_RESULT_LOGGER", Value: "nop"},
+				{Name: "APP_FIBER_DEBUG_LOG", Value: "false"},
+			},
+		},
+		{
+			name: "RateLimit",
+			args: args{
+				namespace:      "testnamespace",
+				routerDefaults: &config.RouterDefaults{},
+				ver: &models.RouterVersion{
+					Router:   &models.Router{Name: "test1"},
+					Version:  1,
+					Timeout:  "10s",
+					Protocol: routerConfig.HTTP,
+					RateLimit: &models.RateLimitConfig{
+						FieldSource:       expRequest.HeaderFieldSource,
+						Field:             "X-Client-ID",
+						RequestsPerSecond: 12.5,
+						MaxInFlight:       8,
+						MetricKeys:        []string{"client-a", "client-b"},
+					},
+					LogConfig: &models.LogConfig{
+						ResultLoggerType: models.NopLogger,
+					},
+				},
+			},
+			want: []corev1.EnvVar{
+				{Name: "APP_NAME", Value: "test1-1.testnamespace"},
+				{Name: "APP_ENVIRONMENT", Value: ""},
+				{Name: "ROUTER_TIMEOUT", Value: "10s"},
+				{Name: "APP_JAEGER_COLLECTOR_ENDPOINT", Value: ""},
+				{Name: "ROUTER_CONFIG_FILE", Value: "/app/config/fiber.yml"},
+				{Name: "ROUTER_PROTOCOL", Value: string(routerConfig.HTTP)},
+				{Name: "APP_SENTRY_ENABLED", Value: "false"},
+				{Name: "APP_SENTRY_DSN", Value: ""},
+				{Name: "ROUTER_RATE_LIMIT_FIELD_SOURCE", Value: "header"},
+				{Name: "ROUTER_RATE_LIMIT_FIELD", Value: "X-Client-ID"},
+				{Name: "ROUTER_RATE_LIMIT_REQUESTS_PER_SEC", Value: "12.5"},
+				{Name: "ROUTER_RATE_LIMIT_BURST", Value: "0"},
+				{Name: "ROUTER_RATE_LIMIT_MAX_IN_FLIGHT", Value: "8"},
+				{Name: "ROUTER_RATE_LIMIT_METRIC_KEYS", Value: "client-a,client-b"},
+				{Name: "APP_LOGLEVEL", Value: ""},
+				{Name: "APP_CUSTOM_METRICS", Value: "false"},
+				{Name: "APP_JAEGER_ENABLED", Value: "false"},
+				{Name: "APP_RESULT_LOGGER", Value: "nop"},
+				{Name: "APP_FIBER_DEBUG_LOG", Value: "false"},
+			},
+		},
+		{
+			name: "BanditSnapshots",
+			args: args{
+				namespace:      "testnamespace",
+				routerDefaults: &config.RouterDefaults{},
+				ver: &models.RouterVersion{
+					Router:   &models.Router{Name: "test1"},
+					Version:  1,
+					Timeout:  "10s",
+					Protocol: routerConfig.HTTP,
+					Bandit: &models.BanditConfig{
+						Algorithm:        models.ThompsonSamplingAlgorithm,
+						SnapshotInterval: "30s",
+					},
+					LogConfig: &models.LogConfig{
+						ResultLoggerType: models.NopLogger,
+					},
+				},
+			},
+			want: []corev1.EnvVar{
+				{Name: "APP_NAME", Value: "test1-1.testnamespace"},
+				{Name: "APP_ENVIRONMENT", Value: ""},
+				{Name: "ROUTER_TIMEOUT", Value: "10s"},
+				{Name: "APP_JAEGER_COLLECTOR_ENDPOINT", Value: ""},
+				{Name: "ROUTER_CONFIG_FILE", Value: "/app/config/fiber.yml"},
+				{Name: "ROUTER_PROTOCOL", Value: string(routerConfig.HTTP)},
+				{Name: "APP_SENTRY_ENABLED", Value: "false"},
+				{Name: "APP_SENTRY_DSN", Value: ""},
+				{Name: "ROUTER_BANDIT_SNAPSHOT_FILE", Value: "/app/bandit/snapshot.json"},
+				{Name: "ROUTER_BANDIT_SNAPSHOT_INTERVAL", Value: "30s"},
+				{Name: "APP_LOGLEVEL", Value: ""},
+				{Name: "APP_CUSTOM_METRICS", Value: "false"},
+				{Name: "APP_JAEGER_ENABLED", Value: "false"},
+				{Name: "APP_RESULT_LOGGER", Value: "nop"},
+				{Name: "APP_FIBER_DEBUG_LOG", Value: "false"},
+			},
+		},
+		{
+			name: "FallbackResponses",
+			args: args{
+				namespace:      "testnamespace",
+				routerDefaults: &config.RouterDefaults{},
+				ver: &models.RouterVersion{
+					Router:   &models.Router{Name: "test1"},
+					Version:  1,
+					Timeout:  "10s",
+					Protocol: routerConfig.HTTP,
+					TrafficRules: models.TrafficRules{
+						{
+							Name:   "rule-a",
+							Routes: []string{"route-a"},
+							FallbackResponse: &models.FallbackResponse{
+								Status: 503,
+								Body:   json.RawMessage(`{"score":1}`),
+							},
+						},
+						{
+							Name:   "rule-b",
+							Routes: []string{"route-b"},
+						},
+					},
+					FallbackResponse: &models.FallbackResponse{Body: json.RawMessage(`{"score":0}`)},
+					LogConfig: &models.LogConfig{
+						ResultLoggerType: models.NopLogger,
+					},
+				},
+			},
+			want: []corev1.EnvVar{
+				{Name: "APP_NAME", Value: "test1-1.testnamespace"},
+				{Name: "APP_ENVIRONMENT", Value: ""},
+				{Name: "ROUTER_TIMEOUT", Value: "10s"},
+				{Name: "APP_JAEGER_COLLECTOR_ENDPOINT", Value: ""},
+				{Name: "ROUTER_CONFIG_FILE", Value: "/app/config/fiber.yml"},
+				{Name: "ROUTER_PROTOCOL", Value: string(routerConfig.HTTP)},
+				{Name: "APP_SENTRY_ENABLED", Value: "false"},
+				{Name: "APP_SENTRY_DSN", Value: ""},
+				{
+					Name: "ROUTER_FALLBACK_RESPONSES",
+					Value: `{"default":{"body":{"score":0}},` +
+						`"traffic_rules":{"rule-a":{"status":503,"body":{"score":1}}}}`,
+				},
+				{Name: "APP_LOGLEVEL", Value: ""},
+				{Name: "APP_CUSTOM_METRICS", Value: "false"},
+				{Name: "APP_JAEGER_ENABLED", Value: "false"},
+				{Name: "APP_RESULT_LOGGER", Value: "nop"},
+				{Name: "APP_FIBER_DEBUG_LOG", Value: "false"},
+			},
+		},
+		{
+			name: "DebugResponse",
+			args: args{
+				namespace:      "testnamespace",
+				routerDefaults: &config.RouterDefaults{},
+				ver: &models.RouterVersion{
+					Router:   &models.Router{Name: "test1"},
+					Version:  1,
+					Timeout:  "10s",
+					Protocol: routerConfig.HTTP,
+					LogConfig: &models.LogConfig{
+						ResultLoggerType:     models.NopLogger,
+						DebugResponseEnabled: true,
+					},
+				},
+			},
+			want: []corev1.EnvVar{
+				{Name: "APP_NAME", Value: "test1-1.testnamespace"},
+				{Name: "APP_ENVIRONMENT", Value: ""},
+				{Name: "ROUTER_TIMEOUT", Value: "10s"},
+				{Name: "APP_JAEGER_COLLECTOR_ENDPOINT", Value: ""},
+				{Name: "ROUTER_CONFIG_FILE", Value: "/app/config/fiber.yml"},
+				{Name: "ROUTER_PROTOCOL", Value: string(routerConfig.HTTP)},
+				{Name: "APP_SENTRY_ENABLED", Value: "false"},
+				{Name: "APP_SENTRY_DSN", Value: ""},
+				{Name: "APP_LOGLEVEL", Value: ""},
+				{Name: "APP_CUSTOM_METRICS", Value: "false"},
+				{Name: "APP_JAEGER_ENABLED", Value: "false"},
+				{Name: "APP_RESULT_LOGGER", Value: "nop"},
+				{Name: "APP_FIBER_DEBUG_LOG", Value: "false"},
+				{Name: "ROUTER_DEBUG_RESPONSE_ENABLED", Value: "true"},
+			},
+		},
+		{
+			name: "ResultLogPolicy",
+			args: args{
+				namespace:      "testnamespace",
+				routerDefaults: &config.RouterDefaults{},
+				ver: &models.RouterVersion{
+					Router:   &models.Router{Name: "test1"},
+					Version:  1,
+					Timeout:  "10s",
+					Protocol: routerConfig.HTTP,
+					LogConfig: &models.LogConfig{
+						ResultLoggerType: models.NopLogger,
+						SamplingRate:     &samplingRate,
+						SamplingUnit: &models.ResultLogSamplingUnit{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-User-ID",
+						},
+						Redactions: []models.ResultLogRedaction{
+							{
+								FieldSource: expRequest.PayloadFieldSource,
+								Field:       "customer.phone",
+								Action:      routerConfig.MaskRedactionAction,
+							},
+							{
+								FieldSource: expRequest.HeaderFieldSource,
+								Field:       "X-Email",
+								Action:      routerConfig.HashRedactionAction,
+							},
+						},
+						HashKeySecret: "hash-key",
+					},
+				},
+			},
+			want: []corev1.EnvVar{
+				{Name: "APP_NAME", Value: "test1-1.testnamespace"},
+				{Name: "APP_ENVIRONMENT", Value: ""},
+				{Name: "ROUTER_TIMEOUT", Value: "10s"},
+				{Name: "APP_JAEGER_COLLECTOR_ENDPOINT", Value: ""},
+				{Name: "ROUTER_CONFIG_FILE", Value: "/app/config/fiber.yml"},
+				{Name: "ROUTER_PROTOCOL", Value: string(routerConfig.HTTP)},
+				{Name: "APP_SENTRY_ENABLED", Value: "false"},
+				{Name: "APP_SENTRY_DSN", Value: ""},
+				{Name: "APP_LOGLEVEL", Value: ""},
+				{Name: "APP_CUSTOM_METRICS", Value: "false"},
+				{Name: "APP_JAEGER_ENABLED", Value: "false"},
+				{Name: "APP_RESULT_LOGGER", Value: "nop"},
+				{Name: "APP_FIBER_DEBUG_LOG", Value: "false"},
+				{
+					Name: "APP_RESULT_LOG_POLICY",
+					Value: `{"sampling_rate":0.25,"sampling_unit":{"field_source":"header","field":"X-User-ID"},` +
+						`"redactions":[{"field_source":"payload","field":"customer.phone","action":"mask"},` +
+						`{"field_source":"header","field":"X-Email","action":"hash"}],` +
+						`"hash_key_file":"/var/secret/router-result-log/router-result-log-hash-key"}`,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1250,3 +1678,339 @@ func TestBuildRouterEnvsResultLogger(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildRouterVolumesAuth(t *testing.T) {
+	ver := &models.RouterVersion{
+		ExperimentEngine: &models.ExperimentEngine{Type: "nop"},
+		LogConfig:        &models.LogConfig{ResultLoggerType: models.NopLogger},
+		Auth: &models.AuthConfig{
+			APIKeys: []*models.APIKey{{Caller: "team-a", MLPSecretName: "team-a-key"}},
+			JWT:     &models.JWTAuthConfig{JWKS: json.RawMessage(`{"keys": []}`)},
+		},
+	}
+
+	volumes, volumeMounts := buildRouterVolumes(ver, "test-config-map", "test-secret")
+	require.Len(t, volumes, 2)
+	assert.Equal(t, corev1.Volume{
+		Name: "auth-secret-volume-router",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: "test-secret",
+				Items: []corev1.KeyToPath{
+					{Key: "router-api-keys.json", Path: "router-api-keys.json"},
+					{Key: "router-jwks.json", Path: "router-jwks.json"},
+				},
+			},
+		},
+	}, volumes[1])
+	require.Len(t, volumeMounts, 2)
+	assert.Equal(t, corev1.VolumeMount{
+		Name:      "auth-secret-volume-router",
+		MountPath: "/var/secret/router-auth/",
+	}, volumeMounts[1])
+
+	// The secret is not mounted, if the JWKS is fetched from its URL
+	ver.Auth = &models.AuthConfig{JWT: &models.JWTAuthConfig{JWKSURL: "https://auth.example.com/jwks.json"}}
+	volumes, volumeMounts = buildRouterVolumes(ver, "test-config-map", "test-secret")
+	assert.Len(t, volumes, 1)
+	assert.Len(t, volumeMounts, 1)
+}
+
+func TestBuildRouterVolumesResultLogHashKey(t *testing.T) {
+	ver := &models.RouterVersion{
+		ExperimentEngine: &models.ExperimentEngine{Type: "nop"},
+		LogConfig: &models.LogConfig{
+			ResultLoggerType: models.NopLogger,
+			Redactions: []models.ResultLogRedaction{
+				{FieldSource: expRequest.HeaderFieldSource, Field: "X-Email", Action: routerConfig.HashRedactionAction},
+			},
+			HashKeySecret: "hash-key",
+		},
+	}
+
+	volumes, volumeMounts := buildRouterVolumes(ver, "test-config-map", "test-secret")
+	require.Len(t, volumes, 2)
+	assert.Equal(t, corev1.Volume{
+		Name: "result-log-secret-volume-router",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: "test-secret",
+				Items: []corev1.KeyToPath{
+					{Key: "router-result-log-hash-key", Path: "router-result-log-hash-key"},
+				},
+			},
+		},
+	}, volumes[1])
+	require.Len(t, volumeMounts, 2)
+	assert.Equal(t, corev1.VolumeMount{
+		Name:      "result-log-secret-volume-router",
+		MountPath: "/var/secret/router-result-log/",
+	}, volumeMounts[1])
+
+	// The secret is not mounted, if no field is hashed
+	ver.LogConfig.Redactions[0].Action = routerConfig.MaskRedactionAction
+	volumes, volumeMounts = buildRouterVolumes(ver, "test-config-map", "test-secret")
+	assert.Len(t, volumes, 1)
+	assert.Len(t, volumeMounts, 1)
+}
+
+func TestBuildRouterVolumesBandit(t *testing.T) {
+	ver := &models.RouterVersion{
+		ExperimentEngine: &models.ExperimentEngine{Type: "nop"},
+		LogConfig:        &models.LogConfig{ResultLoggerType: models.NopLogger},
+		Bandit:           &models.BanditConfig{Algorithm: models.ThompsonSamplingAlgorithm, SnapshotInterval: "1m"},
+	}
+
+	volumes, volumeMounts := buildRouterVolumes(ver, "test-config-map", "test-secret")
+	require.Len(t, volumes, 2)
+	assert.Equal(t, corev1.Volume{
+		Name:         "bandit-volume",
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	}, volumes[1])
+	require.Len(t, volumeMounts, 2)
+	assert.Equal(t, corev1.VolumeMount{
+		Name:      "bandit-volume",
+		MountPath: "/app/bandit/",
+	}, volumeMounts[1])
+
+	// The volume is not mounted, if the bandit state is not saved
+	ver.Bandit.SnapshotInterval = ""
+	volumes, volumeMounts = buildRouterVolumes(ver, "test-config-map", "test-secret")
+	assert.Len(t, volumes, 1)
+	assert.Len(t, volumeMounts, 1)
+}
+
+func TestBuildPrePostProcessorEndpoint(t *testing.T) {
+	tests := map[string]struct {
+		protocol         routerConfig.Protocol
+		relativeEndpoint string
+		expected         string
+	}{
+		"http": {
+			protocol:         routerConfig.HTTP,
+			relativeEndpoint: "/echo?delay=10ms",
+			expected:         "http://test-svc-turing-enricher-1.test-project.svc.cluster.local/echo?delay=10ms",
+		},
+		"upi": {
+			protocol:         routerConfig.UPI,
+			relativeEndpoint: "/echo?delay=10ms",
+			expected:         "test-svc-turing-enricher-1.test-project.svc.cluster.local:80",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ver := &models.RouterVersion{
+				Router:   &models.Router{Name: "test-svc"},
+				Version:  1,
+				Protocol: tt.protocol,
+			}
+			got := buildPrePostProcessorEndpoint(ver, "test-project", ComponentTypes.Enricher, tt.relativeEndpoint)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestBuildFiberConfigDockerEnsembler(t *testing.T) {
+	routes := models.Routes{{ID: "control", Type: "PROXY", Endpoint: "localhost:9000", Timeout: "2s"}}
+	ensembler := &models.Ensembler{
+		Type:         models.EnsemblerDockerType,
+		DockerConfig: &models.EnsemblerDockerConfig{},
+	}
+
+	tests := map[string]struct {
+		protocol     fiberProtocol.Protocol
+		expectedType string
+	}{
+		"http": {
+			protocol:     fiberProtocol.HTTP,
+			expectedType: routerConfigTypeCombiner,
+		},
+		"grpc": {
+			protocol:     fiberProtocol.GRPC,
+			expectedType: routerConfigTypeEagerRouter,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := buildFiberConfig("test-svc", routes, ensembler, json.RawMessage("{}"), tt.protocol)
+			require.NoError(t, err)
+			switch cfg := got.(type) {
+			case *fiberConfig.CombinerConfig:
+				assert.Equal(t, tt.expectedType, cfg.Type)
+			case *fiberConfig.RouterConfig:
+				assert.Equal(t, tt.expectedType, cfg.Type)
+				assert.Equal(t, routerConfigStrategyTypeDefault, cfg.Strategy.Type)
+			default:
+				t.Fatalf("unexpected fiber config type %T", got)
+			}
+		})
+	}
+}
+
+func TestBuildTrafficSplittingFiberConfigWeighted(t *testing.T) {
+	routes := models.Routes{
+		{ID: "control", Type: "PROXY", Endpoint: "http://localhost:9000", Timeout: "2s"},
+		{ID: "canary", Type: "PROXY", Endpoint: "http://localhost:9001", Timeout: "2s"},
+	}
+	stableWeight, canaryWeight := 95, 5
+	rules := models.TrafficRules{
+		{Name: "stable", Weight: &stableWeight, Routes: []string{"control"}},
+		{Name: "canary", Weight: &canaryWeight, Routes: []string{"canary"}},
+		{Name: "default-traffic-rule", Conditions: []*router.TrafficRuleCondition{}, Routes: []string{"control"}},
+	}
+	unit := &models.TrafficSplitUnit{FieldSource: expRequest.HeaderFieldSource, Field: "X-User-Id"}
+
+	got, err := buildTrafficSplittingFiberConfig(
+		"test-svc", routes, rules, unit, nil, json.RawMessage("{}"), fiberProtocol.HTTP)
+	require.NoError(t, err)
+
+	cfg, ok := got.(*fiberConfig.RouterConfig)
+	require.True(t, ok)
+	assert.Equal(t, routerConfigTypeLazyRouter, cfg.Type)
+	assert.Equal(t, routerConfigStrategyTypeWeightedSplit, cfg.Strategy.Type)
+	require.Len(t, cfg.Routes, 3)
+
+	var strategy fiberapi.WeightedTrafficSplittingStrategy
+	require.NoError(t, json.Unmarshal(cfg.Strategy.Properties, &strategy))
+	assert.Equal(t, fiberapi.WeightedTrafficSplittingStrategy{
+		DefaultRouteID: "default-traffic-rule",
+		Unit:           &fiberapi.TrafficSplitUnit{FieldSource: expRequest.HeaderFieldSource, Field: "X-User-Id"},
+		Rules: []*fiberapi.WeightedTrafficSplittingStrategyRule{
+			{RouteID: "stable", Weight: 95},
+			{RouteID: "canary", Weight: 5},
+		},
+	}, strategy)
+
+	_, err = buildTrafficSplittingFiberConfig(
+		"test-svc", routes, rules, nil, nil, json.RawMessage("{}"), fiberProtocol.HTTP)
+	assert.EqualError(t, err, "failed to build fiber config, traffic split unit is not set")
+}
+
+func TestBuildShadowFiberConfig(t *testing.T) {
+	routes := models.Routes{
+		{ID: "control", Type: "PROXY", Endpoint: "http://localhost:9000", Timeout: "2s"},
+		{ID: "challenger", Type: "PROXY", Endpoint: "http://localhost:9001", Timeout: "2s", Shadow: true},
+	}
+	primaryRoutes, shadowRoutes := routes.SplitShadowRoutes()
+	require.Equal(t, models.Routes{routes[0]}, primaryRoutes)
+	require.Equal(t, models.Routes{routes[1]}, shadowRoutes)
+
+	primary, err := buildFiberConfig(
+		shadowPrimaryRouteID, primaryRoutes, nil, json.RawMessage("{}"), fiberProtocol.HTTP)
+	require.NoError(t, err)
+
+	got, err := buildShadowFiberConfig("test-svc", primary, shadowRoutes, fiberProtocol.HTTP)
+	require.NoError(t, err)
+
+	cfg, ok := got.(*fiberConfig.RouterConfig)
+	require.True(t, ok)
+	assert.Equal(t, "test-svc", cfg.ID)
+	assert.Equal(t, routerConfigTypeLazyRouter, cfg.Type)
+	assert.Equal(t, routerConfigStrategyTypeShadow, cfg.Strategy.Type)
+	require.Len(t, cfg.Routes, 2)
+	assert.Equal(t, primary, cfg.Routes[0])
+
+	var strategy fiberapi.ShadowRoutingStrategy
+	require.NoError(t, json.Unmarshal(cfg.Strategy.Properties, &strategy))
+	assert.Equal(t, fiberapi.ShadowRoutingStrategy{
+		PrimaryRouteID: shadowPrimaryRouteID,
+		ShadowRouteIDs: []string{"challenger"},
+	}, strategy)
+}
+
+func TestBuildFiberConfigMapBuiltinEnsembler(t *testing.T) {
+	routes := models.Routes{
+		{ID: "control", Type: "PROXY", Endpoint: "http://localhost:9000", Timeout: "2s"},
+		{ID: "treatment-a", Type: "PROXY", Endpoint: "http://localhost:9001", Timeout: "2s"},
+	}
+	ver := &models.RouterVersion{
+		Router:           &models.Router{Name: "test-svc"},
+		Version:          1,
+		Routes:           routes,
+		DefaultRouteID:   "control",
+		ExperimentEngine: &models.ExperimentEngine{Type: models.ExperimentEngineTypeNop},
+		Protocol:         routerConfig.HTTP,
+		Ensembler: &models.Ensembler{
+			Type: models.EnsemblerBuiltinType,
+			BuiltinConfig: &models.EnsemblerBuiltinConfig{
+				Strategy:   models.BuiltinWeightedAverageStrategy,
+				Weights:    map[string]float64{"control": 0.8, "treatment-a": 0.2},
+				ValuePaths: []string{"predictions.score"},
+			},
+		},
+	}
+
+	got, err := buildFiberConfigMap(ver, &mlp.Project{Name: "test-project"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `fan_in:
+  properties:
+    builtin_ensembler:
+      strategy: weighted_average
+      value_paths:
+      - predictions.score
+      weights:
+        control: 0.8
+        treatment-a: 0.2
+    default_route_id: control
+    experiment_engine: nop
+  type: fiber.EnsemblingFanIn
+id: test-svc
+routes:
+- endpoint: http://localhost:9000
+  id: control
+  protocol: HTTP
+  timeout: 2s
+  type: PROXY
+- endpoint: http://localhost:9001
+  id: treatment-a
+  protocol: HTTP
+  timeout: 2s
+  type: PROXY
+type: COMBINER
+`, got.Data)
+}
+
+func TestBuildFiberConfigMapBandit(t *testing.T) {
+	ver := &models.RouterVersion{
+		Router:  &models.Router{Name: "test-svc"},
+		Version: 1,
+		Routes: models.Routes{
+			{ID: "control", Type: "PROXY", Endpoint: "http://localhost:9000", Timeout: "2s"},
+			{ID: "treatment-a", Type: "PROXY", Endpoint: "http://localhost:9001", Timeout: "2s"},
+		},
+		DefaultRouteID:   "control",
+		ExperimentEngine: &models.ExperimentEngine{Type: models.ExperimentEngineTypeNop},
+		Protocol:         routerConfig.HTTP,
+		Bandit: &models.BanditConfig{
+			Algorithm: models.EpsilonGreedyAlgorithm,
+			Epsilon:   0.1,
+			Routes:    []string{"control", "treatment-a"},
+		},
+	}
+
+	got, err := buildFiberConfigMap(ver, &mlp.Project{Name: "test-project"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `id: test-svc
+routes:
+- endpoint: http://localhost:9000
+  id: control
+  protocol: HTTP
+  timeout: 2s
+  type: PROXY
+- endpoint: http://localhost:9001
+  id: treatment-a
+  protocol: HTTP
+  timeout: 2s
+  type: PROXY
+strategy:
+  properties:
+    algorithm: epsilon_greedy
+    default_route_id: control
+    epsilon: 0.1
+    route_ids:
+    - control
+    - treatment-a
+  type: fiber.BanditRoutingStrategy
+type: LAZY_ROUTER
+`, got.Data)
+}
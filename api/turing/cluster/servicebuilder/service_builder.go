@@ -25,6 +25,10 @@ const (
 	secretMountPath          = "/var/secret/"
 	secretMountPathRouter    = "/var/secret/router/"
 	secretMountPathExpEngine = "/var/secret/exp-engine/"
+	secretVolumeAuth         = "auth-secret-volume-router"
+	secretMountPathAuth      = "/var/secret/router-auth/"
+	secretVolumeResultLog    = "result-log-secret-volume-router"
+	secretMountPathResultLog = "/var/secret/router-result-log/"
 	// Kubernetes secret key name for usage in: router, ensembler, enricher.
 	// They will share the same Kubernetes secret for every RouterVersion deployment.
 	// Hence, the key name should be used to retrieve different credentials.
@@ -32,6 +36,11 @@ const (
 	SecretKeyNameEnsembler = "ensembler-service-account.json"
 	SecretKeyNameEnricher  = "enricher-service-account.json"
 	SecretKeyNameExpEngine = "exp-engine-service-account.json"
+	// Kubernetes secret key names of the files, that authenticate the router's callers
+	SecretKeyNameRouterAPIKeys = "router-api-keys.json"
+	SecretKeyNameRouterJWKS    = "router-jwks.json"
+	// Kubernetes secret key name of the secret key of the hashed fields of the result logs
+	SecretKeyNameRouterHashKey = "router-result-log-hash-key"
 )
 
 var ComponentTypes = struct {
@@ -213,6 +222,7 @@ func (sb *clusterSvcBuilder) NewEnricherService(
 		},
 		IsClusterLocal:               true,
 		ContainerPort:                int32(enricher.Port),
+		Protocol:                     routerVersion.Protocol,
 		MinReplicas:                  enricher.ResourceRequest.MinReplica,
 		MaxReplicas:                  enricher.ResourceRequest.MaxReplica,
 		InitialScale:                 initialScale,
@@ -299,6 +309,7 @@ func (sb *clusterSvcBuilder) NewEnsemblerService(
 		},
 		IsClusterLocal:               true,
 		ContainerPort:                int32(docker.Port),
+		Protocol:                     routerVersion.Protocol,
 		MinReplicas:                  docker.ResourceRequest.MinReplica,
 		MaxReplicas:                  docker.ResourceRequest.MaxReplica,
 		InitialScale:                 initialScale,
@@ -15,6 +15,7 @@ import (
 	"github.com/caraml-dev/turing/api/turing/cluster"
 	tu "github.com/caraml-dev/turing/api/turing/internal/testutils"
 	"github.com/caraml-dev/turing/api/turing/models"
+	routerConfig "github.com/caraml-dev/turing/engines/router/missionctl/config"
 )
 
 var testTopologySpreadConstraints = []corev1.TopologySpreadConstraint{
@@ -105,6 +106,7 @@ func TestNewEnricherService(t *testing.T) {
 				},
 				IsClusterLocal:               true,
 				ContainerPort:                8080,
+				Protocol:                     routerConfig.HTTP,
 				MinReplicas:                  1,
 				MaxReplicas:                  2,
 				InitialScale:                 &testInitialScale,
@@ -205,6 +207,7 @@ func TestNewEnsemblerService(t *testing.T) {
 				},
 				IsClusterLocal:               true,
 				ContainerPort:                8080,
+				Protocol:                     routerConfig.HTTP,
 				MinReplicas:                  2,
 				MaxReplicas:                  3,
 				AutoscalingMetric:            "concurrency",
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// AuthConfig is the configuration of the authentication of the callers of the router's prediction
+// endpoints. The callers may be authenticated with static API keys, JWTs or both.
+type AuthConfig struct {
+	// Static API keys of the callers, stored as MLP secrets
+	APIKeys []*APIKey `json:"api_keys,omitempty" validate:"omitempty,unique=Caller,dive"`
+	// Validation of the callers' JWTs
+	JWT *JWTAuthConfig `json:"jwt,omitempty" validate:"omitempty"`
+}
+
+// APIKey is a static API key, that identifies the given caller
+type APIKey struct {
+	// Identity of the caller, that uses the API key
+	Caller string `json:"caller" validate:"required"`
+	// Name of the MLP secret, that holds the API key
+	MLPSecretName string `json:"mlp_secret_name" validate:"required"`
+}
+
+// JWTAuthConfig is the configuration of the validation of the callers' JWTs, which are verified against
+// the keys of a JSON Web Key Set (JWKS), either fetched from its URL or configured inline
+type JWTAuthConfig struct {
+	// URL of the JWKS, which is refreshed periodically by the router
+	JWKSURL string `json:"jwks_url,omitempty"`
+	// JWKS itself, which is mounted in the router as a file
+	JWKS json.RawMessage `json:"jwks,omitempty"`
+	// Expected issuer (iss claim) of the JWTs. The issuer is not checked if not set.
+	Issuer string `json:"issuer,omitempty"`
+	// Expected audience (aud claim) of the JWTs. The audience is not checked if not set.
+	Audience string `json:"audience,omitempty"`
+	// Claims, which the JWTs must have with the given values
+	RequiredClaims map[string]string `json:"required_claims,omitempty"`
+	// Claim, that identifies the caller. Defaults to the sub claim in the router.
+	IdentityClaim string `json:"identity_claim,omitempty"`
+}
+
+func (c AuthConfig) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+func (c *AuthConfig) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, c)
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// BanditAlgorithm is the algorithm used by the multi-armed bandit to trade off exploring
+// the routes and exploiting the best route
+type BanditAlgorithm string
+
+const (
+	// ThompsonSamplingAlgorithm selects the route with the highest reward sampled from the
+	// Beta distribution of its rewards
+	ThompsonSamplingAlgorithm BanditAlgorithm = "thompson_sampling"
+	// EpsilonGreedyAlgorithm selects a random route with the probability epsilon, and the route
+	// with the highest mean reward otherwise
+	EpsilonGreedyAlgorithm BanditAlgorithm = "epsilon_greedy"
+)
+
+// BanditConfig is the configuration of the multi-armed bandit, which selects the route of each
+// request and learns the best route from the rewards sent to the router's feedback endpoint
+type BanditConfig struct {
+	Algorithm BanditAlgorithm `json:"algorithm" validate:"required,oneof=thompson_sampling epsilon_greedy"`
+	// Probability of exploring a random route, for the epsilon-greedy algorithm
+	Epsilon float64 `json:"epsilon,omitempty" validate:"gte=0,lte=1"`
+	// IDs of the routes selected by the bandit. All the routes are selected, if not set.
+	Routes []string `json:"routes,omitempty"`
+	// Interval at which each replica of the router saves its bandit state to its volume, as a valid
+	// duration string, so that the state is restored when the replica restarts. Not saved, if not set.
+	SnapshotInterval string `json:"snapshot_interval,omitempty"`
+}
+
+func (c BanditConfig) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+func (c *BanditConfig) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, c)
+}
@@ -13,6 +13,7 @@ type Ensembler struct {
 	StandardConfig *EnsemblerStandardConfig `json:"standard_config"` // Ensembler config when Type is "standard"
 	DockerConfig   *EnsemblerDockerConfig   `json:"docker_config"`   // Ensembler config when Type is "docker"
 	PyfuncConfig   *EnsemblerPyfuncConfig   `json:"pyfunc_config"`   // Ensembler config when Type is "pyfunc"
+	BuiltinConfig  *EnsemblerBuiltinConfig  `json:"builtin_config"`  // Ensembler config when Type is "builtin"
 }
 
 // TableName returns the name of a table, where GORM should store/retrieve
@@ -29,6 +30,7 @@ const (
 	EnsemblerStandardType EnsemblerType = "standard"
 	EnsemblerDockerType   EnsemblerType = "docker"
 	EnsemblerPyFuncType   EnsemblerType = "pyfunc"
+	EnsemblerBuiltinType  EnsemblerType = "builtin"
 )
 
 type EnsemblerStandardConfig struct {
@@ -77,6 +79,35 @@ type EnsemblerPyfuncConfig struct {
 	Env EnvVars `json:"env" validate:"required"`
 }
 
+// EnsemblerBuiltinStrategy is the logic used by a built-in ensembler to combine the route responses
+type EnsemblerBuiltinStrategy string
+
+const (
+	// BuiltinWeightedAverageStrategy averages the numeric values at the value paths of the route responses
+	BuiltinWeightedAverageStrategy EnsemblerBuiltinStrategy = "weighted_average"
+	// BuiltinMajorityVoteStrategy selects the response with the most common label among the route responses
+	BuiltinMajorityVoteStrategy EnsemblerBuiltinStrategy = "majority_vote"
+	// BuiltinFirstSuccessStrategy selects the first successful route response, in the order of priority
+	BuiltinFirstSuccessStrategy EnsemblerBuiltinStrategy = "first_success"
+)
+
+// EnsemblerBuiltinConfig is the configuration of a built-in ensembler, which combines the route
+// responses within the router, without an ensembler service
+type EnsemblerBuiltinConfig struct {
+	Strategy EnsemblerBuiltinStrategy `json:"strategy" validate:"required"`
+	// Weights of the routes, by route ID. If not set, all routes have the same weight.
+	Weights map[string]float64 `json:"weights,omitempty" validate:"omitempty,dive,gte=0"`
+	// JSON paths of the numeric values to average, e.g. "predictions.score", for the weighted average
+	ValuePaths []string `json:"value_paths,omitempty" validate:"omitempty,dive,required"`
+	// JSON path of the label to vote on, for the majority vote
+	LabelPath string `json:"label_path,omitempty"`
+	// IDs of the routes, in the order in which their responses are preferred. The default route, followed
+	// by the other routes in alphabetical order, are preferred after the listed routes.
+	RoutePriority []string `json:"route_priority,omitempty"`
+	// JSON path of the route weights in the treatment config, which override the configured weights
+	TreatmentWeightsPath string `json:"treatment_weights_path,omitempty"`
+}
+
 type ExperimentMapping struct {
 	Experiment string `json:"experiment" validate:"required"` // Experiment name from the experiment engine
 	Treatment  string `json:"treatment" validate:"required"`  // Treatment name for the experiment
@@ -125,3 +156,15 @@ func (c EnsemblerPyfuncConfig) Value() (driver.Value, error) {
 func (c *EnsemblerPyfuncConfig) Scan(value interface{}) error {
 	return json.Unmarshal(value.([]byte), &c)
 }
+
+// Value implements sql.driver.Valuer interface so database tools like go-orm knows how to serialize the struct object
+// for storage in the database
+func (c EnsemblerBuiltinConfig) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// Scan implements sql.Scanner interface so database tools like go-orm knows how to de-serialize the struct object
+// from the database
+func (c *EnsemblerBuiltinConfig) Scan(value interface{}) error {
+	return json.Unmarshal(value.([]byte), &c)
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// FallbackResponse is the static response, that the router serves in place of the error, when its routes,
+// ensembler or experiment engine fail. The fallback response of the traffic rule, that the request matched,
+// takes precedence over the router's fallback response.
+type FallbackResponse struct {
+	// HTTP status of the response. Defaults to 200 OK. Not supported by the UPI routers, which serve
+	// the fallback response with the OK status.
+	Status int `json:"status,omitempty" validate:"omitempty,min=200,max=599"`
+	// JSON payload of the response or, for the UPI routers, the JSON representation of the
+	// PredictValuesResponse
+	Body json.RawMessage `json:"body" validate:"required"`
+}
+
+func (r FallbackResponse) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+func (r *FallbackResponse) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, r)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
 	routerConfig "github.com/caraml-dev/turing/engines/router/missionctl/config"
 )
 
@@ -54,6 +55,25 @@ type KafkaConfig struct {
 	SerializationFormat SerializationFormat `json:"serialization_format"`
 }
 
+// ResultLogSamplingUnit is the field of the request, which value decides whether its result is logged,
+// so that the results of the requests with the same value are either all logged or not.
+type ResultLogSamplingUnit struct {
+	FieldSource request.FieldSource `json:"field_source" validate:"required"`
+	Field       string              `json:"field" validate:"required"`
+}
+
+// ResultLogRedaction is a field, which value is redacted before the result is logged.
+type ResultLogRedaction struct {
+	// The header field source redacts the header of the given name, and the payload field source
+	// redacts the field at the given JSON path, of the request and of the responses
+	FieldSource request.FieldSource `json:"field_source" validate:"required,oneof=header payload"`
+	Field       string              `json:"field" validate:"required"`
+	// The hash action replaces the value with its HMAC-SHA256, keyed by the HashKeySecret of the
+	// LogConfig, rather than with its plain hash, so that the values can't be looked up by hashing
+	// the candidate values
+	Action routerConfig.RedactionAction `json:"action" validate:"required,oneof=drop hash mask"`
+}
+
 // LogConfig contains all log configuration necessary for a deployment
 // of the Turing Router.
 type LogConfig struct {
@@ -67,12 +87,56 @@ type LogConfig struct {
 	JaegerEnabled bool `json:"jaeger_enabled"`
 	// Result Logger type. The associated config must not be null.
 	ResultLoggerType ResultLogger `json:"result_logger_type"`
+	// Result Logger types, to log the results to several destinations at once. If set, the
+	// ResultLoggerType is one of them, and the associated configs must not be null.
+	ResultLoggerTypes []ResultLogger `json:"result_logger_types,omitempty"`
 	// Configuration necessary to log results to BigQuery. Cannot be empty if
 	// ResultLoggerType is set to "bigquery".
 	BigQueryConfig *BigQueryConfig `json:"bigquery_config,omitempty"`
 	// Configuration necessary to log results to kafka. Cannot be empty if
 	// ResultLoggerType is set to "kafka"
 	KafkaConfig *KafkaConfig `json:"kafka_config,omitempty"`
+	// Allow the clients to request the debug trace of a request, with the Turing-Debug
+	// request header. Defaults to false.
+	DebugResponseEnabled bool `json:"debug_response_enabled,omitempty"`
+	// Fraction of the requests, which results are logged. All of the results are logged if not set.
+	SamplingRate *float64 `json:"sampling_rate,omitempty"`
+	// Field of the request, by which the results are sampled deterministically. The results are
+	// sampled at random if not set.
+	SamplingUnit *ResultLogSamplingUnit `json:"sampling_unit,omitempty"`
+	// Fields, which values are redacted in the logged request and responses
+	Redactions []ResultLogRedaction `json:"redactions,omitempty"`
+	// MLP secret name of the secret key of the hashed fields. Required if any of the Redactions
+	// hashes its field.
+	HashKeySecret string `json:"hash_key_secret,omitempty"`
+}
+
+// HasHashRedaction returns true, if any of the redactions hashes its field
+func (l *LogConfig) HasHashRedaction() bool {
+	for _, redaction := range l.Redactions {
+		if redaction.Action == routerConfig.HashRedactionAction {
+			return true
+		}
+	}
+	return false
+}
+
+// ResultLoggers returns the result loggers, that the results are logged to
+func (l *LogConfig) ResultLoggers() []ResultLogger {
+	if len(l.ResultLoggerTypes) > 0 {
+		return l.ResultLoggerTypes
+	}
+	return []ResultLogger{l.ResultLoggerType}
+}
+
+// HasResultLogger returns true, if the results are logged to the given result logger
+func (l *LogConfig) HasResultLogger(loggerType ResultLogger) bool {
+	for _, resultLogger := range l.ResultLoggers() {
+		if resultLogger == loggerType {
+			return true
+		}
+	}
+	return false
 }
 
 func (l LogConfig) Value() (driver.Value, error) {
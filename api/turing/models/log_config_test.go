@@ -75,6 +75,41 @@ func TestLogConfigValue(t *testing.T) {
 				}
 			}`),
 		},
+		"multiple": {
+			logConfig: LogConfig{
+				LogLevel:          routerConfig.InfoLevel,
+				ResultLoggerType:  KafkaLogger,
+				ResultLoggerTypes: []ResultLogger{KafkaLogger, BigQueryLogger},
+				KafkaConfig: &KafkaConfig{
+					Brokers:             "test-brokers",
+					Topic:               "test-topic",
+					SerializationFormat: JSONSerializationFormat,
+				},
+				BigQueryConfig: &BigQueryConfig{
+					Table:                "test-table",
+					ServiceAccountSecret: "svc-acct-secret",
+					BatchLoad:            true,
+				},
+			},
+			expected: string(`{
+				"log_level": "INFO",
+				"custom_metrics_enabled": false,
+				"fiber_debug_log_enabled": false,
+				"jaeger_enabled": false,
+				"result_logger_type": "kafka",
+				"result_logger_types": ["kafka", "bigquery"],
+				"bigquery_config": {
+					"table": "test-table",
+					"service_account_secret": "svc-acct-secret",
+					"batch_load": true
+				},
+				"kafka_config": {
+					"brokers": "test-brokers",
+					"topic": "test-topic",
+					"serialization_format": "json"
+				}
+			}`),
+		},
 	}
 
 	for name, data := range tests {
@@ -135,3 +170,19 @@ func TestLogConfigScan(t *testing.T) {
 		})
 	}
 }
+
+func TestLogConfigResultLoggers(t *testing.T) {
+	logConfig := &LogConfig{ResultLoggerType: BigQueryLogger}
+	assert.Equal(t, []ResultLogger{BigQueryLogger}, logConfig.ResultLoggers())
+	assert.True(t, logConfig.HasResultLogger(BigQueryLogger))
+	assert.False(t, logConfig.HasResultLogger(KafkaLogger))
+
+	// The result logger types take precedence over the single result logger type
+	logConfig = &LogConfig{
+		ResultLoggerType:  KafkaLogger,
+		ResultLoggerTypes: []ResultLogger{KafkaLogger, BigQueryLogger},
+	}
+	assert.Equal(t, []ResultLogger{KafkaLogger, BigQueryLogger}, logConfig.ResultLoggers())
+	assert.True(t, logConfig.HasResultLogger(BigQueryLogger))
+	assert.False(t, logConfig.HasResultLogger(UPILogger))
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"github.com/caraml-dev/turing/engines/router"
+)
+
+// ProcessingStages is the ordered list of the pre / post-processing stages of the router. The stages of each
+// phase run in the configured order, each one receiving the output of the previous one.
+type ProcessingStages []*router.ProcessingStage
+
+func (s ProcessingStages) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *ProcessingStages) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &s)
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+)
+
+// RateLimitConfig is the configuration of the router's admission control, which limits the request rate
+// and the number of in-flight requests of each client. The clients are identified by the value of the
+// key field of their requests, and the requests without the key field share the limits of a single client.
+type RateLimitConfig struct {
+	// Source and name of the field of the request, which value identifies the client
+	FieldSource request.FieldSource `json:"field_source" validate:"required"`
+	Field       string              `json:"field" validate:"required"`
+	// Sustained number of requests per second of each client. The request rate is not limited if not set.
+	RequestsPerSecond float64 `json:"requests_per_second,omitempty" validate:"gte=0"`
+	// Number of requests, that each client can send at once above the sustained rate.
+	// Defaults to the requests per second, rounded up.
+	Burst int `json:"burst,omitempty" validate:"gte=0"`
+	// Maximum number of concurrent requests of each client. The concurrency is not limited if not set.
+	MaxInFlight int `json:"max_in_flight,omitempty" validate:"gte=0"`
+	// Keys of the clients, which rejected requests are counted by their own key in the router's metrics.
+	// The rejected requests of the other clients are counted together.
+	MetricKeys []string `json:"metric_keys,omitempty"`
+}
+
+func (c RateLimitConfig) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+func (c *RateLimitConfig) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, c)
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+)
+
+// ResponseCacheConfig is the configuration of the router's in-process response cache.
+// The responses are cached by the values of the key fields of the request, so the cache
+// should only be configured if the response is deterministic for these values.
+type ResponseCacheConfig struct {
+	// Maximum number of the responses to cache. The least recently used responses are evicted first.
+	MaxEntries int `json:"max_entries" validate:"required,min=1"`
+	// Duration for which a cached response is valid, as a valid quantity string
+	TTL string `json:"ttl" validate:"required"`
+	// Fields of the request, which values make up the cache key
+	KeyFields []ResponseCacheKeyField `json:"key_fields" validate:"required,dive"`
+}
+
+// ResponseCacheKeyField is a field of the request, which value is part of the response cache key
+type ResponseCacheKeyField struct {
+	FieldSource request.FieldSource `json:"field_source" validate:"required"`
+	Field       string              `json:"field" validate:"required"`
+}
+
+func (c ResponseCacheConfig) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+func (c *ResponseCacheConfig) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, c)
+}
@@ -43,6 +43,10 @@ type RouterVersion struct {
 	DefaultTrafficRule *DefaultTrafficRule `json:"default_traffic_rule,omitempty"`
 	// Rules for activating some routes based on request conditions.
 	TrafficRules TrafficRules `json:"rules,omitempty"`
+	// Request field used to split the traffic between the weighted traffic rules.
+	TrafficSplitUnit *TrafficSplitUnit `json:"traffic_split_unit,omitempty"`
+	// Multi-armed bandit, which selects the route of each request, instead of the experiment engine.
+	Bandit *BanditConfig `json:"bandit,omitempty"`
 	// Configuration for the experiment engine queried by the router.
 	ExperimentEngine *ExperimentEngine `json:"experiment_engine"`
 	// Resource requests for deployment
@@ -55,6 +59,17 @@ type RouterVersion struct {
 	Protocol routerConfig.Protocol `json:"protocol"`
 	// Logging configuration for the router
 	LogConfig *LogConfig `json:"log_config"`
+	// Configuration of the router's response cache. Responses are not cached if not set.
+	ResponseCache *ResponseCacheConfig `json:"response_cache,omitempty"`
+	// Pre / post-processing stages, that run before the router and after the ensembler respectively
+	ProcessingStages ProcessingStages `json:"processing_stages,omitempty"`
+	// Authentication of the router's callers. The callers are not authenticated if not set.
+	Auth *AuthConfig `json:"auth,omitempty"`
+	// Rate limits of the router's clients. The requests are not limited if not set.
+	RateLimit *RateLimitConfig `json:"rate_limit,omitempty"`
+	// Static response, that is served when the routes, ensembler or experiment engine fail, unless the
+	// matched traffic rule has its own fallback response. The errors are returned if not set.
+	FallbackResponse *FallbackResponse `json:"fallback_response,omitempty"`
 
 	// The enricher used by the router
 	EnricherID sql.NullInt32 `json:"-"`
@@ -0,0 +1,294 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RedactedValue replaces the values of the sensitive fields in the router version diffs
+const RedactedValue = "<redacted>"
+
+// routerVersionDiffFields are the fields of the router versions that are compared, by their JSON names.
+// The metadata of the router versions, e.g. their status or timestamps, is left out.
+var routerVersionDiffFields = []string{
+	"routes",
+	"default_route_id",
+	"default_traffic_rule",
+	"rules",
+	"traffic_split_unit",
+	"bandit",
+	"experiment_engine",
+	"resource_request",
+	"autoscaling_policy",
+	"timeout",
+	"protocol",
+	"log_config",
+	"response_cache",
+	"processing_stages",
+	"auth",
+	"rate_limit",
+	"fallback_response",
+	"enricher",
+	"ensembler",
+}
+
+// entityMetadataFields are the fields of the persisted entities, that are not part of their configuration
+var entityMetadataFields = []string{"id", "created_at", "updated_at"}
+
+// sensitiveFieldNames are the parts of the names of the fields, which values are redacted in the diffs.
+// The experiment engine configs are free-form, so the fields are matched by name, wherever they are.
+var sensitiveFieldNames = []string{"token", "secret", "password", "key", "credential"}
+
+// nonSensitiveFields are the fields of the router config, which names match the sensitiveFieldNames, but
+// which values are not sensitive
+var nonSensitiveFields = map[string]bool{
+	"result_log_key": true,
+	"key_fields":     true,
+	"metric_keys":    true,
+}
+
+// sensitivePathPattern matches the paths of the other fields, which values are redacted in the diffs,
+// i.e. the values of the environment variables of the enricher and the ensembler
+var sensitivePathPattern = regexp.MustCompile(`(^|\.)env\[[^\]]+\]\.value$`)
+
+// arrayElementKeys are the fields that identify the elements of the arrays, in order of precedence, so that
+// e.g. the routes are compared by their IDs, regardless of their order
+var arrayElementKeys = []string{"id", "name"}
+
+// FieldChangeType is the type of the change of a field between two router versions
+type FieldChangeType string
+
+const (
+	FieldAdded    FieldChangeType = "added"
+	FieldRemoved  FieldChangeType = "removed"
+	FieldModified FieldChangeType = "modified"
+)
+
+// FieldChange is the change of a field between two router versions
+type FieldChange struct {
+	// Path of the field, e.g. routes[id=route-a].timeout
+	Path string `json:"path"`
+	// Type of the change
+	Type FieldChangeType `json:"type"`
+	// Value of the field in the original router version, not set if the field was added
+	From interface{} `json:"from,omitempty"`
+	// Value of the field in the new router version, not set if the field was removed
+	To interface{} `json:"to,omitempty"`
+}
+
+// RouterVersionDiff is the field-level diff between two versions of a router
+type RouterVersionDiff struct {
+	From    uint          `json:"from"`
+	To      uint          `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// DiffRouterVersions compares the configuration of the given router versions, field by field. The values of
+// the sensitive fields are redacted.
+func DiffRouterVersions(from *RouterVersion, to *RouterVersion) (*RouterVersionDiff, error) {
+	fromFields, err := routerVersionDiffValues(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := routerVersionDiffValues(to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &RouterVersionDiff{
+		From:    from.Version,
+		To:      to.Version,
+		Changes: []FieldChange{},
+	}
+	for _, field := range routerVersionDiffFields {
+		diff.Changes = append(diff.Changes, diffValues(field, fromFields[field], toFields[field], false)...)
+	}
+	return diff, nil
+}
+
+// routerVersionDiffValues returns the JSON values of the compared fields of the router version
+func routerVersionDiffValues(routerVersion *RouterVersion) (map[string]interface{}, error) {
+	data, err := json.Marshal(routerVersion)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	for _, field := range []string{"enricher", "ensembler"} {
+		if entity, ok := values[field].(map[string]interface{}); ok {
+			for _, metadataField := range entityMetadataFields {
+				delete(entity, metadataField)
+			}
+		}
+	}
+	return values, nil
+}
+
+func diffValues(path string, from interface{}, to interface{}, sensitive bool) []FieldChange {
+	switch {
+	case reflect.DeepEqual(from, to):
+		return nil
+	case from == nil:
+		return []FieldChange{{Path: path, Type: FieldAdded, To: redact(path, to, sensitive)}}
+	case to == nil:
+		return []FieldChange{{Path: path, Type: FieldRemoved, From: redact(path, from, sensitive)}}
+	}
+
+	switch fromValue := from.(type) {
+	case map[string]interface{}:
+		if toValue, ok := to.(map[string]interface{}); ok {
+			return diffObjects(path, fromValue, toValue, sensitive)
+		}
+	case []interface{}:
+		if toValue, ok := to.([]interface{}); ok {
+			return diffArrays(path, fromValue, toValue, sensitive)
+		}
+	}
+	return []FieldChange{{
+		Path: path,
+		Type: FieldModified,
+		From: redact(path, from, sensitive),
+		To:   redact(path, to, sensitive),
+	}}
+}
+
+func diffObjects(path string, from map[string]interface{}, to map[string]interface{}, sensitive bool) []FieldChange {
+	keys := map[string]bool{}
+	for key := range from {
+		keys[key] = true
+	}
+	for key := range to {
+		keys[key] = true
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	var changes []FieldChange
+	for _, key := range sortedKeys {
+		fieldPath := path + "." + key
+		changes = append(changes,
+			diffValues(fieldPath, from[key], to[key], sensitive || isSensitiveField(fieldPath, key))...)
+	}
+	return changes
+}
+
+func diffArrays(path string, from []interface{}, to []interface{}, sensitive bool) []FieldChange {
+	elementKey, ok := getArrayElementKey(from, to)
+	if !ok {
+		// Compare the elements by their positions
+		var changes []FieldChange
+		for i := 0; i < len(from) || i < len(to); i++ {
+			var fromElement, toElement interface{}
+			if i < len(from) {
+				fromElement = from[i]
+			}
+			if i < len(to) {
+				toElement = to[i]
+			}
+			changes = append(changes, diffValues(fmt.Sprintf("%s[%d]", path, i), fromElement, toElement, sensitive)...)
+		}
+		return changes
+	}
+
+	// Compare the elements by their keys, in the order of the original array, followed by the added elements
+	fromElements, toElements := indexArray(from, elementKey), indexArray(to, elementKey)
+	var keys []string
+	for _, element := range from {
+		keys = append(keys, element.(map[string]interface{})[elementKey].(string))
+	}
+	for _, element := range to {
+		if key := element.(map[string]interface{})[elementKey].(string); fromElements[key] == nil {
+			keys = append(keys, key)
+		}
+	}
+
+	var changes []FieldChange
+	for _, key := range keys {
+		elementPath := fmt.Sprintf("%s[%s=%s]", path, elementKey, key)
+		changes = append(changes, diffValues(elementPath, fromElements[key], toElements[key], sensitive)...)
+	}
+	return changes
+}
+
+// getArrayElementKey returns the field that uniquely identifies the elements of both arrays, if any
+func getArrayElementKey(arrays ...[]interface{}) (string, bool) {
+	for _, key := range arrayElementKeys {
+		if isArrayElementKey(key, arrays...) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func isArrayElementKey(key string, arrays ...[]interface{}) bool {
+	for _, array := range arrays {
+		seen := map[string]bool{}
+		for _, element := range array {
+			object, ok := element.(map[string]interface{})
+			if !ok {
+				return false
+			}
+			value, ok := object[key].(string)
+			if !ok || value == "" || seen[value] {
+				return false
+			}
+			seen[value] = true
+		}
+	}
+	return true
+}
+
+func indexArray(array []interface{}, key string) map[string]interface{} {
+	index := make(map[string]interface{}, len(array))
+	for _, element := range array {
+		index[element.(map[string]interface{})[key].(string)] = element
+	}
+	return index
+}
+
+// isSensitiveField returns whether the value of the field, with the given path and name, is redacted
+func isSensitiveField(path string, name string) bool {
+	name = strings.ToLower(name)
+	if nonSensitiveFields[name] {
+		return false
+	}
+	for _, sensitiveName := range sensitiveFieldNames {
+		if strings.Contains(name, sensitiveName) {
+			return true
+		}
+	}
+	return sensitivePathPattern.MatchString(path)
+}
+
+// redact returns the given value, with the given path, with the values of its sensitive fields redacted
+func redact(path string, value interface{}, sensitive bool) interface{} {
+	if sensitive {
+		return RedactedValue
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, fieldValue := range v {
+			fieldPath := path + "." + key
+			redacted[key] = redact(fieldPath, fieldValue, isSensitiveField(fieldPath, key))
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, element := range v {
+			redacted[i] = redact(fmt.Sprintf("%s[%d]", path, i), element, false)
+		}
+		return redacted
+	}
+	return value
+}
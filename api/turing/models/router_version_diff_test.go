@@ -0,0 +1,147 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffRouterVersions(t *testing.T) {
+	routeA := &Route{ID: "route-a", Type: "PROXY", Endpoint: "http://example.com/a", Timeout: "10ms"}
+	routeB := &Route{ID: "route-b", Type: "PROXY", Endpoint: "http://example.com/b", Timeout: "10ms"}
+
+	from := &RouterVersion{
+		Model:          Model{ID: 1, CreatedAt: time.Unix(1, 0)},
+		Version:        1,
+		Status:         RouterVersionStatusDeployed,
+		Routes:         Routes{routeA, routeB},
+		DefaultRouteID: "route-a",
+		ExperimentEngine: &ExperimentEngine{
+			Type:   "standard",
+			Config: json.RawMessage(`{"client":{"id":"1","username":"client","passkey":"encrypted-1"}}`),
+		},
+		Timeout: "100ms",
+		LogConfig: &LogConfig{
+			ResultLoggerType: NopLogger,
+		},
+		Enricher: &Enricher{
+			Model: Model{ID: 1},
+			Image: "enricher:1",
+			Env:   EnvVars{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}},
+		},
+	}
+	to := &RouterVersion{
+		Model:   Model{ID: 2, CreatedAt: time.Unix(2, 0)},
+		Version: 2,
+		Status:  RouterVersionStatusPending,
+		Routes: Routes{
+			{ID: "route-b", Type: "PROXY", Endpoint: "http://example.com/b", Timeout: "20ms"},
+			{ID: "route-c", Type: "PROXY", Endpoint: "http://example.com/c", Timeout: "10ms"},
+		},
+		DefaultRouteID: "route-b",
+		ExperimentEngine: &ExperimentEngine{
+			Type:   "standard",
+			Config: json.RawMessage(`{"client":{"id":"1","username":"client","passkey":"encrypted-2"}}`),
+		},
+		Timeout: "100ms",
+		LogConfig: &LogConfig{
+			ResultLoggerType: NopLogger,
+		},
+		Enricher: &Enricher{
+			Model: Model{ID: 2},
+			Image: "enricher:1",
+			Env:   EnvVars{{Name: "B", Value: "3"}, {Name: "A", Value: "1"}},
+		},
+	}
+
+	diff, err := DiffRouterVersions(from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, &RouterVersionDiff{
+		From: 1,
+		To:   2,
+		Changes: []FieldChange{
+			{
+				Path: "routes[id=route-a]",
+				Type: FieldRemoved,
+				From: map[string]interface{}{
+					"id":          "route-a",
+					"type":        "PROXY",
+					"endpoint":    "http://example.com/a",
+					"timeout":     "10ms",
+					"annotations": nil,
+				},
+			},
+			{Path: "routes[id=route-b].timeout", Type: FieldModified, From: "10ms", To: "20ms"},
+			{
+				Path: "routes[id=route-c]",
+				Type: FieldAdded,
+				To: map[string]interface{}{
+					"id":          "route-c",
+					"type":        "PROXY",
+					"endpoint":    "http://example.com/c",
+					"timeout":     "10ms",
+					"annotations": nil,
+				},
+			},
+			{Path: "default_route_id", Type: FieldModified, From: "route-a", To: "route-b"},
+			{
+				Path: "experiment_engine.config.client.passkey",
+				Type: FieldModified,
+				From: RedactedValue,
+				To:   RedactedValue,
+			},
+			{Path: "enricher.env[name=B].value", Type: FieldModified, From: RedactedValue, To: RedactedValue},
+		},
+	}, diff)
+
+	// The fields with sensitive names are redacted, regardless of their case
+	diff, err = DiffRouterVersions(
+		&RouterVersion{ExperimentEngine: &ExperimentEngine{Config: json.RawMessage(`{}`)}},
+		&RouterVersion{ExperimentEngine: &ExperimentEngine{Config: json.RawMessage(`{
+			"access_token": "a",
+			"Client_Secret": "b",
+			"secret": "c",
+			"credentials": {"user": "d"},
+			"result_log_key": "e"
+		}`)}},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []FieldChange{
+		{Path: "experiment_engine.config.Client_Secret", Type: FieldAdded, To: RedactedValue},
+		{Path: "experiment_engine.config.access_token", Type: FieldAdded, To: RedactedValue},
+		{Path: "experiment_engine.config.credentials", Type: FieldAdded, To: RedactedValue},
+		{Path: "experiment_engine.config.result_log_key", Type: FieldAdded, To: "e"},
+		{Path: "experiment_engine.config.secret", Type: FieldAdded, To: RedactedValue},
+	}, diff.Changes)
+
+	// Identical router versions
+	diff, err = DiffRouterVersions(from, from)
+	assert.NoError(t, err)
+	assert.Equal(t, &RouterVersionDiff{From: 1, To: 1, Changes: []FieldChange{}}, diff)
+
+	// Sensitive fields of the added values are redacted, including the values of the environment variables
+	diff, err = DiffRouterVersions(&RouterVersion{Version: 1}, from)
+	assert.NoError(t, err)
+	for _, change := range diff.Changes {
+		if change.Path == "enricher" {
+			assert.Equal(t, []interface{}{
+				map[string]interface{}{"name": "A", "value": RedactedValue},
+				map[string]interface{}{"name": "B", "value": RedactedValue},
+			}, change.To.(map[string]interface{})["env"])
+		}
+		if change.Path == "experiment_engine" {
+			assert.Equal(t, map[string]interface{}{
+				"type": "standard",
+				"config": map[string]interface{}{
+					"client": map[string]interface{}{
+						"id":       "1",
+						"username": "client",
+						"passkey":  RedactedValue,
+					},
+				},
+			}, change.To)
+		}
+	}
+}
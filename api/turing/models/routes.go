@@ -9,6 +9,8 @@ import (
 
 	fiberConfig "github.com/gojek/fiber/config"
 	fiberProtocol "github.com/gojek/fiber/protocol"
+
+	"github.com/caraml-dev/turing/engines/router"
 )
 
 // Route maps onto the fiber.Component.
@@ -25,6 +27,15 @@ type Route struct {
 	Timeout string `json:"timeout"`
 	// Grpc ServiceMethod name
 	ServiceMethod string `json:"service_method,omitempty"`
+	// Shadow routes receive a copy of every request, but their responses are
+	// only logged and never returned to the client
+	Shadow bool `json:"shadow,omitempty"`
+	// RetryPolicy (optional) configures the retries of the failed requests to the route
+	RetryPolicy *router.RetryPolicy `json:"retry_policy,omitempty"`
+	// CircuitBreaker (optional) stops sending the requests to the route, while it keeps failing
+	CircuitBreaker *router.CircuitBreakerConfig `json:"circuit_breaker,omitempty"`
+	// HedgingPolicy (optional) sends a duplicate request to the route, if it's slow to respond
+	HedgingPolicy *router.HedgingPolicy `json:"hedging_policy,omitempty"`
 }
 
 type Routes []*Route
@@ -42,6 +53,19 @@ func (r *Routes) Scan(value interface{}) error {
 	return json.Unmarshal(b, &r)
 }
 
+// SplitShadowRoutes returns the primary routes and the shadow routes, separately
+func (r Routes) SplitShadowRoutes() (Routes, Routes) {
+	primary, shadow := Routes{}, Routes{}
+	for _, route := range r {
+		if route.Shadow {
+			shadow = append(shadow, route)
+		} else {
+			primary = append(primary, route)
+		}
+	}
+	return primary, shadow
+}
+
 // ToFiberRoutes converts routes to a type compatible with Fiber's config
 func (r *Routes) ToFiberRoutes(protocol fiberProtocol.Protocol) (*fiberConfig.Routes, error) {
 	routes := make([]fiberConfig.Config, 0, len(*r))
@@ -54,7 +78,7 @@ func (r *Routes) ToFiberRoutes(protocol fiberProtocol.Protocol) (*fiberConfig.Ro
 			protocol != fiberProtocol.GRPC {
 			return nil, fmt.Errorf("invalid route protocol for %s", route.ID)
 		}
-		routes = append(routes, &fiberConfig.ProxyConfig{
+		proxyConfig := &fiberConfig.ProxyConfig{
 			ComponentConfig: fiberConfig.ComponentConfig{
 				ID:   route.ID,
 				Type: route.Type,
@@ -65,7 +89,19 @@ func (r *Routes) ToFiberRoutes(protocol fiberProtocol.Protocol) (*fiberConfig.Ro
 			GrpcConfig: fiberConfig.GrpcConfig{
 				ServiceMethod: route.ServiceMethod,
 			},
-		})
+		}
+		// The retry policy, the circuit breaker and the hedging policy are not supported by Fiber,
+		// and are applied by the Turing router instead
+		if route.RetryPolicy != nil || route.CircuitBreaker != nil || route.HedgingPolicy != nil {
+			routes = append(routes, &router.ProxyConfig{
+				ProxyConfig:    proxyConfig,
+				RetryPolicy:    route.RetryPolicy,
+				CircuitBreaker: route.CircuitBreaker,
+				HedgingPolicy:  route.HedgingPolicy,
+			})
+		} else {
+			routes = append(routes, proxyConfig)
+		}
 	}
 	return (*fiberConfig.Routes)(&routes), nil
 }
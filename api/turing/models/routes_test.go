@@ -7,6 +7,8 @@ import (
 	fiberConfig "github.com/gojek/fiber/config"
 	fiberProtocol "github.com/gojek/fiber/protocol"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/caraml-dev/turing/engines/router"
 )
 
 var testHTTPRoutes = Routes{
@@ -143,6 +145,82 @@ func TestRoutesToFiberRoutes(t *testing.T) {
 			},
 			success: true,
 		},
+		"success | retry policy and circuit breaker": {
+			routes: Routes{
+				{
+					ID:       "test-id",
+					Type:     "PROXY",
+					Endpoint: "test-endpoint",
+					Timeout:  "2s",
+					RetryPolicy: &router.RetryPolicy{
+						MaxAttempts: 3,
+						Backoff:     fiberConfig.Duration(time.Millisecond * 10),
+					},
+					CircuitBreaker: &router.CircuitBreakerConfig{
+						FailureThreshold: 5,
+						OpenInterval:     fiberConfig.Duration(time.Second * 30),
+						HalfOpenProbes:   1,
+					},
+				},
+			},
+			protocol: fiberProtocol.HTTP,
+			fiberRoutes: fiberConfig.Routes{
+				&router.ProxyConfig{
+					ProxyConfig: &fiberConfig.ProxyConfig{
+						ComponentConfig: fiberConfig.ComponentConfig{
+							ID:   "test-id",
+							Type: "PROXY",
+						},
+						Endpoint: "test-endpoint",
+						Timeout:  fiberConfig.Duration(time.Second * 2),
+						Protocol: fiberProtocol.HTTP,
+					},
+					RetryPolicy: &router.RetryPolicy{
+						MaxAttempts: 3,
+						Backoff:     fiberConfig.Duration(time.Millisecond * 10),
+					},
+					CircuitBreaker: &router.CircuitBreakerConfig{
+						FailureThreshold: 5,
+						OpenInterval:     fiberConfig.Duration(time.Second * 30),
+						HalfOpenProbes:   1,
+					},
+				},
+			},
+			success: true,
+		},
+		"success | hedging policy": {
+			routes: Routes{
+				{
+					ID:       "test-id",
+					Type:     "PROXY",
+					Endpoint: "test-endpoint",
+					Timeout:  "2s",
+					HedgingPolicy: &router.HedgingPolicy{
+						Delay:           fiberConfig.Duration(time.Millisecond * 50),
+						MaxHedgePercent: 10,
+					},
+				},
+			},
+			protocol: fiberProtocol.HTTP,
+			fiberRoutes: fiberConfig.Routes{
+				&router.ProxyConfig{
+					ProxyConfig: &fiberConfig.ProxyConfig{
+						ComponentConfig: fiberConfig.ComponentConfig{
+							ID:   "test-id",
+							Type: "PROXY",
+						},
+						Endpoint: "test-endpoint",
+						Timeout:  fiberConfig.Duration(time.Second * 2),
+						Protocol: fiberProtocol.HTTP,
+					},
+					HedgingPolicy: &router.HedgingPolicy{
+						Delay:           fiberConfig.Duration(time.Millisecond * 50),
+						MaxHedgePercent: 10,
+					},
+				},
+			},
+			success: true,
+		},
 		"failure | bad timeout": {
 			routes: Routes{
 				{
@@ -5,13 +5,19 @@ import (
 	"encoding/json"
 	"errors"
 
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
 	"github.com/caraml-dev/turing/engines/router"
 )
 
 type TrafficRule struct {
-	Name       string                         `json:"name" validate:"required,notBlank"`
-	Conditions []*router.TrafficRuleCondition `json:"conditions" validate:"required,notBlank,dive"`
-	Routes     []string                       `json:"routes" validate:"required,notBlank"`
+	Name string `json:"name" validate:"required,notBlank"`
+	// Conditions are required for conditional rules and are not supported for weighted rules
+	Conditions []*router.TrafficRuleCondition `json:"conditions" validate:"dive"`
+	// Weight is the percentage of the traffic sent to the routes of a weighted rule
+	Weight *int     `json:"weight,omitempty" validate:"omitempty,min=1,max=100"`
+	Routes []string `json:"routes" validate:"required,notBlank"`
+	// FallbackResponse is served in place of the error, when the routes of the rule fail
+	FallbackResponse *FallbackResponse `json:"fallback_response,omitempty" validate:"omitempty"`
 }
 
 type TrafficRules []*TrafficRule
@@ -29,6 +35,17 @@ func (r *TrafficRules) Scan(value interface{}) error {
 	return json.Unmarshal(b, r)
 }
 
+// IsWeighted returns true if the traffic is split between the rules based on their weights,
+// rather than on the request conditions
+func (r TrafficRules) IsWeighted() bool {
+	for _, rule := range r {
+		if rule.Weight != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *TrafficRules) ConditionalRouteIDs() map[string]bool {
 	distinctRouteIDs := map[string]bool{}
 
@@ -39,3 +56,23 @@ func (r *TrafficRules) ConditionalRouteIDs() map[string]bool {
 	}
 	return distinctRouteIDs
 }
+
+// TrafficSplitUnit is the field of the request, which value is hashed to consistently
+// assign the requests to one of the weighted traffic rules
+type TrafficSplitUnit struct {
+	FieldSource request.FieldSource `json:"field_source" validate:"required"`
+	Field       string              `json:"field" validate:"required"`
+}
+
+func (u TrafficSplitUnit) Value() (driver.Value, error) {
+	return json.Marshal(u)
+}
+
+func (u *TrafficSplitUnit) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, u)
+}
@@ -97,6 +97,36 @@ func (_m *DeploymentService) GetLocalSecret(serviceAccountKeyFilePath string) (*
 	return r0, r1
 }
 
+// PlanRouterVersion provides a mock function with given fields: project, environment, currentRouterVersion, routerVersion, secretMap, pyfuncEnsembler, experimentConfig
+func (_m *DeploymentService) PlanRouterVersion(project *client.Project, environment *merlinclient.Environment, currentRouterVersion *models.RouterVersion, routerVersion *models.RouterVersion, secretMap map[string]string, pyfuncEnsembler *models.PyFuncEnsembler, experimentConfig json.RawMessage) (*service.RouterDeploymentPlan, error) {
+	ret := _m.Called(project, environment, currentRouterVersion, routerVersion, secretMap, pyfuncEnsembler, experimentConfig)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PlanRouterVersion")
+	}
+
+	var r0 *service.RouterDeploymentPlan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*client.Project, *merlinclient.Environment, *models.RouterVersion, *models.RouterVersion, map[string]string, *models.PyFuncEnsembler, json.RawMessage) (*service.RouterDeploymentPlan, error)); ok {
+		return rf(project, environment, currentRouterVersion, routerVersion, secretMap, pyfuncEnsembler, experimentConfig)
+	}
+	if rf, ok := ret.Get(0).(func(*client.Project, *merlinclient.Environment, *models.RouterVersion, *models.RouterVersion, map[string]string, *models.PyFuncEnsembler, json.RawMessage) *service.RouterDeploymentPlan); ok {
+		r0 = rf(project, environment, currentRouterVersion, routerVersion, secretMap, pyfuncEnsembler, experimentConfig)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.RouterDeploymentPlan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*client.Project, *merlinclient.Environment, *models.RouterVersion, *models.RouterVersion, map[string]string, *models.PyFuncEnsembler, json.RawMessage) error); ok {
+		r1 = rf(project, environment, currentRouterVersion, routerVersion, secretMap, pyfuncEnsembler, experimentConfig)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UndeployRouterVersion provides a mock function with given fields: project, environment, routerVersion, eventsCh, isCleanUp
 func (_m *DeploymentService) UndeployRouterVersion(project *client.Project, environment *merlinclient.Environment, routerVersion *models.RouterVersion, eventsCh *service.EventChannel, isCleanUp bool) error {
 	ret := _m.Called(project, environment, routerVersion, eventsCh, isCleanUp)
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
@@ -13,8 +14,13 @@ import (
 	merlin "github.com/caraml-dev/merlin/client"
 	mlp "github.com/caraml-dev/mlp/api/client"
 	"github.com/pkg/errors"
+	istiov1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	policyv1cfg "k8s.io/client-go/applyconfigurations/policy/v1"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
 
 	"github.com/caraml-dev/turing/api/turing/cluster"
 	"github.com/caraml-dev/turing/api/turing/cluster/labeller"
@@ -51,7 +57,31 @@ type DeploymentService interface {
 	GetLocalSecret(
 		serviceAccountKeyFilePath string,
 	) (*string, error)
+	PlanRouterVersion(
+		project *mlp.Project,
+		environment *merlin.Environment,
+		currentRouterVersion *models.RouterVersion,
+		routerVersion *models.RouterVersion,
+		secretMap map[string]string,
+		pyfuncEnsembler *models.PyFuncEnsembler,
+		experimentConfig json.RawMessage,
+	) (*RouterDeploymentPlan, error)
 }
+
+// RouterDeploymentPlan holds the cluster resources that would be applied by the deployment of a router version
+type RouterDeploymentPlan struct {
+	Secret               *corev1.Secret                                       `json:"secret"`
+	ConfigMaps           []*corev1.ConfigMap                                  `json:"config_maps"`
+	KnativeServices      []*knservingv1.Service                               `json:"knative_services"`
+	StatefulSets         []*appsv1.StatefulSet                                `json:"stateful_sets"`
+	Services             []*corev1.Service                                    `json:"services"`
+	VirtualService       *istiov1beta1.VirtualService                         `json:"virtual_service,omitempty"`
+	PodDisruptionBudgets []*policyv1cfg.PodDisruptionBudgetApplyConfiguration `json:"pod_disruption_budgets"`
+}
+
+// redactedSecretValue replaces the values of the secrets in the deployment plans
+const redactedSecretValue = "<redacted>"
+
 type deploymentService struct {
 	// Deployment configs
 	deploymentTimeout         time.Duration
@@ -170,7 +200,7 @@ func (ds *deploymentService) DeployRouterVersion(
 	}
 
 	// Deploy fluentd if enabled
-	if routerVersion.LogConfig.ResultLoggerType == models.BigQueryLogger {
+	if routerVersion.LogConfig.HasResultLogger(models.BigQueryLogger) {
 		fluentdService := ds.svcBuilder.NewFluentdService(routerVersion, project,
 			secretName, ds.routerDefaults.FluentdConfig)
 		// Deploy fluentd
@@ -269,7 +299,7 @@ func (ds *deploymentService) UndeployRouterVersion(
 
 	var errs []string
 	// Delete fluentd if required
-	if routerVersion.LogConfig.ResultLoggerType == models.BigQueryLogger {
+	if routerVersion.LogConfig.HasResultLogger(models.BigQueryLogger) {
 		fluentdService := ds.svcBuilder.NewFluentdService(routerVersion,
 			project, "", ds.routerDefaults.FluentdConfig)
 		err = deleteK8sService(controller, fluentdService, isCleanUp)
@@ -312,6 +342,127 @@ func (ds *deploymentService) UndeployRouterVersion(
 	return nil
 }
 
+// PlanRouterVersion returns the cluster resources that DeployRouterVersion would apply for the given router
+// version, without applying them. The cluster is only queried for the current replicas of the services, and
+// the values of the secrets are redacted.
+func (ds *deploymentService) PlanRouterVersion(
+	project *mlp.Project,
+	environment *merlin.Environment,
+	currRouterVersion *models.RouterVersion,
+	routerVersion *models.RouterVersion,
+	secretMap map[string]string,
+	pyfuncEnsembler *models.PyFuncEnsembler,
+	experimentConfig json.RawMessage,
+) (*RouterDeploymentPlan, error) {
+	// If pyfunc ensembler is specified as an ensembler service, use its image without building it
+	if pyfuncEnsembler != nil {
+		image, err := ds.ensemblerServiceImageBuilder.GetEnsemblerImage(project, pyfuncEnsembler)
+		if err != nil {
+			return nil, err
+		}
+		setPyFuncEnsemblerDockerConfig(routerVersion, image.ImageRef)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ds.deploymentTimeout)
+	defer cancel()
+
+	// Get the cluster controller
+	controller, err := ds.getClusterControllerByEnvironment(environment.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Secret
+	redactedSecretMap := make(map[string]string, len(secretMap))
+	for key := range secretMap {
+		redactedSecretMap[key] = redactedSecretValue
+	}
+	secret := ds.svcBuilder.NewSecret(routerVersion, project, redactedSecretMap)
+	plan := &RouterDeploymentPlan{
+		Secret:               secret.BuildSecret(),
+		ConfigMaps:           []*corev1.ConfigMap{},
+		KnativeServices:      []*knservingv1.Service{},
+		StatefulSets:         []*appsv1.StatefulSet{},
+		Services:             []*corev1.Service{},
+		PodDisruptionBudgets: []*policyv1cfg.PodDisruptionBudgetApplyConfiguration{},
+	}
+	// Show the redacted values as plain strings, rather than base64-encoded ones
+	plan.Secret.StringData, plan.Secret.Data = redactedSecretMap, nil
+
+	// Services of each of the components
+	services, err := ds.createServices(
+		ctx, controller,
+		routerVersion, currRouterVersion, project, ds.environmentType,
+		secret.Name, experimentConfig,
+		ds.routerDefaults, ds.sentryEnabled, ds.sentryDSN,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, svc := range services {
+		if svc.ConfigMap != nil {
+			plan.ConfigMaps = append(plan.ConfigMaps, svc.ConfigMap.BuildConfigMap(svc.Namespace))
+		}
+		knSvc, err := svc.BuildKnativeServiceConfig()
+		if err != nil {
+			return nil, err
+		}
+		plan.KnativeServices = append(plan.KnativeServices, knSvc)
+	}
+
+	// Fluentd
+	if routerVersion.LogConfig.HasResultLogger(models.BigQueryLogger) {
+		fluentdService := ds.svcBuilder.NewFluentdService(routerVersion, project,
+			secret.Name, ds.routerDefaults.FluentdConfig)
+		statefulSet, k8sSvc := fluentdService.BuildKubernetesServiceConfig()
+		plan.StatefulSets = append(plan.StatefulSets, statefulSet)
+		plan.Services = append(plan.Services, k8sSvc)
+	}
+
+	// Router endpoint, only if the router's Knative service URL can be determined before the deployment
+	routerSvcName := ds.svcBuilder.GetRouterServiceName(routerVersion)
+	if routerSvcURL, ok := getPlannedKnativeServiceURL(routerVersion.Router, routerSvcName); ok {
+		routerEndpoint, err := ds.svcBuilder.NewRouterEndpoint(routerVersion, project, routerSvcURL)
+		if err != nil {
+			return nil, err
+		}
+		plan.VirtualService = routerEndpoint.BuildVirtualService()
+	}
+
+	// PDBs
+	if ds.pdbConfig.Enabled {
+		for _, pdb := range ds.createPodDisruptionBudgets(routerVersion, project) {
+			pdbCfg, err := pdb.BuildPDB()
+			if err != nil {
+				return nil, err
+			}
+			plan.PodDisruptionBudgets = append(plan.PodDisruptionBudgets, pdbCfg)
+		}
+	}
+
+	return plan, nil
+}
+
+// getPlannedKnativeServiceURL returns the expected URL of the router's Knative service with the given name.
+// The URL is only known once the service has been deployed, so it's derived from the router's current
+// endpoint, which only differs from the Knative service URLs by the service name.
+func getPlannedKnativeServiceURL(router *models.Router, svcName string) (string, bool) {
+	if router == nil || router.Endpoint == "" {
+		return "", false
+	}
+	endpoint := router.Endpoint
+	// The endpoints of UPI routers don't have a scheme
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return "", false
+	}
+	routerEndpointName := fmt.Sprintf("%s-turing-router", router.Name)
+	return "http://" + strings.Replace(endpointURL.Hostname(), routerEndpointName, svcName, 1), true
+}
+
 func (ds *deploymentService) DeleteRouterEndpoint(
 	project *mlp.Project,
 	environment *merlin.Environment,
@@ -463,6 +614,14 @@ func (ds *deploymentService) buildEnsemblerServiceImage(
 		),
 	)
 	// Create a new docker config for the ensembler with the newly generated image
+	setPyFuncEnsemblerDockerConfig(routerVersion, imageRef)
+
+	return nil
+}
+
+// setPyFuncEnsemblerDockerConfig sets the docker config of the pyfunc ensembler of the given router version,
+// to deploy it as a service with the given image
+func setPyFuncEnsemblerDockerConfig(routerVersion *models.RouterVersion, imageRef string) {
 	routerVersion.Ensembler.DockerConfig = &models.EnsemblerDockerConfig{
 		Image:             imageRef,
 		ResourceRequest:   routerVersion.Ensembler.PyfuncConfig.ResourceRequest,
@@ -473,8 +632,6 @@ func (ds *deploymentService) buildEnsemblerServiceImage(
 		Secrets:           routerVersion.Ensembler.PyfuncConfig.Secrets,
 		Env:               routerVersion.Ensembler.PyfuncConfig.Env,
 	}
-
-	return nil
 }
 
 func (ds *deploymentService) GetLocalSecret(
@@ -777,7 +934,7 @@ func (ds *deploymentService) createPodDisruptionBudgets(
 	}
 
 	// Fluentd logger's PDB
-	if routerVersion.LogConfig.ResultLoggerType == models.BigQueryLogger &&
+	if routerVersion.LogConfig.HasResultLogger(models.BigQueryLogger) &&
 		math.Ceil(float64(servicebuilder.FluentdReplicaCount)*
 			minAvailablePercent) < float64(servicebuilder.FluentdReplicaCount) {
 		fluentdPdb := ds.svcBuilder.NewPodDisruptionBudget(
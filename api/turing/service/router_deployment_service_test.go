@@ -379,6 +379,173 @@ func TestDeployEndpoint(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// mockPlanClusterServiceBuilder extends mockClusterServiceBuilder with the labels of the fluentd service,
+// that are required to build its Kubernetes resources
+type mockPlanClusterServiceBuilder struct {
+	*mockClusterServiceBuilder
+}
+
+func (msb *mockPlanClusterServiceBuilder) NewFluentdService(
+	rv *models.RouterVersion,
+	project *mlp.Project,
+	serviceAccountSecretName string,
+	fluentdConfig *config.FluentdConfig,
+) *cluster.KubernetesService {
+	svc := msb.mockClusterServiceBuilder.NewFluentdService(rv, project, serviceAccountSecretName, fluentdConfig)
+	svc.Labels = map[string]string{}
+	return svc
+}
+
+func TestPlanRouterVersion(t *testing.T) {
+	testEnv := "test-env"
+	testNamespace := "test-namespace"
+	defaultMinAvailablePercentage := 20
+
+	// Create test router version
+	filePath := filepath.Join("..", "testdata", "cluster",
+		"servicebuilder", "router_version_success.json")
+	routerVersion := tu.GetRouterVersion(t, filePath)
+
+	// Create mock controller, that is not expected to apply any resources
+	controller := &mocks.Controller{}
+
+	// Create mock service builder
+	svcBuilder := &mockPlanClusterServiceBuilder{
+		mockClusterServiceBuilder: &mockClusterServiceBuilder{
+			rv: routerVersion,
+			knativeServiceConfig: &config.KnativeServiceDefaults{
+				QueueProxyResourcePercentage:          20,
+				UserContainerCPULimitRequestFactor:    1.75,
+				UserContainerMemoryLimitRequestFactor: 1.75,
+			},
+		},
+	}
+
+	ds := &deploymentService{
+		routerDefaults: &config.RouterDefaults{
+			JaegerCollectorEndpoint: "jaeger-endpoint",
+			FluentdConfig:           &config.FluentdConfig{Tag: "fluentd-tag"},
+		},
+		deploymentTimeout: time.Second * 5,
+		environmentType:   "staging",
+		clusterControllers: map[string]cluster.Controller{
+			testEnv: controller,
+		},
+		svcBuilder: svcBuilder,
+		pdbConfig: config.PodDisruptionBudgetConfig{
+			Enabled:                true,
+			MinAvailablePercentage: &defaultMinAvailablePercentage,
+		},
+	}
+
+	// Router that has never been deployed
+	plan, err := ds.PlanRouterVersion(
+		&mlp.Project{Name: testNamespace},
+		&merlin.Environment{Name: testEnv},
+		nil,
+		routerVersion,
+		secretMap,
+		nil,
+		nil,
+	)
+	assert.NoError(t, err)
+	assert.Len(t, controller.Calls, 0)
+
+	assert.Equal(t, fmt.Sprintf("%s-svc-acct-secret-%d", routerVersion.Router.Name, routerVersion.Version),
+		plan.Secret.Name)
+	assert.Nil(t, plan.Secret.Data)
+	assert.Equal(t, map[string]string{
+		servicebuilder.SecretKeyNameRouter:    redactedSecretValue,
+		servicebuilder.SecretKeyNameEnricher:  redactedSecretValue,
+		servicebuilder.SecretKeyNameEnsembler: redactedSecretValue,
+		servicebuilder.SecretKeyNameExpEngine: redactedSecretValue,
+	}, plan.Secret.StringData)
+
+	assert.Len(t, plan.ConfigMaps, 1)
+	assert.Equal(t, fmt.Sprintf("%s-fiber-config-%d", routerVersion.Router.Name, routerVersion.Version),
+		plan.ConfigMaps[0].Name)
+	assert.Equal(t, testNamespace, plan.ConfigMaps[0].Namespace)
+
+	knSvcNames := []string{}
+	for _, svc := range plan.KnativeServices {
+		knSvcNames = append(knSvcNames, svc.Name)
+	}
+	assert.ElementsMatch(t, []string{
+		fmt.Sprintf("%s-enricher-%d", routerVersion.Router.Name, routerVersion.Version),
+		fmt.Sprintf("%s-ensembler-%d", routerVersion.Router.Name, routerVersion.Version),
+		fmt.Sprintf("%s-router-%d", routerVersion.Router.Name, routerVersion.Version),
+	}, knSvcNames)
+
+	assert.Len(t, plan.StatefulSets, 1)
+	assert.Len(t, plan.Services, 1)
+	assert.Nil(t, plan.VirtualService)
+	assert.Len(t, plan.PodDisruptionBudgets, 3)
+
+	// Router with an existing endpoint
+	routerVersion.Router.Endpoint = "http://test-svc-turing-router.models.example.com"
+	plan, err = ds.PlanRouterVersion(
+		&mlp.Project{Name: testNamespace},
+		&merlin.Environment{Name: testEnv},
+		nil,
+		routerVersion,
+		secretMap,
+		nil,
+		nil,
+	)
+	assert.NoError(t, err)
+	assert.Len(t, controller.Calls, 0)
+	assert.NotNil(t, plan.VirtualService)
+	assert.Equal(t, "test-svc-turing-router", plan.VirtualService.Name)
+
+	// Unknown environment
+	_, err = ds.PlanRouterVersion(
+		&mlp.Project{Name: testNamespace},
+		&merlin.Environment{Name: "unknown-env"},
+		nil,
+		routerVersion,
+		secretMap,
+		nil,
+		nil,
+	)
+	assert.Error(t, err)
+}
+
+func TestGetPlannedKnativeServiceURL(t *testing.T) {
+	tests := map[string]struct {
+		router      *models.Router
+		expectedURL string
+		expectedOK  bool
+	}{
+		"success | http endpoint": {
+			router: &models.Router{
+				Name:     "router",
+				Endpoint: "http://router-turing-router.models.example.com/v1/predict",
+			},
+			expectedURL: "http://router-turing-router-2.models.example.com",
+			expectedOK:  true,
+		},
+		"success | upi endpoint": {
+			router: &models.Router{
+				Name:     "router",
+				Endpoint: "router-turing-router.models.example.com:80",
+			},
+			expectedURL: "http://router-turing-router-2.models.example.com",
+			expectedOK:  true,
+		},
+		"failure | not deployed": {
+			router: &models.Router{Name: "router"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			url, ok := getPlannedKnativeServiceURL(tt.router, "router-turing-router-2")
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expectedURL, url)
+		})
+	}
+}
+
 func TestDeleteEndpoint(t *testing.T) {
 	testEnv := "test-env"
 	testNs := "test-namespace"
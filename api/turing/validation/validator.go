@@ -1,15 +1,20 @@
 package validation
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/golang-collections/collections/set"
 
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
 	"github.com/go-playground/validator/v10"
 	"github.com/go-playground/validator/v10/non-standard/validators"
+	"google.golang.org/protobuf/encoding/protojson"
 
 	"github.com/caraml-dev/turing/api/turing/api/request"
 	"github.com/caraml-dev/turing/api/turing/models"
@@ -22,6 +27,13 @@ import (
 var tableRegexString = `.+\.[a-zA-Z0-9_]+\.[a-zA-Z0-9_]+`
 var trafficRuleNameRegex = regexp.MustCompile(`^[A-Za-z\d][\w\d \-()#$%&:.]{2,62}[\w\d\-()#$%&:.]$`)
 
+// totalTrafficRuleWeight is the value that the weights of the weighted traffic rules should sum up to
+const totalTrafficRuleWeight = 100
+
+// maxFallbackResponseBodySize is the maximum size of the body of a fallback response, in bytes. The fallback
+// responses are passed to the router in its environment, so their size is limited.
+const maxFallbackResponseBodySize = 16 * 1024
+
 // NewValidator creates a new validator using the given defaults
 func NewValidator(expSvc service.ExperimentsService) (*validator.Validate, error) {
 	instance := validator.New()
@@ -39,8 +51,14 @@ func NewValidator(expSvc service.ExperimentsService) (*validator.Validate, error
 
 	instance.RegisterStructValidation(validateEnsemblerStandardConfig, models.EnsemblerStandardConfig{})
 
+	instance.RegisterStructValidation(validateEnsemblerBuiltinConfig, models.EnsemblerBuiltinConfig{})
+
 	instance.RegisterStructValidation(validateRouterConfig, request.RouterConfig{})
 
+	instance.RegisterStructValidation(validateTrafficRule, models.TrafficRule{})
+
+	instance.RegisterStructValidation(router.ValidateTrafficRuleCondition, router.TrafficRuleCondition{})
+
 	// register common.RuleConditionOperator type to use its String representation for validation
 	instance.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
 		if val, ok := field.Interface().(router.RuleConditionOperator); ok {
@@ -66,55 +84,128 @@ func validateEnsemblerStandardConfig(sl validator.StructLevel) {
 	}
 }
 
+func validateEnsemblerBuiltinConfig(sl validator.StructLevel) {
+	ensemblerBuiltinConfig := sl.Current().Interface().(models.EnsemblerBuiltinConfig)
+	// Verify that the fields required by the strategy are set
+	switch ensemblerBuiltinConfig.Strategy {
+	case models.BuiltinWeightedAverageStrategy:
+		if len(ensemblerBuiltinConfig.ValuePaths) == 0 {
+			sl.ReportError(ensemblerBuiltinConfig.ValuePaths,
+				"ValuePaths", "ValuePaths", "required for the weighted_average strategy", "")
+		}
+	case models.BuiltinMajorityVoteStrategy:
+		if ensemblerBuiltinConfig.LabelPath == "" {
+			sl.ReportError(ensemblerBuiltinConfig.LabelPath,
+				"LabelPath", "LabelPath", "required for the majority_vote strategy", "")
+		}
+	case models.BuiltinFirstSuccessStrategy:
+	default:
+		sl.ReportError(ensemblerBuiltinConfig.Strategy, "Strategy", "Strategy",
+			"oneof", "weighted_average majority_vote first_success")
+	}
+}
+
 func validateLogConfig(sl validator.StructLevel) {
 	field := sl.Current().Interface().(request.LogConfig)
+	// The hashed fields are keyed by the secret key of the router
+	for _, redaction := range field.Redactions {
+		if redaction.Action == routerConfig.HashRedactionAction && field.HashKeySecret == "" {
+			sl.ReportError(field.HashKeySecret, "hash_key_secret", "HashKeySecret", "required-by-hash-redaction", "")
+			break
+		}
+	}
+	if len(field.ResultLoggerTypes) > 0 {
+		validateResultLoggerTypes(sl, field)
+		return
+	}
 	switch field.ResultLoggerType {
 	case models.NopLogger, models.UPILogger:
 		return
 	case models.BigQueryLogger:
-		bqConf := field.BigQueryConfig
-		if bqConf == nil {
-			sl.ReportError(field.BigQueryConfig,
-				"bigquery_config", "BigQueryConfig", "bigquery-config-missing", "")
-		} else {
-			tableRegex := regexp.MustCompile(tableRegexString)
-			if !tableRegex.MatchString(bqConf.Table) {
-				sl.ReportError(field.BigQueryConfig,
-					"bigquery_config", "BigQueryConfig", "bigquery-config-invalid-tablename", "")
-			}
-			if len(bqConf.ServiceAccountSecret) == 0 {
-				sl.ReportError(field.BigQueryConfig,
-					"bigquery_config", "BigQueryConfig", "bigquery-config-missing-svc-account", "")
-			}
-		}
+		validateBigQueryLoggerConfig(sl, field)
 		return
 	case models.KafkaLogger:
-		kafkaConf := field.KafkaConfig
-		if kafkaConf == nil {
-			sl.ReportError(field.KafkaConfig,
-				"kafka_config", "KafkaConfig", "kafka-config-missing", "")
-		} else {
-			if len(kafkaConf.Brokers) == 0 {
-				sl.ReportError(field.KafkaConfig,
-					"kafka_config", "KafkaConfig", "kafka-config-brokers-missing", "")
-			}
-			if len(kafkaConf.Topic) == 0 {
-				sl.ReportError(field.KafkaConfig,
-					"kafka_config", "KafkaConfig", "kafka-config-topic-missing", "")
-			}
-			if kafkaConf.SerializationFormat != models.JSONSerializationFormat &&
-				kafkaConf.SerializationFormat != models.ProtobufSerializationFormat {
-				sl.ReportError(field.KafkaConfig,
-					"kafka_config", "KafkaConfig", "kafka-serialization-format-oneOf",
-					string(kafkaConf.SerializationFormat))
-			}
-		}
+		validateKafkaLoggerConfig(sl, field)
 		return
 	default:
 		sl.ReportError(field.ResultLoggerType, "type", "ResultLoggerType", "oneof", "bigquery,nop,kafka")
 	}
 }
 
+// validateResultLoggerTypes checks that the result loggers, which the results are logged to at once,
+// are distinct and configured. The Kafka and UPI loggers are exclusive, as they share the router's
+// Kafka config.
+func validateResultLoggerTypes(sl validator.StructLevel, field request.LogConfig) {
+	resultLoggers := set.New()
+	for idx, resultLogger := range field.ResultLoggerTypes {
+		ns := fmt.Sprintf("result_logger_types[%d]", idx)
+		if resultLoggers.Has(resultLogger) {
+			sl.ReportError(resultLogger, ns, "ResultLoggerTypes", "unique", "")
+			continue
+		}
+		resultLoggers.Insert(resultLogger)
+
+		switch resultLogger {
+		case models.UPILogger:
+		case models.BigQueryLogger:
+			validateBigQueryLoggerConfig(sl, field)
+		case models.KafkaLogger:
+			validateKafkaLoggerConfig(sl, field)
+		default:
+			sl.ReportError(resultLogger, ns, "ResultLoggerTypes", "oneof", "bigquery,kafka,upi")
+		}
+	}
+	if resultLoggers.Has(models.KafkaLogger) && resultLoggers.Has(models.UPILogger) {
+		sl.ReportError(field.ResultLoggerTypes,
+			"result_logger_types", "ResultLoggerTypes", "kafka-upi-loggers-exclusive", "")
+	}
+	if field.ResultLoggerType != "" && !resultLoggers.Has(field.ResultLoggerType) {
+		sl.ReportError(field.ResultLoggerType,
+			"type", "ResultLoggerType", "should be one of the result_logger_types", "")
+	}
+}
+
+func validateBigQueryLoggerConfig(sl validator.StructLevel, field request.LogConfig) {
+	bqConf := field.BigQueryConfig
+	if bqConf == nil {
+		sl.ReportError(field.BigQueryConfig,
+			"bigquery_config", "BigQueryConfig", "bigquery-config-missing", "")
+		return
+	}
+	tableRegex := regexp.MustCompile(tableRegexString)
+	if !tableRegex.MatchString(bqConf.Table) {
+		sl.ReportError(field.BigQueryConfig,
+			"bigquery_config", "BigQueryConfig", "bigquery-config-invalid-tablename", "")
+	}
+	if len(bqConf.ServiceAccountSecret) == 0 {
+		sl.ReportError(field.BigQueryConfig,
+			"bigquery_config", "BigQueryConfig", "bigquery-config-missing-svc-account", "")
+	}
+}
+
+func validateKafkaLoggerConfig(sl validator.StructLevel, field request.LogConfig) {
+	kafkaConf := field.KafkaConfig
+	if kafkaConf == nil {
+		sl.ReportError(field.KafkaConfig,
+			"kafka_config", "KafkaConfig", "kafka-config-missing", "")
+		return
+	}
+	if len(kafkaConf.Brokers) == 0 {
+		sl.ReportError(field.KafkaConfig,
+			"kafka_config", "KafkaConfig", "kafka-config-brokers-missing", "")
+	}
+	if len(kafkaConf.Topic) == 0 {
+		sl.ReportError(field.KafkaConfig,
+			"kafka_config", "KafkaConfig", "kafka-config-topic-missing", "")
+	}
+	if kafkaConf.SerializationFormat != models.JSONSerializationFormat &&
+		kafkaConf.SerializationFormat != models.ProtobufSerializationFormat {
+		sl.ReportError(field.KafkaConfig,
+			"kafka_config", "KafkaConfig", "kafka-serialization-format-oneOf",
+			string(kafkaConf.SerializationFormat))
+	}
+}
+
 func newExperimentConfigValidator(expSvc service.ExperimentsService) func(validator.StructLevel) {
 	supportedEngines := make(map[string]bool)
 	supportedEnginesStr := models.ExperimentEngineTypeNop
@@ -221,38 +312,35 @@ func validateConditionOrthogonality(
 	fieldName string,
 	allRules models.TrafficRules,
 ) {
-	trafficConditionsValueMap := map[string]map[string]*set.Set{}
+	// Group the conditions of each rule by the field they are applied to
+	trafficConditionsMap := map[string]map[string][]*router.TrafficRuleCondition{}
 	for _, rule := range allRules {
-		trafficConditionsValueMap[rule.Name] = map[string]*set.Set{}
+		trafficConditionsMap[rule.Name] = map[string][]*router.TrafficRuleCondition{}
 		for _, condition := range rule.Conditions {
-			conditionValues := []interface{}{}
-			for _, val := range condition.Values {
-				conditionValues = append(conditionValues, val)
-			}
-			trafficConditionsValueMap[rule.Name][condition.Field] = set.New(conditionValues...)
+			trafficConditionsMap[rule.Name][condition.Field] = append(
+				trafficConditionsMap[rule.Name][condition.Field], condition)
 		}
 	}
 
 	allErrors := []string{}
 	for idx, rule1 := range allRules {
-		rule1Fields, ok := trafficConditionsValueMap[rule1.Name]
+		rule1Fields, ok := trafficConditionsMap[rule1.Name]
 		// Check that there are rule conditions
 		if ok {
-			// Check that the current rule and the other are orthogonal
-			rulesOverlap := true
-
 			for _, rule2 := range allRules[idx+1:] {
 				// Rules with same name should be skipped for orthogonality checks since they will fail unique name validation
 				if rule1.Name == rule2.Name {
 					continue
 				}
-				for field, currSet := range rule1Fields {
+				// Check that the current rule and the other are orthogonal
+				rulesOverlap := true
+				for field, currConditions := range rule1Fields {
 					isCurrValEmpty, isOtherValEmpty := false, false
-					if !ok || currSet == nil || currSet.Len() == 0 {
+					if len(currConditions) == 0 {
 						isCurrValEmpty = true
 					}
-					otherSet, ok := trafficConditionsValueMap[rule2.Name][field]
-					if !ok || otherSet == nil || otherSet.Len() == 0 {
+					otherConditions, ok := trafficConditionsMap[rule2.Name][field]
+					if !ok || len(otherConditions) == 0 {
 						isOtherValEmpty = true
 					}
 
@@ -260,7 +348,8 @@ func validateConditionOrthogonality(
 					// If only one of the values is empty, we can skip further checks.
 					// If both empty, nothing to do.
 					if !isCurrValEmpty && !isOtherValEmpty {
-						if currSet.Intersection(otherSet).Len() == 0 {
+						if !conditionsOverlap(append(append([]*router.TrafficRuleCondition{},
+							currConditions...), otherConditions...)) {
 							// At least one field value does not overlap, we can terminate the check for
 							// this other rule.
 							rulesOverlap = false
@@ -287,6 +376,109 @@ func validateConditionOrthogonality(
 	}
 }
 
+// conditionsOverlap checks if there is a value of the field that satisfies all the given conditions,
+// which are applied to the same field. Since some operators (e.g. regular expressions) cannot be reasoned
+// about in general, the check is done by testing a set of candidate values, derived from the values of the
+// conditions, against all the conditions. The conditions are therefore only reported as overlapping, if
+// such a value is found. The exception are the regular expressions, which may match values that are not
+// derived from the conditions, e.g. "test-1" for "^test-": a regular expression combined with any other
+// condition on the same field is conservatively reported as overlapping, unless one of the conditions is
+// an `in` condition, whose values are then the only ones that can satisfy all the conditions.
+func conditionsOverlap(conditions []*router.TrafficRuleCondition) bool {
+	numRegexes, hasInValues := 0, false
+	for _, condition := range conditions {
+		// The operator is nil if it failed other validations
+		if condition.Operator.Operator == nil {
+			return false
+		}
+		switch condition.Operator.String() {
+		case router.MatchesConditionOperator.String():
+			numRegexes++
+		case router.InConditionOperator.String():
+			hasInValues = true
+		}
+	}
+	if numRegexes > 0 && len(conditions) > 1 && !hasInValues {
+		return true
+	}
+
+	for _, candidate := range overlapCandidates(conditions) {
+		satisfiesAll := true
+		for _, condition := range conditions {
+			if ok, err := condition.Operator.Test(candidate, condition.Values); err != nil || !ok {
+				satisfiesAll = false
+				break
+			}
+		}
+		if satisfiesAll {
+			return true
+		}
+	}
+	return false
+}
+
+func overlapCandidates(conditions []*router.TrafficRuleCondition) []string {
+	candidates := []string{}
+	allValues := []string{}
+	for _, condition := range conditions {
+		allValues = append(allValues, condition.Values...)
+	}
+	// A value that is different from all the values of the conditions
+	candidates = append(candidates, strings.Join(allValues, "")+"-")
+
+	numbers := []float64{}
+	for _, condition := range conditions {
+		for _, value := range condition.Values {
+			candidates = append(candidates, value)
+			switch condition.Operator.String() {
+			case router.GreaterThanConditionOperator.String(),
+				router.GreaterThanOrEqualConditionOperator.String(),
+				router.LessThanConditionOperator.String(),
+				router.LessThanOrEqualConditionOperator.String():
+				if num, err := strconv.ParseFloat(value, 64); err == nil {
+					numbers = append(numbers, num)
+				}
+			case router.VersionGreaterThanConditionOperator.String(),
+				router.VersionGreaterThanOrEqualConditionOperator.String(),
+				router.VersionLessThanConditionOperator.String(),
+				router.VersionLessThanOrEqualConditionOperator.String():
+				candidates = append(candidates, nextVersions(value)...)
+			}
+		}
+	}
+
+	// Values next to and in between the bounds of the numeric ranges
+	for i, x := range numbers {
+		candidates = append(candidates, formatNumber(x-1), formatNumber(x+1))
+		for _, y := range numbers[i+1:] {
+			candidates = append(candidates, formatNumber((x+y)/2))
+		}
+	}
+	return candidates
+}
+
+// nextVersions returns the versions that directly follow the given version, in each of its components
+func nextVersions(value string) []string {
+	parts := strings.Split(strings.TrimPrefix(value, "v"), ".")
+	core := [3]uint64{}
+	for i := 0; i < len(parts) && i < len(core); i++ {
+		num, err := strconv.ParseUint(parts[i], 10, 64)
+		if err != nil {
+			return nil
+		}
+		core[i] = num
+	}
+	return []string{
+		fmt.Sprintf("%d.%d.%d", core[0], core[1], core[2]+1),
+		fmt.Sprintf("%d.%d.0", core[0], core[1]+1),
+		fmt.Sprintf("%d.0.0", core[0]+1),
+	}
+}
+
+func formatNumber(num float64) string {
+	return strconv.FormatFloat(num, 'f', -1, 64)
+}
+
 func validateStdEnsemblerNotConfiguredForNopExpEngine(
 	sl validator.StructLevel,
 	ensembler *models.Ensembler,
@@ -310,8 +502,10 @@ func validateRouterConfig(sl validator.StructLevel) {
 	}
 	routeIDsStr := strings.Join(routeIDs, " ")
 
-	// Validate default route
-	if router.Ensembler == nil || router.Ensembler.Type == models.EnsemblerStandardType {
+	// Validate default route. UPI routers always forward the response of the default route to the
+	// ensembler, so the default route is required regardless of the ensembler type.
+	isUPIRouter := router.Protocol != nil && *router.Protocol == routerConfig.UPI
+	if router.Ensembler == nil || router.Ensembler.Type == models.EnsemblerStandardType || isUPIRouter {
 		if router.DefaultRouteID == nil {
 			sl.ReportError(router.DefaultRouteID, "default_route_id", "DefaultRouteID",
 				"should be set for chosen ensembler type", "")
@@ -321,12 +515,37 @@ func validateRouterConfig(sl validator.StructLevel) {
 				sl.ReportValidationErrors(ns, ns, err.(validator.ValidationErrors))
 			}
 		}
+	} else if router.Ensembler.Type == models.EnsemblerBuiltinType {
+		// The default route is optional for built-in ensemblers, which prefer its response
+		if router.DefaultRouteID != nil && *router.DefaultRouteID != "" {
+			if err := instance.Var(*router.DefaultRouteID, fmt.Sprintf("oneof=%s", routeIDsStr)); err != nil {
+				ns := "DefaultRouteID"
+				sl.ReportValidationErrors(ns, ns, err.(validator.ValidationErrors))
+			}
+		}
 	} else if router.DefaultRouteID != nil && *router.DefaultRouteID != "" {
 		sl.ReportError(router.DefaultRouteID, "default_route_id", "DefaultRouteID",
 			"should not be set for chosen ensembler type", *router.DefaultRouteID)
 	}
 
+	// Validate the routes referenced by the built-in ensembler
+	if router.Ensembler != nil && router.Ensembler.BuiltinConfig != nil {
+		validateBuiltinEnsemblerRoutes(sl, router.Ensembler.BuiltinConfig, routeIDsStr)
+	}
+
 	// Validate traffic rules
+	allowedFieldSource := []string{string(expRequest.HeaderFieldSource),
+		string(expRequest.PayloadFieldSource)}
+	if isUPIRouter {
+		allowedFieldSource = []string{string(expRequest.HeaderFieldSource),
+			string(expRequest.PredictionContextSource)}
+	}
+	// The identity of the caller is only available, if the router authenticates its callers
+	if router.Auth != nil {
+		allowedFieldSource = append(allowedFieldSource, string(expRequest.CallerFieldSource))
+	}
+	allowedFieldSourceStr := strings.Join(allowedFieldSource, " ")
+
 	allRuleRoutesSet := set.New()
 	if router.TrafficRules != nil {
 		if len(router.TrafficRules) > 0 {
@@ -344,6 +563,10 @@ func validateRouterConfig(sl validator.StructLevel) {
 		}
 		for ruleIdx, rule := range router.TrafficRules {
 			checkTrafficRuleName(sl, "TrafficRule", rule.Name)
+			if rule.FallbackResponse != nil {
+				validateFallbackResponse(sl, fmt.Sprintf("TrafficRules[%d].FallbackResponse", ruleIdx),
+					rule.FallbackResponse, isUPIRouter)
+			}
 			if rule.Routes != nil {
 				for idx, routeID := range rule.Routes {
 					allRuleRoutesSet.Insert(routeID)
@@ -356,14 +579,6 @@ func validateRouterConfig(sl validator.StructLevel) {
 
 			if rule.Conditions != nil {
 				// validate the field source of traffic rules are valid for given protocol
-				allowedFieldSource := []string{string(expRequest.HeaderFieldSource),
-					string(expRequest.PayloadFieldSource)}
-				if router.Protocol != nil && *router.Protocol == routerConfig.UPI {
-					allowedFieldSource = []string{string(expRequest.HeaderFieldSource),
-						string(expRequest.PredictionContextSource)}
-				}
-				allowedFieldSourceStr := strings.Join(allowedFieldSource, " ")
-
 				for condIdx, cond := range rule.Conditions {
 					ns := fmt.Sprintf("TrafficRules[%d].Conditions[%d].FieldSource", ruleIdx, condIdx)
 					err := instance.Var(cond.FieldSource, fmt.Sprintf("oneof=%s", allowedFieldSourceStr))
@@ -376,29 +591,385 @@ func validateRouterConfig(sl validator.StructLevel) {
 		}
 	}
 
+	// Validate that the hedging delays are shorter than the route timeouts
+	validateRouteHedgingPolicies(sl, router.Routes)
+
+	// Validate that the shadow routes are not part of the routing
+	primaryRoutes, shadowRoutes := router.Routes.SplitShadowRoutes()
+	if len(shadowRoutes) > 0 {
+		validateShadowRoutes(sl, router, primaryRoutes, shadowRoutes)
+	}
+
 	// Validate dangling routes and traffic rules orthogonality checks
 	if router.TrafficRules != nil && len(router.TrafficRules) > 0 {
-		checkDanglingRoutes(sl, "Routes", router.Routes, allRuleRoutesSet)
-		validateConditionOrthogonality(sl, "TrafficRules", router.TrafficRules)
+		checkDanglingRoutes(sl, "Routes", primaryRoutes, allRuleRoutesSet)
+		if router.TrafficRules.IsWeighted() {
+			validateWeightedTrafficRules(sl, router.TrafficRules, router.TrafficSplitUnit, allowedFieldSourceStr)
+		} else {
+			validateConditionOrthogonality(sl, "TrafficRules", router.TrafficRules)
+		}
+	}
+
+	// Validate that the bandit selects between the primary routes, and that it is the only routing logic
+	if router.Bandit != nil {
+		validateBandit(sl, router, primaryRoutes)
+	}
+
+	// Validate the response cache key fields and ttl
+	if router.ResponseCache != nil {
+		validateResponseCache(sl, router.ResponseCache, allowedFieldSourceStr)
+	}
+
+	// Validate that the rate limits are keyed by a valid field, and that they limit the rate or the concurrency
+	if router.RateLimit != nil {
+		validateRateLimit(sl, router.RateLimit, allowedFieldSourceStr)
+	}
+
+	// Validate that the result logs are sampled by a valid field of the router's requests
+	if router.LogConfig != nil && router.LogConfig.SamplingUnit != nil {
+		if fieldSource := router.LogConfig.SamplingUnit.FieldSource; fieldSource != "" {
+			if err := instance.Var(fieldSource, fmt.Sprintf("oneof=%s", allowedFieldSourceStr)); err != nil {
+				sl.ReportError(fieldSource, "LogConfig.SamplingUnit.FieldSource", "FieldSource", "oneof", "")
+			}
+		}
+	}
+
+	// Validate that the fallback response is a valid response of the router's protocol
+	if router.FallbackResponse != nil {
+		validateFallbackResponse(sl, "FallbackResponse", router.FallbackResponse, isUPIRouter)
+	}
+
+	// Validate that the processing stages have positive timeouts and distinct result log keys
+	if len(router.ProcessingStages) > 0 {
+		validateProcessingStages(sl, router.ProcessingStages)
+	}
+
+	// Validate that the callers are authenticated with API keys and / or JWTs
+	if router.Auth != nil {
+		validateAuth(sl, router.Auth)
 	}
 
 	// Validate that a non-nop experiment engine is used if a standard ensembler is set
 	validateStdEnsemblerNotConfiguredForNopExpEngine(sl, router.Ensembler, router.ExperimentEngine)
 
 	// Validate config combination that are specific to UPI routers
-	if router.Protocol != nil && *router.Protocol == routerConfig.UPI {
+	if isUPIRouter {
 		validateUPIRouter(sl, router)
 	} else {
 		validateHTTPRouter(sl, router)
 	}
 }
+
+// validateBuiltinEnsemblerRoutes checks that the routes, which are weighted or prioritised by the
+// built-in ensembler, are configured
+func validateBuiltinEnsemblerRoutes(sl validator.StructLevel, cfg *models.EnsemblerBuiltinConfig, routeIDsStr string) {
+	instance := sl.Validator()
+	for routeID := range cfg.Weights {
+		if err := instance.Var(routeID, fmt.Sprintf("oneof=%s", routeIDsStr)); err != nil {
+			ns := fmt.Sprintf("Ensembler.BuiltinConfig.Weights[%s]", routeID)
+			sl.ReportValidationErrors(ns, ns, err.(validator.ValidationErrors))
+		}
+	}
+	for idx, routeID := range cfg.RoutePriority {
+		if err := instance.Var(routeID, fmt.Sprintf("oneof=%s", routeIDsStr)); err != nil {
+			ns := fmt.Sprintf("Ensembler.BuiltinConfig.RoutePriority[%d]", idx)
+			sl.ReportValidationErrors(ns, ns, err.(validator.ValidationErrors))
+		}
+	}
+}
+
+// validateBandit checks that the routes of the multi-armed bandit are the primary routes of the router, and
+// that the router doesn't use an experiment engine, traffic rules or an ensembler, which would otherwise
+// decide on the routes instead of the bandit
+func validateBandit(sl validator.StructLevel, router request.RouterConfig, primaryRoutes models.Routes) {
+	instance := sl.Validator()
+
+	routeIDs := make([]string, len(primaryRoutes))
+	for idx, route := range primaryRoutes {
+		routeIDs[idx] = route.ID
+	}
+	for idx, routeID := range router.Bandit.Routes {
+		if err := instance.Var(routeID, fmt.Sprintf("oneof=%s", strings.Join(routeIDs, " "))); err != nil {
+			ns := fmt.Sprintf("Bandit.Routes[%d]", idx)
+			sl.ReportValidationErrors(ns, ns, err.(validator.ValidationErrors))
+		}
+	}
+
+	if router.ExperimentEngine != nil && router.ExperimentEngine.Type != models.ExperimentEngineTypeNop {
+		sl.ReportError(router.ExperimentEngine.Type, "ExperimentEngine.Type", "Type",
+			"should be nop when the bandit is configured", "")
+	}
+	if len(router.TrafficRules) > 0 {
+		sl.ReportError(router.TrafficRules, "TrafficRules", "TrafficRules",
+			"should not be set when the bandit is configured", "")
+	}
+	if router.Ensembler != nil {
+		sl.ReportError(router.Ensembler.Type, "Ensembler.Type", "Type",
+			"should not be set when the bandit is configured", "")
+	}
+	if interval := router.Bandit.SnapshotInterval; interval != "" {
+		if d, err := time.ParseDuration(interval); err != nil || d <= 0 {
+			sl.ReportError(interval, "Bandit.SnapshotInterval", "SnapshotInterval",
+				"should be a positive duration", interval)
+		}
+		// Each replica saves its own state, and only accepts the feedback of the requests it served, so the
+		// snapshots are only supported for a single replica
+		if router.ResourceRequest != nil && router.ResourceRequest.MaxReplica > 1 {
+			sl.ReportError(interval, "Bandit.SnapshotInterval", "SnapshotInterval",
+				"should not be set when the max replica is greater than 1", interval)
+		}
+	}
+}
+
+// validateTrafficRule checks that the conditions are only set on the conditional traffic rules
+func validateTrafficRule(sl validator.StructLevel) {
+	rule := sl.Current().Interface().(models.TrafficRule)
+
+	if rule.Weight == nil && len(rule.Conditions) == 0 {
+		sl.ReportError(rule.Conditions, "Conditions", "Conditions", "notBlank", "")
+	} else if rule.Weight != nil && len(rule.Conditions) != 0 {
+		sl.ReportError(rule.Conditions, "Conditions", "Conditions",
+			"should not be set for weighted traffic rule", "")
+	}
+}
+
+// validateWeightedTrafficRules checks that all the traffic rules are weighted, that their weights sum up
+// to 100 and that the request field, used to split the traffic between the rules, is configured
+func validateWeightedTrafficRules(
+	sl validator.StructLevel,
+	rules models.TrafficRules,
+	unit *models.TrafficSplitUnit,
+	allowedFieldSource string,
+) {
+	totalWeight := 0
+	for ruleIdx, rule := range rules {
+		if rule.Weight == nil {
+			sl.ReportError(rule.Weight, fmt.Sprintf("TrafficRules[%d].Weight", ruleIdx), "Weight",
+				"should be set for all traffic rules if any of them is weighted", "")
+			return
+		}
+		totalWeight += *rule.Weight
+	}
+	if totalWeight != totalTrafficRuleWeight {
+		sl.ReportError(rules, "TrafficRules", "TrafficRules",
+			fmt.Sprintf("weights of the traffic rules should sum up to %d", totalTrafficRuleWeight),
+			strconv.Itoa(totalWeight))
+	}
+
+	if unit == nil {
+		sl.ReportError(unit, "traffic_split_unit", "TrafficSplitUnit", "should be set for weighted traffic rules", "")
+	} else if unit.FieldSource != "" {
+		if err := sl.Validator().Var(unit.FieldSource, fmt.Sprintf("oneof=%s", allowedFieldSource)); err != nil {
+			sl.ReportError(unit.FieldSource, "TrafficSplitUnit.FieldSource", "FieldSource", "oneof", "")
+		}
+	}
+}
+
+// validateResponseCache checks that the ttl of the response cache is a valid duration and that
+// the field sources of its key fields are valid for the router's protocol
+func validateResponseCache(sl validator.StructLevel, cache *models.ResponseCacheConfig, allowedFieldSource string) {
+	if cache.TTL != "" {
+		if ttl, err := time.ParseDuration(cache.TTL); err != nil || ttl <= 0 {
+			sl.ReportError(cache.TTL, "ResponseCache.TTL", "TTL", "should be a positive duration", cache.TTL)
+		}
+	}
+	for idx, field := range cache.KeyFields {
+		if field.FieldSource == "" {
+			continue
+		}
+		if err := sl.Validator().Var(field.FieldSource, fmt.Sprintf("oneof=%s", allowedFieldSource)); err != nil {
+			sl.ReportError(field.FieldSource, fmt.Sprintf("ResponseCache.KeyFields[%d].FieldSource", idx),
+				"FieldSource", "oneof", "")
+		}
+	}
+}
+
+// validateRateLimit checks that the field source of the rate limits' key field is valid for the router's
+// protocol, and that they limit either the request rate or the number of in-flight requests
+func validateRateLimit(sl validator.StructLevel, rateLimit *models.RateLimitConfig, allowedFieldSource string) {
+	if rateLimit.FieldSource != "" {
+		if err := sl.Validator().Var(rateLimit.FieldSource, fmt.Sprintf("oneof=%s", allowedFieldSource)); err != nil {
+			sl.ReportError(rateLimit.FieldSource, "RateLimit.FieldSource", "FieldSource", "oneof", "")
+		}
+	}
+	if rateLimit.RequestsPerSecond <= 0 && rateLimit.MaxInFlight <= 0 {
+		sl.ReportError(rateLimit, "RateLimit", "RateLimit",
+			"should set requests_per_second and / or max_in_flight", "")
+	}
+	if rateLimit.Burst > 0 && rateLimit.RequestsPerSecond <= 0 {
+		sl.ReportError(rateLimit.Burst, "RateLimit.Burst", "Burst", "should only be set with requests_per_second", "")
+	}
+}
+
+// validateFallbackResponse checks that the body of the fallback response is within the size limit, and that
+// it is valid JSON or, for the UPI routers, a valid PredictValuesResponse, which is served with the OK status
+func validateFallbackResponse(
+	sl validator.StructLevel,
+	ns string,
+	resp *models.FallbackResponse,
+	isUPIRouter bool,
+) {
+	if len(resp.Body) == 0 {
+		// The missing body is reported by the required tag
+		return
+	}
+	if len(resp.Body) > maxFallbackResponseBodySize {
+		sl.ReportError(resp.Body, ns+".Body", "Body",
+			fmt.Sprintf("should be at most %d bytes", maxFallbackResponseBodySize), strconv.Itoa(len(resp.Body)))
+		return
+	}
+	if !isUPIRouter {
+		if !json.Valid(resp.Body) {
+			sl.ReportError(resp.Body, ns+".Body", "Body", "should be valid JSON", "")
+		}
+		return
+	}
+	if resp.Status != 0 {
+		sl.ReportError(resp.Status, ns+".Status", "Status", "should not be set for UPI routers", "")
+	}
+	if err := protojson.Unmarshal(resp.Body, &upiv1.PredictValuesResponse{}); err != nil {
+		sl.ReportError(resp.Body, ns+".Body", "Body", "should be a valid UPI PredictValuesResponse", "")
+	}
+}
+
+// reservedResultLogKeys are the keys of the Turing components' responses in the result log, which
+// cannot be used by the processing stages
+var reservedResultLogKeys = func() *set.Set {
+	keys := set.New()
+	for _, key := range router.ReservedResultLogKeys() {
+		keys.Insert(key)
+	}
+	return keys
+}()
+
+// validateProcessingStages checks that the timeout of each processing stage is positive and that
+// the stages' responses are logged under distinct keys, which do not clash with the Turing components
+func validateProcessingStages(sl validator.StructLevel, stages models.ProcessingStages) {
+	logKeys := set.New()
+	for idx, stage := range stages {
+		if stage.Timeout <= 0 {
+			sl.ReportError(stage.Timeout, fmt.Sprintf("ProcessingStages[%d].Timeout", idx), "Timeout",
+				"should be a positive duration", "")
+		}
+		logKey := stage.LogKey()
+		if reservedResultLogKeys.Has(logKey) {
+			sl.ReportError(logKey, fmt.Sprintf("ProcessingStages[%d].ResultLogKey", idx), "ResultLogKey",
+				"should not be a reserved key", logKey)
+		} else if logKeys.Has(logKey) {
+			sl.ReportError(logKey, fmt.Sprintf("ProcessingStages[%d].ResultLogKey", idx), "ResultLogKey",
+				"should be unique", logKey)
+		}
+		logKeys.Insert(logKey)
+	}
+}
+
+// validateAuth checks that at least one authentication method is configured, and that the JWKS
+// of the JWT validation is either fetched from a valid URL or configured inline as a valid JSON object
+func validateAuth(sl validator.StructLevel, auth *models.AuthConfig) {
+	if len(auth.APIKeys) == 0 && auth.JWT == nil {
+		sl.ReportError(auth, "Auth", "Auth", "should configure api_keys and / or jwt", "")
+		return
+	}
+	if auth.JWT == nil {
+		return
+	}
+
+	jwt := auth.JWT
+	switch {
+	case jwt.JWKSURL == "" && len(jwt.JWKS) == 0:
+		sl.ReportError(jwt.JWKSURL, "Auth.JWT.JWKSURL", "JWKSURL", "should set either jwks_url or jwks", "")
+	case jwt.JWKSURL != "" && len(jwt.JWKS) > 0:
+		sl.ReportError(jwt.JWKSURL, "Auth.JWT.JWKSURL", "JWKSURL", "should not be set together with jwks", "")
+	case jwt.JWKSURL != "":
+		if err := sl.Validator().Var(jwt.JWKSURL, "url"); err != nil {
+			sl.ReportError(jwt.JWKSURL, "Auth.JWT.JWKSURL", "JWKSURL", "url", jwt.JWKSURL)
+		}
+	default:
+		var jwks struct {
+			Keys []json.RawMessage `json:"keys"`
+		}
+		if err := json.Unmarshal(jwt.JWKS, &jwks); err != nil || len(jwks.Keys) == 0 {
+			sl.ReportError(jwt.JWKS, "Auth.JWT.JWKS", "JWKS", "should be a JSON Web Key Set with at least one key", "")
+		}
+	}
+	for name := range jwt.RequiredClaims {
+		if name == "" {
+			sl.ReportError(jwt.RequiredClaims, "Auth.JWT.RequiredClaims", "RequiredClaims",
+				"should not have an empty claim name", "")
+		}
+	}
+}
+
+// validateRouteHedgingPolicies checks that the hedging delay of each route is shorter than its timeout,
+// otherwise the duplicate request would never be sent
+func validateRouteHedgingPolicies(sl validator.StructLevel, routes models.Routes) {
+	for idx, route := range routes {
+		if route.HedgingPolicy == nil {
+			continue
+		}
+		timeout, err := time.ParseDuration(route.Timeout)
+		if err == nil && time.Duration(route.HedgingPolicy.Delay) >= timeout {
+			sl.ReportError(route.HedgingPolicy.Delay, fmt.Sprintf("Routes[%d].HedgingPolicy.Delay", idx), "Delay",
+				"should be shorter than the route timeout", route.Timeout)
+		}
+	}
+}
+
+// validateShadowRoutes checks that the shadow routes are neither the default route nor used by any
+// traffic rule, since their responses are never returned, and that the router has other routes
+func validateShadowRoutes(
+	sl validator.StructLevel,
+	router request.RouterConfig,
+	primaryRoutes models.Routes,
+	shadowRoutes models.Routes,
+) {
+	if len(primaryRoutes) == 0 {
+		sl.ReportError(router.Routes, "Routes", "Routes", "should have at least one route that is not a shadow route", "")
+	}
+
+	shadowRouteIDs := set.New()
+	for _, route := range shadowRoutes {
+		shadowRouteIDs.Insert(route.ID)
+	}
+	if router.DefaultRouteID != nil && shadowRouteIDs.Has(*router.DefaultRouteID) {
+		sl.ReportError(router.DefaultRouteID, "DefaultRouteID", "DefaultRouteID",
+			"should not be a shadow route", *router.DefaultRouteID)
+	}
+	if router.DefaultTrafficRule != nil {
+		for idx, routeID := range router.DefaultTrafficRule.Routes {
+			if shadowRouteIDs.Has(routeID) {
+				sl.ReportError(routeID, fmt.Sprintf("DefaultTrafficRule.Routes[%d]", idx), "Routes",
+					"should not be a shadow route", routeID)
+			}
+		}
+	}
+	for ruleIdx, rule := range router.TrafficRules {
+		for idx, routeID := range rule.Routes {
+			if shadowRouteIDs.Has(routeID) {
+				sl.ReportError(routeID, fmt.Sprintf("TrafficRules[%d].Routes[%d]", ruleIdx, idx), "Routes",
+					"should not be a shadow route", routeID)
+			}
+		}
+	}
+}
+
 func validateUPIRouter(
 	sl validator.StructLevel,
 	router request.RouterConfig,
 ) {
-	if router.Ensembler != nil && router.Ensembler.Type != models.EnsemblerStandardType {
+	if router.Ensembler != nil && router.Ensembler.Type == models.EnsemblerPyFuncType {
 		sl.ReportError(router.Ensembler.Type, "Ensembler.Type", "Type",
-			"only standard ensembler is supported for UPI", "")
+			"pyfunc ensembler is not supported for UPI", "")
+	}
+	if router.Ensembler != nil && router.Ensembler.Type == models.EnsemblerBuiltinType {
+		sl.ReportError(router.Ensembler.Type, "Ensembler.Type", "Type",
+			"builtin ensembler is not supported for UPI", "")
+	}
+	for idx, route := range router.Routes {
+		if route.Shadow {
+			sl.ReportError(route.Shadow, fmt.Sprintf("Routes[%d].Shadow", idx), "Shadow",
+				"shadow routes are not supported for UPI", "")
+		}
 	}
 }
 
@@ -407,4 +978,10 @@ func validateHTTPRouter(sl validator.StructLevel, router request.RouterConfig) {
 		sl.ReportError(router.LogConfig.ResultLoggerType, "LogConfig.ResultLoggerType",
 			"Type", "logger should not be upi", "")
 	}
+	for idx, resultLogger := range router.LogConfig.ResultLoggerTypes {
+		if resultLogger == models.UPILogger {
+			sl.ReportError(resultLogger, fmt.Sprintf("LogConfig.ResultLoggerTypes[%d]", idx),
+				"Type", "logger should not be upi", "")
+		}
+	}
 }
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/go-playground/validator/v10"
+	fiberConfig "github.com/gojek/fiber/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -110,7 +112,148 @@ func TestValidateEnsemblerStandardConfig(t *testing.T) {
 	}
 }
 
+func TestValidateEnsemblerBuiltinConfig(t *testing.T) {
+	tt := map[string]struct {
+		input models.EnsemblerBuiltinConfig
+		err   string
+	}{
+		"failure | strategy undefined": {
+			input: models.EnsemblerBuiltinConfig{},
+			err: "Key: 'EnsemblerBuiltinConfig.Strategy' Error:Field validation for 'Strategy' " +
+				"failed on the 'required' tag\n" +
+				"Key: 'EnsemblerBuiltinConfig.Strategy' Error:Field validation for 'Strategy' " +
+				"failed on the 'oneof' tag",
+		},
+		"failure | unknown strategy": {
+			input: models.EnsemblerBuiltinConfig{Strategy: "median"},
+			err: "Key: 'EnsemblerBuiltinConfig.Strategy' Error:Field validation for 'Strategy' " +
+				"failed on the 'oneof' tag",
+		},
+		"failure | weighted average without value paths": {
+			input: models.EnsemblerBuiltinConfig{Strategy: models.BuiltinWeightedAverageStrategy},
+			err: "Key: 'EnsemblerBuiltinConfig.ValuePaths' Error:Field validation for 'ValuePaths' " +
+				"failed on the 'required for the weighted_average strategy' tag",
+		},
+		"failure | majority vote without label path": {
+			input: models.EnsemblerBuiltinConfig{Strategy: models.BuiltinMajorityVoteStrategy},
+			err: "Key: 'EnsemblerBuiltinConfig.LabelPath' Error:Field validation for 'LabelPath' " +
+				"failed on the 'required for the majority_vote strategy' tag",
+		},
+		"failure | negative weight": {
+			input: models.EnsemblerBuiltinConfig{
+				Strategy: models.BuiltinFirstSuccessStrategy,
+				Weights:  map[string]float64{"route-1": -1},
+			},
+			err: "Key: 'EnsemblerBuiltinConfig.Weights[route-1]' Error:Field validation for 'Weights[route-1]' " +
+				"failed on the 'gte' tag",
+		},
+		"success | weighted average": {
+			input: models.EnsemblerBuiltinConfig{
+				Strategy:   models.BuiltinWeightedAverageStrategy,
+				Weights:    map[string]float64{"route-1": 0.7, "route-2": 0.3},
+				ValuePaths: []string{"predictions.score"},
+			},
+		},
+		"success | majority vote": {
+			input: models.EnsemblerBuiltinConfig{
+				Strategy:  models.BuiltinMajorityVoteStrategy,
+				LabelPath: "predictions.label",
+			},
+		},
+		"success | first success": {
+			input: models.EnsemblerBuiltinConfig{
+				Strategy:      models.BuiltinFirstSuccessStrategy,
+				RoutePriority: []string{"route-2", "route-1"},
+			},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			validate, err := validation.NewValidator(nil)
+			assert.NoError(t, err)
+			err = validate.Struct(tc.input)
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestValidateBuiltinEnsemblerRouterConfig(t *testing.T) {
+	routeA, routeB, routeC := "route-a", "route-b", "route-c"
+	routes := models.Routes{{ID: routeA}, {ID: routeB}}
+
+	suite := map[string]routerConfigTestCase{
+		"success | without default route": {
+			routes: routes,
+			ensembler: &models.Ensembler{
+				Type: models.EnsemblerBuiltinType,
+				BuiltinConfig: &models.EnsemblerBuiltinConfig{
+					Strategy:      models.BuiltinFirstSuccessStrategy,
+					Weights:       map[string]float64{routeA: 1, routeB: 2},
+					RoutePriority: []string{routeB, routeA},
+				},
+			},
+		},
+		"success | with default route": {
+			routes:         routes,
+			defaultRouteID: &routeA,
+			ensembler: &models.Ensembler{
+				Type: models.EnsemblerBuiltinType,
+				BuiltinConfig: &models.EnsemblerBuiltinConfig{
+					Strategy: models.BuiltinFirstSuccessStrategy,
+				},
+			},
+		},
+		"failure | unknown default route": {
+			routes:         routes,
+			defaultRouteID: &routeC,
+			ensembler: &models.Ensembler{
+				Type: models.EnsemblerBuiltinType,
+				BuiltinConfig: &models.EnsemblerBuiltinConfig{
+					Strategy: models.BuiltinFirstSuccessStrategy,
+				},
+			},
+			expectedError: "Key: 'RouterConfig.DefaultRouteID' Error:Field validation for '' failed on the 'oneof' tag",
+		},
+		"failure | unknown routes": {
+			routes: routes,
+			ensembler: &models.Ensembler{
+				Type: models.EnsemblerBuiltinType,
+				BuiltinConfig: &models.EnsemblerBuiltinConfig{
+					Strategy:      models.BuiltinFirstSuccessStrategy,
+					Weights:       map[string]float64{"route-c": 1},
+					RoutePriority: []string{routeA, "route-d"},
+				},
+			},
+			expectedError: strings.Join([]string{
+				"Key: 'RouterConfig.Ensembler.BuiltinConfig.Weights[route-c]' " +
+					"Error:Field validation for '' failed on the 'oneof' tag",
+				"Key: 'RouterConfig.Ensembler.BuiltinConfig.RoutePriority[1]' " +
+					"Error:Field validation for '' failed on the 'oneof' tag",
+			}, "\n"),
+		},
+	}
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			validate, err := getDefaultValidator()
+			require.NoError(t, err)
+
+			err = validate.Struct(tt.RouterConfig())
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
 func TestValidateLogConfig(t *testing.T) {
+	samplingRate, zeroSamplingRate, invalidSamplingRate := 0.1, 0.0, 1.5
 	tt := map[string]struct {
 		input  request.LogConfig
 		hasErr bool
@@ -217,6 +360,137 @@ func TestValidateLogConfig(t *testing.T) {
 			},
 			hasErr: true,
 		},
+		"multiple_valid_config": {
+			input: request.LogConfig{
+				ResultLoggerTypes: []models.ResultLogger{"kafka", "bigquery"},
+				KafkaConfig: &request.KafkaConfig{
+					Brokers:             "broker1,broker2",
+					Topic:               "topic",
+					SerializationFormat: "json",
+				},
+				BigQueryConfig: &request.BigQueryConfig{
+					Table:                "project.dataset.table",
+					ServiceAccountSecret: "acc",
+				},
+			},
+			hasErr: false,
+		},
+		"multiple_valid_upi": {
+			input: request.LogConfig{
+				ResultLoggerType:  "upi",
+				ResultLoggerTypes: []models.ResultLogger{"upi", "bigquery"},
+				BigQueryConfig: &request.BigQueryConfig{
+					Table:                "project.dataset.table",
+					ServiceAccountSecret: "acc",
+				},
+			},
+			hasErr: false,
+		},
+		"multiple_missing_config": {
+			input: request.LogConfig{
+				ResultLoggerTypes: []models.ResultLogger{"kafka", "bigquery"},
+				KafkaConfig: &request.KafkaConfig{
+					Brokers:             "broker1,broker2",
+					Topic:               "topic",
+					SerializationFormat: "json",
+				},
+			},
+			hasErr: true,
+		},
+		"multiple_duplicate_type": {
+			input: request.LogConfig{
+				ResultLoggerTypes: []models.ResultLogger{"upi", "upi"},
+			},
+			hasErr: true,
+		},
+		"multiple_invalid_type": {
+			input: request.LogConfig{
+				ResultLoggerTypes: []models.ResultLogger{"upi", "nop"},
+			},
+			hasErr: true,
+		},
+		"multiple_kafka_and_upi": {
+			input: request.LogConfig{
+				ResultLoggerTypes: []models.ResultLogger{"kafka", "upi"},
+				KafkaConfig: &request.KafkaConfig{
+					Brokers:             "broker1,broker2",
+					Topic:               "topic",
+					SerializationFormat: "json",
+				},
+			},
+			hasErr: true,
+		},
+		"multiple_type_not_listed": {
+			input: request.LogConfig{
+				ResultLoggerType:  "nop",
+				ResultLoggerTypes: []models.ResultLogger{"upi"},
+			},
+			hasErr: true,
+		},
+		"sampling_and_redactions_valid": {
+			input: request.LogConfig{
+				ResultLoggerType: "upi",
+				SamplingRate:     &samplingRate,
+				SamplingUnit: &models.ResultLogSamplingUnit{
+					FieldSource: expRequest.HeaderFieldSource,
+					Field:       "X-User-ID",
+				},
+				Redactions: []models.ResultLogRedaction{
+					{FieldSource: expRequest.HeaderFieldSource, Field: "X-Phone", Action: routerConfig.DropRedactionAction},
+					{FieldSource: expRequest.PayloadFieldSource, Field: "customer.email", Action: routerConfig.HashRedactionAction},
+				},
+				HashKeySecret: "hash-key",
+			},
+			hasErr: false,
+		},
+		"hash_redaction_missing_key": {
+			input: request.LogConfig{
+				ResultLoggerType: "upi",
+				Redactions: []models.ResultLogRedaction{
+					{FieldSource: expRequest.PayloadFieldSource, Field: "customer.email", Action: routerConfig.HashRedactionAction},
+				},
+			},
+			hasErr: true,
+		},
+		"sampling_rate_zero": {
+			input: request.LogConfig{
+				ResultLoggerType: "upi",
+				SamplingRate:     &zeroSamplingRate,
+			},
+			hasErr: true,
+		},
+		"sampling_rate_above_one": {
+			input: request.LogConfig{
+				ResultLoggerType: "upi",
+				SamplingRate:     &invalidSamplingRate,
+			},
+			hasErr: true,
+		},
+		"sampling_unit_missing_field": {
+			input: request.LogConfig{
+				ResultLoggerType: "upi",
+				SamplingUnit:     &models.ResultLogSamplingUnit{FieldSource: expRequest.HeaderFieldSource},
+			},
+			hasErr: true,
+		},
+		"redaction_invalid_action": {
+			input: request.LogConfig{
+				ResultLoggerType: "upi",
+				Redactions: []models.ResultLogRedaction{
+					{FieldSource: expRequest.HeaderFieldSource, Field: "X-Phone", Action: "encrypt"},
+				},
+			},
+			hasErr: true,
+		},
+		"redaction_invalid_field_source": {
+			input: request.LogConfig{
+				ResultLoggerType: "upi",
+				Redactions: []models.ResultLogRedaction{
+					{FieldSource: expRequest.CallerFieldSource, Field: "id", Action: routerConfig.MaskRedactionAction},
+				},
+			},
+			hasErr: true,
+		},
 	}
 
 	for name, tc := range tt {
@@ -299,7 +573,15 @@ type routerConfigTestCase struct {
 	defaultRouteID     *string
 	defaultTrafficRule *models.DefaultTrafficRule
 	trafficRules       models.TrafficRules
+	trafficSplitUnit   *models.TrafficSplitUnit
+	bandit             *models.BanditConfig
 	autoscalingPolicy  *models.AutoscalingPolicy
+	responseCache      *models.ResponseCacheConfig
+	processingStages   models.ProcessingStages
+	auth               *models.AuthConfig
+	rateLimit          *models.RateLimitConfig
+	fallbackResponse   *models.FallbackResponse
+	resourceRequest    *models.ResourceRequest
 	expectedError      string
 	logConfig          *request.LogConfig
 }
@@ -319,6 +601,8 @@ func (tt routerConfigTestCase) RouterConfig() *request.RouterConfig {
 		DefaultRouteID:     tt.defaultRouteID,
 		DefaultTrafficRule: tt.defaultTrafficRule,
 		TrafficRules:       tt.trafficRules,
+		TrafficSplitUnit:   tt.trafficSplitUnit,
+		Bandit:             tt.bandit,
 		AutoscalingPolicy:  tt.autoscalingPolicy,
 		ExperimentEngine:   experimentEngine,
 		Timeout:            "20s",
@@ -326,6 +610,12 @@ func (tt routerConfigTestCase) RouterConfig() *request.RouterConfig {
 		LogConfig:          tt.logConfig,
 		Enricher:           tt.enricher,
 		Ensembler:          tt.ensembler,
+		ResponseCache:      tt.responseCache,
+		ProcessingStages:   tt.processingStages,
+		Auth:               tt.auth,
+		RateLimit:          tt.rateLimit,
+		FallbackResponse:   tt.fallbackResponse,
+		ResourceRequest:    tt.resourceRequest,
 	}
 }
 
@@ -352,6 +642,7 @@ func TestValidateTrafficRules(t *testing.T) {
 	defaultTrafficRule := &models.DefaultTrafficRule{
 		Routes: []string{routeAID, routeBID},
 	}
+	stableWeight, canaryWeight := 90, 10
 
 	suite := map[string]routerConfigTestCase{
 		"success": {
@@ -853,7 +1144,7 @@ func TestValidateTrafficRules(t *testing.T) {
 				"'Fallback Route (DefaultRouteId): 'route-a' should be associated to all Traffic Rules' tag",
 			}, " "),
 		},
-		"failure | Overlapping Traffic Rules": {
+		"success | non-overlapping version ranges": {
 			routes:             models.Routes{routeA, routeB, routeC},
 			defaultRouteID:     &routeAID,
 			defaultTrafficRule: defaultTrafficRule,
@@ -863,31 +1154,46 @@ func TestValidateTrafficRules(t *testing.T) {
 					Conditions: []*router.TrafficRuleCondition{
 						{
 							FieldSource: expRequest.HeaderFieldSource,
-							Field:       "X-Region",
-							Operator:    router.InConditionOperator,
-							Values:      []string{"region-a"},
+							Field:       "X-App-Version",
+							Operator:    router.VersionLessThanConditionOperator,
+							Values:      []string{"4.2"},
 						},
 					},
 					Routes: []string{routeAID, routeBID},
 				},
+				{
+					Name: "rule-b",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-App-Version",
+							Operator:    router.VersionGreaterThanOrEqualConditionOperator,
+							Values:      []string{"4.2.0"},
+						},
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-App-Version",
+							Operator:    router.VersionLessThanConditionOperator,
+							Values:      []string{"5"},
+						},
+					},
+					Routes: []string{routeAID, routeCID},
+				},
 				{
 					Name: "rule-c",
 					Conditions: []*router.TrafficRuleCondition{
 						{
 							FieldSource: expRequest.HeaderFieldSource,
-							Field:       "X-Region",
-							Operator:    router.InConditionOperator,
-							Values:      []string{"region-a"},
+							Field:       "X-App-Version",
+							Operator:    router.VersionGreaterThanOrEqualConditionOperator,
+							Values:      []string{"5.0.0"},
 						},
 					},
 					Routes: []string{routeAID, routeCID},
 				},
 			},
-			expectedError: "Key: 'RouterConfig.TrafficRules' Error:Field validation for 'TrafficRules' " +
-				"failed on the 'Rules Orthogonality check failed, following pairs of rules are overlapping - " +
-				"(rule-a,rule-c).' tag",
 		},
-		"failure | Complex overlapping Traffic Rules": {
+		"success | non-overlapping regex and values": {
 			routes:             models.Routes{routeA, routeB, routeC},
 			defaultRouteID:     &routeAID,
 			defaultTrafficRule: defaultTrafficRule,
@@ -897,15 +1203,9 @@ func TestValidateTrafficRules(t *testing.T) {
 					Conditions: []*router.TrafficRuleCondition{
 						{
 							FieldSource: expRequest.HeaderFieldSource,
-							Field:       "X-Country",
-							Operator:    router.InConditionOperator,
-							Values:      []string{"ID"},
-						},
-						{
-							FieldSource: expRequest.HeaderFieldSource,
-							Field:       "X-Region",
-							Operator:    router.InConditionOperator,
-							Values:      []string{"region-a"},
+							Field:       "X-User",
+							Operator:    router.MatchesConditionOperator,
+							Values:      []string{"^test-"},
 						},
 					},
 					Routes: []string{routeAID, routeBID},
@@ -915,59 +1215,931 @@ func TestValidateTrafficRules(t *testing.T) {
 					Conditions: []*router.TrafficRuleCondition{
 						{
 							FieldSource: expRequest.HeaderFieldSource,
-							Field:       "X-Country",
+							Field:       "X-User",
 							Operator:    router.InConditionOperator,
-							Values:      []string{"ID", "SG"},
+							Values:      []string{"user-1", "user-2"},
 						},
+					},
+					Routes: []string{routeAID, routeCID},
+				},
+			},
+		},
+		"failure | regexes on the same field": {
+			routes:             models.Routes{routeA, routeB, routeC},
+			defaultRouteID:     &routeAID,
+			defaultTrafficRule: defaultTrafficRule,
+			trafficRules: models.TrafficRules{
+				{
+					Name: "rule-a",
+					Conditions: []*router.TrafficRuleCondition{
 						{
 							FieldSource: expRequest.HeaderFieldSource,
-							Field:       "X-Region",
-							Operator:    router.InConditionOperator,
-							Values:      []string{"region-a", "region-b", "region-c"},
+							Field:       "X-User",
+							Operator:    router.MatchesConditionOperator,
+							Values:      []string{"^test-"},
 						},
 					},
 					Routes: []string{routeAID, routeBID},
 				},
 				{
-					Name: "rule-c",
+					Name: "rule-b",
 					Conditions: []*router.TrafficRuleCondition{
 						{
 							FieldSource: expRequest.HeaderFieldSource,
-							Field:       "X-Country",
-							Operator:    router.InConditionOperator,
-							Values:      []string{"SG"},
+							Field:       "X-User",
+							Operator:    router.MatchesConditionOperator,
+							Values:      []string{"^test-a"},
 						},
+					},
+					Routes: []string{routeAID, routeCID},
+				},
+			},
+			expectedError: strings.Join([]string{
+				"Key: 'RouterConfig.TrafficRules' Error:Field validation for 'TrafficRules' failed on the",
+				"'Rules Orthogonality check failed, following pairs of rules are overlapping - (rule-a,rule-b).' tag",
+			}, " "),
+		},
+		"failure | regex and exists on the same field": {
+			routes:             models.Routes{routeA, routeB, routeC},
+			defaultRouteID:     &routeAID,
+			defaultTrafficRule: defaultTrafficRule,
+			trafficRules: models.TrafficRules{
+				{
+					Name: "rule-a",
+					Conditions: []*router.TrafficRuleCondition{
 						{
 							FieldSource: expRequest.HeaderFieldSource,
-							Field:       "X-Region",
-							Operator:    router.InConditionOperator,
-							Values:      []string{"region-a"},
+							Field:       "X-User",
+							Operator:    router.MatchesConditionOperator,
+							Values:      []string{"^test-"},
 						},
 					},
-					Routes: []string{routeAID, routeCID},
+					Routes: []string{routeAID, routeBID},
 				},
 				{
-					Name: "rule-d",
+					Name: "rule-b",
 					Conditions: []*router.TrafficRuleCondition{
 						{
 							FieldSource: expRequest.HeaderFieldSource,
-							Field:       "X-Country",
-							Operator:    router.InConditionOperator,
-							Values:      []string{"ID"},
+							Field:       "X-User",
+							Operator:    router.ExistsConditionOperator,
+							Values:      nil,
 						},
+					},
+					Routes: []string{routeAID, routeCID},
+				},
+			},
+			expectedError: strings.Join([]string{
+				"Key: 'RouterConfig.TrafficRules' Error:Field validation for 'TrafficRules' failed on the",
+				"'Rules Orthogonality check failed, following pairs of rules are overlapping - (rule-a,rule-b).' tag",
+			}, " "),
+		},
+		"failure | regex and not_in on the same field": {
+			routes:             models.Routes{routeA, routeB, routeC},
+			defaultRouteID:     &routeAID,
+			defaultTrafficRule: defaultTrafficRule,
+			trafficRules: models.TrafficRules{
+				{
+					Name: "rule-a",
+					Conditions: []*router.TrafficRuleCondition{
 						{
 							FieldSource: expRequest.HeaderFieldSource,
-							Field:       "X-Region",
-							Operator:    router.InConditionOperator,
-							Values:      []string{"region-b"},
+							Field:       "X-User",
+							Operator:    router.MatchesConditionOperator,
+							Values:      []string{"^test-"},
 						},
 					},
 					Routes: []string{routeAID, routeBID},
 				},
+				{
+					Name: "rule-b",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-User",
+							Operator:    router.NotInConditionOperator,
+							Values:      []string{"user-1"},
+						},
+					},
+					Routes: []string{routeAID, routeCID},
+				},
+			},
+			expectedError: strings.Join([]string{
+				"Key: 'RouterConfig.TrafficRules' Error:Field validation for 'TrafficRules' failed on the",
+				"'Rules Orthogonality check failed, following pairs of rules are overlapping - (rule-a,rule-b).' tag",
+			}, " "),
+		},
+		"failure | regex and prefix on the same field": {
+			routes:             models.Routes{routeA, routeB, routeC},
+			defaultRouteID:     &routeAID,
+			defaultTrafficRule: defaultTrafficRule,
+			trafficRules: models.TrafficRules{
+				{
+					Name: "rule-a",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-User",
+							Operator:    router.MatchesConditionOperator,
+							Values:      []string{"^test-"},
+						},
+					},
+					Routes: []string{routeAID, routeBID},
+				},
+				{
+					Name: "rule-b",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-User",
+							Operator:    router.PrefixConditionOperator,
+							Values:      []string{"test"},
+						},
+					},
+					Routes: []string{routeAID, routeCID},
+				},
+			},
+			expectedError: strings.Join([]string{
+				"Key: 'RouterConfig.TrafficRules' Error:Field validation for 'TrafficRules' failed on the",
+				"'Rules Orthogonality check failed, following pairs of rules are overlapping - (rule-a,rule-b).' tag",
+			}, " "),
+		},
+		"failure | regex and numeric comparison on the same field": {
+			routes:             models.Routes{routeA, routeB, routeC},
+			defaultRouteID:     &routeAID,
+			defaultTrafficRule: defaultTrafficRule,
+			trafficRules: models.TrafficRules{
+				{
+					Name: "rule-a",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-User",
+							Operator:    router.MatchesConditionOperator,
+							Values:      []string{"^test-"},
+						},
+					},
+					Routes: []string{routeAID, routeBID},
+				},
+				{
+					Name: "rule-b",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-User",
+							Operator:    router.GreaterThanConditionOperator,
+							Values:      []string{"10"},
+						},
+					},
+					Routes: []string{routeAID, routeCID},
+				},
+			},
+			expectedError: strings.Join([]string{
+				"Key: 'RouterConfig.TrafficRules' Error:Field validation for 'TrafficRules' failed on the",
+				"'Rules Orthogonality check failed, following pairs of rules are overlapping - (rule-a,rule-b).' tag",
+			}, " "),
+		},
+		"failure | overlapping traffic rules with new operators": {
+			routes:             models.Routes{routeA, routeB, routeC},
+			defaultRouteID:     &routeAID,
+			defaultTrafficRule: defaultTrafficRule,
+			trafficRules: models.TrafficRules{
+				{
+					Name: "rule-a",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Region",
+							Operator:    router.NotInConditionOperator,
+							Values:      []string{"region-a"},
+						},
+					},
+					Routes: []string{routeAID, routeBID},
+				},
+				{
+					Name: "rule-b",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Region",
+							Operator:    router.InConditionOperator,
+							Values:      []string{"region-a", "region-b"},
+						},
+					},
+					Routes: []string{routeAID, routeCID},
+				},
+				{
+					Name: "rule-c",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Region",
+							Operator:    router.ExistsConditionOperator,
+							Values:      nil,
+						},
+					},
+					Routes: []string{routeAID, routeCID},
+				},
+				{
+					Name: "rule-d",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Score",
+							Operator:    router.GreaterThanConditionOperator,
+							Values:      []string{"0.5"},
+						},
+					},
+					Routes: []string{routeAID, routeBID},
+				},
+				{
+					Name: "rule-e",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Score",
+							Operator:    router.LessThanOrEqualConditionOperator,
+							Values:      []string{"0.5"},
+						},
+					},
+					Routes: []string{routeAID, routeBID},
+				},
+				{
+					Name: "rule-f",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Score",
+							Operator:    router.LessThanConditionOperator,
+							Values:      []string{"0.6"},
+						},
+					},
+					Routes: []string{routeAID, routeBID},
+				},
+			},
+			expectedError: "Key: 'RouterConfig.TrafficRules' Error:Field validation for 'TrafficRules' " +
+				"failed on the 'Rules Orthogonality check failed, following pairs of rules are overlapping - " +
+				"(rule-a,rule-b), (rule-a,rule-c), (rule-b,rule-c), (rule-d,rule-f), (rule-e,rule-f).' tag",
+		},
+		"success | weighted traffic rules": {
+			routes:             models.Routes{routeA, routeB, routeC},
+			defaultRouteID:     &routeAID,
+			defaultTrafficRule: defaultTrafficRule,
+			trafficRules: models.TrafficRules{
+				{
+					Name:   "stable",
+					Weight: &stableWeight,
+					Routes: []string{routeAID, routeBID},
+				},
+				{
+					Name:   "canary",
+					Weight: &canaryWeight,
+					Routes: []string{routeAID, routeCID},
+				},
+			},
+			trafficSplitUnit: &models.TrafficSplitUnit{
+				FieldSource: expRequest.HeaderFieldSource,
+				Field:       "X-User-Id",
+			},
+		},
+		"failure | weights don't sum up to 100": {
+			routes:             models.Routes{routeA, routeB, routeC},
+			defaultRouteID:     &routeAID,
+			defaultTrafficRule: defaultTrafficRule,
+			trafficRules: models.TrafficRules{
+				{
+					Name:   "stable",
+					Weight: &stableWeight,
+					Routes: []string{routeAID, routeBID},
+				},
+				{
+					Name:   "canary",
+					Weight: &stableWeight,
+					Routes: []string{routeAID, routeCID},
+				},
+			},
+			trafficSplitUnit: &models.TrafficSplitUnit{
+				FieldSource: expRequest.HeaderFieldSource,
+				Field:       "X-User-Id",
+			},
+			expectedError: "Key: 'RouterConfig.TrafficRules' Error:Field validation for 'TrafficRules' " +
+				"failed on the 'weights of the traffic rules should sum up to 100' tag",
+		},
+		"failure | weighted traffic rules without unit": {
+			routes:             models.Routes{routeA, routeB, routeC},
+			defaultRouteID:     &routeAID,
+			defaultTrafficRule: defaultTrafficRule,
+			trafficRules: models.TrafficRules{
+				{
+					Name:   "stable",
+					Weight: &stableWeight,
+					Routes: []string{routeAID, routeBID},
+				},
+				{
+					Name:   "canary",
+					Weight: &canaryWeight,
+					Routes: []string{routeAID, routeCID},
+				},
+			},
+			expectedError: "Key: 'RouterConfig.traffic_split_unit' Error:Field validation for " +
+				"'traffic_split_unit' failed on the 'should be set for weighted traffic rules' tag",
+		},
+		"failure | weighted traffic rules with invalid unit field source": {
+			routes:             models.Routes{routeA, routeB, routeC},
+			defaultRouteID:     &routeAID,
+			defaultTrafficRule: defaultTrafficRule,
+			trafficRules: models.TrafficRules{
+				{
+					Name:   "stable",
+					Weight: &stableWeight,
+					Routes: []string{routeAID, routeBID},
+				},
+				{
+					Name:   "canary",
+					Weight: &canaryWeight,
+					Routes: []string{routeAID, routeCID},
+				},
+			},
+			trafficSplitUnit: &models.TrafficSplitUnit{
+				FieldSource: expRequest.PredictionContextSource,
+				Field:       "user_id",
+			},
+			expectedError: "Key: 'RouterConfig.TrafficSplitUnit.FieldSource' " +
+				"Error:Field validation for 'TrafficSplitUnit.FieldSource' failed on the 'oneof' tag",
+		},
+		"failure | weighted traffic rule with conditions": {
+			routes:             models.Routes{routeA, routeB, routeC},
+			defaultRouteID:     &routeAID,
+			defaultTrafficRule: defaultTrafficRule,
+			trafficRules: models.TrafficRules{
+				{
+					Name:   "stable",
+					Weight: &stableWeight,
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Region",
+							Operator:    router.InConditionOperator,
+							Values:      []string{"region-a"},
+						},
+					},
+					Routes: []string{routeAID, routeBID},
+				},
+				{
+					Name:   "canary",
+					Weight: &canaryWeight,
+					Routes: []string{routeAID, routeCID},
+				},
+			},
+			trafficSplitUnit: &models.TrafficSplitUnit{
+				FieldSource: expRequest.HeaderFieldSource,
+				Field:       "X-User-Id",
+			},
+			expectedError: "Key: 'RouterConfig.TrafficRules[0].Conditions' Error:Field validation for " +
+				"'Conditions' failed on the 'should not be set for weighted traffic rule' tag",
+		},
+		"failure | mixed weighted and conditional traffic rules": {
+			routes:             models.Routes{routeA, routeB, routeC},
+			defaultRouteID:     &routeAID,
+			defaultTrafficRule: defaultTrafficRule,
+			trafficRules: models.TrafficRules{
+				{
+					Name: "conditional",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Region",
+							Operator:    router.InConditionOperator,
+							Values:      []string{"region-a"},
+						},
+					},
+					Routes: []string{routeAID, routeBID},
+				},
+				{
+					Name:   "canary",
+					Weight: &canaryWeight,
+					Routes: []string{routeAID, routeCID},
+				},
+			},
+			trafficSplitUnit: &models.TrafficSplitUnit{
+				FieldSource: expRequest.HeaderFieldSource,
+				Field:       "X-User-Id",
+			},
+			expectedError: "Key: 'RouterConfig.TrafficRules[0].Weight' Error:Field validation for " +
+				"'TrafficRules[0].Weight' failed on the " +
+				"'should be set for all traffic rules if any of them is weighted' tag",
+		},
+		"failure | Overlapping Traffic Rules": {
+			routes:             models.Routes{routeA, routeB, routeC},
+			defaultRouteID:     &routeAID,
+			defaultTrafficRule: defaultTrafficRule,
+			trafficRules: models.TrafficRules{
+				{
+					Name: "rule-a",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Region",
+							Operator:    router.InConditionOperator,
+							Values:      []string{"region-a"},
+						},
+					},
+					Routes: []string{routeAID, routeBID},
+				},
+				{
+					Name: "rule-c",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Region",
+							Operator:    router.InConditionOperator,
+							Values:      []string{"region-a"},
+						},
+					},
+					Routes: []string{routeAID, routeCID},
+				},
+			},
+			expectedError: "Key: 'RouterConfig.TrafficRules' Error:Field validation for 'TrafficRules' " +
+				"failed on the 'Rules Orthogonality check failed, following pairs of rules are overlapping - " +
+				"(rule-a,rule-c).' tag",
+		},
+		"failure | Complex overlapping Traffic Rules": {
+			routes:             models.Routes{routeA, routeB, routeC},
+			defaultRouteID:     &routeAID,
+			defaultTrafficRule: defaultTrafficRule,
+			trafficRules: models.TrafficRules{
+				{
+					Name: "rule-a",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Country",
+							Operator:    router.InConditionOperator,
+							Values:      []string{"ID"},
+						},
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Region",
+							Operator:    router.InConditionOperator,
+							Values:      []string{"region-a"},
+						},
+					},
+					Routes: []string{routeAID, routeBID},
+				},
+				{
+					Name: "rule-b",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Country",
+							Operator:    router.InConditionOperator,
+							Values:      []string{"ID", "SG"},
+						},
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Region",
+							Operator:    router.InConditionOperator,
+							Values:      []string{"region-a", "region-b", "region-c"},
+						},
+					},
+					Routes: []string{routeAID, routeBID},
+				},
+				{
+					Name: "rule-c",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Country",
+							Operator:    router.InConditionOperator,
+							Values:      []string{"SG"},
+						},
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Region",
+							Operator:    router.InConditionOperator,
+							Values:      []string{"region-a"},
+						},
+					},
+					Routes: []string{routeAID, routeCID},
+				},
+				{
+					Name: "rule-d",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Country",
+							Operator:    router.InConditionOperator,
+							Values:      []string{"ID"},
+						},
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Region",
+							Operator:    router.InConditionOperator,
+							Values:      []string{"region-b"},
+						},
+					},
+					Routes: []string{routeAID, routeBID},
+				},
+			},
+			expectedError: "Key: 'RouterConfig.TrafficRules' Error:Field validation for 'TrafficRules' " +
+				"failed on the 'Rules Orthogonality check failed, following pairs of rules are overlapping - " +
+				"(rule-a,rule-b), (rule-b,rule-c), (rule-b,rule-d).' tag",
+		},
+	}
+
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			validate, err := getDefaultValidator()
+			require.NoError(t, err)
+
+			err = validate.Struct(tt.RouterConfig())
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestValidateAutoscaling(t *testing.T) {
+	enricherBasic := request.EnricherEnsemblerConfig{
+		Image: "lala",
+		ResourceRequest: &models.ResourceRequest{
+			MinReplica: 0,
+			MaxReplica: 5,
+			CPURequest: resource.Quantity{
+				Format: "500M",
+			},
+			MemoryRequest: resource.Quantity{
+				Format: "1G",
+			},
+		},
+		Endpoint: "endpoint",
+		Timeout:  "6s",
+		Port:     8080,
+		Env: []*models.EnvVar{
+			{
+				Name:  "key",
+				Value: "value",
+			},
+		},
+		Secrets: []models.Secret{},
+	}
+	makeEnricher := func(
+		enricher request.EnricherEnsemblerConfig,
+		policy *models.AutoscalingPolicy,
+	) *request.EnricherEnsemblerConfig {
+		newEnr := enricher
+		newEnr.AutoscalingPolicy = policy
+		return &newEnr
+	}
+	routeID := "abc"
+	route := &models.Route{
+		ID:       routeID,
+		Type:     "PROXY",
+		Endpoint: "http://example.com/a",
+		Timeout:  "10ms",
+	}
+	id := models.ID(1)
+	suite := map[string]routerConfigTestCase{
+		"success | no autoscaling policy | all components": {
+			routes:   models.Routes{route},
+			enricher: &enricherBasic,
+			ensembler: &models.Ensembler{
+				Type: models.EnsemblerDockerType,
+			},
+		},
+		"success | no autoscaling policy | pyfunc ensembler": {
+			routes: models.Routes{route},
+			ensembler: &models.Ensembler{
+				Type: models.EnsemblerPyFuncType,
+				PyfuncConfig: &models.EnsemblerPyfuncConfig{
+					ProjectID:       &id,
+					EnsemblerID:     &id,
+					ResourceRequest: &models.ResourceRequest{},
+					Timeout:         "1s",
+					Env:             models.EnvVars{},
+					Secrets:         []models.Secret{},
+				},
+			},
+		},
+		"success | valid autoscaling policy | all components": {
+			routes: models.Routes{route},
+			autoscalingPolicy: &models.AutoscalingPolicy{
+				Metric: models.AutoscalingMetricCPU,
+				Target: "90",
+			},
+			enricher: makeEnricher(enricherBasic, &models.AutoscalingPolicy{
+				Metric: models.AutoscalingMetricConcurrency,
+				Target: "2",
+			}),
+			ensembler: &models.Ensembler{
+				Type: models.EnsemblerDockerType,
+				DockerConfig: &models.EnsemblerDockerConfig{
+					Endpoint: "http://abc.com",
+					Port:     8080,
+					Image:    "nginx",
+					ResourceRequest: &models.ResourceRequest{
+						CPURequest:    resource.Quantity{Format: "500m"},
+						MemoryRequest: resource.Quantity{Format: "1Gi"},
+					},
+					AutoscalingPolicy: &models.AutoscalingPolicy{
+						Metric: models.AutoscalingMetricRPS,
+						Target: "400",
+					},
+					Timeout: "5s",
+					Env:     models.EnvVars{},
+					Secrets: models.Secrets{},
+				},
+			},
+		},
+		"success | valid autoscaling policy | pyfunc ensembler": {
+			routes: models.Routes{route},
+			ensembler: &models.Ensembler{
+				Type: models.EnsemblerPyFuncType,
+				PyfuncConfig: &models.EnsemblerPyfuncConfig{
+					ProjectID:       &id,
+					EnsemblerID:     &id,
+					ResourceRequest: &models.ResourceRequest{},
+					AutoscalingPolicy: &models.AutoscalingPolicy{
+						Metric: models.AutoscalingMetricMemory,
+						Target: "80",
+					},
+					Timeout: "1s",
+					Env:     models.EnvVars{},
+					Secrets: models.Secrets{},
+				},
+			},
+		},
+		"failure | invalid autoscaling metric": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			autoscalingPolicy: &models.AutoscalingPolicy{
+				Metric: "abc",
+				Target: "100",
+			},
+			expectedError: strings.Join([]string{"Key: 'RouterConfig.AutoscalingPolicy.Metric' ",
+				"Error:Field validation for 'Metric' failed on the 'oneof' tag"}, ""),
+		},
+		"failure | invalid autoscaling target": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			autoscalingPolicy: &models.AutoscalingPolicy{
+				Metric: models.AutoscalingMetricRPS,
+				Target: "hundred",
+			},
+			expectedError: strings.Join([]string{"Key: 'RouterConfig.AutoscalingPolicy.Target' ",
+				"Error:Field validation for 'Target' failed on the 'numeric' tag"}, ""),
+		},
+	}
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			validate, err := getDefaultValidator()
+			require.NoError(t, err)
+
+			err = validate.Struct(tt.RouterConfig())
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestValidateStdEnsemblerNotConfiguredForNopExpEngine(t *testing.T) {
+	routeID := "abc"
+	route := &models.Route{
+		ID:       routeID,
+		Type:     "PROXY",
+		Endpoint: "http://example.com/a",
+		Timeout:  "10ms",
+	}
+
+	suite := map[string]routerConfigTestCase{
+		"success": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			experimentEngine: &request.ExperimentEngineConfig{
+				Type: "custom",
+			},
+			ensembler: &models.Ensembler{
+				Type: models.EnsemblerStandardType,
+				StandardConfig: &models.EnsemblerStandardConfig{
+					RouteNamePath: "route-1",
+				},
+			},
+		},
+		"failure | standard ensembler is configured with nop exp engine": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			ensembler: &models.Ensembler{
+				Type: models.EnsemblerStandardType,
+				StandardConfig: &models.EnsemblerStandardConfig{
+					RouteNamePath: "route-1",
+				},
+			},
+			expectedError: strings.Join([]string{"Key: 'RouterConfig.Ensembler.Type' ",
+				"Error:Field validation for 'Ensembler.Type' failed on the ",
+				"'should not be standard if an nop experiment engine is configured' tag"}, ""),
+		},
+	}
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			validate, err := getDefaultValidator()
+			require.NoError(t, err)
+
+			err = validate.Struct(tt.RouterConfig())
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestValidateRoutePolicies(t *testing.T) {
+	routeID := "route-a"
+	newRoute := func(retry *router.RetryPolicy, breaker *router.CircuitBreakerConfig) *models.Route {
+		return &models.Route{
+			ID:             routeID,
+			Type:           "PROXY",
+			Endpoint:       "http://example.com/a",
+			Timeout:        "10ms",
+			RetryPolicy:    retry,
+			CircuitBreaker: breaker,
+		}
+	}
+
+	suite := map[string]routerConfigTestCase{
+		"success": {
+			routes: models.Routes{newRoute(
+				&router.RetryPolicy{MaxAttempts: 3, RetryableStatusCodes: []int{503}},
+				&router.CircuitBreakerConfig{
+					FailureThreshold: 5,
+					OpenInterval:     fiberConfig.Duration(30 * time.Second),
+					HalfOpenProbes:   1,
+				},
+			)},
+			defaultRouteID: &routeID,
+		},
+		"failure | invalid retry policy": {
+			routes:         models.Routes{newRoute(&router.RetryPolicy{}, nil)},
+			defaultRouteID: &routeID,
+			expectedError: "Key: 'RouterConfig.Routes[0].RetryPolicy.MaxAttempts' Error:Field validation for " +
+				"'MaxAttempts' failed on the 'required' tag",
+		},
+		"failure | invalid circuit breaker": {
+			routes: models.Routes{newRoute(nil, &router.CircuitBreakerConfig{
+				FailureThreshold: 5,
+				HalfOpenProbes:   1,
+			})},
+			defaultRouteID: &routeID,
+			expectedError: "Key: 'RouterConfig.Routes[0].CircuitBreaker.OpenInterval' Error:Field validation for " +
+				"'OpenInterval' failed on the 'required' tag",
+		},
+	}
+
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			validate, err := getDefaultValidator()
+			require.NoError(t, err)
+
+			err = validate.Struct(tt.RouterConfig())
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestValidateRouteHedgingPolicies(t *testing.T) {
+	routeID := "route-a"
+	newRoute := func(hedging *router.HedgingPolicy) *models.Route {
+		return &models.Route{
+			ID:            routeID,
+			Type:          "PROXY",
+			Endpoint:      "http://example.com/a",
+			Timeout:       "100ms",
+			HedgingPolicy: hedging,
+		}
+	}
+
+	suite := map[string]routerConfigTestCase{
+		"success": {
+			routes: models.Routes{newRoute(&router.HedgingPolicy{
+				Delay:           fiberConfig.Duration(50 * time.Millisecond),
+				MaxHedgePercent: 10,
+			})},
+			defaultRouteID: &routeID,
+		},
+		"failure | max hedge percent out of range": {
+			routes: models.Routes{newRoute(&router.HedgingPolicy{
+				Delay:           fiberConfig.Duration(50 * time.Millisecond),
+				MaxHedgePercent: 150,
+			})},
+			defaultRouteID: &routeID,
+			expectedError: "Key: 'RouterConfig.Routes[0].HedgingPolicy.MaxHedgePercent' Error:Field validation for " +
+				"'MaxHedgePercent' failed on the 'lte' tag",
+		},
+		"failure | delay not shorter than the timeout": {
+			routes: models.Routes{newRoute(&router.HedgingPolicy{
+				Delay:           fiberConfig.Duration(100 * time.Millisecond),
+				MaxHedgePercent: 10,
+			})},
+			defaultRouteID: &routeID,
+			expectedError: "Key: 'RouterConfig.Routes[0].HedgingPolicy.Delay' Error:Field validation for " +
+				"'Routes[0].HedgingPolicy.Delay' failed on the 'should be shorter than the route timeout' tag",
+		},
+	}
+
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			validate, err := getDefaultValidator()
+			require.NoError(t, err)
+
+			err = validate.Struct(tt.RouterConfig())
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestValidateShadowRoutes(t *testing.T) {
+	routeAID, shadowID := "route-a", "shadow"
+	routeA := &models.Route{
+		ID:       routeAID,
+		Type:     "PROXY",
+		Endpoint: "http://example.com/a",
+		Timeout:  "10ms",
+	}
+	shadowRoute := &models.Route{
+		ID:       shadowID,
+		Type:     "PROXY",
+		Endpoint: "http://example.com/shadow",
+		Timeout:  "10ms",
+		Shadow:   true,
+	}
+	trafficRules := models.TrafficRules{
+		{
+			Name: "rule-name",
+			Conditions: []*router.TrafficRuleCondition{
+				{
+					FieldSource: expRequest.HeaderFieldSource,
+					Field:       "X-Region",
+					Operator:    router.InConditionOperator,
+					Values:      []string{"region-a"},
+				},
 			},
-			expectedError: "Key: 'RouterConfig.TrafficRules' Error:Field validation for 'TrafficRules' " +
-				"failed on the 'Rules Orthogonality check failed, following pairs of rules are overlapping - " +
-				"(rule-a,rule-b), (rule-b,rule-c), (rule-b,rule-d).' tag",
+			Routes: []string{routeAID},
+		},
+	}
+
+	suite := map[string]routerConfigTestCase{
+		"success": {
+			routes:         models.Routes{routeA, shadowRoute},
+			defaultRouteID: &routeAID,
+		},
+		"success | shadow route not used by traffic rules": {
+			routes:             models.Routes{routeA, shadowRoute},
+			defaultRouteID:     &routeAID,
+			defaultTrafficRule: &models.DefaultTrafficRule{Routes: []string{routeAID}},
+			trafficRules:       trafficRules,
+		},
+		"failure | only shadow routes": {
+			routes:         models.Routes{shadowRoute},
+			defaultRouteID: &shadowID,
+			expectedError: strings.Join([]string{
+				"Key: 'RouterConfig.Routes' Error:Field validation for 'Routes' failed on the " +
+					"'should have at least one route that is not a shadow route' tag",
+				"Key: 'RouterConfig.DefaultRouteID' Error:Field validation for 'DefaultRouteID' failed on the " +
+					"'should not be a shadow route' tag",
+			}, "\n"),
+		},
+		"failure | shadow route in traffic rule": {
+			routes:             models.Routes{routeA, shadowRoute},
+			defaultRouteID:     &routeAID,
+			defaultTrafficRule: &models.DefaultTrafficRule{Routes: []string{routeAID, shadowID}},
+			trafficRules:       trafficRules,
+			expectedError: "Key: 'RouterConfig.DefaultTrafficRule.Routes[1]' Error:Field validation for " +
+				"'DefaultTrafficRule.Routes[1]' failed on the 'should not be a shadow route' tag",
+		},
+		"failure | upi router": {
+			routes:         models.Routes{routeA, shadowRoute},
+			defaultRouteID: &routeAID,
+			protocol:       routerConfig.UPI,
+			logConfig: &request.LogConfig{
+				ResultLoggerType: models.UPILogger,
+			},
+			expectedError: "Key: 'RouterConfig.Routes[1].Shadow' Error:Field validation for 'Routes[1].Shadow' " +
+				"failed on the 'shadow routes are not supported for UPI' tag",
 		},
 	}
 
@@ -986,137 +2158,409 @@ func TestValidateTrafficRules(t *testing.T) {
 	}
 }
 
-func TestValidateAutoscaling(t *testing.T) {
-	enricherBasic := request.EnricherEnsemblerConfig{
-		Image: "lala",
-		ResourceRequest: &models.ResourceRequest{
-			MinReplica: 0,
-			MaxReplica: 5,
-			CPURequest: resource.Quantity{
-				Format: "500M",
+func TestValidateBandit(t *testing.T) {
+	control, treatment := "control", "treatment-a"
+	routes := models.Routes{
+		{ID: control},
+		{ID: treatment},
+		{ID: "shadow", Shadow: true},
+	}
+
+	suite := map[string]routerConfigTestCase{
+		"success": {
+			routes:         routes,
+			defaultRouteID: &control,
+			bandit: &models.BanditConfig{
+				Algorithm:        models.EpsilonGreedyAlgorithm,
+				Epsilon:          0.2,
+				Routes:           []string{control, treatment},
+				SnapshotInterval: "30s",
 			},
-			MemoryRequest: resource.Quantity{
-				Format: "1G",
+			resourceRequest: &models.ResourceRequest{MinReplica: 1, MaxReplica: 1},
+		},
+		"success | all routes": {
+			routes:         routes,
+			defaultRouteID: &control,
+			bandit:         &models.BanditConfig{Algorithm: models.ThompsonSamplingAlgorithm},
+		},
+		"failure | unknown algorithm": {
+			routes:         routes,
+			defaultRouteID: &control,
+			bandit:         &models.BanditConfig{Algorithm: "ucb"},
+			expectedError: "Key: 'RouterConfig.Bandit.Algorithm' Error:Field validation for 'Algorithm' " +
+				"failed on the 'oneof' tag",
+		},
+		"failure | invalid epsilon": {
+			routes:         routes,
+			defaultRouteID: &control,
+			bandit:         &models.BanditConfig{Algorithm: models.EpsilonGreedyAlgorithm, Epsilon: 2},
+			expectedError: "Key: 'RouterConfig.Bandit.Epsilon' Error:Field validation for 'Epsilon' " +
+				"failed on the 'lte' tag",
+		},
+		"failure | invalid snapshot interval": {
+			routes:         routes,
+			defaultRouteID: &control,
+			bandit: &models.BanditConfig{
+				Algorithm:        models.ThompsonSamplingAlgorithm,
+				SnapshotInterval: "-1m",
 			},
+			expectedError: "Key: 'RouterConfig.Bandit.SnapshotInterval' Error:Field validation for " +
+				"'Bandit.SnapshotInterval' failed on the 'should be a positive duration' tag",
 		},
-		Endpoint: "endpoint",
-		Timeout:  "6s",
-		Port:     8080,
-		Env: []*models.EnvVar{
-			{
-				Name:  "key",
-				Value: "value",
+		"failure | snapshot interval with multiple replicas": {
+			routes:         routes,
+			defaultRouteID: &control,
+			bandit: &models.BanditConfig{
+				Algorithm:        models.ThompsonSamplingAlgorithm,
+				SnapshotInterval: "1m",
 			},
+			resourceRequest: &models.ResourceRequest{MinReplica: 1, MaxReplica: 2},
+			expectedError: "Key: 'RouterConfig.Bandit.SnapshotInterval' Error:Field validation for " +
+				"'Bandit.SnapshotInterval' failed on the 'should not be set when the max replica is greater than 1' tag",
+		},
+		"failure | shadow route": {
+			routes:         routes,
+			defaultRouteID: &control,
+			bandit: &models.BanditConfig{
+				Algorithm: models.ThompsonSamplingAlgorithm,
+				Routes:    []string{control, "shadow"},
+			},
+			expectedError: "Key: 'RouterConfig.Bandit.Routes[1]' Error:Field validation for '' failed on the 'oneof' tag",
+		},
+		"failure | other routing logic": {
+			routes:         routes,
+			defaultRouteID: &control,
+			experimentEngine: &request.ExperimentEngineConfig{
+				Type: "custom",
+			},
+			ensembler: &models.Ensembler{
+				Type:           models.EnsemblerStandardType,
+				StandardConfig: &models.EnsemblerStandardConfig{RouteNamePath: "route"},
+			},
+			bandit: &models.BanditConfig{Algorithm: models.ThompsonSamplingAlgorithm},
+			expectedError: strings.Join([]string{
+				"Key: 'RouterConfig.ExperimentEngine.Type' Error:Field validation for 'ExperimentEngine.Type' " +
+					"failed on the 'should be nop when the bandit is configured' tag",
+				"Key: 'RouterConfig.Ensembler.Type' Error:Field validation for 'Ensembler.Type' " +
+					"failed on the 'should not be set when the bandit is configured' tag",
+			}, "\n"),
 		},
-		Secrets: []models.Secret{},
 	}
-	makeEnricher := func(
-		enricher request.EnricherEnsemblerConfig,
-		policy *models.AutoscalingPolicy,
-	) *request.EnricherEnsemblerConfig {
-		newEnr := enricher
-		newEnr.AutoscalingPolicy = policy
-		return &newEnr
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			validate, err := getDefaultValidator()
+			require.NoError(t, err)
+
+			err = validate.Struct(tt.RouterConfig())
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.expectedError)
+			}
+		})
 	}
-	routeID := "abc"
+}
+
+func TestValidateResponseCache(t *testing.T) {
+	routeID := "route-a"
 	route := &models.Route{
 		ID:       routeID,
 		Type:     "PROXY",
 		Endpoint: "http://example.com/a",
 		Timeout:  "10ms",
 	}
-	id := models.ID(1)
+
 	suite := map[string]routerConfigTestCase{
-		"success | no autoscaling policy | all components": {
-			routes:   models.Routes{route},
-			enricher: &enricherBasic,
-			ensembler: &models.Ensembler{
-				Type: models.EnsemblerDockerType,
+		"success": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			responseCache: &models.ResponseCacheConfig{
+				MaxEntries: 100,
+				TTL:        "1m",
+				KeyFields: []models.ResponseCacheKeyField{
+					{FieldSource: expRequest.HeaderFieldSource, Field: "X-Customer-ID"},
+					{FieldSource: expRequest.PayloadFieldSource, Field: "features.location"},
+				},
 			},
 		},
-		"success | no autoscaling policy | pyfunc ensembler": {
-			routes: models.Routes{route},
-			ensembler: &models.Ensembler{
-				Type: models.EnsemblerPyFuncType,
-				PyfuncConfig: &models.EnsemblerPyfuncConfig{
-					ProjectID:       &id,
-					EnsemblerID:     &id,
-					ResourceRequest: &models.ResourceRequest{},
-					Timeout:         "1s",
-					Env:             models.EnvVars{},
-					Secrets:         []models.Secret{},
+		"success | upi router": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			protocol:       routerConfig.UPI,
+			logConfig: &request.LogConfig{
+				ResultLoggerType: models.UPILogger,
+			},
+			responseCache: &models.ResponseCacheConfig{
+				MaxEntries: 100,
+				TTL:        "1m",
+				KeyFields: []models.ResponseCacheKeyField{
+					{FieldSource: expRequest.PredictionContextSource, Field: "customer_id"},
 				},
 			},
 		},
-		"success | valid autoscaling policy | all components": {
-			routes: models.Routes{route},
-			autoscalingPolicy: &models.AutoscalingPolicy{
-				Metric: models.AutoscalingMetricCPU,
-				Target: "90",
+		"failure | missing key fields": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			responseCache: &models.ResponseCacheConfig{
+				MaxEntries: 100,
+				TTL:        "1m",
 			},
-			enricher: makeEnricher(enricherBasic, &models.AutoscalingPolicy{
-				Metric: models.AutoscalingMetricConcurrency,
-				Target: "2",
-			}),
-			ensembler: &models.Ensembler{
-				Type: models.EnsemblerDockerType,
-				DockerConfig: &models.EnsemblerDockerConfig{
-					Endpoint: "http://abc.com",
-					Port:     8080,
-					Image:    "nginx",
-					ResourceRequest: &models.ResourceRequest{
-						CPURequest:    resource.Quantity{Format: "500m"},
-						MemoryRequest: resource.Quantity{Format: "1Gi"},
-					},
-					AutoscalingPolicy: &models.AutoscalingPolicy{
-						Metric: models.AutoscalingMetricRPS,
-						Target: "400",
-					},
-					Timeout: "5s",
-					Env:     models.EnvVars{},
-					Secrets: models.Secrets{},
+			expectedError: "Key: 'RouterConfig.ResponseCache.KeyFields' Error:Field validation for " +
+				"'KeyFields' failed on the 'required' tag",
+		},
+		"failure | invalid ttl": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			responseCache: &models.ResponseCacheConfig{
+				MaxEntries: 100,
+				TTL:        "1 minute",
+				KeyFields: []models.ResponseCacheKeyField{
+					{FieldSource: expRequest.HeaderFieldSource, Field: "X-Customer-ID"},
+				},
+			},
+			expectedError: "Key: 'RouterConfig.ResponseCache.TTL' Error:Field validation for " +
+				"'ResponseCache.TTL' failed on the 'should be a positive duration' tag",
+		},
+		"failure | invalid field source for upi router": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			protocol:       routerConfig.UPI,
+			logConfig: &request.LogConfig{
+				ResultLoggerType: models.UPILogger,
+			},
+			responseCache: &models.ResponseCacheConfig{
+				MaxEntries: 100,
+				TTL:        "1m",
+				KeyFields: []models.ResponseCacheKeyField{
+					{FieldSource: expRequest.PayloadFieldSource, Field: "customer_id"},
+				},
+			},
+			expectedError: "Key: 'RouterConfig.ResponseCache.KeyFields[0].FieldSource' Error:Field validation for " +
+				"'ResponseCache.KeyFields[0].FieldSource' failed on the 'oneof' tag",
+		},
+	}
+
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			validate, err := getDefaultValidator()
+			require.NoError(t, err)
+
+			err = validate.Struct(tt.RouterConfig())
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestValidateProcessingStages(t *testing.T) {
+	routeID := "route-a"
+	route := &models.Route{
+		ID:       routeID,
+		Type:     "PROXY",
+		Endpoint: "http://example.com/a",
+		Timeout:  "10ms",
+	}
+	newStage := func(name string, resultLogKey string) *router.ProcessingStage {
+		return &router.ProcessingStage{
+			Name:         name,
+			Phase:        router.PreprocessingPhase,
+			Endpoint:     "http://example.com/" + name,
+			Timeout:      fiberConfig.Duration(50 * time.Millisecond),
+			ErrorPolicy:  router.SkipErrorPolicy,
+			ResultLogKey: resultLogKey,
+		}
+	}
+
+	suite := map[string]routerConfigTestCase{
+		"success": {
+			routes:           models.Routes{route},
+			defaultRouteID:   &routeID,
+			processingStages: models.ProcessingStages{newStage("stage-a", ""), newStage("stage-b", "calibration")},
+		},
+		"failure | duplicate names": {
+			routes:           models.Routes{route},
+			defaultRouteID:   &routeID,
+			processingStages: models.ProcessingStages{newStage("stage-a", ""), newStage("stage-a", "calibration")},
+			expectedError: "Key: 'RouterConfig.ProcessingStages' Error:Field validation for " +
+				"'ProcessingStages' failed on the 'unique' tag",
+		},
+		"failure | invalid error policy": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			processingStages: models.ProcessingStages{
+				&router.ProcessingStage{
+					Name:        "stage-a",
+					Phase:       router.PostprocessingPhase,
+					Endpoint:    "http://example.com/stage-a",
+					Timeout:     fiberConfig.Duration(50 * time.Millisecond),
+					ErrorPolicy: "retry",
+				},
+			},
+			expectedError: "Key: 'RouterConfig.ProcessingStages[0].ErrorPolicy' Error:Field validation for " +
+				"'ErrorPolicy' failed on the 'oneof' tag",
+		},
+		"failure | negative timeout": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			processingStages: models.ProcessingStages{
+				&router.ProcessingStage{
+					Name:     "stage-a",
+					Phase:    router.PreprocessingPhase,
+					Endpoint: "http://example.com/stage-a",
+					Timeout:  fiberConfig.Duration(-time.Second),
 				},
 			},
+			expectedError: "Key: 'RouterConfig.ProcessingStages[0].Timeout' Error:Field validation for " +
+				"'ProcessingStages[0].Timeout' failed on the 'should be a positive duration' tag",
+		},
+		"failure | duplicate result log keys": {
+			routes:           models.Routes{route},
+			defaultRouteID:   &routeID,
+			processingStages: models.ProcessingStages{newStage("stage-a", ""), newStage("stage-b", "stage-a")},
+			expectedError: "Key: 'RouterConfig.ProcessingStages[1].ResultLogKey' Error:Field validation for " +
+				"'ProcessingStages[1].ResultLogKey' failed on the 'should be unique' tag",
+		},
+		"failure | reserved result log key": {
+			routes:           models.Routes{route},
+			defaultRouteID:   &routeID,
+			processingStages: models.ProcessingStages{newStage("stage-a", "router")},
+			expectedError: "Key: 'RouterConfig.ProcessingStages[0].ResultLogKey' Error:Field validation for " +
+				"'ProcessingStages[0].ResultLogKey' failed on the 'should not be a reserved key' tag",
+		},
+		"failure | reserved timing result log key": {
+			routes:           models.Routes{route},
+			defaultRouteID:   &routeID,
+			processingStages: models.ProcessingStages{newStage("timing", "")},
+			expectedError: "Key: 'RouterConfig.ProcessingStages[0].ResultLogKey' Error:Field validation for " +
+				"'ProcessingStages[0].ResultLogKey' failed on the 'should not be a reserved key' tag",
+		},
+	}
+
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			validate, err := getDefaultValidator()
+			require.NoError(t, err)
+
+			err = validate.Struct(tt.RouterConfig())
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestValidateAuth(t *testing.T) {
+	routeID := "route-a"
+	route := &models.Route{
+		ID:       routeID,
+		Type:     "PROXY",
+		Endpoint: "http://example.com/a",
+		Timeout:  "10ms",
+	}
+	apiKeys := []*models.APIKey{{Caller: "team-a", MLPSecretName: "team-a-key"}}
+	callerCache := &models.ResponseCacheConfig{
+		MaxEntries: 10,
+		TTL:        "1m",
+		KeyFields:  []models.ResponseCacheKeyField{{FieldSource: expRequest.CallerFieldSource, Field: "id"}},
+	}
+
+	suite := map[string]routerConfigTestCase{
+		"success | api keys": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			auth:           &models.AuthConfig{APIKeys: apiKeys},
 		},
-		"success | valid autoscaling policy | pyfunc ensembler": {
-			routes: models.Routes{route},
-			ensembler: &models.Ensembler{
-				Type: models.EnsemblerPyFuncType,
-				PyfuncConfig: &models.EnsemblerPyfuncConfig{
-					ProjectID:       &id,
-					EnsemblerID:     &id,
-					ResourceRequest: &models.ResourceRequest{},
-					AutoscalingPolicy: &models.AutoscalingPolicy{
-						Metric: models.AutoscalingMetricMemory,
-						Target: "80",
-					},
-					Timeout: "1s",
-					Env:     models.EnvVars{},
-					Secrets: models.Secrets{},
-				},
-			},
+		"success | jwks url": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			auth: &models.AuthConfig{JWT: &models.JWTAuthConfig{
+				JWKSURL:        "https://auth.example.com/.well-known/jwks.json",
+				RequiredClaims: map[string]string{"scope": "predict"},
+			}},
 		},
-		"failure | invalid autoscaling metric": {
+		"success | inline jwks": {
 			routes:         models.Routes{route},
 			defaultRouteID: &routeID,
-			autoscalingPolicy: &models.AutoscalingPolicy{
-				Metric: "abc",
-				Target: "100",
+			auth: &models.AuthConfig{
+				APIKeys: apiKeys,
+				JWT:     &models.JWTAuthConfig{JWKS: json.RawMessage(`{"keys": [{"kty": "RSA"}]}`)},
 			},
-			expectedError: strings.Join([]string{"Key: 'RouterConfig.AutoscalingPolicy.Metric' ",
-				"Error:Field validation for 'Metric' failed on the 'oneof' tag"}, ""),
 		},
-		"failure | invalid autoscaling target": {
+		"success | caller field source": {
 			routes:         models.Routes{route},
 			defaultRouteID: &routeID,
-			autoscalingPolicy: &models.AutoscalingPolicy{
-				Metric: models.AutoscalingMetricRPS,
-				Target: "hundred",
-			},
-			expectedError: strings.Join([]string{"Key: 'RouterConfig.AutoscalingPolicy.Target' ",
-				"Error:Field validation for 'Target' failed on the 'numeric' tag"}, ""),
+			responseCache:  callerCache,
+			auth:           &models.AuthConfig{APIKeys: apiKeys},
+		},
+		"failure | caller field source without auth": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			responseCache:  callerCache,
+			expectedError: "Key: 'RouterConfig.ResponseCache.KeyFields[0].FieldSource' Error:Field validation for " +
+				"'ResponseCache.KeyFields[0].FieldSource' failed on the 'oneof' tag",
+		},
+		"failure | no methods": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			auth:           &models.AuthConfig{},
+			expectedError: "Key: 'RouterConfig.Auth' Error:Field validation for 'Auth' failed on the " +
+				"'should configure api_keys and / or jwt' tag",
+		},
+		"failure | duplicate callers": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			auth: &models.AuthConfig{APIKeys: []*models.APIKey{
+				{Caller: "team-a", MLPSecretName: "team-a-key"},
+				{Caller: "team-a", MLPSecretName: "team-a-other-key"},
+			}},
+			expectedError: "Key: 'RouterConfig.Auth.APIKeys' Error:Field validation for 'APIKeys' failed on the " +
+				"'unique' tag",
+		},
+		"failure | missing secret name": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			auth:           &models.AuthConfig{APIKeys: []*models.APIKey{{Caller: "team-a"}}},
+			expectedError: "Key: 'RouterConfig.Auth.APIKeys[0].MLPSecretName' Error:Field validation for " +
+				"'MLPSecretName' failed on the 'required' tag",
+		},
+		"failure | missing jwks": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			auth:           &models.AuthConfig{JWT: &models.JWTAuthConfig{Audience: "turing-router"}},
+			expectedError: "Key: 'RouterConfig.Auth.JWT.JWKSURL' Error:Field validation for " +
+				"'Auth.JWT.JWKSURL' failed on the 'should set either jwks_url or jwks' tag",
+		},
+		"failure | both jwks url and jwks": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			auth: &models.AuthConfig{JWT: &models.JWTAuthConfig{
+				JWKSURL: "https://auth.example.com/.well-known/jwks.json",
+				JWKS:    json.RawMessage(`{"keys": [{"kty": "RSA"}]}`),
+			}},
+			expectedError: "Key: 'RouterConfig.Auth.JWT.JWKSURL' Error:Field validation for " +
+				"'Auth.JWT.JWKSURL' failed on the 'should not be set together with jwks' tag",
+		},
+		"failure | invalid jwks url": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			auth:           &models.AuthConfig{JWT: &models.JWTAuthConfig{JWKSURL: "auth.example.com"}},
+			expectedError: "Key: 'RouterConfig.Auth.JWT.JWKSURL' Error:Field validation for " +
+				"'Auth.JWT.JWKSURL' failed on the 'url' tag",
+		},
+		"failure | invalid jwks": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			auth:           &models.AuthConfig{JWT: &models.JWTAuthConfig{JWKS: json.RawMessage(`{"keys": []}`)}},
+			expectedError: "Key: 'RouterConfig.Auth.JWT.JWKS' Error:Field validation for " +
+				"'Auth.JWT.JWKS' failed on the 'should be a JSON Web Key Set with at least one key' tag",
 		},
 	}
+
 	for name, tt := range suite {
 		t.Run(name, func(t *testing.T) {
 			validate, err := getDefaultValidator()
@@ -1132,8 +2576,8 @@ func TestValidateAutoscaling(t *testing.T) {
 	}
 }
 
-func TestValidateStdEnsemblerNotConfiguredForNopExpEngine(t *testing.T) {
-	routeID := "abc"
+func TestValidateRateLimit(t *testing.T) {
+	routeID := "route-a"
 	route := &models.Route{
 		ID:       routeID,
 		Type:     "PROXY",
@@ -1142,33 +2586,93 @@ func TestValidateStdEnsemblerNotConfiguredForNopExpEngine(t *testing.T) {
 	}
 
 	suite := map[string]routerConfigTestCase{
-		"success": {
+		"success | rate": {
 			routes:         models.Routes{route},
 			defaultRouteID: &routeID,
-			experimentEngine: &request.ExperimentEngineConfig{
-				Type: "custom",
+			rateLimit: &models.RateLimitConfig{
+				FieldSource:       expRequest.HeaderFieldSource,
+				Field:             "X-Client-ID",
+				RequestsPerSecond: 12.5,
+				Burst:             20,
 			},
-			ensembler: &models.Ensembler{
-				Type: models.EnsemblerStandardType,
-				StandardConfig: &models.EnsemblerStandardConfig{
-					RouteNamePath: "route-1",
-				},
+		},
+		"success | concurrency": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			rateLimit: &models.RateLimitConfig{
+				FieldSource: expRequest.PayloadFieldSource,
+				Field:       "client.id",
+				MaxInFlight: 10,
 			},
 		},
-		"failure | standard ensembler is configured with nop exp engine": {
+		"success | upi prediction context": {
+			protocol:       routerConfig.UPI,
 			routes:         models.Routes{route},
 			defaultRouteID: &routeID,
-			ensembler: &models.Ensembler{
-				Type: models.EnsemblerStandardType,
-				StandardConfig: &models.EnsemblerStandardConfig{
-					RouteNamePath: "route-1",
-				},
+			rateLimit: &models.RateLimitConfig{
+				FieldSource: expRequest.PredictionContextSource,
+				Field:       "client_id",
+				MaxInFlight: 10,
 			},
-			expectedError: strings.Join([]string{"Key: 'RouterConfig.Ensembler.Type' ",
-				"Error:Field validation for 'Ensembler.Type' failed on the ",
-				"'should not be standard if an nop experiment engine is configured' tag"}, ""),
+			logConfig: &request.LogConfig{ResultLoggerType: models.UPILogger},
+		},
+		"failure | invalid field source": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			rateLimit: &models.RateLimitConfig{
+				FieldSource: expRequest.PredictionContextSource,
+				Field:       "client_id",
+				MaxInFlight: 10,
+			},
+			expectedError: "Key: 'RouterConfig.RateLimit.FieldSource' Error:Field validation for " +
+				"'RateLimit.FieldSource' failed on the 'oneof' tag",
+		},
+		"failure | missing field": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			rateLimit: &models.RateLimitConfig{
+				FieldSource: expRequest.HeaderFieldSource,
+				MaxInFlight: 10,
+			},
+			expectedError: "Key: 'RouterConfig.RateLimit.Field' Error:Field validation for " +
+				"'Field' failed on the 'required' tag",
+		},
+		"failure | no limits": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			rateLimit: &models.RateLimitConfig{
+				FieldSource: expRequest.HeaderFieldSource,
+				Field:       "X-Client-ID",
+			},
+			expectedError: "Key: 'RouterConfig.RateLimit' Error:Field validation for 'RateLimit' failed on the " +
+				"'should set requests_per_second and / or max_in_flight' tag",
+		},
+		"failure | burst without rate": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			rateLimit: &models.RateLimitConfig{
+				FieldSource: expRequest.HeaderFieldSource,
+				Field:       "X-Client-ID",
+				Burst:       5,
+				MaxInFlight: 10,
+			},
+			expectedError: "Key: 'RouterConfig.RateLimit.Burst' Error:Field validation for " +
+				"'RateLimit.Burst' failed on the 'should only be set with requests_per_second' tag",
+		},
+		"failure | negative rate": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			rateLimit: &models.RateLimitConfig{
+				FieldSource:       expRequest.HeaderFieldSource,
+				Field:             "X-Client-ID",
+				RequestsPerSecond: -1,
+				MaxInFlight:       10,
+			},
+			expectedError: "Key: 'RouterConfig.RateLimit.RequestsPerSecond' Error:Field validation for " +
+				"'RequestsPerSecond' failed on the 'gte' tag",
 		},
 	}
+
 	for name, tt := range suite {
 		t.Run(name, func(t *testing.T) {
 			validate, err := getDefaultValidator()
@@ -1203,14 +2707,48 @@ func TestValidateUPIRouter(t *testing.T) {
 				ResultLoggerType: models.UPILogger,
 			},
 		},
-		"failure | unsupported ensembler type": {
+		"success | docker ensembler": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			protocol:       routerConfig.UPI,
+			ensembler: &models.Ensembler{
+				Type: models.EnsemblerDockerType,
+			},
+			logConfig: &request.LogConfig{
+				ResultLoggerType: models.UPILogger,
+			},
+		},
+		"failure | docker ensembler without default route": {
 			routes:   models.Routes{route},
 			protocol: routerConfig.UPI,
 			ensembler: &models.Ensembler{
 				Type: models.EnsemblerDockerType,
 			},
+			expectedError: "Key: 'RouterConfig.default_route_id' Error:Field validation for 'default_route_id' " +
+				"failed on the 'should be set for chosen ensembler type' tag",
+		},
+		"failure | unsupported ensembler type": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			protocol:       routerConfig.UPI,
+			ensembler: &models.Ensembler{
+				Type: models.EnsemblerPyFuncType,
+			},
+			expectedError: "Key: 'RouterConfig.Ensembler.Type' Error:Field validation for 'Ensembler.Type' " +
+				"failed on the 'pyfunc ensembler is not supported for UPI' tag",
+		},
+		"failure | builtin ensembler": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			protocol:       routerConfig.UPI,
+			ensembler: &models.Ensembler{
+				Type: models.EnsemblerBuiltinType,
+				BuiltinConfig: &models.EnsemblerBuiltinConfig{
+					Strategy: models.BuiltinFirstSuccessStrategy,
+				},
+			},
 			expectedError: "Key: 'RouterConfig.Ensembler.Type' Error:Field validation for 'Ensembler.Type' " +
-				"failed on the 'only standard ensembler is supported for UPI' tag",
+				"failed on the 'builtin ensembler is not supported for UPI' tag",
 		},
 	}
 	for name, tt := range suite {
@@ -1248,7 +2786,218 @@ func TestValidateHTTPRouter(t *testing.T) {
 			expectedError: "Key: 'RouterConfig.LogConfig.ResultLoggerType' Error:Field validation for " +
 				"'LogConfig.ResultLoggerType' failed on the 'logger should not be upi' tag",
 		},
+		"failure | unsupported logger types": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			logConfig: &request.LogConfig{
+				ResultLoggerTypes: []models.ResultLogger{models.UPILogger}},
+			expectedError: "Key: 'RouterConfig.LogConfig.ResultLoggerTypes[0]' Error:Field validation for " +
+				"'LogConfig.ResultLoggerTypes[0]' failed on the 'logger should not be upi' tag",
+		},
+	}
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			validate, err := getDefaultValidator()
+			require.NoError(t, err)
+
+			err = validate.Struct(tt.RouterConfig())
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestValidateFallbackResponse(t *testing.T) {
+	routeID := "route-a"
+	route := &models.Route{
+		ID:       routeID,
+		Type:     "PROXY",
+		Endpoint: "http://example.com/a",
+		Timeout:  "10ms",
+	}
+	upiBody := json.RawMessage(`{"prediction_result_table": {"name": "fallback"}}`)
+
+	suite := map[string]routerConfigTestCase{
+		"success | http": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			fallbackResponse: &models.FallbackResponse{
+				Status: 503,
+				Body:   json.RawMessage(`{"score": 0}`),
+			},
+		},
+		"success | upi": {
+			protocol:         routerConfig.UPI,
+			routes:           models.Routes{route},
+			defaultRouteID:   &routeID,
+			fallbackResponse: &models.FallbackResponse{Body: upiBody},
+			logConfig:        &request.LogConfig{ResultLoggerType: models.UPILogger},
+		},
+		"success | traffic rule": {
+			routes:             models.Routes{route},
+			defaultRouteID:     &routeID,
+			defaultTrafficRule: &models.DefaultTrafficRule{Routes: []string{routeID}},
+			trafficRules: models.TrafficRules{
+				{
+					Name: "rule-a",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Region",
+							Operator:    router.InConditionOperator,
+							Values:      []string{"region-a"},
+						},
+					},
+					Routes:           []string{routeID},
+					FallbackResponse: &models.FallbackResponse{Body: json.RawMessage(`[]`)},
+				},
+			},
+		},
+		"failure | invalid json": {
+			routes:           models.Routes{route},
+			defaultRouteID:   &routeID,
+			fallbackResponse: &models.FallbackResponse{Body: json.RawMessage(`{"score":`)},
+			expectedError: "Key: 'RouterConfig.FallbackResponse.Body' Error:Field validation for " +
+				"'FallbackResponse.Body' failed on the 'should be valid JSON' tag",
+		},
+		"failure | invalid status": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			fallbackResponse: &models.FallbackResponse{
+				Status: 99,
+				Body:   json.RawMessage(`{}`),
+			},
+			expectedError: "Key: 'RouterConfig.FallbackResponse.Status' Error:Field validation for " +
+				"'Status' failed on the 'min' tag",
+		},
+		"failure | missing body": {
+			routes:           models.Routes{route},
+			defaultRouteID:   &routeID,
+			fallbackResponse: &models.FallbackResponse{Status: 200},
+			expectedError: "Key: 'RouterConfig.FallbackResponse.Body' Error:Field validation for " +
+				"'Body' failed on the 'required' tag",
+		},
+		"failure | body too large": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			fallbackResponse: &models.FallbackResponse{
+				Body: json.RawMessage(`"` + strings.Repeat("a", 16*1024) + `"`),
+			},
+			expectedError: "Key: 'RouterConfig.FallbackResponse.Body' Error:Field validation for " +
+				"'FallbackResponse.Body' failed on the 'should be at most 16384 bytes' tag",
+		},
+		"failure | upi status": {
+			protocol:       routerConfig.UPI,
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			fallbackResponse: &models.FallbackResponse{
+				Status: 503,
+				Body:   upiBody,
+			},
+			logConfig: &request.LogConfig{ResultLoggerType: models.UPILogger},
+			expectedError: "Key: 'RouterConfig.FallbackResponse.Status' Error:Field validation for " +
+				"'FallbackResponse.Status' failed on the 'should not be set for UPI routers' tag",
+		},
+		"failure | invalid upi body": {
+			protocol:         routerConfig.UPI,
+			routes:           models.Routes{route},
+			defaultRouteID:   &routeID,
+			fallbackResponse: &models.FallbackResponse{Body: json.RawMessage(`{"score": 0}`)},
+			logConfig:        &request.LogConfig{ResultLoggerType: models.UPILogger},
+			expectedError: "Key: 'RouterConfig.FallbackResponse.Body' Error:Field validation for " +
+				"'FallbackResponse.Body' failed on the 'should be a valid UPI PredictValuesResponse' tag",
+		},
+		"failure | invalid traffic rule body": {
+			routes:             models.Routes{route},
+			defaultRouteID:     &routeID,
+			defaultTrafficRule: &models.DefaultTrafficRule{Routes: []string{routeID}},
+			trafficRules: models.TrafficRules{
+				{
+					Name: "rule-a",
+					Conditions: []*router.TrafficRuleCondition{
+						{
+							FieldSource: expRequest.HeaderFieldSource,
+							Field:       "X-Region",
+							Operator:    router.InConditionOperator,
+							Values:      []string{"region-a"},
+						},
+					},
+					Routes:           []string{routeID},
+					FallbackResponse: &models.FallbackResponse{Body: json.RawMessage(`fallback`)},
+				},
+			},
+			expectedError: "Key: 'RouterConfig.TrafficRules[0].FallbackResponse.Body' Error:Field validation " +
+				"for 'TrafficRules[0].FallbackResponse.Body' failed on the 'should be valid JSON' tag",
+		},
+	}
+
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			validate, err := getDefaultValidator()
+			require.NoError(t, err)
+
+			err = validate.Struct(tt.RouterConfig())
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestValidateResultLogSamplingUnit(t *testing.T) {
+	routeID := "route-a"
+	route := &models.Route{
+		ID:       routeID,
+		Type:     "PROXY",
+		Endpoint: "http://example.com/a",
+		Timeout:  "10ms",
+	}
+
+	suite := map[string]routerConfigTestCase{
+		"success | http": {
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			logConfig: &request.LogConfig{
+				ResultLoggerType: models.NopLogger,
+				SamplingUnit: &models.ResultLogSamplingUnit{
+					FieldSource: expRequest.PayloadFieldSource,
+					Field:       "customer.id",
+				},
+			},
+		},
+		"success | upi": {
+			protocol:       routerConfig.UPI,
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			logConfig: &request.LogConfig{
+				ResultLoggerType: models.UPILogger,
+				SamplingUnit: &models.ResultLogSamplingUnit{
+					FieldSource: expRequest.PredictionContextSource,
+					Field:       "customer_id",
+				},
+			},
+		},
+		"failure | invalid field source": {
+			protocol:       routerConfig.UPI,
+			routes:         models.Routes{route},
+			defaultRouteID: &routeID,
+			logConfig: &request.LogConfig{
+				ResultLoggerType: models.UPILogger,
+				SamplingUnit: &models.ResultLogSamplingUnit{
+					FieldSource: expRequest.PayloadFieldSource,
+					Field:       "customer.id",
+				},
+			},
+			expectedError: "Key: 'RouterConfig.LogConfig.SamplingUnit.FieldSource' Error:Field validation for " +
+				"'LogConfig.SamplingUnit.FieldSource' failed on the 'oneof' tag",
+		},
 	}
+
 	for name, tt := range suite {
 		t.Run(name, func(t *testing.T) {
 			validate, err := getDefaultValidator()
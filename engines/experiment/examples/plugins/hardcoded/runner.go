@@ -1,6 +1,7 @@
 package hardcoded
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -44,11 +45,25 @@ func (e *ExperimentRunner) Configure(cfg json.RawMessage) error {
 }
 
 func (e *ExperimentRunner) GetTreatmentForRequest(
+	header http.Header,
+	payload []byte,
+	options runner.GetTreatmentOptions,
+) (*runner.Treatment, error) {
+	return e.GetTreatmentForRequestWithContext(context.Background(), header, payload, options)
+}
+
+// GetTreatmentForRequestWithContext returns the treatment of the first experiment that the request is
+// segmented into, and stops looking for it once the context is done
+func (e *ExperimentRunner) GetTreatmentForRequestWithContext(
+	ctx context.Context,
 	header http.Header,
 	payload []byte,
 	_ runner.GetTreatmentOptions,
 ) (*runner.Treatment, error) {
 	for _, exp := range e.experiments {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		segmentationUnit, err := request.GetValueFromHTTPRequest(
 			header,
 			payload,
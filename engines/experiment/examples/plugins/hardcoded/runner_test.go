@@ -1,6 +1,7 @@
 package hardcoded
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
@@ -40,8 +41,12 @@ func TestExperimentRunner_GetTreatmentForRequest(t *testing.T) {
 		]
 	}`)
 
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
 	suite := map[string]struct {
 		runnerConfig json.RawMessage
+		ctx          context.Context
 		header       http.Header
 		payload      json.RawMessage
 		expected     *runner.Treatment
@@ -70,6 +75,12 @@ func TestExperimentRunner_GetTreatmentForRequest(t *testing.T) {
 			payload:      json.RawMessage(`{}`),
 			err:          "no experiment configured for the unit",
 		},
+		"failure | context cancelled": {
+			runnerConfig: runnerConfig,
+			ctx:          cancelledCtx,
+			payload:      json.RawMessage(`{"client": {"id": 4}}`),
+			err:          "context canceled",
+		},
 	}
 
 	for name, tt := range suite {
@@ -78,7 +89,12 @@ func TestExperimentRunner_GetTreatmentForRequest(t *testing.T) {
 			err := expRunner.Configure(tt.runnerConfig)
 			require.NoError(t, err)
 
-			actual, err := expRunner.GetTreatmentForRequest(tt.header, tt.payload, runner.GetTreatmentOptions{})
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx
+			}
+			actual, err := expRunner.GetTreatmentForRequestWithContext(
+				ctx, tt.header, tt.payload, runner.GetTreatmentOptions{})
 			if tt.err != "" {
 				require.Error(t, err)
 				require.EqualError(t, err, tt.err)
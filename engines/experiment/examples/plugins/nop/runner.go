@@ -1,6 +1,7 @@
 package nop
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -16,6 +17,15 @@ func (r *ExperimentRunner) Configure(json.RawMessage) error {
 }
 
 func (r *ExperimentRunner) GetTreatmentForRequest(
+	header http.Header,
+	payload []byte,
+	options runner.GetTreatmentOptions,
+) (*runner.Treatment, error) {
+	return r.GetTreatmentForRequestWithContext(context.Background(), header, payload, options)
+}
+
+func (r *ExperimentRunner) GetTreatmentForRequestWithContext(
+	context.Context,
 	http.Header,
 	[]byte,
 	runner.GetTreatmentOptions,
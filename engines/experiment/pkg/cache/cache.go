@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache is an in-process cache, that holds at most the configured number of entries,
+// evicting the least recently used entry when it is full. The entries expire after the
+// configured TTL.
+type Cache[V any] struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	// entries holds the cache entries, ordered from the most to the least recently used
+	entries *list.List
+	items   map[string]*list.Element
+	// now returns the current time, and is only overridden in the tests
+	now func() time.Time
+}
+
+type entry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// New creates a new Cache with the given maximum number of entries and TTL
+func New[V any](maxEntries int, ttl time.Duration) *Cache[V] {
+	return &Cache[V]{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    list.New(),
+		items:      map[string]*list.Element{},
+		now:        time.Now,
+	}
+}
+
+// Get returns the value cached for the given key, if it exists and has not expired
+func (c *Cache[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var value V
+	elem, ok := c.items[key]
+	if !ok {
+		return value, false
+	}
+	e := elem.Value.(*entry[V])
+	if !c.now().Before(e.expiresAt) {
+		c.remove(elem)
+		return value, false
+	}
+	c.entries.MoveToFront(elem)
+	return e.value, true
+}
+
+// Set caches the value for the given key, evicting the least recently used entry,
+// if the cache is full
+func (c *Cache[V]) Set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if elem, ok := c.items[key]; ok {
+		e := elem.Value.(*entry[V])
+		e.value, e.expiresAt = value, expiresAt
+		c.entries.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.entries.PushFront(&entry[V]{key: key, value: value, expiresAt: expiresAt})
+	for c.entries.Len() > c.maxEntries {
+		c.remove(c.entries.Back())
+	}
+}
+
+// Remove removes the value cached for the given key, and returns it, if it exists and has not expired
+func (c *Cache[V]) Remove(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var value V
+	elem, ok := c.items[key]
+	if !ok {
+		return value, false
+	}
+	c.remove(elem)
+	e := elem.Value.(*entry[V])
+	if !c.now().Before(e.expiresAt) {
+		return value, false
+	}
+	return e.value, true
+}
+
+// Len returns the number of entries in the cache, including the expired ones,
+// that have not been evicted yet
+func (c *Cache[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries.Len()
+}
+
+func (c *Cache[V]) remove(elem *list.Element) {
+	c.entries.Remove(elem)
+	delete(c.items, elem.Value.(*entry[V]).key)
+}
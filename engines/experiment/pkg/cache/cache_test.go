@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New[string](2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", "value-a")
+	c.Set("b", "value-b")
+	value, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "value-a", value)
+
+	// "b" is the least recently used entry, and is evicted
+	c.Set("c", "value-c")
+	assert.Equal(t, 2, c.Len())
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+
+	// Entries expire after the TTL
+	now = now.Add(time.Minute)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 1, c.Len())
+
+	// Setting an existing key refreshes its value and expiry
+	c.Set("c", "value-c2")
+	value, ok = c.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, "value-c2", value)
+
+	// Removed entries are returned only once
+	value, ok = c.Remove("c")
+	assert.True(t, ok)
+	assert.Equal(t, "value-c2", value)
+	_, ok = c.Remove("c")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
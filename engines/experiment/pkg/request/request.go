@@ -22,8 +22,14 @@ const (
 	HeaderFieldSource FieldSource = "header"
 	// PredictionContextSource is used to represent the prediction_context field in UPI request
 	PredictionContextSource FieldSource = "prediction_context"
+	// CallerFieldSource is used to represent the identity of the authenticated caller
+	CallerFieldSource FieldSource = "caller"
 )
 
+// CallerHeader is the request header (or the gRPC request metadata key), in which the router passes the
+// identity of the authenticated caller, as a JSON object, to the Turing components
+const CallerHeader = "Turing-Caller"
+
 // GetFieldSource converts the input string to a FieldSource
 func GetFieldSource(srcString string) (FieldSource, error) {
 	switch strings.ToLower(srcString) {
@@ -33,6 +39,8 @@ func GetFieldSource(srcString string) (FieldSource, error) {
 		return PayloadFieldSource, nil
 	case "prediction_context":
 		return PredictionContextSource, nil
+	case "caller":
+		return CallerFieldSource, nil
 	}
 	return "", fmt.Errorf("Unknown field source %s", srcString)
 }
@@ -44,12 +52,15 @@ func GetFieldSource(srcString string) (FieldSource, error) {
 // bodyBytes - request JSON payload
 // fieldSrc - source of data, where the given key will be looked in,
 //
-//	one of `PayloadFieldSource` | `HeaderFieldSource`
+//	one of `PayloadFieldSource` | `HeaderFieldSource` | `CallerFieldSource`
 //
 // field - if `fieldSrc` is `HeaderFieldSource` - name of request header
 //
 //			   if `fieldSrc` is `PayloadFieldSource` - json path to the value that should
 //	        be extracted from the request payload
+//
+//			   if `fieldSrc` is `CallerFieldSource` - json path to the value that should
+//	        be extracted from the identity of the authenticated caller
 func GetValueFromHTTPRequest(
 	reqHeader http.Header,
 	bodyBytes []byte,
@@ -66,16 +77,20 @@ func GetValueFromHTTPRequest(
 			return "", fmt.Errorf("Field %s not found in the request header", field)
 		}
 		return value, nil
+	case CallerFieldSource:
+		return getValueFromCaller(reqHeader.Get(CallerHeader), field)
 	default:
 		return "", fmt.Errorf("Unrecognized field source %s", fieldSrc)
 	}
 }
 
 // GetValueFromUPIRequest retrieve the value from upi request or header depending on the value of `fieldSrc`.
-// Valid value of `fieldSrc` are `HeaderFieldSource` and `PredictionContextSource`.
+// Valid value of `fieldSrc` are `HeaderFieldSource`, `PredictionContextSource` and `CallerFieldSource`.
 // If `fieldSrc` is `HeaderFieldSource`, then the value will be retrieved from `reqHeader`.
 // If `fieldSrc` is `PredictionContextSource`, then the value will be retrieved from
 // `prediction_context` field of the upi request `req`.
+// If `fieldSrc` is `CallerFieldSource`, then the value will be retrieved from the identity of the
+// authenticated caller, in `reqHeader`.
 // Other `fieldSrc` value will produce error.
 func GetValueFromUPIRequest(
 	reqHeader metadata.MD,
@@ -105,12 +120,31 @@ func GetValueFromUPIRequest(
 		}
 
 		return value, nil
+	case CallerFieldSource:
+		values := reqHeader.Get(CallerHeader)
+		if len(values) == 0 {
+			return getValueFromCaller("", field)
+		}
+		return getValueFromCaller(values[0], field)
 	default:
 		return "", fmt.Errorf("Unrecognized field source %s", fieldSrc)
 	}
 }
 
 func getValueFromJSONPayload(body []byte, key string) (string, error) {
+	return getValueFromJSON(body, key, "the request payload")
+}
+
+// getValueFromCaller retrieves the value for the given field from the identity of the authenticated
+// caller, which is set by the router in the CallerHeader
+func getValueFromCaller(caller string, key string) (string, error) {
+	if caller == "" {
+		return "", errors.Errorf("Field %s not found in the caller identity: Caller is not authenticated", key)
+	}
+	return getValueFromJSON([]byte(caller), key, "the caller identity")
+}
+
+func getValueFromJSON(body []byte, key string, source string) (string, error) {
 	// Retrieve value using JSON path
 	value, dataType, _, _ := jsonparser.Get(body, strings.Split(key, ".")...)
 
@@ -121,7 +155,7 @@ func getValueFromJSONPayload(body []byte, key string) (string, error) {
 	case jsonparser.Null:
 		return "", nil
 	case jsonparser.NotExist:
-		return "", errors.Errorf("Field %s not found in the request payload: Key path not found", key)
+		return "", errors.Errorf("Field %s not found in %s: Key path not found", key, source)
 	default:
 		return "", errors.Errorf("Field %s can not be parsed as string value, unsupported type: %s", key, dataType.String())
 	}
@@ -20,6 +20,10 @@ func TestGetFieldSource(t *testing.T) {
 	fieldSrc, err = request.GetFieldSource("payload")
 	assert.Equal(t, request.PayloadFieldSource, fieldSrc)
 	assert.NoError(t, err)
+	// caller
+	fieldSrc, err = request.GetFieldSource("caller")
+	assert.Equal(t, request.CallerFieldSource, fieldSrc)
+	assert.NoError(t, err)
 	// unknown
 	_, err = request.GetFieldSource("test")
 	assert.Error(t, err)
@@ -97,6 +101,32 @@ func TestGetValueFromHTTPRequest(t *testing.T) {
 			body:     []byte(`{"session_id": null}`),
 			expected: "",
 		},
+		"success | caller": {
+			field:    "claims.tier",
+			fieldSrc: request.CallerFieldSource,
+			header: func() http.Header {
+				header := http.Header{}
+				header.Set(request.CallerHeader, `{"id": "team-a", "method": "jwt", "claims": {"tier": "gold"}}`)
+				return header
+			}(),
+			expected: "gold",
+		},
+		"failure | caller not authenticated": {
+			field:    "id",
+			fieldSrc: request.CallerFieldSource,
+			header:   http.Header{},
+			err:      "Field id not found in the caller identity: Caller is not authenticated",
+		},
+		"failure | caller field not found": {
+			field:    "claims.tier",
+			fieldSrc: request.CallerFieldSource,
+			header: func() http.Header {
+				header := http.Header{}
+				header.Set(request.CallerHeader, `{"id": "team-a", "method": "api_key"}`)
+				return header
+			}(),
+			err: "Field claims.tier not found in the caller identity: Key path not found",
+		},
 		"failure | header": {
 			field:    "CustomerID",
 			fieldSrc: request.HeaderFieldSource,
@@ -206,6 +236,20 @@ func TestGetValueFromUPIRequest(t *testing.T) {
 			},
 			expected: "1234",
 		},
+		"success | caller": {
+			field:    "id",
+			fieldSrc: request.CallerFieldSource,
+			header: metadata.MD{
+				"turing-caller": []string{`{"id": "team-a", "method": "api_key"}`},
+			},
+			expected: "team-a",
+		},
+		"failure | caller not authenticated": {
+			field:    "id",
+			fieldSrc: request.CallerFieldSource,
+			header:   metadata.MD{},
+			err:      "Field id not found in the caller identity: Caller is not authenticated",
+		},
 		"failure | header not found": {
 			field:    "missing-header",
 			fieldSrc: request.HeaderFieldSource,
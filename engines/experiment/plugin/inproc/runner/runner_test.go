@@ -9,6 +9,7 @@ import (
 	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
 
 	runnerPlugin "github.com/caraml-dev/turing/engines/experiment/plugin/inproc/runner"
+	_ "github.com/caraml-dev/turing/engines/experiment/plugin/inproc/runner/nop"
 	"github.com/caraml-dev/turing/engines/experiment/runner"
 	"github.com/caraml-dev/turing/engines/experiment/runner/mocks"
 )
@@ -92,3 +93,17 @@ func TestRegisterAndGet(t *testing.T) {
 		})
 	}
 }
+
+func TestGetContextRunner(t *testing.T) {
+	// The in-process runners are context-aware, so that they are not wrapped in the adapter
+	got, err := runnerPlugin.Get("nop", nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, ok := got.(runner.ContextExperimentRunner); !ok {
+		t.Errorf("Get() got = %T, want a runner.ContextExperimentRunner", got)
+	}
+	if ctxRunner := runner.NewContextExperimentRunner(got); ctxRunner != got {
+		t.Errorf("NewContextExperimentRunner() got = %T, want %T", ctxRunner, got)
+	}
+}
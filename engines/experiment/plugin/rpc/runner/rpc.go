@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/rpc"
@@ -28,20 +29,47 @@ func (c *rpcClient) GetTreatmentForRequest(
 	header http.Header,
 	payload []byte,
 	options runner.GetTreatmentOptions,
+) (*runner.Treatment, error) {
+	return c.GetTreatmentForRequestWithContext(context.Background(), header, payload, options)
+}
+
+func (c *rpcClient) GetTreatmentForRequestWithContext(
+	ctx context.Context,
+	header http.Header,
+	payload []byte,
+	options runner.GetTreatmentOptions,
 ) (*runner.Treatment, error) {
 	req := GetTreatmentRequest{
 		Header:  header,
 		Payload: payload,
 		Options: options,
 	}
-	var resp runner.Treatment
-
-	err := c.Call("Plugin.GetTreatmentForRequest", &req, &resp)
-	if err != nil {
-		return nil, err
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Deadline = deadline
 	}
 
-	return &resp, nil
+	// net/rpc calls are not cancellable, so the call is made in the background
+	// and its result is abandoned if the context is done first
+	type callResult struct {
+		resp *runner.Treatment
+		err  error
+	}
+	resultCh := make(chan callResult, 1)
+	go func() {
+		var resp runner.Treatment
+		err := c.Call("Plugin.GetTreatmentForRequest", &req, &resp)
+		resultCh <- callResult{resp: &resp, err: err}
+	}()
+
+	select {
+	case result := <-resultCh:
+		if result.err != nil {
+			return nil, result.err
+		}
+		return result.resp, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 func (c *rpcClient) RegisterMetricsCollector(
@@ -136,7 +164,15 @@ func (s *rpcServer) Configure(cfg json.RawMessage, _ *interface{}) (err error) {
 }
 
 func (s *rpcServer) GetTreatmentForRequest(req *GetTreatmentRequest, resp *runner.Treatment) error {
-	treatment, err := s.Impl.GetTreatmentForRequest(req.Header, req.Payload, req.Options)
+	ctx := context.Background()
+	if !req.Deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, req.Deadline)
+		defer cancel()
+	}
+
+	treatment, err := runner.NewContextExperimentRunner(s.Impl).
+		GetTreatmentForRequestWithContext(ctx, req.Header, req.Payload, req.Options)
 	if err != nil {
 		return err
 	}
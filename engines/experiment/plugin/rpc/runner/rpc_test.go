@@ -1,10 +1,13 @@
 package runner
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"net/http"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
 	"github.com/stretchr/testify/assert"
@@ -88,6 +91,65 @@ func TestRpcClient_GetTreatmentForRequest(t *testing.T) {
 	}
 }
 
+func TestRpcClient_GetTreatmentForRequestWithContext(t *testing.T) {
+	expected := &runner.Treatment{
+		ExperimentName: "experiment-1",
+		Name:           "my-treatment",
+		Config:         json.RawMessage("{}"),
+	}
+
+	suite := map[string]struct {
+		delay   time.Duration
+		timeout time.Duration
+		err     string
+	}{
+		"success | deadline is passed to the plugin": {
+			timeout: time.Second,
+		},
+		"failure | deadline exceeded": {
+			delay:   time.Second,
+			timeout: 10 * time.Millisecond,
+			err:     context.DeadlineExceeded.Error(),
+		},
+	}
+
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			deadline, _ := ctx.Deadline()
+
+			mockClient := &mocks.RPCClient{}
+			mockClient.
+				On(
+					"Call",
+					"Plugin.GetTreatmentForRequest",
+					mock.MatchedBy(func(req *GetTreatmentRequest) bool {
+						return req.Deadline.Equal(deadline)
+					}),
+					mock.AnythingOfType("*runner.Treatment")).
+				After(tt.delay).
+				Run(func(args mock.Arguments) {
+					resp := args.Get(2).(*runner.Treatment)
+					*resp = *expected
+				}).
+				Return(nil)
+
+			rpcClient := rpcClient{RPCClient: mockClient}
+			actual, err := rpcClient.GetTreatmentForRequestWithContext(
+				ctx, http.Header{}, nil, runner.GetTreatmentOptions{})
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				assert.Nil(t, actual)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, expected, actual)
+				mockClient.AssertExpectations(t)
+			}
+		})
+	}
+}
+
 func TestRpcServer_GetTreatmentForRequest(t *testing.T) {
 	suite := map[string]struct {
 		expected *runner.Treatment
@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -36,12 +37,25 @@ func (er *configurableExperimentRunner) Configure(cfg json.RawMessage) (err erro
 	return
 }
 
+func (er *configurableExperimentRunner) GetTreatmentForRequestWithContext(
+	ctx context.Context,
+	header http.Header,
+	payload []byte,
+	options runner.GetTreatmentOptions,
+) (*runner.Treatment, error) {
+	return runner.NewContextExperimentRunner(er.ExperimentRunner).
+		GetTreatmentForRequestWithContext(ctx, header, payload, options)
+}
+
 // GetTreatmentRequest is a struct, used to pass the data required by
 // ExperimentRunner.GetTreatmentForRequest() between RPC client and server
 type GetTreatmentRequest struct {
 	Header  http.Header
 	Payload []byte
 	Options runner.GetTreatmentOptions
+	// Deadline of the request's context on the client side, used to bound
+	// the processing of the request by the plugin. Zero value means no deadline.
+	Deadline time.Time
 }
 
 // MeasureDurationMsSinceRequest is a struct, used to pass the data required by
@@ -0,0 +1,160 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/cache"
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
+)
+
+// TreatmentCacheRequestsTotal is the name of the metric counting the lookups in the treatment cache
+const TreatmentCacheRequestsTotal = "exp_engine_treatment_cache_requests_total"
+
+// upiRequestKey represents the key for the UPI request, stored in the context
+const upiRequestKey ContextKey = "upiRequestKey"
+
+// upiRequest is the original request of a UPI router, whose prediction context is passed to the
+// experiment runners as the JSON payload
+type upiRequest struct {
+	header  metadata.MD
+	request *upiv1.PredictValuesRequest
+}
+
+// WithUPIRequest associates the original request of a UPI router with the given context, so that
+// the unit ID of the treatment cache is read from it, rather than from the HTTP header and payload
+// passed to the experiment runner
+func WithUPIRequest(ctx context.Context, header metadata.MD, req *upiv1.PredictValuesRequest) context.Context {
+	return context.WithValue(ctx, upiRequestKey, &upiRequest{header: header, request: req})
+}
+
+// TreatmentCacheConfig configures the caching of the treatments, by the unit ID of the requests
+type TreatmentCacheConfig struct {
+	// UnitIDSource is the source of the unit ID in the request
+	UnitIDSource request.FieldSource
+	// UnitIDField is the name of the header or the JSON path of the payload field holding the unit ID
+	UnitIDField string
+	// MaxEntries is the maximum number of treatments cached
+	MaxEntries int
+	// TTL is the duration for which a treatment is cached
+	TTL time.Duration
+}
+
+// NewCachingRunner returns a ContextExperimentRunner that memoises the treatments returned by the given
+// runner, by the unit ID of the requests. Failed requests and requests without a unit ID are not cached.
+// This assumes that the treatment of a request only depends on its unit ID. The unit ID of the requests of
+// the UPI routers is read from the request associated with the context by WithUPIRequest. The cached
+// treatments are copied, so that they are not shared by the requests.
+func NewCachingRunner(
+	name string,
+	runner ExperimentRunner,
+	cfg TreatmentCacheConfig,
+) ContextExperimentRunner {
+	return &cachingRunner{
+		ContextExperimentRunner: NewContextExperimentRunner(runner),
+		name:                    name,
+		cfg:                     cfg,
+		cache:                   cache.New[*Treatment](cfg.MaxEntries, cfg.TTL),
+		collector:               metrics.Glob(),
+	}
+}
+
+type cachingRunner struct {
+	ContextExperimentRunner
+
+	name      string
+	cfg       TreatmentCacheConfig
+	cache     *cache.Cache[*Treatment]
+	collector metrics.Collector
+}
+
+func (r *cachingRunner) GetTreatmentForRequest(
+	header http.Header,
+	payload []byte,
+	options GetTreatmentOptions,
+) (*Treatment, error) {
+	return r.GetTreatmentForRequestWithContext(context.Background(), header, payload, options)
+}
+
+func (r *cachingRunner) GetTreatmentForRequestWithContext(
+	ctx context.Context,
+	header http.Header,
+	payload []byte,
+	options GetTreatmentOptions,
+) (*Treatment, error) {
+	unitID, err := r.getUnitID(ctx, header, payload)
+	if err != nil || unitID == "" {
+		r.recordLookup(false)
+		return r.ContextExperimentRunner.GetTreatmentForRequestWithContext(ctx, header, payload, options)
+	}
+
+	if treatment, ok := r.cache.Get(unitID); ok {
+		r.recordLookup(true)
+		return copyTreatment(treatment), nil
+	}
+	r.recordLookup(false)
+
+	treatment, err := r.ContextExperimentRunner.GetTreatmentForRequestWithContext(ctx, header, payload, options)
+	if err == nil && treatment != nil {
+		r.cache.Set(unitID, copyTreatment(treatment))
+	}
+	return treatment, err
+}
+
+// getUnitID returns the unit ID of the request, from the UPI request associated with the context, if any,
+// or from the HTTP header and payload
+func (r *cachingRunner) getUnitID(ctx context.Context, header http.Header, payload []byte) (string, error) {
+	if upiReq, ok := ctx.Value(upiRequestKey).(*upiRequest); ok {
+		return request.GetValueFromUPIRequest(upiReq.header, upiReq.request, r.cfg.UnitIDSource, r.cfg.UnitIDField)
+	}
+	return request.GetValueFromHTTPRequest(header, payload, r.cfg.UnitIDSource, r.cfg.UnitIDField)
+}
+
+// copyTreatment returns a deep copy of the given treatment
+func copyTreatment(treatment *Treatment) *Treatment {
+	return &Treatment{
+		ExperimentName: treatment.ExperimentName,
+		Name:           treatment.Name,
+		Config:         append(json.RawMessage(nil), treatment.Config...),
+	}
+}
+
+// RegisterMetricsCollector registers the treatment cache metric, before registering the metrics
+// of the wrapped runner
+func (r *cachingRunner) RegisterMetricsCollector(
+	collector metrics.Collector,
+	metricsRegistrationHelper MetricsRegistrationHelper,
+) error {
+	err := metricsRegistrationHelper.Register([]instrumentation.Metric{
+		{
+			Name:        TreatmentCacheRequestsTotal,
+			Type:        instrumentation.CounterMetricType,
+			Description: "Counter for the lookups in the experiment treatment cache, by their result (hit or miss).",
+			Labels:      []string{"engine", "result"},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	r.collector = collector
+	return r.ContextExperimentRunner.RegisterMetricsCollector(collector, metricsRegistrationHelper)
+}
+
+func (r *cachingRunner) recordLookup(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	// Failing to record the metric should not fail the request
+	_ = r.collector.Inc(TreatmentCacheRequestsTotal, map[string]string{
+		"engine": r.name,
+		"result": result,
+	})
+}
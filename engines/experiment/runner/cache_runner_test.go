@@ -0,0 +1,209 @@
+package runner_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/experiment/runner"
+	"github.com/caraml-dev/turing/engines/experiment/runner/mocks"
+	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
+)
+
+// mockMetricsCollector satisfies the metrics.Collector interface, recording the counter increments
+type mockMetricsCollector struct {
+	mock.Mock
+}
+
+func (*mockMetricsCollector) MeasureDurationMsSince(metrics.MetricName, time.Time, map[string]string) error {
+	return nil
+}
+func (*mockMetricsCollector) MeasureDurationMs(metrics.MetricName, map[string]func() string) func() {
+	return func() {}
+}
+func (*mockMetricsCollector) RecordGauge(metrics.MetricName, float64, map[string]string) error {
+	return nil
+}
+func (c *mockMetricsCollector) Inc(key metrics.MetricName, labels map[string]string) error {
+	c.Called(key, labels)
+	return nil
+}
+
+func TestCachingRunner(t *testing.T) {
+	treatment := &runner.Treatment{ExperimentName: "experiment-1", Name: "treatment-1"}
+	cfg := runner.TreatmentCacheConfig{
+		UnitIDSource: request.HeaderFieldSource,
+		UnitIDField:  "Customer-ID",
+		MaxEntries:   10,
+		TTL:          time.Minute,
+	}
+
+	type call struct {
+		unitID   string
+		err      error
+		expected *runner.Treatment
+		expErr   string
+	}
+
+	suite := map[string]struct {
+		calls       []call
+		engineCalls int
+		expHits     int
+		expMisses   int
+	}{
+		"success | cached by unit ID": {
+			calls: []call{
+				{unitID: "1001", expected: treatment},
+				{unitID: "1001", expected: treatment},
+				{unitID: "1002", expected: treatment},
+			},
+			engineCalls: 2,
+			expHits:     1,
+			expMisses:   2,
+		},
+		"success | missing unit ID": {
+			calls: []call{
+				{expected: treatment},
+				{expected: treatment},
+			},
+			engineCalls: 2,
+			expMisses:   2,
+		},
+		"failure | errors are not cached": {
+			calls: []call{
+				{unitID: "1001", err: errors.New("engine error"), expErr: "engine error"},
+				{unitID: "1001", expected: treatment},
+				{unitID: "1001", expected: treatment},
+			},
+			engineCalls: 2,
+			expHits:     1,
+			expMisses:   2,
+		},
+	}
+
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			mockRunner := &mocks.ExperimentRunner{}
+			mockRunner.On("RegisterMetricsCollector", mock.Anything, mock.Anything).Return(nil)
+			mockHelper := &mocks.MetricsRegistrationHelper{}
+			mockHelper.On("Register", mock.Anything).Return(nil)
+			collector := &mockMetricsCollector{}
+			collector.On("Inc", mock.Anything, mock.Anything).Return(nil)
+
+			cachingRunner := runner.NewCachingRunner("exp-engine", mockRunner, cfg)
+			assert.NoError(t, cachingRunner.RegisterMetricsCollector(collector, mockHelper))
+			mockHelper.AssertCalled(t, "Register", mock.MatchedBy(func(m []instrumentation.Metric) bool {
+				return len(m) == 1 && m[0].Name == runner.TreatmentCacheRequestsTotal
+			}))
+			mockRunner.AssertCalled(t, "RegisterMetricsCollector", collector, mockHelper)
+
+			for _, c := range tt.calls {
+				header := http.Header{}
+				if c.unitID != "" {
+					header.Set("Customer-ID", c.unitID)
+				}
+				mockRunner.
+					On("GetTreatmentForRequest", header, mock.Anything, mock.Anything).
+					Return(c.expected, c.err).Once()
+
+				actual, err := cachingRunner.GetTreatmentForRequest(header, nil, runner.GetTreatmentOptions{})
+				if c.expErr != "" {
+					assert.EqualError(t, err, c.expErr)
+				} else {
+					assert.NoError(t, err)
+					assert.Equal(t, c.expected, actual)
+				}
+			}
+
+			mockRunner.AssertNumberOfCalls(t, "GetTreatmentForRequest", tt.engineCalls)
+			for result, count := range map[string]int{"hit": tt.expHits, "miss": tt.expMisses} {
+				labels := map[string]string{"engine": "exp-engine", "result": result}
+				calls := 0
+				for _, call := range collector.Calls {
+					if call.Arguments.Get(0) == metrics.MetricName(runner.TreatmentCacheRequestsTotal) &&
+						assert.ObjectsAreEqual(labels, call.Arguments.Get(1)) {
+						calls++
+					}
+				}
+				assert.Equal(t, count, calls, result)
+			}
+		})
+	}
+}
+
+func TestCachingRunnerUPI(t *testing.T) {
+	treatment := &runner.Treatment{ExperimentName: "experiment-1", Name: "treatment-1"}
+	mockRunner := &mocks.ExperimentRunner{}
+	mockRunner.On("GetTreatmentForRequest", mock.Anything, mock.Anything, mock.Anything).Return(treatment, nil)
+	cachingRunner := runner.NewCachingRunner("exp-engine", mockRunner, runner.TreatmentCacheConfig{
+		UnitIDSource: request.PredictionContextSource,
+		UnitIDField:  "customer_id",
+		MaxEntries:   10,
+		TTL:          time.Minute,
+	})
+
+	newUPIContext := func(customerID string) context.Context {
+		return runner.WithUPIRequest(context.Background(), metadata.MD{}, &upiv1.PredictValuesRequest{
+			PredictionContext: []*upiv1.Variable{
+				{Name: "customer_id", Type: upiv1.Type_TYPE_STRING, StringValue: customerID},
+			},
+		})
+	}
+
+	// The unit ID is read from the prediction context of the UPI request, rather than from the payload
+	for _, customerID := range []string{"1001", "1001", "1002"} {
+		actual, err := cachingRunner.GetTreatmentForRequestWithContext(
+			newUPIContext(customerID), http.Header{}, []byte(`{}`), runner.GetTreatmentOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, treatment, actual)
+	}
+	mockRunner.AssertNumberOfCalls(t, "GetTreatmentForRequest", 2)
+}
+
+func TestCachingRunnerCopiesTreatments(t *testing.T) {
+	treatment := &runner.Treatment{
+		ExperimentName: "experiment-1",
+		Name:           "treatment-1",
+		Config:         json.RawMessage(`{"route":"a"}`),
+	}
+	mockRunner := &mocks.ExperimentRunner{}
+	mockRunner.On("GetTreatmentForRequest", mock.Anything, mock.Anything, mock.Anything).Return(treatment, nil)
+	cachingRunner := runner.NewCachingRunner("exp-engine", mockRunner, runner.TreatmentCacheConfig{
+		UnitIDSource: request.HeaderFieldSource,
+		UnitIDField:  "Customer-ID",
+		MaxEntries:   10,
+		TTL:          time.Minute,
+	})
+	header := http.Header{"Customer-Id": []string{"1001"}}
+
+	// Changing the returned treatments doesn't change the cached treatment
+	first, err := cachingRunner.GetTreatmentForRequest(header, nil, runner.GetTreatmentOptions{})
+	require.NoError(t, err)
+	first.Name = "changed"
+	first.Config[2] = 'X'
+
+	second, err := cachingRunner.GetTreatmentForRequest(header, nil, runner.GetTreatmentOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, &runner.Treatment{
+		ExperimentName: "experiment-1",
+		Name:           "treatment-1",
+		Config:         json.RawMessage(`{"route":"a"}`),
+	}, second)
+	second.Name = "changed-again"
+
+	third, err := cachingRunner.GetTreatmentForRequest(header, nil, runner.GetTreatmentOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "treatment-1", third.Name)
+	mockRunner.AssertNumberOfCalls(t, "GetTreatmentForRequest", 1)
+}
@@ -0,0 +1,46 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+)
+
+// NewContextExperimentRunner returns a ContextExperimentRunner for the given ExperimentRunner. If the runner
+// already implements ContextExperimentRunner, it is returned as is. Otherwise, the runner is wrapped in an
+// adapter that calls GetTreatmentForRequest in the background and abandons the call when the context is done.
+func NewContextExperimentRunner(runner ExperimentRunner) ContextExperimentRunner {
+	if ctxRunner, ok := runner.(ContextExperimentRunner); ok {
+		return ctxRunner
+	}
+	return &contextRunnerAdapter{ExperimentRunner: runner}
+}
+
+type contextRunnerAdapter struct {
+	ExperimentRunner
+}
+
+type treatmentResult struct {
+	treatment *Treatment
+	err       error
+}
+
+func (r *contextRunnerAdapter) GetTreatmentForRequestWithContext(
+	ctx context.Context,
+	header http.Header,
+	payload []byte,
+	options GetTreatmentOptions,
+) (*Treatment, error) {
+	// Buffered, so that the goroutine can complete even if the result is abandoned
+	resultCh := make(chan treatmentResult, 1)
+	go func() {
+		treatment, err := r.ExperimentRunner.GetTreatmentForRequest(header, payload, options)
+		resultCh <- treatmentResult{treatment: treatment, err: err}
+	}()
+
+	select {
+	case result := <-resultCh:
+		return result.treatment, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,72 @@
+package runner_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/caraml-dev/turing/engines/experiment/runner"
+	"github.com/caraml-dev/turing/engines/experiment/runner/mocks"
+	"github.com/caraml-dev/turing/engines/experiment/runner/nop"
+)
+
+func TestNewContextExperimentRunner(t *testing.T) {
+	treatment := &runner.Treatment{ExperimentName: "experiment-1", Name: "treatment-1"}
+
+	suite := map[string]struct {
+		delay    time.Duration
+		timeout  time.Duration
+		err      error
+		expected *runner.Treatment
+		expErr   error
+	}{
+		"success": {
+			timeout:  time.Second,
+			expected: treatment,
+		},
+		"failure | runner error": {
+			timeout: time.Second,
+			err:     errors.New("failed to fetch treatment"),
+			expErr:  errors.New("failed to fetch treatment"),
+		},
+		"failure | deadline exceeded": {
+			delay:   time.Second,
+			timeout: 10 * time.Millisecond,
+			expErr:  context.DeadlineExceeded,
+		},
+	}
+
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			mockRunner := &mocks.ExperimentRunner{}
+			mockRunner.
+				On("GetTreatmentForRequest", mock.Anything, mock.Anything, mock.Anything).
+				After(tt.delay).
+				Return(tt.expected, tt.err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			actual, err := runner.NewContextExperimentRunner(mockRunner).
+				GetTreatmentForRequestWithContext(ctx, http.Header{}, nil, runner.GetTreatmentOptions{})
+			if tt.expErr != nil {
+				assert.EqualError(t, err, tt.expErr.Error())
+				assert.Nil(t, actual)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNewContextExperimentRunner_ContextAware(t *testing.T) {
+	// A runner that is already context-aware should not be wrapped
+	ctxRunner := &nop.ExperimentRunner{}
+	assert.Same(t, ctxRunner, runner.NewContextExperimentRunner(ctxRunner))
+}
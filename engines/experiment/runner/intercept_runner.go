@@ -9,16 +9,16 @@ func NewInterceptRunner(
 	name string,
 	runner ExperimentRunner,
 	interceptors ...Interceptor,
-) ExperimentRunner {
+) ContextExperimentRunner {
 	return &interceptRunner{
-		ExperimentRunner: runner,
-		name:             name,
-		interceptors:     interceptors,
+		ContextExperimentRunner: NewContextExperimentRunner(runner),
+		name:                    name,
+		interceptors:            interceptors,
 	}
 }
 
 type interceptRunner struct {
-	ExperimentRunner
+	ContextExperimentRunner
 
 	name         string
 	interceptors []Interceptor
@@ -29,14 +29,23 @@ func (r *interceptRunner) GetTreatmentForRequest(
 	payload []byte,
 	options GetTreatmentOptions,
 ) (*Treatment, error) {
-	ctx := context.WithValue(context.Background(), ExperimentEngineKey, r.name)
+	return r.GetTreatmentForRequestWithContext(context.Background(), header, payload, options)
+}
+
+func (r *interceptRunner) GetTreatmentForRequestWithContext(
+	ctx context.Context,
+	header http.Header,
+	payload []byte,
+	options GetTreatmentOptions,
+) (*Treatment, error) {
+	ctx = context.WithValue(ctx, ExperimentEngineKey, r.name)
 
 	// Call BeforeDispatch on the interceptors, run the experiment and then AfterCompletion
 	for _, interceptor := range r.interceptors {
 		ctx = interceptor.BeforeDispatch(ctx)
 	}
 
-	treatment, err := r.ExperimentRunner.GetTreatmentForRequest(header, payload, options)
+	treatment, err := r.ContextExperimentRunner.GetTreatmentForRequestWithContext(ctx, header, payload, options)
 
 	for _, interceptor := range r.interceptors {
 		interceptor.AfterCompletion(ctx, err)
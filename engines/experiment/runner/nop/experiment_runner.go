@@ -1,6 +1,7 @@
 package nop
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -23,6 +24,16 @@ func (ExperimentRunner) GetTreatmentForRequest(
 	return nopTreatment, nil
 }
 
+// GetTreatmentForRequestWithContext returns a dummy experiment treatment
+func (ExperimentRunner) GetTreatmentForRequestWithContext(
+	context.Context,
+	http.Header,
+	[]byte,
+	runner.GetTreatmentOptions,
+) (*runner.Treatment, error) {
+	return nopTreatment, nil
+}
+
 func (ExperimentRunner) RegisterMetricsCollector(
 	_ metrics.Collector,
 	_ runner.MetricsRegistrationHelper,
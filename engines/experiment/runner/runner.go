@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -44,3 +45,18 @@ type ExperimentRunner interface {
 		metricsRegistrationHelper MetricsRegistrationHelper,
 	) error
 }
+
+// ContextExperimentRunner is a context-aware variant of the ExperimentRunner interface. The context carries
+// the deadline, cancellation signal and tracing information of the request being served by the Turing Router,
+// so that the experiment engine can stop processing the request once it is no longer needed.
+type ContextExperimentRunner interface {
+	ExperimentRunner
+	// GetTreatmentForRequestWithContext is the same as GetTreatmentForRequest, but it should return as soon as
+	// the given context is done
+	GetTreatmentForRequestWithContext(
+		ctx context.Context,
+		header http.Header,
+		payload []byte,
+		options GetTreatmentOptions,
+	) (*Treatment, error)
+}
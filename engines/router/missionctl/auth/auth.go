@@ -0,0 +1,151 @@
+// Package auth authenticates the callers of the router's prediction endpoints, with static API keys
+// and / or JWTs, and identifies the authenticated caller to the Turing components.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+)
+
+const (
+	// APIKeyHeader is the request header (or the gRPC request metadata key), that holds the caller's API key
+	APIKeyHeader = "X-API-Key"
+	// AuthorizationHeader is the request header (or the gRPC request metadata key), that holds the caller's
+	// JWT, as a bearer token
+	AuthorizationHeader = "Authorization"
+)
+
+// Method is the method with which a caller is authenticated
+type Method string
+
+const (
+	// APIKeyMethod authenticates the caller with one of the router's static API keys
+	APIKeyMethod Method = "api_key"
+	// JWTMethod authenticates the caller with a JWT, signed by one of the keys in the router's JWKS
+	JWTMethod Method = "jwt"
+)
+
+// Caller is the identity of an authenticated caller, which is passed on to the Turing components
+// as a JSON object, in the request.CallerHeader
+type Caller struct {
+	// ID identifies the caller, i.e. the name of its API key, or the identity claim of its JWT
+	ID     string `json:"id"`
+	Method Method `json:"method"`
+	// Claims are the claims of the caller's JWT, if authenticated with a JWT
+	Claims map[string]interface{} `json:"claims,omitempty"`
+}
+
+// Encode returns the JSON representation of the caller
+func (c *Caller) Encode() string {
+	// The claims come from a parsed JSON object, so the caller can always be serialized
+	data, _ := json.Marshal(c)
+	return string(data)
+}
+
+// ParseCaller parses the caller from its JSON representation
+func ParseCaller(value string) (*Caller, error) {
+	var caller Caller
+	if err := json.Unmarshal([]byte(value), &caller); err != nil {
+		return nil, errors.Newf(errors.BadInput, "Failed to parse the caller: %s", err.Error())
+	}
+	return &caller, nil
+}
+
+// Authenticator authenticates the callers of the router's prediction endpoints
+type Authenticator struct {
+	// apiKeys maps the SHA-256 hash of each API key to the identity of its caller, so that looking
+	// up the caller does not leak the keys through its timing
+	apiKeys map[[sha256.Size]byte]string
+	jwt     *jwtValidator
+}
+
+// NewAuthenticator creates a new Authenticator from the given config. It returns nil, if the
+// authentication is not enabled.
+func NewAuthenticator(cfg *config.AuthConfig) (*Authenticator, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+
+	authenticator := &Authenticator{}
+	if cfg.APIKeysFile != "" {
+		apiKeys, err := loadAPIKeys(cfg.APIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		authenticator.apiKeys = apiKeys
+	}
+	if cfg.JWT.Enabled() {
+		validator, err := newJWTValidator(cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		authenticator.jwt = validator
+	}
+	return authenticator, nil
+}
+
+// Authenticate authenticates the caller with the given API key or, if it is not set, with the bearer
+// token in the given value of the authorization header
+func (a *Authenticator) Authenticate(apiKey string, authorization string) (*Caller, error) {
+	if apiKey != "" && a.apiKeys != nil {
+		if callerID, ok := a.apiKeys[sha256.Sum256([]byte(apiKey))]; ok {
+			return &Caller{ID: callerID, Method: APIKeyMethod}, nil
+		}
+		return nil, errors.Newf(errors.Unauthenticated, "Invalid API key")
+	}
+	if token, ok := bearerToken(authorization); ok && a.jwt != nil {
+		return a.jwt.Validate(token)
+	}
+	return nil, errors.Newf(errors.Unauthenticated, "Missing credentials")
+}
+
+// RunKeyRefresh reloads the JWKS at the configured interval, until the context is done. It returns
+// immediately, if the callers are not authenticated with JWTs.
+func (a *Authenticator) RunKeyRefresh(ctx context.Context) {
+	if a == nil || a.jwt == nil {
+		return
+	}
+	a.jwt.RunRefresh(ctx)
+}
+
+// loadAPIKeys reads the JSON file, that maps the identity of each caller to its API key
+func loadAPIKeys(path string) (map[[sha256.Size]byte]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to read the API keys file")
+	}
+
+	var keys map[string]string
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, errors.Newf(errors.BadConfig, "Failed to parse the API keys file: %s", err.Error())
+	}
+
+	apiKeys := make(map[[sha256.Size]byte]string, len(keys))
+	for callerID, key := range keys {
+		if key == "" {
+			return nil, errors.Newf(errors.BadConfig, "Empty API key for caller %s", callerID)
+		}
+		hash := sha256.Sum256([]byte(key))
+		if other, ok := apiKeys[hash]; ok {
+			return nil, errors.Newf(errors.BadConfig, "Callers %s and %s have the same API key", other, callerID)
+		}
+		apiKeys[hash] = callerID
+	}
+	return apiKeys, nil
+}
+
+// bearerToken returns the token in the given value of the authorization header, if it is a bearer token
+func bearerToken(authorization string) (string, bool) {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
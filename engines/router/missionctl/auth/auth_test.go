@@ -0,0 +1,342 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+)
+
+func encodeBigInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func buildJWKS(t *testing.T, keys map[string]crypto.PublicKey) []byte {
+	jwks := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	for keyID, key := range keys {
+		switch key := key.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, jsonWebKey{
+				KeyType: "RSA",
+				KeyID:   keyID,
+				Use:     "sig",
+				N:       encodeBigInt(key.N),
+				E:       encodeBigInt(big.NewInt(int64(key.E))),
+			})
+		case *ecdsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, jsonWebKey{
+				KeyType: "EC",
+				KeyID:   keyID,
+				Curve:   key.Curve.Params().Name,
+				X:       encodeBigInt(key.X),
+				Y:       encodeBigInt(key.Y),
+			})
+		}
+	}
+	data, err := json.Marshal(jwks)
+	require.NoError(t, err)
+	return data
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, keyID string, key crypto.PrivateKey, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = keyID
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestNewAuthenticatorDisabled(t *testing.T) {
+	authenticator, err := NewAuthenticator(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, authenticator)
+
+	authenticator, err = NewAuthenticator(&config.AuthConfig{JWT: &config.JWTAuthConfig{}})
+	assert.NoError(t, err)
+	assert.Nil(t, authenticator)
+}
+
+func TestNewAuthenticatorInvalidConfig(t *testing.T) {
+	tests := map[string]struct {
+		cfg *config.AuthConfig
+		err string
+	}{
+		"missing api keys file": {
+			cfg: &config.AuthConfig{APIKeysFile: filepath.Join(t.TempDir(), "missing.json")},
+			err: "Failed to read the API keys file",
+		},
+		"empty api key": {
+			cfg: &config.AuthConfig{APIKeysFile: writeFile(t, "api-keys.json", []byte(`{"team-a": ""}`))},
+			err: "Empty API key for caller team-a",
+		},
+		"invalid jwks": {
+			cfg: &config.AuthConfig{
+				JWT: &config.JWTAuthConfig{JWKSFile: writeFile(t, "jwks.json", []byte(`{"keys": "none"}`))},
+			},
+			err: "Failed to parse the JWKS",
+		},
+		"no signing keys": {
+			cfg: &config.AuthConfig{
+				JWT: &config.JWTAuthConfig{
+					JWKSFile: writeFile(t, "jwks.json", []byte(`{"keys": [{"kty": "RSA", "use": "enc"}]}`)),
+				},
+			},
+			err: "The JWKS has no supported signing keys",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewAuthenticator(tt.cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	authenticator, err := NewAuthenticator(&config.AuthConfig{
+		APIKeysFile: writeFile(t, "api-keys.json", []byte(`{"team-a": "key-a", "team-b": "key-b"}`)),
+	})
+	require.NoError(t, err)
+
+	caller, err := authenticator.Authenticate("key-b", "")
+	assert.NoError(t, err)
+	assert.Equal(t, &Caller{ID: "team-b", Method: APIKeyMethod}, caller)
+
+	_, err = authenticator.Authenticate("key-c", "")
+	assert.EqualError(t, err, "Invalid API key")
+
+	// JWT authentication is not enabled
+	_, err = authenticator.Authenticate("", "Bearer token")
+	assert.EqualError(t, err, "Missing credentials")
+}
+
+func TestAuthenticateJWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwksFile := writeFile(t, "jwks.json", buildJWKS(t, map[string]crypto.PublicKey{
+		"rsa-key": &rsaKey.PublicKey,
+		"ec-key":  &ecKey.PublicKey,
+	}))
+	authenticator, err := NewAuthenticator(&config.AuthConfig{
+		APIKeysFile: writeFile(t, "api-keys.json", []byte(`{"team-a": "key-a"}`)),
+		JWT: &config.JWTAuthConfig{
+			JWKSFile:       jwksFile,
+			Issuer:         "https://auth.example.com",
+			Audience:       "turing-router",
+			RequiredClaims: config.RequiredClaims{"scope": "predict"},
+			IdentityClaim:  "client_id",
+		},
+	})
+	require.NoError(t, err)
+
+	exp := time.Now().Add(time.Hour).Unix()
+	newClaims := func(overrides jwt.MapClaims) jwt.MapClaims {
+		claims := jwt.MapClaims{
+			"iss":       "https://auth.example.com",
+			"aud":       []string{"turing-router"},
+			"exp":       exp,
+			"scope":     "read predict",
+			"client_id": "team-b",
+		}
+		for key, value := range overrides {
+			if value == nil {
+				delete(claims, key)
+			} else {
+				claims[key] = value
+			}
+		}
+		return claims
+	}
+
+	tests := map[string]struct {
+		token    string
+		expected *Caller
+		err      string
+	}{
+		"success | rsa": {
+			token: signToken(t, jwt.SigningMethodRS256, "rsa-key", rsaKey, newClaims(nil)),
+			expected: &Caller{
+				ID:     "team-b",
+				Method: JWTMethod,
+				Claims: map[string]interface{}{
+					"iss":       "https://auth.example.com",
+					"aud":       []interface{}{"turing-router"},
+					"exp":       float64(exp),
+					"scope":     "read predict",
+					"client_id": "team-b",
+				},
+			},
+		},
+		"success | ec": {
+			token: signToken(t, jwt.SigningMethodES256, "ec-key", ecKey, newClaims(jwt.MapClaims{
+				"scope": []string{"predict"},
+			})),
+			expected: &Caller{
+				ID:     "team-b",
+				Method: JWTMethod,
+				Claims: map[string]interface{}{
+					"iss":       "https://auth.example.com",
+					"aud":       []interface{}{"turing-router"},
+					"exp":       float64(exp),
+					"scope":     []interface{}{"predict"},
+					"client_id": "team-b",
+				},
+			},
+		},
+		"failure | unknown key": {
+			token: signToken(t, jwt.SigningMethodRS256, "other-key", otherKey, newClaims(nil)),
+			err:   "Invalid JWT: token is unverifiable: error while executing keyfunc: unknown key id \"other-key\"",
+		},
+		"failure | invalid signature": {
+			token: signToken(t, jwt.SigningMethodRS256, "rsa-key", otherKey, newClaims(nil)),
+			err:   "Invalid JWT: token signature is invalid: crypto/rsa: verification error",
+		},
+		"failure | symmetric signing method": {
+			token: signToken(t, jwt.SigningMethodHS256, "rsa-key", []byte("secret"), newClaims(nil)),
+			err:   "Invalid JWT: token signature is invalid: signing method HS256 is invalid",
+		},
+		"failure | expired": {
+			token: signToken(t, jwt.SigningMethodRS256, "rsa-key", rsaKey, newClaims(jwt.MapClaims{
+				"exp": time.Now().Add(-time.Hour).Unix(),
+			})),
+			err: "Invalid JWT: token has invalid claims: token is expired",
+		},
+		"failure | missing expiration": {
+			token: signToken(t, jwt.SigningMethodRS256, "rsa-key", rsaKey, newClaims(jwt.MapClaims{"exp": nil})),
+			err:   "Invalid JWT: token has invalid claims: token is missing required claim: exp claim is required",
+		},
+		"failure | wrong audience": {
+			token: signToken(t, jwt.SigningMethodRS256, "rsa-key", rsaKey, newClaims(jwt.MapClaims{
+				"aud": "other-service",
+			})),
+			err: "Invalid JWT: token has invalid claims: token has invalid audience",
+		},
+		"failure | wrong issuer": {
+			token: signToken(t, jwt.SigningMethodRS256, "rsa-key", rsaKey, newClaims(jwt.MapClaims{
+				"iss": "https://other.example.com",
+			})),
+			err: "Invalid JWT: token has invalid claims: token has invalid issuer",
+		},
+		"failure | missing required claim": {
+			token: signToken(t, jwt.SigningMethodRS256, "rsa-key", rsaKey, newClaims(jwt.MapClaims{
+				"scope": "read",
+			})),
+			err: "JWT does not have the required claim scope",
+		},
+		"failure | missing identity claim": {
+			token: signToken(t, jwt.SigningMethodRS256, "rsa-key", rsaKey, newClaims(jwt.MapClaims{
+				"client_id": nil,
+			})),
+			err: "JWT does not have the identity claim client_id",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			caller, err := authenticator.Authenticate("", "Bearer "+tt.token)
+			if tt.err == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, caller)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+
+	// The API key takes precedence over the JWT
+	caller, err := authenticator.Authenticate("key-a", "Bearer "+tests["success | rsa"].token)
+	assert.NoError(t, err)
+	assert.Equal(t, &Caller{ID: "team-a", Method: APIKeyMethod}, caller)
+
+	// The authorization header does not hold a bearer token
+	_, err = authenticator.Authenticate("", "Basic dXNlcjpwYXNz")
+	assert.EqualError(t, err, "Missing credentials")
+}
+
+func TestJWKSRefresh(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var jwks atomic.Value
+	jwks.Store(buildJWKS(t, map[string]crypto.PublicKey{"old-key": &oldKey.PublicKey}))
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		_, _ = rw.Write(jwks.Load().([]byte))
+	}))
+	defer server.Close()
+
+	authenticator, err := NewAuthenticator(&config.AuthConfig{
+		JWT: &config.JWTAuthConfig{JWKSURL: server.URL, IdentityClaim: "sub"},
+	})
+	require.NoError(t, err)
+
+	claims := jwt.MapClaims{"sub": "team-a", "exp": time.Now().Add(time.Hour).Unix()}
+	oldToken := signToken(t, jwt.SigningMethodRS256, "old-key", oldKey, claims)
+	newToken := signToken(t, jwt.SigningMethodRS256, "new-key", newKey, claims)
+
+	_, err = authenticator.Authenticate("", "Bearer "+oldToken)
+	assert.NoError(t, err)
+	_, err = authenticator.Authenticate("", "Bearer "+newToken)
+	assert.Error(t, err)
+
+	// Rotate the keys
+	jwks.Store(buildJWKS(t, map[string]crypto.PublicKey{"new-key": &newKey.PublicKey}))
+	require.NoError(t, authenticator.jwt.RefreshKeys())
+
+	_, err = authenticator.Authenticate("", "Bearer "+oldToken)
+	assert.Error(t, err)
+	caller, err := authenticator.Authenticate("", "Bearer "+newToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "team-a", caller.ID)
+
+	// The current keys are kept, if the JWKS fails to load
+	server.Close()
+	assert.Error(t, authenticator.jwt.RefreshKeys())
+	_, err = authenticator.Authenticate("", "Bearer "+newToken)
+	assert.NoError(t, err)
+}
+
+func TestCallerEncoding(t *testing.T) {
+	caller := &Caller{ID: "team-a", Method: JWTMethod, Claims: map[string]interface{}{"tier": "gold"}}
+	assert.Equal(t, `{"id":"team-a","method":"jwt","claims":{"tier":"gold"}}`, caller.Encode())
+
+	parsed, err := ParseCaller(caller.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, caller, parsed)
+
+	_, err = ParseCaller("team-a")
+	assert.Error(t, err)
+}
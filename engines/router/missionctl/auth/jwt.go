@@ -0,0 +1,272 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+)
+
+// jwksFetchTimeout is the timeout of the request that fetches the JWKS from its URL
+const jwksFetchTimeout = 10 * time.Second
+
+// signingMethods are the JWT signing methods, that are accepted by the router. Only asymmetric
+// methods are accepted, since the keys are published in the JWKS.
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// jsonWebKey is a public key in a JSON Web Key Set. Only RSA and EC keys are supported.
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	// Modulus and exponent of an RSA key
+	N string `json:"n"`
+	E string `json:"e"`
+	// Curve and coordinates of an EC key
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+}
+
+// jwtValidator validates the callers' JWTs against the keys in a JSON Web Key Set (JWKS),
+// which is reloaded periodically to pick up the rotated keys
+type jwtValidator struct {
+	cfg    *config.JWTAuthConfig
+	parser *jwt.Parser
+	// load reads the JWKS from its file or URL
+	load func() ([]byte, error)
+
+	mu   sync.RWMutex
+	keys map[string]crypto.PublicKey
+}
+
+func newJWTValidator(cfg *config.JWTAuthConfig) (*jwtValidator, error) {
+	opts := []jwt.ParserOption{jwt.WithValidMethods(signingMethods), jwt.WithExpirationRequired()}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	v := &jwtValidator{cfg: cfg, parser: jwt.NewParser(opts...)}
+	if cfg.JWKSFile != "" {
+		v.load = func() ([]byte, error) { return os.ReadFile(cfg.JWKSFile) }
+	} else {
+		client := &http.Client{Timeout: jwksFetchTimeout}
+		v.load = func() ([]byte, error) { return fetchJWKS(client, cfg.JWKSURL) }
+	}
+
+	if err := v.RefreshKeys(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Validate validates the given JWT and returns the identity of its caller
+func (v *jwtValidator) Validate(token string) (*Caller, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, v.keyFunc); err != nil {
+		return nil, errors.Newf(errors.Unauthenticated, "Invalid JWT: %s", err.Error())
+	}
+	for name, value := range v.cfg.RequiredClaims {
+		if !hasClaimValue(claims[name], value) {
+			return nil, errors.Newf(errors.Unauthenticated, "JWT does not have the required claim %s", name)
+		}
+	}
+	callerID, _ := claims[v.cfg.IdentityClaim].(string)
+	if callerID == "" {
+		return nil, errors.Newf(errors.Unauthenticated, "JWT does not have the identity claim %s",
+			v.cfg.IdentityClaim)
+	}
+	return &Caller{ID: callerID, Method: JWTMethod, Claims: claims}, nil
+}
+
+// RefreshKeys reloads the keys from the JWKS
+func (v *jwtValidator) RefreshKeys() error {
+	data, err := v.load()
+	if err != nil {
+		return errors.Wrapf(err, "Failed to load the JWKS")
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.keys = keys
+	return nil
+}
+
+// RunRefresh reloads the keys from the JWKS at the configured interval, until the context is done.
+// The current keys are kept, if the JWKS fails to load.
+func (v *jwtValidator) RunRefresh(ctx context.Context) {
+	if v.cfg.RefreshInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(v.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := v.RefreshKeys(); err != nil {
+				log.Glob().Errorf("Failed to refresh the JWKS: %s", err)
+			}
+		}
+	}
+}
+
+// keyFunc returns the key from the JWKS, that the given token is signed with
+func (v *jwtValidator) keyFunc(token *jwt.Token) (interface{}, error) {
+	keyID, _ := token.Header["kid"].(string)
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	key, ok := v.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", keyID)
+	}
+	return key, nil
+}
+
+// fetchJWKS fetches the JWKS from the given URL
+func fetchJWKS(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// parseJWKS parses the signing keys from the given JWKS, by their key id. The keys, which are
+// not used for signatures or have an unsupported type, are skipped.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, errors.Newf(errors.BadConfig, "Failed to parse the JWKS: %s", err.Error())
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		var key crypto.PublicKey
+		var err error
+		switch jwk.KeyType {
+		case "RSA":
+			key, err = jwk.rsaPublicKey()
+		case "EC":
+			key, err = jwk.ecdsaPublicKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, errors.Newf(errors.BadConfig, "Invalid key %q in the JWKS: %s", jwk.KeyID, err.Error())
+		}
+		keys[jwk.KeyID] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.Newf(errors.BadConfig, "The JWKS has no supported signing keys")
+	}
+	return keys, nil
+}
+
+func (jwk jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeBigInt(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (jwk jsonWebKey) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch jwk.Curve {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+	}
+	x, err := decodeBigInt(jwk.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeBigInt(jwk.Y)
+	if err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// decodeBigInt decodes a base64url-encoded big-endian integer
+func decodeBigInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, fmt.Errorf("missing key parameter")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// hasClaimValue returns whether the given claim has the expected value. The value of a string claim may
+// be a space-separated list of values, like the OAuth scope, and the claim may also be a list of values.
+func hasClaimValue(claim interface{}, expected string) bool {
+	switch value := claim.(type) {
+	case nil:
+		return false
+	case string:
+		return value == expected || slices.Contains(strings.Fields(value), expected)
+	case []interface{}:
+		for _, item := range value {
+			if fmt.Sprint(item) == expected {
+				return true
+			}
+		}
+		return false
+	default:
+		return fmt.Sprint(value) == expected
+	}
+}
@@ -0,0 +1,223 @@
+// Package bandit holds the in-process state of the multi-armed bandit routing strategy, which
+// learns the best route (arm) of the router from the rewards of its route selections.
+package bandit
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/cache"
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+)
+
+// Algorithm is the algorithm used by the bandit to trade off exploring the routes and
+// exploiting the best route
+type Algorithm string
+
+const (
+	// ThompsonSampling selects the route with the highest reward sampled from the
+	// Beta distribution of its rewards
+	ThompsonSampling Algorithm = "thompson_sampling"
+	// EpsilonGreedy selects a random route with the probability epsilon, and the route with
+	// the highest mean reward otherwise
+	EpsilonGreedy Algorithm = "epsilon_greedy"
+)
+
+// Policy is the configuration of the bandit's route selection
+type Policy struct {
+	Algorithm Algorithm `json:"algorithm"`
+	// Epsilon is the probability of exploring a random route, for the EpsilonGreedy algorithm
+	Epsilon float64 `json:"epsilon"`
+}
+
+// ArmStats holds the statistics of one route (arm) of the bandit
+type ArmStats struct {
+	// Selections is the number of times that the route was selected
+	Selections int64 `json:"selections"`
+	// Rewards is the number of rewards received for the selections of the route
+	Rewards int64 `json:"rewards"`
+	// TotalReward is the sum of the rewards received for the selections of the route
+	TotalReward float64 `json:"total_reward"`
+}
+
+// MeanReward returns the mean of the rewards received for the route, or 0 if there are none
+func (s ArmStats) MeanReward() float64 {
+	if s.Rewards == 0 {
+		return 0
+	}
+	return s.TotalReward / float64(s.Rewards)
+}
+
+// State holds the statistics of the routes, and the route selections that are waiting for their
+// rewards, by the Turing request ID
+type State struct {
+	mu      sync.Mutex
+	arms    map[string]*ArmStats
+	pending *cache.Cache[string]
+	rand    *rand.Rand
+}
+
+// NewState creates a new State, which accepts the rewards of at most maxPendingFeedbacks
+// route selections, within the feedbackTTL after their selection
+func NewState(maxPendingFeedbacks int, feedbackTTL time.Duration) *State {
+	return &State{
+		arms:    map[string]*ArmStats{},
+		pending: cache.New[string](maxPendingFeedbacks, feedbackTTL),
+		rand:    rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+}
+
+// Select selects one of the given routes using the policy, and records the selection for the
+// request, so that the reward of the request can be attributed to the selected route. The routes
+// must not be empty.
+func (s *State) Select(requestID string, routeIDs []string, policy Policy) string {
+	s.mu.Lock()
+	var routeID string
+	switch policy.Algorithm {
+	case EpsilonGreedy:
+		routeID = s.selectEpsilonGreedy(routeIDs, policy.Epsilon)
+	default:
+		routeID = s.selectThompsonSampling(routeIDs)
+	}
+	s.arm(routeID).Selections++
+	s.mu.Unlock()
+
+	if requestID != "" {
+		s.pending.Set(requestID, routeID)
+	}
+	if err := metrics.Glob().Inc(
+		instrumentation.BanditRouteSelectionsTotal,
+		map[string]string{"route": routeID},
+	); err != nil {
+		log.Glob().Errorf("Failed to record the bandit selection of route %s: %s", routeID, err.Error())
+	}
+	return routeID
+}
+
+// Reward records the reward, between 0 and 1, for the route selected for the given request,
+// and returns the ID of the route. The reward of each request is only accepted once.
+func (s *State) Reward(requestID string, reward float64) (string, error) {
+	if reward < 0 || reward > 1 || math.IsNaN(reward) {
+		return "", errors.Newf(errors.BadInput, "Reward must be between 0 and 1, got %v", reward)
+	}
+	routeID, ok := s.pending.Remove(requestID)
+	if !ok {
+		return "", errors.Newf(errors.NotFound, "No route selection is waiting for the reward of request %s", requestID)
+	}
+
+	s.mu.Lock()
+	arm := s.arm(routeID)
+	arm.Rewards++
+	arm.TotalReward += reward
+	meanReward := arm.MeanReward()
+	s.mu.Unlock()
+
+	labels := map[string]string{"route": routeID}
+	if err := metrics.Glob().Inc(instrumentation.BanditRouteRewardsTotal, labels); err != nil {
+		log.Glob().Errorf("Failed to record the bandit reward of route %s: %s", routeID, err.Error())
+	}
+	if err := metrics.Glob().RecordGauge(instrumentation.BanditRouteMeanReward, meanReward, labels); err != nil {
+		log.Glob().Errorf("Failed to record the bandit mean reward of route %s: %s", routeID, err.Error())
+	}
+	return routeID, nil
+}
+
+// Stats returns a copy of the statistics of the routes, by route ID
+func (s *State) Stats() map[string]ArmStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make(map[string]ArmStats, len(s.arms))
+	for routeID, arm := range s.arms {
+		stats[routeID] = *arm
+	}
+	return stats
+}
+
+// Restore replaces the statistics of the routes with the given ones
+func (s *State) Restore(stats map[string]ArmStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.arms = make(map[string]*ArmStats, len(stats))
+	for routeID, arm := range stats {
+		arm := arm
+		s.arms[routeID] = &arm
+	}
+}
+
+// arm returns the statistics of the given route, creating them if they don't exist.
+// It is expected to be called with the lock held.
+func (s *State) arm(routeID string) *ArmStats {
+	arm, ok := s.arms[routeID]
+	if !ok {
+		arm = &ArmStats{}
+		s.arms[routeID] = arm
+	}
+	return arm
+}
+
+func (s *State) selectThompsonSampling(routeIDs []string) string {
+	best, bestSample := routeIDs[0], -1.0
+	for _, routeID := range routeIDs {
+		// The prior of the reward of each route is the uniform distribution, Beta(1, 1)
+		arm := s.arm(routeID)
+		sample := sampleBeta(s.rand, 1+arm.TotalReward, 1+float64(arm.Rewards)-arm.TotalReward)
+		if sample > bestSample {
+			best, bestSample = routeID, sample
+		}
+	}
+	return best
+}
+
+func (s *State) selectEpsilonGreedy(routeIDs []string, epsilon float64) string {
+	if s.rand.Float64() < epsilon {
+		return routeIDs[s.rand.IntN(len(routeIDs))]
+	}
+
+	// The ties between the routes with the highest mean reward are broken randomly
+	var best []string
+	bestReward := -1.0
+	for _, routeID := range routeIDs {
+		reward := s.arm(routeID).MeanReward()
+		switch {
+		case reward > bestReward:
+			best, bestReward = []string{routeID}, reward
+		case reward == bestReward:
+			best = append(best, routeID)
+		}
+	}
+	return best[s.rand.IntN(len(best))]
+}
+
+// sampleBeta samples the Beta(alpha, beta) distribution, from the ratio of two Gamma samples
+func sampleBeta(r *rand.Rand, alpha float64, beta float64) float64 {
+	x := sampleGamma(r, alpha)
+	y := sampleGamma(r, beta)
+	return x / (x + y)
+}
+
+// sampleGamma samples the Gamma(shape, 1) distribution, for shape >= 1, using the method of
+// Marsaglia and Tsang
+func sampleGamma(r *rand.Rand, shape float64) float64 {
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := r.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := r.Float64()
+		if u < 1-0.0331*x*x*x*x || math.Log(u) < 0.5*x*x+d*(1-v+math.Log(v)) {
+			return d * v
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package bandit
+
+import (
+	"context"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+)
+
+func newTestState() *State {
+	state := NewState(100, time.Minute)
+	state.rand = rand.New(rand.NewPCG(1, 2))
+	return state
+}
+
+func TestStateSelectAndReward(t *testing.T) {
+	state := newTestState()
+
+	routeID := state.Select("req-1", []string{"route-a"}, Policy{Algorithm: ThompsonSampling})
+	assert.Equal(t, "route-a", routeID)
+
+	rewarded, err := state.Reward("req-1", 0.5)
+	require.NoError(t, err)
+	assert.Equal(t, "route-a", rewarded)
+	assert.Equal(t, map[string]ArmStats{
+		"route-a": {Selections: 1, Rewards: 1, TotalReward: 0.5},
+	}, state.Stats())
+
+	// The reward of each request is only accepted once
+	_, err = state.Reward("req-1", 1)
+	assert.EqualError(t, err, "No route selection is waiting for the reward of request req-1")
+
+	_, err = state.Reward("req-2", 1)
+	assert.EqualError(t, err, "No route selection is waiting for the reward of request req-2")
+
+	state.Select("req-3", []string{"route-a"}, Policy{Algorithm: ThompsonSampling})
+	_, err = state.Reward("req-3", 1.5)
+	assert.EqualError(t, err, "Reward must be between 0 and 1, got 1.5")
+}
+
+func TestStateSelectConverges(t *testing.T) {
+	routeIDs := []string{"route-a", "route-b", "route-c"}
+	// The probability of a reward of 1, for each of the routes
+	rewardRates := map[string]float64{"route-a": 0.2, "route-b": 0.8, "route-c": 0.4}
+
+	tests := map[string]Policy{
+		"thompson sampling": {Algorithm: ThompsonSampling},
+		"epsilon greedy":    {Algorithm: EpsilonGreedy, Epsilon: 0.1},
+	}
+	for name, policy := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := newTestState()
+			rewardRand := rand.New(rand.NewPCG(3, 4))
+
+			for i := 0; i < 2000; i++ {
+				routeID := state.Select("req", routeIDs, policy)
+				reward := 0.0
+				if rewardRand.Float64() < rewardRates[routeID] {
+					reward = 1
+				}
+				_, err := state.Reward("req", reward)
+				require.NoError(t, err)
+			}
+
+			stats := state.Stats()
+			assert.Greater(t, stats["route-b"].Selections, int64(1600))
+			assert.InDelta(t, 0.8, stats["route-b"].MeanReward(), 0.05)
+		})
+	}
+}
+
+func TestStateSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bandit.json")
+
+	state := newTestState()
+	// A missing snapshot file is ignored
+	require.NoError(t, state.LoadSnapshot(path))
+
+	state.Select("req-1", []string{"route-a"}, Policy{Algorithm: EpsilonGreedy})
+	_, err := state.Reward("req-1", 1)
+	require.NoError(t, err)
+	require.NoError(t, state.SaveSnapshot(path))
+
+	restored := newTestState()
+	require.NoError(t, restored.LoadSnapshot(path))
+	assert.Equal(t, state.Stats(), restored.Stats())
+
+	require.NoError(t, os.WriteFile(path, []byte("invalid"), 0600))
+	assert.ErrorContains(t, restored.LoadSnapshot(path), "Failed to parse the bandit snapshot file")
+}
+
+func TestStateRunSnapshots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bandit.json")
+	state := newTestState()
+	state.Select("", []string{"route-a"}, Policy{Algorithm: ThompsonSampling})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		state.RunSnapshots(ctx, path, time.Hour)
+		close(done)
+	}()
+	// The state is saved once more when the context is done
+	cancel()
+	<-done
+
+	require.NoError(t, InitGlobalState(&config.BanditConfig{
+		FeedbackTTL:         time.Minute,
+		MaxPendingFeedbacks: 10,
+		SnapshotFile:        path,
+	}))
+	assert.Equal(t, map[string]ArmStats{"route-a": {Selections: 1}}, Glob().Stats())
+}
@@ -0,0 +1,103 @@
+package bandit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+)
+
+// globalState is the bandit state shared by all the bandit routing strategies of the router,
+// so that it is kept when the router's Fiber config is reloaded
+var globalState = NewState(100000, 10*time.Minute)
+
+// Glob returns the global bandit state
+func Glob() *State {
+	return globalState
+}
+
+// InitGlobalState replaces the global bandit state with a new one, created from the given config,
+// and restores its statistics from the snapshot file, if it is configured and exists
+func InitGlobalState(cfg *config.BanditConfig) error {
+	state := NewState(cfg.MaxPendingFeedbacks, cfg.FeedbackTTL)
+	if cfg.SnapshotFile != "" {
+		if err := state.LoadSnapshot(cfg.SnapshotFile); err != nil {
+			return err
+		}
+	}
+	globalState = state
+	return nil
+}
+
+// SaveSnapshot saves the statistics of the routes to the given file. The file is replaced
+// atomically, so that a partially written snapshot is never restored.
+func (s *State) SaveSnapshot(path string) error {
+	data, err := json.Marshal(s.Stats())
+	if err != nil {
+		return errors.Wrapf(err, "Failed to serialize the bandit state")
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return errors.Wrapf(err, "Failed to create the bandit snapshot file")
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err = tmpFile.Write(data); err == nil {
+		err = tmpFile.Close()
+	} else {
+		_ = tmpFile.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), path)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "Failed to write the bandit snapshot file")
+	}
+	return nil
+}
+
+// LoadSnapshot restores the statistics of the routes from the given file. A missing file is
+// not an error, since there is no snapshot before the state is saved for the first time.
+func (s *State) LoadSnapshot(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "Failed to read the bandit snapshot file")
+	}
+
+	var stats map[string]ArmStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return errors.Newf(errors.BadConfig, "Failed to parse the bandit snapshot file: %s", err.Error())
+	}
+	s.Restore(stats)
+	return nil
+}
+
+// RunSnapshots saves the statistics of the routes to the given file at the given interval,
+// and once more when the context is done
+func (s *State) RunSnapshots(ctx context.Context, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.SaveSnapshot(path); err != nil {
+				log.Glob().Errorf("Failed to save the bandit state: %s", err)
+			}
+			return
+		case <-ticker.C:
+			if err := s.SaveSnapshot(path); err != nil {
+				log.Glob().Errorf("Failed to save the bandit state: %s", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"encoding/json"
+	"net/http"
+
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+)
+
+// HTTPRequestKey builds the cache key of the HTTP request, from the values of the given fields.
+// It returns false, if any of the fields is not found in the request.
+func HTTPRequestKey(fields []config.CacheKeyField, header http.Header, body []byte) (string, bool) {
+	return makeKey(fields, func(field config.CacheKeyField) (string, error) {
+		return request.GetValueFromHTTPRequest(header, body, field.FieldSource, field.Field)
+	})
+}
+
+// UPIRequestKey builds the cache key of the UPI request, from the values of the given fields.
+// It returns false, if any of the fields is not found in the request.
+func UPIRequestKey(fields []config.CacheKeyField, md metadata.MD, req *upiv1.PredictValuesRequest) (string, bool) {
+	return makeKey(fields, func(field config.CacheKeyField) (string, error) {
+		return request.GetValueFromUPIRequest(md, req, field.FieldSource, field.Field)
+	})
+}
+
+func makeKey(fields []config.CacheKeyField, getValue func(config.CacheKeyField) (string, error)) (string, bool) {
+	if len(fields) == 0 {
+		return "", false
+	}
+	values := make([]string, len(fields))
+	for idx, field := range fields {
+		value, err := getValue(field)
+		if err != nil {
+			return "", false
+		}
+		values[idx] = value
+	}
+	// The values are JSON-encoded, so that the key is unambiguous, regardless of their content
+	key, err := json.Marshal(values)
+	if err != nil {
+		return "", false
+	}
+	return string(key), true
+}
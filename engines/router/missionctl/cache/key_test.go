@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"net/http"
+	"testing"
+
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+)
+
+func TestHTTPRequestKey(t *testing.T) {
+	fields := []config.CacheKeyField{
+		{FieldSource: request.HeaderFieldSource, Field: "X-User-Id"},
+		{FieldSource: request.PayloadFieldSource, Field: "order.id"},
+	}
+	header := http.Header{"X-User-Id": []string{"user-1"}}
+
+	key, ok := HTTPRequestKey(fields, header, []byte(`{"order": {"id": "order-1"}}`))
+	assert.True(t, ok)
+	assert.Equal(t, `["user-1","order-1"]`, key)
+
+	other, ok := HTTPRequestKey(fields, header, []byte(`{"order": {"id": "order-2"}}`))
+	assert.True(t, ok)
+	assert.NotEqual(t, key, other)
+
+	_, ok = HTTPRequestKey(fields, header, []byte(`{}`))
+	assert.False(t, ok)
+
+	_, ok = HTTPRequestKey(nil, header, []byte(`{}`))
+	assert.False(t, ok)
+}
+
+func TestUPIRequestKey(t *testing.T) {
+	fields := []config.CacheKeyField{
+		{FieldSource: request.HeaderFieldSource, Field: "x-user-id"},
+		{FieldSource: request.PredictionContextSource, Field: "country"},
+	}
+	req := &upiv1.PredictValuesRequest{
+		PredictionContext: []*upiv1.Variable{
+			{Name: "country", Type: upiv1.Type_TYPE_STRING, StringValue: "SG"},
+		},
+	}
+
+	key, ok := UPIRequestKey(fields, metadata.Pairs("x-user-id", "user-1"), req)
+	assert.True(t, ok)
+	assert.Equal(t, `["user-1","SG"]`, key)
+
+	_, ok = UPIRequestKey(fields, metadata.MD{}, req)
+	assert.False(t, ok)
+}
@@ -1,13 +1,17 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/caraml-dev/mlp/api/pkg/instrumentation/sentry"
+	"github.com/go-playground/validator/v10"
 	"github.com/kelseyhightower/envconfig"
 
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router"
 	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
 )
 
@@ -67,6 +71,7 @@ type Config struct {
 	EnrichmentConfig *EnrichmentConfig `envconfig:"ENRICHER"`
 	RouterConfig     *RouterConfig     `envconfig:"ROUTER"`
 	EnsemblerConfig  *EnsemblerConfig  `envconfig:"ENSEMBLER"`
+	ProcessingStages ProcessingStages  `envconfig:"PROCESSING_STAGES"`
 
 	AppConfig *AppConfig `envconfig:"APP"`
 }
@@ -131,11 +136,34 @@ type AppConfig struct {
 	CustomMetrics bool         `split_words:"true" default:"false"`
 	FiberDebugLog bool         `split_words:"true" default:"false"`
 	ResultLogger  ResultLogger `split_words:"true" default:"NOP"`
-	BigQuery      *BQConfig    `envconfig:"BQ"`
+	// ResultLoggers are the destinations, that the result logs are written to at once. If set, they take
+	// precedence over the single ResultLogger.
+	ResultLoggers []ResultLogger `split_words:"true"`
+	BigQuery      *BQConfig      `envconfig:"BQ"`
 	Fluentd       *FluentdConfig
 	Kafka         *KafkaConfig
 	Jaeger        *JaegerConfig
 	Sentry        sentry.Config
+	// ResultLogPolicy samples and redacts the result logs, before they are written to the result loggers
+	ResultLogPolicy *ResultLogPolicy `envconfig:"RESULT_LOG_POLICY"`
+	// ResultLogSpool buffers the result logs on disk, while their result loggers are unavailable
+	ResultLogSpool *ResultLogSpoolConfig `split_words:"true"`
+	// ResultLogQueue decouples the result logging of the requests from the writes to the result loggers
+	ResultLogQueue *ResultLogQueueConfig `split_words:"true"`
+	// ShutdownGracePeriod is the maximum time to drain the in-flight requests and flush the result logger,
+	// when the router is shutting down
+	ShutdownGracePeriod time.Duration `split_words:"true" default:"30s"`
+	// ShutdownDrainDelay is the time between the readiness checks starting to fail and the router shutting
+	// down, so that the load balancers stop sending new requests to the router before it stops accepting them
+	ShutdownDrainDelay time.Duration `split_words:"true" default:"5s"`
+}
+
+// ResultLoggerTypes returns the configured result logging destinations
+func (c *AppConfig) ResultLoggerTypes() []ResultLogger {
+	if len(c.ResultLoggers) > 0 {
+		return c.ResultLoggers
+	}
+	return []ResultLogger{c.ResultLogger}
 }
 
 // Decode parses the LogLevel config defined and validates if it is one of the supported
@@ -193,6 +221,355 @@ type RouterConfig struct {
 	ConfigFile string        `split_words:"true" required:"true"`
 	Timeout    time.Duration `default:"20ms"`
 	Protocol   Protocol      `default:"HTTP_JSON"`
+	Cache      *CacheConfig
+	Reload     *ReloadConfig
+	Bandit     *BanditConfig
+	Auth       *AuthConfig
+	RateLimit  *RateLimitConfig `split_words:"true"`
+	// Fallback is the static responses, that are served when the routes, the ensembler or the experiment
+	// engine fail
+	Fallback *FallbackConfig `envconfig:"FALLBACK_RESPONSES"`
+	// DebugResponseEnabled allows the clients to request the debug trace of a request, with the
+	// debug request header
+	DebugResponseEnabled bool `split_words:"true"`
+}
+
+// ReloadConfig is the structure used to parse the environment configs of the hot reloads of the
+// router's Fiber config, which apply the changes to the config without restarting the router.
+type ReloadConfig struct {
+	// WatchInterval is the interval at which the config file is checked for changes. The config file
+	// is not watched, if not set.
+	WatchInterval time.Duration `split_words:"true"`
+	// PushEnabled enables the internal endpoint that accepts a new config
+	PushEnabled bool `split_words:"true"`
+	// PushPort is the port of the internal listener of the endpoint that accepts a new config, which is
+	// separate from the router's port, so that the endpoint is not exposed with the prediction endpoints
+	PushPort int `split_words:"true" default:"8081"`
+}
+
+// BanditConfig is the structure used to parse the environment configs of the in-process state of
+// the multi-armed bandit routing strategy, which learns the best route from the rewards of its selections.
+type BanditConfig struct {
+	// FeedbackTTL is the duration after the route selection, within which its reward is accepted
+	FeedbackTTL time.Duration `split_words:"true" default:"10m"`
+	// MaxPendingFeedbacks is the maximum number of route selections, that are waiting for their rewards
+	MaxPendingFeedbacks int `split_words:"true" default:"100000"`
+	// SnapshotFile is the file, to which the bandit state is periodically saved, and from which it is
+	// restored on start up. The state is not saved, if not set.
+	SnapshotFile string `split_words:"true"`
+	// SnapshotInterval is the interval at which the bandit state is saved to the snapshot file
+	SnapshotInterval time.Duration `split_words:"true" default:"1m"`
+}
+
+// AuthConfig is the structure used to parse the environment configs of the authentication of the
+// callers of the router's prediction endpoints. The callers are not authenticated, if neither the
+// API keys nor the JWT validation are configured.
+type AuthConfig struct {
+	// APIKeysFile is the JSON file, that maps the identity of each caller to its API key
+	APIKeysFile string `envconfig:"API_KEYS_FILE"`
+	JWT         *JWTAuthConfig
+}
+
+// Enabled returns whether the callers of the router's prediction endpoints are authenticated
+func (cfg *AuthConfig) Enabled() bool {
+	return cfg != nil && (cfg.APIKeysFile != "" || cfg.JWT.Enabled())
+}
+
+// JWTAuthConfig is the structure used to parse the environment configs of the validation of the
+// callers' JWTs, which are signed with one of the keys in the configured JSON Web Key Set (JWKS)
+type JWTAuthConfig struct {
+	// JWKSFile is the file, from which the JWKS is read
+	JWKSFile string `envconfig:"JWKS_FILE"`
+	// JWKSURL is the URL, from which the JWKS is fetched, if the JWKS file is not set
+	JWKSURL string `envconfig:"JWKS_URL"`
+	// RefreshInterval is the interval at which the JWKS is reloaded, to pick up rotated keys
+	RefreshInterval time.Duration `split_words:"true" default:"15m"`
+	// Issuer is the expected issuer (iss) of the JWTs. The issuer is not checked, if not set.
+	Issuer string
+	// Audience is the expected audience (aud) of the JWTs. The audience is not checked, if not set.
+	Audience string
+	// RequiredClaims are the claims, which the JWTs must have with the given values
+	RequiredClaims RequiredClaims `split_words:"true"`
+	// IdentityClaim is the claim, which value identifies the caller
+	IdentityClaim string `split_words:"true" default:"sub"`
+}
+
+// Enabled returns whether the callers' JWTs are validated
+func (cfg *JWTAuthConfig) Enabled() bool {
+	return cfg != nil && (cfg.JWKSFile != "" || cfg.JWKSURL != "")
+}
+
+// RequiredClaims maps the name of each claim, that the JWTs must have, to its required value
+type RequiredClaims map[string]string
+
+// Decode parses the RequiredClaims config from its JSON representation
+func (claims *RequiredClaims) Decode(value string) error {
+	if value == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(value), claims); err != nil {
+		return errors.Newf(errors.BadConfig, "Failed to parse the required claims: %s", err.Error())
+	}
+	return nil
+}
+
+// RateLimitConfig is the structure used to parse the environment configs of the admission control of
+// the router's prediction endpoints, which limits the request rate and the number of in-flight requests
+// of each client. The clients are identified by the value of the key field of their requests, and the
+// requests without the key field share the limits of a single client.
+type RateLimitConfig struct {
+	// FieldSource and Field locate the key field of the request, that identifies the client
+	FieldSource request.FieldSource `split_words:"true"`
+	Field       string
+	// RequestsPerSec is the rate, at which the tokens of each client's token bucket are refilled.
+	// The request rate is not limited, if not set.
+	RequestsPerSec float64 `split_words:"true"`
+	// Burst is the capacity of each client's token bucket. Defaults to the request rate, rounded up.
+	Burst int
+	// MaxInFlight is the maximum number of the concurrent requests of each client. The number of the
+	// concurrent requests is not limited, if not set.
+	MaxInFlight int `split_words:"true"`
+	// MaxKeys is the maximum number of the clients, which limits are tracked at a time. The least
+	// recently seen clients are forgotten first.
+	MaxKeys int `split_words:"true" default:"10000"`
+	// MetricKeys are the keys of the clients, which rejected requests are counted by their own key.
+	// The rejected requests of the other clients are counted together, to bound the metric's cardinality.
+	MetricKeys []string `split_words:"true"`
+}
+
+// Enabled returns whether the requests of the clients are limited
+func (cfg *RateLimitConfig) Enabled() bool {
+	return cfg != nil && (cfg.RequestsPerSec > 0 || cfg.MaxInFlight > 0)
+}
+
+// FallbackResponse is a static response, that is served in place of the error of the router
+type FallbackResponse struct {
+	// Status is the HTTP status of the response. Defaults to 200 OK. It is not used by the UPI routers,
+	// which serve the fallback response with the OK status.
+	Status int `json:"status,omitempty"`
+	// Body is the JSON payload of the response or, for the UPI routers, the JSON representation of
+	// the PredictValuesResponse
+	Body json.RawMessage `json:"body"`
+}
+
+// FallbackConfig is the structure used to parse the environment config of the router's fallback responses,
+// from its JSON representation. The fallback response of the traffic rule, that the request matched, is
+// served if set, or else the router's default fallback response. No fallback response is served, if
+// neither is set.
+type FallbackConfig struct {
+	Default      *FallbackResponse            `json:"default,omitempty"`
+	TrafficRules map[string]*FallbackResponse `json:"traffic_rules,omitempty"`
+}
+
+// Decode parses the FallbackConfig from its JSON representation
+func (cfg *FallbackConfig) Decode(value string) error {
+	if value == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(value), cfg); err != nil {
+		return errors.Newf(errors.BadConfig, "Failed to parse the fallback responses: %s", err.Error())
+	}
+	return nil
+}
+
+// ResultLogSpoolConfig is the structure used to parse the environment configs of the on-disk spool of the
+// result logs. The result logs, that fail to be written to a result logger, are appended to the spool of the
+// result logger, and replayed in order once it recovers. The result logs are not spooled, if Dir is not set.
+type ResultLogSpoolConfig struct {
+	// Dir is the directory of the spools, which has a sub-directory for the spool of each result logger
+	Dir string
+	// MaxBytes is the maximum size of the spool of each result logger, above which the result logs are dropped
+	MaxBytes int64 `split_words:"true" default:"1073741824"`
+	// SegmentBytes is the size of the segment files of the spool, above which a new segment is started.
+	// The segments are deleted once they are replayed.
+	SegmentBytes int64 `split_words:"true" default:"67108864"`
+	// ReplayInterval is the interval at which the spooled result logs are replayed to the result logger
+	ReplayInterval time.Duration `split_words:"true" default:"5s"`
+	// MaxReplayAttempts is the number of the failed replays of a spooled result log, after which it's moved
+	// to the dead-letter file of the spool, so that a result log, which the result logger rejects, doesn't
+	// block the result logs behind it
+	MaxReplayAttempts int `split_words:"true" default:"10"`
+	// CloseTimeout is the maximum time to replay the spool on shutdown. The result logs, that are still
+	// spooled by then, are left on disk and replayed on the next start.
+	CloseTimeout time.Duration `split_words:"true" default:"5s"`
+}
+
+// Enabled returns whether the result logs are spooled
+func (cfg *ResultLogSpoolConfig) Enabled() bool {
+	return cfg != nil && cfg.Dir != ""
+}
+
+// OverflowPolicy is the policy of the result log queue, when it is full
+type OverflowPolicy string
+
+const (
+	// DropNewestOverflowPolicy drops the result log, that is being enqueued
+	DropNewestOverflowPolicy OverflowPolicy = "drop-newest"
+	// DropOldestOverflowPolicy drops the oldest result log in the queue, to make room for the new one
+	DropOldestOverflowPolicy OverflowPolicy = "drop-oldest"
+	// BlockOverflowPolicy waits for the queue to have room for the new result log
+	BlockOverflowPolicy OverflowPolicy = "block"
+)
+
+// Decode parses the OverflowPolicy config defined and validates if it is one of the
+// supported values.
+func (policy *OverflowPolicy) Decode(value string) error {
+	value = strings.ToLower(value)
+	switch OverflowPolicy(value) {
+	case DropNewestOverflowPolicy,
+		DropOldestOverflowPolicy,
+		BlockOverflowPolicy:
+		*policy = OverflowPolicy(value)
+		return nil
+	}
+	return errors.Newf(errors.BadConfig, "Overflow policy value %s not supported", value)
+}
+
+// ResultLogQueueConfig is the structure used to parse the environment configs of the result log queue.
+// The result logs are enqueued by the requests, and written to the result loggers in batches by a pool
+// of workers, so that the requests don't wait for the result loggers. The queue is disabled, and each
+// result log is written by a goroutine of its own, unless Size is set.
+type ResultLogQueueConfig struct {
+	// Size is the maximum number of the result logs in the queue. The UPI router logs and the other result
+	// logs are queued separately, each in a queue of this size.
+	Size int `default:"0"`
+	// Workers is the number of the workers, that write the result logs to the result loggers
+	Workers int `default:"4"`
+	// BatchSize is the maximum number of the result logs, that a worker writes at once
+	BatchSize int `split_words:"true" default:"100"`
+	// FlushInterval is the maximum time, that a worker waits for a batch to fill up before writing it
+	FlushInterval time.Duration `split_words:"true" default:"1s"`
+	// OverflowPolicy is the policy for the new result logs, when the queue is full
+	OverflowPolicy OverflowPolicy `split_words:"true" default:"drop-newest"`
+}
+
+// Enabled returns whether the result logs are queued
+func (cfg *ResultLogQueueConfig) Enabled() bool {
+	return cfg != nil && cfg.Size > 0
+}
+
+// RedactionAction is the action, that redacts the value of a field in the result logs
+type RedactionAction string
+
+const (
+	// DropRedactionAction removes the field
+	DropRedactionAction RedactionAction = "drop"
+	// HashRedactionAction replaces the value with its HMAC-SHA256, keyed by the router's hash key,
+	// so that the hashed values can be joined on but not looked up by hashing the candidate values
+	HashRedactionAction RedactionAction = "hash"
+	// MaskRedactionAction replaces all but the last 4 characters of the value with asterisks
+	MaskRedactionAction RedactionAction = "mask"
+)
+
+// ResultLogSamplingUnit is the field of the request, which value decides whether the request is logged,
+// so that either all or none of the requests with the same value are logged
+type ResultLogSamplingUnit struct {
+	FieldSource request.FieldSource `json:"field_source"`
+	Field       string              `json:"field"`
+}
+
+// ResultLogRedaction is a field, which value is redacted in the result logs. For the header field
+// source, the field is the name of a header of the request and the responses. For the payload field
+// source, the field is the JSON path of a field of the request and response bodies, with "*" matching
+// any key of an object or any element of an array, e.g. "customer.phone" or "items.*.email".
+type ResultLogRedaction struct {
+	FieldSource request.FieldSource `json:"field_source"`
+	Field       string              `json:"field"`
+	Action      RedactionAction     `json:"action"`
+}
+
+// ResultLogPolicy is the structure used to parse the environment config of the sampling and the
+// redaction of the result logs, from its JSON representation
+type ResultLogPolicy struct {
+	// SamplingRate is the fraction of the requests, which results are logged. All of the results are
+	// logged, if not set.
+	SamplingRate *float64 `json:"sampling_rate,omitempty"`
+	// SamplingUnit makes the sampling deterministic per value of the given request field. The requests
+	// are sampled at random, if not set or if the request doesn't have the field.
+	SamplingUnit *ResultLogSamplingUnit `json:"sampling_unit,omitempty"`
+	// Redactions are the fields, which values are redacted before the result logs are written
+	Redactions []ResultLogRedaction `json:"redactions,omitempty"`
+	// HashKeyFile is the path of the file, which contents are the secret key of the hash redactions.
+	// It's required, if any of the fields are hashed.
+	HashKeyFile string `json:"hash_key_file,omitempty"`
+}
+
+// Decode parses the ResultLogPolicy from its JSON representation
+func (cfg *ResultLogPolicy) Decode(value string) error {
+	if value == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(value), cfg); err != nil {
+		return errors.Newf(errors.BadConfig, "Failed to parse the result log policy: %s", err.Error())
+	}
+	if cfg.SamplingRate != nil && (*cfg.SamplingRate < 0 || *cfg.SamplingRate > 1) {
+		return errors.Newf(errors.BadConfig, "Invalid result log sampling rate %v", *cfg.SamplingRate)
+	}
+	for _, redaction := range cfg.Redactions {
+		switch redaction.Action {
+		case DropRedactionAction, HashRedactionAction, MaskRedactionAction:
+		default:
+			return errors.Newf(errors.BadConfig, "Redaction action %s not supported", redaction.Action)
+		}
+		if redaction.FieldSource != request.HeaderFieldSource && redaction.FieldSource != request.PayloadFieldSource {
+			return errors.Newf(errors.BadConfig, "Redaction field source %s not supported", redaction.FieldSource)
+		}
+		if redaction.Action == HashRedactionAction && cfg.HashKeyFile == "" {
+			return errors.Newf(errors.BadConfig, "The hash key file is required by the hash redaction of %s", redaction.Field)
+		}
+	}
+	return nil
+}
+
+// CacheConfig is the structure used to parse the environment configs of the router's
+// in-process response cache. The responses are cached by the values of the key fields
+// of the request, so the cache should only be enabled if the response is deterministic
+// for these values.
+type CacheConfig struct {
+	Enabled    bool
+	MaxEntries int            `split_words:"true" default:"10000"`
+	TTL        time.Duration  `default:"1m"`
+	KeyFields  CacheKeyFields `split_words:"true"`
+}
+
+// CacheKeyField is a field of the request, which value is part of the response cache key
+type CacheKeyField struct {
+	FieldSource request.FieldSource `json:"field_source"`
+	Field       string              `json:"field"`
+}
+
+// CacheKeyFields is the list of the request fields, that make up the response cache key
+type CacheKeyFields []CacheKeyField
+
+// Decode parses the CacheKeyFields config from its JSON representation
+func (fields *CacheKeyFields) Decode(value string) error {
+	if value == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(value), fields); err != nil {
+		return errors.Newf(errors.BadConfig, "Failed to parse cache key fields: %s", err.Error())
+	}
+	return nil
+}
+
+// ProcessingStages is the ordered list of the router's pre / post-processing stages
+type ProcessingStages router.ProcessingStages
+
+// Decode parses the ProcessingStages config from its JSON representation, and validates each stage
+func (stages *ProcessingStages) Decode(value string) error {
+	if value == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(value), stages); err != nil {
+		return errors.Newf(errors.BadConfig, "Failed to parse processing stages: %s", err.Error())
+	}
+	validate := validator.New()
+	for _, stage := range *stages {
+		if err := validate.Struct(stage); err != nil {
+			return errors.Newf(errors.BadConfig, "Invalid processing stage: %s", err.Error())
+		}
+	}
+	return nil
 }
 
 // EnsemblerConfig is the structure used to parse the Ensembler's environment configs
@@ -1,13 +1,18 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/caraml-dev/mlp/api/pkg/instrumentation/sentry"
+	fiberConfig "github.com/gojek/fiber/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router"
 	tu "github.com/caraml-dev/turing/engines/router/missionctl/internal/testutils"
 )
 
@@ -36,34 +41,81 @@ var requiredEnvs = map[string]string{
 	"APP_ENVIRONMENT":    "dev",
 }
 
+var processingStagesEnv = `[{"name":"fraud-check","phase":"preprocessing","endpoint":"http://localhost:8083",` +
+	`"timeout":"5ms","error_policy":"skip"}]`
+
 var optionalEnvs = map[string]string{
-	"ENRICHER_ENDPOINT":              "http://localhost:8081",
-	"ENRICHER_TIMEOUT":               "5ms",
-	"ENSEMBLER_ENDPOINT":             "http://localhost:8082",
-	"ENSEMBLER_TIMEOUT":              "2ms",
-	"ROUTER_TIMEOUT":                 "10ms",
-	"ROUTER_PROTOCOL":                "UPI_V1",
-	"APP_LOGLEVEL":                   "DEBUG",
-	"APP_FIBER_DEBUG_LOG":            "true",
-	"APP_RESULT_LOGGER":              "CONSOLE",
-	"APP_GCP_PROJECT":                "gcp-project-id",
-	"APP_BQ_DATASET":                 "turing",
-	"APP_BQ_TABLE":                   "turing-test",
-	"APP_BQ_BATCH_LOAD":              "true",
-	"APP_CUSTOM_METRICS":             "true",
-	"APP_FLUENTD_HOST":               "localhost",
-	"APP_FLUENTD_PORT":               "24224",
-	"APP_FLUENTD_TAG":                "response.log",
-	"APP_KAFKA_BROKERS":              "localhost:9000",
-	"APP_KAFKA_TOPIC":                "kafka_topic",
-	"APP_KAFKA_SERIALIZATION_FORMAT": "json",
-	"APP_JAEGER_ENABLED":             "true",
-	"APP_JAEGER_COLLECTOR_ENDPOINT":  "http://localhost:5000",
-	"APP_JAEGER_REPORTER_HOST":       "localhost",
-	"APP_JAEGER_REPORTER_PORT":       "5001",
-	"APP_SENTRY_ENABLED":             "true",
-	"APP_SENTRY_DSN":                 "test:dsn",
-	"APP_SENTRY_LABELS":              "sentry_key1:value1,sentry_key2:value2",
+	"ENRICHER_ENDPOINT":                   "http://localhost:8081",
+	"ENRICHER_TIMEOUT":                    "5ms",
+	"ENSEMBLER_ENDPOINT":                  "http://localhost:8082",
+	"ENSEMBLER_TIMEOUT":                   "2ms",
+	"PROCESSING_STAGES":                   processingStagesEnv,
+	"ROUTER_TIMEOUT":                      "10ms",
+	"ROUTER_PROTOCOL":                     "UPI_V1",
+	"ROUTER_CACHE_ENABLED":                "true",
+	"ROUTER_CACHE_MAX_ENTRIES":            "100",
+	"ROUTER_CACHE_TTL":                    "30s",
+	"ROUTER_CACHE_KEY_FIELDS":             `[{"field_source":"header","field":"X-User-Id"}]`,
+	"ROUTER_RELOAD_WATCH_INTERVAL":        "10s",
+	"ROUTER_RELOAD_PUSH_ENABLED":          "true",
+	"ROUTER_RELOAD_PUSH_PORT":             "9090",
+	"ROUTER_BANDIT_FEEDBACK_TTL":          "5m",
+	"ROUTER_BANDIT_MAX_PENDING_FEEDBACKS": "1000",
+	"ROUTER_BANDIT_SNAPSHOT_FILE":         "/var/bandit.json",
+	"ROUTER_BANDIT_SNAPSHOT_INTERVAL":     "30s",
+	"ROUTER_AUTH_API_KEYS_FILE":           "/var/secret/router-auth/api-keys.json",
+	"ROUTER_AUTH_JWT_JWKS_URL":            "https://auth.example.com/.well-known/jwks.json",
+	"ROUTER_AUTH_JWT_REFRESH_INTERVAL":    "1h",
+	"ROUTER_AUTH_JWT_ISSUER":              "https://auth.example.com",
+	"ROUTER_AUTH_JWT_AUDIENCE":            "turing-router",
+	"ROUTER_AUTH_JWT_REQUIRED_CLAIMS":     `{"scope":"predict","tier":"gold"}`,
+	"ROUTER_AUTH_JWT_IDENTITY_CLAIM":      "client_id",
+	"ROUTER_RATE_LIMIT_FIELD_SOURCE":      "header",
+	"ROUTER_RATE_LIMIT_FIELD":             "X-Client-ID",
+	"ROUTER_RATE_LIMIT_REQUESTS_PER_SEC":  "12.5",
+	"ROUTER_RATE_LIMIT_BURST":             "20",
+	"ROUTER_RATE_LIMIT_MAX_IN_FLIGHT":     "8",
+	"ROUTER_RATE_LIMIT_MAX_KEYS":          "500",
+	"ROUTER_RATE_LIMIT_METRIC_KEYS":       "client-a,client-b",
+	"ROUTER_FALLBACK_RESPONSES":           `{"default":{"body":0},"traffic_rules":{"vip":{"status":503,"body":{}}}}`,
+	"ROUTER_DEBUG_RESPONSE_ENABLED":       "true",
+	"APP_LOGLEVEL":                        "DEBUG",
+	"APP_FIBER_DEBUG_LOG":                 "true",
+	"APP_RESULT_LOGGER":                   "CONSOLE",
+	"APP_RESULT_LOGGERS":                  "console,kafka",
+	"APP_GCP_PROJECT":                     "gcp-project-id",
+	"APP_BQ_DATASET":                      "turing",
+	"APP_BQ_TABLE":                        "turing-test",
+	"APP_BQ_BATCH_LOAD":                   "true",
+	"APP_CUSTOM_METRICS":                  "true",
+	"APP_FLUENTD_HOST":                    "localhost",
+	"APP_FLUENTD_PORT":                    "24224",
+	"APP_FLUENTD_TAG":                     "response.log",
+	"APP_KAFKA_BROKERS":                   "localhost:9000",
+	"APP_KAFKA_TOPIC":                     "kafka_topic",
+	"APP_KAFKA_SERIALIZATION_FORMAT":      "json",
+	"APP_JAEGER_ENABLED":                  "true",
+	"APP_JAEGER_COLLECTOR_ENDPOINT":       "http://localhost:5000",
+	"APP_JAEGER_REPORTER_HOST":            "localhost",
+	"APP_JAEGER_REPORTER_PORT":            "5001",
+	"APP_SENTRY_ENABLED":                  "true",
+	"APP_SENTRY_DSN":                      "test:dsn",
+	"APP_SENTRY_LABELS":                   "sentry_key1:value1,sentry_key2:value2",
+	"APP_RESULT_LOG_POLICY": `{"sampling_rate":0.1,"sampling_unit":{"field_source":"header","field":"X-User-ID"},` +
+		`"redactions":[{"field_source":"payload","field":"customer.phone","action":"mask"}]}`,
+	"APP_RESULT_LOG_SPOOL_DIR":                 "/var/spool/turing",
+	"APP_RESULT_LOG_SPOOL_MAX_BYTES":           "1048576",
+	"APP_RESULT_LOG_SPOOL_SEGMENT_BYTES":       "65536",
+	"APP_RESULT_LOG_SPOOL_REPLAY_INTERVAL":     "1s",
+	"APP_RESULT_LOG_SPOOL_MAX_REPLAY_ATTEMPTS": "3",
+	"APP_RESULT_LOG_SPOOL_CLOSE_TIMEOUT":       "2s",
+	"APP_RESULT_LOG_QUEUE_SIZE":                "5000",
+	"APP_RESULT_LOG_QUEUE_WORKERS":             "8",
+	"APP_RESULT_LOG_QUEUE_BATCH_SIZE":          "500",
+	"APP_RESULT_LOG_QUEUE_FLUSH_INTERVAL":      "200ms",
+	"APP_RESULT_LOG_QUEUE_OVERFLOW_POLICY":     "Drop-Oldest",
+	"APP_SHUTDOWN_GRACE_PERIOD":                "10s",
+	"APP_SHUTDOWN_DRAIN_DELAY":                 "1s",
 }
 
 func TestMissingRequiredEnvs(t *testing.T) {
@@ -86,6 +138,25 @@ func TestInitConfigDefaultEnvs(t *testing.T) {
 			ConfigFile: "/var/test.yaml",
 			Timeout:    20 * time.Millisecond,
 			Protocol:   HTTP,
+			Cache: &CacheConfig{
+				Enabled:    false,
+				MaxEntries: 10000,
+				TTL:        time.Minute,
+			},
+			Reload: &ReloadConfig{PushPort: 8081},
+			Bandit: &BanditConfig{
+				FeedbackTTL:         10 * time.Minute,
+				MaxPendingFeedbacks: 100000,
+				SnapshotInterval:    time.Minute,
+			},
+			Auth: &AuthConfig{
+				JWT: &JWTAuthConfig{
+					RefreshInterval: 15 * time.Minute,
+					IdentityClaim:   "sub",
+				},
+			},
+			RateLimit: &RateLimitConfig{MaxKeys: 10000},
+			Fallback:  &FallbackConfig{},
 		},
 		EnsemblerConfig: &EnsemblerConfig{
 			Endpoint: "",
@@ -127,6 +198,23 @@ func TestInitConfigDefaultEnvs(t *testing.T) {
 				DSN:     "",
 				Labels:  nil,
 			},
+			ResultLogPolicy: &ResultLogPolicy{},
+			ResultLogSpool: &ResultLogSpoolConfig{
+				MaxBytes:          1073741824,
+				SegmentBytes:      67108864,
+				ReplayInterval:    5 * time.Second,
+				MaxReplayAttempts: 10,
+				CloseTimeout:      5 * time.Second,
+			},
+			ResultLogQueue: &ResultLogQueueConfig{
+				Size:           0,
+				Workers:        4,
+				BatchSize:      100,
+				FlushInterval:  time.Second,
+				OverflowPolicy: DropNewestOverflowPolicy,
+			},
+			ShutdownGracePeriod: 30 * time.Second,
+			ShutdownDrainDelay:  5 * time.Second,
 		},
 	}
 
@@ -140,6 +228,7 @@ func TestInitConfigDefaultEnvs(t *testing.T) {
 }
 
 func TestInitConfigEnv(t *testing.T) {
+	samplingRate := 0.1
 	expected := Config{
 		Port: 8080,
 		EnrichmentConfig: &EnrichmentConfig{
@@ -150,17 +239,73 @@ func TestInitConfigEnv(t *testing.T) {
 			ConfigFile: "/var/test.yaml",
 			Timeout:    10 * time.Millisecond,
 			Protocol:   UPI,
+			Cache: &CacheConfig{
+				Enabled:    true,
+				MaxEntries: 100,
+				TTL:        30 * time.Second,
+				KeyFields: CacheKeyFields{
+					{FieldSource: request.HeaderFieldSource, Field: "X-User-Id"},
+				},
+			},
+			Reload: &ReloadConfig{
+				WatchInterval: 10 * time.Second,
+				PushEnabled:   true,
+				PushPort:      9090,
+			},
+			Bandit: &BanditConfig{
+				FeedbackTTL:         5 * time.Minute,
+				MaxPendingFeedbacks: 1000,
+				SnapshotFile:        "/var/bandit.json",
+				SnapshotInterval:    30 * time.Second,
+			},
+			Auth: &AuthConfig{
+				APIKeysFile: "/var/secret/router-auth/api-keys.json",
+				JWT: &JWTAuthConfig{
+					JWKSURL:         "https://auth.example.com/.well-known/jwks.json",
+					RefreshInterval: time.Hour,
+					Issuer:          "https://auth.example.com",
+					Audience:        "turing-router",
+					RequiredClaims:  RequiredClaims{"scope": "predict", "tier": "gold"},
+					IdentityClaim:   "client_id",
+				},
+			},
+			RateLimit: &RateLimitConfig{
+				FieldSource:    request.HeaderFieldSource,
+				Field:          "X-Client-ID",
+				RequestsPerSec: 12.5,
+				Burst:          20,
+				MaxInFlight:    8,
+				MaxKeys:        500,
+				MetricKeys:     []string{"client-a", "client-b"},
+			},
+			Fallback: &FallbackConfig{
+				Default: &FallbackResponse{Body: json.RawMessage(`0`)},
+				TrafficRules: map[string]*FallbackResponse{
+					"vip": {Status: 503, Body: json.RawMessage(`{}`)},
+				},
+			},
+			DebugResponseEnabled: true,
 		},
 		EnsemblerConfig: &EnsemblerConfig{
 			Endpoint: "http://localhost:8082",
 			Timeout:  2 * time.Millisecond,
 		},
+		ProcessingStages: ProcessingStages{
+			{
+				Name:        "fraud-check",
+				Phase:       router.PreprocessingPhase,
+				Endpoint:    "http://localhost:8083",
+				Timeout:     fiberConfig.Duration(5 * time.Millisecond),
+				ErrorPolicy: router.SkipErrorPolicy,
+			},
+		},
 		AppConfig: &AppConfig{
 			Name:          "turing",
 			Environment:   "dev",
 			LogLevel:      "DEBUG",
 			FiberDebugLog: true,
 			ResultLogger:  "CONSOLE",
+			ResultLoggers: []ResultLogger{ConsoleLogger, KafkaLogger},
 			BigQuery: &BQConfig{
 				Project:   "gcp-project-id",
 				Dataset:   "turing",
@@ -194,6 +339,30 @@ func TestInitConfigEnv(t *testing.T) {
 					"sentry_key2": "value2",
 				},
 			},
+			ResultLogPolicy: &ResultLogPolicy{
+				SamplingRate: &samplingRate,
+				SamplingUnit: &ResultLogSamplingUnit{FieldSource: request.HeaderFieldSource, Field: "X-User-ID"},
+				Redactions: []ResultLogRedaction{
+					{FieldSource: request.PayloadFieldSource, Field: "customer.phone", Action: MaskRedactionAction},
+				},
+			},
+			ResultLogSpool: &ResultLogSpoolConfig{
+				Dir:               "/var/spool/turing",
+				MaxBytes:          1048576,
+				SegmentBytes:      65536,
+				ReplayInterval:    time.Second,
+				MaxReplayAttempts: 3,
+				CloseTimeout:      2 * time.Second,
+			},
+			ResultLogQueue: &ResultLogQueueConfig{
+				Size:           5000,
+				Workers:        8,
+				BatchSize:      500,
+				FlushInterval:  200 * time.Millisecond,
+				OverflowPolicy: DropOldestOverflowPolicy,
+			},
+			ShutdownGracePeriod: 10 * time.Second,
+			ShutdownDrainDelay:  time.Second,
 		},
 	}
 
@@ -312,6 +481,102 @@ func TestResultLoggerDecode(t *testing.T) {
 	}
 }
 
+func TestOverflowPolicyDecode(t *testing.T) {
+	tests := map[string]struct {
+		value   string
+		result  OverflowPolicy
+		success bool
+	}{
+		"drop-newest": {
+			value:   "drop-newest",
+			result:  DropNewestOverflowPolicy,
+			success: true,
+		},
+		"drop-oldest": {
+			value:   "DROP-OLDEST",
+			result:  DropOldestOverflowPolicy,
+			success: true,
+		},
+		"block": {
+			value:   "block",
+			result:  BlockOverflowPolicy,
+			success: true,
+		},
+		"unknown_policy": {
+			value:   "drop",
+			result:  OverflowPolicy(""),
+			success: false,
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			var policy OverflowPolicy
+			err := policy.Decode(data.value)
+
+			assert.Equal(t, data.result, policy)
+			assert.Equal(t, data.success, err == nil)
+		})
+	}
+}
+
+func TestAppConfigResultLoggerTypes(t *testing.T) {
+	cfg := &AppConfig{ResultLogger: BigqueryLogger}
+	assert.Equal(t, []ResultLogger{BigqueryLogger}, cfg.ResultLoggerTypes())
+
+	// The result loggers take precedence over the single result logger
+	cfg.ResultLoggers = []ResultLogger{KafkaLogger, BigqueryLogger}
+	assert.Equal(t, []ResultLogger{KafkaLogger, BigqueryLogger}, cfg.ResultLoggerTypes())
+}
+
+func TestResultLogPolicyDecode(t *testing.T) {
+	tests := map[string]struct {
+		value         string
+		expectedError string
+	}{
+		"empty": {
+			value: "",
+		},
+		"valid": {
+			value: `{"sampling_rate":0.5,"redactions":[{"field_source":"header","field":"X-Phone","action":"hash"}],` +
+				`"hash_key_file":"/var/secret/router-result-log/hash-key"}`,
+		},
+		"invalid json": {
+			value:         `{"sampling_rate":`,
+			expectedError: "Failed to parse the result log policy",
+		},
+		"invalid sampling rate": {
+			value:         `{"sampling_rate":1.5}`,
+			expectedError: "Invalid result log sampling rate 1.5",
+		},
+		"invalid action": {
+			value:         `{"redactions":[{"field_source":"header","field":"X-Phone","action":"encrypt"}]}`,
+			expectedError: "Redaction action encrypt not supported",
+		},
+		"invalid field source": {
+			value:         `{"redactions":[{"field_source":"caller","field":"id","action":"drop"}]}`,
+			expectedError: "Redaction field source caller not supported",
+		},
+		"missing hash key file": {
+			value:         `{"redactions":[{"field_source":"header","field":"X-Phone","action":"hash"}]}`,
+			expectedError: "The hash key file is required by the hash redaction of X-Phone",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var policy ResultLogPolicy
+			err := policy.Decode(tt.value)
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
 func TestSerializationFormatDecode(t *testing.T) {
 	// Make test cases
 	tests := map[string]testSuiteSerializationFormat{
@@ -354,3 +619,51 @@ func setupNewEnv(envMaps ...map[string]string) {
 		}
 	}
 }
+
+func TestProcessingStagesDecode(t *testing.T) {
+	tests := map[string]struct {
+		value    string
+		expected ProcessingStages
+		err      string
+	}{
+		"empty": {
+			value: "",
+		},
+		"success": {
+			value: `[{"name":"calibration","phase":"postprocessing","endpoint":"http://calibration",` +
+				`"timeout":"20ms","result_log_key":"calibrated"}]`,
+			expected: ProcessingStages{
+				{
+					Name:         "calibration",
+					Phase:        router.PostprocessingPhase,
+					Endpoint:     "http://calibration",
+					Timeout:      fiberConfig.Duration(20 * time.Millisecond),
+					ResultLogKey: "calibrated",
+				},
+			},
+		},
+		"invalid json": {
+			value: `{"name":"calibration"}`,
+			err: "Failed to parse processing stages: " +
+				"json: cannot unmarshal object into Go value of type config.ProcessingStages",
+		},
+		"invalid phase": {
+			value: `[{"name":"calibration","phase":"routing","endpoint":"http://calibration","timeout":"20ms"}]`,
+			err: "Invalid processing stage: Key: 'ProcessingStage.Phase' " +
+				"Error:Field validation for 'Phase' failed on the 'oneof' tag",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var stages ProcessingStages
+			err := stages.Decode(tt.value)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, stages)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gojek/fiber"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/turingctx"
+)
+
+const (
+	// HeaderKey is the request header (or the gRPC request metadata key) that requests the debug trace
+	// of the request, if debug responses are enabled for the router
+	HeaderKey = "Turing-Debug"
+	// ResponseMetadataKey is the gRPC response header, that holds the debug trace of a UPI request
+	ResponseMetadataKey = "turing-debug-bin"
+)
+
+// IsRequested returns whether the value of the debug header requests the debug trace
+func IsRequested(value string) bool {
+	requested, err := strconv.ParseBool(value)
+	return err == nil && requested
+}
+
+// Component holds the response / error of a Turing component
+type Component struct {
+	Response json.RawMessage `json:"response,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// RouteCall holds the response received from a route
+type RouteCall struct {
+	RouteID   string          `json:"route_id"`
+	Status    int             `json:"status"`
+	LatencyMs int64           `json:"latency_ms"`
+	Response  json.RawMessage `json:"response,omitempty"`
+}
+
+// StageCall holds the response / error of a processing stage
+type StageCall struct {
+	Name string `json:"name"`
+	Component
+}
+
+// Info is the debug trace of a request, that is returned to the client
+type Info struct {
+	TuringReqID    string          `json:"turing_req_id"`
+	CacheHit       bool            `json:"cache_hit,omitempty"`
+	Enricher       *Component      `json:"enricher,omitempty"`
+	Experiment     *Component      `json:"experiment,omitempty"`
+	TrafficRule    string          `json:"traffic_rule,omitempty"`
+	Routes         []*RouteCall    `json:"routes"`
+	Router         *Component      `json:"router,omitempty"`
+	EnsemblerInput json.RawMessage `json:"ensembler_input,omitempty"`
+	Ensembler      *Component      `json:"ensembler,omitempty"`
+	Stages         []*StageCall    `json:"stages,omitempty"`
+}
+
+// Envelope is the response to a request in debug mode, that holds the final response
+// (or error) of the router, together with the debug trace of the request
+type Envelope struct {
+	Response json.RawMessage `json:"response,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Debug    *Info           `json:"debug"`
+}
+
+// Trace collects the outputs of the Turing components and the responses of the routes, while
+// a request in debug mode is being processed. All the methods are no-ops on a nil Trace, so that
+// the callers need not check whether the request is in debug mode.
+type Trace struct {
+	// encode converts the payload of a route response to JSON
+	encode func(fiber.Response) json.RawMessage
+
+	mu      sync.Mutex
+	closed  bool
+	pending sync.WaitGroup
+	info    Info
+}
+
+// NewTrace creates a new Trace for the given request. The payloads of the route responses are
+// converted to JSON with the given function.
+func NewTrace(turingReqID string, encode func(fiber.Response) json.RawMessage) *Trace {
+	return &Trace{
+		encode: encode,
+		info:   Info{TuringReqID: turingReqID, Routes: []*RouteCall{}},
+	}
+}
+
+// JSONPayload returns the given payload as is, if it is valid JSON, or as a JSON string otherwise
+func JSONPayload(payload []byte) json.RawMessage {
+	if len(payload) == 0 {
+		return nil
+	}
+	if json.Valid(payload) {
+		return payload
+	}
+	encoded, _ := json.Marshal(string(payload))
+	return encoded
+}
+
+// EncodeJSONResponse converts the payload of a route response to JSON, using JSONPayload
+func EncodeJSONResponse(resp fiber.Response) json.RawMessage {
+	return JSONPayload(resp.Payload())
+}
+
+// SetCacheHit records that the response was served from the response cache
+func (t *Trace) SetCacheHit() {
+	t.update(func(info *Info) { info.CacheHit = true })
+}
+
+// SetEnricher records the response / error of the enricher
+func (t *Trace) SetEnricher(c *Component) {
+	t.update(func(info *Info) { info.Enricher = c })
+}
+
+// SetExperiment records the treatment / error from the experiment engine
+func (t *Trace) SetExperiment(c *Component) {
+	t.update(func(info *Info) { info.Experiment = c })
+}
+
+// SetTrafficRule records the traffic rule, that the request matched
+func (t *Trace) SetTrafficRule(rule string) {
+	t.update(func(info *Info) { info.TrafficRule = rule })
+}
+
+// SetRouter records the response / error of the router
+func (t *Trace) SetRouter(c *Component) {
+	t.update(func(info *Info) { info.Router = c })
+}
+
+// SetEnsemblerInput records the request sent to the ensembler
+func (t *Trace) SetEnsemblerInput(input json.RawMessage) {
+	t.update(func(info *Info) { info.EnsemblerInput = input })
+}
+
+// SetEnsembler records the response / error of the ensembler
+func (t *Trace) SetEnsembler(c *Component) {
+	t.update(func(info *Info) { info.Ensembler = c })
+}
+
+// AddStage records the response / error of a processing stage, in the order in which the stages run
+func (t *Trace) AddStage(name string, c *Component) {
+	t.update(func(info *Info) { info.Stages = append(info.Stages, &StageCall{Name: name, Component: *c}) })
+}
+
+// BeginRoute registers a call to a route that is about to be dispatched. It returns false if
+// the trace has already been closed by Wait, in which case the call should not be recorded.
+func (t *Trace) BeginRoute() bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return false
+	}
+	t.pending.Add(1)
+	return true
+}
+
+// RecordRoute saves the responses of a call to a route, previously registered with BeginRoute
+func (t *Trace) RecordRoute(routeID string, latency time.Duration, responses ...fiber.Response) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	for _, resp := range responses {
+		t.info.Routes = append(t.info.Routes, &RouteCall{
+			RouteID:   routeID,
+			Status:    resp.StatusCode(),
+			LatencyMs: latency.Milliseconds(),
+			Response:  t.encode(resp),
+		})
+	}
+	t.mu.Unlock()
+
+	t.pending.Done()
+}
+
+// Wait closes the trace for new calls to the routes, waits for all the pending ones
+// to complete and returns the debug trace of the request
+func (t *Trace) Wait() *Info {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	t.closed = true
+	t.mu.Unlock()
+
+	t.pending.Wait()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	info := t.info
+	info.Routes = append([]*RouteCall{}, t.info.Routes...)
+	return &info
+}
+
+func (t *Trace) update(fn func(*Info)) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn(&t.info)
+}
+
+// WithTrace associates the debug trace with the given context object
+func WithTrace(ctx context.Context, trace *Trace) context.Context {
+	return context.WithValue(ctx, turingctx.TuringDebugTraceKey, trace)
+}
+
+// GetTrace returns the debug trace from the input context
+func GetTrace(ctx context.Context) (*Trace, error) {
+	if ctxValue, ok := ctx.Value(turingctx.TuringDebugTraceKey).(*Trace); ok {
+		return ctxValue, nil
+	}
+	return nil, errors.Newf(errors.Unknown, "Debug trace not found in the context")
+}
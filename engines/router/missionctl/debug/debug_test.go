@@ -0,0 +1,94 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	fiberHttp "github.com/gojek/fiber/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRequested(t *testing.T) {
+	assert.True(t, IsRequested("true"))
+	assert.True(t, IsRequested("1"))
+	assert.False(t, IsRequested("false"))
+	assert.False(t, IsRequested(""))
+	assert.False(t, IsRequested("yes"))
+}
+
+func TestJSONPayload(t *testing.T) {
+	assert.Equal(t, json.RawMessage(`{"key": "value"}`), JSONPayload([]byte(`{"key": "value"}`)))
+	assert.Equal(t, json.RawMessage(`"not json"`), JSONPayload([]byte(`not json`)))
+	assert.Nil(t, JSONPayload(nil))
+}
+
+func TestTrace(t *testing.T) {
+	trace := NewTrace("test-req-id", EncodeJSONResponse)
+	trace.SetEnricher(&Component{Response: json.RawMessage(`{"enriched": true}`)})
+	trace.SetExperiment(&Component{Error: "experiment error"})
+	trace.SetTrafficRule("rule-a")
+	trace.SetRouter(&Component{Response: json.RawMessage(`{"routed": true}`)})
+	trace.SetEnsemblerInput(json.RawMessage(`{"request": {}}`))
+	trace.SetEnsembler(&Component{Response: json.RawMessage(`{"ensembled": true}`)})
+	trace.AddStage("calibration", &Component{Error: "stage error"})
+
+	require.True(t, trace.BeginRoute())
+	require.True(t, trace.BeginRoute())
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		trace.RecordRoute("route-a", 10*time.Millisecond, fiberHttp.NewHTTPResponse(&http.Response{
+			StatusCode: http.StatusOK,
+			Body:       http.NoBody,
+		}))
+	}()
+	trace.RecordRoute("route-b", time.Millisecond, fiberHttp.NewHTTPResponse(&http.Response{
+		StatusCode: http.StatusCreated,
+		Body:       io.NopCloser(strings.NewReader(`{"key": "value"}`)),
+	}))
+
+	info := trace.Wait()
+	assert.ElementsMatch(t, []*RouteCall{
+		{RouteID: "route-a", Status: http.StatusOK, LatencyMs: 10},
+		{RouteID: "route-b", Status: http.StatusCreated, LatencyMs: 1, Response: json.RawMessage(`{"key": "value"}`)},
+	}, info.Routes)
+	info.Routes = nil
+	assert.Equal(t, &Info{
+		TuringReqID:    "test-req-id",
+		Enricher:       &Component{Response: json.RawMessage(`{"enriched": true}`)},
+		Experiment:     &Component{Error: "experiment error"},
+		TrafficRule:    "rule-a",
+		Router:         &Component{Response: json.RawMessage(`{"routed": true}`)},
+		EnsemblerInput: json.RawMessage(`{"request": {}}`),
+		Ensembler:      &Component{Response: json.RawMessage(`{"ensembled": true}`)},
+		Stages:         []*StageCall{{Name: "calibration", Component: Component{Error: "stage error"}}},
+	}, info)
+
+	// Calls to the routes, that begin after the trace is closed, are not recorded
+	assert.False(t, trace.BeginRoute())
+}
+
+func TestNilTrace(t *testing.T) {
+	var trace *Trace
+	trace.SetTrafficRule("rule-a")
+	trace.SetRouter(&Component{Error: "router error"})
+	trace.AddStage("calibration", &Component{Error: "stage error"})
+	assert.False(t, trace.BeginRoute())
+	assert.Nil(t, trace.Wait())
+}
+
+func TestGetTrace(t *testing.T) {
+	_, err := GetTrace(context.Background())
+	assert.EqualError(t, err, "Debug trace not found in the context")
+
+	trace := NewTrace("test-req-id", EncodeJSONResponse)
+	got, err := GetTrace(WithTrace(context.Background(), trace))
+	assert.NoError(t, err)
+	assert.Equal(t, trace, got)
+}
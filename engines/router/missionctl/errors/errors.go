@@ -28,6 +28,10 @@ const (
 	NotFound
 	// TimeOut is used when a request / go routine times out
 	TimeOut
+	// Unavailable is used when a downstream service is temporarily not accepting requests
+	Unavailable
+	// Unauthenticated is used when the caller's credentials are missing or invalid
+	Unauthenticated
 )
 
 type turingError struct {
@@ -87,6 +91,10 @@ func GetErrorCode(err error, p fiberProtocol.Protocol) int {
 			code = http.StatusBadGateway
 		case NotFound:
 			code = http.StatusNotFound
+		case Unavailable:
+			code = http.StatusServiceUnavailable
+		case Unauthenticated:
+			code = http.StatusUnauthorized
 		default:
 			code = http.StatusInternalServerError
 		}
@@ -98,6 +106,10 @@ func GetErrorCode(err error, p fiberProtocol.Protocol) int {
 			code = int(codes.Internal)
 		case NotFound:
 			code = int(codes.NotFound)
+		case Unavailable:
+			code = int(codes.Unavailable)
+		case Unauthenticated:
+			code = int(codes.Unauthenticated)
 		default:
 			code = int(codes.Internal)
 		}
@@ -78,6 +78,16 @@ func TestGetHTTPErrorCode(t *testing.T) {
 			err:          Newf(NotFound, ""),
 			expectedCode: http.StatusNotFound,
 		},
+		{
+			name:         "Unavailable",
+			err:          Newf(Unavailable, ""),
+			expectedCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:         "Unauthenticated",
+			err:          Newf(Unauthenticated, ""),
+			expectedCode: http.StatusUnauthorized,
+		},
 	}
 
 	for _, data := range testErrorSuite {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
@@ -18,12 +19,14 @@ import (
 	"github.com/caraml-dev/turing/engines/router/missionctl/turingctx"
 )
 
-// NewExperimentRunner returns an instance of the Planner, based on the input engine name
+// NewExperimentRunner returns an instance of the Planner, based on the input engine name. If the treatment
+// cache config is set, the treatments returned by the experiment engine are cached by the unit ID of the requests.
 func NewExperimentRunner(
 	name string,
 	cfg map[string]interface{},
 	livenessPeriodSeconds int,
-) (runner.ExperimentRunner, error) {
+	cacheCfg *runner.TreatmentCacheConfig,
+) (runner.ContextExperimentRunner, error) {
 	factory, err := experiment.NewEngineFactory(name, cfg, log.Glob())
 	if err != nil {
 		return nil, err
@@ -39,19 +42,31 @@ func NewExperimentRunner(
 		return nil, err
 	}
 
-	if err := engine.RegisterMetricsCollector(metrics.Glob(), _metrics.MetricsRegistrationHelper{}); err != nil {
-		return nil, err
-	}
-
 	interceptors := []runner.Interceptor{
 		NewMetricsInterceptor(),
 	}
 
-	return runner.NewInterceptRunner(name, engine, interceptors...), nil
+	expRunner := runner.NewInterceptRunner(name, engine, interceptors...)
+	// The cache wraps the interceptors, so that the cache hits are not measured as experiment engine requests
+	if cacheCfg != nil {
+		expRunner = runner.NewCachingRunner(name, expRunner, *cacheCfg)
+	}
 
+	if err := expRunner.RegisterMetricsCollector(metrics.Glob(), _metrics.MetricsRegistrationHelper{}); err != nil {
+		return nil, err
+	}
+
+	return expRunner, nil
 }
 
+// monitoredRPCEngineFactories is the set of the rpc engine factories, which plugins are monitored. The factories
+// are shared by the experiment runners with the same config, e.g. when the router's config is reloaded.
+var monitoredRPCEngineFactories sync.Map
+
 func startRPCPluginMonitoring(rpcEngineFactory *rpc.EngineFactory, livenessPeriodSeconds int) {
+	if _, monitored := monitoredRPCEngineFactories.LoadOrStore(rpcEngineFactory, true); monitored {
+		return
+	}
 	ticker := time.NewTicker(time.Duration(livenessPeriodSeconds) * time.Second)
 	go func() {
 		for {
@@ -38,7 +38,7 @@ func TestNewExperimentRunner(t *testing.T) {
 
 	for name, data := range tests {
 		t.Run(name, func(t *testing.T) {
-			_, err := NewExperimentRunner(data.engineName, data.config, data.livenessPeriodSeconds)
+			_, err := NewExperimentRunner(data.engineName, data.config, data.livenessPeriodSeconds, nil)
 			if (err != nil) != data.wantErr {
 				t.Errorf("NewExperimentRunner() error= %v, wantErr %v", err, data.wantErr)
 			}
@@ -0,0 +1,184 @@
+// Package fallback serves the router's static fallback responses, in place of the errors of the routes,
+// the ensembler or the experiment engine, so that the callers still receive a usable response.
+package fallback
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+	"github.com/caraml-dev/turing/engines/router/missionctl/turingctx"
+)
+
+// HeaderKey is the response header (or the gRPC response metadata key), that marks the fallback responses
+const HeaderKey = "Turing-Fallback"
+
+// Reason is the Turing component, which failure the fallback response is served for
+type Reason string
+
+const (
+	// RouterReason serves the fallback response, when the routes fail or time out
+	RouterReason Reason = "router"
+	// ExperimentReason serves the fallback response, when the experiment engine fails and the default
+	// route fails too
+	ExperimentReason Reason = "experiment"
+	// EnsemblerReason serves the fallback response, when the ensembler fails
+	EnsemblerReason Reason = "ensembler"
+)
+
+// Response is a fallback response, parsed for the router's protocol. It implements the router's
+// HTTP Response interface.
+type Response struct {
+	// Status is the HTTP status of the response
+	Status int
+	// Payload is the JSON payload of the response
+	Payload []byte
+	// UPIResponse is the response of the UPI routers, parsed from the payload
+	UPIResponse *upiv1.PredictValuesResponse
+}
+
+// Body returns the payload of the HTTP fallback response
+func (r *Response) Body() []byte {
+	return r.Payload
+}
+
+// Header returns the header of the HTTP fallback response, that marks it as a fallback response
+func (r *Response) Header() http.Header {
+	return http.Header{
+		"Content-Type": []string{"application/json"},
+		HeaderKey:      []string{"true"},
+	}
+}
+
+// Responses holds the router's default fallback response and the fallback responses of its traffic rules
+type Responses struct {
+	defaultResponse *Response
+	trafficRules    map[string]*Response
+}
+
+// NewResponses parses the fallback responses in the given config, for the given router protocol.
+// It returns nil, if no fallback response is configured.
+func NewResponses(cfg *config.FallbackConfig, protocol config.Protocol) (*Responses, error) {
+	if cfg == nil || (cfg.Default == nil && len(cfg.TrafficRules) == 0) {
+		return nil, nil
+	}
+
+	var err error
+	responses := &Responses{trafficRules: make(map[string]*Response, len(cfg.TrafficRules))}
+	if cfg.Default != nil {
+		if responses.defaultResponse, err = newResponse(cfg.Default, protocol); err != nil {
+			return nil, errors.Wrapf(err, "Invalid default fallback response")
+		}
+	}
+	for rule, resp := range cfg.TrafficRules {
+		if responses.trafficRules[rule], err = newResponse(resp, protocol); err != nil {
+			return nil, errors.Wrapf(err, "Invalid fallback response of traffic rule %s", rule)
+		}
+	}
+	return responses, nil
+}
+
+// Get returns the fallback response of the given traffic rule or, if the rule doesn't have one,
+// the router's default fallback response, and records it in the fallback responses metric.
+// It returns nil, if there is no fallback response to serve.
+func (r *Responses) Get(trafficRule string, reason Reason) *Response {
+	if r == nil {
+		return nil
+	}
+	resp, ok := r.trafficRules[trafficRule]
+	if !ok {
+		resp = r.defaultResponse
+	}
+	if resp == nil {
+		return nil
+	}
+
+	if err := metrics.Glob().Inc(
+		instrumentation.FallbackResponsesTotal,
+		map[string]string{"traffic_rule": trafficRule, "reason": string(reason)},
+	); err != nil {
+		log.Glob().Errorf("Failed to record the fallback response of traffic rule %q: %s", trafficRule, err.Error())
+	}
+	return resp
+}
+
+func newResponse(cfg *config.FallbackResponse, protocol config.Protocol) (*Response, error) {
+	if cfg == nil {
+		return nil, errors.Newf(errors.BadConfig, "Missing fallback response")
+	}
+	resp := &Response{Status: cfg.Status, Payload: cfg.Body}
+	if protocol == config.UPI {
+		resp.Status = http.StatusOK
+		resp.UPIResponse = &upiv1.PredictValuesResponse{}
+		if err := protojson.Unmarshal(cfg.Body, resp.UPIResponse); err != nil {
+			return nil, errors.Newf(errors.BadConfig, "Failed to parse the UPI response: %s", err.Error())
+		}
+		return resp, nil
+	}
+
+	if resp.Status == 0 {
+		resp.Status = http.StatusOK
+	}
+	if resp.Status < http.StatusOK || resp.Status > 599 {
+		return nil, errors.Newf(errors.BadConfig, "Invalid status %d", resp.Status)
+	}
+	if !json.Valid(cfg.Body) {
+		return nil, errors.Newf(errors.BadConfig, "The body is not valid JSON")
+	}
+	return resp, nil
+}
+
+// Selection records the traffic rule, that the request matched, so that its fallback response can be
+// served, even if the routes of the rule fail and their response doesn't carry the traffic rule label
+type Selection struct {
+	mu          sync.Mutex
+	trafficRule string
+}
+
+// NewSelection creates a new Selection
+func NewSelection() *Selection {
+	return &Selection{}
+}
+
+// SetTrafficRule records the traffic rule, that the request matched. It is a no-op on a nil selection,
+// so that the routing strategies can call it regardless of whether the selection is recorded.
+func (s *Selection) SetTrafficRule(rule string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trafficRule = rule
+}
+
+// TrafficRule returns the traffic rule, that the request matched, which is empty if none was recorded
+func (s *Selection) TrafficRule() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.trafficRule
+}
+
+// WithSelection associates the traffic rule selection with the given context object
+func WithSelection(ctx context.Context, selection *Selection) context.Context {
+	return context.WithValue(ctx, turingctx.TuringFallbackSelectionKey, selection)
+}
+
+// GetSelection returns the traffic rule selection from the input context
+func GetSelection(ctx context.Context) (*Selection, error) {
+	if ctxValue, ok := ctx.Value(turingctx.TuringFallbackSelectionKey).(*Selection); ok {
+		return ctxValue, nil
+	}
+	return nil, errors.Newf(errors.Unknown, "Fallback selection not found in the context")
+}
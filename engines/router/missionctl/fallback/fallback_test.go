@@ -0,0 +1,162 @@
+package fallback
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+)
+
+func TestNewResponsesDisabled(t *testing.T) {
+	responses, err := NewResponses(nil, config.HTTP)
+	require.NoError(t, err)
+	assert.Nil(t, responses)
+
+	responses, err = NewResponses(&config.FallbackConfig{}, config.HTTP)
+	require.NoError(t, err)
+	assert.Nil(t, responses)
+
+	// Get is a no-op, if there are no fallback responses
+	assert.Nil(t, responses.Get("rule-a", RouterReason))
+}
+
+func TestNewResponses(t *testing.T) {
+	tests := map[string]struct {
+		cfg           *config.FallbackConfig
+		protocol      config.Protocol
+		expectedError string
+	}{
+		"http": {
+			cfg: &config.FallbackConfig{
+				Default: &config.FallbackResponse{Body: json.RawMessage(`{"score": 0}`)},
+				TrafficRules: map[string]*config.FallbackResponse{
+					"rule-a": {Status: http.StatusServiceUnavailable, Body: json.RawMessage(`[]`)},
+				},
+			},
+			protocol: config.HTTP,
+		},
+		"http | invalid status": {
+			cfg: &config.FallbackConfig{
+				Default: &config.FallbackResponse{Status: 99, Body: json.RawMessage(`{}`)},
+			},
+			protocol:      config.HTTP,
+			expectedError: "Invalid default fallback response: Invalid status 99",
+		},
+		"http | invalid body": {
+			cfg: &config.FallbackConfig{
+				TrafficRules: map[string]*config.FallbackResponse{
+					"rule-a": {Body: json.RawMessage(`{"score":`)},
+				},
+			},
+			protocol:      config.HTTP,
+			expectedError: "Invalid fallback response of traffic rule rule-a: The body is not valid JSON",
+		},
+		"http | missing response": {
+			cfg: &config.FallbackConfig{
+				TrafficRules: map[string]*config.FallbackResponse{"rule-a": nil},
+			},
+			protocol:      config.HTTP,
+			expectedError: "Invalid fallback response of traffic rule rule-a: Missing fallback response",
+		},
+		"upi": {
+			cfg: &config.FallbackConfig{
+				Default: &config.FallbackResponse{
+					Body: json.RawMessage(`{"prediction_result_table": {"name": "fallback"}}`),
+				},
+			},
+			protocol: config.UPI,
+		},
+		"upi | invalid body": {
+			cfg: &config.FallbackConfig{
+				Default: &config.FallbackResponse{Body: json.RawMessage(`{"score": 0}`)},
+			},
+			protocol:      config.UPI,
+			expectedError: "Invalid default fallback response: Failed to parse the UPI response",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			responses, err := NewResponses(tt.cfg, tt.protocol)
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, responses)
+		})
+	}
+}
+
+func TestResponsesGet(t *testing.T) {
+	responses, err := NewResponses(&config.FallbackConfig{
+		Default: &config.FallbackResponse{Body: json.RawMessage(`{"score": 0}`)},
+		TrafficRules: map[string]*config.FallbackResponse{
+			"rule-a": {Status: http.StatusServiceUnavailable, Body: json.RawMessage(`{"score": 1}`)},
+		},
+	}, config.HTTP)
+	require.NoError(t, err)
+
+	// The fallback response of the traffic rule takes precedence over the default one
+	resp := responses.Get("rule-a", RouterReason)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Status)
+	assert.JSONEq(t, `{"score": 1}`, string(resp.Body()))
+	assert.Equal(t, "true", resp.Header().Get(HeaderKey))
+	assert.Nil(t, resp.UPIResponse)
+
+	// The default fallback response is served with the OK status, for the other rules
+	resp = responses.Get("rule-b", EnsemblerReason)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusOK, resp.Status)
+	assert.JSONEq(t, `{"score": 0}`, string(resp.Body()))
+
+	// There is no fallback response, for the rules without one, if the default one is not set
+	responses, err = NewResponses(&config.FallbackConfig{
+		TrafficRules: map[string]*config.FallbackResponse{"rule-a": {Body: json.RawMessage(`{}`)}},
+	}, config.HTTP)
+	require.NoError(t, err)
+	assert.Nil(t, responses.Get("rule-b", ExperimentReason))
+}
+
+func TestResponsesGetUPI(t *testing.T) {
+	responses, err := NewResponses(&config.FallbackConfig{
+		Default: &config.FallbackResponse{
+			Status: http.StatusServiceUnavailable,
+			Body:   json.RawMessage(`{"prediction_result_table": {"name": "fallback"}}`),
+		},
+	}, config.UPI)
+	require.NoError(t, err)
+
+	resp := responses.Get("rule-a", RouterReason)
+	require.NotNil(t, resp)
+	// The status is not used by the UPI routers
+	assert.Equal(t, http.StatusOK, resp.Status)
+	assert.True(t, proto.Equal(
+		&upiv1.PredictValuesResponse{PredictionResultTable: &upiv1.Table{Name: "fallback"}},
+		resp.UPIResponse,
+	))
+}
+
+func TestSelection(t *testing.T) {
+	// The selection is a no-op, if it is not recorded for the request
+	selection, err := GetSelection(context.Background())
+	assert.Nil(t, selection)
+	assert.EqualError(t, err, "Fallback selection not found in the context")
+	selection.SetTrafficRule("rule-a")
+	assert.Equal(t, "", selection.TrafficRule())
+
+	ctx := WithSelection(context.Background(), NewSelection())
+	selection, err = GetSelection(ctx)
+	require.NoError(t, err)
+	selection.SetTrafficRule("rule-a")
+	assert.Equal(t, "rule-a", selection.TrafficRule())
+}
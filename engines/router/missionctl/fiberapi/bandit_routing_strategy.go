@@ -0,0 +1,98 @@
+package fiberapi
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/gojek/fiber"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/bandit"
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+	"github.com/caraml-dev/turing/engines/router/missionctl/turingctx"
+)
+
+// BanditRoutingStrategy selects the route using a multi-armed bandit, which learns the best
+// route from the rewards of its selections. The rewards are sent to the router's feedback
+// endpoint, by the Turing request ID. The default route, if any, is used as the fallback.
+type BanditRoutingStrategy struct {
+	DefaultRouteID string `json:"default_route_id"`
+	// RouteIDs are the routes (arms) of the bandit. All the routes are used, if not set.
+	RouteIDs  []string         `json:"route_ids,omitempty" validate:"omitempty,dive,notBlank"`
+	Algorithm bandit.Algorithm `json:"algorithm" validate:"required,oneof=thompson_sampling epsilon_greedy"`
+	// Epsilon is the probability of exploring a random route, for the epsilon-greedy algorithm
+	Epsilon float64 `json:"epsilon" validate:"gte=0,lte=1"`
+}
+
+// Initialize is invoked by the Fiber library to initialize this strategy
+// with the configuration
+func (s *BanditRoutingStrategy) Initialize(properties json.RawMessage) error {
+	if err := json.Unmarshal(properties, s); err != nil {
+		return errors.Wrapf(err, "Failed initializing bandit routing strategy")
+	}
+	if err := validation.Struct(s); err != nil {
+		return errors.Wrapf(err, "Failed initializing bandit routing strategy")
+	}
+
+	return nil
+}
+
+// SelectRoute picks the primary route using the bandit, and records the selection for
+// the Turing request ID, so that the reward of the request can be attributed to the route
+func (s *BanditRoutingStrategy) SelectRoute(
+	ctx context.Context,
+	req fiber.Request,
+	routes map[string]fiber.Component,
+) (fiber.Component, []fiber.Component, fiber.Labels, error) {
+	labels := fiber.NewLabelsMap()
+
+	arms := s.getArms(ctx, routes)
+	if len(arms) == 0 {
+		// This is unexpected, terminate with error.
+		err := errors.Newf(errors.BadConfig, "none of the bandit routes exist in the router")
+		log.WithContext(ctx).Errorf(err.Error())
+		return nil, nil, labels, createFiberError(err, req.Protocol())
+	}
+
+	turingReqID, _ := turingctx.GetRequestID(ctx)
+	routeID := bandit.Glob().Select(turingReqID, arms, bandit.Policy{
+		Algorithm: s.Algorithm,
+		Epsilon:   s.Epsilon,
+	})
+
+	fallbacks := []fiber.Component{}
+	if defRoute, ok := routes[s.DefaultRouteID]; ok && s.DefaultRouteID != routeID {
+		fallbacks = append(fallbacks, defRoute)
+	}
+	return routes[routeID], fallbacks, labels, nil
+}
+
+// getArms returns the IDs of the bandit routes, that exist in the router, in a stable order.
+// The routes with an open circuit breaker are excluded, unless all the routes have one.
+func (s *BanditRoutingStrategy) getArms(ctx context.Context, routes map[string]fiber.Component) []string {
+	routeIDs := s.RouteIDs
+	if len(routeIDs) == 0 {
+		for routeID := range routes {
+			routeIDs = append(routeIDs, routeID)
+		}
+		sort.Strings(routeIDs)
+	}
+
+	var arms, openArms []string
+	for _, routeID := range routeIDs {
+		route, exists := routes[routeID]
+		switch {
+		case !exists:
+			log.WithContext(ctx).Errorf(`bandit route with id "%s" doesn't exist in the router`, routeID)
+		case isCircuitOpen(route):
+			openArms = append(openArms, routeID)
+		default:
+			arms = append(arms, routeID)
+		}
+	}
+	if len(arms) == 0 {
+		return openArms
+	}
+	return arms
+}
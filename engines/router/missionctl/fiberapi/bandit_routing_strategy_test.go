@@ -0,0 +1,139 @@
+package fiberapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	"github.com/stretchr/testify/require"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/bandit"
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/fiberapi"
+	tfu "github.com/caraml-dev/turing/engines/router/missionctl/fiberapi/internal/testutils"
+	tu "github.com/caraml-dev/turing/engines/router/missionctl/internal/testutils"
+	"github.com/caraml-dev/turing/engines/router/missionctl/turingctx"
+)
+
+func TestBanditRoutingStrategy_Initialize(t *testing.T) {
+	type testCase struct {
+		properties    json.RawMessage
+		strategy      *fiberapi.BanditRoutingStrategy
+		expectedError string
+	}
+
+	suite := map[string]testCase{
+		"success | thompson sampling": {
+			properties: json.RawMessage(`{
+				"default_route_id": "control",
+				"algorithm": "thompson_sampling"
+			}`),
+			strategy: &fiberapi.BanditRoutingStrategy{
+				DefaultRouteID: "control",
+				Algorithm:      bandit.ThompsonSampling,
+			},
+		},
+		"success | epsilon greedy": {
+			properties: json.RawMessage(`{
+				"route_ids": ["control", "treatment-a"],
+				"algorithm": "epsilon_greedy",
+				"epsilon": 0.1
+			}`),
+			strategy: &fiberapi.BanditRoutingStrategy{
+				RouteIDs:  []string{"control", "treatment-a"},
+				Algorithm: bandit.EpsilonGreedy,
+				Epsilon:   0.1,
+			},
+		},
+		"failure | unknown algorithm": {
+			properties: json.RawMessage(`{"algorithm": "ucb"}`),
+			expectedError: "Failed initializing bandit routing strategy: " +
+				"Key: 'BanditRoutingStrategy.Algorithm' " +
+				"Error:Field validation for 'Algorithm' failed on the 'oneof' tag",
+		},
+		"failure | invalid epsilon": {
+			properties: json.RawMessage(`{"algorithm": "epsilon_greedy", "epsilon": 1.5}`),
+			expectedError: "Failed initializing bandit routing strategy: " +
+				"Key: 'BanditRoutingStrategy.Epsilon' " +
+				"Error:Field validation for 'Epsilon' failed on the 'lte' tag",
+		},
+		"failure | invalid type": {
+			properties: json.RawMessage(`42`),
+			expectedError: "Failed initializing bandit routing strategy: " +
+				"json: cannot unmarshal number into Go value of type fiberapi.BanditRoutingStrategy",
+		},
+	}
+
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			strategy := new(fiberapi.BanditRoutingStrategy)
+			err := strategy.Initialize(tt.properties)
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+				tu.FailOnError(t, tu.CompareObjects(strategy, tt.strategy))
+			} else {
+				require.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestBanditRoutingStrategy_SelectRoute(t *testing.T) {
+	routes := map[string]fiber.Component{
+		"control":     tfu.NewFiberCaller(t, "control"),
+		"treatment-a": tfu.NewFiberCaller(t, "treatment-a"),
+	}
+	req := tfu.NewHTTPFiberRequest(t, http.Header{}, `{}`)
+
+	t.Run("success", func(t *testing.T) {
+		require.NoError(t, bandit.InitGlobalState(&config.BanditConfig{
+			FeedbackTTL:         time.Minute,
+			MaxPendingFeedbacks: 10,
+		}))
+		strategy := &fiberapi.BanditRoutingStrategy{
+			DefaultRouteID: "control",
+			RouteIDs:       []string{"treatment-a", "treatment-missing"},
+			Algorithm:      bandit.ThompsonSampling,
+		}
+		ctx := turingctx.NewTuringContext(context.Background())
+
+		route, fallbacks, _, err := strategy.SelectRoute(ctx, req, routes)
+		require.NoError(t, err)
+		require.Equal(t, routes["treatment-a"], route)
+		require.Equal(t, []fiber.Component{routes["control"]}, fallbacks)
+
+		// The selection is recorded for the request, to accept its reward
+		turingReqID, err := turingctx.GetRequestID(ctx)
+		require.NoError(t, err)
+		routeID, err := bandit.Glob().Reward(turingReqID, 1)
+		require.NoError(t, err)
+		require.Equal(t, "treatment-a", routeID)
+	})
+
+	t.Run("success | all routes", func(t *testing.T) {
+		strategy := &fiberapi.BanditRoutingStrategy{
+			DefaultRouteID: "control",
+			Algorithm:      bandit.EpsilonGreedy,
+		}
+		route, fallbacks, _, err := strategy.SelectRoute(context.Background(), req, routes)
+		require.NoError(t, err)
+		require.Contains(t, routes, route.ID())
+		if route.ID() == "control" {
+			require.Empty(t, fallbacks)
+		} else {
+			require.Equal(t, []fiber.Component{routes["control"]}, fallbacks)
+		}
+	})
+
+	t.Run("failure | bandit routes don't exist", func(t *testing.T) {
+		strategy := &fiberapi.BanditRoutingStrategy{
+			RouteIDs:  []string{"treatment-missing"},
+			Algorithm: bandit.ThompsonSampling,
+		}
+		_, _, _, err := strategy.SelectRoute(context.Background(), req, routes)
+		require.EqualError(t, err, "none of the bandit routes exist in the router")
+	})
+}
@@ -0,0 +1,253 @@
+package fiberapi
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/experiment"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+)
+
+// BuiltinEnsemblerStrategy is the logic used by the built-in ensembler to combine the route responses
+type BuiltinEnsemblerStrategy string
+
+const (
+	// WeightedAverageStrategy averages the numeric values at the configured paths of the route responses
+	WeightedAverageStrategy BuiltinEnsemblerStrategy = "weighted_average"
+	// MajorityVoteStrategy selects the response with the most common label among the route responses
+	MajorityVoteStrategy BuiltinEnsemblerStrategy = "majority_vote"
+	// FirstSuccessStrategy selects the first successful route response, in the order of priority
+	FirstSuccessStrategy BuiltinEnsemblerStrategy = "first_success"
+)
+
+// builtinEnsemblerCfg is the configuration of the built-in ensembler, set in the fan in properties
+type builtinEnsemblerCfg struct {
+	Strategy BuiltinEnsemblerStrategy `json:"strategy"`
+	// Weights of the routes, by route ID. If not set, all routes have the same weight.
+	Weights map[string]float64 `json:"weights"`
+	// ValuePaths are the paths of the numeric values to average, in the route responses
+	ValuePaths []string `json:"value_paths"`
+	// LabelPath is the path of the label to vote on, in the route responses
+	LabelPath string `json:"label_path"`
+	// RoutePriority is the order of the routes, when selecting a response
+	RoutePriority []string `json:"route_priority"`
+	// TreatmentWeightsPath is the path of the route weights in the treatment config, which override
+	// the configured weights
+	TreatmentWeightsPath string `json:"treatment_weights_path"`
+}
+
+// builtinEnsembler combines the route responses collected by the fan in, without calling an external
+// ensembler. The response of the highest priority route, that is selected or contributes to the result,
+// is used as the base of the ensembled response.
+type builtinEnsembler struct {
+	strategy             BuiltinEnsemblerStrategy
+	weights              map[string]float64
+	valuePaths           []string
+	labelPath            string
+	routePriority        map[string]int
+	treatmentWeightsPath string
+}
+
+// newBuiltinEnsembler creates the built-in ensembler from the fan in properties. If the built-in
+// ensembler is not configured, nil is returned.
+func newBuiltinEnsembler(properties json.RawMessage) (*builtinEnsembler, error) {
+	var cfg struct {
+		BuiltinEnsembler *builtinEnsemblerCfg `json:"builtin_ensembler"`
+	}
+	if err := json.Unmarshal(properties, &cfg); err != nil {
+		return nil, errors.Newf(errors.BadConfig, "Failed to parse built-in ensembler")
+	}
+	if cfg.BuiltinEnsembler == nil {
+		return nil, nil
+	}
+	ensemblerCfg := cfg.BuiltinEnsembler
+
+	switch ensemblerCfg.Strategy {
+	case WeightedAverageStrategy:
+		if len(ensemblerCfg.ValuePaths) == 0 {
+			return nil, errors.Newf(errors.BadConfig, "Value paths must be set for the weighted average ensembler")
+		}
+	case MajorityVoteStrategy:
+		if ensemblerCfg.LabelPath == "" {
+			return nil, errors.Newf(errors.BadConfig, "Label path must be set for the majority vote ensembler")
+		}
+	case FirstSuccessStrategy:
+	default:
+		return nil, errors.Newf(errors.BadConfig, "Unknown built-in ensembler strategy: %s", ensemblerCfg.Strategy)
+	}
+	if err := validateWeights(ensemblerCfg.Weights); err != nil {
+		return nil, err
+	}
+
+	routePriority := make(map[string]int, len(ensemblerCfg.RoutePriority))
+	for i, route := range ensemblerCfg.RoutePriority {
+		routePriority[route] = i
+	}
+	return &builtinEnsembler{
+		strategy:             ensemblerCfg.Strategy,
+		weights:              ensemblerCfg.Weights,
+		valuePaths:           ensemblerCfg.ValuePaths,
+		labelPath:            ensemblerCfg.LabelPath,
+		routePriority:        routePriority,
+		treatmentWeightsPath: ensemblerCfg.TreatmentWeightsPath,
+	}, nil
+}
+
+// Ensemble combines the successful route responses into a single response, using the configured strategy
+func (e *builtinEnsembler) Ensemble(combined *CombinedResponse) ([]byte, error) {
+	responses := e.prioritize(combined.RouteResponses)
+	if len(responses) == 0 {
+		return nil, errors.Newf(errors.BadResponse, "No successful route responses to ensemble")
+	}
+
+	switch e.strategy {
+	case WeightedAverageStrategy:
+		return e.weightedAverage(responses, e.getWeights(combined.Experiment))
+	case MajorityVoteStrategy:
+		return e.majorityVote(responses, e.getWeights(combined.Experiment))
+	default:
+		return responses[0].Data, nil
+	}
+}
+
+// prioritize returns the successful route responses, in the order of priority. The routes in the
+// configured priority come first, followed by the default route and then the others, by their IDs.
+func (e *builtinEnsembler) prioritize(responses []RouteResponse) []RouteResponse {
+	successful := []RouteResponse{}
+	for _, resp := range responses {
+		if resp.success {
+			successful = append(successful, resp)
+		}
+	}
+
+	rank := func(resp RouteResponse) int {
+		if priority, ok := e.routePriority[resp.Route]; ok {
+			return priority
+		}
+		if resp.IsDefault {
+			return len(e.routePriority)
+		}
+		return len(e.routePriority) + 1
+	}
+	sort.SliceStable(successful, func(i, j int) bool {
+		if rankI, rankJ := rank(successful[i]), rank(successful[j]); rankI != rankJ {
+			return rankI < rankJ
+		}
+		return successful[i].Route < successful[j].Route
+	})
+	return successful
+}
+
+// getWeights returns the route weights from the treatment config, if configured and available,
+// or the configured weights otherwise
+func (e *builtinEnsembler) getWeights(expResponse experiment.Response) map[string]float64 {
+	if e.treatmentWeightsPath == "" || expResponse.Error != "" || len(expResponse.Configuration) == 0 {
+		return e.weights
+	}
+
+	data, _, _, err := jsonparser.Get(expResponse.Configuration, strings.Split(e.treatmentWeightsPath, ".")...)
+	if err != nil {
+		return e.weights
+	}
+	var weights map[string]float64
+	if err = json.Unmarshal(data, &weights); err == nil {
+		err = validateWeights(weights)
+	}
+	if err != nil {
+		log.Glob().Warnf("Ignoring the invalid route weights in the treatment config: %s", err)
+		return e.weights
+	}
+	return weights
+}
+
+func (e *builtinEnsembler) weightedAverage(responses []RouteResponse, weights map[string]float64) ([]byte, error) {
+	// The response is built from a copy of the base response, with the values replaced by their averages
+	ensembled := append([]byte{}, responses[0].Data...)
+
+	for _, valuePath := range e.valuePaths {
+		keys := strings.Split(valuePath, ".")
+		var sum, totalWeight float64
+		for _, resp := range responses {
+			weight := routeWeight(weights, resp.Route)
+			if weight == 0 {
+				continue
+			}
+			value, err := jsonparser.GetFloat(resp.Data, keys...)
+			if err != nil {
+				continue
+			}
+			sum += weight * value
+			totalWeight += weight
+		}
+		if totalWeight == 0 {
+			return nil, errors.Newf(errors.BadResponse, "No route responses have a numeric value at %s", valuePath)
+		}
+
+		average := strconv.FormatFloat(sum/totalWeight, 'f', -1, 64)
+		var err error
+		ensembled, err = jsonparser.Set(ensembled, []byte(average), keys...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to set the average value at %s", valuePath)
+		}
+	}
+	return ensembled, nil
+}
+
+func (e *builtinEnsembler) majorityVote(responses []RouteResponse, weights map[string]float64) ([]byte, error) {
+	keys := strings.Split(e.labelPath, ".")
+	votes := map[string]float64{}
+	// The labels, in the order of the highest priority route that voted for them, which breaks the ties
+	var labels []string
+	labelResponses := map[string][]byte{}
+
+	for _, resp := range responses {
+		weight := routeWeight(weights, resp.Route)
+		if weight == 0 {
+			continue
+		}
+		value, dataType, _, err := jsonparser.Get(resp.Data, keys...)
+		if err != nil || dataType == jsonparser.Object || dataType == jsonparser.Array {
+			continue
+		}
+
+		label := string(value)
+		if _, ok := votes[label]; !ok {
+			labels = append(labels, label)
+			labelResponses[label] = resp.Data
+		}
+		votes[label] += weight
+	}
+	if len(labels) == 0 {
+		return nil, errors.Newf(errors.BadResponse, "No route responses have a label at %s", e.labelPath)
+	}
+
+	winner := labels[0]
+	for _, label := range labels[1:] {
+		if votes[label] > votes[winner] {
+			winner = label
+		}
+	}
+	return labelResponses[winner], nil
+}
+
+// routeWeight returns the weight of the given route. If no weights are set, all routes have the same weight.
+func routeWeight(weights map[string]float64, route string) float64 {
+	if len(weights) == 0 {
+		return 1
+	}
+	return weights[route]
+}
+
+func validateWeights(weights map[string]float64) error {
+	for route, weight := range weights {
+		if weight < 0 {
+			return errors.Newf(errors.BadConfig, "Weight of route %s must not be negative", route)
+		}
+	}
+	return nil
+}
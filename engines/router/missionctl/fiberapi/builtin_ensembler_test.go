@@ -0,0 +1,229 @@
+package fiberapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/experiment"
+)
+
+func TestNewBuiltinEnsembler(t *testing.T) {
+	tests := map[string]struct {
+		properties json.RawMessage
+		expected   *builtinEnsembler
+		err        string
+	}{
+		"success | not configured": {
+			properties: json.RawMessage(`{"default_route_id": "route-a"}`),
+		},
+		"success | weighted average": {
+			properties: json.RawMessage(`{
+				"builtin_ensembler": {
+					"strategy": "weighted_average",
+					"weights": {"route-a": 0.7, "route-b": 0.3},
+					"value_paths": ["score"],
+					"route_priority": ["route-b", "route-a"],
+					"treatment_weights_path": "weights"
+				}
+			}`),
+			expected: &builtinEnsembler{
+				strategy:             WeightedAverageStrategy,
+				weights:              map[string]float64{"route-a": 0.7, "route-b": 0.3},
+				valuePaths:           []string{"score"},
+				routePriority:        map[string]int{"route-b": 0, "route-a": 1},
+				treatmentWeightsPath: "weights",
+			},
+		},
+		"success | first success": {
+			properties: json.RawMessage(`{"builtin_ensembler": {"strategy": "first_success"}}`),
+			expected: &builtinEnsembler{
+				strategy:      FirstSuccessStrategy,
+				routePriority: map[string]int{},
+			},
+		},
+		"failure | invalid properties": {
+			properties: json.RawMessage(`{"builtin_ensembler": "majority_vote"}`),
+			err:        "Failed to parse built-in ensembler",
+		},
+		"failure | unknown strategy": {
+			properties: json.RawMessage(`{"builtin_ensembler": {"strategy": "median"}}`),
+			err:        "Unknown built-in ensembler strategy: median",
+		},
+		"failure | missing value paths": {
+			properties: json.RawMessage(`{"builtin_ensembler": {"strategy": "weighted_average"}}`),
+			err:        "Value paths must be set for the weighted average ensembler",
+		},
+		"failure | missing label path": {
+			properties: json.RawMessage(`{"builtin_ensembler": {"strategy": "majority_vote"}}`),
+			err:        "Label path must be set for the majority vote ensembler",
+		},
+		"failure | negative weight": {
+			properties: json.RawMessage(`{
+				"builtin_ensembler": {"strategy": "first_success", "weights": {"route-a": -1}}
+			}`),
+			err: "Weight of route route-a must not be negative",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ensembler, err := newBuiltinEnsembler(tt.properties)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, ensembler)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestBuiltinEnsemblerEnsemble(t *testing.T) {
+	responses := []RouteResponse{
+		{
+			Route:   "route-a",
+			Data:    json.RawMessage(`{"score": 0.5, "label": "cat", "meta": {"count": 10}}`),
+			success: true,
+		},
+		{
+			Route:     "route-b",
+			Data:      json.RawMessage(`{"score": 0.75, "label": "dog", "meta": {"count": 20}}`),
+			IsDefault: true,
+			success:   true,
+		},
+		{
+			Route:   "route-c",
+			Data:    json.RawMessage(`{"score": 0.25, "label": "dog", "meta": {"count": 30}}`),
+			success: true,
+		},
+		{
+			Route: "route-d",
+			Data:  json.RawMessage(`{"code": 500, "error": "internal error"}`),
+		},
+	}
+
+	tests := map[string]struct {
+		ensembler  *builtinEnsembler
+		responses  []RouteResponse
+		experiment experiment.Response
+		expected   string
+		err        string
+	}{
+		"weighted average | equal weights": {
+			ensembler: &builtinEnsembler{
+				strategy:   WeightedAverageStrategy,
+				valuePaths: []string{"score", "meta.count"},
+			},
+			responses: responses,
+			// The default route's response is the base of the ensembled response
+			expected: `{"score": 0.5, "label": "dog", "meta": {"count": 20}}`,
+		},
+		"weighted average | configured weights": {
+			ensembler: &builtinEnsembler{
+				strategy:      WeightedAverageStrategy,
+				weights:       map[string]float64{"route-a": 3, "route-b": 1},
+				valuePaths:    []string{"score"},
+				routePriority: map[string]int{"route-a": 0},
+			},
+			responses: responses,
+			expected:  `{"score": 0.5625, "label": "cat", "meta": {"count": 10}}`,
+		},
+		"weighted average | treatment weights": {
+			ensembler: &builtinEnsembler{
+				strategy:             WeightedAverageStrategy,
+				weights:              map[string]float64{"route-a": 3, "route-b": 1},
+				valuePaths:           []string{"score"},
+				treatmentWeightsPath: "ensembler.weights",
+			},
+			responses: responses,
+			experiment: experiment.Response{
+				Configuration: json.RawMessage(`{"ensembler": {"weights": {"route-b": 1, "route-c": 1}}}`),
+			},
+			expected: `{"score": 0.5, "label": "dog", "meta": {"count": 20}}`,
+		},
+		"weighted average | invalid treatment weights": {
+			ensembler: &builtinEnsembler{
+				strategy:             WeightedAverageStrategy,
+				weights:              map[string]float64{"route-a": 3, "route-b": 1},
+				valuePaths:           []string{"score"},
+				treatmentWeightsPath: "weights",
+			},
+			responses: responses,
+			experiment: experiment.Response{
+				Configuration: json.RawMessage(`{"weights": {"route-b": -1}}`),
+			},
+			expected: `{"score": 0.5625, "label": "dog", "meta": {"count": 20}}`,
+		},
+		"weighted average | missing values": {
+			ensembler: &builtinEnsembler{
+				strategy:   WeightedAverageStrategy,
+				valuePaths: []string{"probability"},
+			},
+			responses: responses,
+			err:       "No route responses have a numeric value at probability",
+		},
+		"majority vote": {
+			ensembler: &builtinEnsembler{
+				strategy:      MajorityVoteStrategy,
+				labelPath:     "label",
+				routePriority: map[string]int{"route-c": 0},
+			},
+			responses: responses,
+			// The response of the highest priority route that voted for the label is returned
+			expected: `{"score": 0.25, "label": "dog", "meta": {"count": 30}}`,
+		},
+		"majority vote | weighted": {
+			ensembler: &builtinEnsembler{
+				strategy:  MajorityVoteStrategy,
+				weights:   map[string]float64{"route-a": 3, "route-b": 1, "route-c": 1},
+				labelPath: "label",
+			},
+			responses: responses,
+			expected:  `{"score": 0.5, "label": "cat", "meta": {"count": 10}}`,
+		},
+		"majority vote | tie is broken by priority": {
+			ensembler: &builtinEnsembler{
+				strategy:      MajorityVoteStrategy,
+				labelPath:     "label",
+				routePriority: map[string]int{"route-a": 0},
+			},
+			responses: responses[:2],
+			expected:  `{"score": 0.5, "label": "cat", "meta": {"count": 10}}`,
+		},
+		"first success | default route": {
+			ensembler: &builtinEnsembler{strategy: FirstSuccessStrategy},
+			responses: responses,
+			expected:  `{"score": 0.75, "label": "dog", "meta": {"count": 20}}`,
+		},
+		"first success | skips failed routes": {
+			ensembler: &builtinEnsembler{
+				strategy:      FirstSuccessStrategy,
+				routePriority: map[string]int{"route-d": 0, "route-c": 1},
+			},
+			responses: responses,
+			expected:  `{"score": 0.25, "label": "dog", "meta": {"count": 30}}`,
+		},
+		"failure | no successful responses": {
+			ensembler: &builtinEnsembler{strategy: FirstSuccessStrategy},
+			responses: responses[3:],
+			err:       "No successful route responses to ensemble",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ensembled, err := tt.ensembler.Ensemble(&CombinedResponse{
+				RouteResponses: tt.responses,
+				Experiment:     tt.experiment,
+			})
+			if tt.err == "" {
+				assert.NoError(t, err)
+				assert.JSONEq(t, tt.expected, string(ensembled))
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
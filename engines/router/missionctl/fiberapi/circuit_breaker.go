@@ -0,0 +1,130 @@
+package fiberapi
+
+import (
+	"sync"
+	"time"
+
+	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
+
+	"github.com/caraml-dev/turing/engines/router"
+	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+)
+
+// circuitState is the state of a circuit breaker. The values are exported as the
+// circuit breaker state metric.
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitHalfOpen
+	circuitOpen
+)
+
+// circuitBreaker tracks the failures of a route and stops sending the requests to it, when
+// the number of consecutive failures reaches the configured threshold
+type circuitBreaker struct {
+	routeID string
+	cfg     router.CircuitBreakerConfig
+
+	mu    sync.Mutex
+	state circuitState
+	// failures is the number of consecutive failures, while the circuit is closed
+	failures int
+	// openedAt is the time at which the circuit was last opened
+	openedAt time.Time
+	// probes is the number of probe requests sent, while the circuit is half-open
+	probes int
+	// successes is the number of successful probe requests, while the circuit is half-open
+	successes int
+
+	// now is used to get the current time, overridden in tests
+	now func() time.Time
+}
+
+func newCircuitBreaker(routeID string, cfg router.CircuitBreakerConfig) *circuitBreaker {
+	cb := &circuitBreaker{
+		routeID: routeID,
+		cfg:     cfg,
+		now:     time.Now,
+	}
+	cb.recordState()
+	return cb
+}
+
+// IsOpen returns whether the requests to the route are currently being rejected
+func (cb *circuitBreaker) IsOpen() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.currentState() == circuitOpen
+}
+
+// Allow returns whether a request can be sent to the route. Every allowed request
+// must be followed by a call to Record, with its outcome.
+func (cb *circuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.currentState() {
+	case circuitOpen:
+		return false
+	case circuitHalfOpen:
+		if cb.probes >= cb.cfg.HalfOpenProbes {
+			return false
+		}
+		cb.probes++
+	}
+	return true
+}
+
+// Record records the outcome of a request sent to the route
+func (cb *circuitBreaker) Record(success bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.currentState() {
+	case circuitClosed:
+		if success {
+			cb.failures = 0
+		} else if cb.failures++; cb.failures >= cb.cfg.FailureThreshold {
+			cb.setState(circuitOpen)
+		}
+	case circuitHalfOpen:
+		if !success {
+			cb.setState(circuitOpen)
+		} else if cb.successes++; cb.successes >= cb.cfg.HalfOpenProbes {
+			cb.setState(circuitClosed)
+		}
+	}
+}
+
+// currentState returns the state of the circuit, moving it to half-open once the open interval
+// has elapsed. It should be called with the lock held.
+func (cb *circuitBreaker) currentState() circuitState {
+	if cb.state == circuitOpen && cb.now().Sub(cb.openedAt) >= time.Duration(cb.cfg.OpenInterval) {
+		cb.setState(circuitHalfOpen)
+	}
+	return cb.state
+}
+
+// setState moves the circuit to the given state and resets its counters. It should be called
+// with the lock held.
+func (cb *circuitBreaker) setState(state circuitState) {
+	cb.state = state
+	cb.failures, cb.probes, cb.successes = 0, 0, 0
+	if state == circuitOpen {
+		cb.openedAt = cb.now()
+		log.Glob().Warnf("Circuit breaker of route %s is open", cb.routeID)
+	}
+	cb.recordState()
+}
+
+func (cb *circuitBreaker) recordState() {
+	if err := metrics.Glob().RecordGauge(
+		instrumentation.RouteCircuitBreakerState,
+		float64(cb.state),
+		map[string]string{"route": cb.routeID},
+	); err != nil {
+		log.Glob().Errorf("Failed to record the circuit breaker state of route %s: %s", cb.routeID, err.Error())
+	}
+}
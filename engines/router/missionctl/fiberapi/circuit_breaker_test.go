@@ -0,0 +1,52 @@
+package fiberapi
+
+import (
+	"testing"
+	"time"
+
+	fiberConfig "github.com/gojek/fiber/config"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/caraml-dev/turing/engines/router"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	cb := newCircuitBreaker("route-a", router.CircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenInterval:     fiberConfig.Duration(time.Minute),
+		HalfOpenProbes:   2,
+	})
+	cb.now = func() time.Time { return now }
+
+	// Consecutive failures open the circuit
+	assert.True(t, cb.Allow())
+	cb.Record(false)
+	assert.True(t, cb.Allow())
+	cb.Record(true)
+	assert.True(t, cb.Allow())
+	cb.Record(false)
+	assert.False(t, cb.IsOpen())
+	assert.True(t, cb.Allow())
+	cb.Record(false)
+	assert.True(t, cb.IsOpen())
+	assert.False(t, cb.Allow())
+
+	// The circuit is half-open after the open interval, and a failed probe opens it again
+	now = now.Add(time.Minute)
+	assert.False(t, cb.IsOpen())
+	assert.True(t, cb.Allow())
+	cb.Record(false)
+	assert.True(t, cb.IsOpen())
+
+	// Only the configured number of probes are allowed, and their success closes the circuit
+	now = now.Add(time.Minute)
+	assert.True(t, cb.Allow())
+	assert.True(t, cb.Allow())
+	assert.False(t, cb.Allow())
+	cb.Record(true)
+	assert.Equal(t, circuitHalfOpen, cb.state)
+	cb.Record(true)
+	assert.Equal(t, circuitClosed, cb.state)
+	assert.True(t, cb.Allow())
+}
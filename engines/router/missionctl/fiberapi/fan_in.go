@@ -27,10 +27,12 @@ import (
 const FanInID = "fan_in"
 
 // EnsemblingFanIn combines the results from the fanout with the experiment parameters
-// and forwards to the configured ensembling endpoint
+// and forwards to the configured ensembling endpoint, or ensembles them with the
+// built-in ensembler, if configured
 type EnsemblingFanIn struct {
 	*experimentationPolicy
 	*routeSelectionPolicy
+	*builtinEnsembler
 }
 
 // Initialize is invoked by the Fiber library to initialize a new FanIn.
@@ -45,6 +47,10 @@ func (fanIn *EnsemblingFanIn) Initialize(properties json.RawMessage) error {
 	if err != nil {
 		return errors.Wrapf(err, "Failed initializing route selection policy on FanIn")
 	}
+	fanIn.builtinEnsembler, err = newBuiltinEnsembler(properties)
+	if err != nil {
+		return errors.Wrapf(err, "Failed initializing built-in ensembler on FanIn")
+	}
 	return nil
 }
 
@@ -76,7 +82,7 @@ func (fanIn *EnsemblingFanIn) Aggregate(
 		}
 
 		expPlan, expPlanErr := fanIn.experimentEngine.
-			GetTreatmentForRequest(req.Header(), req.Payload(), options)
+			GetTreatmentForRequestWithContext(ctx, req.Header(), req.Payload(), options)
 		// Write to channel
 		expRespCh <- experiment.NewResponse(expPlan, expPlanErr)
 		close(expRespCh)
@@ -142,13 +148,19 @@ func (fanIn *EnsemblingFanIn) collectResponses(
 			Route:     k,
 			Data:      v.Payload(),
 			IsDefault: k == fanIn.defaultRoute,
+			success:   v.IsSuccess(),
 		}
 		result.RouteResponses[idx] = t
 		idx++
 	}
 
-	// Marshal the response, measure time
+	// Ensemble the responses with the built-in ensembler, or marshal them for the ensembler, measure time
+	var rBytes []byte
 	var err error
+	component := "fanin_marshalResponse"
+	if fanIn.builtinEnsembler != nil {
+		component = "fanin_builtinEnsemble"
+	}
 	timer := metrics.Glob().MeasureDurationMs(
 		instrumentation.TuringComponentRequestDurationMs,
 		map[string]func() string{
@@ -156,12 +168,16 @@ func (fanIn *EnsemblingFanIn) collectResponses(
 				return metrics.GetStatusString(err == nil)
 			},
 			"component": func() string {
-				return "fanin_marshalResponse"
+				return component
 			},
 			"traffic_rule": func() string { return "" },
 		},
 	)
-	rBytes, err := jsoniter.Marshal(result)
+	if fanIn.builtinEnsembler != nil {
+		rBytes, err = fanIn.builtinEnsembler.Ensemble(&result)
+	} else {
+		rBytes, err = jsoniter.Marshal(result)
+	}
 	timer()
 	if err != nil {
 		return fiber.NewErrorResponse(err)
@@ -183,6 +199,8 @@ type RouteResponse struct {
 	Route     string          `json:"route"`
 	Data      json.RawMessage `json:"data"`
 	IsDefault bool            `json:"is_default"`
+	// success is used by the built-in ensembler, and is not sent to the ensembler
+	success bool
 }
 
 // CombinedResponse captures the structure of the final response sent back by the fan in
@@ -46,6 +46,7 @@ var efi = &EnsemblingFanIn{
 	&routeSelectionPolicy{
 		defaultRoute: "control",
 	},
+	nil,
 }
 var efiExpTimeout = &EnsemblingFanIn{
 	&experimentationPolicy{
@@ -57,6 +58,7 @@ var efiExpTimeout = &EnsemblingFanIn{
 	&routeSelectionPolicy{
 		defaultRoute: "control",
 	},
+	nil,
 }
 
 func TestInitializeEnsemblingFanIn(t *testing.T) {
@@ -86,6 +88,33 @@ func TestInitializeEnsemblingFanIn(t *testing.T) {
 				experimentationPolicy: &experimentationPolicy{},
 			},
 		},
+		"success | built-in ensembler": {
+			properties: json.RawMessage(`{
+				"default_route_id":  "route1",
+				"experiment_engine": "Test",
+				"builtin_ensembler": {"strategy": "first_success", "route_priority": ["route2"]}
+			}`),
+			success: true,
+			expected: EnsemblingFanIn{
+				routeSelectionPolicy: &routeSelectionPolicy{
+					defaultRoute: "route1",
+				},
+				experimentationPolicy: &experimentationPolicy{},
+				builtinEnsembler: &builtinEnsembler{
+					strategy:      FirstSuccessStrategy,
+					routePriority: map[string]int{"route2": 0},
+				},
+			},
+		},
+		"invalid built-in ensembler": {
+			properties: json.RawMessage(`{
+				"experiment_engine": "Test",
+				"builtin_ensembler": {"strategy": "majority_vote"}
+			}`),
+			success: false,
+			err: "Failed initializing built-in ensembler on FanIn: " +
+				"Label path must be set for the majority vote ensembler",
+		},
 		"missing experimentation policy": {
 			properties: json.RawMessage(`invalid_data`),
 			success:    false,
@@ -108,7 +137,9 @@ func TestInitializeEnsemblingFanIn(t *testing.T) {
 			)
 			monkey.Patch(
 				experiment.NewExperimentRunner,
-				func(_ string, _ map[string]interface{}, _ int) (runner.ExperimentRunner, error) {
+				func(
+					_ string, _ map[string]interface{}, _ int, _ *runner.TreatmentCacheConfig,
+				) (runner.ContextExperimentRunner, error) {
 					return nil, nil
 				},
 			)
@@ -220,6 +251,29 @@ func TestEnsemblingFanInAggregate(t *testing.T) {
 	}
 }
 
+func TestEnsemblingFanInAggregateBuiltinEnsembler(t *testing.T) {
+	fanIn := &EnsemblingFanIn{
+		efi.experimentationPolicy,
+		efi.routeSelectionPolicy,
+		&builtinEnsembler{
+			strategy:      FirstSuccessStrategy,
+			routePriority: map[string]int{"treatment-B": 0},
+		},
+	}
+	respQueue := makeTestResponseQueue("treatment-A", "treatment-B")
+	req := tu.MakeTestRequest(t, tu.NopHTTPRequestModifier)
+	fiberReq, err := fiberHttp.NewHTTPRequest(req)
+	tu.FailOnError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp := fanIn.Aggregate(ctx, fiberReq, respQueue)
+
+	// The ensembled response is returned in place of the combined response
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.JSONEq(t, `{"value": "treatment-B"}`, string(resp.Payload()))
+}
+
 func makeTestResponseQueue(endpointNames ...string) fiber.ResponseQueue {
 	// Make response channel
 	ch := make(chan fiber.Response, len(endpointNames))
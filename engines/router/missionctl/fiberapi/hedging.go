@@ -0,0 +1,139 @@
+package fiberapi
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
+	"github.com/gojek/fiber"
+
+	"github.com/caraml-dev/turing/engines/router"
+	"github.com/caraml-dev/turing/engines/router/missionctl/hedging"
+	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+)
+
+// hedger sends a duplicate request to a route, if no response is received within the hedging delay,
+// and uses the response that arrives first. The number of duplicate requests in progress is capped,
+// as a percentage of the requests to the route in progress.
+type hedger struct {
+	routeID string
+	cfg     router.HedgingPolicy
+
+	mu sync.Mutex
+	// inFlight is the number of requests to the route in progress, excluding the duplicate requests
+	inFlight int
+	// hedges is the number of duplicate requests to the route in progress
+	hedges int
+}
+
+func newHedger(routeID string, cfg router.HedgingPolicy) *hedger {
+	return &hedger{
+		routeID: routeID,
+		cfg:     cfg,
+	}
+}
+
+// hedgedResponse is the response from a copy of the hedged request
+type hedgedResponse struct {
+	copy hedging.Copy
+	resp fiber.Response
+}
+
+// Dispatch calls the route using the given function, which is called again with the duplicate
+// request, if no response is received within the hedging delay. The first successful response
+// is returned, or the last failed one, if both copies of the request fail. The copy of the request
+// that is still in progress, if any, is cancelled.
+func (h *hedger) Dispatch(ctx context.Context, dispatch func(context.Context) fiber.Response) fiber.Response {
+	h.begin()
+	defer h.end()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Buffered, so that the copy of the request that is not used doesn't block
+	results := make(chan hedgedResponse, 2)
+	go func() {
+		results <- hedgedResponse{copy: hedging.OriginalCopy, resp: dispatch(ctx)}
+	}()
+
+	timer := time.NewTimer(time.Duration(h.cfg.Delay))
+	defer timer.Stop()
+
+	pending, hedged := 1, false
+	for {
+		select {
+		case <-timer.C:
+			if !h.acquireHedge() {
+				log.WithContext(ctx).Debugf("Hedged requests to route %s are at capacity, not hedging", h.routeID)
+				continue
+			}
+			hedged = true
+			pending++
+			go func() {
+				defer h.releaseHedge()
+				results <- hedgedResponse{copy: hedging.HedgeCopy, resp: dispatch(ctx)}
+			}()
+		case result := <-results:
+			pending--
+			// Wait for the other copy of the request, if the first response is a failure
+			if !result.resp.IsSuccess() && pending > 0 {
+				continue
+			}
+			if hedged {
+				h.record(ctx, result.copy)
+			}
+			return result.resp
+		}
+	}
+}
+
+// begin registers a request to the route that is about to be dispatched
+func (h *hedger) begin() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.inFlight++
+}
+
+// end unregisters a request to the route, that has completed
+func (h *hedger) end() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.inFlight--
+}
+
+// acquireHedge returns whether a duplicate request can be sent to the route, and registers it if so.
+// The number of hedges is rounded down, so that no request is hedged until there are enough requests
+// to the route in progress for the cap to allow it.
+func (h *hedger) acquireHedge() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	maxHedges := int(math.Floor(float64(h.inFlight) * h.cfg.MaxHedgePercent / 100))
+	if h.hedges >= maxHedges {
+		return false
+	}
+	h.hedges++
+	return true
+}
+
+// releaseHedge unregisters a duplicate request to the route, that has completed
+func (h *hedger) releaseHedge() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hedges--
+}
+
+// record saves the outcome of the hedged request, to be logged together with the request
+func (h *hedger) record(ctx context.Context, winner hedging.Copy) {
+	if collector, err := hedging.GetCollector(ctx); err == nil {
+		collector.Record(&hedging.Record{RouteID: h.routeID, Winner: winner})
+	}
+	if err := metrics.Glob().Inc(
+		instrumentation.RouteHedgedRequestsTotal,
+		map[string]string{"route": h.routeID, "winner": string(winner)},
+	); err != nil {
+		log.Glob().Errorf("Failed to record the hedged request to route %s: %s", h.routeID, err.Error())
+	}
+}
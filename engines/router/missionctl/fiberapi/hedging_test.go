@@ -0,0 +1,185 @@
+package fiberapi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	fiberConfig "github.com/gojek/fiber/config"
+	fiberHttp "github.com/gojek/fiber/http"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/caraml-dev/turing/engines/router"
+	"github.com/caraml-dev/turing/engines/router/missionctl/hedging"
+)
+
+// delayedCall is the status code of the response to a call to the route, and the delay before it's received
+type delayedCall struct {
+	delay  time.Duration
+	status int
+}
+
+// delayedDispatcher responds to each call to the route after the configured delay. The calls that are
+// cancelled before the delay elapses receive a 499 response.
+type delayedDispatcher struct {
+	mu         sync.Mutex
+	calls      []delayedCall
+	dispatches int
+	completed  int
+	cancelled  int
+}
+
+func (d *delayedDispatcher) dispatch(ctx context.Context) fiber.Response {
+	d.mu.Lock()
+	call := d.calls[d.dispatches]
+	d.dispatches++
+	d.mu.Unlock()
+
+	status := call.status
+	select {
+	case <-time.After(call.delay):
+	case <-ctx.Done():
+		d.mu.Lock()
+		d.cancelled++
+		d.mu.Unlock()
+		status = 499
+	}
+	d.mu.Lock()
+	d.completed++
+	d.mu.Unlock()
+	return fiberHttp.NewHTTPResponse(&http.Response{StatusCode: status, Body: http.NoBody})
+}
+
+func TestHedgerDispatch(t *testing.T) {
+	tests := map[string]struct {
+		calls             []delayedCall
+		expectedStatus    int
+		expectedCalls     int
+		expectedCancelled int
+		expectedRecords   []*hedging.Record
+	}{
+		"success | response before the delay": {
+			calls:           []delayedCall{{delay: 0, status: http.StatusOK}},
+			expectedStatus:  http.StatusOK,
+			expectedCalls:   1,
+			expectedRecords: []*hedging.Record{},
+		},
+		"failure | response before the delay": {
+			calls:           []delayedCall{{delay: 0, status: http.StatusInternalServerError}},
+			expectedStatus:  http.StatusInternalServerError,
+			expectedCalls:   1,
+			expectedRecords: []*hedging.Record{},
+		},
+		"success | hedge wins": {
+			calls: []delayedCall{
+				{delay: time.Second, status: http.StatusOK},
+				{delay: 0, status: http.StatusCreated},
+			},
+			expectedStatus:    http.StatusCreated,
+			expectedCalls:     2,
+			expectedCancelled: 1,
+			expectedRecords:   []*hedging.Record{{RouteID: "route-a", Winner: hedging.HedgeCopy}},
+		},
+		"success | original wins": {
+			calls: []delayedCall{
+				{delay: 50 * time.Millisecond, status: http.StatusOK},
+				{delay: time.Second, status: http.StatusCreated},
+			},
+			expectedStatus:    http.StatusOK,
+			expectedCalls:     2,
+			expectedCancelled: 1,
+			expectedRecords:   []*hedging.Record{{RouteID: "route-a", Winner: hedging.OriginalCopy}},
+		},
+		"success | original fails first": {
+			calls: []delayedCall{
+				{delay: 20 * time.Millisecond, status: http.StatusInternalServerError},
+				{delay: 50 * time.Millisecond, status: http.StatusCreated},
+			},
+			expectedStatus:  http.StatusCreated,
+			expectedCalls:   2,
+			expectedRecords: []*hedging.Record{{RouteID: "route-a", Winner: hedging.HedgeCopy}},
+		},
+		"failure | both copies fail": {
+			calls: []delayedCall{
+				{delay: 20 * time.Millisecond, status: http.StatusInternalServerError},
+				{delay: 50 * time.Millisecond, status: http.StatusServiceUnavailable},
+			},
+			expectedStatus:  http.StatusServiceUnavailable,
+			expectedCalls:   2,
+			expectedRecords: []*hedging.Record{{RouteID: "route-a", Winner: hedging.HedgeCopy}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := newHedger("route-a", router.HedgingPolicy{
+				Delay:           fiberConfig.Duration(10 * time.Millisecond),
+				MaxHedgePercent: 100,
+			})
+			dispatcher := &delayedDispatcher{calls: tt.calls}
+			collector := hedging.NewCollector()
+
+			resp := h.Dispatch(hedging.WithCollector(context.Background(), collector), dispatcher.dispatch)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode())
+
+			// Wait for the cancelled copy of the request, if any, to complete
+			assert.Eventually(t, func() bool {
+				dispatcher.mu.Lock()
+				defer dispatcher.mu.Unlock()
+				return dispatcher.completed == tt.expectedCalls
+			}, time.Second, time.Millisecond)
+			dispatcher.mu.Lock()
+			defer dispatcher.mu.Unlock()
+			assert.Equal(t, tt.expectedCalls, dispatcher.dispatches)
+			assert.Equal(t, tt.expectedCancelled, dispatcher.cancelled)
+			assert.Equal(t, tt.expectedRecords, collector.Records())
+		})
+	}
+}
+
+func TestHedgerCapsConcurrentHedges(t *testing.T) {
+	h := newHedger("route-a", router.HedgingPolicy{
+		Delay:           fiberConfig.Duration(10 * time.Millisecond),
+		MaxHedgePercent: 50,
+	})
+
+	// No hedge is allowed, until 50% of the requests in progress make up a whole request
+	h.begin()
+	assert.False(t, h.acquireHedge())
+
+	// Up to 50% of the requests in progress can be hedged
+	h.begin()
+	assert.True(t, h.acquireHedge())
+	assert.False(t, h.acquireHedge())
+	h.begin()
+	h.begin()
+	assert.True(t, h.acquireHedge())
+	assert.False(t, h.acquireHedge())
+
+	// Hedges are allowed again, once the hedges in progress complete
+	h.releaseHedge()
+	assert.True(t, h.acquireHedge())
+}
+
+func TestHedgerDoesNotHedgeBelowCap(t *testing.T) {
+	h := newHedger("route-a", router.HedgingPolicy{
+		Delay:           fiberConfig.Duration(10 * time.Millisecond),
+		MaxHedgePercent: 10,
+	})
+	dispatcher := &delayedDispatcher{calls: []delayedCall{
+		{delay: 50 * time.Millisecond, status: http.StatusOK},
+		{delay: 0, status: http.StatusCreated},
+	}}
+	collector := hedging.NewCollector()
+
+	// A single request in progress is not hedged, as 10% of it is less than a whole request
+	resp := h.Dispatch(hedging.WithCollector(context.Background(), collector), dispatcher.dispatch)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	dispatcher.mu.Lock()
+	defer dispatcher.mu.Unlock()
+	assert.Equal(t, 1, dispatcher.dispatches)
+	assert.Empty(t, collector.Records())
+}
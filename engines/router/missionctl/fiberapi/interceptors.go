@@ -8,6 +8,7 @@ import (
 	"github.com/gojek/fiber"
 	"github.com/opentracing/opentracing-go"
 
+	"github.com/caraml-dev/turing/engines/router/missionctl/debug"
 	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
 
 	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation/tracing"
@@ -21,6 +22,8 @@ type ctxKey string
 
 const (
 	startTimeKey ctxKey = "startTimeKey"
+	// debugStartTimeKey is the start time of a call to a route, that is recorded in the debug trace
+	debugStartTimeKey ctxKey = "debugStartTimeKey"
 )
 
 /////////////////////////// TimeLoggingInterceptor ////////////////////////////
@@ -210,3 +213,52 @@ func (i *TracingInterceptor) AfterCompletion(
 		span.Finish()
 	}
 }
+
+/////////////////////////// DebugTraceInterceptor /////////////////////////////
+
+// NewDebugTraceInterceptor is a creator for a DebugTraceInterceptor
+func NewDebugTraceInterceptor() fiber.Interceptor {
+	return &DebugTraceInterceptor{}
+}
+
+// DebugTraceInterceptor is the structural interceptor used for recording the responses
+// from the individual routes in the debug trace of the request, if the request is in debug mode
+type DebugTraceInterceptor struct {
+	*fiber.NoopAfterDispatchInterceptor
+}
+
+// BeforeDispatch registers the call to the route in the debug trace, if any,
+// and associates its start time to the context
+func (i *DebugTraceInterceptor) BeforeDispatch(
+	ctx context.Context,
+	_ fiber.Request,
+) context.Context {
+	if cKind, ok := ctx.Value(fiber.CtxComponentKindKey).(fiber.ComponentKind); !ok || cKind != fiber.CallerKind {
+		return ctx
+	}
+	if trace, err := debug.GetTrace(ctx); err == nil && trace.BeginRoute() {
+		ctx = context.WithValue(ctx, debugStartTimeKey, time.Now())
+	}
+	return ctx
+}
+
+// AfterCompletion records the responses from the route and the time taken, in the debug trace
+func (i *DebugTraceInterceptor) AfterCompletion(
+	ctx context.Context,
+	_ fiber.Request,
+	queue fiber.ResponseQueue,
+) {
+	// The start time is only set, if the call to the route was registered in the debug trace
+	startTime, ok := ctx.Value(debugStartTimeKey).(time.Time)
+	if !ok {
+		return
+	}
+	trace, _ := debug.GetTrace(ctx)
+	routeName, _ := ctx.Value(fiber.CtxComponentIDKey).(string)
+
+	responses := []fiber.Response{}
+	for resp := range queue.Iter() {
+		responses = append(responses, resp)
+	}
+	trace.RecordRoute(routeName, time.Since(startTime), responses...)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/debug"
 	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation/tracing"
 	tu "github.com/caraml-dev/turing/engines/router/missionctl/internal/testutils"
 	"github.com/caraml-dev/turing/engines/router/missionctl/log"
@@ -374,6 +375,45 @@ func TestTracingInterceptorAfterCompletion(t *testing.T) {
 	mockSp.AssertCalled(t, "Finish")
 }
 
+func TestDebugTraceInterceptor(t *testing.T) {
+	tests := map[string]struct {
+		kind           fiber.ComponentKind
+		expectedRoutes []*debug.RouteCall
+	}{
+		"caller": {
+			kind: fiber.CallerKind,
+			expectedRoutes: []*debug.RouteCall{
+				{RouteID: "test_ComponentID", Status: http.StatusOK, Response: json.RawMessage(`"Test Body"`)},
+			},
+		},
+		"combiner": {
+			kind:           fiber.CombinerKind,
+			expectedRoutes: []*debug.RouteCall{},
+		},
+	}
+	// Run tests
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			trace := debug.NewTrace("test-req-id", debug.EncodeJSONResponse)
+
+			// Make test ctx
+			ctx := debug.WithTrace(context.Background(), trace)
+			ctx = context.WithValue(ctx, fiber.CtxComponentIDKey, "test_ComponentID")
+			ctx = context.WithValue(ctx, fiber.CtxComponentKindKey, data.kind)
+
+			i := NewDebugTraceInterceptor()
+			ctx = i.BeforeDispatch(ctx, nil)
+			i.AfterCompletion(ctx, nil, createTestFiberResponseQueue(http.StatusOK))
+
+			info := trace.Wait()
+			for _, route := range info.Routes {
+				route.LatencyMs = 0
+			}
+			assert.Equal(t, data.expectedRoutes, info.Routes)
+		})
+	}
+}
+
 func createTestFiberResponseQueue(respStatus int) fiber.ResponseQueue {
 	testBody := []byte(`Test Body`)
 	httpResp := http.Response{
@@ -2,6 +2,7 @@
 package testutils
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"time"
@@ -27,18 +28,32 @@ type MockExperimentRunner struct {
 // is initialized with TestTreatment.
 // If MockExperimentRunner.WantErr is true, GetTreatmentForRequest will return error.
 func (mp MockExperimentRunner) GetTreatmentForRequest(
-	http.Header,
-	[]byte,
-	runner.GetTreatmentOptions,
+	header http.Header,
+	payload []byte,
+	options runner.GetTreatmentOptions,
+) (*runner.Treatment, error) {
+	return mp.GetTreatmentForRequestWithContext(context.Background(), header, payload, options)
+}
+
+// GetTreatmentForRequestWithContext behaves like GetTreatmentForRequest, except that
+// when MockExperimentRunner.WantTimeout is true, it returns early if the context is done.
+func (mp MockExperimentRunner) GetTreatmentForRequestWithContext(
+	ctx context.Context,
+	_ http.Header,
+	_ []byte,
+	_ runner.GetTreatmentOptions,
 ) (*runner.Treatment, error) {
 	if mp.WantTimeout {
-		time.Sleep(mp.Timeout)
-		return nil, errors.New("timeout reached")
+		select {
+		case <-time.After(mp.Timeout):
+			return nil, errors.New("timeout reached")
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
 	} else if mp.WantErr {
 		return nil, errors.New("failed to retrieve experiment treatment")
 	}
 	return mp.Treatment, nil
-
 }
 
 func (mp MockExperimentRunner) RegisterMetricsCollector(
@@ -0,0 +1,175 @@
+package fiberapi
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/gojek/fiber"
+	fiberErrors "github.com/gojek/fiber/errors"
+
+	"github.com/caraml-dev/turing/engines/router"
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+)
+
+// routePolicy holds the retry policy, the circuit breaker and the hedging policy of a route. The circuit
+// breaker and the hedging limits are shared by all the occurrences of the route in the router config.
+type routePolicy struct {
+	retry   *router.RetryPolicy
+	breaker *circuitBreaker
+	hedger  *hedger
+}
+
+// routePolicyConfig is used to parse the retry policies, the circuit breakers and the hedging policies
+// of the routes from the Fiber config, since they are ignored by Fiber itself
+type routePolicyConfig struct {
+	ID             string                       `json:"id"`
+	Routes         []routePolicyConfig          `json:"routes"`
+	RetryPolicy    *router.RetryPolicy          `json:"retry_policy"`
+	CircuitBreaker *router.CircuitBreakerConfig `json:"circuit_breaker"`
+	HedgingPolicy  *router.HedgingPolicy        `json:"hedging_policy"`
+}
+
+// loadRoutePolicies reads the Fiber config file and returns the policies of its routes, by route ID
+func loadRoutePolicies(cfgFilePath string) (map[string]*routePolicy, error) {
+	data, err := os.ReadFile(cfgFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return parseRoutePolicies(data)
+}
+
+// parseRoutePolicies parses the Fiber config and returns the policies of its routes, by route ID
+func parseRoutePolicies(data []byte) (map[string]*routePolicy, error) {
+	var cfg routePolicyConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse the route policies")
+	}
+	policies := map[string]*routePolicy{}
+	collectRoutePolicies(cfg, policies)
+	return policies, nil
+}
+
+func collectRoutePolicies(cfg routePolicyConfig, policies map[string]*routePolicy) {
+	_, exists := policies[cfg.ID]
+	if !exists && (cfg.RetryPolicy != nil || cfg.CircuitBreaker != nil || cfg.HedgingPolicy != nil) {
+		policy := &routePolicy{retry: cfg.RetryPolicy}
+		if cfg.CircuitBreaker != nil {
+			policy.breaker = newCircuitBreaker(cfg.ID, *cfg.CircuitBreaker)
+		}
+		if cfg.HedgingPolicy != nil {
+			policy.hedger = newHedger(cfg.ID, *cfg.HedgingPolicy)
+		}
+		policies[cfg.ID] = policy
+	}
+	for _, route := range cfg.Routes {
+		collectRoutePolicies(route, policies)
+	}
+}
+
+// applyRoutePolicies recursively replaces the routes of the given component, that have a retry
+// policy, a circuit breaker or a hedging policy, with a resilientRoute
+func applyRoutePolicies(component fiber.Component, policies map[string]*routePolicy) {
+	multiRoute, ok := component.(fiber.MultiRouteComponent)
+	if !ok || len(policies) == 0 {
+		return
+	}
+	routes := multiRoute.GetRoutes()
+	for routeID, route := range routes {
+		applyRoutePolicies(route, policies)
+		if policy, exists := policies[routeID]; exists && route.Kind() == fiber.CallerKind {
+			routes[routeID] = &resilientRoute{Component: route, routePolicy: policy}
+		}
+	}
+	multiRoute.SetRoutes(routes)
+}
+
+// resilientRoute wraps a route, retrying the failed requests according to its retry policy,
+// rejecting the requests immediately, while its circuit breaker is open, and hedging the slow
+// requests according to its hedging policy
+type resilientRoute struct {
+	fiber.Component
+	*routePolicy
+}
+
+// isCircuitOpen returns whether the circuit breaker of the given route, if any, is open
+func isCircuitOpen(route fiber.Component) bool {
+	if timed, ok := route.(*timedRoute); ok {
+		route = timed.Component
+	}
+	r, ok := route.(*resilientRoute)
+	return ok && r.breaker != nil && r.breaker.IsOpen()
+}
+
+// Dispatch dispatches the request to the wrapped route
+func (r *resilientRoute) Dispatch(ctx context.Context, req fiber.Request) fiber.ResponseQueue {
+	out := make(chan fiber.Response, 1)
+	go func() {
+		defer close(out)
+		out <- r.dispatch(ctx, req)
+	}()
+	return fiber.NewResponseQueue(out, 1)
+}
+
+func (r *resilientRoute) dispatch(ctx context.Context, req fiber.Request) fiber.Response {
+	maxAttempts, backoff := 1, time.Duration(0)
+	if r.retry != nil {
+		maxAttempts, backoff = r.retry.MaxAttempts, time.Duration(r.retry.Backoff)
+	}
+
+	var resp fiber.Response
+	for attempt := 1; ; attempt++ {
+		if r.breaker != nil && !r.breaker.Allow() {
+			err := createFiberError(
+				errors.Newf(errors.Unavailable, "circuit breaker of route %s is open", r.ID()),
+				req.Protocol(),
+			)
+			return fiber.NewErrorResponse(&err)
+		}
+
+		resp = r.dispatchAttempt(ctx, req)
+		if r.breaker != nil {
+			r.breaker.Record(resp.IsSuccess())
+		}
+		if resp.IsSuccess() || attempt >= maxAttempts || !r.retry.IsRetryable(resp.StatusCode()) {
+			return resp
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return resp
+		}
+	}
+}
+
+// dispatchAttempt dispatches the request to the wrapped route, hedging it if the route has a hedging policy
+func (r *resilientRoute) dispatchAttempt(ctx context.Context, req fiber.Request) fiber.Response {
+	if r.hedger == nil {
+		return r.dispatchOnce(ctx, req)
+	}
+	return r.hedger.Dispatch(ctx, func(ctx context.Context) fiber.Response {
+		return r.dispatchOnce(ctx, req)
+	})
+}
+
+// dispatchOnce dispatches a copy of the request to the wrapped route and returns its last response
+func (r *resilientRoute) dispatchOnce(ctx context.Context, req fiber.Request) fiber.Response {
+	copyReq, err := req.Clone()
+	if err != nil {
+		return fiber.NewErrorResponse(fiberErrors.NewFiberError(req.Protocol(), err))
+	}
+	// Read all the responses, keeping the first failed one, if any
+	var resp fiber.Response
+	for routeResp := range r.Component.Dispatch(ctx, copyReq).Iter() {
+		if resp == nil || resp.IsSuccess() {
+			resp = routeResp
+		}
+	}
+	if resp == nil {
+		return fiber.NewErrorResponse(fiberErrors.ErrNoValidResponseFromRoutes(req.Protocol()))
+	}
+	return resp
+}
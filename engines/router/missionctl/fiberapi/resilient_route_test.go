@@ -0,0 +1,244 @@
+package fiberapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	fiberConfig "github.com/gojek/fiber/config"
+	fiberHttp "github.com/gojek/fiber/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/caraml-dev/turing/engines/router"
+	"github.com/caraml-dev/turing/engines/router/missionctl/hedging"
+	tu "github.com/caraml-dev/turing/engines/router/missionctl/internal/testutils"
+)
+
+// statusDispatcher responds with the given status codes, one per request, repeating the last one
+type statusDispatcher struct {
+	mu       sync.Mutex
+	statuses []int
+	calls    int
+}
+
+func (d *statusDispatcher) Do(_ fiber.Request) fiber.Response {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	status := d.statuses[len(d.statuses)-1]
+	if d.calls < len(d.statuses) {
+		status = d.statuses[d.calls]
+	}
+	d.calls++
+	return fiberHttp.NewHTTPResponse(&http.Response{StatusCode: status, Body: http.NoBody})
+}
+
+func newTestResilientRoute(t *testing.T, dispatcher fiber.Dispatcher, policy *routePolicy) fiber.Component {
+	caller, err := fiber.NewCaller("route-a", dispatcher)
+	tu.FailOnError(t, err)
+	return &resilientRoute{
+		Component:   fiber.NewProxy(fiber.NewBackend("route-a", "http://localhost/predict"), caller),
+		routePolicy: policy,
+	}
+}
+
+func newTestFiberRequest(t *testing.T) fiber.Request {
+	req, err := fiberHttp.NewHTTPRequest(httptest.NewRequest(http.MethodPost, "/v1/predict", http.NoBody))
+	tu.FailOnError(t, err)
+	return req
+}
+
+func TestParseRoutePolicies(t *testing.T) {
+	cfg := `
+id: router
+type: LAZY_ROUTER
+routes:
+- id: rule-a
+  type: EAGER_ROUTER
+  routes:
+  - id: route-a
+    type: PROXY
+    endpoint: http://localhost/a
+    retry_policy:
+      max_attempts: 3
+      backoff: 10ms
+      retryable_status_codes: [503]
+    circuit_breaker:
+      failure_threshold: 5
+      open_interval: 30s
+      half_open_probes: 1
+  - id: route-b
+    type: PROXY
+    endpoint: http://localhost/b
+    hedging_policy:
+      delay: 50ms
+      max_hedge_percent: 10
+- id: route-c
+  type: PROXY
+  endpoint: http://localhost/c
+  retry_policy:
+    max_attempts: 2
+`
+	policies, err := parseRoutePolicies([]byte(cfg))
+	require.NoError(t, err)
+	require.Len(t, policies, 3)
+
+	assert.Equal(t, &router.RetryPolicy{
+		MaxAttempts:          3,
+		Backoff:              fiberConfig.Duration(10 * time.Millisecond),
+		RetryableStatusCodes: []int{503},
+	}, policies["route-a"].retry)
+	require.NotNil(t, policies["route-a"].breaker)
+	assert.Equal(t, router.CircuitBreakerConfig{
+		FailureThreshold: 5,
+		OpenInterval:     fiberConfig.Duration(30 * time.Second),
+		HalfOpenProbes:   1,
+	}, policies["route-a"].breaker.cfg)
+
+	assert.Nil(t, policies["route-a"].hedger)
+
+	assert.Nil(t, policies["route-b"].retry)
+	assert.Nil(t, policies["route-b"].breaker)
+	require.NotNil(t, policies["route-b"].hedger)
+	assert.Equal(t, router.HedgingPolicy{
+		Delay:           fiberConfig.Duration(50 * time.Millisecond),
+		MaxHedgePercent: 10,
+	}, policies["route-b"].hedger.cfg)
+
+	assert.Equal(t, &router.RetryPolicy{MaxAttempts: 2}, policies["route-c"].retry)
+	assert.Nil(t, policies["route-c"].breaker)
+	assert.Nil(t, policies["route-c"].hedger)
+}
+
+func TestApplyRoutePolicies(t *testing.T) {
+	lazyRouter := fiber.NewLazyRouter("router")
+	lazyRouter.SetRoutes(makeTestRoutes(t, "route-a", "route-b"))
+
+	applyRoutePolicies(lazyRouter, map[string]*routePolicy{
+		"route-a": {retry: &router.RetryPolicy{MaxAttempts: 2}},
+	})
+
+	routes := lazyRouter.GetRoutes()
+	assert.IsType(t, &resilientRoute{}, routes["route-a"])
+	assert.Equal(t, "route-a", routes["route-a"].ID())
+	assert.IsType(t, &fiber.Proxy{}, routes["route-b"])
+}
+
+func TestResilientRouteRetries(t *testing.T) {
+	tests := map[string]struct {
+		statuses      []int
+		retry         *router.RetryPolicy
+		expectedCalls int
+		expectedCode  int
+	}{
+		"success | no retries": {
+			statuses:      []int{http.StatusOK},
+			retry:         &router.RetryPolicy{MaxAttempts: 3},
+			expectedCalls: 1,
+			expectedCode:  http.StatusOK,
+		},
+		"success | after retry": {
+			statuses:      []int{http.StatusServiceUnavailable, http.StatusOK},
+			retry:         &router.RetryPolicy{MaxAttempts: 3, Backoff: fiberConfig.Duration(time.Millisecond)},
+			expectedCalls: 2,
+			expectedCode:  http.StatusOK,
+		},
+		"failure | max attempts reached": {
+			statuses:      []int{http.StatusServiceUnavailable},
+			retry:         &router.RetryPolicy{MaxAttempts: 3},
+			expectedCalls: 3,
+			expectedCode:  http.StatusServiceUnavailable,
+		},
+		"failure | status code not retryable": {
+			statuses: []int{http.StatusBadRequest},
+			retry: &router.RetryPolicy{
+				MaxAttempts:          3,
+				RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+			},
+			expectedCalls: 1,
+			expectedCode:  http.StatusBadRequest,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			dispatcher := &statusDispatcher{statuses: tt.statuses}
+			route := newTestResilientRoute(t, dispatcher, &routePolicy{retry: tt.retry})
+
+			resp := <-route.Dispatch(context.Background(), newTestFiberRequest(t)).Iter()
+
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			assert.Equal(t, tt.expectedCalls, dispatcher.calls)
+		})
+	}
+}
+
+func TestResilientRouteCircuitBreaker(t *testing.T) {
+	dispatcher := &statusDispatcher{statuses: []int{http.StatusInternalServerError}}
+	route := newTestResilientRoute(t, dispatcher, &routePolicy{
+		retry: &router.RetryPolicy{MaxAttempts: 3},
+		breaker: newCircuitBreaker("route-a", router.CircuitBreakerConfig{
+			FailureThreshold: 2,
+			OpenInterval:     fiberConfig.Duration(time.Minute),
+			HalfOpenProbes:   1,
+		}),
+	})
+
+	// The circuit is opened during the retries, and the remaining attempt is rejected
+	resp := <-route.Dispatch(context.Background(), newTestFiberRequest(t)).Iter()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode())
+	assert.Equal(t, 2, dispatcher.calls)
+	assert.True(t, isCircuitOpen(route))
+
+	// The requests are rejected without calling the route, while the circuit is open
+	resp = <-route.Dispatch(context.Background(), newTestFiberRequest(t)).Iter()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode())
+	assert.Equal(t, 2, dispatcher.calls)
+}
+
+// slowDispatcher responds to the first request after the delay, and to the other requests immediately
+type slowDispatcher struct {
+	mu    sync.Mutex
+	delay time.Duration
+	calls int
+}
+
+func (d *slowDispatcher) Do(_ fiber.Request) fiber.Response {
+	d.mu.Lock()
+	d.calls++
+	calls := d.calls
+	d.mu.Unlock()
+	if calls == 1 {
+		time.Sleep(d.delay)
+		return fiberHttp.NewHTTPResponse(&http.Response{StatusCode: http.StatusOK, Body: http.NoBody})
+	}
+	return fiberHttp.NewHTTPResponse(&http.Response{StatusCode: http.StatusCreated, Body: http.NoBody})
+}
+
+func TestResilientRouteHedging(t *testing.T) {
+	dispatcher := &slowDispatcher{delay: 200 * time.Millisecond}
+	route := newTestResilientRoute(t, dispatcher, &routePolicy{
+		hedger: newHedger("route-a", router.HedgingPolicy{
+			Delay:           fiberConfig.Duration(10 * time.Millisecond),
+			MaxHedgePercent: 100,
+		}),
+	})
+	collector := hedging.NewCollector()
+	ctx := hedging.WithCollector(context.Background(), collector)
+
+	// The response from the duplicate request is used, as the original request is slow
+	resp := <-route.Dispatch(ctx, newTestFiberRequest(t)).Iter()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode())
+	assert.Equal(t, []*hedging.Record{{RouteID: "route-a", Winner: hedging.HedgeCopy}}, collector.Records())
+
+	// The request is not hedged, if the response is received within the delay
+	collector = hedging.NewCollector()
+	ctx = hedging.WithCollector(context.Background(), collector)
+	resp = <-route.Dispatch(ctx, newTestFiberRequest(t)).Iter()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode())
+	assert.Empty(t, collector.Records())
+}
@@ -2,7 +2,11 @@ package fiberapi
 
 import (
 	"encoding/json"
+	"time"
 
+	fiberConfig "github.com/gojek/fiber/config"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
 	"github.com/caraml-dev/turing/engines/experiment/runner"
 	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
 	"github.com/caraml-dev/turing/engines/router/missionctl/experiment"
@@ -21,6 +25,14 @@ type expPolicyCfg struct {
 	ExpEngine             string                 `json:"experiment_engine,omitempty"`
 	ExpEngineProps        map[string]interface{} `json:"experiment_engine_properties,omitempty"`
 	LivenessPeriodSeconds int                    `json:"experiment_engine_liveness_period_seconds,omitempty"`
+	TreatmentCache        *treatmentCacheCfg     `json:"experiment_treatment_cache,omitempty"`
+}
+
+type treatmentCacheCfg struct {
+	UnitIDSource string               `json:"unit_id_source"`
+	UnitIDField  string               `json:"unit_id_field"`
+	MaxEntries   int                  `json:"max_entries"`
+	TTL          fiberConfig.Duration `json:"ttl"`
 }
 
 // ****************************************************************************
@@ -80,7 +92,7 @@ func newRouteSelectionPolicy(properties json.RawMessage) (*routeSelectionPolicy,
 
 // experimentationPolicy captures the common properties for Experimenting
 type experimentationPolicy struct {
-	experimentEngine runner.ExperimentRunner
+	experimentEngine runner.ContextExperimentRunner
 }
 
 // MarshalJSON is used to marshal the struct type with private fields into valid json,
@@ -107,11 +119,20 @@ func newExperimentationPolicy(properties json.RawMessage) (*experimentationPolic
 		return nil, errors.Newf(errors.BadConfig, "Failed to parse experimentation policy")
 	}
 
+	var cacheCfg *runner.TreatmentCacheConfig
+	if expPolicy.TreatmentCache != nil {
+		cacheCfg, err = newTreatmentCacheConfig(expPolicy.TreatmentCache)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Initialize experiment policy
 	engine, err := experiment.NewExperimentRunner(
 		expPolicy.ExpEngine,
 		expPolicy.ExpEngineProps,
 		expPolicy.LivenessPeriodSeconds,
+		cacheCfg,
 	)
 	if err != nil {
 		return nil, err
@@ -120,3 +141,27 @@ func newExperimentationPolicy(properties json.RawMessage) (*experimentationPolic
 		experimentEngine: engine,
 	}, nil
 }
+
+// newTreatmentCacheConfig validates the treatment cache properties and converts them to the
+// config of the experiment runner's cache
+func newTreatmentCacheConfig(cfg *treatmentCacheCfg) (*runner.TreatmentCacheConfig, error) {
+	unitIDSource, err := request.GetFieldSource(cfg.UnitIDSource)
+	if err != nil {
+		return nil, errors.Newf(errors.BadConfig, "Invalid unit ID source of the treatment cache: %s", cfg.UnitIDSource)
+	}
+	if cfg.UnitIDField == "" {
+		return nil, errors.Newf(errors.BadConfig, "Unit ID field of the treatment cache is not set")
+	}
+	if cfg.MaxEntries <= 0 || cfg.TTL <= 0 {
+		return nil, errors.Newf(
+			errors.BadConfig,
+			"Max entries and TTL of the treatment cache should be positive",
+		)
+	}
+	return &runner.TreatmentCacheConfig{
+		UnitIDSource: unitIDSource,
+		UnitIDField:  cfg.UnitIDField,
+		MaxEntries:   cfg.MaxEntries,
+		TTL:          time.Duration(cfg.TTL),
+	}, nil
+}
@@ -3,9 +3,12 @@ package fiberapi
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
+	fiberConfig "github.com/gojek/fiber/config"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
 	"github.com/caraml-dev/turing/engines/experiment/runner"
 	"github.com/caraml-dev/turing/engines/experiment/runner/nop"
 	"github.com/caraml-dev/turing/engines/router/missionctl/experiment"
@@ -147,6 +150,43 @@ func TestNewExperimentationPolicy(t *testing.T) {
 			success: false,
 			err:     "Failed to parse experimentation policy",
 		},
+		"failure | invalid treatment cache unit ID source": {
+			props: json.RawMessage(`{
+				"experiment_engine": "Nop",
+				"experiment_treatment_cache": {
+					"unit_id_source": "body",
+					"unit_id_field": "customer_id",
+					"max_entries": 100,
+					"ttl": "1m"
+				}
+			}`),
+			success: false,
+			err:     "Invalid unit ID source of the treatment cache: body",
+		},
+		"failure | missing treatment cache unit ID field": {
+			props: json.RawMessage(`{
+				"experiment_engine": "Nop",
+				"experiment_treatment_cache": {
+					"unit_id_source": "header",
+					"max_entries": 100,
+					"ttl": "1m"
+				}
+			}`),
+			success: false,
+			err:     "Unit ID field of the treatment cache is not set",
+		},
+		"failure | invalid treatment cache ttl": {
+			props: json.RawMessage(`{
+				"experiment_engine": "Nop",
+				"experiment_treatment_cache": {
+					"unit_id_source": "payload",
+					"unit_id_field": "customer.id",
+					"max_entries": 100
+				}
+			}`),
+			success: false,
+			err:     "Max entries and TTL of the treatment cache should be positive",
+		},
 	}
 
 	for name, data := range tests {
@@ -163,6 +203,22 @@ func TestNewExperimentationPolicy(t *testing.T) {
 	}
 }
 
+func TestNewTreatmentCacheConfig(t *testing.T) {
+	actual, err := newTreatmentCacheConfig(&treatmentCacheCfg{
+		UnitIDSource: "Header",
+		UnitIDField:  "Customer-ID",
+		MaxEntries:   100,
+		TTL:          fiberConfig.Duration(time.Minute),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &runner.TreatmentCacheConfig{
+		UnitIDSource: request.HeaderFieldSource,
+		UnitIDField:  "Customer-ID",
+		MaxEntries:   100,
+		TTL:          time.Minute,
+	}, actual)
+}
+
 func TestCustomRoutingPolicyMarshal(t *testing.T) {
 	expectedJSON := `{"DefaultRoute": "route1"}`
 	policy := routeSelectionPolicy{
@@ -12,6 +12,7 @@ import (
 	"github.com/gojek/fiber"
 	grpcFiber "github.com/gojek/fiber/grpc"
 	fiberProtocol "github.com/gojek/fiber/protocol"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
 	"github.com/caraml-dev/turing/engines/experiment/runner"
@@ -102,6 +103,8 @@ func (r *DefaultTuringRoutingStrategy) SelectRoute(
 			// http headers are transformed into canonical case, using httpHeader.Set, to call experiment engine in http1
 			httpHeader.Set(k, strings.Join(v, ","))
 		}
+		// The unit ID of the treatment cache, if any, is read from the UPI request
+		ctx = runner.WithUPIRequest(ctx, metadata.MD(req.Header()), requestProto)
 	}
 
 	// Get the experiment treatment
@@ -109,7 +112,7 @@ func (r *DefaultTuringRoutingStrategy) SelectRoute(
 	options := runner.GetTreatmentOptions{
 		TuringRequestID: turingReqID,
 	}
-	expPlan, expErr := r.experimentEngine.GetTreatmentForRequest(httpHeader, payload, options)
+	expPlan, expErr := r.experimentEngine.GetTreatmentForRequestWithContext(ctx, httpHeader, payload, options)
 
 	// Create experiment response object
 	experimentResponse := experiment.NewResponse(expPlan, expErr)
@@ -130,9 +133,9 @@ func (r *DefaultTuringRoutingStrategy) SelectRoute(
 	// perform a check on both of them and determine the final route response to return
 	for _, m := range r.experimentMappings {
 		if m.Experiment == expPlan.ExperimentName && m.Treatment == expPlan.Name {
-			// Stop matching on first match because only 1 route is required. Don't send in fallbacks,
-			// because we do not want to suppress the error from the preferred route.
-			return routes[m.Route], []fiber.Component{}, labels, nil
+			// Stop matching on first match because only 1 route is required.
+			primary, fallbacks := selectRoute(ctx, routes[m.Route], fallbacks)
+			return primary, fallbacks, labels, nil
 		}
 	}
 
@@ -149,7 +152,8 @@ func (r *DefaultTuringRoutingStrategy) SelectRoute(
 		}
 
 		if selectedRoute, ok := routes[routeName]; ok {
-			return selectedRoute, []fiber.Component{}, labels, nil
+			primary, fallbacks := selectRoute(ctx, selectedRoute, fallbacks)
+			return primary, fallbacks, labels, nil
 		}
 
 		// There are no routes with the route name found in the treatment
@@ -162,3 +166,18 @@ func (r *DefaultTuringRoutingStrategy) SelectRoute(
 	// primary route will be nil if there are no matching treatments in the mapping
 	return nil, fallbacks, labels, nil
 }
+
+// selectRoute returns the selected route as the primary route, without any fallbacks, because we do
+// not want to suppress the error from the preferred route. If the circuit breaker of the selected route
+// is open, the fallbacks are returned instead, so that the request is sent to them immediately.
+func selectRoute(
+	ctx context.Context,
+	route fiber.Component,
+	fallbacks []fiber.Component,
+) (fiber.Component, []fiber.Component) {
+	if isCircuitOpen(route) {
+		log.WithContext(ctx).Debugf("Circuit breaker of route %s is open, using the fallback routes", route.ID())
+		return nil, fallbacks
+	}
+	return route, []fiber.Component{}
+}
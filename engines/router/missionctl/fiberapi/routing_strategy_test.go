@@ -5,16 +5,19 @@ import (
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"bou.ke/monkey"
 	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
 	"github.com/gojek/fiber"
+	fiberConfig "github.com/gojek/fiber/config"
 	fiberGrpc "github.com/gojek/fiber/grpc"
 	fiberHttp "github.com/gojek/fiber/http"
 	"github.com/stretchr/testify/assert"
 
 	runnerV1 "github.com/caraml-dev/turing/engines/experiment/plugin/inproc/runner"
 	"github.com/caraml-dev/turing/engines/experiment/runner"
+	"github.com/caraml-dev/turing/engines/router"
 	"github.com/caraml-dev/turing/engines/router/missionctl/experiment"
 	testutils2 "github.com/caraml-dev/turing/engines/router/missionctl/fiberapi/internal/testutils"
 	tu "github.com/caraml-dev/turing/engines/router/missionctl/internal/testutils"
@@ -112,7 +115,9 @@ func TestInitializeDefaultRoutingStrategy(t *testing.T) {
 			)
 			monkey.Patch(
 				experiment.NewExperimentRunner,
-				func(_ string, _ map[string]interface{}, _ int) (runner.ExperimentRunner, error) {
+				func(
+					_ string, _ map[string]interface{}, _ int, _ *runner.TreatmentCacheConfig,
+				) (runner.ContextExperimentRunner, error) {
 					return nil, nil
 				},
 			)
@@ -367,6 +372,40 @@ func TestDefaultRoutingStrategy(t *testing.T) {
 	}
 }
 
+func TestDefaultRoutingStrategyCircuitOpen(t *testing.T) {
+	fiberHTTPReq, err := fiberHttp.NewHTTPRequest(tu.MakeTestRequest(t, tu.NopHTTPRequestModifier))
+	tu.FailOnError(t, err)
+
+	// Open the circuit breaker of the route selected by the treatment
+	routes := makeTestRoutes(t, "route-A", "control")
+	breaker := newCircuitBreaker("route-A", router.CircuitBreakerConfig{
+		FailureThreshold: 1,
+		OpenInterval:     fiberConfig.Duration(time.Minute),
+		HalfOpenProbes:   1,
+	})
+	breaker.Record(false)
+	routes["route-A"] = &resilientRoute{Component: routes["route-A"], routePolicy: &routePolicy{breaker: breaker}}
+
+	strategy := DefaultTuringRoutingStrategy{
+		&experimentationPolicy{
+			experimentEngine: testutils2.MockExperimentRunner{
+				Treatment: &runner.Treatment{ExperimentName: "test_experiment", Name: "treatment-A"},
+			},
+		},
+		&routeSelectionPolicy{
+			defaultRoute: "control",
+			experimentMappings: []experimentMapping{
+				{Experiment: "test_experiment", Treatment: "treatment-A", Route: "route-A"},
+			},
+		},
+	}
+	route, fallbacks, _, err := strategy.SelectRoute(context.Background(), fiberHTTPReq, routes)
+
+	assert.NoError(t, err)
+	assert.Nil(t, route)
+	assert.Equal(t, []fiber.Component{routes["control"]}, fallbacks)
+}
+
 // For every endpoint name, this method creates a fiber proxy with the given name
 // and an empty endpoint and returns a map of the endpoint name and proxy
 func makeTestRoutes(t *testing.T, names ...string) map[string]fiber.Component {
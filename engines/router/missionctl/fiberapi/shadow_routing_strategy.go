@@ -0,0 +1,101 @@
+package fiberapi
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gojek/fiber"
+	fiberHttp "github.com/gojek/fiber/http"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+	"github.com/caraml-dev/turing/engines/router/missionctl/shadow"
+)
+
+// ShadowRoutingStrategy always selects the primary route and, in addition, dispatches a copy
+// of the request to each of the shadow routes asynchronously. The responses of the shadow routes
+// are never returned, and are only recorded to the shadow response collector in the request
+// context (if any), for logging.
+type ShadowRoutingStrategy struct {
+	PrimaryRouteID string   `json:"primary_route_id" validate:"required,notBlank"`
+	ShadowRouteIDs []string `json:"shadow_route_ids" validate:"required,notBlank,dive,notBlank"`
+}
+
+// Initialize is invoked by the Fiber library to initialize this strategy
+// with the configuration
+func (s *ShadowRoutingStrategy) Initialize(properties json.RawMessage) error {
+	if err := json.Unmarshal(properties, s); err != nil {
+		return errors.Wrapf(err, "Failed initializing shadow routing strategy")
+	}
+	if err := validation.Struct(s); err != nil {
+		return errors.Wrapf(err, "Failed initializing shadow routing strategy")
+	}
+
+	return nil
+}
+
+// SelectRoute dispatches the request to the shadow routes and returns the primary route
+func (s *ShadowRoutingStrategy) SelectRoute(
+	ctx context.Context,
+	req fiber.Request,
+	routes map[string]fiber.Component,
+) (fiber.Component, []fiber.Component, fiber.Labels, error) {
+	labels := fiber.NewLabelsMap()
+
+	primary, exists := routes[s.PrimaryRouteID]
+	if !exists {
+		// This is unexpected, terminate with error.
+		err := errors.Newf(errors.BadConfig, `route with id "%s" doesn't exist in the router`, s.PrimaryRouteID)
+		log.WithContext(ctx).Errorf(err.Error())
+		return nil, nil, labels, createFiberError(err, req.Protocol())
+	}
+
+	collector, _ := shadow.GetCollector(ctx)
+	// The shadow requests should not be cancelled, when the primary request completes
+	shadowCtx := context.WithoutCancel(ctx)
+	for _, routeID := range s.ShadowRouteIDs {
+		route, exists := routes[routeID]
+		if !exists {
+			log.WithContext(ctx).Errorf(`shadow route with id "%s" doesn't exist in the router`, routeID)
+			continue
+		}
+		shadowReq, err := req.Clone()
+		if err != nil {
+			log.WithContext(ctx).Errorf("Failed to copy the request for shadow route %s: %s", routeID, err)
+			continue
+		}
+		// Only record the shadow response, if it can still be logged
+		if collector != nil && !collector.Begin() {
+			collector = nil
+		}
+		go dispatchShadow(shadowCtx, shadowReq, route, collector)
+	}
+
+	return primary, []fiber.Component{}, labels, nil
+}
+
+// dispatchShadow dispatches the request to the shadow route and records its response
+// to the given collector, if any
+func dispatchShadow(ctx context.Context, req fiber.Request, route fiber.Component, collector *shadow.Collector) {
+	startTime := time.Now()
+	resp := &shadow.Response{RouteID: route.ID()}
+
+	for r := range route.Dispatch(ctx, req).Iter() {
+		if !r.IsSuccess() {
+			resp.Error = string(r.Payload())
+			continue
+		}
+		resp.Body = r.Payload()
+		if httpResp, ok := r.(*fiberHttp.Response); ok {
+			resp.Header = httpResp.Header()
+		}
+	}
+	resp.Latency = time.Since(startTime)
+
+	if collector != nil {
+		collector.Record(resp)
+	} else if resp.Error != "" {
+		log.WithContext(ctx).Debugf("Error response received from shadow route %s: %s", resp.RouteID, resp.Error)
+	}
+}
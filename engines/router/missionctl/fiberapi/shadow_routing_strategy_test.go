@@ -0,0 +1,133 @@
+package fiberapi_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gojek/fiber"
+	fiberHttp "github.com/gojek/fiber/http"
+	"github.com/stretchr/testify/require"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/fiberapi"
+	tfu "github.com/caraml-dev/turing/engines/router/missionctl/fiberapi/internal/testutils"
+	tu "github.com/caraml-dev/turing/engines/router/missionctl/internal/testutils"
+	"github.com/caraml-dev/turing/engines/router/missionctl/shadow"
+)
+
+// shadowDispatcher always responds with the given body
+type shadowDispatcher struct {
+	body string
+}
+
+func (d shadowDispatcher) Do(_ fiber.Request) fiber.Response {
+	return fiberHttp.NewHTTPResponse(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString(d.body)),
+	})
+}
+
+func TestShadowRoutingStrategy_Initialize(t *testing.T) {
+	type testCase struct {
+		properties    json.RawMessage
+		strategy      *fiberapi.ShadowRoutingStrategy
+		expectedError string
+	}
+
+	suite := map[string]testCase{
+		"success": {
+			properties: json.RawMessage(`{
+				"primary_route_id": "primary",
+				"shadow_route_ids": ["shadow-a", "shadow-b"]
+			}`),
+			strategy: &fiberapi.ShadowRoutingStrategy{
+				PrimaryRouteID: "primary",
+				ShadowRouteIDs: []string{"shadow-a", "shadow-b"},
+			},
+		},
+		"failure | missing primary route": {
+			properties: json.RawMessage(`{
+				"shadow_route_ids": ["shadow-a"]
+			}`),
+			expectedError: "Failed initializing shadow routing strategy: " +
+				"Key: 'ShadowRoutingStrategy.PrimaryRouteID' " +
+				"Error:Field validation for 'PrimaryRouteID' failed on the 'required' tag",
+		},
+		"failure | empty shadow routes": {
+			properties: json.RawMessage(`{
+				"primary_route_id": "primary",
+				"shadow_route_ids": []
+			}`),
+			expectedError: "Failed initializing shadow routing strategy: " +
+				"Key: 'ShadowRoutingStrategy.ShadowRouteIDs' " +
+				"Error:Field validation for 'ShadowRouteIDs' failed on the 'notBlank' tag",
+		},
+		"failure | invalid type": {
+			properties: json.RawMessage(`42`),
+			expectedError: "Failed initializing shadow routing strategy: " +
+				"json: cannot unmarshal number into Go value of type fiberapi.ShadowRoutingStrategy",
+		},
+	}
+
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			strategy := new(fiberapi.ShadowRoutingStrategy)
+			err := strategy.Initialize(tt.properties)
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+				tu.FailOnError(t, tu.CompareObjects(strategy, tt.strategy))
+			} else {
+				require.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestShadowRoutingStrategy_SelectRoute(t *testing.T) {
+	shadowRoute, err := fiber.NewCaller("shadow-a", shadowDispatcher{body: `{"shadow": true}`})
+	require.NoError(t, err)
+
+	routes := map[string]fiber.Component{
+		"primary":  tfu.NewFiberCaller(t, "primary"),
+		"shadow-a": shadowRoute,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		strategy := &fiberapi.ShadowRoutingStrategy{
+			PrimaryRouteID: "primary",
+			ShadowRouteIDs: []string{"shadow-a", "shadow-missing"},
+		}
+		collector := shadow.NewCollector()
+		ctx := shadow.WithCollector(context.Background(), collector)
+
+		req, err := fiberHttp.NewHTTPRequest(httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{}`)))
+		require.NoError(t, err)
+
+		route, fallbacks, _, err := strategy.SelectRoute(ctx, req, routes)
+		require.NoError(t, err)
+		require.Equal(t, routes["primary"], route)
+		require.Empty(t, fallbacks)
+
+		responses := collector.Wait()
+		require.Len(t, responses, 1)
+		require.Equal(t, "shadow-a", responses[0].RouteID)
+		require.Equal(t, `{"shadow": true}`, string(responses[0].Body))
+		require.Equal(t, "application/json", responses[0].Header.Get("Content-Type"))
+		require.Empty(t, responses[0].Error)
+	})
+
+	t.Run("failure | primary route doesn't exist", func(t *testing.T) {
+		strategy := &fiberapi.ShadowRoutingStrategy{
+			PrimaryRouteID: "control",
+			ShadowRouteIDs: []string{"shadow-a"},
+		}
+		_, _, _, err := strategy.SelectRoute(
+			context.Background(), tfu.NewHTTPFiberRequest(t, http.Header{}, `{}`), routes)
+		require.EqualError(t, err, `route with id "control" doesn't exist in the router`)
+	})
+}
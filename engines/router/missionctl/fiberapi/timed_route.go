@@ -0,0 +1,61 @@
+package fiberapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/gojek/fiber"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/timing"
+)
+
+// applyRouteTimings recursively wraps the routes of the given component with a timedRoute.
+// It is applied after the route policies, so that the timings include the retries and the hedged
+// requests of the routes.
+func applyRouteTimings(component fiber.Component) {
+	multiRoute, ok := component.(fiber.MultiRouteComponent)
+	if !ok {
+		return
+	}
+	routes := multiRoute.GetRoutes()
+	for routeID, route := range routes {
+		applyRouteTimings(route)
+		if route.Kind() == fiber.CallerKind {
+			routes[routeID] = &timedRoute{Component: route}
+		}
+	}
+	multiRoute.SetRoutes(routes)
+}
+
+// timedRoute wraps a route, recording the calls to it in the timing collector of the request, if any.
+// The calls are recorded before their responses are passed on, unlike those measured by the interceptors,
+// which complete asynchronously, so that they are all recorded by the time the router responds.
+type timedRoute struct {
+	fiber.Component
+}
+
+// Dispatch dispatches the request to the wrapped route
+func (r *timedRoute) Dispatch(ctx context.Context, req fiber.Request) fiber.ResponseQueue {
+	collector, err := timing.GetCollector(ctx)
+	if err != nil {
+		return r.Component.Dispatch(ctx, req)
+	}
+
+	startTime := time.Now()
+	queue := r.Component.Dispatch(ctx, req)
+	out := make(chan fiber.Response, 1)
+	go func() {
+		defer close(out)
+		for resp := range queue.Iter() {
+			collector.RecordRoute(&timing.Record{
+				Component:  timing.RouteComponent,
+				RouteID:    r.ID(),
+				StartTime:  startTime,
+				Duration:   time.Since(startTime),
+				StatusCode: resp.StatusCode(),
+			}, resp)
+			out <- resp
+		}
+	}()
+	return fiber.NewResponseQueue(out, 1)
+}
@@ -0,0 +1,76 @@
+package fiberapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	fiberConfig "github.com/gojek/fiber/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/caraml-dev/turing/engines/router"
+	"github.com/caraml-dev/turing/engines/router/missionctl/timing"
+)
+
+func TestApplyRouteTimings(t *testing.T) {
+	lazyRouter := fiber.NewLazyRouter("router")
+	lazyRouter.SetRoutes(makeTestRoutes(t, "route-a", "route-b"))
+	applyRoutePolicies(lazyRouter, map[string]*routePolicy{
+		"route-a": {retry: &router.RetryPolicy{MaxAttempts: 2}},
+	})
+
+	applyRouteTimings(lazyRouter)
+
+	routes := lazyRouter.GetRoutes()
+	require.IsType(t, &timedRoute{}, routes["route-a"])
+	assert.IsType(t, &resilientRoute{}, routes["route-a"].(*timedRoute).Component)
+	assert.Equal(t, "route-a", routes["route-a"].ID())
+	require.IsType(t, &timedRoute{}, routes["route-b"])
+	assert.IsType(t, &fiber.Proxy{}, routes["route-b"].(*timedRoute).Component)
+}
+
+func TestTimedRoute(t *testing.T) {
+	dispatcher := &statusDispatcher{statuses: []int{http.StatusInternalServerError, http.StatusOK}}
+	route := &timedRoute{Component: newTestResilientRoute(t, dispatcher, &routePolicy{
+		retry: &router.RetryPolicy{MaxAttempts: 2},
+	})}
+	collector := timing.NewCollector()
+	ctx := timing.WithCollector(context.Background(), collector)
+
+	// The call to the route, including its retries, is recorded before the response is returned
+	startTime := time.Now()
+	resp := <-route.Dispatch(ctx, newTestFiberRequest(t)).Iter()
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	records := collector.Records()
+	require.Len(t, records, 1)
+	assert.Equal(t, timing.RouteComponent, records[0].Component)
+	assert.Equal(t, "route-a", records[0].RouteID)
+	assert.Equal(t, http.StatusOK, records[0].StatusCode)
+	assert.False(t, records[0].StartTime.Before(startTime))
+	collector.SetResponse(resp)
+	assert.Equal(t, "route-a", collector.RouteID())
+
+	// The route is called as is, if the request has no timing collector
+	resp = <-route.Dispatch(context.Background(), newTestFiberRequest(t)).Iter()
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Len(t, collector.Records(), 1)
+}
+
+func TestTimedRouteCircuitOpen(t *testing.T) {
+	dispatcher := &statusDispatcher{statuses: []int{http.StatusInternalServerError}}
+	route := &timedRoute{Component: newTestResilientRoute(t, dispatcher, &routePolicy{
+		breaker: newCircuitBreaker("route-a", router.CircuitBreakerConfig{
+			FailureThreshold: 1,
+			OpenInterval:     fiberConfig.Duration(time.Minute),
+			HalfOpenProbes:   1,
+		}),
+	})}
+
+	// The circuit breaker of the wrapped route is checked
+	assert.False(t, isCircuitOpen(route))
+	<-route.Dispatch(context.Background(), newTestFiberRequest(t)).Iter()
+	assert.True(t, isCircuitOpen(route))
+}
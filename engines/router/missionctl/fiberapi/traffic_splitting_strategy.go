@@ -14,6 +14,7 @@ import (
 
 	"github.com/caraml-dev/turing/engines/router"
 	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/fallback"
 	"github.com/caraml-dev/turing/engines/router/missionctl/log"
 )
 
@@ -21,6 +22,8 @@ var (
 	validation = func() *validator.Validate {
 		instance := validator.New()
 		_ = instance.RegisterValidation("notBlank", validators.NotBlank)
+		instance.RegisterStructValidation(router.ValidateTrafficRuleCondition, router.TrafficRuleCondition{})
+		instance.RegisterStructValidation(validateWeightedTrafficSplittingStrategy, WeightedTrafficSplittingStrategy{})
 
 		return instance
 	}()
@@ -120,7 +123,7 @@ func (s *TrafficSplittingStrategy) SelectRoute(
 		} else if res {
 			routeID := rule.RouteID
 			if r, exists := routes[routeID]; exists {
-				return r, []fiber.Component{}, labels.WithLabel(TrafficRuleLabel, r.ID()), nil
+				return r, []fiber.Component{}, withTrafficRule(ctx, labels, r), nil
 			}
 			// This is unexpected, terminate with error.
 			err := errors.Newf(errors.BadConfig, `route with id "%s" doesn't exist in the router`, routeID)
@@ -132,7 +135,7 @@ func (s *TrafficSplittingStrategy) SelectRoute(
 	// Given request hasn't satisfied any of the rules configured on this routing strategy;
 	// check if default route exists.
 	if defaultRoute, exist := routes[s.DefaultRouteID]; exist {
-		return defaultRoute, []fiber.Component{}, labels.WithLabel(TrafficRuleLabel, defaultRoute.ID()), nil
+		return defaultRoute, []fiber.Component{}, withTrafficRule(ctx, labels, defaultRoute), nil
 	}
 
 	// No matches whatsoever.
@@ -140,3 +143,11 @@ func (s *TrafficSplittingStrategy) SelectRoute(
 	log.WithContext(ctx).Errorf(err.Error())
 	return nil, nil, labels, createFiberError(err, req.Protocol())
 }
+
+// withTrafficRule labels the selected route with the traffic rule, that the request matched, and records
+// the rule in the request's fallback selection, if any, since the labels are lost if the route fails
+func withTrafficRule(ctx context.Context, labels fiber.Labels, route fiber.Component) fiber.Labels {
+	selection, _ := fallback.GetSelection(ctx)
+	selection.SetTrafficRule(route.ID())
+	return labels.WithLabel(TrafficRuleLabel, route.ID())
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
 	"github.com/caraml-dev/turing/engines/router"
+	"github.com/caraml-dev/turing/engines/router/missionctl/fallback"
 	"github.com/caraml-dev/turing/engines/router/missionctl/fiberapi"
 	tfu "github.com/caraml-dev/turing/engines/router/missionctl/fiberapi/internal/testutils"
 	tu "github.com/caraml-dev/turing/engines/router/missionctl/internal/testutils"
@@ -631,13 +632,16 @@ func TestTrafficSplittingStrategy_SelectRoute(t *testing.T) {
 
 	for name, tt := range suite {
 		t.Run(name, func(t *testing.T) {
-			ctx := turingctx.NewTuringContext(context.Background())
+			selection := fallback.NewSelection()
+			ctx := fallback.WithSelection(turingctx.NewTuringContext(context.Background()), selection)
 			actual, actualFallbacks, labels, err := tt.strategy.SelectRoute(ctx, tt.request, tt.routes)
 			if tt.expectedError == "" {
 				require.NoError(t, err)
 				require.Equal(t, tt.expected, actual)
 				require.Equal(t, tt.fallbacks, actualFallbacks)
 				require.Equal(t, tt.labels, labels)
+				// The traffic rule is recorded to pick its fallback response, if the route fails
+				require.Equal(t, actual.ID(), selection.TrafficRule())
 			} else {
 				require.EqualError(t, err, tt.expectedError)
 			}
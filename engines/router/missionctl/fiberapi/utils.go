@@ -23,10 +23,20 @@ func CreateFiberRouterFromConfig(
 		return nil, err
 	}
 
+	// Apply the retry, circuit breaker and hedging policies of the routes, which are not supported by Fiber
+	policies, err := loadRoutePolicies(cfgFilePath)
+	if err != nil {
+		return nil, err
+	}
+	applyRoutePolicies(component, policies)
+	// Record the calls to the routes in the timing collector of the request
+	applyRouteTimings(component)
+
 	// Create required interceptors
 	interceptors := []fiber.Interceptor{
 		NewErrorLoggingInterceptor(log.Glob()),
 		NewMetricsInterceptor(),
+		NewDebugTraceInterceptor(),
 	}
 
 	if fiberDebugLog {
@@ -80,5 +90,20 @@ func registerFiberTypes() error {
 	if err != nil {
 		return err
 	}
+
+	err = types.InstallType("fiber.WeightedTrafficSplittingStrategy", &WeightedTrafficSplittingStrategy{})
+	if err != nil {
+		return err
+	}
+
+	err = types.InstallType("fiber.ShadowRoutingStrategy", &ShadowRoutingStrategy{})
+	if err != nil {
+		return err
+	}
+
+	err = types.InstallType("fiber.BanditRoutingStrategy", &BanditRoutingStrategy{})
+	if err != nil {
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,135 @@
+package fiberapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"github.com/go-playground/validator/v10"
+	"github.com/gojek/fiber"
+	grpcFiber "github.com/gojek/fiber/grpc"
+	fiberProtocol "github.com/gojek/fiber/protocol"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+)
+
+// totalTrafficWeight is the value, that the weights of all the rules of the
+// WeightedTrafficSplittingStrategy are expected to sum up to
+const totalTrafficWeight = 100
+
+// WeightedTrafficSplittingStrategyRule represents one rule of the WeightedTrafficSplittingStrategy.
+// Each rule receives the share of the traffic, proportional to its weight
+type WeightedTrafficSplittingStrategyRule struct {
+	RouteID string `json:"route_id" validate:"required,notBlank"`
+	Weight  int    `json:"weight" validate:"min=1,max=100"`
+}
+
+// TrafficSplitUnit is the field of the request, which value is used to assign
+// the request to one of the rules of the WeightedTrafficSplittingStrategy
+type TrafficSplitUnit struct {
+	FieldSource request.FieldSource `json:"field_source" validate:"required,oneof=header payload prediction_context"`
+	Field       string              `json:"field" validate:"required"`
+}
+
+// WeightedTrafficSplittingStrategy selects the route by hashing the value of the unit field
+// of the request into one of the rules, based on their weights. Requests with the same value
+// of the unit are consistently routed to the same route. Requests without the unit field are
+// sent to the default route.
+type WeightedTrafficSplittingStrategy struct {
+	DefaultRouteID string                                  `json:"default_route_id"`
+	Unit           *TrafficSplitUnit                       `json:"unit" validate:"required"`
+	Rules          []*WeightedTrafficSplittingStrategyRule `json:"rules" validate:"required,notBlank,dive"`
+}
+
+// Initialize is invoked by the Fiber library to initialize this strategy
+// with the configuration
+func (s *WeightedTrafficSplittingStrategy) Initialize(properties json.RawMessage) error {
+	if err := json.Unmarshal(properties, s); err != nil {
+		return errors.Wrapf(err, "Failed initializing weighted traffic splitting strategy")
+	}
+	if err := validation.Struct(s); err != nil {
+		return errors.Wrapf(err, "Failed initializing weighted traffic splitting strategy")
+	}
+
+	return nil
+}
+
+// SelectRoute picks the primary route, based on the bucket that the
+// value of the unit field of the request falls into
+func (s *WeightedTrafficSplittingStrategy) SelectRoute(
+	ctx context.Context,
+	req fiber.Request,
+	routes map[string]fiber.Component,
+) (fiber.Component, []fiber.Component, fiber.Labels, error) {
+	labels := fiber.NewLabelsMap()
+
+	routeID := s.DefaultRouteID
+	unitValue, err := s.getUnitValue(req)
+	if err != nil {
+		log.WithContext(ctx).Debugf(
+			"Failed to retrieve traffic split unit from the request, using default route: %s", err)
+	} else {
+		routeID = s.selectRule(unitValue).RouteID
+	}
+
+	if r, exists := routes[routeID]; exists {
+		return r, []fiber.Component{}, withTrafficRule(ctx, labels, r), nil
+	}
+
+	// This is unexpected, terminate with error.
+	err = errors.Newf(errors.BadConfig, `route with id "%s" doesn't exist in the router`, routeID)
+	log.WithContext(ctx).Errorf(err.Error())
+	return nil, nil, labels, createFiberError(err, req.Protocol())
+}
+
+// selectRule returns the rule, which range of buckets contains the bucket of the given unit value
+func (s *WeightedTrafficSplittingStrategy) selectRule(unitValue string) *WeightedTrafficSplittingStrategyRule {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(unitValue))
+	bucket := int(hash.Sum32() % totalTrafficWeight)
+
+	for _, rule := range s.Rules {
+		if bucket < rule.Weight {
+			return rule
+		}
+		bucket -= rule.Weight
+	}
+	// Unreachable, given that the weights of the rules sum up to totalTrafficWeight
+	return s.Rules[len(s.Rules)-1]
+}
+
+func (s *WeightedTrafficSplittingStrategy) getUnitValue(req fiber.Request) (string, error) {
+	switch req.Protocol() {
+	case fiberProtocol.GRPC:
+		grpcFiberReq, ok := req.(*grpcFiber.Request)
+		if !ok {
+			return "", fmt.Errorf("failed to convert into grpc fiber request")
+		}
+		upiReq, ok := grpcFiberReq.ProtoMessage().(*upiv1.PredictValuesRequest)
+		if !ok {
+			return "", fmt.Errorf("failed to convert into upi request")
+		}
+		return request.GetValueFromUPIRequest(req.Header(), upiReq, s.Unit.FieldSource, s.Unit.Field)
+	default:
+		return request.GetValueFromHTTPRequest(req.Header(), req.Payload(), s.Unit.FieldSource, s.Unit.Field)
+	}
+}
+
+// validateWeightedTrafficSplittingStrategy checks that the weights of the rules sum up to totalTrafficWeight
+func validateWeightedTrafficSplittingStrategy(sl validator.StructLevel) {
+	strategy := sl.Current().Interface().(WeightedTrafficSplittingStrategy)
+
+	totalWeight := 0
+	for _, rule := range strategy.Rules {
+		if rule != nil {
+			totalWeight += rule.Weight
+		}
+	}
+	if len(strategy.Rules) > 0 && totalWeight != totalTrafficWeight {
+		sl.ReportError(strategy.Rules, "Rules", "Rules", "sum", fmt.Sprint(totalTrafficWeight))
+	}
+}
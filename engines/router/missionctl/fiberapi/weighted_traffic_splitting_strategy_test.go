@@ -0,0 +1,226 @@
+package fiberapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"github.com/gojek/fiber"
+	"github.com/stretchr/testify/require"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router/missionctl/fallback"
+	"github.com/caraml-dev/turing/engines/router/missionctl/fiberapi"
+	tfu "github.com/caraml-dev/turing/engines/router/missionctl/fiberapi/internal/testutils"
+	tu "github.com/caraml-dev/turing/engines/router/missionctl/internal/testutils"
+)
+
+func TestWeightedTrafficSplittingStrategy_Initialize(t *testing.T) {
+	type testCase struct {
+		properties    json.RawMessage
+		strategy      *fiberapi.WeightedTrafficSplittingStrategy
+		expectedError string
+	}
+
+	suite := map[string]testCase{
+		"success": {
+			properties: json.RawMessage(`{
+				"default_route_id": "control",
+				"unit": {
+					"field_source": "header",
+					"field": "X-User-Id"
+				},
+				"rules": [
+					{"route_id": "ROUTE-A", "weight": 90},
+					{"route_id": "ROUTE-B", "weight": 10}
+				]
+			}`),
+			strategy: &fiberapi.WeightedTrafficSplittingStrategy{
+				DefaultRouteID: "control",
+				Unit: &fiberapi.TrafficSplitUnit{
+					FieldSource: request.HeaderFieldSource,
+					Field:       "X-User-Id",
+				},
+				Rules: []*fiberapi.WeightedTrafficSplittingStrategyRule{
+					{RouteID: "ROUTE-A", Weight: 90},
+					{RouteID: "ROUTE-B", Weight: 10},
+				},
+			},
+		},
+		"failure | missing unit": {
+			properties: json.RawMessage(`{
+				"rules": [{"route_id": "ROUTE-A", "weight": 100}]
+			}`),
+			expectedError: "Failed initializing weighted traffic splitting strategy: " +
+				"Key: 'WeightedTrafficSplittingStrategy.Unit' " +
+				"Error:Field validation for 'Unit' failed on the 'required' tag",
+		},
+		"failure | weights don't sum up to 100": {
+			properties: json.RawMessage(`{
+				"unit": {"field_source": "payload", "field": "user_id"},
+				"rules": [
+					{"route_id": "ROUTE-A", "weight": 50},
+					{"route_id": "ROUTE-B", "weight": 20}
+				]
+			}`),
+			expectedError: "Failed initializing weighted traffic splitting strategy: " +
+				"Key: 'WeightedTrafficSplittingStrategy.Rules' " +
+				"Error:Field validation for 'Rules' failed on the 'sum' tag",
+		},
+		"failure | zero weight": {
+			properties: json.RawMessage(`{
+				"unit": {"field_source": "payload", "field": "user_id"},
+				"rules": [
+					{"route_id": "ROUTE-A", "weight": 100},
+					{"route_id": "ROUTE-B", "weight": 0}
+				]
+			}`),
+			expectedError: "Failed initializing weighted traffic splitting strategy: " +
+				"Key: 'WeightedTrafficSplittingStrategy.Rules[1].Weight' " +
+				"Error:Field validation for 'Weight' failed on the 'min' tag",
+		},
+		"failure | invalid type": {
+			properties: json.RawMessage(`42`),
+			expectedError: "Failed initializing weighted traffic splitting strategy: " +
+				"json: cannot unmarshal number into Go value of type fiberapi.WeightedTrafficSplittingStrategy",
+		},
+	}
+
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			strategy := new(fiberapi.WeightedTrafficSplittingStrategy)
+			err := strategy.Initialize(tt.properties)
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+				tu.FailOnError(t, tu.CompareObjects(strategy, tt.strategy))
+			} else {
+				require.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestWeightedTrafficSplittingStrategy_SelectRoute(t *testing.T) {
+	type testCase struct {
+		strategy      *fiberapi.WeightedTrafficSplittingStrategy
+		routes        map[string]fiber.Component
+		request       fiber.Request
+		expected      fiber.Component
+		labels        fiber.Labels
+		expectedError string
+	}
+
+	routes := map[string]fiber.Component{
+		"control": tfu.NewFiberCaller(t, "control"),
+		"route-a": tfu.NewFiberCaller(t, "route-a"),
+		"route-b": tfu.NewFiberCaller(t, "route-b"),
+	}
+
+	suite := map[string]testCase{
+		"success | http": {
+			strategy: &fiberapi.WeightedTrafficSplittingStrategy{
+				DefaultRouteID: "control",
+				Unit:           &fiberapi.TrafficSplitUnit{FieldSource: request.HeaderFieldSource, Field: "X-User-Id"},
+				Rules: []*fiberapi.WeightedTrafficSplittingStrategyRule{
+					{RouteID: "route-a", Weight: 100},
+				},
+			},
+			routes:   routes,
+			request:  tfu.NewHTTPFiberRequest(t, http.Header{"X-User-Id": []string{"user-1"}}, `{}`),
+			expected: tfu.NewFiberCaller(t, "route-a"),
+			labels:   fiber.LabelsMap{"traffic-rule": []string{"route-a"}},
+		},
+		"success | upi": {
+			strategy: &fiberapi.WeightedTrafficSplittingStrategy{
+				DefaultRouteID: "control",
+				Unit:           &fiberapi.TrafficSplitUnit{FieldSource: request.PredictionContextSource, Field: "user_id"},
+				Rules: []*fiberapi.WeightedTrafficSplittingStrategyRule{
+					{RouteID: "route-b", Weight: 100},
+				},
+			},
+			routes: routes,
+			request: tfu.NewUPIFiberRequest(t, map[string]string{}, &upiv1.PredictValuesRequest{
+				PredictionContext: []*upiv1.Variable{
+					{Name: "user_id", Type: upiv1.Type_TYPE_STRING, StringValue: "user-1"},
+				},
+			}),
+			expected: tfu.NewFiberCaller(t, "route-b"),
+			labels:   fiber.LabelsMap{"traffic-rule": []string{"route-b"}},
+		},
+		"success | missing unit uses default route": {
+			strategy: &fiberapi.WeightedTrafficSplittingStrategy{
+				DefaultRouteID: "control",
+				Unit:           &fiberapi.TrafficSplitUnit{FieldSource: request.PayloadFieldSource, Field: "user_id"},
+				Rules: []*fiberapi.WeightedTrafficSplittingStrategyRule{
+					{RouteID: "route-a", Weight: 100},
+				},
+			},
+			routes:   routes,
+			request:  tfu.NewHTTPFiberRequest(t, http.Header{}, `{"session_id": "abc"}`),
+			expected: tfu.NewFiberCaller(t, "control"),
+			labels:   fiber.LabelsMap{"traffic-rule": []string{"control"}},
+		},
+		"failure | route doesn't exist": {
+			strategy: &fiberapi.WeightedTrafficSplittingStrategy{
+				Unit: &fiberapi.TrafficSplitUnit{FieldSource: request.PayloadFieldSource, Field: "user_id"},
+				Rules: []*fiberapi.WeightedTrafficSplittingStrategyRule{
+					{RouteID: "route-c", Weight: 100},
+				},
+			},
+			routes:        routes,
+			request:       tfu.NewHTTPFiberRequest(t, http.Header{}, `{"user_id": "user-1"}`),
+			expectedError: `route with id "route-c" doesn't exist in the router`,
+		},
+	}
+
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			selection := fallback.NewSelection()
+			ctx := fallback.WithSelection(context.Background(), selection)
+			route, fallbacks, labels, err := tt.strategy.SelectRoute(ctx, tt.request, tt.routes)
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, route)
+				require.Empty(t, fallbacks)
+				require.Equal(t, tt.labels, labels)
+				require.Equal(t, route.ID(), selection.TrafficRule())
+			} else {
+				require.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestWeightedTrafficSplittingStrategy_SelectRouteDistribution(t *testing.T) {
+	strategy := &fiberapi.WeightedTrafficSplittingStrategy{
+		Unit: &fiberapi.TrafficSplitUnit{FieldSource: request.HeaderFieldSource, Field: "X-User-Id"},
+		Rules: []*fiberapi.WeightedTrafficSplittingStrategyRule{
+			{RouteID: "route-a", Weight: 80},
+			{RouteID: "route-b", Weight: 20},
+		},
+	}
+	routes := map[string]fiber.Component{
+		"route-a": tfu.NewFiberCaller(t, "route-a"),
+		"route-b": tfu.NewFiberCaller(t, "route-b"),
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		header := http.Header{"X-User-Id": []string{fmt.Sprintf("user-%d", i)}}
+
+		route, _, _, err := strategy.SelectRoute(context.Background(), tfu.NewHTTPFiberRequest(t, header, `{}`), routes)
+		require.NoError(t, err)
+		counts[route.ID()]++
+
+		// The same unit is always assigned to the same route
+		again, _, _, err := strategy.SelectRoute(context.Background(), tfu.NewHTTPFiberRequest(t, header, `{}`), routes)
+		require.NoError(t, err)
+		require.Equal(t, route.ID(), again.ID())
+	}
+
+	require.InDelta(t, 8000, counts["route-a"], 300)
+	require.InDelta(t, 2000, counts["route-b"], 300)
+}
@@ -0,0 +1,64 @@
+package hedging
+
+import (
+	"context"
+	"sync"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/turingctx"
+)
+
+// Copy identifies the copy of a hedged request, whose response was used
+type Copy string
+
+const (
+	// OriginalCopy is the request that was sent to the route first
+	OriginalCopy Copy = "original"
+	// HedgeCopy is the duplicate request, that was sent to the route after the hedging delay
+	HedgeCopy Copy = "hedge"
+)
+
+// Record holds the outcome of a hedged request to a route
+type Record struct {
+	RouteID string
+	Winner  Copy
+}
+
+// Collector collects the hedged requests to the routes, while the request is being processed,
+// so that they can be logged together with the request
+type Collector struct {
+	mu      sync.Mutex
+	records []*Record
+}
+
+// NewCollector creates a new Collector
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+// Record saves the outcome of a hedged request
+func (c *Collector) Record(record *Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records = append(c.records, record)
+}
+
+// Records returns the hedged requests recorded so far
+func (c *Collector) Records() []*Record {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*Record{}, c.records...)
+}
+
+// WithCollector associates the hedged request collector with the given context object
+func WithCollector(ctx context.Context, collector *Collector) context.Context {
+	return context.WithValue(ctx, turingctx.TuringHedgingCollectorKey, collector)
+}
+
+// GetCollector returns the hedged request collector from the input context
+func GetCollector(ctx context.Context) (*Collector, error) {
+	if ctxValue, ok := ctx.Value(turingctx.TuringHedgingCollectorKey).(*Collector); ok {
+		return ctxValue, nil
+	}
+	return nil, errors.Newf(errors.Unknown, "Hedged request collector not found in the context")
+}
@@ -0,0 +1,32 @@
+package hedging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	collector := NewCollector()
+	assert.Empty(t, collector.Records())
+
+	collector.Record(&Record{RouteID: "route-a", Winner: HedgeCopy})
+	collector.Record(&Record{RouteID: "route-b", Winner: OriginalCopy})
+
+	assert.Equal(t, []*Record{
+		{RouteID: "route-a", Winner: HedgeCopy},
+		{RouteID: "route-b", Winner: OriginalCopy},
+	}, collector.Records())
+}
+
+func TestGetCollector(t *testing.T) {
+	_, err := GetCollector(context.Background())
+	assert.EqualError(t, err, "Hedged request collector not found in the context")
+
+	collector := NewCollector()
+	actual, err := GetCollector(WithCollector(context.Background(), collector))
+	require.NoError(t, err)
+	assert.Same(t, collector, actual)
+}
@@ -14,9 +14,9 @@ func InitMetricsCollector(enabled bool) error {
 		log.Glob().Info("Initializing Prometheus Metrics Collector")
 		// Use the Prometheus Instrumentation Client
 		err := metrics.InitPrometheusMetricsCollector(
-			map[metrics.MetricName]metrics.PrometheusGaugeVec{},
+			instrumentation.GetGaugeMap(),
 			instrumentation.GetHistogramMap(),
-			map[metrics.MetricName]metrics.PrometheusCounterVec{},
+			instrumentation.GetCounterMap(),
 		)
 		if err != nil {
 			return err
@@ -23,6 +23,38 @@ const (
 	RouteRequestDurationMs metrics.MetricName = "route_request_duration_ms"
 	// TuringComponentRequestDurationMs is the key to measure time taken at each Turing Component
 	TuringComponentRequestDurationMs metrics.MetricName = "turing_comp_request_duration_ms"
+	// ResponseCacheRequestsTotal is the key to count the lookups in the router's response cache
+	ResponseCacheRequestsTotal metrics.MetricName = "response_cache_requests_total"
+	// RouteCircuitBreakerState is the key to record the state of the circuit breakers of the Fiber routes
+	RouteCircuitBreakerState metrics.MetricName = "route_circuit_breaker_state"
+	// RouterConfigReloadsTotal is the key to count the hot reloads of the router's Fiber config
+	RouterConfigReloadsTotal metrics.MetricName = "router_config_reloads_total"
+	// BanditRouteSelectionsTotal is the key to count the route selections of the multi-armed bandit
+	BanditRouteSelectionsTotal metrics.MetricName = "bandit_route_selections_total"
+	// BanditRouteRewardsTotal is the key to count the rewards received for the bandit's route selections
+	BanditRouteRewardsTotal metrics.MetricName = "bandit_route_rewards_total"
+	// BanditRouteMeanReward is the key to record the mean reward of the routes of the multi-armed bandit
+	BanditRouteMeanReward metrics.MetricName = "bandit_route_mean_reward"
+	// RouteHedgedRequestsTotal is the key to count the hedged requests to the Fiber routes
+	RouteHedgedRequestsTotal metrics.MetricName = "route_hedged_requests_total"
+	// RateLimitedRequestsTotal is the key to count the requests, that are rejected by the router's admission control
+	RateLimitedRequestsTotal metrics.MetricName = "rate_limited_requests_total"
+	// FallbackResponsesTotal is the key to count the fallback responses, that are served in place of the errors
+	FallbackResponsesTotal metrics.MetricName = "fallback_responses_total"
+	// ResultLogWritesTotal is the key to count the writes of the result logs to each of the router's destinations
+	ResultLogWritesTotal metrics.MetricName = "result_log_writes_total"
+	// ResultLogSpoolDepth is the key to record the number of the result logs in the on-disk spool of each result logger
+	ResultLogSpoolDepth metrics.MetricName = "result_log_spool_depth"
+	// ResultLogSpoolReplayLagSeconds is the key to record the age of the oldest result log in each spool
+	ResultLogSpoolReplayLagSeconds metrics.MetricName = "result_log_spool_replay_lag_seconds"
+	// ResultLogSpoolDroppedTotal is the key to count the result logs, that are dropped by the spools
+	ResultLogSpoolDroppedTotal metrics.MetricName = "result_log_spool_dropped_total"
+	// ResultLogQueueDepth is the key to record the number of the result logs, that are queued to be written
+	ResultLogQueueDepth metrics.MetricName = "result_log_queue_depth"
+	// ResultLogQueueDroppedTotal is the key to count the result logs, that are dropped as the queue is full
+	ResultLogQueueDroppedTotal metrics.MetricName = "result_log_queue_dropped_total"
+	// ResultLogQueueBlockedTotal is the key to count the result logs, that wait for the queue to have room
+	ResultLogQueueBlockedTotal metrics.MetricName = "result_log_queue_blocked_total"
 )
 
 // requestLatencyBuckets defines the buckets used in the custom Histogram metrics defined by Turing
@@ -72,6 +104,152 @@ func GetHistogramMap() map[metrics.MetricName]metrics.PrometheusHistogramVec {
 	return histogramMap
 }
 
+func GetGaugeMap() map[metrics.MetricName]metrics.PrometheusGaugeVec {
+	// gaugeMap maintains a mapping between the metric name and the corresponding gauge vector
+	var gaugeMap = map[metrics.MetricName]metrics.PrometheusGaugeVec{
+		RouteCircuitBreakerState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      string(RouteCircuitBreakerState),
+			Help:      "Gauge for the state of the circuit breaker of the Fiber routes (0: closed, 1: half-open, 2: open).",
+		},
+			[]string{"route"},
+		),
+		BanditRouteMeanReward: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      string(BanditRouteMeanReward),
+			Help:      "Gauge for the mean reward of the routes selected by the multi-armed bandit.",
+		},
+			[]string{"route"},
+		),
+		ResultLogSpoolDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      string(ResultLogSpoolDepth),
+			Help:      "Gauge for the number of the result logs in the on-disk spool, by result logger.",
+		},
+			[]string{"sink"},
+		),
+		ResultLogSpoolReplayLagSeconds: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      string(ResultLogSpoolReplayLagSeconds),
+			Help:      "Gauge for the time (in seconds) since the oldest result log in the spool was spooled.",
+		},
+			[]string{"sink"},
+		),
+		ResultLogQueueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      string(ResultLogQueueDepth),
+			Help:      "Gauge for the number of the result logs, that are queued to be written to the result loggers, by queue.",
+		},
+			[]string{"queue"},
+		),
+	}
+
+	return gaugeMap
+}
+
+func GetCounterMap() map[metrics.MetricName]metrics.PrometheusCounterVec {
+	// counterMap maintains a mapping between the metric name and the corresponding counter vector
+	var counterMap = map[metrics.MetricName]metrics.PrometheusCounterVec{
+		ResponseCacheRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      string(ResponseCacheRequestsTotal),
+			Help:      "Counter for the lookups in the response cache, by their result (hit or miss).",
+		},
+			[]string{"result"},
+		),
+		RouterConfigReloadsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      string(RouterConfigReloadsTotal),
+			Help:      "Counter for the hot reloads of the router's Fiber config, by their source and status.",
+		},
+			[]string{"source", "status"},
+		),
+		BanditRouteSelectionsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      string(BanditRouteSelectionsTotal),
+			Help:      "Counter for the route selections of the multi-armed bandit, by route.",
+		},
+			[]string{"route"},
+		),
+		BanditRouteRewardsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      string(BanditRouteRewardsTotal),
+			Help:      "Counter for the rewards received for the route selections of the multi-armed bandit, by route.",
+		},
+			[]string{"route"},
+		),
+		RouteHedgedRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      string(RouteHedgedRequestsTotal),
+			Help:      "Counter for the hedged requests to the Fiber routes, by route and the copy whose response was used.",
+		},
+			[]string{"route", "winner"},
+		),
+		RateLimitedRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      string(RateLimitedRequestsTotal),
+			Help: "Counter for the requests rejected by the rate limits, by configured client key " +
+				"(or other) and reason (rate or concurrency).",
+		},
+			[]string{"key", "reason"},
+		),
+		FallbackResponsesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      string(FallbackResponsesTotal),
+			Help:      "Counter for the fallback responses served, by traffic rule and the failed component.",
+		},
+			[]string{"traffic_rule", "reason"},
+		),
+		ResultLogWritesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      string(ResultLogWritesTotal),
+			Help:      "Counter for the writes of the result logs, by destination and status.",
+		},
+			[]string{"sink", "status"},
+		),
+		ResultLogSpoolDroppedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      string(ResultLogSpoolDroppedTotal),
+			Help:      "Counter for the result logs, that are dropped by the on-disk spool, by result logger and reason.",
+		},
+			[]string{"sink", "reason"},
+		),
+		ResultLogQueueDroppedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      string(ResultLogQueueDroppedTotal),
+			Help: "Counter for the result logs, that are dropped as the result log queue is full, " +
+				"by queue and overflow policy.",
+		},
+			[]string{"queue", "policy"},
+		),
+		ResultLogQueueBlockedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      string(ResultLogQueueBlockedTotal),
+			Help:      "Counter for the result logs, that wait for the result log queue to have room, by queue.",
+		},
+			[]string{"queue"},
+		),
+	}
+
+	return counterMap
+}
+
 //////////////////////////// MetricsRegistrationHelper Definitions //////////////////////////////
 
 type MetricType string
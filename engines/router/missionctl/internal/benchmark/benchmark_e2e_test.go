@@ -61,16 +61,21 @@ func runTuringUpiGRPCServer(port int) {
 	mc, err := missionctl.NewMissionControlUPI(
 		singleUpiGRPCRouteServer,
 		false,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 	)
 	if err != nil {
 		log.Glob().Panicf("failed to create mc: %v", err.Error())
 	}
-	rl := resultlog.InitTuringResultLogger("", resultlog.NewNopLogger())
-	upiResultLogger, err := resultlog.InitUPIResultLogger("route-name-3.project", config.NopLogger, nil, rl)
+	rl := resultlog.InitTuringResultLogger("", resultlog.NewNopLogger(), nil)
+	upiResultLogger, err := resultlog.InitUPIResultLogger("route-name-3.project", nil, rl, nil)
 	if err != nil {
 		log.Glob().Panicf("failed to create upi result logger: %v", err.Error())
 	}
-	upiServer := upi.NewUPIServer(mc, upiResultLogger)
+	upiServer := upi.NewUPIServer(mc, upiResultLogger, false, nil, nil)
 	go upiServer.Run(l)
 }
 
@@ -95,12 +100,13 @@ func runTuringUpiHTTPServer(port int) {
 		testCfg.RouterConfig,
 		testCfg.EnsemblerConfig,
 		testCfg.AppConfig,
+		nil,
 	)
 	if err != nil {
 		log.Glob().Fatalf("fail to create mission control: %v", err.Error())
 	}
-	rl := resultlog.InitTuringResultLogger("", resultlog.NewNopLogger())
-	http.Handle("/v1/predict", handlers.NewHTTPHandler(mc, rl))
+	rl := resultlog.InitTuringResultLogger("", resultlog.NewNopLogger(), nil)
+	http.Handle("/v1/predict", handlers.NewHTTPHandler(mc, rl, false))
 	go func() {
 		if err := http.ListenAndServe(fmt.Sprintf(":%d", testCfg.Port), http.DefaultServeMux); err != nil {
 			log.Glob().Fatalf("failed to serve: %s", err)
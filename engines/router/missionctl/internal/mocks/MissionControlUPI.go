@@ -9,7 +9,15 @@ import (
 
 	errors "github.com/caraml-dev/turing/engines/router/missionctl/errors"
 
+	fallback "github.com/caraml-dev/turing/engines/router/missionctl/fallback"
+
+	metadata "google.golang.org/grpc/metadata"
+
 	mock "github.com/stretchr/testify/mock"
+
+	router "github.com/caraml-dev/turing/engines/router"
+
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
 )
 
 // MissionControlUPI is an autogenerated mock type for the MissionControlUPI type
@@ -17,6 +25,196 @@ type MissionControlUPI struct {
 	mock.Mock
 }
 
+// CacheResponse provides a mock function with given fields: key, resp
+func (_m *MissionControlUPI) CacheResponse(key string, resp *upiv1.PredictValuesResponse) {
+	_m.Called(key, resp)
+}
+
+// Enrich provides a mock function with given fields: ctx, req, md
+func (_m *MissionControlUPI) Enrich(ctx context.Context, req *upiv1.PredictValuesRequest, md metadata.MD) (*upiv1.PredictValuesResponse, metadata.MD, *errors.TuringError) {
+	ret := _m.Called(ctx, req, md)
+
+	var r0 *upiv1.PredictValuesResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *upiv1.PredictValuesRequest, metadata.MD) *upiv1.PredictValuesResponse); ok {
+		r0 = rf(ctx, req, md)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*upiv1.PredictValuesResponse)
+		}
+	}
+
+	var r1 metadata.MD
+	if rf, ok := ret.Get(1).(func(context.Context, *upiv1.PredictValuesRequest, metadata.MD) metadata.MD); ok {
+		r1 = rf(ctx, req, md)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(metadata.MD)
+		}
+	}
+
+	var r2 *errors.TuringError
+	if rf, ok := ret.Get(2).(func(context.Context, *upiv1.PredictValuesRequest, metadata.MD) *errors.TuringError); ok {
+		r2 = rf(ctx, req, md)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(*errors.TuringError)
+		}
+	}
+
+	return r0, r1, r2
+}
+
+// Ensemble provides a mock function with given fields: ctx, req, routerResp, md
+func (_m *MissionControlUPI) Ensemble(ctx context.Context, req *upiv1.PredictValuesRequest, routerResp *upiv1.PredictValuesResponse, md metadata.MD) (*upiv1.PredictValuesResponse, metadata.MD, *errors.TuringError) {
+	ret := _m.Called(ctx, req, routerResp, md)
+
+	var r0 *upiv1.PredictValuesResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *upiv1.PredictValuesRequest, *upiv1.PredictValuesResponse, metadata.MD) *upiv1.PredictValuesResponse); ok {
+		r0 = rf(ctx, req, routerResp, md)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*upiv1.PredictValuesResponse)
+		}
+	}
+
+	var r1 metadata.MD
+	if rf, ok := ret.Get(1).(func(context.Context, *upiv1.PredictValuesRequest, *upiv1.PredictValuesResponse, metadata.MD) metadata.MD); ok {
+		r1 = rf(ctx, req, routerResp, md)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(metadata.MD)
+		}
+	}
+
+	var r2 *errors.TuringError
+	if rf, ok := ret.Get(2).(func(context.Context, *upiv1.PredictValuesRequest, *upiv1.PredictValuesResponse, metadata.MD) *errors.TuringError); ok {
+		r2 = rf(ctx, req, routerResp, md)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(*errors.TuringError)
+		}
+	}
+
+	return r0, r1, r2
+}
+
+// GetCachedResponse provides a mock function with given fields: req, md
+func (_m *MissionControlUPI) GetCachedResponse(req *upiv1.PredictValuesRequest, md metadata.MD) (string, *upiv1.PredictValuesResponse) {
+	ret := _m.Called(req, md)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(*upiv1.PredictValuesRequest, metadata.MD) string); ok {
+		r0 = rf(req, md)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 *upiv1.PredictValuesResponse
+	if rf, ok := ret.Get(1).(func(*upiv1.PredictValuesRequest, metadata.MD) *upiv1.PredictValuesResponse); ok {
+		r1 = rf(req, md)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*upiv1.PredictValuesResponse)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetFallbackResponse provides a mock function with given fields: trafficRule, reason
+func (_m *MissionControlUPI) GetFallbackResponse(trafficRule string, reason fallback.Reason) *upiv1.PredictValuesResponse {
+	ret := _m.Called(trafficRule, reason)
+
+	var r0 *upiv1.PredictValuesResponse
+	if rf, ok := ret.Get(0).(func(string, fallback.Reason) *upiv1.PredictValuesResponse); ok {
+		r0 = rf(trafficRule, reason)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*upiv1.PredictValuesResponse)
+		}
+	}
+
+	return r0
+}
+
+// GetProcessingStages provides a mock function with given fields: phase
+func (_m *MissionControlUPI) GetProcessingStages(phase router.StagePhase) router.ProcessingStages {
+	ret := _m.Called(phase)
+
+	var r0 router.ProcessingStages
+	if rf, ok := ret.Get(0).(func(router.StagePhase) router.ProcessingStages); ok {
+		r0 = rf(phase)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(router.ProcessingStages)
+		}
+	}
+
+	return r0
+}
+
+// IsEnricherEnabled provides a mock function with given fields:
+func (_m *MissionControlUPI) IsEnricherEnabled() bool {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// IsEnsemblerEnabled provides a mock function with given fields:
+func (_m *MissionControlUPI) IsEnsemblerEnabled() bool {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// Process provides a mock function with given fields: ctx, stage, req, md
+func (_m *MissionControlUPI) Process(ctx context.Context, stage *router.ProcessingStage, req *upiv1.PredictValuesRequest, md metadata.MD) (*upiv1.PredictValuesResponse, metadata.MD, *errors.TuringError) {
+	ret := _m.Called(ctx, stage, req, md)
+
+	var r0 *upiv1.PredictValuesResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *router.ProcessingStage, *upiv1.PredictValuesRequest, metadata.MD) *upiv1.PredictValuesResponse); ok {
+		r0 = rf(ctx, stage, req, md)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*upiv1.PredictValuesResponse)
+		}
+	}
+
+	var r1 metadata.MD
+	if rf, ok := ret.Get(1).(func(context.Context, *router.ProcessingStage, *upiv1.PredictValuesRequest, metadata.MD) metadata.MD); ok {
+		r1 = rf(ctx, stage, req, md)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(metadata.MD)
+		}
+	}
+
+	var r2 *errors.TuringError
+	if rf, ok := ret.Get(2).(func(context.Context, *router.ProcessingStage, *upiv1.PredictValuesRequest, metadata.MD) *errors.TuringError); ok {
+		r2 = rf(ctx, stage, req, md)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(*errors.TuringError)
+		}
+	}
+
+	return r0, r1, r2
+}
+
 // Route provides a mock function with given fields: _a0, _a1
 func (_m *MissionControlUPI) Route(_a0 context.Context, _a1 fiber.Request) (fiber.Response, *errors.TuringError) {
 	ret := _m.Called(_a0, _a1)
@@ -41,3 +239,8 @@ func (_m *MissionControlUPI) Route(_a0 context.Context, _a1 fiber.Request) (fibe
 
 	return r0, r1
 }
+
+// SetFiberRouter provides a mock function with given fields: router
+func (_m *MissionControlUPI) SetFiberRouter(router fiber.Component) {
+	_m.Called(router)
+}
@@ -42,8 +42,8 @@ func (e *bqLogEntry) Save() (map[string]bigquery.Value, string, error) {
 		return kvPairs, "", errors.Wrapf(err, "Error unmarshaling the result log for save to BQ")
 	}
 
-	// Special handling: Update request, experiment, enricher, router and ensembler headers to a list of records,
-	// expected by BQ.
+	// Special handling: Update request, experiment, enricher, router, ensembler and processing stage headers
+	// to a list of records, expected by BQ.
 	// It seems protobq.Marshal will be adding support for map[string]string that would help simplify the
 	// implementation of Save().
 	kvPairs["request"] = bigquery.Value(map[string]interface{}{
@@ -54,6 +54,42 @@ func (e *bqLogEntry) Save() (map[string]bigquery.Value, string, error) {
 	kvPairs["enricher"] = formatBQLogEntryResponse(e.resultLog.Enricher)
 	kvPairs["router"] = formatBQLogEntryResponse(e.resultLog.Router)
 	kvPairs["ensembler"] = formatBQLogEntryResponse(e.resultLog.Ensembler)
+	if len(e.resultLog.Shadow) > 0 {
+		shadows := []map[string]interface{}{}
+		for _, shadow := range e.resultLog.Shadow {
+			shadows = append(shadows, map[string]interface{}{
+				"route_id":   shadow.RouteId,
+				"response":   formatBQLogEntryResponse(shadow.Response),
+				"latency_ms": shadow.LatencyMs,
+			})
+		}
+		kvPairs["shadow"] = bigquery.Value(shadows)
+	}
+	if len(e.resultLog.Stages) > 0 {
+		stages := []map[string]interface{}{}
+		for _, stage := range e.resultLog.Stages {
+			stages = append(stages, map[string]interface{}{
+				"key":      stage.Key,
+				"response": formatBQLogEntryResponse(stage.Response),
+			})
+		}
+		kvPairs["stages"] = bigquery.Value(stages)
+	}
+	// The timings are also formatted manually, so that the status code of the successful UPI calls, which
+	// is 0, is not omitted
+	if len(e.resultLog.Timings) > 0 {
+		timings := []map[string]interface{}{}
+		for _, timing := range e.resultLog.Timings {
+			timings = append(timings, map[string]interface{}{
+				"component":   timing.Component,
+				"route_id":    timing.RouteId,
+				"start_time":  timing.StartTime.AsTime(),
+				"duration_ms": timing.DurationMs,
+				"status_code": timing.StatusCode,
+			})
+		}
+		kvPairs["timings"] = bigquery.Value(timings)
+	}
 
 	return kvPairs, "", nil
 }
@@ -145,6 +181,25 @@ func (l *bigQueryLogger) write(t *turing.TuringResultLogMessage) error {
 	return nil
 }
 
+// writeBatch inserts the messages with a single streaming insert. The batches should be small enough
+// for the 10MB limit of the requests.
+func (l *bigQueryLogger) writeBatch(messages []*turing.TuringResultLogMessage) error {
+	ins := l.bqClient.Dataset(l.dataset).Table(l.table).Inserter()
+	items := make([]*bqLogEntry, 0, len(messages))
+	for _, message := range messages {
+		items = append(items, &bqLogEntry{message})
+	}
+	if err := ins.Put(context.Background(), items); err != nil {
+		return errors.Wrapf(err, "Error during streaming insert")
+	}
+	return nil
+}
+
+// Close closes the BigQuery client
+func (l *bigQueryLogger) Close() error {
+	return l.bqClient.Close()
+}
+
 // getLogData returns the log information as a generic interface{} object. Internally, it calls
 // the Save method defined on the bqLogEntry structure which implements the
 // bigquery.ValueSaver interface and returns the log data as a map. This can be returned
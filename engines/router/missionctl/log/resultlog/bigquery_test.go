@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	tu "github.com/caraml-dev/turing/engines/router/missionctl/internal/testutils"
+	"github.com/caraml-dev/turing/engines/router/missionctl/timing"
 	"github.com/caraml-dev/turing/engines/router/missionctl/turingctx"
 )
 
@@ -121,6 +122,15 @@ func TestBigQueryLoggerGetData(t *testing.T) {
 	AddResponse(entry, "enricher", `{"key": "enricher_data"}`, nil, "")
 	AddResponse(entry, "router", `{"key": "router_data"}`, nil, "")
 	AddResponse(entry, "ensembler", "", nil, "Error Response")
+	AddShadowResponse(entry, "shadow-route", `{"key": "shadow_data"}`, nil, "", 25*time.Millisecond)
+	AddResponse(entry, "stage_a", `{"key": "stage_data"}`, map[string]string{"Content-Type": "application/json"}, "")
+	addTimings(entry, &Timings{
+		Records: []*timing.Record{
+			{Component: "router", StartTime: timestamp, Duration: 1500 * time.Microsecond, StatusCode: 0},
+		},
+		RouteID:     "route-a",
+		TrafficRule: "rule-1",
+	})
 
 	// Get the log data and validate
 	logData := testLogger.getLogData(entry)
@@ -179,6 +189,43 @@ func TestBigQueryLoggerGetData(t *testing.T) {
 		} else {
 			tu.FailOnError(t, fmt.Errorf("Cannot cast ensembler log to expected type"))
 		}
+
+		// Shadow
+		if shadows, ok := logMap["shadow"].([]map[string]interface{}); ok && len(shadows) == 1 {
+			assert.Equal(t, "shadow-route", shadows[0]["route_id"])
+			assert.Equal(t, int64(25), shadows[0]["latency_ms"])
+			if respObj, ok := shadows[0]["response"].(map[string]interface{}); ok {
+				assert.Equal(t, `{"key": "shadow_data"}`, respObj["response"])
+			} else {
+				tu.FailOnError(t, fmt.Errorf("Cannot cast shadow response log to expected type"))
+			}
+		} else {
+			tu.FailOnError(t, fmt.Errorf("Cannot cast shadow log to expected type"))
+		}
+
+		// Processing stages
+		assert.Equal(t, []map[string]interface{}{
+			{
+				"key": "stage_a",
+				"response": bigquery.Value(map[string]interface{}{
+					"header":   []map[string]interface{}{{"key": "Content-Type", "value": "application/json"}},
+					"response": `{"key": "stage_data"}`,
+				}),
+			},
+		}, logMap["stages"])
+
+		// Timings
+		assert.Equal(t, []map[string]interface{}{
+			{
+				"component":   "router",
+				"route_id":    "",
+				"start_time":  timestamp,
+				"duration_ms": 1.5,
+				"status_code": int32(0),
+			},
+		}, logMap["timings"])
+		assert.Equal(t, "route-a", logMap["route_id"])
+		assert.Equal(t, "rule-1", logMap["traffic_rule"])
 	} else {
 		tu.FailOnError(t, fmt.Errorf("Cannot cast log result to expected type"))
 	}
@@ -46,3 +46,8 @@ func (*ConsoleLogger) write(turLogEntry *turing.TuringResultLogMessage) error {
 	logger.Infow("Turing Request Summary", data...)
 	return nil
 }
+
+// Close is a nop method, as the console logs are synced with the global logger
+func (*ConsoleLogger) Close() error {
+	return nil
+}
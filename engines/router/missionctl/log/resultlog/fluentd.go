@@ -16,6 +16,7 @@ import (
 // the result log to a fluentd server (useful for mocking in tests).
 type fluentdClient interface {
 	Post(string, interface{}) error
+	Close() error
 }
 
 // FluentdLogger generates instances of FluentdLog for posting results to a
@@ -72,3 +73,12 @@ func (l *FluentdLogger) write(turLogEntry *turing.TuringResultLogMessage) error
 	err = l.fluentLogger.Post(l.tag, l.bqLogger.getLogData(turLogEntry))
 	return err
 }
+
+// Close flushes the buffered logs to the fluentd server, and closes the fluentd client and the
+// BigQueryLogger
+func (l *FluentdLogger) Close() error {
+	if err := l.fluentLogger.Close(); err != nil {
+		return errors.Wrapf(err, "Failed to close the Fluentd client")
+	}
+	return l.bqLogger.Close()
+}
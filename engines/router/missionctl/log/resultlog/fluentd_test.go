@@ -24,6 +24,10 @@ func (mf *MockFluentClient) Post(tag string, msg interface{}) error {
 	return nil
 }
 
+func (mf *MockFluentClient) Close() error {
+	return mf.Called().Error(0)
+}
+
 // MockBqLogger implements the BigQueryLogger interface
 type MockBqLogger struct {
 	mock.Mock
@@ -33,6 +37,10 @@ func (bq *MockBqLogger) write(*turing.TuringResultLogMessage) error {
 	return nil
 }
 
+func (bq *MockBqLogger) Close() error {
+	return bq.Called().Error(0)
+}
+
 func (bq *MockBqLogger) getLogData(t *turing.TuringResultLogMessage) interface{} {
 	bq.Called(t)
 	return t
@@ -121,3 +129,20 @@ func TestFuentdLoggerWrite(t *testing.T) {
 	bqLogger.AssertCalled(t, "getLogData", entry)
 	fluentClient.AssertCalled(t, "Post", "test-tag", entry)
 }
+
+func TestFluentdLoggerClose(t *testing.T) {
+	bqLogger := &MockBqLogger{}
+	bqLogger.On("Close").Return(nil)
+	fluentClient := &MockFluentClient{}
+	fluentClient.On("Close").Return(nil)
+
+	testLogger := &FluentdLogger{
+		tag:          "test-tag",
+		bqLogger:     bqLogger,
+		fluentLogger: fluentClient,
+	}
+
+	assert.NoError(t, testLogger.Close())
+	fluentClient.AssertCalled(t, "Close")
+	bqLogger.AssertCalled(t, "Close")
+}
@@ -15,6 +15,7 @@ import (
 
 const (
 	kafkaConnectTimeoutMs = 1000
+	kafkaFlushTimeoutMs   = 5000
 )
 
 // kafkaProducer minimally defines the functionality used by the KafkaLogger,
@@ -22,6 +23,8 @@ const (
 type kafkaProducer interface {
 	GetMetadata(*string, bool, int) (*kafka.Metadata, error)
 	Produce(*kafka.Message, chan kafka.Event) error
+	Flush(int) int
+	Close()
 }
 
 // KafkaLogger logs the result log data to the configured Kafka topic
@@ -65,13 +68,26 @@ func newKafkaProducer(cfg *config.KafkaConfig) (kafkaProducer, error) {
 	return producer, err
 }
 
+// kafkaLogEntry is a message to be written to the Kafka topic, with the fields of its key
+type kafkaLogEntry struct {
+	message     proto.Message
+	turingReqID string
+	timestamp   *timestamppb.Timestamp
+}
+
 func (l *KafkaLogger) writeToKafka(
 	message proto.Message,
 	turingReqID string,
 	timestamp *timestamppb.Timestamp) error {
+	return l.writeBatchToKafka([]kafkaLogEntry{{message: message, turingReqID: turingReqID, timestamp: timestamp}})
+}
+
+// writeBatchToKafka produces the messages of the given entries at once, and waits for all of them
+// to be delivered
+func (l *KafkaLogger) writeBatchToKafka(entries []kafkaLogEntry) error {
 	var err error
 
-	// Measure time taken to marshal the data and write the log to the kafka topic
+	// Measure time taken to marshal the data and write the logs to the kafka topic
 	defer metrics.Glob().MeasureDurationMs(
 		instrumentation.TuringComponentRequestDurationMs,
 		map[string]func() string{
@@ -85,48 +101,85 @@ func (l *KafkaLogger) writeToKafka(
 		},
 	)()
 
-	// Format Kafka Message
+	// Produce Messages. The delivery channel is buffered for all of the messages, and is only closed
+	// once all of the produced messages are delivered.
+	deliveryChan := make(chan kafka.Event, len(entries))
+	defer close(deliveryChan)
+	produced := 0
+	for _, entry := range entries {
+		var msg *kafka.Message
+		msg, err = l.newKafkaMessage(entry)
+		if err != nil {
+			break
+		}
+		if err = l.producer.Produce(msg, deliveryChan); err != nil {
+			break
+		}
+		produced++
+	}
+
+	// Get delivery responses
+	var failed int
+	var deliveryErr error
+	for i := 0; i < produced; i++ {
+		event := <-deliveryChan
+		msg := event.(*kafka.Message)
+		if msg.TopicPartition.Error != nil {
+			failed++
+			deliveryErr = msg.TopicPartition.Error
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		if len(entries) == 1 {
+			err = errors.Newf(errors.BadResponse, "Delivery failed: %v\n", deliveryErr)
+		} else {
+			err = errors.Newf(errors.BadResponse,
+				"Delivery failed for %d of %d messages: %v", failed, len(entries), deliveryErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// newKafkaMessage formats the Kafka message of the given entry, in the configured serialization format
+func (l *KafkaLogger) newKafkaMessage(entry kafkaLogEntry) (*kafka.Message, error) {
 	var keyBytes, valueBytes []byte
+	var err error
 	if l.serializationFormat == config.JSONSerializationFormat {
-		valueBytes, err = newJSONKafkaLogEntry(message)
+		valueBytes, err = newJSONKafkaLogEntry(entry.message)
 	} else if l.serializationFormat == config.ProtobufSerializationFormat {
 		keyBytes, valueBytes, err = newProtobufKafkaLogEntry(
-			message,
-			turingReqID,
-			timestamp)
+			entry.message,
+			entry.turingReqID,
+			entry.timestamp)
 	} else {
 		// Unknown format, we wouldn't hit this since the config is checked at initialization,
 		// but handle it.
-		return errors.Newf(errors.BadConfig, "Unknown Serialization format %s", l.serializationFormat)
+		return nil, errors.Newf(errors.BadConfig, "Unknown Serialization format %s", l.serializationFormat)
 	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	// Produce Message
-	deliveryChan := make(chan kafka.Event, 1)
-	defer close(deliveryChan)
-	err = l.producer.Produce(&kafka.Message{
+	return &kafka.Message{
 		TopicPartition: kafka.TopicPartition{
 			Topic:     &l.topic,
 			Partition: kafka.PartitionAny},
 		Value: valueBytes,
 		Key:   keyBytes,
-	}, deliveryChan)
-
-	if err != nil {
-		return err
-	}
+	}, nil
+}
 
-	// Get delivery response
-	event := <-deliveryChan
-	msg := event.(*kafka.Message)
-	if msg.TopicPartition.Error != nil {
-		err = errors.Newf(errors.BadResponse,
-			"Delivery failed: %v\n", msg.TopicPartition.Error)
-		return err
+// Close waits for the outstanding messages to be delivered, and closes the Kafka producer
+func (l *KafkaLogger) Close() error {
+	remaining := l.producer.Flush(kafkaFlushTimeoutMs)
+	l.producer.Close()
+	if remaining > 0 {
+		return errors.Newf(errors.BadResponse, "Failed to deliver %d message(s) to Kafka", remaining)
 	}
-
 	return nil
 }
 
@@ -138,6 +191,20 @@ func (l *KafkaLogger) write(turLogEntry *turing.TuringResultLogMessage) error {
 	)
 }
 
+// writeBatch produces the messages to the Kafka topic at once, rather than waiting for the delivery
+// of each message in turn
+func (l *KafkaLogger) writeBatch(messages []*turing.TuringResultLogMessage) error {
+	entries := make([]kafkaLogEntry, 0, len(messages))
+	for _, message := range messages {
+		entries = append(entries, kafkaLogEntry{
+			message:     message,
+			turingReqID: message.TuringReqId,
+			timestamp:   message.EventTimestamp,
+		})
+	}
+	return l.writeBatchToKafka(entries)
+}
+
 // newJSONKafkaLogEntry converts a given TuringResultLogEntry to  bytes, for writing to a Kafka topic
 // in JSON format
 func newJSONKafkaLogEntry(message proto.Message) (messageBytes []byte, err error) {
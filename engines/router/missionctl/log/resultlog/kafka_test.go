@@ -38,6 +38,14 @@ func (mp *mockKafkaProducer) Produce(msg *kafka.Message, deliveryChan chan kafka
 	return nil
 }
 
+func (mp *mockKafkaProducer) Flush(timeoutMs int) int {
+	return mp.Called(timeoutMs).Int(0)
+}
+
+func (mp *mockKafkaProducer) Close() {
+	mp.Called()
+}
+
 func TestNewKafkaProducer(t *testing.T) {
 	// Patch the kafka.NewProducer method to validate input
 	cfg := &config.KafkaConfig{
@@ -180,3 +188,53 @@ func TestKafkaLoggerWrite(t *testing.T) {
 	assert.NoError(t, err)
 	mp.AssertCalled(t, "Produce", expectedMessage, mock.Anything)
 }
+
+func TestKafkaLoggerWriteBatch(t *testing.T) {
+	mp := &mockKafkaProducer{}
+	logger := &KafkaLogger{
+		serializationFormat: "json",
+		topic:               "test-topic",
+		producer:            mp,
+	}
+	mp.On("Produce", mock.Anything, mock.Anything).Return(nil)
+
+	// The messages are produced at once, before their deliveries are awaited
+	err := logger.writeBatch([]*turing.TuringResultLogMessage{{TuringReqId: "1"}, {TuringReqId: "2"}})
+	assert.NoError(t, err)
+	mp.AssertNumberOfCalls(t, "Produce", 2)
+	for i, id := range []string{"1", "2"} {
+		msg := mp.Calls[i].Arguments.Get(0).(*kafka.Message)
+		assert.JSONEq(t, fmt.Sprintf(`{"turing_req_id":"%s"}`, id), string(msg.Value))
+	}
+}
+
+func TestKafkaLoggerClose(t *testing.T) {
+	tests := map[string]struct {
+		remaining int
+		err       string
+	}{
+		"success": {},
+		"failure | undelivered messages": {
+			remaining: 2,
+			err:       "Failed to deliver 2 message(s) to Kafka",
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			mp := &mockKafkaProducer{}
+			mp.On("Flush", kafkaFlushTimeoutMs).Return(data.remaining)
+			mp.On("Close").Return()
+			logger := &KafkaLogger{producer: mp}
+
+			err := logger.Close()
+			if data.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, data.err)
+			}
+			// The producer is closed, even if some messages were not delivered
+			mp.AssertCalled(t, "Close")
+		})
+	}
+}
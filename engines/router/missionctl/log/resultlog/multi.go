@@ -0,0 +1,76 @@
+package resultlog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog/proto/turing"
+)
+
+// ResultLogSink is a destination of the MultiLogger, identified by its result logger type
+type ResultLogSink struct {
+	Type   config.ResultLogger
+	Logger TuringResultLogger
+}
+
+// MultiLogger writes each TuringResultLogMessage to several result loggers. The sinks are isolated
+// from each other, i.e. the failure of one sink doesn't prevent the message from being written to
+// the others.
+type MultiLogger struct {
+	sinks []ResultLogSink
+}
+
+// NewMultiLogger creates a new MultiLogger, that writes to the given sinks
+func NewMultiLogger(sinks ...ResultLogSink) *MultiLogger {
+	return &MultiLogger{sinks: sinks}
+}
+
+// write writes the message to each of the sinks, and records the result in the result log writes
+// metric. It returns an error, that lists the failed sinks, if any.
+func (l *MultiLogger) write(message *turing.TuringResultLogMessage) error {
+	return l.writeBatch([]*turing.TuringResultLogMessage{message})
+}
+
+// writeBatch writes the messages to each of the sinks, at once if the sink supports batches, and
+// records the results in the result log writes metric
+func (l *MultiLogger) writeBatch(messages []*turing.TuringResultLogMessage) error {
+	var failures []string
+	for _, sink := range l.sinks {
+		err := writeBatch(sink.Logger, messages)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", sink.Type, err.Error()))
+		}
+		labels := map[string]string{"sink": string(sink.Type), "status": metrics.GetStatusString(err == nil)}
+		for range messages {
+			if metricErr := metrics.Glob().Inc(instrumentation.ResultLogWritesTotal, labels); metricErr != nil {
+				log.Glob().Errorf("Failed to record the result log write to %s: %s", sink.Type, metricErr.Error())
+				break
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return errors.Newf(errors.Unknown, "Failed to write to %d of %d result loggers: %s",
+			len(failures), len(l.sinks), strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// Close closes each of the sinks, regardless of whether the others fail to close
+func (l *MultiLogger) Close() error {
+	var failures []string
+	for _, sink := range l.sinks {
+		if err := sink.Logger.Close(); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", sink.Type, err.Error()))
+		}
+	}
+	if len(failures) > 0 {
+		return errors.Newf(errors.Unknown, "Failed to close the result loggers: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
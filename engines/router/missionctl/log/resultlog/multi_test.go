@@ -0,0 +1,57 @@
+package resultlog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog/proto/turing"
+)
+
+// failingResultLogger satisfies the TuringResultLogger interface, and fails to write and close
+type failingResultLogger struct{}
+
+func (*failingResultLogger) write(_ *turing.TuringResultLogMessage) error {
+	return errors.New("sink unavailable")
+}
+
+func (*failingResultLogger) Close() error {
+	return errors.New("flush failed")
+}
+
+func TestMultiLoggerWrite(t *testing.T) {
+	_, message := makeTestTuringResultLog(t)
+	kafkaLogger, bqLogger := &mockResultLogger{}, &mockResultLogger{}
+	logger := NewMultiLogger(
+		ResultLogSink{Type: config.KafkaLogger, Logger: kafkaLogger},
+		ResultLogSink{Type: config.BigqueryLogger, Logger: bqLogger},
+	)
+
+	assert.NoError(t, logger.write(message))
+	assert.Equal(t, message, kafkaLogger.result)
+	assert.Equal(t, message, bqLogger.result)
+
+	assert.NoError(t, logger.Close())
+	assert.True(t, kafkaLogger.closed)
+	assert.True(t, bqLogger.closed)
+}
+
+func TestMultiLoggerSinkIsolation(t *testing.T) {
+	_, message := makeTestTuringResultLog(t)
+	bqLogger := &mockResultLogger{}
+	logger := NewMultiLogger(
+		ResultLogSink{Type: config.KafkaLogger, Logger: &failingResultLogger{}},
+		ResultLogSink{Type: config.BigqueryLogger, Logger: bqLogger},
+	)
+
+	// The message is still written to the other sinks, if one of them fails
+	err := logger.write(message)
+	assert.EqualError(t, err, "Failed to write to 1 of 2 result loggers: KAFKA: sink unavailable")
+	assert.Equal(t, message, bqLogger.result)
+
+	err = logger.Close()
+	assert.EqualError(t, err, "Failed to close the result loggers: KAFKA: flush failed")
+	assert.True(t, bqLogger.closed)
+}
@@ -14,3 +14,8 @@ func NewNopLogger() *NopLogger {
 func (*NopLogger) write(_ *turing.TuringResultLogMessage) error {
 	return nil
 }
+
+// Close is a nop method that satisfies the TuringResultLogger interface
+func (*NopLogger) Close() error {
+	return nil
+}
@@ -0,0 +1,336 @@
+package resultlog
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog/proto/turing"
+)
+
+// maskedSuffixLength is the number of the trailing characters, that are kept by the mask redaction
+const maskedSuffixLength = 4
+
+// Policy samples the requests, which results are logged, and redacts the fields of the result logs,
+// before they are written to the result loggers. A nil Policy logs all of the results as they are.
+type Policy struct {
+	samplingRate float64
+	samplingUnit *config.ResultLogSamplingUnit
+	// headerRedactions are the redactions of the headers, keyed by the lower-cased header name
+	headerRedactions map[string]config.RedactionAction
+	// payloadRedactions are the redactions of the bodies, with their JSON paths split into keys
+	payloadRedactions []payloadRedaction
+	// hashKey is the secret key of the HMAC of the hashed fields
+	hashKey []byte
+}
+
+type payloadRedaction struct {
+	path   []string
+	action config.RedactionAction
+}
+
+// NewPolicy creates the result log Policy from the given config, reading the hash key from its file,
+// if configured. It returns nil, if the config neither samples nor redacts the result logs.
+func NewPolicy(cfg *config.ResultLogPolicy) (*Policy, error) {
+	if cfg == nil || ((cfg.SamplingRate == nil || *cfg.SamplingRate >= 1) && len(cfg.Redactions) == 0) {
+		return nil, nil
+	}
+
+	policy := &Policy{
+		samplingRate:     1,
+		samplingUnit:     cfg.SamplingUnit,
+		headerRedactions: map[string]config.RedactionAction{},
+	}
+	if cfg.SamplingRate != nil {
+		policy.samplingRate = *cfg.SamplingRate
+	}
+	if cfg.HashKeyFile != "" {
+		key, err := os.ReadFile(cfg.HashKeyFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to read the result log hash key file")
+		}
+		// The trailing newline of the key file is not part of the key
+		policy.hashKey = bytes.TrimSpace(key)
+		if len(policy.hashKey) == 0 {
+			return nil, errors.Newf(errors.BadConfig, "Empty result log hash key")
+		}
+	}
+	for _, redaction := range cfg.Redactions {
+		switch redaction.FieldSource {
+		case request.HeaderFieldSource:
+			policy.headerRedactions[strings.ToLower(redaction.Field)] = redaction.Action
+		case request.PayloadFieldSource:
+			policy.payloadRedactions = append(policy.payloadRedactions, payloadRedaction{
+				path:   strings.Split(redaction.Field, "."),
+				action: redaction.Action,
+			})
+		}
+	}
+	return policy, nil
+}
+
+// SampleHTTPRequest returns whether the result of the given HTTP request should be logged
+func (p *Policy) SampleHTTPRequest(header http.Header, body []byte) bool {
+	if p == nil || p.samplingRate >= 1 {
+		return true
+	}
+	if p.samplingUnit == nil {
+		return p.sample("", false)
+	}
+	unit, err := request.GetValueFromHTTPRequest(header, body, p.samplingUnit.FieldSource, p.samplingUnit.Field)
+	return p.sample(unit, err == nil)
+}
+
+// SampleUPIRequest returns whether the result of the given UPI request should be logged
+func (p *Policy) SampleUPIRequest(header metadata.MD, req *upiv1.PredictValuesRequest) bool {
+	if p == nil || p.samplingRate >= 1 {
+		return true
+	}
+	if p.samplingUnit == nil {
+		return p.sample("", false)
+	}
+	unit, err := request.GetValueFromUPIRequest(header, req, p.samplingUnit.FieldSource, p.samplingUnit.Field)
+	return p.sample(unit, err == nil)
+}
+
+// sample samples the request by the hash of its unit, so that the requests with the same unit are
+// either all logged or not, or at random if the request doesn't have a unit
+func (p *Policy) sample(unit string, found bool) bool {
+	if !found {
+		return rand.Float64() < p.samplingRate
+	}
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(unit))
+	return float64(hash.Sum64())/math.MaxUint64 < p.samplingRate
+}
+
+// RedactTuringResultLog redacts the headers and the bodies of the request and the responses, in the
+// given TuringResultLogMessage
+func (p *Policy) RedactTuringResultLog(message *turing.TuringResultLogMessage) {
+	if p == nil {
+		return
+	}
+	if message.Request != nil {
+		p.redactHeader(message.Request.Header)
+		message.Request.Body = p.redactBody(message.Request.Body)
+	}
+	for _, resp := range []*turing.Response{message.Experiment, message.Enricher, message.Router, message.Ensembler} {
+		p.redactResponse(resp)
+	}
+	for _, stage := range message.Stages {
+		p.redactResponse(stage.Response)
+	}
+	for _, shadowResp := range message.Shadow {
+		p.redactResponse(shadowResp.Response)
+	}
+}
+
+// RedactRouterLog redacts the headers, the tables and the variables of the router input and output,
+// in the given RouterLog. The JSON paths of the payload redactions are relative to the JSON
+// representation of the RouterInput and the RouterOutput. It returns an error, if the redacted
+// router input or output is no longer valid, in which case the RouterLog should not be written.
+func (p *Policy) RedactRouterLog(routerLog *upiv1.RouterLog) error {
+	if p == nil {
+		return nil
+	}
+	if routerLog.RouterInput != nil {
+		routerLog.RouterInput.Headers = p.redactUPIHeaders(routerLog.RouterInput.Headers)
+		if err := p.redactMessage(routerLog.RouterInput); err != nil {
+			return errors.Wrapf(err, "Failed to redact the router input")
+		}
+	}
+	if routerLog.RouterOutput != nil {
+		routerLog.RouterOutput.Headers = p.redactUPIHeaders(routerLog.RouterOutput.Headers)
+		if err := p.redactMessage(routerLog.RouterOutput); err != nil {
+			return errors.Wrapf(err, "Failed to redact the router output")
+		}
+	}
+	return nil
+}
+
+func (p *Policy) redactResponse(resp *turing.Response) {
+	if resp == nil {
+		return
+	}
+	p.redactHeader(resp.Header)
+	resp.Response = p.redactBody(resp.Response)
+}
+
+func (p *Policy) redactHeader(header map[string]string) {
+	if len(p.headerRedactions) == 0 {
+		return
+	}
+	for key, value := range header {
+		action, ok := p.headerRedactions[strings.ToLower(key)]
+		if !ok {
+			continue
+		}
+		if action == config.DropRedactionAction {
+			delete(header, key)
+			continue
+		}
+		header[key] = p.redactString(value, action)
+	}
+}
+
+func (p *Policy) redactUPIHeaders(headers []*upiv1.Header) []*upiv1.Header {
+	if len(p.headerRedactions) == 0 {
+		return headers
+	}
+	redacted := make([]*upiv1.Header, 0, len(headers))
+	for _, header := range headers {
+		action, ok := p.headerRedactions[strings.ToLower(header.GetKey())]
+		if !ok {
+			redacted = append(redacted, header)
+			continue
+		}
+		if action != config.DropRedactionAction {
+			redacted = append(redacted, &upiv1.Header{Key: header.GetKey(), Value: p.redactString(header.GetValue(), action)})
+		}
+	}
+	return redacted
+}
+
+// redactBody redacts the fields of the given JSON body. The body is dropped, if it's not valid JSON,
+// as its fields can't be redacted.
+func (p *Policy) redactBody(body string) string {
+	if len(p.payloadRedactions) == 0 || body == "" {
+		return body
+	}
+	redacted, err := p.redactJSON([]byte(body))
+	if err != nil {
+		return ""
+	}
+	return string(redacted)
+}
+
+// redactMessage redacts the fields of the JSON representation of the given proto message, in place
+func (p *Policy) redactMessage(message proto.Message) error {
+	if len(p.payloadRedactions) == 0 {
+		return nil
+	}
+	data, err := protoJSONMarshaller.Marshal(message)
+	if err != nil {
+		return err
+	}
+	redacted, err := p.redactJSON(data)
+	if err != nil {
+		return err
+	}
+	proto.Reset(message)
+	return protojson.Unmarshal(redacted, message)
+}
+
+func (p *Policy) redactJSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	for _, redaction := range p.payloadRedactions {
+		value = p.redactValue(value, redaction.path, redaction.action)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// redactValue redacts the fields at the given path of the decoded JSON value, and returns the value
+func (p *Policy) redactValue(value interface{}, path []string, action config.RedactionAction) interface{} {
+	key, last := path[0], len(path) == 1
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for field, fieldValue := range v {
+			if key != "*" && key != field {
+				continue
+			}
+			if !last {
+				v[field] = p.redactValue(fieldValue, path[1:], action)
+			} else if action == config.DropRedactionAction {
+				delete(v, field)
+			} else {
+				v[field] = p.redactJSONValue(fieldValue, action)
+			}
+		}
+		return v
+	case []interface{}:
+		index, err := strconv.Atoi(key)
+		if key != "*" && (err != nil || index < 0 || index >= len(v)) {
+			return v
+		}
+		var elements []interface{}
+		for i, element := range v {
+			if key != "*" && i != index {
+				elements = append(elements, element)
+				continue
+			}
+			if !last {
+				elements = append(elements, p.redactValue(element, path[1:], action))
+			} else if action != config.DropRedactionAction {
+				elements = append(elements, p.redactJSONValue(element, action))
+			}
+		}
+		if elements == nil {
+			elements = []interface{}{}
+		}
+		return elements
+	}
+	return value
+}
+
+// redactJSONValue hashes or masks the given decoded JSON value, as a string. The values, that are not
+// strings, are redacted by their JSON representation.
+func (p *Policy) redactJSONValue(value interface{}, action config.RedactionAction) interface{} {
+	str, ok := value.(string)
+	if !ok {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil
+		}
+		str = string(data)
+	}
+	return p.redactString(str, action)
+}
+
+func (p *Policy) redactString(value string, action config.RedactionAction) string {
+	switch action {
+	case config.HashRedactionAction:
+		mac := hmac.New(sha256.New, p.hashKey)
+		_, _ = mac.Write([]byte(value))
+		return hex.EncodeToString(mac.Sum(nil))
+	case config.MaskRedactionAction:
+		// The short values are masked entirely
+		runes, kept := []rune(value), 0
+		if len(runes) > maskedSuffixLength {
+			kept = maskedSuffixLength
+		}
+		for i := 0; i < len(runes)-kept; i++ {
+			runes[i] = '*'
+		}
+		return string(runes)
+	}
+	return ""
+}
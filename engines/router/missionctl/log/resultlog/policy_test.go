@@ -0,0 +1,251 @@
+package resultlog
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog/proto/turing"
+)
+
+func samplingRate(rate float64) *float64 {
+	return &rate
+}
+
+func hmacHex(key string, value string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	_, _ = mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func writeHashKeyFile(t *testing.T, key string) string {
+	path := filepath.Join(t.TempDir(), "hash-key")
+	require.NoError(t, os.WriteFile(path, []byte(key), 0600))
+	return path
+}
+
+func newTestPolicy(t *testing.T, cfg *config.ResultLogPolicy) *Policy {
+	policy, err := NewPolicy(cfg)
+	require.NoError(t, err)
+	return policy
+}
+
+func TestNewPolicy(t *testing.T) {
+	// The policy is not created, if it neither samples nor redacts the result logs
+	assert.Nil(t, newTestPolicy(t, nil))
+	assert.Nil(t, newTestPolicy(t, &config.ResultLogPolicy{}))
+	assert.Nil(t, newTestPolicy(t, &config.ResultLogPolicy{SamplingRate: samplingRate(1)}))
+
+	assert.NotNil(t, newTestPolicy(t, &config.ResultLogPolicy{SamplingRate: samplingRate(0.5)}))
+	assert.NotNil(t, newTestPolicy(t, &config.ResultLogPolicy{
+		Redactions: []config.ResultLogRedaction{
+			{FieldSource: request.HeaderFieldSource, Field: "X-Phone", Action: config.DropRedactionAction},
+		},
+	}))
+
+	// The hash key is read from its file
+	hashRedactions := []config.ResultLogRedaction{
+		{FieldSource: request.HeaderFieldSource, Field: "X-Phone", Action: config.HashRedactionAction},
+	}
+	_, err := NewPolicy(&config.ResultLogPolicy{
+		Redactions:  hashRedactions,
+		HashKeyFile: filepath.Join(t.TempDir(), "missing"),
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Failed to read the result log hash key file")
+	_, err = NewPolicy(&config.ResultLogPolicy{Redactions: hashRedactions, HashKeyFile: writeHashKeyFile(t, "\n")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Empty result log hash key")
+
+	// A nil policy logs all of the results as they are
+	var policy *Policy
+	assert.True(t, policy.SampleHTTPRequest(http.Header{}, nil))
+	assert.True(t, policy.SampleUPIRequest(metadata.MD{}, &upiv1.PredictValuesRequest{}))
+	message := &turing.TuringResultLogMessage{Request: &turing.Request{Body: `{"phone": "6281234567"}`}}
+	policy.RedactTuringResultLog(message)
+	assert.Equal(t, `{"phone": "6281234567"}`, message.Request.Body)
+}
+
+func TestPolicySample(t *testing.T) {
+	unit := &config.ResultLogSamplingUnit{FieldSource: request.HeaderFieldSource, Field: "X-User-ID"}
+
+	// No request is logged, at the zero sampling rate
+	policy := newTestPolicy(t, &config.ResultLogPolicy{SamplingRate: samplingRate(0), SamplingUnit: unit})
+	assert.False(t, policy.SampleHTTPRequest(http.Header{"X-User-Id": []string{"user-1"}}, nil))
+	assert.False(t, policy.SampleHTTPRequest(http.Header{}, nil))
+
+	// The requests are sampled deterministically, by their unit, at roughly the sampling rate
+	policy = newTestPolicy(t, &config.ResultLogPolicy{SamplingRate: samplingRate(0.3), SamplingUnit: unit})
+	sampled := 0
+	for i := 0; i < 10000; i++ {
+		header := http.Header{"X-User-Id": []string{fmt.Sprintf("user-%d", i)}}
+		isSampled := policy.SampleHTTPRequest(header, nil)
+		for j := 0; j < 3; j++ {
+			assert.Equal(t, isSampled, policy.SampleHTTPRequest(header, nil))
+		}
+		if isSampled {
+			sampled++
+		}
+	}
+	assert.InDelta(t, 3000, sampled, 300)
+
+	// The unit of the UPI requests is read from the request metadata or the prediction context
+	policy = newTestPolicy(t, &config.ResultLogPolicy{
+		SamplingRate: samplingRate(0.5),
+		SamplingUnit: &config.ResultLogSamplingUnit{FieldSource: request.PredictionContextSource, Field: "user"},
+	})
+	req := &upiv1.PredictValuesRequest{
+		PredictionContext: []*upiv1.Variable{{Name: "user", Type: upiv1.Type_TYPE_STRING, StringValue: "user-1"}},
+	}
+	isSampled := policy.SampleUPIRequest(metadata.MD{}, req)
+	for j := 0; j < 3; j++ {
+		assert.Equal(t, isSampled, policy.SampleUPIRequest(metadata.MD{}, req))
+	}
+}
+
+func TestPolicyRedactTuringResultLog(t *testing.T) {
+	policy := newTestPolicy(t, &config.ResultLogPolicy{
+		Redactions: []config.ResultLogRedaction{
+			{FieldSource: request.HeaderFieldSource, Field: "x-phone", Action: config.DropRedactionAction},
+			{FieldSource: request.HeaderFieldSource, Field: "X-Email", Action: config.HashRedactionAction},
+			{FieldSource: request.PayloadFieldSource, Field: "customer.phone", Action: config.MaskRedactionAction},
+			{FieldSource: request.PayloadFieldSource, Field: "customer.name", Action: config.DropRedactionAction},
+			{FieldSource: request.PayloadFieldSource, Field: "items.*.email", Action: config.HashRedactionAction},
+			{FieldSource: request.PayloadFieldSource, Field: "tokens.1", Action: config.MaskRedactionAction},
+		},
+		// The trailing newline of the key file is not part of the key
+		HashKeyFile: writeHashKeyFile(t, "secret\n"),
+	})
+
+	message := &turing.TuringResultLogMessage{
+		Request: &turing.Request{
+			Header: map[string]string{"X-Phone": "6281234567", "X-Email": "a@b.com", "X-Other": "value"},
+			Body: `{"customer": {"phone": 6281234567, "name": "A", "id": 1}, ` +
+				`"items": [{"email": "a@b.com"}, {"email": "c@d.com", "qty": 2}], "tokens": ["abc", "abcdefgh"]}`,
+		},
+		Enricher:  &turing.Response{Response: `{"customer": {"phone": "+62 812"}}`},
+		Router:    &turing.Response{Error: "timeout"},
+		Ensembler: &turing.Response{Response: `not json`},
+		Stages: []*turing.StageResponse{
+			{Key: "stage", Response: &turing.Response{Header: map[string]string{"x-phone": "1"}, Response: `[]`}},
+		},
+	}
+	policy.RedactTuringResultLog(message)
+
+	assert.Equal(t, map[string]string{"X-Email": hmacHex("secret", "a@b.com"), "X-Other": "value"}, message.Request.Header)
+	assert.JSONEq(t, fmt.Sprintf(`{
+		"customer": {"phone": "******4567", "id": 1},
+		"items": [{"email": "%s"}, {"email": "%s", "qty": 2}],
+		"tokens": ["abc", "****efgh"]
+	}`, hmacHex("secret", "a@b.com"), hmacHex("secret", "c@d.com")), message.Request.Body)
+	assert.JSONEq(t, `{"customer": {"phone": "*** 812"}}`, message.Enricher.Response)
+	assert.Equal(t, &turing.Response{Error: "timeout"}, message.Router)
+	// The bodies, that are not valid JSON, are dropped
+	assert.Equal(t, "", message.Ensembler.Response)
+	assert.Equal(t, map[string]string{}, message.Stages[0].Response.Header)
+	assert.Equal(t, `[]`, message.Stages[0].Response.Response)
+}
+
+func TestPolicyRedactRouterLog(t *testing.T) {
+	policy := newTestPolicy(t, &config.ResultLogPolicy{
+		Redactions: []config.ResultLogRedaction{
+			{FieldSource: request.HeaderFieldSource, Field: "x-phone", Action: config.DropRedactionAction},
+			{FieldSource: request.PayloadFieldSource, Field: "prediction_table.phone", Action: config.MaskRedactionAction},
+			{FieldSource: request.PayloadFieldSource, Field: "prediction_context", Action: config.DropRedactionAction},
+		},
+	})
+
+	predictionTable, err := structpb.NewStruct(map[string]interface{}{"phone": "6281234567", "id": 1})
+	require.NoError(t, err)
+	routerLog := &upiv1.RouterLog{
+		PredictionId: "123",
+		RouterInput: &upiv1.RouterInput{
+			PredictionTable: predictionTable,
+			Headers:         []*upiv1.Header{{Key: "x-phone", Value: "6281234567"}, {Key: "x-other", Value: "value"}},
+		},
+		RouterOutput: &upiv1.RouterOutput{
+			PredictionContext: []*upiv1.Variable{{Name: "phone", Type: upiv1.Type_TYPE_STRING, StringValue: "1"}},
+		},
+	}
+	require.NoError(t, policy.RedactRouterLog(routerLog))
+
+	assert.Equal(t, "123", routerLog.PredictionId)
+	assert.Equal(t, "******4567", routerLog.RouterInput.PredictionTable.Fields["phone"].GetStringValue())
+	assert.Equal(t, float64(1), routerLog.RouterInput.PredictionTable.Fields["id"].GetNumberValue())
+	require.Len(t, routerLog.RouterInput.Headers, 1)
+	assert.Equal(t, "x-other", routerLog.RouterInput.Headers[0].Key)
+	assert.Empty(t, routerLog.RouterOutput.PredictionContext)
+
+	// The router log can't be redacted, if the redacted values are not valid for their fields
+	policy = newTestPolicy(t, &config.ResultLogPolicy{
+		Redactions: []config.ResultLogRedaction{
+			{FieldSource: request.PayloadFieldSource, Field: "status", Action: config.MaskRedactionAction},
+		},
+	})
+	err = policy.RedactRouterLog(&upiv1.RouterLog{RouterOutput: &upiv1.RouterOutput{Status: 12345}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Failed to redact the router output")
+}
+
+func TestResultLoggerPolicy(t *testing.T) {
+	logger := log.Glob()
+
+	// The results of the requests, that are not sampled, are not logged
+	mockLogger := &mockResultLogger{}
+	policy := newTestPolicy(t, &config.ResultLogPolicy{SamplingRate: samplingRate(0)})
+	rl := InitTuringResultLogger("app", mockLogger, policy)
+	respCh := make(chan RouterResponse, 1)
+	respCh <- RouterResponse{key: ResultLogKeys.Router, body: []byte(`{}`)}
+	close(respCh)
+	rl.LogTuringRouterRequestSummary("1", logger, time.Now(), http.Header{}, []byte(`{}`), respCh)
+	assert.Equal(t, int32(0), mockLogger.numOfCalls)
+
+	// The results are redacted before they are written
+	rl = InitTuringResultLogger("app", mockLogger, newTestPolicy(t, &config.ResultLogPolicy{
+		Redactions: []config.ResultLogRedaction{
+			{FieldSource: request.PayloadFieldSource, Field: "phone", Action: config.DropRedactionAction},
+		},
+	}))
+	respCh = make(chan RouterResponse, 1)
+	respCh <- RouterResponse{key: ResultLogKeys.Router, body: []byte(`{"phone": "1", "score": 1}`)}
+	close(respCh)
+	rl.LogTuringRouterRequestSummary("1", logger, time.Now(), http.Header{}, []byte(`{"phone": "1"}`), respCh)
+	require.Equal(t, int32(1), mockLogger.numOfCalls)
+	assert.Equal(t, `{}`, mockLogger.result.Request.Body)
+	assert.JSONEq(t, `{"score": 1}`, mockLogger.result.Router.Response)
+}
+
+func TestUPIResultLoggerPolicy(t *testing.T) {
+	upiLogger, resultLogger := &mockUPILogger{}, &mockResultLogger{}
+	policy := newTestPolicy(t, &config.ResultLogPolicy{SamplingRate: samplingRate(0)})
+	ul, err := InitUPIResultLogger(
+		"test-app-1.project",
+		upiLogger,
+		InitTuringResultLogger("test-app-1.project", resultLogger, policy),
+		policy,
+	)
+	require.NoError(t, err)
+
+	// Neither log is written, if the request is not sampled
+	respCh := make(chan GrpcRouterResponse, 1)
+	respCh <- GrpcRouterResponse{Key: ResultLogKeys.Router, Body: &upiv1.PredictValuesResponse{}}
+	close(respCh)
+	ul.LogTuringRouterRequestSummary(metadata.MD{}, &upiv1.PredictValuesRequest{}, respCh)
+	assert.Nil(t, upiLogger.routerLog)
+	assert.Nil(t, resultLogger.result)
+}
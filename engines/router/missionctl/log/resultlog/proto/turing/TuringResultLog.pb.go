@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.28.0
+// 	protoc-gen-go v1.29.0
 // 	protoc        v3.19.4
 // source: TuringResultLog.proto
 
@@ -145,6 +145,271 @@ func (x *Response) GetHeader() map[string]string {
 	return nil
 }
 
+type ShadowResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The ID of the shadow route
+	RouteId string `protobuf:"bytes,1,opt,name=route_id,json=routeId,proto3" json:"route_id,omitempty"`
+	// The response / error from the shadow route
+	Response *Response `protobuf:"bytes,2,opt,name=response,proto3" json:"response,omitempty"`
+	// The time taken to receive the response from the shadow route, in milliseconds
+	LatencyMs int64 `protobuf:"varint,3,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`
+}
+
+func (x *ShadowResponse) Reset() {
+	*x = ShadowResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_TuringResultLog_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ShadowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShadowResponse) ProtoMessage() {}
+
+func (x *ShadowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_TuringResultLog_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShadowResponse.ProtoReflect.Descriptor instead.
+func (*ShadowResponse) Descriptor() ([]byte, []int) {
+	return file_TuringResultLog_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ShadowResponse) GetRouteId() string {
+	if x != nil {
+		return x.RouteId
+	}
+	return ""
+}
+
+func (x *ShadowResponse) GetResponse() *Response {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *ShadowResponse) GetLatencyMs() int64 {
+	if x != nil {
+		return x.LatencyMs
+	}
+	return 0
+}
+
+type HedgedRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The ID of the route, to which a duplicate request was sent
+	RouteId string `protobuf:"bytes,1,opt,name=route_id,json=routeId,proto3" json:"route_id,omitempty"`
+	// The copy of the request whose response was used, either "original" or "hedge"
+	Winner string `protobuf:"bytes,2,opt,name=winner,proto3" json:"winner,omitempty"`
+}
+
+func (x *HedgedRequest) Reset() {
+	*x = HedgedRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_TuringResultLog_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HedgedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HedgedRequest) ProtoMessage() {}
+
+func (x *HedgedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_TuringResultLog_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HedgedRequest.ProtoReflect.Descriptor instead.
+func (*HedgedRequest) Descriptor() ([]byte, []int) {
+	return file_TuringResultLog_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HedgedRequest) GetRouteId() string {
+	if x != nil {
+		return x.RouteId
+	}
+	return ""
+}
+
+func (x *HedgedRequest) GetWinner() string {
+	if x != nil {
+		return x.Winner
+	}
+	return ""
+}
+
+type StageResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The result log key of the pre / post-processing stage
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// The response / error from the processing stage
+	Response *Response `protobuf:"bytes,2,opt,name=response,proto3" json:"response,omitempty"`
+}
+
+func (x *StageResponse) Reset() {
+	*x = StageResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_TuringResultLog_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StageResponse) ProtoMessage() {}
+
+func (x *StageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_TuringResultLog_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StageResponse.ProtoReflect.Descriptor instead.
+func (*StageResponse) Descriptor() ([]byte, []int) {
+	return file_TuringResultLog_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StageResponse) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *StageResponse) GetResponse() *Response {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+type ComponentTiming struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The result log key of the Turing component (enricher / router / ensembler / the key of the processing stage),
+	// or "route" for the calls to the individual routes
+	Component string `protobuf:"bytes,1,opt,name=component,proto3" json:"component,omitempty"`
+	// The ID of the route, only set for the calls to the individual routes
+	RouteId string `protobuf:"bytes,2,opt,name=route_id,json=routeId,proto3" json:"route_id,omitempty"`
+	// The time at which the call to the component started
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	// The time taken to receive the response from the component, in milliseconds
+	DurationMs float64 `protobuf:"fixed64,4,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	// The status code of the response from the component; an HTTP status code, or a gRPC status code for UPI routers
+	StatusCode int32 `protobuf:"varint,5,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+}
+
+func (x *ComponentTiming) Reset() {
+	*x = ComponentTiming{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_TuringResultLog_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ComponentTiming) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComponentTiming) ProtoMessage() {}
+
+func (x *ComponentTiming) ProtoReflect() protoreflect.Message {
+	mi := &file_TuringResultLog_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComponentTiming.ProtoReflect.Descriptor instead.
+func (*ComponentTiming) Descriptor() ([]byte, []int) {
+	return file_TuringResultLog_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ComponentTiming) GetComponent() string {
+	if x != nil {
+		return x.Component
+	}
+	return ""
+}
+
+func (x *ComponentTiming) GetRouteId() string {
+	if x != nil {
+		return x.RouteId
+	}
+	return ""
+}
+
+func (x *ComponentTiming) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *ComponentTiming) GetDurationMs() float64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *ComponentTiming) GetStatusCode() int32 {
+	if x != nil {
+		return x.StatusCode
+	}
+	return 0
+}
+
 // key
 type TuringResultLogKey struct {
 	state         protoimpl.MessageState
@@ -160,7 +425,7 @@ type TuringResultLogKey struct {
 func (x *TuringResultLogKey) Reset() {
 	*x = TuringResultLogKey{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_TuringResultLog_proto_msgTypes[2]
+		mi := &file_TuringResultLog_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -173,7 +438,7 @@ func (x *TuringResultLogKey) String() string {
 func (*TuringResultLogKey) ProtoMessage() {}
 
 func (x *TuringResultLogKey) ProtoReflect() protoreflect.Message {
-	mi := &file_TuringResultLog_proto_msgTypes[2]
+	mi := &file_TuringResultLog_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -186,7 +451,7 @@ func (x *TuringResultLogKey) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TuringResultLogKey.ProtoReflect.Descriptor instead.
 func (*TuringResultLogKey) Descriptor() ([]byte, []int) {
-	return file_TuringResultLog_proto_rawDescGZIP(), []int{2}
+	return file_TuringResultLog_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *TuringResultLogKey) GetTuringReqId() string {
@@ -226,12 +491,28 @@ type TuringResultLogMessage struct {
 	Router *Response `protobuf:"bytes,7,opt,name=router,proto3" json:"router,omitempty"`
 	// The response from the Enricher, if configured
 	Ensembler *Response `protobuf:"bytes,8,opt,name=ensembler,proto3" json:"ensembler,omitempty"`
+	// The responses from the shadow routes, if configured. These responses are never returned to the client.
+	Shadow []*ShadowResponse `protobuf:"bytes,9,rep,name=shadow,proto3" json:"shadow,omitempty"`
+	// Whether the response was served from the router's response cache, without calling the Turing components
+	CacheHit bool `protobuf:"varint,10,opt,name=cache_hit,json=cacheHit,proto3" json:"cache_hit,omitempty"`
+	// The requests to the routes that were hedged, because no response was received within the hedging delay
+	HedgedRequests []*HedgedRequest `protobuf:"bytes,11,rep,name=hedged_requests,json=hedgedRequests,proto3" json:"hedged_requests,omitempty"`
+	// The responses from the pre / post-processing stages, if configured, in the order in which they ran
+	Stages []*StageResponse `protobuf:"bytes,12,rep,name=stages,proto3" json:"stages,omitempty"`
+	// The identity of the authenticated caller, if the router authenticates its callers
+	Caller string `protobuf:"bytes,13,opt,name=caller,proto3" json:"caller,omitempty"`
+	// The timings of the calls to the Turing components and to the routes, in the order in which they started
+	Timings []*ComponentTiming `protobuf:"bytes,14,rep,name=timings,proto3" json:"timings,omitempty"`
+	// The ID of the route, whose response was returned by the router, if any
+	RouteId string `protobuf:"bytes,15,opt,name=route_id,json=routeId,proto3" json:"route_id,omitempty"`
+	// The traffic rule, that the request matched, if any
+	TrafficRule string `protobuf:"bytes,16,opt,name=traffic_rule,json=trafficRule,proto3" json:"traffic_rule,omitempty"`
 }
 
 func (x *TuringResultLogMessage) Reset() {
 	*x = TuringResultLogMessage{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_TuringResultLog_proto_msgTypes[3]
+		mi := &file_TuringResultLog_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -244,7 +525,7 @@ func (x *TuringResultLogMessage) String() string {
 func (*TuringResultLogMessage) ProtoMessage() {}
 
 func (x *TuringResultLogMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_TuringResultLog_proto_msgTypes[3]
+	mi := &file_TuringResultLog_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -257,7 +538,7 @@ func (x *TuringResultLogMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TuringResultLogMessage.ProtoReflect.Descriptor instead.
 func (*TuringResultLogMessage) Descriptor() ([]byte, []int) {
-	return file_TuringResultLog_proto_rawDescGZIP(), []int{3}
+	return file_TuringResultLog_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *TuringResultLogMessage) GetTuringReqId() string {
@@ -316,6 +597,62 @@ func (x *TuringResultLogMessage) GetEnsembler() *Response {
 	return nil
 }
 
+func (x *TuringResultLogMessage) GetShadow() []*ShadowResponse {
+	if x != nil {
+		return x.Shadow
+	}
+	return nil
+}
+
+func (x *TuringResultLogMessage) GetCacheHit() bool {
+	if x != nil {
+		return x.CacheHit
+	}
+	return false
+}
+
+func (x *TuringResultLogMessage) GetHedgedRequests() []*HedgedRequest {
+	if x != nil {
+		return x.HedgedRequests
+	}
+	return nil
+}
+
+func (x *TuringResultLogMessage) GetStages() []*StageResponse {
+	if x != nil {
+		return x.Stages
+	}
+	return nil
+}
+
+func (x *TuringResultLogMessage) GetCaller() string {
+	if x != nil {
+		return x.Caller
+	}
+	return ""
+}
+
+func (x *TuringResultLogMessage) GetTimings() []*ComponentTiming {
+	if x != nil {
+		return x.Timings
+	}
+	return nil
+}
+
+func (x *TuringResultLogMessage) GetRouteId() string {
+	if x != nil {
+		return x.RouteId
+	}
+	return ""
+}
+
+func (x *TuringResultLogMessage) GetTrafficRule() string {
+	if x != nil {
+		return x.TrafficRule
+	}
+	return ""
+}
+
 var File_TuringResultLog_proto protoreflect.FileDescriptor
 
 var file_TuringResultLog_proto_rawDesc = []byte{
@@ -343,43 +680,93 @@ var file_TuringResultLog_proto_rawDesc = []byte{
 	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
 	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
 	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
-	0x22, 0x7d, 0x0a, 0x12, 0x54, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
-	0x4c, 0x6f, 0x67, 0x4b, 0x65, 0x79, 0x12, 0x22, 0x0a, 0x0d, 0x74, 0x75, 0x72, 0x69, 0x6e, 0x67,
-	0x5f, 0x72, 0x65, 0x71, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x74,
-	0x75, 0x72, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x49, 0x64, 0x12, 0x43, 0x0a, 0x0f, 0x65, 0x76,
-	0x65, 0x6e, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
-	0x0e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22,
-	0x8d, 0x03, 0x0a, 0x16, 0x54, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
-	0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x22, 0x0a, 0x0d, 0x74, 0x75,
-	0x72, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x71, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0b, 0x74, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x49, 0x64, 0x12, 0x43,
-	0x0a, 0x0f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
-	0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
-	0x61, 0x6d, 0x70, 0x52, 0x0e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
-	0x61, 0x6d, 0x70, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x5f, 0x76, 0x65,
-	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x72, 0x6f, 0x75,
-	0x74, 0x65, 0x72, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x29, 0x0a, 0x07, 0x72, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x74, 0x75,
-	0x72, 0x69, 0x6e, 0x67, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x07, 0x72, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x30, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x65, 0x72, 0x69, 0x6d,
-	0x65, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x74, 0x75, 0x72, 0x69,
-	0x6e, 0x67, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x0a, 0x65, 0x78, 0x70,
-	0x65, 0x72, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x2c, 0x0a, 0x08, 0x65, 0x6e, 0x72, 0x69, 0x63,
-	0x68, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x74, 0x75, 0x72, 0x69,
-	0x6e, 0x67, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x08, 0x65, 0x6e, 0x72,
-	0x69, 0x63, 0x68, 0x65, 0x72, 0x12, 0x28, 0x0a, 0x06, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x18,
-	0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x74, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x2e, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x06, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x12,
-	0x2e, 0x0a, 0x09, 0x65, 0x6e, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x65, 0x72, 0x18, 0x08, 0x20, 0x01,
+	0x22, 0x78, 0x0a, 0x0e, 0x53, 0x68, 0x61, 0x64, 0x6f, 0x77, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x49, 0x64, 0x12, 0x2c, 0x0a,
+	0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x10, 0x2e, 0x74, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x52, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6c,
+	0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x09, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4d, 0x73, 0x22, 0x42, 0x0a, 0x0d, 0x48, 0x65,
+	0x64, 0x67, 0x65, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x72,
+	0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x72,
+	0x6f, 0x75, 0x74, 0x65, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x22, 0x4f,
+	0x0a, 0x0d, 0x53, 0x74, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x2c, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x74, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x2e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0xc7, 0x01, 0x0a, 0x0f, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x54, 0x69, 0x6d,
+	0x69, 0x6e, 0x67, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e,
+	0x74, 0x12, 0x19, 0x0a, 0x08, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x49, 0x64, 0x12, 0x39, 0x0a, 0x0a,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x64, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x43, 0x6f, 0x64, 0x65, 0x22, 0x7d, 0x0a, 0x12, 0x54, 0x75, 0x72,
+	0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x4c, 0x6f, 0x67, 0x4b, 0x65, 0x79, 0x12,
+	0x22, 0x0a, 0x0d, 0x74, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x71, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x74, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x52, 0x65,
+	0x71, 0x49, 0x64, 0x12, 0x43, 0x0a, 0x0f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0xd2, 0x05, 0x0a, 0x16, 0x54, 0x75, 0x72,
+	0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x12, 0x22, 0x0a, 0x0d, 0x74, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65,
+	0x71, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x74, 0x75, 0x72, 0x69,
+	0x6e, 0x67, 0x52, 0x65, 0x71, 0x49, 0x64, 0x12, 0x43, 0x0a, 0x0f, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0e, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x25, 0x0a, 0x0e,
+	0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x29, 0x0a, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x74, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x2e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x30,
+	0x0a, 0x0a, 0x65, 0x78, 0x70, 0x65, 0x72, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x10, 0x2e, 0x74, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x2e, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x52, 0x0a, 0x65, 0x78, 0x70, 0x65, 0x72, 0x69, 0x6d, 0x65, 0x6e, 0x74,
+	0x12, 0x2c, 0x0a, 0x08, 0x65, 0x6e, 0x72, 0x69, 0x63, 0x68, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01,
 	0x28, 0x0b, 0x32, 0x10, 0x2e, 0x74, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x2e, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x52, 0x09, 0x65, 0x6e, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x65, 0x72, 0x42,
-	0x2f, 0x42, 0x14, 0x54, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x4c,
-	0x6f, 0x67, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x5a, 0x17, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
-	0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x6a, 0x65, 0x6b, 0x2f, 0x74, 0x75, 0x72, 0x69, 0x6e, 0x67,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6f, 0x6e, 0x73, 0x65, 0x52, 0x08, 0x65, 0x6e, 0x72, 0x69, 0x63, 0x68, 0x65, 0x72, 0x12, 0x28,
+	0x0a, 0x06, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10,
+	0x2e, 0x74, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x52, 0x06, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x12, 0x2e, 0x0a, 0x09, 0x65, 0x6e, 0x73, 0x65,
+	0x6d, 0x62, 0x6c, 0x65, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x74, 0x75,
+	0x72, 0x69, 0x6e, 0x67, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x09, 0x65,
+	0x6e, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x65, 0x72, 0x12, 0x2e, 0x0a, 0x06, 0x73, 0x68, 0x61, 0x64,
+	0x6f, 0x77, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x74, 0x75, 0x72, 0x69, 0x6e,
+	0x67, 0x2e, 0x53, 0x68, 0x61, 0x64, 0x6f, 0x77, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x52, 0x06, 0x73, 0x68, 0x61, 0x64, 0x6f, 0x77, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x61, 0x63, 0x68,
+	0x65, 0x5f, 0x68, 0x69, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x63, 0x61, 0x63,
+	0x68, 0x65, 0x48, 0x69, 0x74, 0x12, 0x3e, 0x0a, 0x0f, 0x68, 0x65, 0x64, 0x67, 0x65, 0x64, 0x5f,
+	0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15,
+	0x2e, 0x74, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x2e, 0x48, 0x65, 0x64, 0x67, 0x65, 0x64, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x0e, 0x68, 0x65, 0x64, 0x67, 0x65, 0x64, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x73, 0x12, 0x2d, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x67, 0x65, 0x73, 0x18,
+	0x0c, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x74, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x2e, 0x53,
+	0x74, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x06, 0x73, 0x74,
+	0x61, 0x67, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x18, 0x0d,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x12, 0x31, 0x0a, 0x07,
+	0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x0e, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e,
+	0x74, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74,
+	0x54, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x52, 0x07, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x73, 0x12,
+	0x19, 0x0a, 0x08, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x0f, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x72,
+	0x61, 0x66, 0x66, 0x69, 0x63, 0x5f, 0x72, 0x75, 0x6c, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x52, 0x75, 0x6c, 0x65, 0x42, 0x2f, 0x42,
+	0x14, 0x54, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x4c, 0x6f, 0x67,
+	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x5a, 0x17, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x67, 0x6f, 0x6a, 0x65, 0x6b, 0x2f, 0x74, 0x75, 0x72, 0x69, 0x6e, 0x67, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -394,31 +781,42 @@ func file_TuringResultLog_proto_rawDescGZIP() []byte {
 	return file_TuringResultLog_proto_rawDescData
 }
 
-var file_TuringResultLog_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_TuringResultLog_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_TuringResultLog_proto_goTypes = []interface{}{
 	(*Request)(nil),                // 0: turing.Request
 	(*Response)(nil),               // 1: turing.Response
-	(*TuringResultLogKey)(nil),     // 2: turing.TuringResultLogKey
-	(*TuringResultLogMessage)(nil), // 3: turing.TuringResultLogMessage
-	nil,                            // 4: turing.Request.HeaderEntry
-	nil,                            // 5: turing.Response.HeaderEntry
-	(*timestamppb.Timestamp)(nil),  // 6: google.protobuf.Timestamp
+	(*ShadowResponse)(nil),         // 2: turing.ShadowResponse
+	(*HedgedRequest)(nil),          // 3: turing.HedgedRequest
+	(*StageResponse)(nil),          // 4: turing.StageResponse
+	(*ComponentTiming)(nil),        // 5: turing.ComponentTiming
+	(*TuringResultLogKey)(nil),     // 6: turing.TuringResultLogKey
+	(*TuringResultLogMessage)(nil), // 7: turing.TuringResultLogMessage
+	nil,                            // 8: turing.Request.HeaderEntry
+	nil,                            // 9: turing.Response.HeaderEntry
+	(*timestamppb.Timestamp)(nil),  // 10: google.protobuf.Timestamp
 }
 var file_TuringResultLog_proto_depIdxs = []int32{
-	4, // 0: turing.Request.header:type_name -> turing.Request.HeaderEntry
-	5, // 1: turing.Response.header:type_name -> turing.Response.HeaderEntry
-	6, // 2: turing.TuringResultLogKey.event_timestamp:type_name -> google.protobuf.Timestamp
-	6, // 3: turing.TuringResultLogMessage.event_timestamp:type_name -> google.protobuf.Timestamp
-	0, // 4: turing.TuringResultLogMessage.request:type_name -> turing.Request
-	1, // 5: turing.TuringResultLogMessage.experiment:type_name -> turing.Response
-	1, // 6: turing.TuringResultLogMessage.enricher:type_name -> turing.Response
-	1, // 7: turing.TuringResultLogMessage.router:type_name -> turing.Response
-	1, // 8: turing.TuringResultLogMessage.ensembler:type_name -> turing.Response
-	9, // [9:9] is the sub-list for method output_type
-	9, // [9:9] is the sub-list for method input_type
-	9, // [9:9] is the sub-list for extension type_name
-	9, // [9:9] is the sub-list for extension extendee
-	0, // [0:9] is the sub-list for field type_name
+	8,  // 0: turing.Request.header:type_name -> turing.Request.HeaderEntry
+	9,  // 1: turing.Response.header:type_name -> turing.Response.HeaderEntry
+	1,  // 2: turing.ShadowResponse.response:type_name -> turing.Response
+	1,  // 3: turing.StageResponse.response:type_name -> turing.Response
+	10, // 4: turing.ComponentTiming.start_time:type_name -> google.protobuf.Timestamp
+	10, // 5: turing.TuringResultLogKey.event_timestamp:type_name -> google.protobuf.Timestamp
+	10, // 6: turing.TuringResultLogMessage.event_timestamp:type_name -> google.protobuf.Timestamp
+	0,  // 7: turing.TuringResultLogMessage.request:type_name -> turing.Request
+	1,  // 8: turing.TuringResultLogMessage.experiment:type_name -> turing.Response
+	1,  // 9: turing.TuringResultLogMessage.enricher:type_name -> turing.Response
+	1,  // 10: turing.TuringResultLogMessage.router:type_name -> turing.Response
+	1,  // 11: turing.TuringResultLogMessage.ensembler:type_name -> turing.Response
+	2,  // 12: turing.TuringResultLogMessage.shadow:type_name -> turing.ShadowResponse
+	3,  // 13: turing.TuringResultLogMessage.hedged_requests:type_name -> turing.HedgedRequest
+	4,  // 14: turing.TuringResultLogMessage.stages:type_name -> turing.StageResponse
+	5,  // 15: turing.TuringResultLogMessage.timings:type_name -> turing.ComponentTiming
+	16, // [16:16] is the sub-list for method output_type
+	16, // [16:16] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_TuringResultLog_proto_init() }
@@ -452,7 +850,7 @@ func file_TuringResultLog_proto_init() {
 			}
 		}
 		file_TuringResultLog_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TuringResultLogKey); i {
+			switch v := v.(*ShadowResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -464,6 +862,54 @@ func file_TuringResultLog_proto_init() {
 			}
 		}
 		file_TuringResultLog_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HedgedRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_TuringResultLog_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StageResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_TuringResultLog_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ComponentTiming); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_TuringResultLog_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TuringResultLogKey); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_TuringResultLog_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*TuringResultLogMessage); i {
 			case 0:
 				return &v.state
@@ -482,7 +928,7 @@ func file_TuringResultLog_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_TuringResultLog_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,271 @@
+package resultlog
+
+import (
+	"sync"
+	"time"
+
+	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog/proto/turing"
+)
+
+// batchResultLogger is implemented by the result loggers, that can write several messages at once
+type batchResultLogger interface {
+	writeBatch(messages []*turing.TuringResultLogMessage) error
+}
+
+// writeBatch writes the messages to the given result logger, at once if it supports batches, or
+// one by one otherwise
+func writeBatch(logger TuringResultLogger, messages []*turing.TuringResultLogMessage) error {
+	if batchLogger, ok := logger.(batchResultLogger); ok {
+		return batchLogger.writeBatch(messages)
+	}
+	return writeEach(logger.write, messages)
+}
+
+// writeEach writes the messages one by one, with the given write function
+func writeEach[M any](write func(M) error, messages []M) error {
+	if len(messages) == 1 {
+		return write(messages[0])
+	}
+	var failed int
+	var lastErr error
+	for _, message := range messages {
+		if err := write(message); err != nil {
+			failed++
+			lastErr = err
+		}
+	}
+	if failed > 0 {
+		return errors.Wrapf(lastErr, "Failed to write %d of %d result logs", failed, len(messages))
+	}
+	return nil
+}
+
+const (
+	// turingQueueName and upiQueueName label the metrics of the queues of the TuringResultLogMessages
+	// and of the UPI RouterLogs
+	turingQueueName = "turing"
+	upiQueueName    = "upi"
+)
+
+// QueueLogger decouples the result logging of the requests from the writes to the underlying result
+// logger. The messages are enqueued in a bounded queue, and written to the result logger in batches
+// by a pool of workers. When the queue is full, the new messages are handled by the overflow policy.
+type QueueLogger struct {
+	*logQueue[*turing.TuringResultLogMessage]
+	logger TuringResultLogger
+}
+
+// NewQueueLogger creates a new QueueLogger, that writes to the given result logger, and starts its workers
+func NewQueueLogger(logger TuringResultLogger, cfg *config.ResultLogQueueConfig) (*QueueLogger, error) {
+	queue, err := newLogQueue(turingQueueName, func(messages []*turing.TuringResultLogMessage) error {
+		return writeBatch(logger, messages)
+	}, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &QueueLogger{logQueue: queue, logger: logger}, nil
+}
+
+// write enqueues the message to be written by the workers. It returns an error, if the message is dropped.
+func (l *QueueLogger) write(message *turing.TuringResultLogMessage) error {
+	return l.enqueue(message)
+}
+
+// Close stops accepting new messages, waits for the workers to write the queued messages, and
+// closes the underlying result logger
+func (l *QueueLogger) Close() error {
+	l.close()
+	return l.logger.Close()
+}
+
+// UPIQueueLogger is the QueueLogger of the UPI RouterLogs, which are written to the underlying
+// UPILogger one by one, by the workers
+type UPIQueueLogger struct {
+	*logQueue[*upiv1.RouterLog]
+	logger UPILogger
+}
+
+// NewUPIQueueLogger creates a new UPIQueueLogger, that writes to the given UPILogger, and starts its workers
+func NewUPIQueueLogger(logger UPILogger, cfg *config.ResultLogQueueConfig) (*UPIQueueLogger, error) {
+	queue, err := newLogQueue(upiQueueName, func(routerLogs []*upiv1.RouterLog) error {
+		return writeEach(logger.write, routerLogs)
+	}, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &UPIQueueLogger{logQueue: queue, logger: logger}, nil
+}
+
+// write enqueues the router log to be written by the workers. It returns an error, if the router
+// log is dropped.
+func (l *UPIQueueLogger) write(routerLog *upiv1.RouterLog) error {
+	return l.enqueue(routerLog)
+}
+
+// Close stops accepting new router logs, waits for the workers to write the queued router logs,
+// and closes the underlying UPILogger
+func (l *UPIQueueLogger) Close() error {
+	l.close()
+	return l.logger.Close()
+}
+
+// logQueue is the bounded queue of the messages of the QueueLogger and the UPIQueueLogger. The
+// messages are written in batches with the given function, by a pool of workers.
+type logQueue[M any] struct {
+	writeMessages  func(messages []M) error
+	queue          chan M
+	batchSize      int
+	flushInterval  time.Duration
+	overflowPolicy config.OverflowPolicy
+	// name labels the metrics of the queue
+	name string
+
+	// mu guards closed, so that no message is enqueued after the queue is closed
+	mu      sync.RWMutex
+	closed  bool
+	workers sync.WaitGroup
+}
+
+// newLogQueue creates a new logQueue, that writes the messages with the given function, and starts its workers
+func newLogQueue[M any](
+	name string,
+	writeMessages func(messages []M) error,
+	cfg *config.ResultLogQueueConfig,
+) (*logQueue[M], error) {
+	if cfg.Size <= 0 || cfg.Workers <= 0 || cfg.BatchSize <= 0 || cfg.FlushInterval <= 0 {
+		return nil, errors.Newf(errors.BadConfig,
+			"The size, workers, batch size and flush interval of the result log queue must be positive")
+	}
+	switch cfg.OverflowPolicy {
+	case config.DropNewestOverflowPolicy, config.DropOldestOverflowPolicy, config.BlockOverflowPolicy:
+	default:
+		return nil, errors.Newf(errors.BadConfig, "Overflow policy value %s not supported", cfg.OverflowPolicy)
+	}
+
+	q := &logQueue[M]{
+		writeMessages:  writeMessages,
+		queue:          make(chan M, cfg.Size),
+		batchSize:      cfg.BatchSize,
+		flushInterval:  cfg.FlushInterval,
+		overflowPolicy: cfg.OverflowPolicy,
+		name:           name,
+	}
+	q.workers.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go q.run()
+	}
+	q.recordDepth()
+	return q, nil
+}
+
+// enqueue enqueues the message to be written by the workers. If the queue is full, the message is
+// dropped, the oldest message in the queue is dropped in its place, or the call blocks until the
+// queue has room, by the overflow policy. It returns an error, if the message is dropped.
+func (q *logQueue[M]) enqueue(message M) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return errors.Newf(errors.Unknown, "The result log queue is closed")
+	}
+	defer q.recordDepth()
+
+	select {
+	case q.queue <- message:
+		return nil
+	default:
+	}
+	switch q.overflowPolicy {
+	case config.BlockOverflowPolicy:
+		q.recordOverflow(instrumentation.ResultLogQueueBlockedTotal, map[string]string{"queue": q.name})
+		q.queue <- message
+		return nil
+	case config.DropOldestOverflowPolicy:
+		for {
+			select {
+			case <-q.queue:
+				q.recordOverflow(instrumentation.ResultLogQueueDroppedTotal,
+					map[string]string{"queue": q.name, "policy": string(q.overflowPolicy)})
+			default:
+			}
+			select {
+			case q.queue <- message:
+				return nil
+			default:
+			}
+		}
+	default:
+		q.recordOverflow(instrumentation.ResultLogQueueDroppedTotal,
+			map[string]string{"queue": q.name, "policy": string(q.overflowPolicy)})
+		return errors.Newf(errors.Unknown, "The result log queue is full")
+	}
+}
+
+// close stops accepting new messages, and waits for the workers to write the queued messages
+func (q *logQueue[M]) close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.queue)
+	}
+	q.mu.Unlock()
+
+	q.workers.Wait()
+	q.recordDepth()
+}
+
+// run collects the queued messages into batches, and writes each batch once it is full or the flush
+// interval has passed since its first message, until the queue is closed and drained
+func (q *logQueue[M]) run() {
+	defer q.workers.Done()
+	for {
+		message, ok := <-q.queue
+		if !ok {
+			return
+		}
+		batch := make([]M, 1, q.batchSize)
+		batch[0] = message
+
+		timer := time.NewTimer(q.flushInterval)
+	collect:
+		for len(batch) < q.batchSize {
+			select {
+			case message, ok := <-q.queue:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, message)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+
+		q.recordDepth()
+		if err := q.writeMessages(batch); err != nil {
+			log.Glob().Errorf("Result Logging Error: %s", err.Error())
+		}
+	}
+}
+
+func (q *logQueue[M]) recordDepth() {
+	if err := metrics.Glob().RecordGauge(
+		instrumentation.ResultLogQueueDepth,
+		float64(len(q.queue)),
+		map[string]string{"queue": q.name},
+	); err != nil {
+		log.Glob().Errorf("Failed to record the result log queue depth: %s", err.Error())
+	}
+}
+
+func (q *logQueue[M]) recordOverflow(key metrics.MetricName, labels map[string]string) {
+	if err := metrics.Glob().Inc(key, labels); err != nil {
+		log.Glob().Errorf("Failed to record the result log queue overflow: %s", err.Error())
+	}
+}
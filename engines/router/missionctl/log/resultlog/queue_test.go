@@ -0,0 +1,273 @@
+package resultlog
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog/proto/turing"
+)
+
+// batchingResultLogger satisfies the batchResultLogger interface, and records the batches written
+// to it. The writes wait for the gate, if it is set.
+type batchingResultLogger struct {
+	mu      sync.Mutex
+	gate    chan struct{}
+	batches [][]string
+	closed  bool
+}
+
+func (l *batchingResultLogger) write(message *turing.TuringResultLogMessage) error {
+	return l.writeBatch([]*turing.TuringResultLogMessage{message})
+}
+
+func (l *batchingResultLogger) writeBatch(messages []*turing.TuringResultLogMessage) error {
+	if l.gate != nil {
+		<-l.gate
+	}
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.TuringReqId)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.batches = append(l.batches, ids)
+	return nil
+}
+
+func (l *batchingResultLogger) Close() error {
+	l.closed = true
+	return nil
+}
+
+func (l *batchingResultLogger) writtenIDs() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var ids []string
+	for _, batch := range l.batches {
+		ids = append(ids, batch...)
+	}
+	return ids
+}
+
+func newTestQueueConfig(policy config.OverflowPolicy) *config.ResultLogQueueConfig {
+	return &config.ResultLogQueueConfig{
+		Size:           2,
+		Workers:        1,
+		BatchSize:      1,
+		FlushInterval:  time.Second,
+		OverflowPolicy: policy,
+	}
+}
+
+func TestNewQueueLoggerInvalidConfig(t *testing.T) {
+	cfg := newTestQueueConfig(config.DropNewestOverflowPolicy)
+	cfg.Workers = 0
+	_, err := NewQueueLogger(&batchingResultLogger{}, cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be positive")
+
+	_, err = NewQueueLogger(&batchingResultLogger{}, newTestQueueConfig(config.OverflowPolicy("drop")))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Overflow policy value drop not supported")
+}
+
+func TestQueueLoggerBatches(t *testing.T) {
+	inner := &batchingResultLogger{}
+	queue, err := NewQueueLogger(inner, &config.ResultLogQueueConfig{
+		Size:           10,
+		Workers:        1,
+		BatchSize:      3,
+		FlushInterval:  time.Hour,
+		OverflowPolicy: config.DropNewestOverflowPolicy,
+	})
+	require.NoError(t, err)
+
+	for _, id := range []string{"1", "2", "3", "4"} {
+		require.NoError(t, queue.write(&turing.TuringResultLogMessage{TuringReqId: id}))
+	}
+	// The full batch is written at once, and the rest of the queue is written when it is closed
+	assert.Eventually(t, func() bool { return len(inner.writtenIDs()) == 3 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, queue.Close())
+	assert.Equal(t, [][]string{{"1", "2", "3"}, {"4"}}, inner.batches)
+	assert.True(t, inner.closed)
+
+	// The messages are not enqueued, once the queue is closed
+	err = queue.write(&turing.TuringResultLogMessage{TuringReqId: "5"})
+	assert.EqualError(t, err, "The result log queue is closed")
+}
+
+func TestQueueLoggerFlushInterval(t *testing.T) {
+	inner := &batchingResultLogger{}
+	cfg := newTestQueueConfig(config.DropNewestOverflowPolicy)
+	cfg.BatchSize = 100
+	cfg.FlushInterval = 10 * time.Millisecond
+	queue, err := NewQueueLogger(inner, cfg)
+	require.NoError(t, err)
+
+	// The partial batch is written, once the flush interval has passed
+	require.NoError(t, queue.write(&turing.TuringResultLogMessage{TuringReqId: "1"}))
+	assert.Eventually(t, func() bool { return len(inner.writtenIDs()) == 1 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, queue.Close())
+}
+
+// fillTestQueue writes the messages with the given IDs to the queue, while its only worker is
+// blocked writing the first of them, and returns the errors of the writes
+func fillTestQueue(t *testing.T, queue *QueueLogger, ids ...string) []error {
+	require.NoError(t, queue.write(&turing.TuringResultLogMessage{TuringReqId: ids[0]}))
+	// Wait for the worker to take the first message from the queue
+	assert.Eventually(t, func() bool { return len(queue.queue) == 0 }, time.Second, time.Millisecond)
+
+	var errs []error
+	for _, id := range ids[1:] {
+		errs = append(errs, queue.write(&turing.TuringResultLogMessage{TuringReqId: id}))
+	}
+	return errs
+}
+
+func TestQueueLoggerOverflow(t *testing.T) {
+	tests := map[string]struct {
+		policy      config.OverflowPolicy
+		expectedErr string
+		expectedIDs []string
+	}{
+		"drop-newest": {
+			policy:      config.DropNewestOverflowPolicy,
+			expectedErr: "The result log queue is full",
+			expectedIDs: []string{"1", "2", "3"},
+		},
+		"drop-oldest": {
+			policy:      config.DropOldestOverflowPolicy,
+			expectedIDs: []string{"1", "3", "4"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			inner := &batchingResultLogger{gate: make(chan struct{})}
+			queue, err := NewQueueLogger(inner, newTestQueueConfig(tt.policy))
+			require.NoError(t, err)
+
+			errs := fillTestQueue(t, queue, "1", "2", "3", "4")
+			assert.NoError(t, errs[0])
+			assert.NoError(t, errs[1])
+			if tt.expectedErr != "" {
+				assert.EqualError(t, errs[2], tt.expectedErr)
+			} else {
+				assert.NoError(t, errs[2])
+			}
+
+			close(inner.gate)
+			require.NoError(t, queue.Close())
+			assert.Equal(t, tt.expectedIDs, inner.writtenIDs())
+		})
+	}
+}
+
+func TestQueueLoggerOverflowBlock(t *testing.T) {
+	inner := &batchingResultLogger{gate: make(chan struct{})}
+	queue, err := NewQueueLogger(inner, newTestQueueConfig(config.BlockOverflowPolicy))
+	require.NoError(t, err)
+	fillTestQueue(t, queue, "1", "2", "3")
+
+	// The write blocks, until the queue has room
+	written := make(chan error)
+	go func() {
+		written <- queue.write(&turing.TuringResultLogMessage{TuringReqId: "4"})
+	}()
+	select {
+	case <-written:
+		t.Fatal("The write should block, while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(inner.gate)
+	require.NoError(t, <-written)
+	require.NoError(t, queue.Close())
+	assert.Equal(t, []string{"1", "2", "3", "4"}, inner.writtenIDs())
+}
+
+func TestUPIQueueLogger(t *testing.T) {
+	inner := &mockUPILogger{}
+	cfg := newTestQueueConfig(config.DropNewestOverflowPolicy)
+	cfg.BatchSize = 10
+	cfg.FlushInterval = time.Hour
+	queue, err := NewUPIQueueLogger(inner, cfg)
+	require.NoError(t, err)
+
+	// The queued router logs are written one by one, at the latest when the queue is closed
+	require.NoError(t, queue.write(&upiv1.RouterLog{PredictionId: "1"}))
+	require.NoError(t, queue.write(&upiv1.RouterLog{PredictionId: "2"}))
+	assert.Equal(t, int32(0), inner.numOfCalls)
+	require.NoError(t, queue.Close())
+	assert.Equal(t, int32(2), inner.numOfCalls)
+	assert.Equal(t, "2", inner.routerLog.PredictionId)
+
+	err = queue.write(&upiv1.RouterLog{PredictionId: "3"})
+	assert.EqualError(t, err, "The result log queue is closed")
+}
+
+func TestWriteBatch(t *testing.T) {
+	// The result loggers, that don't support batches, are written to one message at a time
+	inner := &recoveringResultLogger{}
+	messages := []*turing.TuringResultLogMessage{{TuringReqId: "1"}, {TuringReqId: "2"}}
+	require.NoError(t, writeBatch(inner, messages))
+	assert.Equal(t, []string{"1", "2"}, inner.writtenIDs())
+
+	err := writeBatch(&failingResultLogger{}, messages)
+	assert.EqualError(t, err, "Failed to write 2 of 2 result logs: sink unavailable")
+	assert.Equal(t, errors.New("sink unavailable"), writeBatch(&failingResultLogger{}, messages[:1]))
+}
+
+func TestResultLoggerLog(t *testing.T) {
+	queue, err := NewQueueLogger(&batchingResultLogger{}, newTestQueueConfig(config.DropNewestOverflowPolicy))
+	require.NoError(t, err)
+	upiQueue, err := NewUPIQueueLogger(&mockUPILogger{}, newTestQueueConfig(config.DropNewestOverflowPolicy))
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		logger interface{ Log(logFn func()) }
+		queued bool
+	}{
+		"queued": {
+			logger: InitTuringResultLogger("", queue, nil),
+			queued: true,
+		},
+		"not queued": {
+			logger: InitTuringResultLogger("", &batchingResultLogger{}, nil),
+		},
+		"upi | queued": {
+			logger: &UPIResultLogger{upiLogger: upiQueue, turingResultLogger: InitTuringResultLogger("", queue, nil)},
+			queued: true,
+		},
+		"upi | router logs not queued": {
+			logger: &UPIResultLogger{upiLogger: &mockUPILogger{}, turingResultLogger: InitTuringResultLogger("", queue, nil)},
+		},
+		"upi | result logs not queued": {
+			logger: &UPIResultLogger{turingResultLogger: InitTuringResultLogger("", &batchingResultLogger{}, nil)},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// The request summary is logged before Log returns, if the logs are queued, and by a new
+			// goroutine, that Log doesn't wait for, otherwise
+			release := make(chan struct{})
+			var logged bool
+			tt.logger.Log(func() {
+				if !tt.queued {
+					<-release
+				}
+				logged = true
+			})
+			assert.Equal(t, tt.queued, logged)
+			close(release)
+		})
+	}
+}
@@ -4,17 +4,24 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router"
+	"github.com/caraml-dev/turing/engines/router/missionctl/auth"
 	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/hedging"
 	"github.com/caraml-dev/turing/engines/router/missionctl/log"
 	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog/proto/turing"
 	mchttp "github.com/caraml-dev/turing/engines/router/missionctl/server/http"
 	"github.com/caraml-dev/turing/engines/router/missionctl/server/http/handlers/compression"
+	"github.com/caraml-dev/turing/engines/router/missionctl/shadow"
+	"github.com/caraml-dev/turing/engines/router/missionctl/timing"
 )
 
 // ResultLogger holds the logic how the TuringResultLogMessage is being constructed,
@@ -26,11 +33,17 @@ type ResultLogger struct {
 	// will be logged as RouterVersion in TuringResultLog.proto
 	// Format: {router_name}-{router_version}.{project_name}
 	appName string
+	// policy samples and redacts the result logs
+	policy *Policy
+	// pending tracks the request summaries that are being logged asynchronously
+	pending sync.WaitGroup
 }
 
 // TuringResultLogger is an abstraction for the underlying result logger for TuringResultLogMessage
 type TuringResultLogger interface {
 	write(message *turing.TuringResultLogMessage) error
+	// Close flushes the buffered logs, if any, and releases the resources held by the logger
+	Close() error
 }
 
 // RouterResponse is the struct of expected to pass into response channel to be logged as TuringResultLogMessage later
@@ -39,21 +52,29 @@ type RouterResponse struct {
 	header http.Header
 	body   []byte
 	err    string
+	// cached is true, if the response was served from the router's response cache
+	cached bool
+	// shadows holds the responses from the shadow routes, only set for the Shadow key
+	shadows []*shadow.Response
+	// hedgedRequests holds the hedged requests to the routes, only set for the Hedging key
+	hedgedRequests []*hedging.Record
+	// timings holds the timings of the calls to the components and the routes, together with the
+	// selected route and traffic rule, only set for the Timing key
+	timings *Timings
 }
 
-// ResultLogKeys defines the individual components for which the result log must be created
-var ResultLogKeys = struct {
-	Experiment string
-	Enricher   string
-	Router     string
-	Ensembler  string
-}{
-	Experiment: "experiment",
-	Enricher:   "enricher",
-	Router:     "router",
-	Ensembler:  "ensembler",
+// Timings holds the timings of the calls to the Turing components and to the routes, the ID of the route
+// whose response was returned by the router, and the traffic rule that the request matched
+type Timings struct {
+	Records     []*timing.Record
+	RouteID     string
+	TrafficRule string
 }
 
+// ResultLogKeys defines the individual components for which the result log must be created. They
+// are defined with the processing stages, which can't use them as their result log keys.
+var ResultLogKeys = router.ResultLogKeys
+
 var protoJSONMarshaller = protojson.MarshalOptions{UseProtoNames: true}
 
 // LogTuringRouterRequestSummary logs the summary of the request made to the turing router,
@@ -74,6 +95,12 @@ func (rl *ResultLogger) LogTuringRouterRequestSummary(
 	if err != nil {
 		logger.Errorf("Error occurred when reading request body: %s", err.Error())
 	}
+	if !rl.policy.SampleHTTPRequest(reqHeader, uncompressedData) {
+		for range mcRespCh {
+			// Drain the responses, as the result of the request is not logged
+		}
+		return
+	}
 
 	// Create a new TuringResultLogEntry record with the context and request info
 	logEntry := NewTuringResultLog(predictionID, timestamp, reqHeader, string(uncompressedData))
@@ -81,6 +108,21 @@ func (rl *ResultLogger) LogTuringRouterRequestSummary(
 	// Read incoming responses and prepare for logging
 	for resp := range mcRespCh {
 		logger.Debugw("Received data in response channel")
+		if resp.key == ResultLogKeys.Shadow {
+			addShadowResponses(logger, logEntry, resp.shadows)
+			continue
+		}
+		if resp.key == ResultLogKeys.Hedging {
+			addHedgedRequests(logEntry, resp.hedgedRequests)
+			continue
+		}
+		if resp.key == ResultLogKeys.Timing {
+			addTimings(logEntry, resp.timings)
+			continue
+		}
+		if resp.cached {
+			logEntry.CacheHit = true
+		}
 		// If error exists, add an error record
 		if resp.err != "" {
 			AddResponse(logEntry, resp.key, "", nil, resp.err)
@@ -108,6 +150,43 @@ func (rl *ResultLogger) LogTuringRouterRequestSummary(
 	}
 }
 
+// Log runs the given function, which logs a request summary. If the result logs are queued, it's run
+// in the calling goroutine, as it only builds the result log and enqueues it. Otherwise, it's run in a
+// new goroutine with LogAsync, so that the request doesn't wait for the result logger.
+func (rl *ResultLogger) Log(logFn func()) {
+	if rl.queued() {
+		logFn()
+		return
+	}
+	rl.LogAsync(logFn)
+}
+
+// queued returns whether the result logs are enqueued in a QueueLogger
+func (rl *ResultLogger) queued() bool {
+	_, ok := rl.trl.(*QueueLogger)
+	return ok
+}
+
+// LogAsync runs the given function, which logs a request summary, in a new goroutine. The goroutine
+// is tracked, so that the result logger waits for it to complete when it is closed.
+func (rl *ResultLogger) LogAsync(logFn func()) {
+	rl.pending.Add(1)
+	go func() {
+		defer rl.pending.Done()
+		logFn()
+	}()
+}
+
+// Close waits for the request summaries that are being logged to complete, and then closes the
+// underlying result logger, flushing any buffered logs. If the context is done first, the underlying
+// result logger is left open, as it's still in use.
+func (rl *ResultLogger) Close(ctx context.Context) error {
+	if err := waitForPendingLogs(ctx, &rl.pending); err != nil {
+		return err
+	}
+	return rl.trl.Close()
+}
+
 // LogTuringRouterRequestError logs the given turing request id and the error data
 func (rl *ResultLogger) LogTuringRouterRequestError(ctx context.Context, err *errors.TuringError) {
 	logger := log.WithContext(ctx)
@@ -151,8 +230,53 @@ func (rl *ResultLogger) SendResponseToLogChannel(
 	}
 }
 
+// SendCachedResponseToLogChannel copies the response served from the router's response cache
+// to the given channel as a RouterResponse object
+func (rl *ResultLogger) SendCachedResponseToLogChannel(ch chan<- RouterResponse, key string, r mchttp.Response) {
+	ch <- RouterResponse{
+		key:    key,
+		header: r.Header(),
+		body:   r.Body(),
+		cached: true,
+	}
+}
+
+// SendShadowResponsesToLogChannel copies the responses from the shadow routes to the given channel
+// as a single RouterResponse object
+func (rl *ResultLogger) SendShadowResponsesToLogChannel(ch chan<- RouterResponse, responses []*shadow.Response) {
+	if len(responses) == 0 {
+		return
+	}
+	ch <- RouterResponse{
+		key:     ResultLogKeys.Shadow,
+		shadows: responses,
+	}
+}
+
+// SendHedgedRequestsToLogChannel copies the hedged requests to the routes to the given channel
+// as a single RouterResponse object
+func (rl *ResultLogger) SendHedgedRequestsToLogChannel(ch chan<- RouterResponse, records []*hedging.Record) {
+	if len(records) == 0 {
+		return
+	}
+	ch <- RouterResponse{
+		key:            ResultLogKeys.Hedging,
+		hedgedRequests: records,
+	}
+}
+
+// SendTimingsToLogChannel copies the timings of the calls to the components and the routes to the
+// given channel as a single RouterResponse object
+func (rl *ResultLogger) SendTimingsToLogChannel(ch chan<- RouterResponse, timings *Timings) {
+	ch <- RouterResponse{
+		key:     ResultLogKeys.Timing,
+		timings: timings,
+	}
+}
+
 func (rl *ResultLogger) logEntry(log *turing.TuringResultLogMessage) error {
 	log.RouterVersion = rl.appName
+	rl.policy.RedactTuringResultLog(log)
 	return rl.trl.write(log)
 }
 
@@ -175,16 +299,46 @@ func NewTuringResultLog[h http.Header | metadata.MD](
 			Header: reqHeader,
 			Body:   body,
 		},
+		Caller: getCallerID(reqHeader),
 	}
 }
 
+// getCallerID returns the identity of the authenticated caller, from the formatted request header
+func getCallerID(header map[string]string) string {
+	for key, value := range header {
+		if strings.EqualFold(key, request.CallerHeader) {
+			if caller, err := auth.ParseCaller(value); err == nil {
+				return caller.ID
+			}
+		}
+	}
+	return ""
+}
+
 // InitTuringResultLogger initializes the result with supplied logger for
 // logging TuringResultLogMessage. appName stores the configured app name,
 // Format: {router_name}-{router_version}.{project_name}
-func InitTuringResultLogger(appName string, logger TuringResultLogger) *ResultLogger {
+// The result logs are sampled and redacted with the given policy, if set.
+func InitTuringResultLogger(appName string, logger TuringResultLogger, policy *Policy) *ResultLogger {
 	return &ResultLogger{
 		trl:     logger,
 		appName: appName,
+		policy:  policy,
+	}
+}
+
+// waitForPendingLogs waits for the pending logs to be written, until the context is done
+func waitForPendingLogs(ctx context.Context, pending *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "Timed out waiting for the pending result logs")
 	}
 }
 
@@ -218,7 +372,8 @@ func uncompressHTTPBody(header http.Header, body []byte) ([]byte, error) {
 	return result, nil
 }
 
-// AddResponse adds the per-component response/error info to the TuringResultLogEntry
+// AddResponse adds the per-component response/error info to the TuringResultLogEntry. The responses with
+// any other key are from the processing stages, and are added in the order in which they are received.
 func AddResponse(rl *turing.TuringResultLogMessage, key string, body string, header map[string]string, err string) {
 	responseRecord := &turing.Response{
 		Header:   header,
@@ -234,5 +389,70 @@ func AddResponse(rl *turing.TuringResultLogMessage, key string, body string, hea
 		rl.Router = responseRecord
 	case ResultLogKeys.Ensembler:
 		rl.Ensembler = responseRecord
+	default:
+		rl.Stages = append(rl.Stages, &turing.StageResponse{Key: key, Response: responseRecord})
+	}
+}
+
+// AddShadowResponse adds the response/error info of a shadow route to the TuringResultLogEntry
+func AddShadowResponse(
+	rl *turing.TuringResultLogMessage,
+	routeID string,
+	body string,
+	header map[string]string,
+	err string,
+	latency time.Duration,
+) {
+	rl.Shadow = append(rl.Shadow, &turing.ShadowResponse{
+		RouteId: routeID,
+		Response: &turing.Response{
+			Header:   header,
+			Response: body,
+			Error:    err,
+		},
+		LatencyMs: latency.Milliseconds(),
+	})
+}
+
+func addShadowResponses(logger log.Logger, logEntry *turing.TuringResultLogMessage, responses []*shadow.Response) {
+	for _, resp := range responses {
+		if resp.Error != "" {
+			AddShadowResponse(logEntry, resp.RouteID, "", nil, resp.Error, resp.Latency)
+			continue
+		}
+		uncompressedData, err := uncompressHTTPBody(resp.Header, resp.Body)
+		if err != nil {
+			logger.Errorf("Error occurred when reading %s shadow response body: %s", resp.RouteID, err.Error())
+			AddShadowResponse(logEntry, resp.RouteID, "", nil, err.Error(), resp.Latency)
+			continue
+		}
+		AddShadowResponse(logEntry, resp.RouteID, string(uncompressedData), FormatHeader(resp.Header), "", resp.Latency)
+	}
+}
+
+func addHedgedRequests(logEntry *turing.TuringResultLogMessage, records []*hedging.Record) {
+	for _, record := range records {
+		logEntry.HedgedRequests = append(logEntry.HedgedRequests, &turing.HedgedRequest{
+			RouteId: record.RouteID,
+			Winner:  string(record.Winner),
+		})
+	}
+}
+
+// addTimings adds the timings of the request to the log entry, if any
+func addTimings(logEntry *turing.TuringResultLogMessage, timings *Timings) {
+	if timings == nil {
+		return
+	}
+	for _, record := range timings.Records {
+		logEntry.Timings = append(logEntry.Timings, &turing.ComponentTiming{
+			Component:  record.Component,
+			RouteId:    record.RouteID,
+			StartTime:  timestamppb.New(record.StartTime),
+			DurationMs: float64(record.Duration) / float64(time.Millisecond),
+			StatusCode: int32(record.StatusCode),
+		})
 	}
+	logEntry.RouteId = timings.RouteID
+	logEntry.TrafficRule = timings.TrafficRule
 }
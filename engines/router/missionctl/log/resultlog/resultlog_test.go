@@ -16,9 +16,11 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/hedging"
 	tu "github.com/caraml-dev/turing/engines/router/missionctl/internal/testutils"
 	"github.com/caraml-dev/turing/engines/router/missionctl/log"
 	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog/proto/turing"
+	"github.com/caraml-dev/turing/engines/router/missionctl/timing"
 	"github.com/caraml-dev/turing/engines/router/missionctl/turingctx"
 
 	fiberProtocol "github.com/gojek/fiber/protocol"
@@ -28,6 +30,7 @@ import (
 type mockResultLogger struct {
 	numOfCalls int32
 	result     *turing.TuringResultLogMessage
+	closed     bool
 }
 
 // write satisfies the TuringResultLogger interface
@@ -37,6 +40,12 @@ func (l *mockResultLogger) write(log *turing.TuringResultLogMessage) error {
 	return nil
 }
 
+// Close satisfies the TuringResultLogger interface
+func (l *mockResultLogger) Close() error {
+	l.closed = true
+	return nil
+}
+
 // Helper methods for resultlog package tests
 func makeTestTuringResultLog(t *testing.T) (context.Context, *turing.TuringResultLogMessage) {
 	// Make test request
@@ -202,6 +211,170 @@ func TestResultLogger_LogTuringRouterRequestSummary(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "hedged requests",
+			args: args{
+				predictionID: predictionID,
+				timestamp:    testTime,
+				reqHeader:    http.Header{},
+				reqBody:      []byte("req body"),
+				routerResponse: []RouterResponse{
+					{
+						key:  ResultLogKeys.Router,
+						body: []byte("resp body"),
+					},
+					{
+						key: ResultLogKeys.Hedging,
+						hedgedRequests: []*hedging.Record{
+							{RouteID: "route-a", Winner: hedging.HedgeCopy},
+							{RouteID: "route-b", Winner: hedging.OriginalCopy},
+						},
+					},
+				},
+			},
+			want: &turing.TuringResultLogMessage{
+				TuringReqId:    predictionID,
+				EventTimestamp: timestamppb.New(testTime),
+				RouterVersion:  appName,
+				Request: &turing.Request{
+					Header: map[string]string{},
+					Body:   "req body",
+				},
+				Router: &turing.Response{
+					Response: "resp body",
+					Header:   map[string]string{},
+				},
+				HedgedRequests: []*turing.HedgedRequest{
+					{RouteId: "route-a", Winner: "hedge"},
+					{RouteId: "route-b", Winner: "original"},
+				},
+			},
+		},
+		{
+			name: "timings",
+			args: args{
+				predictionID: predictionID,
+				timestamp:    testTime,
+				reqHeader:    http.Header{},
+				reqBody:      []byte("req body"),
+				routerResponse: []RouterResponse{
+					{
+						key:  ResultLogKeys.Router,
+						body: []byte("resp body"),
+					},
+					{
+						key: ResultLogKeys.Timing,
+						timings: &Timings{
+							Records: []*timing.Record{
+								{Component: ResultLogKeys.Router, StartTime: testTime, Duration: 25 * time.Millisecond,
+									StatusCode: http.StatusOK},
+								{Component: timing.RouteComponent, RouteID: "route-a", StartTime: testTime,
+									Duration: 2500 * time.Microsecond, StatusCode: http.StatusOK},
+							},
+							RouteID:     "route-a",
+							TrafficRule: "rule-1",
+						},
+					},
+				},
+			},
+			want: &turing.TuringResultLogMessage{
+				TuringReqId:    predictionID,
+				EventTimestamp: timestamppb.New(testTime),
+				RouterVersion:  appName,
+				Request: &turing.Request{
+					Header: map[string]string{},
+					Body:   "req body",
+				},
+				Router: &turing.Response{
+					Response: "resp body",
+					Header:   map[string]string{},
+				},
+				Timings: []*turing.ComponentTiming{
+					{Component: "router", StartTime: timestamppb.New(testTime), DurationMs: 25, StatusCode: 200},
+					{Component: "route", RouteId: "route-a", StartTime: timestamppb.New(testTime), DurationMs: 2.5,
+						StatusCode: 200},
+				},
+				RouteId:     "route-a",
+				TrafficRule: "rule-1",
+			},
+		},
+		{
+			name: "no timings",
+			args: args{
+				predictionID: predictionID,
+				timestamp:    testTime,
+				reqHeader:    http.Header{},
+				reqBody:      []byte("req body"),
+				routerResponse: []RouterResponse{
+					{
+						key:  ResultLogKeys.Router,
+						body: []byte("resp body"),
+					},
+					{
+						key: ResultLogKeys.Timing,
+					},
+				},
+			},
+			want: &turing.TuringResultLogMessage{
+				TuringReqId:    predictionID,
+				EventTimestamp: timestamppb.New(testTime),
+				RouterVersion:  appName,
+				Request: &turing.Request{
+					Header: map[string]string{},
+					Body:   "req body",
+				},
+				Router: &turing.Response{
+					Response: "resp body",
+					Header:   map[string]string{},
+				},
+			},
+		},
+		{
+			name: "processing stages",
+			args: args{
+				predictionID: predictionID,
+				timestamp:    testTime,
+				reqHeader:    http.Header{},
+				reqBody:      []byte("req body"),
+				routerResponse: []RouterResponse{
+					{
+						key:  "fraud-check",
+						body: []byte("fraud-check body"),
+					},
+					{
+						key:  ResultLogKeys.Router,
+						body: []byte("resp body"),
+					},
+					{
+						key: "calibration",
+						err: "stage timeout",
+					},
+				},
+			},
+			want: &turing.TuringResultLogMessage{
+				TuringReqId:    predictionID,
+				EventTimestamp: timestamppb.New(testTime),
+				RouterVersion:  appName,
+				Request: &turing.Request{
+					Header: map[string]string{},
+					Body:   "req body",
+				},
+				Router: &turing.Response{
+					Response: "resp body",
+					Header:   map[string]string{},
+				},
+				Stages: []*turing.StageResponse{
+					{
+						Key:      "fraud-check",
+						Response: &turing.Response{Response: "fraud-check body", Header: map[string]string{}},
+					},
+					{
+						Key:      "calibration",
+						Response: &turing.Response{Error: "stage timeout"},
+					},
+				},
+			},
+		},
 		{
 			name: "error resp",
 			args: args{
@@ -271,6 +444,39 @@ func TestResultLogger_LogTuringRouterRequestSummary(t *testing.T) {
 	}
 }
 
+func TestResultLoggerClose(t *testing.T) {
+	t.Run("success | pending logs are written before closing", func(t *testing.T) {
+		mockLogger := &mockResultLogger{}
+		rl := InitTuringResultLogger("", mockLogger, nil)
+
+		release := make(chan struct{})
+		rl.LogAsync(func() {
+			<-release
+			_ = rl.logEntry(&turing.TuringResultLogMessage{TuringReqId: "123"})
+		})
+		time.AfterFunc(50*time.Millisecond, func() { close(release) })
+
+		assert.NoError(t, rl.Close(context.Background()))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&mockLogger.numOfCalls))
+		assert.True(t, mockLogger.closed)
+	})
+
+	t.Run("failure | timed out waiting for pending logs", func(t *testing.T) {
+		mockLogger := &mockResultLogger{}
+		rl := InitTuringResultLogger("", mockLogger, nil)
+
+		release := make(chan struct{})
+		defer close(release)
+		rl.LogAsync(func() { <-release })
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := rl.Close(ctx)
+		assert.EqualError(t, err, "Timed out waiting for the pending result logs: context deadline exceeded")
+		assert.False(t, mockLogger.closed)
+	})
+}
+
 // TestSendResponseToLogChannel tests the copyResponseToLogChannel method in logutils.
 // Verify that when an error is set, the error message is copied, response is set to null;
 // when the error is empty, the response is copied an error field in the log is empty.
@@ -281,7 +487,7 @@ func TestSendResponseToLogChannel(t *testing.T) {
 		"error":   errors.NewTuringError(fmt.Errorf("test error"), fiberProtocol.HTTP),
 	}
 
-	rl := InitTuringResultLogger("", NewNopLogger())
+	rl := InitTuringResultLogger("", NewNopLogger(), nil)
 
 	for name, httpErr := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -349,6 +555,24 @@ func TestNewTuringResultLog(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "authenticated caller",
+			args: args[http.Header]{
+				header:       http.Header{"Turing-Caller": []string{`{"id":"team-a","method":"api_key"}`}},
+				body:         "test",
+				timestamp:    testTime,
+				predictionID: predictionID,
+			},
+			want: &turing.TuringResultLogMessage{
+				TuringReqId:    predictionID,
+				EventTimestamp: timestamppb.New(testTime),
+				Request: &turing.Request{
+					Header: map[string]string{"Turing-Caller": `{"id":"team-a","method":"api_key"}`},
+					Body:   "test",
+				},
+				Caller: "team-a",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,586 @@
+package resultlog
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog/proto/turing"
+)
+
+const (
+	// spoolSegmentExt is the extension of the segment files of the spool
+	spoolSegmentExt = ".spool"
+	// spoolDeadLetterFile is the file of the spooled records, that failed to be replayed too many times.
+	// It has the same format as the segments, but it is not replayed.
+	spoolDeadLetterFile = "dead-letter"
+	// spoolHeaderSize is the size of the header of each spooled record, which holds the time at
+	// which the record was spooled, the length of the record and its CRC-32 checksum
+	spoolHeaderSize = 16
+
+	spoolDropReasonFull     = "full"
+	spoolDropReasonCorrupt  = "corrupt"
+	spoolDropReasonRejected = "rejected"
+)
+
+// SpoolLogger wraps a TuringResultLogger with a size-capped, on-disk write-ahead spool. The result
+// logs, that fail to be written to the result logger, are appended to the spool, and replayed in
+// order once the result logger recovers. While the spool has a backlog, the new result logs are
+// appended to it as well, so that they are written in order. The spooled result logs are written
+// at least once, i.e. they may be written again, if the router restarts during the replay, or if a
+// batch of result logs is spooled after it was partially written. A spooled result log, that fails
+// to be replayed too many times, e.g. because the result logger rejects it, is moved to the
+// dead-letter file of the spool, so that it doesn't block the result logs behind it.
+type SpoolLogger struct {
+	logger            TuringResultLogger
+	sink              config.ResultLogger
+	dir               string
+	maxBytes          int64
+	segmentBytes      int64
+	replayInterval    time.Duration
+	maxReplayAttempts int
+	closeTimeout      time.Duration
+
+	mu sync.Mutex
+	// segments are the segment files of the spool, from the oldest to the newest. The new records
+	// are appended to the last segment, and replayed from the first segment.
+	segments []*spoolSegment
+	writer   *os.File
+	reader   *os.File
+	// readOffset is the offset of the next record to replay, in the first segment
+	readOffset int64
+	entries    int
+	size       int64
+
+	// replayMu serializes the replays of the replay loop and of Close
+	replayMu sync.Mutex
+	// replayAttempts is the number of the failed replays of the first spooled record
+	replayAttempts int
+	done           chan struct{}
+	stopped        chan struct{}
+}
+
+type spoolSegment struct {
+	seq     uint64
+	path    string
+	size    int64
+	entries int
+}
+
+type spoolEntry struct {
+	message   *turing.TuringResultLogMessage
+	spooledAt time.Time
+	size      int64
+}
+
+// NewSpoolLogger creates a new SpoolLogger, that spools the result logs of the given result logger
+// in a sub-directory of the configured spool directory. The result logs, that were spooled before
+// the router restarted, are replayed as well.
+func NewSpoolLogger(
+	logger TuringResultLogger,
+	sink config.ResultLogger,
+	cfg *config.ResultLogSpoolConfig,
+) (*SpoolLogger, error) {
+	if cfg.MaxBytes <= 0 || cfg.SegmentBytes <= 0 || cfg.ReplayInterval <= 0 || cfg.MaxReplayAttempts <= 0 ||
+		cfg.CloseTimeout <= 0 {
+		return nil, errors.Newf(errors.BadConfig, "The max bytes, segment bytes, replay interval, "+
+			"max replay attempts and close timeout of the result log spool must be positive")
+	}
+	l := &SpoolLogger{
+		logger:            logger,
+		sink:              sink,
+		dir:               filepath.Join(cfg.Dir, strings.ToLower(string(sink))),
+		maxBytes:          cfg.MaxBytes,
+		segmentBytes:      cfg.SegmentBytes,
+		replayInterval:    cfg.ReplayInterval,
+		maxReplayAttempts: cfg.MaxReplayAttempts,
+		closeTimeout:      cfg.CloseTimeout,
+		done:              make(chan struct{}),
+		stopped:           make(chan struct{}),
+	}
+	if err := os.MkdirAll(l.dir, 0o750); err != nil {
+		return nil, errors.Wrapf(err, "Failed to create the result log spool directory %s", l.dir)
+	}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	if l.entries > 0 {
+		log.Glob().Infof("Found %d spooled result logs of %s, to be replayed", l.entries, sink)
+	}
+	l.recordDepth()
+
+	go l.run()
+	return l, nil
+}
+
+// write writes the message to the result logger, or appends it to the spool, if the result logger
+// fails or the spool has a backlog. It returns an error, if the message can't be spooled either.
+func (l *SpoolLogger) write(message *turing.TuringResultLogMessage) error {
+	return l.writeBatch([]*turing.TuringResultLogMessage{message})
+}
+
+// writeBatch writes the messages to the result logger, or appends all of them to the spool, if the
+// result logger fails or the spool has a backlog
+func (l *SpoolLogger) writeBatch(messages []*turing.TuringResultLogMessage) error {
+	if l.depth() == 0 {
+		err := writeBatch(l.logger, messages)
+		if err == nil {
+			return nil
+		}
+		log.Glob().Warnf("Failed to write the result logs to %s, spooling them: %s", l.sink, err.Error())
+	}
+
+	var failed int
+	var lastErr error
+	spooledAt := time.Now()
+	for _, message := range messages {
+		if err := l.spool(message, spooledAt); err != nil {
+			failed++
+			lastErr = err
+		}
+	}
+	if failed == 1 && len(messages) == 1 {
+		return lastErr
+	}
+	if failed > 0 {
+		return errors.Wrapf(lastErr, "Failed to spool %d of %d result logs", failed, len(messages))
+	}
+	return nil
+}
+
+// Close stops the replay of the spool, makes one last attempt to replay it, for up to the close
+// timeout, and closes the spool and the result logger. The result logs, that are still spooled,
+// are left on disk, and replayed on the next start.
+func (l *SpoolLogger) Close() error {
+	close(l.done)
+	<-l.stopped
+	ctx, cancel := context.WithTimeout(context.Background(), l.closeTimeout)
+	defer cancel()
+	l.replay(ctx)
+	if depth := l.depth(); depth > 0 {
+		log.Glob().Infof("Leaving %d spooled result logs of %s, to be replayed on the next start", depth, l.sink)
+	}
+
+	l.mu.Lock()
+	err := l.closeFiles()
+	l.mu.Unlock()
+	if err != nil {
+		log.Glob().Errorf("Failed to close the result log spool of %s: %s", l.sink, err.Error())
+	}
+	return l.logger.Close()
+}
+
+// run replays the spool at every replay interval, until the SpoolLogger is closed
+func (l *SpoolLogger) run() {
+	defer close(l.stopped)
+	ticker := time.NewTicker(l.replayInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+			l.replay(context.Background())
+		}
+	}
+}
+
+// replay writes the spooled result logs to the result logger, in order, until the spool is empty,
+// the result logger fails or the context is done. The first spooled result log is moved to the
+// dead-letter file, once it failed to be replayed the max replay attempts.
+func (l *SpoolLogger) replay(ctx context.Context) {
+	l.replayMu.Lock()
+	defer l.replayMu.Unlock()
+	for ctx.Err() == nil {
+		entry, err := l.next()
+		if err != nil {
+			log.Glob().Errorf("Failed to read the result log spool of %s: %s", l.sink, err.Error())
+			return
+		}
+		if entry == nil {
+			l.recordLag(0)
+			return
+		}
+		l.recordLag(time.Since(entry.spooledAt))
+		if err := l.logger.write(entry.message); err != nil {
+			l.replayAttempts++
+			if l.replayAttempts < l.maxReplayAttempts {
+				log.Glob().Warnf("Failed to replay the spooled result logs to %s: %s", l.sink, err.Error())
+				return
+			}
+			log.Glob().Errorf("Moving the result log %s, that failed to be replayed to %s %d times, "+
+				"to the dead-letter file: %s", entry.message.TuringReqId, l.sink, l.replayAttempts, err.Error())
+			l.recordDropped(spoolDropReasonRejected, 1)
+			l.deadLetter(entry)
+		}
+		l.replayAttempts = 0
+		l.ack(entry.size)
+	}
+}
+
+// deadLetter appends the spooled result log to the dead-letter file of the spool, where it can be
+// inspected. The dead-letter file is capped at the segment size, above which the result log is
+// only dropped.
+func (l *SpoolLogger) deadLetter(entry *spoolEntry) {
+	record, err := encodeSpoolRecord(entry.message, entry.spooledAt)
+	if err != nil {
+		log.Glob().Errorf("Failed to serialize the dead-letter result log: %s", err.Error())
+		return
+	}
+	path := filepath.Join(l.dir, spoolDeadLetterFile)
+	if info, err := os.Stat(path); err == nil && info.Size()+int64(len(record)) > l.segmentBytes {
+		log.Glob().Errorf("The result log dead-letter file %s is full, dropping the result log", path)
+		return
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o640)
+	if err != nil {
+		log.Glob().Errorf("Failed to open the result log dead-letter file %s: %s", path, err.Error())
+		return
+	}
+	defer file.Close()
+	if _, err := file.Write(record); err != nil {
+		log.Glob().Errorf("Failed to write the result log dead-letter file %s: %s", path, err.Error())
+	}
+}
+
+// spool appends the message to the last segment of the spool, or drops it, if the spool is full
+func (l *SpoolLogger) spool(message *turing.TuringResultLogMessage, spooledAt time.Time) error {
+	record, err := encodeSpoolRecord(message, spooledAt)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.size+int64(len(record)) > l.maxBytes {
+		l.recordDropped(spoolDropReasonFull, 1)
+		return errors.Newf(errors.Unknown, "The result log spool of %s is full", l.sink)
+	}
+	segment, err := l.openWriter(int64(len(record)))
+	if err != nil {
+		return err
+	}
+	if _, err := l.writer.Write(record); err != nil {
+		// Discard the partially written record, so that it isn't replayed
+		_ = l.writer.Truncate(segment.size)
+		return errors.Wrapf(err, "Failed to spool the result log")
+	}
+	segment.size += int64(len(record))
+	segment.entries++
+	l.size += int64(len(record))
+	l.entries++
+	l.recordDepth()
+	return nil
+}
+
+// openWriter opens the segment, that the record of the given size is appended to. A new segment
+// is started, if the record doesn't fit in the last segment.
+func (l *SpoolLogger) openWriter(recordSize int64) (*spoolSegment, error) {
+	var last *spoolSegment
+	if n := len(l.segments); n > 0 {
+		last = l.segments[n-1]
+	}
+	if last != nil && last.size > 0 && last.size+recordSize > l.segmentBytes {
+		if err := l.closeWriter(); err != nil {
+			return nil, err
+		}
+		last = nil
+	}
+	if l.writer != nil {
+		return last, nil
+	}
+
+	newSegment := last == nil
+	if newSegment {
+		seq := uint64(1)
+		if n := len(l.segments); n > 0 {
+			seq = l.segments[n-1].seq + 1
+		}
+		last = &spoolSegment{seq: seq, path: filepath.Join(l.dir, fmt.Sprintf("%020d%s", seq, spoolSegmentExt))}
+	}
+	writer, err := os.OpenFile(last.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o640)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to open the result log spool segment %s", last.path)
+	}
+	l.writer = writer
+	if newSegment {
+		l.segments = append(l.segments, last)
+	}
+	return last, nil
+}
+
+// next reads the next spooled result log, or returns nil, if the spool is empty. The corrupt
+// records are dropped.
+func (l *SpoolLogger) next() (*spoolEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for len(l.segments) > 0 {
+		segment := l.segments[0]
+		if l.readOffset >= segment.size {
+			if len(l.segments) == 1 {
+				return nil, nil
+			}
+			// The first segment is replayed
+			l.removeFirstSegment()
+			continue
+		}
+
+		if l.reader == nil {
+			reader, err := os.Open(segment.path)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Failed to open the result log spool segment %s", segment.path)
+			}
+			l.reader = reader
+		}
+		spooledAt, data, err := readSpoolRecord(l.reader, l.readOffset, segment.size)
+		if err != nil {
+			// The rest of the segment can't be read, if a record is corrupt
+			log.Glob().Errorf("Dropping %d corrupt result logs from %s: %s", segment.entries, segment.path, err.Error())
+			l.recordDropped(spoolDropReasonCorrupt, segment.entries)
+			l.removeFirstSegment()
+			continue
+		}
+		message := &turing.TuringResultLogMessage{}
+		if err := proto.Unmarshal(data, message); err != nil {
+			log.Glob().Errorf("Dropping a corrupt result log from %s: %s", segment.path, err.Error())
+			l.recordDropped(spoolDropReasonCorrupt, 1)
+			l.advance(int64(spoolHeaderSize + len(data)))
+			continue
+		}
+		return &spoolEntry{message: message, spooledAt: spooledAt, size: int64(spoolHeaderSize + len(data))}, nil
+	}
+	return nil, nil
+}
+
+// ack removes the replayed record of the given size from the spool
+func (l *SpoolLogger) ack(size int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.advance(size)
+}
+
+// advance moves the read offset past the record of the given size, and deletes the spool, once
+// all of its records are replayed
+func (l *SpoolLogger) advance(size int64) {
+	segment := l.segments[0]
+	l.readOffset += size
+	segment.entries--
+	l.entries--
+	if l.entries == 0 {
+		l.reset()
+	} else if l.readOffset >= segment.size && len(l.segments) > 1 {
+		l.removeFirstSegment()
+	}
+	l.recordDepth()
+}
+
+// removeFirstSegment deletes the first segment of the spool, with the records, that are left in it
+func (l *SpoolLogger) removeFirstSegment() {
+	if len(l.segments) == 1 {
+		l.reset()
+		return
+	}
+	segment := l.segments[0]
+	if l.reader != nil {
+		_ = l.reader.Close()
+		l.reader = nil
+	}
+	if err := os.Remove(segment.path); err != nil {
+		log.Glob().Errorf("Failed to delete the result log spool segment %s: %s", segment.path, err.Error())
+	}
+	l.segments = l.segments[1:]
+	l.size -= segment.size
+	l.entries -= segment.entries
+	l.readOffset = 0
+	l.recordDepth()
+}
+
+// reset deletes all of the segments of the spool
+func (l *SpoolLogger) reset() {
+	if err := l.closeFiles(); err != nil {
+		log.Glob().Errorf("Failed to close the result log spool of %s: %s", l.sink, err.Error())
+	}
+	for _, segment := range l.segments {
+		if err := os.Remove(segment.path); err != nil {
+			log.Glob().Errorf("Failed to delete the result log spool segment %s: %s", segment.path, err.Error())
+		}
+	}
+	l.segments = nil
+	l.size = 0
+	l.entries = 0
+	l.readOffset = 0
+	l.recordDepth()
+}
+
+func (l *SpoolLogger) closeWriter() error {
+	if l.writer == nil {
+		return nil
+	}
+	err := l.writer.Sync()
+	if closeErr := l.writer.Close(); err == nil {
+		err = closeErr
+	}
+	l.writer = nil
+	return err
+}
+
+func (l *SpoolLogger) closeFiles() error {
+	err := l.closeWriter()
+	if l.reader != nil {
+		if closeErr := l.reader.Close(); err == nil {
+			err = closeErr
+		}
+		l.reader = nil
+	}
+	return err
+}
+
+func (l *SpoolLogger) depth() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.entries
+}
+
+// load loads the segments of the spool, that were written before the router restarted. The torn
+// records at the end of the segments, e.g. if the router crashed while spooling, are truncated.
+func (l *SpoolLogger) load() error {
+	paths, err := filepath.Glob(filepath.Join(l.dir, "*"+spoolSegmentExt))
+	if err != nil {
+		return errors.Wrapf(err, "Failed to list the result log spool segments")
+	}
+	// The segments are named by their zero-padded sequence numbers, so they are sorted by name
+	sort.Strings(paths)
+	for _, path := range paths {
+		seq, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(path), spoolSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		segment := &spoolSegment{seq: seq, path: path}
+		if err := segment.scan(); err != nil {
+			return err
+		}
+		if segment.entries == 0 {
+			if err := os.Remove(path); err != nil {
+				return errors.Wrapf(err, "Failed to delete the empty result log spool segment %s", path)
+			}
+			continue
+		}
+		l.segments = append(l.segments, segment)
+		l.entries += segment.entries
+		l.size += segment.size
+	}
+	return nil
+}
+
+// scan counts the valid records of the segment, and truncates the segment after the last of them
+func (s *spoolSegment) scan() error {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to open the result log spool segment %s", s.path)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return errors.Wrapf(err, "Failed to read the result log spool segment %s", s.path)
+	}
+
+	for s.size < info.Size() {
+		_, data, err := readSpoolRecord(file, s.size, info.Size())
+		if err != nil {
+			break
+		}
+		s.size += int64(spoolHeaderSize + len(data))
+		s.entries++
+	}
+	if s.size < info.Size() {
+		log.Glob().Warnf("Truncating %d bytes of torn result logs from %s", info.Size()-s.size, s.path)
+		if err := os.Truncate(s.path, s.size); err != nil {
+			return errors.Wrapf(err, "Failed to truncate the result log spool segment %s", s.path)
+		}
+	}
+	return nil
+}
+
+// encodeSpoolRecord serializes the message into a spool record, with its header
+func encodeSpoolRecord(message *turing.TuringResultLogMessage, spooledAt time.Time) ([]byte, error) {
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to serialize the result log")
+	}
+	record := make([]byte, spoolHeaderSize+len(data))
+	binary.BigEndian.PutUint64(record[0:8], uint64(spooledAt.UnixNano()))
+	binary.BigEndian.PutUint32(record[8:12], uint32(len(data)))
+	binary.BigEndian.PutUint32(record[12:16], crc32.ChecksumIEEE(data))
+	copy(record[spoolHeaderSize:], data)
+	return record, nil
+}
+
+// readSpoolRecord reads the record at the given offset of the segment, and verifies its checksum
+func readSpoolRecord(file io.ReaderAt, offset int64, size int64) (time.Time, []byte, error) {
+	if offset+spoolHeaderSize > size {
+		return time.Time{}, nil, errors.Newf(errors.Unknown, "Incomplete record header at offset %d", offset)
+	}
+	header := make([]byte, spoolHeaderSize)
+	if _, err := file.ReadAt(header, offset); err != nil {
+		return time.Time{}, nil, err
+	}
+	spooledAt := time.Unix(0, int64(binary.BigEndian.Uint64(header[0:8])))
+	length := int64(binary.BigEndian.Uint32(header[8:12]))
+	if offset+spoolHeaderSize+length > size {
+		return time.Time{}, nil, errors.Newf(errors.Unknown, "Incomplete record at offset %d", offset)
+	}
+	data := make([]byte, length)
+	if _, err := file.ReadAt(data, offset+spoolHeaderSize); err != nil {
+		return time.Time{}, nil, err
+	}
+	if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(header[12:16]) {
+		return time.Time{}, nil, errors.Newf(errors.Unknown, "Checksum mismatch of the record at offset %d", offset)
+	}
+	return spooledAt, data, nil
+}
+
+func (l *SpoolLogger) recordDepth() {
+	if err := metrics.Glob().RecordGauge(
+		instrumentation.ResultLogSpoolDepth,
+		float64(l.entries),
+		map[string]string{"sink": string(l.sink)},
+	); err != nil {
+		log.Glob().Errorf("Failed to record the result log spool depth of %s: %s", l.sink, err.Error())
+	}
+}
+
+func (l *SpoolLogger) recordLag(lag time.Duration) {
+	if err := metrics.Glob().RecordGauge(
+		instrumentation.ResultLogSpoolReplayLagSeconds,
+		lag.Seconds(),
+		map[string]string{"sink": string(l.sink)},
+	); err != nil {
+		log.Glob().Errorf("Failed to record the result log spool replay lag of %s: %s", l.sink, err.Error())
+	}
+}
+
+func (l *SpoolLogger) recordDropped(reason string, count int) {
+	labels := map[string]string{"sink": string(l.sink), "reason": reason}
+	for i := 0; i < count; i++ {
+		if err := metrics.Glob().Inc(instrumentation.ResultLogSpoolDroppedTotal, labels); err != nil {
+			log.Glob().Errorf("Failed to record the dropped result logs of %s: %s", l.sink, err.Error())
+			return
+		}
+	}
+}
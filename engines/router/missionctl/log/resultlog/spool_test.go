@@ -0,0 +1,283 @@
+package resultlog
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog/proto/turing"
+)
+
+// recoveringResultLogger satisfies the TuringResultLogger interface, and fails to write while it is down
+type recoveringResultLogger struct {
+	mu   sync.Mutex
+	down bool
+	// latency is the time each write takes
+	latency time.Duration
+	// rejected are the IDs of the result logs, that always fail to be written
+	rejected map[string]bool
+	written  []string
+	closed   bool
+}
+
+func (l *recoveringResultLogger) write(message *turing.TuringResultLogMessage) error {
+	time.Sleep(l.latency)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.down {
+		return errors.New("sink unavailable")
+	}
+	if l.rejected[message.TuringReqId] {
+		return errors.New("invalid result log")
+	}
+	l.written = append(l.written, message.TuringReqId)
+	return nil
+}
+
+func (l *recoveringResultLogger) Close() error {
+	l.closed = true
+	return nil
+}
+
+func (l *recoveringResultLogger) setDown(down bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.down = down
+}
+
+func (l *recoveringResultLogger) writtenIDs() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string{}, l.written...)
+}
+
+// newTestSpoolMessage creates a result log, which record fills most of a spool segment of 64 bytes
+func newTestSpoolMessage(id string) *turing.TuringResultLogMessage {
+	return &turing.TuringResultLogMessage{TuringReqId: id, Request: &turing.Request{Body: `{"customer_id": "0123456789"}`}}
+}
+
+func newTestSpoolConfig(t *testing.T) *config.ResultLogSpoolConfig {
+	return &config.ResultLogSpoolConfig{
+		Dir:               t.TempDir(),
+		MaxBytes:          1 << 20,
+		SegmentBytes:      64,
+		ReplayInterval:    time.Hour,
+		MaxReplayAttempts: 3,
+		CloseTimeout:      time.Second,
+	}
+}
+
+func TestNewSpoolLoggerInvalidConfig(t *testing.T) {
+	_, err := NewSpoolLogger(&recoveringResultLogger{}, config.KafkaLogger, &config.ResultLogSpoolConfig{
+		Dir:      t.TempDir(),
+		MaxBytes: 1 << 20,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be positive")
+}
+
+func TestSpoolLoggerReplay(t *testing.T) {
+	inner := &recoveringResultLogger{}
+	spool, err := NewSpoolLogger(inner, config.KafkaLogger, newTestSpoolConfig(t))
+	require.NoError(t, err)
+
+	// The result logs are written directly, while the result logger is up
+	require.NoError(t, spool.write(newTestSpoolMessage("1")))
+	assert.Equal(t, 0, spool.depth())
+
+	// The result logs are spooled, while the result logger is down
+	inner.setDown(true)
+	require.NoError(t, spool.write(newTestSpoolMessage("2")))
+	require.NoError(t, spool.write(newTestSpoolMessage("3")))
+	spool.replay(context.Background())
+	assert.Equal(t, 2, spool.depth())
+
+	// The new result logs are spooled behind the backlog, once the result logger recovers
+	inner.setDown(false)
+	require.NoError(t, spool.write(newTestSpoolMessage("4")))
+	assert.Equal(t, 3, spool.depth())
+	// The small segments are rotated
+	segments, err := filepath.Glob(filepath.Join(spool.dir, "*"+spoolSegmentExt))
+	require.NoError(t, err)
+	assert.Len(t, segments, 3)
+
+	// The backlog is replayed in order, and the spool is deleted
+	spool.replay(context.Background())
+	assert.Equal(t, 0, spool.depth())
+	assert.Equal(t, []string{"1", "2", "3", "4"}, inner.writtenIDs())
+	segments, err = filepath.Glob(filepath.Join(spool.dir, "*"+spoolSegmentExt))
+	require.NoError(t, err)
+	assert.Empty(t, segments)
+
+	require.NoError(t, spool.write(newTestSpoolMessage("5")))
+	require.NoError(t, spool.Close())
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, inner.writtenIDs())
+	assert.True(t, inner.closed)
+}
+
+func TestSpoolLoggerFull(t *testing.T) {
+	inner := &recoveringResultLogger{down: true}
+	cfg := newTestSpoolConfig(t)
+	cfg.MaxBytes = 100
+	spool, err := NewSpoolLogger(inner, config.KafkaLogger, cfg)
+	require.NoError(t, err)
+
+	require.NoError(t, spool.write(newTestSpoolMessage("1")))
+	// The result logs are dropped, once the spool is full
+	err = spool.write(newTestSpoolMessage("2"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "The result log spool of KAFKA is full")
+	assert.Equal(t, 1, spool.depth())
+
+	inner.setDown(false)
+	require.NoError(t, spool.Close())
+	assert.Equal(t, []string{"1"}, inner.writtenIDs())
+}
+
+func TestSpoolLoggerDeadLetter(t *testing.T) {
+	inner := &recoveringResultLogger{rejected: map[string]bool{"1": true}}
+	spool, err := NewSpoolLogger(inner, config.KafkaLogger, newTestSpoolConfig(t))
+	require.NoError(t, err)
+
+	// The rejected result log is spooled, and so are the result logs behind it
+	require.NoError(t, spool.write(newTestSpoolMessage("1")))
+	require.NoError(t, spool.write(newTestSpoolMessage("2")))
+	assert.Equal(t, 2, spool.depth())
+
+	// The rejected result log blocks the spool, until it failed to be replayed the max replay attempts
+	spool.replay(context.Background())
+	spool.replay(context.Background())
+	assert.Equal(t, 2, spool.depth())
+	assert.Empty(t, inner.writtenIDs())
+	spool.replay(context.Background())
+	assert.Equal(t, 0, spool.depth())
+	assert.Equal(t, []string{"2"}, inner.writtenIDs())
+
+	// The rejected result log is moved to the dead-letter file, which is not replayed
+	file, err := os.Open(filepath.Join(spool.dir, spoolDeadLetterFile))
+	require.NoError(t, err)
+	defer file.Close()
+	info, err := file.Stat()
+	require.NoError(t, err)
+	_, data, err := readSpoolRecord(file, 0, info.Size())
+	require.NoError(t, err)
+	message := &turing.TuringResultLogMessage{}
+	require.NoError(t, proto.Unmarshal(data, message))
+	assert.Equal(t, "1", message.TuringReqId)
+
+	// The attempts are counted per result log
+	inner.setDown(true)
+	require.NoError(t, spool.write(newTestSpoolMessage("3")))
+	spool.replay(context.Background())
+	spool.replay(context.Background())
+	inner.setDown(false)
+	spool.replay(context.Background())
+	assert.Equal(t, []string{"2", "3"}, inner.writtenIDs())
+	require.NoError(t, spool.Close())
+}
+
+func TestSpoolLoggerRestart(t *testing.T) {
+	inner := &recoveringResultLogger{down: true}
+	cfg := newTestSpoolConfig(t)
+	spool, err := NewSpoolLogger(inner, config.BigqueryLogger, cfg)
+	require.NoError(t, err)
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, spool.write(newTestSpoolMessage(id)))
+	}
+	require.NoError(t, spool.Close())
+
+	// The torn record at the end of the last segment, e.g. from a crash, is truncated
+	segments, err := filepath.Glob(filepath.Join(cfg.Dir, "bigquery", "*"+spoolSegmentExt))
+	require.NoError(t, err)
+	require.Len(t, segments, 3)
+	file, err := os.OpenFile(segments[2], os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = file.Write([]byte{0, 0, 0})
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	// The spooled result logs are replayed, after the restart
+	inner = &recoveringResultLogger{}
+	spool, err = NewSpoolLogger(inner, config.BigqueryLogger, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 3, spool.depth())
+	require.NoError(t, spool.write(newTestSpoolMessage("4")))
+	spool.replay(context.Background())
+	assert.Equal(t, []string{"1", "2", "3", "4"}, inner.writtenIDs())
+	require.NoError(t, spool.Close())
+}
+
+func TestSpoolLoggerCloseTimeout(t *testing.T) {
+	inner := &recoveringResultLogger{down: true}
+	cfg := newTestSpoolConfig(t)
+	cfg.CloseTimeout = 50 * time.Millisecond
+	spool, err := NewSpoolLogger(inner, config.KafkaLogger, cfg)
+	require.NoError(t, err)
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		require.NoError(t, spool.write(newTestSpoolMessage(id)))
+	}
+
+	// The replay on close stops at the close timeout, and the rest of the spool is left on disk
+	inner.setDown(false)
+	inner.latency = 100 * time.Millisecond
+	require.NoError(t, spool.Close())
+	assert.Equal(t, []string{"1"}, inner.writtenIDs())
+	assert.True(t, inner.closed)
+
+	inner = &recoveringResultLogger{}
+	spool, err = NewSpoolLogger(inner, config.KafkaLogger, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 4, spool.depth())
+	require.NoError(t, spool.Close())
+	assert.Equal(t, []string{"2", "3", "4", "5"}, inner.writtenIDs())
+}
+
+func TestSpoolLoggerCorruptSegment(t *testing.T) {
+	inner := &recoveringResultLogger{down: true}
+	cfg := newTestSpoolConfig(t)
+	spool, err := NewSpoolLogger(inner, config.KafkaLogger, cfg)
+	require.NoError(t, err)
+	for _, id := range []string{"1", "2"} {
+		require.NoError(t, spool.write(newTestSpoolMessage(id)))
+	}
+
+	// The records of a corrupt segment are dropped, and the rest of the spool is replayed
+	segments, err := filepath.Glob(filepath.Join(spool.dir, "*"+spoolSegmentExt))
+	require.NoError(t, err)
+	require.Len(t, segments, 2)
+	file, err := os.OpenFile(segments[0], os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = file.WriteAt([]byte{0xff}, spoolHeaderSize)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	inner.setDown(false)
+	spool.replay(context.Background())
+	assert.Equal(t, 0, spool.depth())
+	assert.Equal(t, []string{"2"}, inner.writtenIDs())
+	require.NoError(t, spool.Close())
+}
+
+func TestSpoolLoggerReplayLoop(t *testing.T) {
+	inner := &recoveringResultLogger{down: true}
+	cfg := newTestSpoolConfig(t)
+	cfg.ReplayInterval = 10 * time.Millisecond
+	spool, err := NewSpoolLogger(inner, config.KafkaLogger, cfg)
+	require.NoError(t, err)
+	require.NoError(t, spool.write(newTestSpoolMessage("1")))
+
+	// The spool is replayed in the background, once the result logger recovers
+	inner.setDown(false)
+	assert.Eventually(t, func() bool { return spool.depth() == 0 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"1"}, inner.writtenIDs())
+	require.NoError(t, spool.Close())
+}
@@ -2,12 +2,16 @@ package resultlog
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router"
 	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/hedging"
 	"github.com/caraml-dev/turing/engines/router/missionctl/log"
 	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
 	"github.com/caraml-dev/universal-prediction-interface/pkg/converter"
@@ -18,11 +22,9 @@ import (
 
 // UPIResultLogger holds the logic how the RouterLog is being constructed,
 // the server will always provide GrpcRouterResponse and UPIResultLogger
-// will be responsible to construct it into TuringResultLogMessage or
+// will be responsible to construct it into TuringResultLogMessage and / or
 // RouterLog and writes to the destination with the underlying logger middleware
 type UPIResultLogger struct {
-	// loggerType is the ResultLoggerType configured in env var
-	loggerType config.ResultLogger
 	// UPILogger holds the detail of how the RouterLog is being written to the configured sink,
 	// currently expected to work with KafkaLogger only
 	upiLogger UPILogger
@@ -33,21 +35,55 @@ type UPIResultLogger struct {
 	routerName    string
 	routerVersion string
 	projectName   string
+	// policy samples the requests, which results are logged, and redacts the RouterLog
+	policy *Policy
+	// pending tracks the request summaries that are being logged asynchronously
+	pending sync.WaitGroup
 }
 
 // GrpcRouterResponse is sent to the result logger to construct RouterLog or TuringResultLog
 type GrpcRouterResponse struct {
-	// Key field is not used for now as there is only router,
-	// it can be used to differentiate enricher/ensembler/experiment engine response
+	// Key is used to differentiate the enricher / router / ensembler response
 	Key     string
 	Header  metadata.MD
 	Body    *upiv1.PredictValuesResponse
 	Err     string
 	ErrCode int
+	// Cached is true, if the response was served from the router's response cache
+	Cached bool
+	// Tolerated is true, if the error of a processing stage was tolerated by its error policy,
+	// in which case the response is not the router output
+	Tolerated bool
+	// HedgedRequests holds the hedged requests to the routes, only set for the Hedging key
+	HedgedRequests []*hedging.Record
+	// Timings holds the timings of the calls to the components and the routes, together with the
+	// selected route and traffic rule, only set for the Timing key
+	Timings *Timings
 }
 
 type UPILogger interface {
 	write(routerLog *upiv1.RouterLog) error
+	// Close flushes the buffered logs, if any, and releases the resources held by the logger
+	Close() error
+}
+
+const (
+	// routeIDHeaderKey is the RouterLog output header, that holds the ID of the route whose response
+	// was returned by the router
+	routeIDHeaderKey = "turing-route-id"
+	// timingsHeaderKey is the RouterLog output header, that holds the timings of the calls to the
+	// components and the routes, in JSON
+	timingsHeaderKey = "turing-timings"
+)
+
+// routerLogTiming is the JSON format of the timings in the RouterLog, which matches that of the
+// timings in the TuringResultLogMessage
+type routerLogTiming struct {
+	Component  string    `json:"component"`
+	RouteID    string    `json:"route_id,omitempty"`
+	StartTime  time.Time `json:"start_time"`
+	DurationMs float64   `json:"duration_ms"`
+	StatusCode int       `json:"status_code"`
 }
 
 var loggingErrorTemplate = "logging error. unable to convert table to struct for %s : %s"
@@ -56,16 +92,20 @@ var convertorTableSchema uint32 = converter.TableSchemaV1
 
 var routerNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_\-]+-\d+.[a-zA-Z0-9\-]+$`)
 
+// InitUPIResultLogger initializes the result logger of the UPI routers, which logs the RouterLog with
+// the given UPILogger and / or the TuringResultLogMessage with the given ResultLogger, whichever are set.
+// The requests, which results are logged, are sampled with the given policy, if set, which also redacts
+// the RouterLog. The TuringResultLogMessage is redacted by the policy of the ResultLogger.
 func InitUPIResultLogger(
 	appName string,
-	loggerType config.ResultLogger,
 	upiLogger UPILogger,
-	resultLogger *ResultLogger) (*UPIResultLogger, error) {
+	resultLogger *ResultLogger,
+	policy *Policy) (*UPIResultLogger, error) {
 
 	upiResultLogger := &UPIResultLogger{
 		upiLogger:          upiLogger,
 		turingResultLogger: resultLogger,
-		loggerType:         loggerType,
+		policy:             policy,
 	}
 	if !routerNameRegex.MatchString(appName) {
 		return nil, fmt.Errorf("invalid router name")
@@ -87,11 +127,88 @@ func (ul *UPIResultLogger) LogTuringRouterRequestSummary(
 	upiReq *upiv1.PredictValuesRequest,
 	mcRespCh <-chan GrpcRouterResponse,
 ) {
-	if ul.loggerType == config.UPILogger {
+	switch {
+	case !ul.policy.SampleUPIRequest(header, upiReq):
+		for range mcRespCh {
+			// Drain the responses, as the result of the request is not logged
+		}
+	case ul.upiLogger != nil && ul.turingResultLogger != nil:
+		// Both logs are built from the same responses, which are collected to be read twice
+		var responses []GrpcRouterResponse
+		for resp := range mcRespCh {
+			responses = append(responses, resp)
+		}
+		logRouterLog(header, upiReq, replayResponses(responses), ul)
+		logTuringResultLog(header, upiReq, replayResponses(responses), ul)
+	case ul.upiLogger != nil:
 		logRouterLog(header, upiReq, mcRespCh, ul)
-	} else {
+	case ul.turingResultLogger != nil:
 		logTuringResultLog(header, upiReq, mcRespCh, ul)
+	default:
+		for range mcRespCh {
+			// Drain the responses, as there is no logger to write them to
+		}
+	}
+}
+
+// replayResponses returns a closed channel, that yields the given responses
+func replayResponses(responses []GrpcRouterResponse) <-chan GrpcRouterResponse {
+	ch := make(chan GrpcRouterResponse, len(responses))
+	for _, resp := range responses {
+		ch <- resp
+	}
+	close(ch)
+	return ch
+}
+
+// Log runs the given function, which logs a request summary. If the router logs and the other result
+// logs are queued, it's run in the calling goroutine, as it only builds the logs and enqueues them.
+// Otherwise, it's run in a new goroutine with LogAsync, so that the request doesn't wait for the loggers.
+func (ul *UPIResultLogger) Log(logFn func()) {
+	if ul.queued() {
+		logFn()
+		return
+	}
+	ul.LogAsync(logFn)
+}
+
+// queued returns whether all the configured loggers enqueue the logs
+func (ul *UPIResultLogger) queued() bool {
+	if ul.upiLogger == nil && ul.turingResultLogger == nil {
+		return false
+	}
+	if _, ok := ul.upiLogger.(*UPIQueueLogger); ul.upiLogger != nil && !ok {
+		return false
+	}
+	return ul.turingResultLogger == nil || ul.turingResultLogger.queued()
+}
+
+// LogAsync runs the given function, which logs a request summary, in a new goroutine. The goroutine
+// is tracked, so that the result logger waits for it to complete when it is closed.
+func (ul *UPIResultLogger) LogAsync(logFn func()) {
+	ul.pending.Add(1)
+	go func() {
+		defer ul.pending.Done()
+		logFn()
+	}()
+}
+
+// Close waits for the request summaries that are being logged to complete, and then closes the
+// underlying logger, flushing any buffered logs. If the context is done first, the underlying
+// logger is left open, as it's still in use.
+func (ul *UPIResultLogger) Close(ctx context.Context) error {
+	if err := waitForPendingLogs(ctx, &ul.pending); err != nil {
+		return err
+	}
+	if ul.upiLogger != nil {
+		if err := ul.upiLogger.Close(); err != nil {
+			return err
+		}
 	}
+	if ul.turingResultLogger != nil {
+		return ul.turingResultLogger.Close(ctx)
+	}
+	return nil
 }
 
 func (ul *UPIResultLogger) logEntry(log *upiv1.RouterLog) error {
@@ -133,6 +250,67 @@ func (ul *UPIResultLogger) SendResponseToLogChannel(
 	}
 }
 
+// SendStageResponseToLogChannel sends the response from a processing stage to the given channel
+// as a RouterResponse object, with the result log key of the stage
+func (ul *UPIResultLogger) SendStageResponseToLogChannel(
+	ch chan<- GrpcRouterResponse,
+	stage *router.ProcessingStage,
+	md metadata.MD,
+	r *upiv1.PredictValuesResponse,
+	err *errors.TuringError) {
+
+	if err != nil {
+		ch <- GrpcRouterResponse{
+			Key:       stage.LogKey(),
+			Header:    md,
+			Err:       err.Message,
+			ErrCode:   err.Code,
+			Tolerated: stage.IsErrorTolerated(),
+		}
+		return
+	}
+
+	ch <- GrpcRouterResponse{
+		Key:    stage.LogKey(),
+		Header: md,
+		Body:   r,
+	}
+}
+
+// SendCachedResponseToLogChannel sends the response served from the router's response cache
+// to the given channel as a RouterResponse object
+func (ul *UPIResultLogger) SendCachedResponseToLogChannel(
+	ch chan<- GrpcRouterResponse,
+	key string,
+	r *upiv1.PredictValuesResponse) {
+	ch <- GrpcRouterResponse{
+		Key:    key,
+		Body:   r,
+		Cached: true,
+	}
+}
+
+// SendHedgedRequestsToLogChannel sends the hedged requests to the routes to the given channel
+// as a single RouterResponse object
+func (ul *UPIResultLogger) SendHedgedRequestsToLogChannel(ch chan<- GrpcRouterResponse, records []*hedging.Record) {
+	if len(records) == 0 {
+		return
+	}
+	ch <- GrpcRouterResponse{
+		Key:            ResultLogKeys.Hedging,
+		HedgedRequests: records,
+	}
+}
+
+// SendTimingsToLogChannel sends the timings of the calls to the components and the routes to the
+// given channel as a single GrpcRouterResponse object
+func (ul *UPIResultLogger) SendTimingsToLogChannel(ch chan<- GrpcRouterResponse, timings *Timings) {
+	ch <- GrpcRouterResponse{
+		Key:     ResultLogKeys.Timing,
+		Timings: timings,
+	}
+}
+
 func logTuringResultLog(
 	header metadata.MD,
 	req *upiv1.PredictValuesRequest,
@@ -150,6 +328,17 @@ func logTuringResultLog(
 
 	// Read incoming responses and prepare for logging
 	for resp := range mcRespCh {
+		if resp.Key == ResultLogKeys.Hedging {
+			addHedgedRequests(logEntry, resp.HedgedRequests)
+			continue
+		}
+		if resp.Key == ResultLogKeys.Timing {
+			addTimings(logEntry, resp.Timings)
+			continue
+		}
+		if resp.Cached {
+			logEntry.CacheHit = true
+		}
 		// If error exists, add an error record
 		if resp.Err != "" {
 			AddResponse(logEntry, resp.Key, "", nil, resp.Err)
@@ -170,8 +359,41 @@ func logRouterLog(header metadata.MD,
 	upiReq *upiv1.PredictValuesRequest,
 	mcRespCh <-chan GrpcRouterResponse,
 	ul *UPIResultLogger) {
-	grpcResp := <-mcRespCh
-	upiResp := grpcResp.Body
+	// Read incoming responses, the response of the last postprocessing stage or the ensembler (if any)
+	// is the final router output
+	var routerResp, outputResp GrpcRouterResponse
+	var timings *Timings
+	for resp := range mcRespCh {
+		switch resp.Key {
+		case ResultLogKeys.Router:
+			routerResp = resp
+			if outputResp.Key == "" {
+				outputResp = resp
+			}
+		case ResultLogKeys.Ensembler:
+			outputResp = resp
+		case ResultLogKeys.Timing:
+			timings = resp.Timings
+		case ResultLogKeys.Enricher:
+			// The router is not called, if the enricher fails, so its error is the router output
+			if resp.Err != "" {
+				outputResp = resp
+			}
+		case ResultLogKeys.Hedging:
+			// Not the router output
+		default:
+			// The processing stages that run before the router are preprocessing stages, which responses
+			// are not the router output, unless they fail the request. The tolerated errors of the
+			// processing stages are not the router output either.
+			if resp.Tolerated {
+				continue
+			}
+			if routerResp.Key != "" || resp.Err != "" {
+				outputResp = resp
+			}
+		}
+	}
+	upiResp := outputResp.Body
 
 	var predictionTable *structpb.Struct
 	var err error
@@ -188,7 +410,12 @@ func logRouterLog(header metadata.MD,
 		return
 	}
 
-	// traffic-rule is returned in fiber response with `traffic-rule` key
+	// traffic-rule is returned in fiber response with `traffic-rule` key. It falls back to the recorded
+	// traffic rule, which is also set when the routes fail.
+	trafficRule := strings.Join(routerResp.Header.Get("traffic-rule"), "")
+	if trafficRule == "" && timings != nil {
+		trafficRule = timings.TrafficRule
+	}
 	routerLog := &upiv1.RouterLog{
 		PredictionId:  upiReq.GetMetadata().GetPredictionId(),
 		TargetName:    upiReq.GetTargetName(),
@@ -196,10 +423,10 @@ func logRouterLog(header metadata.MD,
 		RouterVersion: ul.routerVersion,
 		ProjectName:   ul.projectName,
 		RoutingLogic: &upiv1.RoutingLogic{
-			Models:         upiResp.GetMetadata().GetModels(),
-			TrafficRule:    strings.Join(grpcResp.Header.Get("traffic-rule"), ""),
-			ExperimentName: upiResp.GetMetadata().GetExperimentName(),
-			TreatmentName:  upiResp.GetMetadata().GetTreatmentName(),
+			Models:         routerResp.Body.GetMetadata().GetModels(),
+			TrafficRule:    trafficRule,
+			ExperimentName: routerResp.Body.GetMetadata().GetExperimentName(),
+			TreatmentName:  routerResp.Body.GetMetadata().GetTreatmentName(),
 		},
 		RouterInput: &upiv1.RouterInput{
 			PredictionTable:      predictionTable,
@@ -210,15 +437,24 @@ func logRouterLog(header metadata.MD,
 		},
 		RouterOutput: &upiv1.RouterOutput{
 			PredictionContext: upiResp.GetPredictionContext(),
-			Headers:           convertToUPIHeader(grpcResp.Header),
+			Headers:           convertToUPIHeader(outputResp.Header),
 		},
 		RequestTimestamp:   upiReq.GetMetadata().GetRequestTimestamp(),
 		TableSchemaVersion: convertorTableSchema,
 	}
 
-	if grpcResp.ErrCode != int(codes.OK) {
-		routerLog.RouterOutput.Message = grpcResp.Err
-		routerLog.RouterOutput.Status = uint32(grpcResp.ErrCode)
+	// The RouterLog is defined by UPI, so it has no place for the timings and the selected route,
+	// which are logged in the output headers instead
+	timingHeaders, err := convertTimingsToUPIHeaders(timings)
+	if err != nil {
+		log.Glob().Errorf(loggingErrorTemplate, "timings", err.Error())
+		return
+	}
+	routerLog.RouterOutput.Headers = append(routerLog.RouterOutput.Headers, timingHeaders...)
+
+	if outputResp.ErrCode != int(codes.OK) {
+		routerLog.RouterOutput.Message = outputResp.Err
+		routerLog.RouterOutput.Status = uint32(outputResp.ErrCode)
 	} else {
 		var predictionResultTable *structpb.Struct
 		if upiResp.GetPredictionResultTable() != nil {
@@ -232,6 +468,12 @@ func logRouterLog(header metadata.MD,
 		routerLog.RouterOutput.PredictionResultsTable = predictionResultTable
 	}
 
+	// The RouterLog is not written, if it can't be redacted, so that the redacted fields are not leaked
+	if err := ul.policy.RedactRouterLog(routerLog); err != nil {
+		log.Glob().Errorf("Result Logging Error: %s", err.Error())
+		return
+	}
+
 	// Log the responses. If an error occurs in logging the result to the
 	// configured result log destination, log the error.
 	if err := ul.logEntry(routerLog); err != nil {
@@ -251,6 +493,35 @@ func convertToUPIHeader(md metadata.MD) []*upiv1.Header {
 	return headers
 }
 
+// convertTimingsToUPIHeaders returns the headers, that hold the selected route and the timings, if any
+func convertTimingsToUPIHeaders(timings *Timings) ([]*upiv1.Header, error) {
+	if timings == nil {
+		return nil, nil
+	}
+	var headers []*upiv1.Header
+	if timings.RouteID != "" {
+		headers = append(headers, &upiv1.Header{Key: routeIDHeaderKey, Value: timings.RouteID})
+	}
+	if len(timings.Records) > 0 {
+		records := make([]routerLogTiming, 0, len(timings.Records))
+		for _, record := range timings.Records {
+			records = append(records, routerLogTiming{
+				Component:  record.Component,
+				RouteID:    record.RouteID,
+				StartTime:  record.StartTime.UTC(),
+				DurationMs: float64(record.Duration) / float64(time.Millisecond),
+				StatusCode: record.StatusCode,
+			})
+		}
+		value, err := json.Marshal(records)
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, &upiv1.Header{Key: timingsHeaderKey, Value: string(value)})
+	}
+	return headers, nil
+}
+
 func convertTransformerTable(tables []*upiv1.Table) ([]*structpb.Struct, error) {
 	var t []*structpb.Struct
 	for _, table := range tables {
@@ -15,9 +15,10 @@ import (
 	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
 	"github.com/caraml-dev/universal-prediction-interface/pkg/converter"
 
-	"github.com/caraml-dev/turing/engines/router/missionctl/config"
 	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/hedging"
 	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog/proto/turing"
+	"github.com/caraml-dev/turing/engines/router/missionctl/timing"
 
 	fiberProtocol "github.com/gojek/fiber/protocol"
 )
@@ -49,7 +50,7 @@ func Test_InitUPIResultLogger(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := InitUPIResultLogger(tt.args.appName, "", nil, nil)
+			_, err := InitUPIResultLogger(tt.args.appName, nil, nil, nil)
 			if tt.errMsg != "" {
 				assert.Equal(t, tt.errMsg, err.Error())
 			} else {
@@ -135,6 +136,10 @@ func (l *mockUPILogger) write(log *upiv1.RouterLog) error {
 	return nil
 }
 
+func (l *mockUPILogger) Close() error {
+	return nil
+}
+
 // mockUPILogger is injected to the upiLogger, so that the mapping logic of this function can be
 // tested and verified
 func TestUPIResultLogger_LogTuringRouterRequestSummary_logRouterLog(t *testing.T) {
@@ -188,10 +193,12 @@ func TestUPIResultLogger_LogTuringRouterRequestSummary_logRouterLog(t *testing.T
 	}
 
 	type args struct {
-		reqHeader    metadata.MD
-		upiReq       *upiv1.PredictValuesRequest
-		routerResp   GrpcRouterResponse
-		resultLogger *UPIResultLogger
+		reqHeader     metadata.MD
+		upiReq        *upiv1.PredictValuesRequest
+		routerResp    GrpcRouterResponse
+		ensemblerResp *GrpcRouterResponse
+		stageResps    []GrpcRouterResponse
+		resultLogger  *UPIResultLogger
 	}
 	tests := []struct {
 		name           string
@@ -204,7 +211,6 @@ func TestUPIResultLogger_LogTuringRouterRequestSummary_logRouterLog(t *testing.T
 			args: args{
 				resultLogger: &UPIResultLogger{
 					upiLogger:     &mockUPILogger{},
-					loggerType:    config.UPILogger,
 					routerName:    routerName,
 					routerVersion: routerVersion,
 					projectName:   projectName,
@@ -237,7 +243,7 @@ func TestUPIResultLogger_LogTuringRouterRequestSummary_logRouterLog(t *testing.T
 						RequestTimestamp: time,
 					},
 				},
-				resultLogger: &UPIResultLogger{upiLogger: &mockUPILogger{}, loggerType: config.UPILogger},
+				resultLogger: &UPIResultLogger{upiLogger: &mockUPILogger{}},
 			},
 			want: &upiv1.RouterLog{
 				PredictionId: "123",
@@ -263,8 +269,9 @@ func TestUPIResultLogger_LogTuringRouterRequestSummary_logRouterLog(t *testing.T
 		{
 			name: "predict request only without err",
 			args: args{
-				resultLogger: &UPIResultLogger{upiLogger: &mockUPILogger{}, loggerType: config.UPILogger},
+				resultLogger: &UPIResultLogger{upiLogger: &mockUPILogger{}},
 				routerResp: GrpcRouterResponse{
+					Key:    ResultLogKeys.Router,
 					Header: metadata.Pairs("traffic-rule", "rule3"),
 					Body: &upiv1.PredictValuesResponse{
 						PredictionResultTable: predictionTable,
@@ -303,8 +310,9 @@ func TestUPIResultLogger_LogTuringRouterRequestSummary_logRouterLog(t *testing.T
 		{
 			name: "predict request only with err",
 			args: args{
-				resultLogger: &UPIResultLogger{upiLogger: &mockUPILogger{}, loggerType: config.UPILogger},
+				resultLogger: &UPIResultLogger{upiLogger: &mockUPILogger{}},
 				routerResp: GrpcRouterResponse{
+					Key:     ResultLogKeys.Router,
 					Err:     "no response from model",
 					ErrCode: 13,
 				},
@@ -319,6 +327,261 @@ func TestUPIResultLogger_LogTuringRouterRequestSummary_logRouterLog(t *testing.T
 				},
 			},
 		},
+		{
+			name: "predict request with err and recorded traffic rule",
+			args: args{
+				resultLogger: &UPIResultLogger{upiLogger: &mockUPILogger{}},
+				routerResp: GrpcRouterResponse{
+					Key:     ResultLogKeys.Router,
+					Err:     "no response from model",
+					ErrCode: 13,
+				},
+				stageResps: []GrpcRouterResponse{
+					{
+						Key:     ResultLogKeys.Timing,
+						Timings: &Timings{TrafficRule: "rule3"},
+					},
+				},
+			},
+			want: &upiv1.RouterLog{
+				TableSchemaVersion: convertorTableSchema,
+				RoutingLogic:       &upiv1.RoutingLogic{TrafficRule: "rule3"},
+				RouterInput:        &upiv1.RouterInput{},
+				RouterOutput: &upiv1.RouterOutput{
+					Status:  13,
+					Message: "no response from model",
+				},
+			},
+		},
+		{
+			name: "predict request with selected route",
+			args: args{
+				resultLogger: &UPIResultLogger{upiLogger: &mockUPILogger{}},
+				routerResp: GrpcRouterResponse{
+					Key:    ResultLogKeys.Router,
+					Header: metadata.Pairs("traffic-rule", "rule3"),
+				},
+				stageResps: []GrpcRouterResponse{
+					{
+						Key:     ResultLogKeys.Timing,
+						Timings: &Timings{RouteID: "route-a", TrafficRule: "rule3"},
+					},
+				},
+			},
+			want: &upiv1.RouterLog{
+				TableSchemaVersion: convertorTableSchema,
+				RoutingLogic:       &upiv1.RoutingLogic{TrafficRule: "rule3"},
+				RouterInput:        &upiv1.RouterInput{},
+				RouterOutput: &upiv1.RouterOutput{
+					Headers: []*upiv1.Header{
+						{
+							Key:   "traffic-rule",
+							Value: "rule3",
+						},
+						{
+							Key:   "turing-route-id",
+							Value: "route-a",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "predict request with ensembler",
+			args: args{
+				resultLogger: &UPIResultLogger{upiLogger: &mockUPILogger{}},
+				routerResp: GrpcRouterResponse{
+					Key:    ResultLogKeys.Router,
+					Header: metadata.Pairs("traffic-rule", "rule3"),
+					Body: &upiv1.PredictValuesResponse{
+						PredictionResultTable: &upiv1.Table{Name: "router-table"},
+						Metadata: &upiv1.ResponseMetadata{
+							Models:         modelMedata,
+							ExperimentName: "experiment",
+							TreatmentName:  "treatment",
+						},
+					},
+				},
+				ensemblerResp: &GrpcRouterResponse{
+					Key:    ResultLogKeys.Ensembler,
+					Header: metadata.Pairs("k2", "v2"),
+					Body: &upiv1.PredictValuesResponse{
+						PredictionResultTable: predictionTable,
+						PredictionContext:     predictionContext,
+					},
+				},
+			},
+			want: &upiv1.RouterLog{
+				TableSchemaVersion: convertorTableSchema,
+				RoutingLogic: &upiv1.RoutingLogic{
+					Models:         modelMedata,
+					TrafficRule:    "rule3",
+					ExperimentName: "experiment",
+					TreatmentName:  "treatment",
+				},
+				RouterInput: &upiv1.RouterInput{},
+				RouterOutput: &upiv1.RouterOutput{
+					PredictionResultsTable: predictionTableStruct,
+					PredictionContext:      predictionContext,
+					Headers: []*upiv1.Header{
+						{
+							Key:   "k2",
+							Value: "v2",
+						},
+					},
+					Status: 0,
+				},
+			},
+		},
+		{
+			name: "predict request with ensembler err",
+			args: args{
+				resultLogger: &UPIResultLogger{upiLogger: &mockUPILogger{}},
+				routerResp: GrpcRouterResponse{
+					Key: ResultLogKeys.Router,
+					Body: &upiv1.PredictValuesResponse{
+						Metadata: &upiv1.ResponseMetadata{
+							ExperimentName: "experiment",
+							TreatmentName:  "treatment",
+						},
+					},
+				},
+				ensemblerResp: &GrpcRouterResponse{
+					Key:     ResultLogKeys.Ensembler,
+					Err:     "ensembler timeout",
+					ErrCode: 4,
+				},
+			},
+			want: &upiv1.RouterLog{
+				TableSchemaVersion: convertorTableSchema,
+				RoutingLogic: &upiv1.RoutingLogic{
+					ExperimentName: "experiment",
+					TreatmentName:  "treatment",
+				},
+				RouterInput: &upiv1.RouterInput{},
+				RouterOutput: &upiv1.RouterOutput{
+					Status:  4,
+					Message: "ensembler timeout",
+				},
+			},
+		},
+		{
+			name: "predict request with postprocessing stages",
+			args: args{
+				resultLogger: &UPIResultLogger{upiLogger: &mockUPILogger{}},
+				routerResp: GrpcRouterResponse{
+					Key:  ResultLogKeys.Router,
+					Body: &upiv1.PredictValuesResponse{PredictionResultTable: &upiv1.Table{Name: "router-table"}},
+				},
+				stageResps: []GrpcRouterResponse{
+					{
+						Key:    "calibration",
+						Header: metadata.Pairs("k3", "v3"),
+						Body: &upiv1.PredictValuesResponse{
+							PredictionResultTable: predictionTable,
+							PredictionContext:     predictionContext,
+						},
+					},
+					{
+						Key:       "business-rules",
+						Err:       "stage timeout",
+						ErrCode:   4,
+						Tolerated: true,
+					},
+				},
+			},
+			want: &upiv1.RouterLog{
+				TableSchemaVersion: convertorTableSchema,
+				RoutingLogic:       &upiv1.RoutingLogic{},
+				RouterInput:        &upiv1.RouterInput{},
+				RouterOutput: &upiv1.RouterOutput{
+					PredictionResultsTable: predictionTableStruct,
+					PredictionContext:      predictionContext,
+					Headers: []*upiv1.Header{
+						{
+							Key:   "k3",
+							Value: "v3",
+						},
+					},
+					Status: 0,
+				},
+			},
+		},
+		{
+			name: "predict request with postprocessing stage err",
+			args: args{
+				resultLogger: &UPIResultLogger{upiLogger: &mockUPILogger{}},
+				routerResp: GrpcRouterResponse{
+					Key:  ResultLogKeys.Router,
+					Body: &upiv1.PredictValuesResponse{PredictionResultTable: predictionTable},
+				},
+				stageResps: []GrpcRouterResponse{
+					{
+						Key:     "calibration",
+						Err:     "stage unavailable",
+						ErrCode: 14,
+					},
+				},
+			},
+			want: &upiv1.RouterLog{
+				TableSchemaVersion: convertorTableSchema,
+				RoutingLogic:       &upiv1.RoutingLogic{},
+				RouterInput:        &upiv1.RouterInput{},
+				RouterOutput: &upiv1.RouterOutput{
+					Status:  14,
+					Message: "stage unavailable",
+				},
+			},
+		},
+		{
+			name: "predict request with enricher err",
+			args: args{
+				resultLogger: &UPIResultLogger{upiLogger: &mockUPILogger{}},
+				stageResps: []GrpcRouterResponse{
+					{
+						Key:     ResultLogKeys.Enricher,
+						Err:     "enricher unavailable",
+						ErrCode: 14,
+					},
+				},
+			},
+			want: &upiv1.RouterLog{
+				TableSchemaVersion: convertorTableSchema,
+				RoutingLogic:       &upiv1.RoutingLogic{},
+				RouterInput:        &upiv1.RouterInput{},
+				RouterOutput: &upiv1.RouterOutput{
+					Status:  14,
+					Message: "enricher unavailable",
+				},
+			},
+		},
+		{
+			name: "predict request with preprocessing stage err",
+			args: args{
+				resultLogger: &UPIResultLogger{upiLogger: &mockUPILogger{}},
+				stageResps: []GrpcRouterResponse{
+					{
+						Key:    ResultLogKeys.Enricher,
+						Header: metadata.Pairs("k1", "v1"),
+						Body:   &upiv1.PredictValuesResponse{},
+					},
+					{
+						Key:     "validation",
+						Err:     "invalid request",
+						ErrCode: 3,
+					},
+				},
+			},
+			want: &upiv1.RouterLog{
+				TableSchemaVersion: convertorTableSchema,
+				RoutingLogic:       &upiv1.RoutingLogic{},
+				RouterInput:        &upiv1.RouterInput{},
+				RouterOutput: &upiv1.RouterOutput{
+					Status:  3,
+					Message: "invalid request",
+				},
+			},
+		},
 		{
 			name: "predict request; mismatch number of columns and number of values in a row",
 			args: args{
@@ -365,7 +628,7 @@ func TestUPIResultLogger_LogTuringRouterRequestSummary_logRouterLog(t *testing.T
 						RequestTimestamp: time,
 					},
 				},
-				resultLogger: &UPIResultLogger{upiLogger: &mockUPILogger{}, loggerType: config.UPILogger},
+				resultLogger: &UPIResultLogger{upiLogger: &mockUPILogger{}},
 			},
 			expectLogError: true,
 		},
@@ -373,8 +636,14 @@ func TestUPIResultLogger_LogTuringRouterRequestSummary_logRouterLog(t *testing.T
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			respCh := make(chan GrpcRouterResponse, 1)
+			respCh := make(chan GrpcRouterResponse, 2+len(tt.args.stageResps))
 			respCh <- tt.args.routerResp
+			if tt.args.ensemblerResp != nil {
+				respCh <- *tt.args.ensemblerResp
+			}
+			for _, stageResp := range tt.args.stageResps {
+				respCh <- stageResp
+			}
 			close(respCh)
 			tt.args.resultLogger.LogTuringRouterRequestSummary(tt.args.reqHeader, tt.args.upiReq, respCh)
 			mockLogger, ok := tt.args.resultLogger.upiLogger.(*mockUPILogger)
@@ -389,6 +658,62 @@ func TestUPIResultLogger_LogTuringRouterRequestSummary_logRouterLog(t *testing.T
 	}
 }
 
+func TestConvertTimingsToUPIHeaders(t *testing.T) {
+	startTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := map[string]struct {
+		timings  *Timings
+		expected []*upiv1.Header
+	}{
+		"no timings": {},
+		"combined responses": {
+			timings: &Timings{
+				Records: []*timing.Record{{Component: "router", StartTime: startTime, Duration: 2 * time.Millisecond}},
+			},
+			expected: []*upiv1.Header{
+				{
+					Key:   "turing-timings",
+					Value: `[{"component":"router","start_time":"2024-01-02T03:04:05Z","duration_ms":2,"status_code":0}]`,
+				},
+			},
+		},
+		"selected route": {
+			timings: &Timings{
+				Records: []*timing.Record{
+					{Component: "router", StartTime: startTime, Duration: 2500 * time.Microsecond},
+					{
+						Component:  timing.RouteComponent,
+						RouteID:    "route-a",
+						StartTime:  startTime.Add(time.Millisecond),
+						Duration:   time.Millisecond,
+						StatusCode: 0,
+					},
+				},
+				RouteID: "route-a",
+			},
+			expected: []*upiv1.Header{
+				{
+					Key:   "turing-route-id",
+					Value: "route-a",
+				},
+				{
+					Key: "turing-timings",
+					Value: `[{"component":"router","start_time":"2024-01-02T03:04:05Z","duration_ms":2.5,"status_code":0},` +
+						`{"component":"route","route_id":"route-a","start_time":"2024-01-02T03:04:05.001Z",` +
+						`"duration_ms":1,"status_code":0}]`,
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			headers, err := convertTimingsToUPIHeaders(tt.timings)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, headers)
+		})
+	}
+}
+
 func TestUPIResultLogger_LogTuringRouterRequestSummary_logTuringResultLog(t *testing.T) {
 
 	appName := "appName"
@@ -445,7 +770,6 @@ func TestUPIResultLogger_LogTuringRouterRequestSummary_logTuringResultLog(t *tes
 						trl:     &mockResultLogger{},
 						appName: appName,
 					},
-					loggerType: config.BigqueryLogger,
 				},
 			},
 			want: &turing.TuringResultLogMessage{
@@ -462,6 +786,68 @@ func TestUPIResultLogger_LogTuringRouterRequestSummary_logTuringResultLog(t *tes
 				},
 			},
 		},
+		{
+			name: "hedged requests",
+			args: args{
+				reqHeader: metadata.Pairs("req", "header"),
+				upiReq:    upiReq,
+				routerResp: GrpcRouterResponse{
+					Key:            ResultLogKeys.Hedging,
+					HedgedRequests: []*hedging.Record{{RouteID: "route-a", Winner: hedging.HedgeCopy}},
+				},
+				resultLogger: &UPIResultLogger{
+					turingResultLogger: &ResultLogger{
+						trl:     &mockResultLogger{},
+						appName: appName,
+					},
+				},
+			},
+			want: &turing.TuringResultLogMessage{
+				TuringReqId:    "123",
+				EventTimestamp: timestamppb.New(testTime),
+				RouterVersion:  appName,
+				Request: &turing.Request{
+					Header: map[string]string{"req": "header"},
+					Body:   protoJSONMarshaller.Format(upiReq),
+				},
+				HedgedRequests: []*turing.HedgedRequest{{RouteId: "route-a", Winner: "hedge"}},
+			},
+		},
+		{
+			name: "timings",
+			args: args{
+				reqHeader: metadata.Pairs("req", "header"),
+				upiReq:    upiReq,
+				routerResp: GrpcRouterResponse{
+					Key: ResultLogKeys.Timing,
+					Timings: &Timings{
+						Records: []*timing.Record{
+							{Component: ResultLogKeys.Enricher, StartTime: testTime, Duration: time.Millisecond},
+						},
+						TrafficRule: "rule-1",
+					},
+				},
+				resultLogger: &UPIResultLogger{
+					turingResultLogger: &ResultLogger{
+						trl:     &mockResultLogger{},
+						appName: appName,
+					},
+				},
+			},
+			want: &turing.TuringResultLogMessage{
+				TuringReqId:    "123",
+				EventTimestamp: timestamppb.New(testTime),
+				RouterVersion:  appName,
+				Request: &turing.Request{
+					Header: map[string]string{"req": "header"},
+					Body:   protoJSONMarshaller.Format(upiReq),
+				},
+				Timings: []*turing.ComponentTiming{
+					{Component: "enricher", StartTime: timestamppb.New(testTime), DurationMs: 1},
+				},
+				TrafficRule: "rule-1",
+			},
+		},
 		{
 			name: "error resp",
 			args: args{
@@ -477,7 +863,6 @@ func TestUPIResultLogger_LogTuringRouterRequestSummary_logTuringResultLog(t *tes
 						trl:     &mockResultLogger{},
 						appName: appName,
 					},
-					loggerType: config.BigqueryLogger,
 				},
 			},
 			want: &turing.TuringResultLogMessage{
@@ -507,3 +892,35 @@ func TestUPIResultLogger_LogTuringRouterRequestSummary_logTuringResultLog(t *tes
 		})
 	}
 }
+
+// Both the RouterLog and the TuringResultLogMessage are logged, if both loggers are set
+func TestUPIResultLogger_LogTuringRouterRequestSummary_logBoth(t *testing.T) {
+	appName := "test-app-1.project"
+	testTime := time.Now()
+	upiReq := &upiv1.PredictValuesRequest{
+		TargetName: "target",
+		Metadata: &upiv1.RequestMetadata{
+			PredictionId:     "123",
+			RequestTimestamp: timestamppb.New(testTime),
+		},
+	}
+	upiResponse := &upiv1.PredictValuesResponse{
+		Metadata: &upiv1.ResponseMetadata{PredictionId: "123"},
+	}
+
+	upiLogger, resultLogger := &mockUPILogger{}, &mockResultLogger{}
+	ul, err := InitUPIResultLogger(appName, upiLogger, InitTuringResultLogger(appName, resultLogger, nil), nil)
+	require.NoError(t, err)
+
+	respCh := make(chan GrpcRouterResponse, 1)
+	respCh <- GrpcRouterResponse{Key: ResultLogKeys.Router, Body: upiResponse}
+	close(respCh)
+	ul.LogTuringRouterRequestSummary(metadata.Pairs("req", "header"), upiReq, respCh)
+
+	require.NotNil(t, upiLogger.routerLog)
+	assert.Equal(t, "123", upiLogger.routerLog.PredictionId)
+	assert.Equal(t, "target", upiLogger.routerLog.TargetName)
+	require.NotNil(t, resultLogger.result)
+	assert.Equal(t, "123", resultLogger.result.TuringReqId)
+	assert.Equal(t, protoJSONMarshaller.Format(upiResponse), resultLogger.result.Router.Response)
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gojek/fiber"
@@ -18,11 +19,17 @@ import (
 
 	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
 
+	"github.com/caraml-dev/turing/engines/router"
+	"github.com/caraml-dev/turing/engines/router/missionctl/cache"
 	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/debug"
 	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
 	"github.com/caraml-dev/turing/engines/router/missionctl/experiment"
+	"github.com/caraml-dev/turing/engines/router/missionctl/fallback"
 	"github.com/caraml-dev/turing/engines/router/missionctl/fiberapi"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog"
 	mchttp "github.com/caraml-dev/turing/engines/router/missionctl/server/http"
+	"github.com/caraml-dev/turing/engines/router/missionctl/timing"
 
 	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
 )
@@ -46,8 +53,30 @@ type MissionControl interface {
 		routerResponse []byte,
 		enricherResponse []byte,
 	) (mchttp.Response, *errors.TuringError)
+	// Process calls the endpoint of the given pre / post-processing stage with the payload
+	Process(
+		ctx context.Context,
+		stage *router.ProcessingStage,
+		header http.Header,
+		body []byte,
+	) (mchttp.Response, *errors.TuringError)
 	IsEnricherEnabled() bool
 	IsEnsemblerEnabled() bool
+	// GetProcessingStages returns the processing stages of the given phase, in the order in which they run
+	GetProcessingStages(phase router.StagePhase) router.ProcessingStages
+	// GetCachedResponse returns the response cache key of the request and the response cached
+	// for it, if any. The key is empty, if the response cache is not enabled or the request
+	// doesn't have all the key fields.
+	GetCachedResponse(header http.Header, body []byte) (string, mchttp.Response)
+	// CacheResponse caches the final response of the router for the given response cache key
+	CacheResponse(key string, resp mchttp.Response)
+	// GetFallbackResponse returns the fallback response of the given traffic rule, or the router's default
+	// fallback response, that is served in place of the failure of the given component. It returns nil,
+	// if there is no fallback response to serve.
+	GetFallbackResponse(trafficRule string, reason fallback.Reason) *fallback.Response
+	// SetFiberRouter replaces the Fiber router that the requests are dispatched to. The requests in flight
+	// complete on the previous router.
+	SetFiberRouter(router fiber.Component)
 }
 
 // NewMissionControl creates new instance of the MissingControl,
@@ -58,31 +87,40 @@ func NewMissionControl(
 	routerCfg *config.RouterConfig,
 	ensemblerCfg *config.EnsemblerConfig,
 	appCfg *config.AppConfig,
+	stages config.ProcessingStages,
 ) (MissionControl, error) {
 	fiberRouter, err := fiberapi.CreateFiberRouterFromConfig(routerCfg.ConfigFile, appCfg.FiberDebugLog)
 	if err != nil {
 		return nil, err
 	}
+	fallbackResponses, err := fallback.NewResponses(routerCfg.Fallback, config.HTTP)
+	if err != nil {
+		return nil, err
+	}
 
 	if client == nil {
 		client = http.DefaultClient
 	}
-	fiberHandler := fiberHttp.NewHandler(fiberRouter, fiberHttp.Options{Timeout: routerCfg.Timeout})
 
-	return &missionControl{
+	mc := &missionControl{
 		httpClient:        client,
-		fiberHandler:      fiberHandler,
 		enricherEndpoint:  enrichmentCfg.Endpoint,
 		enricherTimeout:   enrichmentCfg.Timeout,
 		routerTimeout:     routerCfg.Timeout,
 		ensemblerEndpoint: ensemblerCfg.Endpoint,
 		ensemblerTimeout:  ensemblerCfg.Timeout,
-	}, nil
+		stages:            router.ProcessingStages(stages),
+		responseCache:     newResponseCache[mchttp.Response](routerCfg.Cache),
+		fallbackResponses: fallbackResponses,
+	}
+	mc.SetFiberRouter(fiberRouter)
+	return mc, nil
 }
 
 type missionControl struct {
-	httpClient   *http.Client
-	fiberHandler *fiberHttp.Handler
+	httpClient *http.Client
+	// fiberHandler is swapped atomically when the router's config is reloaded
+	fiberHandler atomic.Pointer[fiberHttp.Handler]
 
 	enricherEndpoint string
 	enricherTimeout  time.Duration
@@ -91,6 +129,12 @@ type missionControl struct {
 
 	ensemblerEndpoint string
 	ensemblerTimeout  time.Duration
+
+	stages router.ProcessingStages
+
+	responseCache *responseCache[mchttp.Response]
+
+	fallbackResponses *fallback.Responses
 }
 
 func createNewHTTPRequest(
@@ -122,6 +166,7 @@ func (mc *missionControl) doPost(
 	body []byte,
 	timeout time.Duration,
 	componentLabel string,
+	logKey string,
 ) (mchttp.Response, *errors.TuringError) {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
@@ -144,12 +189,16 @@ func (mc *missionControl) doPost(
 			"traffic_rule": func() string { return "" },
 		},
 	)
+	startTime := time.Now()
 	resp, err := mc.httpClient.Do(req)
 	stopTimer()
 
 	if err != nil {
-		return nil, errors.NewTuringError(err, fiberProtocol.HTTP)
+		turingErr := errors.NewTuringError(err, fiberProtocol.HTTP)
+		recordTiming(ctx, logKey, startTime, turingErr.Code)
+		return nil, turingErr
 	}
+	recordTiming(ctx, logKey, startTime, resp.StatusCode)
 
 	// Defer close non-nil response body
 	if resp.Body != nil {
@@ -192,7 +241,7 @@ func (mc *missionControl) Enrich(
 	)()
 	// Make HTTP request
 	resp, httpErr := mc.doPost(ctx, mc.enricherEndpoint,
-		header, body, mc.enricherTimeout, "enrich")
+		header, body, mc.enricherTimeout, "enrich", resultlog.ResultLogKeys.Enricher)
 	return resp, httpErr
 }
 
@@ -222,6 +271,17 @@ func (mc *missionControl) Route(
 			},
 		},
 	)()
+	// Record the time taken and the status code of the router, once the response is received
+	startTime := time.Now()
+	defer func() {
+		statusCode := http.StatusOK
+		if fiberResponse != nil {
+			statusCode = fiberResponse.StatusCode()
+		} else if routerErr != nil {
+			statusCode = routerErr.Code
+		}
+		recordTiming(ctx, resultlog.ResultLogKeys.Router, startTime, statusCode)
+	}()
 
 	// Create a channel for experiment treatment response and add to context
 	ch := make(chan *experiment.Response, 1)
@@ -237,7 +297,7 @@ func (mc *missionControl) Route(
 	// Pass the request to the Fiber Handler and process the response
 	var routerResp mchttp.Response
 	var fiberError *fiberErrors.FiberError
-	fiberResponse, fiberError = mc.fiberHandler.DoRequest(httpReq)
+	fiberResponse, fiberError = mc.fiberHandler.Load().DoRequest(httpReq)
 	if fiberError != nil {
 		routerResp, routerErr = nil, errors.NewTuringError(fiberError, fiberProtocol.HTTP, fiberError.Code)
 	} else if fiberResponse == nil {
@@ -250,6 +310,13 @@ func (mc *missionControl) Route(
 		httpResp := fiberResponse.(*fiberHttp.Response)
 		routerResp, routerErr = mchttp.NewCachedResponse(httpResp.Payload(), httpResp.Header()), nil
 	}
+	if fiberResponse != nil {
+		trace, _ := debug.GetTrace(ctx)
+		trace.SetTrafficRule(strings.Join(fiberResponse.Label(fiberapi.TrafficRuleLabel), ","))
+		if collector, err := timing.GetCollector(ctx); err == nil {
+			collector.SetResponse(fiberResponse)
+		}
+	}
 
 	// Get the experiment treatment channel from the request context, read result
 	var experimentResponse *experiment.Response
@@ -309,10 +376,40 @@ func (mc *missionControl) Ensemble(
 		httpErr = errors.NewTuringError(err, fiberProtocol.HTTP)
 		return nil, httpErr
 	}
+	trace, _ := debug.GetTrace(ctx)
+	trace.SetEnsemblerInput(payload)
 
 	// Make HTTP request
 	resp, httpErr := mc.doPost(ctx, mc.ensemblerEndpoint,
-		header, payload, mc.ensemblerTimeout, "ensemble")
+		header, payload, mc.ensemblerTimeout, "ensemble", resultlog.ResultLogKeys.Ensembler)
+	return resp, httpErr
+}
+
+// Process calls the endpoint of the given processing stage with the payload
+// and returns the received response
+func (mc *missionControl) Process(
+	ctx context.Context,
+	stage *router.ProcessingStage,
+	header http.Header,
+	body []byte,
+) (mchttp.Response, *errors.TuringError) {
+	var httpErr *errors.TuringError
+	// Measure execution time
+	defer metrics.Glob().MeasureDurationMs(
+		instrumentation.TuringComponentRequestDurationMs,
+		map[string]func() string{
+			"status": func() string {
+				return metrics.GetStatusString(httpErr == nil)
+			},
+			"component": func() string {
+				return stageComponentLabel(stage)
+			},
+			"traffic_rule": func() string { return "" },
+		},
+	)()
+	// Make HTTP request
+	resp, httpErr := mc.doPost(ctx, stage.Endpoint,
+		header, body, time.Duration(stage.Timeout), stageComponentLabel(stage), stage.LogKey())
 	return resp, httpErr
 }
 
@@ -324,6 +421,37 @@ func (mc *missionControl) IsEnsemblerEnabled() bool {
 	return mc.ensemblerEndpoint != ""
 }
 
+func (mc *missionControl) GetProcessingStages(phase router.StagePhase) router.ProcessingStages {
+	return mc.stages.Phase(phase)
+}
+
+func (mc *missionControl) GetCachedResponse(header http.Header, body []byte) (string, mchttp.Response) {
+	if mc.responseCache == nil {
+		return "", nil
+	}
+	key, ok := cache.HTTPRequestKey(mc.responseCache.keyFields, header, body)
+	if !ok {
+		return "", nil
+	}
+	resp, _ := mc.responseCache.lookup(key)
+	return key, resp
+}
+
+func (mc *missionControl) CacheResponse(key string, resp mchttp.Response) {
+	if mc.responseCache == nil || key == "" {
+		return
+	}
+	mc.responseCache.Set(key, resp)
+}
+
+func (mc *missionControl) GetFallbackResponse(trafficRule string, reason fallback.Reason) *fallback.Response {
+	return mc.fallbackResponses.Get(trafficRule, reason)
+}
+
+func (mc *missionControl) SetFiberRouter(router fiber.Component) {
+	mc.fiberHandler.Store(fiberHttp.NewHandler(router, fiberHttp.Options{Timeout: mc.routerTimeout}))
+}
+
 // the makeEnsemblerPayload appends the enricher response to the combined turing router response
 // The turing router resp (routerResp) holds treatment and experiment responses.
 // The routerResp is unmarshalled and enricher response is appended here
@@ -346,6 +474,24 @@ func makeEnsemblerPayload(reqBody []byte, routerResp []byte, enricherResponse []
 	return jsoniter.Marshal(payload)
 }
 
+// recordTiming saves the time taken and the status code of the call to the given component, that started
+// at the given time, in the timing collector of the request, if any
+func recordTiming(ctx context.Context, component string, startTime time.Time, statusCode int) {
+	if collector, err := timing.GetCollector(ctx); err == nil {
+		collector.Record(&timing.Record{
+			Component:  component,
+			StartTime:  startTime,
+			Duration:   time.Since(startTime),
+			StatusCode: statusCode,
+		})
+	}
+}
+
+// stageComponentLabel returns the component label of the metrics of the given processing stage
+func stageComponentLabel(stage *router.ProcessingStage) string {
+	return fmt.Sprintf("stage_%s", stage.Name)
+}
+
 type ensemblerPayload struct {
 	// Original request payload
 	Request json.RawMessage `json:"request"`
@@ -17,14 +17,17 @@ import (
 	"testing"
 	"time"
 
+	fiberConfig "github.com/gojek/fiber/config"
 	"github.com/stretchr/testify/assert"
 
 	_ "github.com/caraml-dev/turing/engines/experiment/plugin/inproc/runner/nop"
+	"github.com/caraml-dev/turing/engines/router"
 	"github.com/caraml-dev/turing/engines/router/missionctl/config"
 	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
 	"github.com/caraml-dev/turing/engines/router/missionctl/fiberapi"
 	tu "github.com/caraml-dev/turing/engines/router/missionctl/internal/testutils"
 	mchttp "github.com/caraml-dev/turing/engines/router/missionctl/server/http"
+	"github.com/caraml-dev/turing/engines/router/missionctl/timing"
 )
 
 // testHTTPServerAddr is the address of the test HTTP server assumed to be running and
@@ -58,6 +61,7 @@ type testSuiteMissionCtl struct {
 	expCfgResultFilePath string
 	compareResultFunc    func(*testing.T, []byte, string)
 	compareExpRespFunc   func(*testing.T, []byte, string)
+	expectedRouteID      string
 }
 
 ////////////////////////////////// Tests //////////////////////////////////////
@@ -69,12 +73,52 @@ func TestNewMissionControl(t *testing.T) {
 		testCfg.RouterConfig,
 		testCfg.EnsemblerConfig,
 		testCfg.AppConfig,
+		nil,
 	)
 	assert.NoError(t, err)
 	assert.Equal(t, true, missionCtl.IsEnricherEnabled())
 	assert.Equal(t, true, missionCtl.IsEnsemblerEnabled())
 }
 
+func TestMissionControlResponseCache(t *testing.T) {
+	routerCfg := *testCfg.RouterConfig
+	routerCfg.Cache = &config.CacheConfig{
+		Enabled:    true,
+		MaxEntries: 10,
+		TTL:        time.Minute,
+		KeyFields: config.CacheKeyFields{
+			{FieldSource: "header", Field: "X-Customer-ID"},
+		},
+	}
+	missionCtl, err := NewMissionControl(
+		nil,
+		testCfg.EnrichmentConfig,
+		&routerCfg,
+		testCfg.EnsemblerConfig,
+		testCfg.AppConfig,
+		nil,
+	)
+	tu.FailOnError(t, err)
+
+	header := http.Header{"X-Customer-Id": []string{"1234"}}
+	resp := mchttp.NewCachedResponse([]byte(`{"value": 1}`), http.Header{})
+
+	// Not cached yet
+	key, cachedResp := missionCtl.GetCachedResponse(header, []byte(`{}`))
+	assert.NotEmpty(t, key)
+	assert.Nil(t, cachedResp)
+
+	missionCtl.CacheResponse(key, resp)
+	key, cachedResp = missionCtl.GetCachedResponse(header, []byte(`{}`))
+	assert.NotEmpty(t, key)
+	assert.Equal(t, resp, cachedResp)
+
+	// Requests without the key fields are never cached
+	key, cachedResp = missionCtl.GetCachedResponse(http.Header{}, []byte(`{}`))
+	assert.Empty(t, key)
+	assert.Nil(t, cachedResp)
+}
+
 func TestMissionControlEnrich(t *testing.T) {
 	missionCtl, err := NewMissionControl(
 		nil,
@@ -82,6 +126,7 @@ func TestMissionControlEnrich(t *testing.T) {
 		testCfg.RouterConfig,
 		testCfg.EnsemblerConfig,
 		testCfg.AppConfig,
+		nil,
 	)
 	tu.FailOnError(t, err)
 
@@ -103,6 +148,55 @@ func TestMissionControlEnrich(t *testing.T) {
 	assert.JSONEq(t, string(data), string(resp.Body()))
 }
 
+func TestMissionControlProcess(t *testing.T) {
+	stages := config.ProcessingStages{
+		{
+			Name:     "calibration",
+			Phase:    router.PostprocessingPhase,
+			Endpoint: fmt.Sprintf("http://%s/enrich/", testHTTPServerAddr),
+			Timeout:  fiberConfig.Duration(time.Second),
+		},
+		{
+			Name:     "fraud-check",
+			Phase:    router.PreprocessingPhase,
+			Endpoint: fmt.Sprintf("http://%s/unknown/", testHTTPServerAddr),
+			Timeout:  fiberConfig.Duration(time.Second),
+		},
+	}
+	missionCtl, err := NewMissionControl(
+		nil,
+		testCfg.EnrichmentConfig,
+		testCfg.RouterConfig,
+		testCfg.EnsemblerConfig,
+		testCfg.AppConfig,
+		stages,
+	)
+	tu.FailOnError(t, err)
+	assert.Equal(t, router.ProcessingStages{stages[1]}, missionCtl.GetProcessingStages(router.PreprocessingPhase))
+	assert.Equal(t, router.ProcessingStages{stages[0]}, missionCtl.GetProcessingStages(router.PostprocessingPhase))
+
+	// Set up Test HTTP Server
+	stopServer := startTestHTTPServer(t, testHTTPServerAddr)
+	defer stopServer()
+
+	collector := timing.NewCollector()
+	ctx := timing.WithCollector(context.Background(), collector)
+	resp, httpErr := missionCtl.Process(ctx, stages[0], http.Header{}, []byte(`{}`))
+	assert.Nil(t, httpErr)
+	assert.JSONEq(t, `{"customer_id": "1230"}`, string(resp.Body()))
+
+	_, httpErr = missionCtl.Process(ctx, stages[1], http.Header{}, []byte(`{}`))
+	assert.EqualError(t, httpErr, "Error response received: status – [404]")
+
+	// The calls to the stages are timed, with the status code of their responses
+	records := collector.Records()
+	assert.Len(t, records, 2)
+	assert.Equal(t, "calibration", records[0].Component)
+	assert.Equal(t, http.StatusOK, records[0].StatusCode)
+	assert.Equal(t, "fraud-check", records[1].Component)
+	assert.Equal(t, http.StatusNotFound, records[1].StatusCode)
+}
+
 func TestMakeEnsemblerPayload(t *testing.T) {
 	payload1 := []byte(`{"key1": "data1"}`)
 	payload2 := []byte(`{"experiment": {}}`)
@@ -135,6 +229,7 @@ func TestMissionControlEnsemble(t *testing.T) {
 		testCfg.RouterConfig,
 		testCfg.EnsemblerConfig,
 		testCfg.AppConfig,
+		nil,
 	)
 	tu.FailOnError(t, err)
 
@@ -174,6 +269,7 @@ func TestMissionControlRoute(t *testing.T) {
 			resultFilePath:     filepath.Join("testdata", "route_response_route_id_1.json"),
 			compareResultFunc:  compareResponse,
 			compareExpRespFunc: assertNil,
+			expectedRouteID:    "route_id_1",
 		},
 	}
 
@@ -207,11 +303,13 @@ func TestMissionControlRoute(t *testing.T) {
 				testCfgLocal.RouterConfig,
 				testCfgLocal.EnsemblerConfig,
 				testCfg.AppConfig,
+				nil,
 			)
 			tu.FailOnError(t, err)
 
 			// Route
-			expResp, resp, httpErr := missionCtl.Route(context.Background(),
+			collector := timing.NewCollector()
+			expResp, resp, httpErr := missionCtl.Route(timing.WithCollector(context.Background(), collector),
 				http.Header{}, []byte(`{"customer_id": "2", "country_id": "TH"}`))
 
 			// Check that the error is nil
@@ -227,6 +325,16 @@ func TestMissionControlRoute(t *testing.T) {
 			// Compare response body and experiment response with expected
 			data.compareResultFunc(t, resp.Body(), data.resultFilePath)
 			data.compareExpRespFunc(t, expResp.Configuration, data.expCfgResultFilePath)
+
+			// Check that the router is timed, followed by the calls to the routes, and that the route
+			// whose response was returned is identified, unless the responses of the routes were combined
+			records := collector.Records()
+			assert.Equal(t, "router", records[0].Component)
+			assert.Equal(t, http.StatusOK, records[0].StatusCode)
+			for _, record := range records[1:] {
+				assert.Equal(t, timing.RouteComponent, record.Component)
+			}
+			assert.Equal(t, data.expectedRouteID, collector.RouteID())
 		})
 	}
 }
@@ -277,6 +385,7 @@ func benchmarkEnrich(payloadFileName string, b *testing.B) {
 		testCfg.RouterConfig,
 		testCfg.EnsemblerConfig,
 		testCfg.AppConfig,
+		nil,
 	)
 
 	// Set up Test HTTP Server
@@ -332,6 +441,7 @@ func benchmarkRoute(cfgFileName string, payloadFileName string, b *testing.B) {
 		testCfgLocal.RouterConfig,
 		testCfgLocal.EnsemblerConfig,
 		testCfg.AppConfig,
+		nil,
 	)
 
 	// Set up Test HTTP Server
@@ -366,16 +476,19 @@ func BenchmarkMissionControlEnsemblingRouteLargePayload(b *testing.B) {
 //
 // The server handles requests with the following paths:
 // - /enrich/
-// - /ensemble/
+// - /ensemble
 // - /route1/
 // - /control/
 //
+// The ensembler path has no trailing slash, as the POST requests, that the ServeMux
+// redirects from /ensemble to /ensemble/, are not followed.
+//
 // This test server can be used to test sending requests to various endpoints configured
 // in the router.
 func startTestHTTPServer(t testing.TB, addr string) (stopServer func()) {
 	handler := http.NewServeMux()
 	handler.HandleFunc("/enrich/", enricherHandler)
-	handler.HandleFunc("/ensemble/", ensemblerHandler)
+	handler.HandleFunc("/ensemble", ensemblerHandler)
 	handler.HandleFunc("/route1/", route1Handler)
 	handler.HandleFunc("/control/", controlHandler)
 	server := httptest.NewUnstartedServer(handler)
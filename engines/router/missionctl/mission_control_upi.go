@@ -2,43 +2,232 @@ package missionctl
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
 	"github.com/gojek/fiber"
 	fiberGrpc "github.com/gojek/fiber/grpc"
 	fiberProtocol "github.com/gojek/fiber/protocol"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
 
+	"github.com/caraml-dev/turing/engines/router"
+	"github.com/caraml-dev/turing/engines/router/missionctl/cache"
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/debug"
 	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/fallback"
 	"github.com/caraml-dev/turing/engines/router/missionctl/fiberapi"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog"
+	"github.com/caraml-dev/turing/engines/router/missionctl/timing"
 
 	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
 )
 
 type MissionControlUPI interface {
+	Enrich(
+		ctx context.Context,
+		req *upiv1.PredictValuesRequest,
+		md metadata.MD,
+	) (*upiv1.PredictValuesResponse, metadata.MD, *errors.TuringError)
 	Route(context.Context, fiber.Request) (fiber.Response, *errors.TuringError)
+	Ensemble(
+		ctx context.Context,
+		req *upiv1.PredictValuesRequest,
+		routerResp *upiv1.PredictValuesResponse,
+		md metadata.MD,
+	) (*upiv1.PredictValuesResponse, metadata.MD, *errors.TuringError)
+	// Process calls the endpoint of the given pre / post-processing stage with the UPI request
+	Process(
+		ctx context.Context,
+		stage *router.ProcessingStage,
+		req *upiv1.PredictValuesRequest,
+		md metadata.MD,
+	) (*upiv1.PredictValuesResponse, metadata.MD, *errors.TuringError)
+	IsEnricherEnabled() bool
+	IsEnsemblerEnabled() bool
+	// GetProcessingStages returns the processing stages of the given phase, in the order in which they run
+	GetProcessingStages(phase router.StagePhase) router.ProcessingStages
+	// GetCachedResponse returns the response cache key of the request and a copy of the response
+	// cached for it, if any. The key is empty, if the response cache is not enabled or the request
+	// doesn't have all the key fields.
+	GetCachedResponse(req *upiv1.PredictValuesRequest, md metadata.MD) (string, *upiv1.PredictValuesResponse)
+	// CacheResponse caches a copy of the final response of the router for the given response cache key
+	CacheResponse(key string, resp *upiv1.PredictValuesResponse)
+	// GetFallbackResponse returns a copy of the fallback response of the given traffic rule, or of the router's
+	// default fallback response, that is served in place of the failure of the given component. It returns
+	// nil, if there is no fallback response to serve.
+	GetFallbackResponse(trafficRule string, reason fallback.Reason) *upiv1.PredictValuesResponse
+	// SetFiberRouter replaces the Fiber router that the requests are dispatched to. The requests in flight
+	// complete on the previous router.
+	SetFiberRouter(router fiber.Component)
 }
 
 type missionControlUpi struct {
-	fiberRouter fiber.Component
+	// fiberRouter is swapped atomically when the router's config is reloaded
+	fiberRouter atomic.Pointer[fiber.Component]
+
+	enricherClient  upiv1.UniversalPredictionServiceClient
+	enricherTimeout time.Duration
+
+	ensemblerClient  upiv1.UniversalPredictionServiceClient
+	ensemblerTimeout time.Duration
+
+	stages router.ProcessingStages
+	// stageClients holds the UPI client of each processing stage, by the name of the stage
+	stageClients map[string]upiv1.UniversalPredictionServiceClient
+
+	responseCache *responseCache[*upiv1.PredictValuesResponse]
+
+	fallbackResponses *fallback.Responses
 }
 
 // NewMissionControlUPI creates new instance of the MissingControl,
-// based on the grpc configuration of fiber.yaml
+// based on the grpc configuration of fiber.yaml and the (optional)
+// enricher, ensembler, response cache, fallback responses and processing stages configuration
 func NewMissionControlUPI(
 	cfgFilePath string,
 	fiberDebugLog bool,
+	enrichmentCfg *config.EnrichmentConfig,
+	ensemblerCfg *config.EnsemblerConfig,
+	cacheCfg *config.CacheConfig,
+	fallbackCfg *config.FallbackConfig,
+	stages config.ProcessingStages,
 ) (MissionControlUPI, error) {
 	fiberRouter, err := fiberapi.CreateFiberRouterFromConfig(cfgFilePath, fiberDebugLog)
 	if err != nil {
 		return nil, err
 	}
+	fallbackResponses, err := fallback.NewResponses(fallbackCfg, config.UPI)
+	if err != nil {
+		return nil, err
+	}
+
+	mc := &missionControlUpi{
+		responseCache:     newResponseCache[*upiv1.PredictValuesResponse](cacheCfg),
+		fallbackResponses: fallbackResponses,
+	}
+	mc.SetFiberRouter(fiberRouter)
+
+	if enrichmentCfg != nil && enrichmentCfg.Endpoint != "" {
+		mc.enricherClient, err = newUPIClient(enrichmentCfg.Endpoint)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to create enricher client")
+		}
+		mc.enricherTimeout = enrichmentCfg.Timeout
+	}
+
+	if ensemblerCfg != nil && ensemblerCfg.Endpoint != "" {
+		mc.ensemblerClient, err = newUPIClient(ensemblerCfg.Endpoint)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to create ensembler client")
+		}
+		mc.ensemblerTimeout = ensemblerCfg.Timeout
+	}
+
+	mc.stages = router.ProcessingStages(stages)
+	mc.stageClients = make(map[string]upiv1.UniversalPredictionServiceClient, len(stages))
+	for _, stage := range stages {
+		mc.stageClients[stage.Name], err = newUPIClient(stage.Endpoint)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to create client of processing stage %s", stage.Name)
+		}
+	}
+
+	return mc, nil
+}
+
+// newUPIClient creates a UPI client that will connect to the given endpoint,
+// in the format host:port
+func newUPIClient(endpoint string) (upiv1.UniversalPredictionServiceClient, error) {
+	conn, err := grpc.DialContext(
+		context.Background(),
+		endpoint,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return upiv1.NewUniversalPredictionServiceClient(conn), nil
+}
+
+func (us *missionControlUpi) predictValues(
+	ctx context.Context,
+	client upiv1.UniversalPredictionServiceClient,
+	req *upiv1.PredictValuesRequest,
+	md metadata.MD,
+	timeout time.Duration,
+	componentLabel string,
+	logKey string,
+) (*upiv1.PredictValuesResponse, metadata.MD, *errors.TuringError) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// Make UPI request and measure duration
+	var err error
+	var respHeader metadata.MD
+	stopTimer := metrics.Glob().MeasureDurationMs(
+		instrumentation.TuringComponentRequestDurationMs,
+		map[string]func() string{
+			"status": func() string {
+				return metrics.GetStatusString(err == nil)
+			},
+			"component": func() string {
+				return fmt.Sprint(componentLabel, "_makeRequest")
+			},
+			"traffic_rule": func() string { return "" },
+		},
+	)
+	startTime := time.Now()
+	resp, err := client.PredictValues(ctx, req, grpc.Header(&respHeader))
+	stopTimer()
+
+	respStatus, _ := status.FromError(err)
+	recordTiming(ctx, logKey, startTime, int(respStatus.Code()))
+	if err != nil {
+		return nil, nil, &errors.TuringError{
+			Code:    int(respStatus.Code()),
+			Message: respStatus.Message(),
+		}
+	}
+	return resp, respHeader, nil
+}
 
-	return &missionControlUpi{
-		fiberRouter: fiberRouter,
-	}, nil
+// Enrich calls the configured enricher endpoint with the UPI request
+// and returns the received response
+func (us *missionControlUpi) Enrich(
+	ctx context.Context,
+	req *upiv1.PredictValuesRequest,
+	md metadata.MD,
+) (*upiv1.PredictValuesResponse, metadata.MD, *errors.TuringError) {
+	var turingError *errors.TuringError
+	// Measure execution time
+	defer metrics.Glob().MeasureDurationMs(
+		instrumentation.TuringComponentRequestDurationMs,
+		map[string]func() string{
+			"status": func() string {
+				return metrics.GetStatusString(turingError == nil)
+			},
+			"component": func() string {
+				return "enrich"
+			},
+			"traffic_rule": func() string { return "" },
+		},
+	)()
+
+	resp, respHeader, turingError := us.predictValues(ctx, us.enricherClient,
+		req, md, us.enricherTimeout, "enrich", resultlog.ResultLogKeys.Enricher)
+	return resp, respHeader, turingError
 }
 
 func (us *missionControlUpi) Route(
@@ -66,13 +255,21 @@ func (us *missionControlUpi) Route(
 		},
 	)()
 
-	resp, ok := <-us.fiberRouter.Dispatch(ctx, fiberRequest).Iter()
+	startTime := time.Now()
+	resp, ok := <-(*us.fiberRouter.Load()).Dispatch(ctx, fiberRequest).Iter()
 	if !ok {
 		turingError = errors.NewTuringError(
 			errors.Newf(errors.BadResponse, "did not get back a valid response from the fiberHandler"), fiberProtocol.GRPC,
 		)
+		recordTiming(ctx, resultlog.ResultLogKeys.Router, startTime, turingError.Code)
 		return nil, turingError
 	}
+	recordTiming(ctx, resultlog.ResultLogKeys.Router, startTime, resp.StatusCode())
+	if collector, err := timing.GetCollector(ctx); err == nil {
+		collector.SetResponse(resp)
+	}
+	trace, _ := debug.GetTrace(ctx)
+	trace.SetTrafficRule(strings.Join(resp.Label(fiberapi.TrafficRuleLabel), ","))
 	if !resp.IsSuccess() {
 		return nil, &errors.TuringError{
 			Code:    resp.StatusCode(),
@@ -101,3 +298,149 @@ func (us *missionControlUpi) Route(
 
 	return grpcResponse, nil
 }
+
+// Ensemble combines the UPI request with the router response and dispatches it
+// to the configured ensembler endpoint
+func (us *missionControlUpi) Ensemble(
+	ctx context.Context,
+	req *upiv1.PredictValuesRequest,
+	routerResp *upiv1.PredictValuesResponse,
+	md metadata.MD,
+) (*upiv1.PredictValuesResponse, metadata.MD, *errors.TuringError) {
+	var turingError *errors.TuringError
+	// Measure execution time
+	defer metrics.Glob().MeasureDurationMs(
+		instrumentation.TuringComponentRequestDurationMs,
+		map[string]func() string{
+			"status": func() string {
+				return metrics.GetStatusString(turingError == nil)
+			},
+			"component": func() string {
+				return "ensemble"
+			},
+			"traffic_rule": func() string { return "" },
+		},
+	)()
+
+	ensemblerReq := makeUPIEnsemblerRequest(req, routerResp)
+	if trace, _ := debug.GetTrace(ctx); trace != nil {
+		input, _ := protojson.Marshal(ensemblerReq)
+		trace.SetEnsemblerInput(input)
+	}
+	resp, respHeader, turingError := us.predictValues(ctx, us.ensemblerClient,
+		ensemblerReq, md, us.ensemblerTimeout, "ensemble", resultlog.ResultLogKeys.Ensembler)
+	return resp, respHeader, turingError
+}
+
+// Process calls the endpoint of the given processing stage with the UPI request
+// and returns the received response
+func (us *missionControlUpi) Process(
+	ctx context.Context,
+	stage *router.ProcessingStage,
+	req *upiv1.PredictValuesRequest,
+	md metadata.MD,
+) (*upiv1.PredictValuesResponse, metadata.MD, *errors.TuringError) {
+	var turingError *errors.TuringError
+	// Measure execution time
+	defer metrics.Glob().MeasureDurationMs(
+		instrumentation.TuringComponentRequestDurationMs,
+		map[string]func() string{
+			"status": func() string {
+				return metrics.GetStatusString(turingError == nil)
+			},
+			"component": func() string {
+				return stageComponentLabel(stage)
+			},
+			"traffic_rule": func() string { return "" },
+		},
+	)()
+
+	client, ok := us.stageClients[stage.Name]
+	if !ok {
+		turingError = errors.NewTuringError(
+			errors.Newf(errors.BadConfig, "processing stage %s is not configured", stage.Name), fiberProtocol.GRPC,
+		)
+		return nil, nil, turingError
+	}
+	resp, respHeader, turingError := us.predictValues(ctx, client,
+		req, md, time.Duration(stage.Timeout), stageComponentLabel(stage), stage.LogKey())
+	return resp, respHeader, turingError
+}
+
+func (us *missionControlUpi) IsEnricherEnabled() bool {
+	return us.enricherClient != nil
+}
+
+func (us *missionControlUpi) IsEnsemblerEnabled() bool {
+	return us.ensemblerClient != nil
+}
+
+func (us *missionControlUpi) GetProcessingStages(phase router.StagePhase) router.ProcessingStages {
+	return us.stages.Phase(phase)
+}
+
+func (us *missionControlUpi) GetCachedResponse(
+	req *upiv1.PredictValuesRequest,
+	md metadata.MD,
+) (string, *upiv1.PredictValuesResponse) {
+	if us.responseCache == nil {
+		return "", nil
+	}
+	key, ok := cache.UPIRequestKey(us.responseCache.keyFields, md, req)
+	if !ok {
+		return "", nil
+	}
+	resp, ok := us.responseCache.lookup(key)
+	if !ok {
+		return key, nil
+	}
+	// The response metadata is populated for each request, so a copy is returned
+	return key, proto.Clone(resp).(*upiv1.PredictValuesResponse)
+}
+
+func (us *missionControlUpi) CacheResponse(key string, resp *upiv1.PredictValuesResponse) {
+	if us.responseCache == nil || key == "" {
+		return
+	}
+	us.responseCache.Set(key, proto.Clone(resp).(*upiv1.PredictValuesResponse))
+}
+
+func (us *missionControlUpi) GetFallbackResponse(
+	trafficRule string,
+	reason fallback.Reason,
+) *upiv1.PredictValuesResponse {
+	resp := us.fallbackResponses.Get(trafficRule, reason)
+	if resp == nil {
+		return nil
+	}
+	return proto.Clone(resp.UPIResponse).(*upiv1.PredictValuesResponse)
+}
+
+func (us *missionControlUpi) SetFiberRouter(router fiber.Component) {
+	us.fiberRouter.Store(&router)
+}
+
+// makeUPIEnsemblerRequest creates the request to the ensembler from the original request and the
+// router response. The prediction result table from the router is appended to the transformer input
+// tables and the prediction context from the router is appended to the request's prediction context.
+func makeUPIEnsemblerRequest(
+	req *upiv1.PredictValuesRequest,
+	routerResp *upiv1.PredictValuesResponse,
+) *upiv1.PredictValuesRequest {
+	ensemblerReq := proto.Clone(req).(*upiv1.PredictValuesRequest)
+	if routerResp == nil {
+		return ensemblerReq
+	}
+
+	if routerResp.GetPredictionResultTable() != nil {
+		if ensemblerReq.TransformerInput == nil {
+			ensemblerReq.TransformerInput = &upiv1.TransformerInput{}
+		}
+		ensemblerReq.TransformerInput.Tables = append(
+			ensemblerReq.TransformerInput.Tables,
+			routerResp.GetPredictionResultTable(),
+		)
+	}
+	ensemblerReq.PredictionContext = append(ensemblerReq.PredictionContext, routerResp.GetPredictionContext()...)
+	return ensemblerReq
+}
@@ -3,10 +3,12 @@ package missionctl
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"testing"
+	"time"
 
 	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
 	"github.com/gojek/fiber"
@@ -19,8 +21,10 @@ import (
 	"go.uber.org/zap/zaptest/observer"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
 	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
 	"github.com/caraml-dev/turing/engines/router/missionctl/internal/mocks"
 	"github.com/caraml-dev/turing/engines/router/missionctl/internal/testutils"
@@ -88,7 +92,7 @@ func TestNewMissionControlUpi(t *testing.T) {
 			logger := zap.New(core)
 			log.SetGlobalLogger(logger.Sugar())
 
-			got, err := NewMissionControlUPI(tt.cfgFilePath, tt.fiberDebugLog)
+			got, err := NewMissionControlUPI(tt.cfgFilePath, tt.fiberDebugLog, nil, nil, nil, nil, nil)
 			if err != nil {
 				require.EqualError(t, err, tt.expectedErr)
 			} else {
@@ -171,7 +175,8 @@ func Test_missionControlUpi_Route(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockFiberRouter := &mocks.Component{}
 			mockFiberRouter.On("Dispatch", mock.Anything, mock.Anything).Return(tt.mockReturn, nil)
-			mc := missionControlUpi{fiberRouter: mockFiberRouter}
+			mc := &missionControlUpi{}
+			mc.SetFiberRouter(mockFiberRouter)
 			ctx := context.Background()
 			ctx = grpc.NewContextWithServerTransportStream(ctx, mockStream)
 
@@ -237,7 +242,7 @@ func Test_missionControlUpi_Route_Integration(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mc, err := NewMissionControlUPI(twoRouteConfig, false)
+			mc, err := NewMissionControlUPI(twoRouteConfig, false, nil, nil, nil, nil, nil)
 			require.NoError(t, err)
 			ctx := context.Background()
 			ctx = grpc.NewContextWithServerTransportStream(ctx, mockStream)
@@ -249,3 +254,80 @@ func Test_missionControlUpi_Route_Integration(t *testing.T) {
 		})
 	}
 }
+
+// Test_missionControlUpi_EnrichEnsemble sends requests to the test server, which will return the prediction table of
+// the request as the prediction result table, to check that the enricher and ensembler are called
+func Test_missionControlUpi_EnrichEnsemble(t *testing.T) {
+	endpoint := fmt.Sprintf("localhost:%d", port)
+	mc, err := NewMissionControlUPI(
+		singleRouteConfig,
+		false,
+		&config.EnrichmentConfig{Endpoint: endpoint, Timeout: 2 * time.Second},
+		&config.EnsemblerConfig{Endpoint: endpoint, Timeout: 2 * time.Second},
+		nil,
+		nil,
+		nil,
+	)
+	require.NoError(t, err)
+	require.True(t, mc.IsEnricherEnabled())
+	require.True(t, mc.IsEnsemblerEnabled())
+
+	req := testutils.GenerateUPIRequest(5, 5)
+	enricherResp, _, turingErr := mc.Enrich(context.Background(), req, metadata.MD{})
+	require.Nil(t, turingErr)
+	require.True(t, proto.Equal(req.PredictionTable, enricherResp.PredictionResultTable))
+
+	ensemblerResp, _, turingErr := mc.Ensemble(context.Background(), req, mockResponse, metadata.MD{})
+	require.Nil(t, turingErr)
+	require.True(t, proto.Equal(req.PredictionTable, ensemblerResp.PredictionResultTable))
+}
+
+func Test_missionControlUpi_EnrichError(t *testing.T) {
+	mc, err := NewMissionControlUPI(
+		singleRouteConfig,
+		false,
+		&config.EnrichmentConfig{Endpoint: "localhost:50599", Timeout: 100 * time.Millisecond},
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+	require.NoError(t, err)
+	require.True(t, mc.IsEnricherEnabled())
+	require.False(t, mc.IsEnsemblerEnabled())
+
+	_, _, turingErr := mc.Enrich(context.Background(), &upiv1.PredictValuesRequest{}, metadata.MD{})
+	require.NotNil(t, turingErr)
+	require.Equal(t, int(codes.Unavailable), turingErr.Code)
+}
+
+func Test_makeUPIEnsemblerRequest(t *testing.T) {
+	req := &upiv1.PredictValuesRequest{
+		PredictionTable: &upiv1.Table{Name: "request_table"},
+		PredictionContext: []*upiv1.Variable{
+			{Name: "country", Type: upiv1.Type_TYPE_STRING, StringValue: "ID"},
+		},
+	}
+	routerResp := &upiv1.PredictValuesResponse{
+		PredictionResultTable: &upiv1.Table{Name: "router_table"},
+		PredictionContext: []*upiv1.Variable{
+			{Name: "model", Type: upiv1.Type_TYPE_STRING, StringValue: "control"},
+		},
+	}
+
+	got := makeUPIEnsemblerRequest(req, routerResp)
+	expected := &upiv1.PredictValuesRequest{
+		PredictionTable: &upiv1.Table{Name: "request_table"},
+		TransformerInput: &upiv1.TransformerInput{
+			Tables: []*upiv1.Table{{Name: "router_table"}},
+		},
+		PredictionContext: []*upiv1.Variable{
+			{Name: "country", Type: upiv1.Type_TYPE_STRING, StringValue: "ID"},
+			{Name: "model", Type: upiv1.Type_TYPE_STRING, StringValue: "control"},
+		},
+	}
+	require.True(t, proto.Equal(expected, got), "ensembler request not equal to expected")
+	// Original request should not be modified
+	require.Nil(t, req.TransformerInput)
+	require.Len(t, req.PredictionContext, 1)
+}
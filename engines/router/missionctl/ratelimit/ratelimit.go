@@ -0,0 +1,188 @@
+// Package ratelimit admits the requests to the router's prediction endpoints, limiting the request rate
+// and the number of in-flight requests of each client, so that one client cannot starve the others.
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/cache"
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+)
+
+const (
+	// clientIdleTTL is the duration after the last request of a client, after which its state is forgotten.
+	// By then, the token bucket of the client has usually been refilled.
+	clientIdleTTL = 10 * time.Minute
+	// concurrencyRetryAfter is the retry hint of the requests, that are rejected for exceeding the maximum
+	// number of in-flight requests, since it is not known when the in-flight requests complete
+	concurrencyRetryAfter = time.Second
+	// OtherMetricKey is the key, by which the rejected requests of the clients, that are not configured
+	// to be counted by their own key, are counted
+	OtherMetricKey = "other"
+)
+
+// Reason is the reason for rejecting a request
+type Reason string
+
+const (
+	// RateReason rejects the request for exceeding the client's request rate
+	RateReason Reason = "rate"
+	// ConcurrencyReason rejects the request for exceeding the client's number of in-flight requests
+	ConcurrencyReason Reason = "concurrency"
+)
+
+// Rejection describes a rejected request, and when the client may retry it
+type Rejection struct {
+	Key        string
+	Reason     Reason
+	RetryAfter time.Duration
+}
+
+// Error returns the message of the rejection, which is returned to the client
+func (r *Rejection) Error() string {
+	if r.Reason == RateReason {
+		return "Request rate limit exceeded"
+	}
+	return "Too many in-flight requests"
+}
+
+// RetryAfterSeconds returns the retry hint in whole seconds, rounded up, as used in the Retry-After header
+func (r *Rejection) RetryAfterSeconds() int {
+	return int(math.Ceil(r.RetryAfter.Seconds()))
+}
+
+// client holds the token bucket and the number of in-flight requests of a client
+type client struct {
+	mu         sync.Mutex
+	tokens     float64
+	refilledAt time.Time
+	inFlight   int
+}
+
+// Limiter admits the requests of each client, as long as they are within the client's limits
+type Limiter struct {
+	cfg   *config.RateLimitConfig
+	burst float64
+	// metricKeys are the keys of the clients, which rejected requests are counted by their own key
+	metricKeys map[string]bool
+
+	mu      sync.Mutex
+	clients *cache.Cache[*client]
+	// now returns the current time, and is only overridden in the tests
+	now func() time.Time
+}
+
+// NewLimiter creates a new Limiter from the given config. It returns nil, if the requests are not limited.
+func NewLimiter(cfg *config.RateLimitConfig) *Limiter {
+	if !cfg.Enabled() {
+		return nil
+	}
+	burst := float64(cfg.Burst)
+	if burst <= 0 {
+		burst = math.Max(1, math.Ceil(cfg.RequestsPerSec))
+	}
+	metricKeys := make(map[string]bool, len(cfg.MetricKeys))
+	for _, key := range cfg.MetricKeys {
+		metricKeys[key] = true
+	}
+	return &Limiter{
+		cfg:        cfg,
+		burst:      burst,
+		metricKeys: metricKeys,
+		clients:    cache.New[*client](cfg.MaxKeys, clientIdleTTL),
+		now:        time.Now,
+	}
+}
+
+// NeedsPayload returns whether the key of the HTTP requests is read from their payload
+func (l *Limiter) NeedsPayload() bool {
+	return l.cfg.FieldSource == request.PayloadFieldSource
+}
+
+// HTTPRequestKey returns the key of the client of the HTTP request, which is empty if the request
+// does not have the key field
+func (l *Limiter) HTTPRequestKey(header http.Header, body []byte) string {
+	key, _ := request.GetValueFromHTTPRequest(header, body, l.cfg.FieldSource, l.cfg.Field)
+	return key
+}
+
+// UPIRequestKey returns the key of the client of the UPI request, which is empty if the request
+// does not have the key field
+func (l *Limiter) UPIRequestKey(md metadata.MD, req *upiv1.PredictValuesRequest) string {
+	key, _ := request.GetValueFromUPIRequest(md, req, l.cfg.FieldSource, l.cfg.Field)
+	return key
+}
+
+// Admit admits the request of the client with the given key, if the client is within its limits, and
+// returns the function that must be called when the request completes. Otherwise, the request is
+// rejected and recorded in the rejected requests metric.
+func (l *Limiter) Admit(key string) (func(), *Rejection) {
+	c := l.getClient(key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The number of in-flight requests is checked first, so that the rejected requests do not use up tokens
+	if l.cfg.MaxInFlight > 0 && c.inFlight >= l.cfg.MaxInFlight {
+		return nil, l.reject(key, ConcurrencyReason, concurrencyRetryAfter)
+	}
+	if rate := l.cfg.RequestsPerSec; rate > 0 {
+		now := l.now()
+		c.tokens = math.Min(l.burst, c.tokens+now.Sub(c.refilledAt).Seconds()*rate)
+		c.refilledAt = now
+		if c.tokens < 1 {
+			return nil, l.reject(key, RateReason, time.Duration((1-c.tokens)/rate*float64(time.Second)))
+		}
+		c.tokens--
+	}
+
+	c.inFlight++
+	return sync.OnceFunc(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.inFlight--
+	}), nil
+}
+
+// getClient returns the state of the client with the given key, creating it with a full token bucket,
+// if the client is new or has been forgotten
+func (l *Limiter) getClient(key string) *client {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	c, ok := l.clients.Get(key)
+	if !ok {
+		c = &client{tokens: l.burst, refilledAt: l.now()}
+	}
+	// The client is set again on every request, to extend its idle TTL
+	l.clients.Set(key, c)
+	return c
+}
+
+func (l *Limiter) reject(key string, reason Reason, retryAfter time.Duration) *Rejection {
+	if err := metrics.Glob().Inc(
+		instrumentation.RateLimitedRequestsTotal,
+		map[string]string{"key": l.metricKey(key), "reason": string(reason)},
+	); err != nil {
+		log.Glob().Errorf("Failed to record the rejected request of client %q: %s", key, err.Error())
+	}
+	return &Rejection{Key: key, Reason: reason, RetryAfter: retryAfter}
+}
+
+// metricKey returns the key, by which the rejected requests of the client are counted. Only the configured
+// keys are counted on their own, since the clients' keys are unbounded.
+func (l *Limiter) metricKey(key string) string {
+	if l.metricKeys[key] {
+		return key
+	}
+	return OtherMetricKey
+}
@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+)
+
+func newTestLimiter(cfg *config.RateLimitConfig, now *time.Time) *Limiter {
+	limiter := NewLimiter(cfg)
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+func TestNewLimiterDisabled(t *testing.T) {
+	assert.Nil(t, NewLimiter(nil))
+	assert.Nil(t, NewLimiter(&config.RateLimitConfig{MaxKeys: 10}))
+}
+
+func TestLimiterRate(t *testing.T) {
+	now := time.Now()
+	limiter := newTestLimiter(&config.RateLimitConfig{RequestsPerSec: 2, Burst: 3, MaxKeys: 10}, &now)
+
+	// The burst is admitted at once
+	for i := 0; i < 3; i++ {
+		release, rejection := limiter.Admit("client-a")
+		require.Nil(t, rejection)
+		release()
+	}
+	_, rejection := limiter.Admit("client-a")
+	assert.Equal(t, &Rejection{Key: "client-a", Reason: RateReason, RetryAfter: 500 * time.Millisecond}, rejection)
+	assert.Equal(t, 1, rejection.RetryAfterSeconds())
+	assert.EqualError(t, rejection, "Request rate limit exceeded")
+
+	// The other clients have their own token buckets
+	_, rejection = limiter.Admit("client-b")
+	assert.Nil(t, rejection)
+
+	// The tokens are refilled at the configured rate
+	now = now.Add(250 * time.Millisecond)
+	_, rejection = limiter.Admit("client-a")
+	assert.Equal(t, &Rejection{Key: "client-a", Reason: RateReason, RetryAfter: 250 * time.Millisecond}, rejection)
+	now = now.Add(250 * time.Millisecond)
+	_, rejection = limiter.Admit("client-a")
+	assert.Nil(t, rejection)
+
+	// The bucket holds at most the burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		_, rejection = limiter.Admit("client-a")
+		require.Nil(t, rejection)
+	}
+	_, rejection = limiter.Admit("client-a")
+	assert.NotNil(t, rejection)
+}
+
+func TestLimiterDefaultBurst(t *testing.T) {
+	now := time.Now()
+	limiter := newTestLimiter(&config.RateLimitConfig{RequestsPerSec: 0.5, MaxKeys: 10}, &now)
+
+	_, rejection := limiter.Admit("client-a")
+	assert.Nil(t, rejection)
+	_, rejection = limiter.Admit("client-a")
+	assert.Equal(t, &Rejection{Key: "client-a", Reason: RateReason, RetryAfter: 2 * time.Second}, rejection)
+}
+
+func TestLimiterMaxInFlight(t *testing.T) {
+	now := time.Now()
+	limiter := newTestLimiter(&config.RateLimitConfig{RequestsPerSec: 1, Burst: 4, MaxInFlight: 2, MaxKeys: 10}, &now)
+
+	releaseA, rejection := limiter.Admit("client-a")
+	require.Nil(t, rejection)
+	_, rejection = limiter.Admit("client-a")
+	require.Nil(t, rejection)
+
+	_, rejection = limiter.Admit("client-a")
+	assert.Equal(t, &Rejection{Key: "client-a", Reason: ConcurrencyReason, RetryAfter: time.Second}, rejection)
+	assert.EqualError(t, rejection, "Too many in-flight requests")
+
+	// Releasing a request more than once has no effect
+	releaseA()
+	releaseA()
+	_, rejection = limiter.Admit("client-a")
+	assert.Nil(t, rejection)
+	_, rejection = limiter.Admit("client-a")
+	assert.Equal(t, ConcurrencyReason, rejection.Reason)
+
+	// The requests rejected for their concurrency do not use up tokens, so the fourth token is still available
+	limiter.cfg.MaxInFlight = 0
+	_, rejection = limiter.Admit("client-a")
+	assert.Nil(t, rejection)
+}
+
+func TestLimiterMaxKeys(t *testing.T) {
+	now := time.Now()
+	limiter := newTestLimiter(&config.RateLimitConfig{RequestsPerSec: 1, MaxKeys: 1}, &now)
+
+	_, rejection := limiter.Admit("client-a")
+	require.Nil(t, rejection)
+	_, rejection = limiter.Admit("client-b")
+	require.Nil(t, rejection)
+
+	// The state of client-a was evicted, so it starts again with a full bucket
+	_, rejection = limiter.Admit("client-a")
+	assert.Nil(t, rejection)
+	assert.Equal(t, 1, limiter.clients.Len())
+}
+
+func TestLimiterMetricKey(t *testing.T) {
+	limiter := NewLimiter(&config.RateLimitConfig{MaxInFlight: 1, MaxKeys: 10, MetricKeys: []string{"client-a"}})
+	assert.Equal(t, "client-a", limiter.metricKey("client-a"))
+	assert.Equal(t, OtherMetricKey, limiter.metricKey("client-b"))
+	assert.Equal(t, OtherMetricKey, limiter.metricKey(""))
+}
+
+func TestLimiterRequestKey(t *testing.T) {
+	limiter := NewLimiter(&config.RateLimitConfig{
+		FieldSource: request.PayloadFieldSource,
+		Field:       "client.id",
+		MaxInFlight: 1,
+	})
+	assert.True(t, limiter.NeedsPayload())
+	assert.Equal(t, "client-a", limiter.HTTPRequestKey(http.Header{}, []byte(`{"client": {"id": "client-a"}}`)))
+	assert.Equal(t, "", limiter.HTTPRequestKey(http.Header{}, []byte(`{}`)))
+
+	limiter = NewLimiter(&config.RateLimitConfig{
+		FieldSource: request.HeaderFieldSource,
+		Field:       "X-Client-ID",
+		MaxInFlight: 1,
+	})
+	assert.False(t, limiter.NeedsPayload())
+	assert.Equal(t, "client-b", limiter.HTTPRequestKey(http.Header{"X-Client-Id": []string{"client-b"}}, nil))
+	assert.Equal(t, "client-c", limiter.UPIRequestKey(
+		metadata.Pairs("x-client-id", "client-c"),
+		&upiv1.PredictValuesRequest{},
+	))
+	assert.Equal(t, "", limiter.UPIRequestKey(metadata.MD{}, &upiv1.PredictValuesRequest{}))
+}
@@ -0,0 +1,45 @@
+package missionctl
+
+import (
+	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/cache"
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+)
+
+// responseCache caches the final responses of the router, by the values of the configured
+// key fields of the request
+type responseCache[V any] struct {
+	*cache.Cache[V]
+	keyFields []config.CacheKeyField
+}
+
+// newResponseCache creates a responseCache from the given config. It returns nil,
+// if the response cache is not enabled.
+func newResponseCache[V any](cfg *config.CacheConfig) *responseCache[V] {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	return &responseCache[V]{
+		Cache:     cache.New[V](cfg.MaxEntries, cfg.TTL),
+		keyFields: cfg.KeyFields,
+	}
+}
+
+// lookup returns the response cached for the given key, if any, and records the cache hit / miss
+func (c *responseCache[V]) lookup(key string) (V, bool) {
+	value, ok := c.Get(key)
+	result := "miss"
+	if ok {
+		result = "hit"
+	}
+	if err := metrics.Glob().Inc(
+		instrumentation.ResponseCacheRequestsTotal,
+		map[string]string{"result": result},
+	); err != nil {
+		log.Glob().Errorf("Failed to record response cache %s: %s", result, err.Error())
+	}
+	return value, ok
+}
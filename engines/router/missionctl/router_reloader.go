@@ -0,0 +1,157 @@
+package missionctl
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
+	"github.com/gojek/fiber"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/fiberapi"
+	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+)
+
+// ReloadSource is the source of the new config of a router reload
+type ReloadSource string
+
+const (
+	// FileReloadSource is used when the mounted config file has changed
+	FileReloadSource ReloadSource = "file"
+	// PushReloadSource is used when the config was pushed to the router
+	PushReloadSource ReloadSource = "push"
+)
+
+// FiberRouterSetter is implemented by the mission controls, which Fiber routers can be replaced at runtime
+type FiberRouterSetter interface {
+	SetFiberRouter(router fiber.Component)
+}
+
+// RouterReloader rebuilds the Fiber router from a new config and swaps it into the mission controls,
+// without restarting the router. The requests in flight complete on the previous Fiber router. If the
+// new config is invalid, the current Fiber router is kept.
+type RouterReloader struct {
+	cfgFilePath   string
+	fiberDebugLog bool
+	targets       []FiberRouterSetter
+
+	// mu serialises the reloads
+	mu sync.Mutex
+	// fileChecksum is the checksum of the config file, when it was last read. It's tracked separately
+	// from the pushed configs, so that a pushed config is only replaced once the config file changes.
+	fileChecksum [sha256.Size]byte
+}
+
+// NewRouterReloader creates a RouterReloader for the Fiber routers of the given mission controls, which
+// have been created from the given config file
+func NewRouterReloader(
+	cfgFilePath string,
+	fiberDebugLog bool,
+	targets ...FiberRouterSetter,
+) (*RouterReloader, error) {
+	cfg, err := os.ReadFile(cfgFilePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to read the router config")
+	}
+	return &RouterReloader{
+		cfgFilePath:   cfgFilePath,
+		fiberDebugLog: fiberDebugLog,
+		targets:       targets,
+		fileChecksum:  sha256.Sum256(cfg),
+	}, nil
+}
+
+// ReloadFromFile reloads the Fiber router from the config file, if it has changed since it was last read
+func (r *RouterReloader) ReloadFromFile() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg, err := os.ReadFile(r.cfgFilePath)
+	if err != nil {
+		r.recordReload(FileReloadSource, err)
+		return errors.Wrapf(err, "Failed to read the router config")
+	}
+	checksum := sha256.Sum256(cfg)
+	if checksum == r.fileChecksum {
+		return nil
+	}
+
+	// The config file is only reloaded once per change, even if it's invalid
+	r.fileChecksum = checksum
+	return r.reload(FileReloadSource, r.cfgFilePath)
+}
+
+// ReloadFromConfig reloads the Fiber router from the given config, in the same format as the config file.
+// The config file is left unchanged.
+func (r *RouterReloader) ReloadFromConfig(cfg []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The Fiber router can only be created from a file. The file keeps the extension of the config
+	// file, which determines how it's parsed.
+	tmpFile, err := os.CreateTemp("", "fiber-*"+filepath.Ext(r.cfgFilePath))
+	if err != nil {
+		r.recordReload(PushReloadSource, err)
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(cfg)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		r.recordReload(PushReloadSource, err)
+		return err
+	}
+	return r.reload(PushReloadSource, tmpFile.Name())
+}
+
+// Watch checks the config file for changes at the given interval, and reloads the Fiber router when it
+// has changed, until the context is done
+func (r *RouterReloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// The errors are already logged and measured
+			_ = r.ReloadFromFile()
+		}
+	}
+}
+
+func (r *RouterReloader) reload(source ReloadSource, cfgFilePath string) error {
+	fiberRouter, err := fiberapi.CreateFiberRouterFromConfig(cfgFilePath, r.fiberDebugLog)
+	if err != nil {
+		err = errors.Wrapf(err, "Failed to create the Fiber router from the new config")
+		r.recordReload(source, err)
+		return err
+	}
+
+	for _, target := range r.targets {
+		target.SetFiberRouter(fiberRouter)
+	}
+	r.recordReload(source, nil)
+	return nil
+}
+
+func (r *RouterReloader) recordReload(source ReloadSource, err error) {
+	if err != nil {
+		log.Glob().Errorf("Failed to reload the router config from %s: %s", source, err.Error())
+	} else {
+		log.Glob().Infof("Reloaded the router config from %s", source)
+	}
+	// Failing to record the metric should not fail the reload
+	_ = metrics.Glob().Inc(instrumentation.RouterConfigReloadsTotal, map[string]string{
+		"source": string(source),
+		"status": metrics.GetStatusString(err == nil),
+	})
+}
@@ -0,0 +1,73 @@
+package missionctl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gojek/fiber"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockFiberRouterSetter records the Fiber routers that it's been set with
+type mockFiberRouterSetter struct {
+	routers []fiber.Component
+}
+
+func (s *mockFiberRouterSetter) SetFiberRouter(router fiber.Component) {
+	s.routers = append(s.routers, router)
+}
+
+func TestRouterReloader(t *testing.T) {
+	defaultRouterCfg, err := os.ReadFile(filepath.Join("testdata", "nop_default_router.yaml"))
+	require.NoError(t, err)
+	ensemblingRouterCfg, err := os.ReadFile(filepath.Join("testdata", "nop_ensembling_router.yaml"))
+	require.NoError(t, err)
+	invalidRouterCfg := []byte("type: UNKNOWN_ROUTER\nid: router\n")
+
+	// Copy the router config, so that it can be modified
+	cfgFilePath := filepath.Join(t.TempDir(), "fiber.yaml")
+	require.NoError(t, os.WriteFile(cfgFilePath, defaultRouterCfg, 0600))
+
+	setter := &mockFiberRouterSetter{}
+	reloader, err := NewRouterReloader(cfgFilePath, false, setter)
+	require.NoError(t, err)
+
+	// The config file is unchanged
+	assert.NoError(t, reloader.ReloadFromFile())
+	assert.Len(t, setter.routers, 0)
+
+	// The config file has changed
+	require.NoError(t, os.WriteFile(cfgFilePath, ensemblingRouterCfg, 0600))
+	assert.NoError(t, reloader.ReloadFromFile())
+	require.Len(t, setter.routers, 1)
+	assert.Equal(t, "combiner", setter.routers[0].ID())
+
+	// The config file has changed, but is invalid
+	require.NoError(t, os.WriteFile(cfgFilePath, invalidRouterCfg, 0600))
+	assert.Error(t, reloader.ReloadFromFile())
+	assert.Len(t, setter.routers, 1)
+
+	// The config is pushed
+	assert.NoError(t, reloader.ReloadFromConfig(defaultRouterCfg))
+	require.Len(t, setter.routers, 2)
+	assert.Equal(t, "eager-router", setter.routers[1].ID())
+
+	// The pushed config is invalid
+	assert.Error(t, reloader.ReloadFromConfig(invalidRouterCfg))
+	assert.Len(t, setter.routers, 2)
+
+	// The invalid config file is not reloaded again, and the pushed config is kept until the config file changes
+	assert.NoError(t, reloader.ReloadFromFile())
+	assert.Len(t, setter.routers, 2)
+	require.NoError(t, os.WriteFile(cfgFilePath, ensemblingRouterCfg, 0600))
+	assert.NoError(t, reloader.ReloadFromFile())
+	assert.Len(t, setter.routers, 3)
+	assert.NoError(t, reloader.ReloadFromFile())
+	assert.Len(t, setter.routers, 3)
+
+	// The config file doesn't exist
+	_, err = NewRouterReloader(filepath.Join(t.TempDir(), "unknown.yaml"), false, setter)
+	assert.Error(t, err)
+}
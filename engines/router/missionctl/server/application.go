@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net"
@@ -11,12 +12,15 @@ import (
 	"github.com/soheilhy/cmux"
 
 	"github.com/caraml-dev/turing/engines/router/missionctl"
+	"github.com/caraml-dev/turing/engines/router/missionctl/auth"
+	"github.com/caraml-dev/turing/engines/router/missionctl/bandit"
 	"github.com/caraml-dev/turing/engines/router/missionctl/config"
 	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
 	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation/metrics"
 	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation/tracing"
 	"github.com/caraml-dev/turing/engines/router/missionctl/log"
 	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog"
+	"github.com/caraml-dev/turing/engines/router/missionctl/ratelimit"
 	"github.com/caraml-dev/turing/engines/router/missionctl/server/http/handlers"
 	"github.com/caraml-dev/turing/engines/router/missionctl/server/upi"
 
@@ -43,18 +47,35 @@ func Run() {
 	defer initInstrumentation(cfg)()
 	// Init Sentry, defer closing client
 	defer initSentryClient(cfg)()
+	// Init the bandit state, defer saving its last snapshot
+	defer initBandit(cfg)()
+	// Init the authenticator of the callers, if enabled, defer stopping the refreshes of its keys
+	authenticator, stopAuthenticator := initAuthenticator(cfg)
+	defer stopAuthenticator()
+	// Init the admission control of the clients' requests, if enabled
+	limiter := ratelimit.NewLimiter(cfg.RouterConfig.RateLimit)
+
+	// shuttingDown is closed when the router starts shutting down, to fail the readiness checks
+	shuttingDown := make(chan struct{})
+	// serveErrCh receives the errors of the servers, which fail before the router shuts down
+	serveErrCh := make(chan error, 4)
 
 	switch cfg.RouterConfig.Protocol {
 	case config.UPI:
 		resultLogger, err := initUpiResultLogger(cfg.AppConfig)
 		if err != nil {
-			log.Glob().Panicf("Failed to init UPI resultLogger")
+			log.Glob().Panicf("Failed to init UPI resultLogger: %v", err)
 		}
 
 		// Init mission control
 		missionCtl, err := missionctl.NewMissionControlUPI(
 			cfg.RouterConfig.ConfigFile,
 			cfg.AppConfig.FiberDebugLog,
+			cfg.EnrichmentConfig,
+			cfg.EnsemblerConfig,
+			cfg.RouterConfig.Cache,
+			cfg.RouterConfig.Fallback,
+			cfg.ProcessingStages,
 		)
 		if err != nil {
 			log.Glob().Panicf("Failed initializing Mission Control: %v", err)
@@ -65,7 +86,13 @@ func Run() {
 			log.Glob().Panicf("Failed to listen on port: %v", cfg.Port)
 		}
 
-		upiServer := upi.NewUPIServer(missionCtl, resultLogger)
+		upiServer := upi.NewUPIServer(
+			missionCtl,
+			resultLogger,
+			cfg.RouterConfig.DebugResponseEnabled,
+			authenticator,
+			limiter,
+		)
 		m := cmux.New(l)
 		grpcListener := m.MatchWithWriters(cmux.HTTP2MatchHeaderFieldPrefixSendSettings("content-type", "application/grpc"))
 		httpListener := m.Match(cmux.Any())
@@ -73,23 +100,25 @@ func Run() {
 		mux := http.NewServeMux()
 		mux.Handle("/v1/internal/", http.StripPrefix(
 			"/v1/internal",
-			handlers.NewInternalAPIHandler([]string{}),
+			handlers.NewInternalAPIHandler([]string{}, shuttingDown),
 		))
+		mux.Handle("/v1/feedback", handlers.NewAuthMiddleware(authenticator, handlers.NewFeedbackHandler(bandit.Glob())))
 		if cfg.AppConfig.CustomMetrics {
 			mux.Handle("/metrics", promhttp.Handler())
 		}
 		httpServer := &http.Server{Handler: mux}
 
+		shutdownReloader := initRouterReloader(cfg, authenticator, serveErrCh, missionCtl)
+
 		log.Glob().Infof("Starting UPI Router in port %d", cfg.Port)
-		go upiServer.Run(grpcListener)
-		go func() {
-			if err := httpServer.Serve(httpListener); err != nil {
-				log.Glob().Errorf("Failed to serve http server: %s", err)
-			}
-		}()
-		if err := m.Serve(); err != nil {
-			log.Glob().Errorf("Failed to serve cmux: %s", err)
-		}
+		go serve(serveErrCh, "UPI server", func() error { return upiServer.Run(grpcListener) })
+		go serve(serveErrCh, "http server", func() error { return httpServer.Serve(httpListener) })
+		go serve(serveErrCh, "cmux", m.Serve)
+
+		waitForShutdown(serveErrCh)
+		shutdown(cfg.AppConfig.ShutdownDrainDelay, cfg.AppConfig.ShutdownGracePeriod, shuttingDown, resultLogger,
+			upiServer.Shutdown, httpServer.Shutdown, shutdownReloader)
+		m.Close()
 	case config.HTTP:
 		resultLogger, err := initTuringResultLogger(cfg.AppConfig)
 		if err != nil {
@@ -103,6 +132,7 @@ func Run() {
 			cfg.RouterConfig,
 			cfg.EnsemblerConfig,
 			cfg.AppConfig,
+			cfg.ProcessingStages,
 		)
 		if err != nil {
 			log.Glob().Panicf("Failed initializing Mission Control: %v", err)
@@ -110,19 +140,33 @@ func Run() {
 		// Register handlers
 		http.Handle("/v1/internal/", http.StripPrefix(
 			"/v1/internal",
-			handlers.NewInternalAPIHandler([]string{}),
+			handlers.NewInternalAPIHandler([]string{}, shuttingDown),
 		))
-		http.Handle("/v1/predict", sentry.Recoverer(handlers.NewHTTPHandler(missionCtl, resultLogger)))
-		http.Handle("/v1/batch_predict", sentry.Recoverer(handlers.NewBatchHTTPHandler(missionCtl, resultLogger)))
+		http.Handle("/v1/predict", sentry.Recoverer(handlers.NewAuthMiddleware(
+			authenticator,
+			handlers.NewRateLimitMiddleware(
+				limiter,
+				handlers.NewHTTPHandler(missionCtl, resultLogger, cfg.RouterConfig.DebugResponseEnabled),
+			),
+		)))
+		http.Handle("/v1/batch_predict", sentry.Recoverer(handlers.NewAuthMiddleware(
+			authenticator,
+			handlers.NewRateLimitMiddleware(limiter, handlers.NewBatchHTTPHandler(missionCtl, resultLogger)),
+		)))
+		http.Handle("/v1/feedback", handlers.NewAuthMiddleware(authenticator, handlers.NewFeedbackHandler(bandit.Glob())))
 		// Register metrics handler
 		if cfg.AppConfig.CustomMetrics {
 			http.Handle("/metrics", promhttp.Handler())
 		}
+		shutdownReloader := initRouterReloader(cfg, authenticator, serveErrCh, missionCtl)
 		// Serve
+		httpServer := &http.Server{Addr: cfg.ListenAddress(), Handler: http.DefaultServeMux}
 		log.Glob().Infof("listening at port %d", cfg.Port)
-		if err := http.ListenAndServe(cfg.ListenAddress(), http.DefaultServeMux); err != nil {
-			log.Glob().Errorf("Failed to start Turing Mission Control API: %s", err)
-		}
+		go serve(serveErrCh, "Turing Mission Control API", httpServer.ListenAndServe)
+
+		waitForShutdown(serveErrCh)
+		shutdown(cfg.AppConfig.ShutdownDrainDelay, cfg.AppConfig.ShutdownGracePeriod, shuttingDown, resultLogger,
+			httpServer.Shutdown, shutdownReloader)
 	default:
 		log.Glob().Panicf("router protocol %s not supported", cfg.RouterConfig.Protocol)
 	}
@@ -152,6 +196,96 @@ func initInstrumentation(cfg *config.Config) func() {
 	}
 }
 
+// initRouterReloader initializes the hot reloads of the router's Fiber config, if enabled, by watching
+// the config file for changes and / or serving the internal endpoint that accepts a new config, on its
+// own listener. The callers of the internal endpoint are authenticated like those of the prediction
+// endpoints. The returned function stops the server of the internal endpoint, if any.
+func initRouterReloader(
+	cfg *config.Config,
+	authenticator *auth.Authenticator,
+	serveErrCh chan<- error,
+	targets ...missionctl.FiberRouterSetter,
+) shutdownFunc {
+	noServer := func(context.Context) error { return nil }
+	reloadCfg := cfg.RouterConfig.Reload
+	if reloadCfg == nil || (reloadCfg.WatchInterval <= 0 && !reloadCfg.PushEnabled) {
+		return noServer
+	}
+
+	reloader, err := missionctl.NewRouterReloader(
+		cfg.RouterConfig.ConfigFile,
+		cfg.AppConfig.FiberDebugLog,
+		targets...,
+	)
+	if err != nil {
+		log.Glob().Panicf("Failed initializing the router reloader: %v", err)
+	}
+	if reloadCfg.WatchInterval > 0 {
+		log.Glob().Infof("Watching the router config for changes every %s", reloadCfg.WatchInterval)
+		go reloader.Watch(context.Background(), reloadCfg.WatchInterval)
+	}
+	if !reloadCfg.PushEnabled {
+		return noServer
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/v1/internal/config", handlers.NewAuthMiddleware(authenticator, handlers.NewConfigReloadHandler(reloader)))
+	server := &http.Server{Addr: fmt.Sprintf(":%d", reloadCfg.PushPort), Handler: mux}
+	log.Glob().Infof("Accepting router configs at port %d", reloadCfg.PushPort)
+	go serve(serveErrCh, "router config server", server.ListenAndServe)
+	return server.Shutdown
+}
+
+// initBandit initializes the state of the multi-armed bandit routing strategy and, if the snapshot
+// file is configured, saves the state to it periodically. The returned function stops saving the
+// state, after saving it one last time.
+func initBandit(cfg *config.Config) func() {
+	banditCfg := cfg.RouterConfig.Bandit
+	if banditCfg == nil {
+		return func() {}
+	}
+	if err := bandit.InitGlobalState(banditCfg); err != nil {
+		log.Glob().Panicf("Failed initializing the bandit state: %v", err)
+	}
+	if banditCfg.SnapshotFile == "" || banditCfg.SnapshotInterval <= 0 {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bandit.Glob().RunSnapshots(ctx, banditCfg.SnapshotFile, banditCfg.SnapshotInterval)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// initAuthenticator initializes the authenticator of the callers of the prediction endpoints, if enabled,
+// and reloads its JWKS periodically. The returned function stops reloading the JWKS.
+func initAuthenticator(cfg *config.Config) (*auth.Authenticator, func()) {
+	authenticator, err := auth.NewAuthenticator(cfg.RouterConfig.Auth)
+	if err != nil {
+		log.Glob().Panicf("Failed initializing the authenticator: %v", err)
+	}
+	if authenticator == nil {
+		return nil, func() {}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		authenticator.RunKeyRefresh(ctx)
+	}()
+	return authenticator, func() {
+		cancel()
+		<-done
+	}
+}
+
 // initSentryClient initializes the Sentry client for error logging
 func initSentryClient(cfg *config.Config) func() {
 	if cfg.AppConfig.Sentry.Enabled {
@@ -170,19 +304,66 @@ func initSentryClient(cfg *config.Config) func() {
 	return func() {}
 }
 
-// initTuringResultLogger created the underlying logging middleware
-// base on config return a TuringResultLogger which abstract it away
+// initTuringResultLogger created the underlying logging middleware for each of the configured
+// result loggers, base on config, and return a TuringResultLogger which abstract them away
 func initTuringResultLogger(cfg *config.AppConfig) (*resultlog.ResultLogger, error) {
-	var err error
-	var logger resultlog.TuringResultLogger
+	return initTuringResultLoggers(cfg, cfg.ResultLoggerTypes())
+}
 
-	switch cfg.ResultLogger {
+// initTuringResultLoggers creates the given result loggers. If there are several of them, each
+// TuringResultLogMessage is written to all of them.
+func initTuringResultLoggers(
+	cfg *config.AppConfig,
+	loggerTypes []config.ResultLogger,
+) (*resultlog.ResultLogger, error) {
+	sinks := make([]resultlog.ResultLogSink, 0, len(loggerTypes))
+	for _, loggerType := range loggerTypes {
+		logger, err := newTuringResultLogger(cfg, loggerType)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.ResultLogSpool.Enabled() {
+			log.Glob().Infof("Initializing the result log spool of %s in %s", loggerType, cfg.ResultLogSpool.Dir)
+			logger, err = resultlog.NewSpoolLogger(logger, loggerType, cfg.ResultLogSpool)
+			if err != nil {
+				return nil, err
+			}
+		}
+		sinks = append(sinks, resultlog.ResultLogSink{Type: loggerType, Logger: logger})
+	}
+
+	logger := sinks[0].Logger
+	if len(sinks) > 1 {
+		log.Glob().Infof("Initializing Multi Result Logger with %d result loggers", len(sinks))
+		logger = resultlog.NewMultiLogger(sinks...)
+	}
+	if cfg.ResultLogQueue.Enabled() {
+		log.Glob().Infof("Initializing the result log queue of size %d with %d workers",
+			cfg.ResultLogQueue.Size, cfg.ResultLogQueue.Workers)
+		queueLogger, err := resultlog.NewQueueLogger(logger, cfg.ResultLogQueue)
+		if err != nil {
+			return nil, err
+		}
+		logger = queueLogger
+	}
+	policy, err := resultlog.NewPolicy(cfg.ResultLogPolicy)
+	if err != nil {
+		return nil, err
+	}
+	return resultlog.InitTuringResultLogger(cfg.Name, logger, policy), nil
+}
+
+// newTuringResultLogger creates the logging middleware of the given result logger type
+func newTuringResultLogger(
+	cfg *config.AppConfig,
+	loggerType config.ResultLogger,
+) (resultlog.TuringResultLogger, error) {
+	switch loggerType {
 	case config.BigqueryLogger:
-		var bqLogger resultlog.BigQueryLogger
 		log.Glob().Info("Initializing BigQuery Result Logger")
 		// Init BQ logger. This will also run the necessary checks on the table schema /
 		// create it if not exists
-		bqLogger, err = resultlog.NewBigQueryLogger(cfg.BigQuery)
+		bqLogger, err := resultlog.NewBigQueryLogger(cfg.BigQuery)
 		if err != nil {
 			return nil, err
 		}
@@ -191,60 +372,60 @@ func initTuringResultLogger(cfg *config.AppConfig) (*resultlog.ResultLogger, err
 		if cfg.BigQuery.BatchLoad {
 			log.Glob().Info("Initializing Fluentd logger for batch logging")
 			// Init fluentd logger for batch logging
-			logger, err = resultlog.NewFluentdLogger(cfg.Fluentd, bqLogger)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			// Use BigQueryLogger for streaming insert
-			logger = bqLogger
+			return resultlog.NewFluentdLogger(cfg.Fluentd, bqLogger)
 		}
+		// Use BigQueryLogger for streaming insert
+		return bqLogger, nil
 	case config.ConsoleLogger:
 		log.Glob().Info("Initializing Console Result Logger")
-		logger = resultlog.NewConsoleLogger()
+		return resultlog.NewConsoleLogger(), nil
 	case config.KafkaLogger:
 		log.Glob().Info("Initializing Kafka Result Logger")
-		logger, err = resultlog.NewKafkaLogger(cfg.Kafka)
-		if err != nil {
-			return nil, err
-		}
+		return resultlog.NewKafkaLogger(cfg.Kafka)
 	case config.NopLogger:
 		log.Glob().Info("Initializing Nop Result Logger")
-		logger = resultlog.NewNopLogger()
-	default:
-		err = errors.Newf(errors.BadInput, "Unrecognized Result Logger: %s", cfg.ResultLogger)
-		return nil, err
+		return resultlog.NewNopLogger(), nil
 	}
-	rl := resultlog.InitTuringResultLogger(cfg.Name, logger)
-	return rl, nil
+	return nil, errors.Newf(errors.BadInput, "Unrecognized Result Logger: %s", loggerType)
 }
 
 // initUpiResultLogger created the underlying middleware for UPI logger type,
-// for other logger type, the TuringResultLogger is reused
+// for other logger types, the TuringResultLogger is reused
 func initUpiResultLogger(cfg *config.AppConfig) (*resultlog.UPIResultLogger, error) {
-
-	var rl *resultlog.UPIResultLogger
-	switch cfg.ResultLogger {
-	case config.UPILogger:
+	var upiLogger resultlog.UPILogger
+	var turingLoggerTypes []config.ResultLogger
+	for _, loggerType := range cfg.ResultLoggerTypes() {
+		if loggerType != config.UPILogger {
+			turingLoggerTypes = append(turingLoggerTypes, loggerType)
+			continue
+		}
 		// only kafka logger is supported now
 		logger, err := resultlog.NewUPIKafkaLogger(cfg.Kafka)
 		if err != nil {
 			return nil, err
 		}
-		rl, err = resultlog.InitUPIResultLogger(cfg.Name, cfg.ResultLogger, logger, nil)
-		if err != nil {
-			return nil, err
-		}
-	default:
-		resultLogger, err := initTuringResultLogger(cfg)
-		if err != nil {
-			log.Glob().Fatalf("Failed initializing Turing Result ResultLogger: %v", err)
-			return nil, err
+		upiLogger = logger
+		if cfg.ResultLogQueue.Enabled() {
+			log.Glob().Infof("Initializing the UPI router log queue of size %d with %d workers",
+				cfg.ResultLogQueue.Size, cfg.ResultLogQueue.Workers)
+			upiLogger, err = resultlog.NewUPIQueueLogger(logger, cfg.ResultLogQueue)
+			if err != nil {
+				return nil, err
+			}
 		}
-		rl, err = resultlog.InitUPIResultLogger(cfg.Name, cfg.ResultLogger, nil, resultLogger)
+	}
+
+	var resultLogger *resultlog.ResultLogger
+	if len(turingLoggerTypes) > 0 {
+		var err error
+		resultLogger, err = initTuringResultLoggers(cfg, turingLoggerTypes)
 		if err != nil {
 			return nil, err
 		}
 	}
-	return rl, nil
+	policy, err := resultlog.NewPolicy(cfg.ResultLogPolicy)
+	if err != nil {
+		return nil, err
+	}
+	return resultlog.InitUPIResultLogger(cfg.Name, upiLogger, resultLogger, policy)
 }
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	fiberProtocol "github.com/gojek/fiber/protocol"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router/missionctl/auth"
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+)
+
+// NewAuthMiddleware creates a handler that authenticates the caller with the given authenticator, if any,
+// before passing the request on to the next handler. The identity of the authenticated caller is passed on
+// to the Turing components in the caller header, which is always removed from the incoming request, so that
+// it cannot be spoofed by the client. The credentials of the caller, i.e. the API key and the JWT, are not
+// passed on.
+func NewAuthMiddleware(authenticator *auth.Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		req.Header.Del(request.CallerHeader)
+		if authenticator != nil {
+			caller, err := authenticator.Authenticate(
+				req.Header.Get(auth.APIKeyHeader),
+				req.Header.Get(auth.AuthorizationHeader),
+			)
+			if err != nil {
+				turingErr := errors.NewTuringError(err, fiberProtocol.HTTP)
+				http.Error(rw, turingErr.Message, turingErr.Code)
+				return
+			}
+			req.Header.Del(auth.APIKeyHeader)
+			req.Header.Del(auth.AuthorizationHeader)
+			req.Header.Set(request.CallerHeader, caller.Encode())
+		}
+		next.ServeHTTP(rw, req)
+	})
+}
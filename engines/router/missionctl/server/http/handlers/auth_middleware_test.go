@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/auth"
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	apiKeysFile := filepath.Join(t.TempDir(), "api-keys.json")
+	require.NoError(t, os.WriteFile(apiKeysFile, []byte(`{"team-a": "key-a"}`), 0600))
+	authenticator, err := auth.NewAuthenticator(&config.AuthConfig{APIKeysFile: apiKeysFile})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		authenticator  *auth.Authenticator
+		header         http.Header
+		expectedCode   int
+		expectedBody   string
+		expectedHeader http.Header
+	}{
+		"success | authenticated": {
+			authenticator: authenticator,
+			header: http.Header{
+				"X-Api-Key":     []string{"key-a"},
+				"Authorization": []string{"Bearer token"},
+				"Turing-Caller": []string{`{"id":"spoofed"}`},
+			},
+			expectedCode: http.StatusOK,
+			expectedHeader: http.Header{
+				"Turing-Caller": []string{`{"id":"team-a","method":"api_key"}`},
+			},
+		},
+		"success | authentication disabled": {
+			header: http.Header{
+				"X-Api-Key":     []string{"key-a"},
+				"Turing-Caller": []string{`{"id":"spoofed"}`},
+			},
+			expectedCode: http.StatusOK,
+			expectedHeader: http.Header{
+				"X-Api-Key": []string{"key-a"},
+			},
+		},
+		"failure | invalid api key": {
+			authenticator: authenticator,
+			header:        http.Header{"X-Api-Key": []string{"key-b"}},
+			expectedCode:  http.StatusUnauthorized,
+			expectedBody:  "Invalid API key\n",
+		},
+		"failure | missing credentials": {
+			authenticator: authenticator,
+			header:        http.Header{},
+			expectedCode:  http.StatusUnauthorized,
+			expectedBody:  "Missing credentials\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var receivedHeader http.Header
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				receivedHeader = req.Header
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/predict", nil)
+			req.Header = tt.header
+			rr := httptest.NewRecorder()
+			NewAuthMiddleware(tt.authenticator, next).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+			assert.Equal(t, tt.expectedHeader, receivedHeader)
+		})
+	}
+}
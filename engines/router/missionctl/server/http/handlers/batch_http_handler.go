@@ -35,7 +35,8 @@ type batchResponse struct {
 	Data       json.RawMessage `json:"data,omitempty"`
 }
 
-// NewBatchHTTPHandler creates an instance of the Mission Control's prediction request handler
+// NewBatchHTTPHandler creates an instance of the Mission Control's batch prediction request handler.
+// The debug trace is not available for the batch requests.
 func NewBatchHTTPHandler(mc missionctl.MissionControl, rl *resultlog.ResultLogger) http.Handler {
 	return &batchHTTPHandler{httpHandler{MissionControl: mc, rl: rl}}
 }
@@ -109,7 +110,7 @@ func (h *batchHTTPHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request)
 				batchResponses[index] = batchResponse
 				return
 			}
-			batchResponse.StatusCode = http.StatusOK
+			batchResponse.StatusCode = responseStatus(resp)
 			batchResponse.Data = resp.Body()
 			batchResponses[index] = batchResponse
 		}(index, value)
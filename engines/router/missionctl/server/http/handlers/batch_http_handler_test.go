@@ -98,7 +98,7 @@ func TestNewBatchHTTPHandler(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			batchHTTPHandler := NewBatchHTTPHandler(
 				test.missionCtl,
-				resultlog.InitTuringResultLogger("", resultlog.NewNopLogger()))
+				resultlog.InitTuringResultLogger("", resultlog.NewNopLogger(), nil))
 			assert.NotNil(t, batchHTTPHandler)
 
 			req := httptest.NewRequest(http.MethodPost, "/v1/batch_predict", bytes.NewBuffer([]byte(test.payload)))
@@ -150,6 +150,7 @@ func createGenericMissionControl(configFilePath string) (missionctl.MissionContr
 		&config.AppConfig{
 			FiberDebugLog: false,
 		},
+		nil,
 	)
 	return missionCtl, err
 }
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MaxConfigBytes is the maximum size of the router config accepted by the config reload handler
+const MaxConfigBytes = 4 << 20
+
+// ConfigReloader reloads the router from a new config
+type ConfigReloader interface {
+	ReloadFromConfig(cfg []byte) error
+}
+
+// NewConfigReloadHandler creates an instance of the handler that accepts a new config for the router,
+// in the same format as the router's config file, and reloads the router with it
+func NewConfigReloadHandler(reloader ConfigReloader) http.Handler {
+	return &configReloadHandler{reloader: reloader}
+}
+
+type configReloadHandler struct {
+	reloader ConfigReloader
+}
+
+func (h *configReloadHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost && req.Method != http.MethodPut {
+		rw.Header().Set("Allow", "POST, PUT")
+		http.Error(rw, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cfg, err := io.ReadAll(http.MaxBytesReader(rw, req.Body, MaxConfigBytes))
+	if err != nil {
+		code := http.StatusBadRequest
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			code = http.StatusRequestEntityTooLarge
+		}
+		http.Error(rw, fmt.Sprintf("Failed to read the router config: %s", err), code)
+		return
+	}
+	if len(cfg) == 0 {
+		http.Error(rw, "Router config is empty", http.StatusBadRequest)
+		return
+	}
+
+	// The current router is kept if the new config is invalid
+	if err := h.reloader.ReloadFromConfig(cfg); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+}
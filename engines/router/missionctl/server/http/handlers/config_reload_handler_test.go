@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockConfigReloader struct {
+	cfg []byte
+	err error
+}
+
+func (r *mockConfigReloader) ReloadFromConfig(cfg []byte) error {
+	r.cfg = cfg
+	return r.err
+}
+
+func TestConfigReloadHandler(t *testing.T) {
+	tests := map[string]struct {
+		method       string
+		body         string
+		reloadErr    error
+		expectedCode int
+		expectedBody string
+		expectedCfg  string
+	}{
+		"success": {
+			method:       http.MethodPost,
+			body:         "id: router",
+			expectedCode: http.StatusOK,
+			expectedCfg:  "id: router",
+		},
+		"failure | invalid config": {
+			method:       http.MethodPost,
+			body:         "id: router",
+			reloadErr:    errors.New("invalid config"),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "invalid config\n",
+			expectedCfg:  "id: router",
+		},
+		"failure | empty config": {
+			method:       http.MethodPost,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "Router config is empty\n",
+		},
+		"failure | config too large": {
+			method:       http.MethodPost,
+			body:         strings.Repeat("#", MaxConfigBytes+1),
+			expectedCode: http.StatusRequestEntityTooLarge,
+			expectedBody: "Failed to read the router config: http: request body too large\n",
+		},
+		"failure | method not allowed": {
+			method:       http.MethodGet,
+			expectedCode: http.StatusMethodNotAllowed,
+			expectedBody: "Method not allowed\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			reloader := &mockConfigReloader{err: tt.reloadErr}
+			req := httptest.NewRequest(tt.method, "/config", bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+
+			NewConfigReloadHandler(reloader).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+			assert.Equal(t, tt.expectedCfg, string(reloader.cfg))
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	fiberProtocol "github.com/gojek/fiber/protocol"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+)
+
+// RewardRecorder records the reward for the route selected for a request
+type RewardRecorder interface {
+	Reward(requestID string, reward float64) (string, error)
+}
+
+// feedbackRequest is the payload of the feedback endpoint
+type feedbackRequest struct {
+	// TuringReqID is the Turing request ID, returned in the Turing-Req-ID header of the response
+	TuringReqID string `json:"turing_req_id"`
+	// Reward is the reward of the response, between 0 and 1
+	Reward *float64 `json:"reward"`
+}
+
+// feedbackResponse is the response of the feedback endpoint
+type feedbackResponse struct {
+	// RouteID is the route, that the reward is attributed to
+	RouteID string `json:"route_id"`
+}
+
+// NewFeedbackHandler creates an instance of the handler that accepts the rewards of the requests,
+// which routes were selected by the multi-armed bandit routing strategy
+func NewFeedbackHandler(recorder RewardRecorder) http.Handler {
+	return &feedbackHandler{recorder: recorder}
+}
+
+type feedbackHandler struct {
+	recorder RewardRecorder
+}
+
+func (h *feedbackHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		rw.Header().Set("Allow", "POST")
+		http.Error(rw, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var feedback feedbackRequest
+	if err := json.NewDecoder(req.Body).Decode(&feedback); err != nil {
+		http.Error(rw, fmt.Sprintf("Failed to parse the feedback: %s", err), http.StatusBadRequest)
+		return
+	}
+	if feedback.TuringReqID == "" || feedback.Reward == nil {
+		http.Error(rw, "Both turing_req_id and reward must be set", http.StatusBadRequest)
+		return
+	}
+
+	routeID, err := h.recorder.Reward(feedback.TuringReqID, *feedback.Reward)
+	if err != nil {
+		http.Error(rw, err.Error(), errors.GetErrorCode(err, fiberProtocol.HTTP))
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(rw).Encode(feedbackResponse{RouteID: routeID})
+}
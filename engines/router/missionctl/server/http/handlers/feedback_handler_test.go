@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+)
+
+type mockRewardRecorder struct {
+	requestID string
+	reward    float64
+	routeID   string
+	err       error
+}
+
+func (r *mockRewardRecorder) Reward(requestID string, reward float64) (string, error) {
+	r.requestID, r.reward = requestID, reward
+	return r.routeID, r.err
+}
+
+func TestFeedbackHandler(t *testing.T) {
+	tests := map[string]struct {
+		method            string
+		body              string
+		rewardErr         error
+		expectedCode      int
+		expectedBody      string
+		expectedRequestID string
+		expectedReward    float64
+	}{
+		"success": {
+			method:            http.MethodPost,
+			body:              `{"turing_req_id": "req-1", "reward": 0.5}`,
+			expectedCode:      http.StatusOK,
+			expectedBody:      `{"route_id":"treatment-a"}` + "\n",
+			expectedRequestID: "req-1",
+			expectedReward:    0.5,
+		},
+		"failure | unknown request": {
+			method:            http.MethodPost,
+			body:              `{"turing_req_id": "req-1", "reward": 0}`,
+			rewardErr:         errors.Newf(errors.NotFound, "No route selection"),
+			expectedCode:      http.StatusNotFound,
+			expectedBody:      "No route selection\n",
+			expectedRequestID: "req-1",
+		},
+		"failure | missing reward": {
+			method:       http.MethodPost,
+			body:         `{"turing_req_id": "req-1"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "Both turing_req_id and reward must be set\n",
+		},
+		"failure | invalid payload": {
+			method:       http.MethodPost,
+			body:         `[]`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "Failed to parse the feedback: " +
+				"json: cannot unmarshal array into Go value of type handlers.feedbackRequest\n",
+		},
+		"failure | method not allowed": {
+			method:       http.MethodGet,
+			expectedCode: http.StatusMethodNotAllowed,
+			expectedBody: "Method not allowed\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := &mockRewardRecorder{routeID: "treatment-a", err: tt.rewardErr}
+			req := httptest.NewRequest(tt.method, "/v1/feedback", bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+
+			NewFeedbackHandler(recorder).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+			assert.Equal(t, tt.expectedRequestID, recorder.requestID)
+			assert.Equal(t, tt.expectedReward, recorder.reward)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,14 +14,20 @@ import (
 
 	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
 
+	"github.com/caraml-dev/turing/engines/router"
 	"github.com/caraml-dev/turing/engines/router/missionctl"
+	"github.com/caraml-dev/turing/engines/router/missionctl/debug"
 	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
 	"github.com/caraml-dev/turing/engines/router/missionctl/experiment"
+	"github.com/caraml-dev/turing/engines/router/missionctl/fallback"
+	"github.com/caraml-dev/turing/engines/router/missionctl/hedging"
 	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation/tracing"
 	"github.com/caraml-dev/turing/engines/router/missionctl/log"
 	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog"
 	"github.com/caraml-dev/turing/engines/router/missionctl/server/constant"
 	mchttp "github.com/caraml-dev/turing/engines/router/missionctl/server/http"
+	"github.com/caraml-dev/turing/engines/router/missionctl/shadow"
+	"github.com/caraml-dev/turing/engines/router/missionctl/timing"
 	"github.com/caraml-dev/turing/engines/router/missionctl/turingctx"
 
 	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
@@ -28,15 +35,17 @@ import (
 
 const httpHandlerID = "http_handler"
 
-// NewHTTPHandler creates an instance of the Mission Control's prediction request handler
-func NewHTTPHandler(mc missionctl.MissionControl, rl *resultlog.ResultLogger) http.Handler {
-	return &httpHandler{MissionControl: mc, rl: rl}
+// NewHTTPHandler creates an instance of the Mission Control's prediction request handler. If debugEnabled
+// is set, the clients can request the debug trace of a request, with the debug request header.
+func NewHTTPHandler(mc missionctl.MissionControl, rl *resultlog.ResultLogger, debugEnabled bool) http.Handler {
+	return &httpHandler{MissionControl: mc, rl: rl, debugEnabled: debugEnabled}
 }
 
 // httpHandler is the Mission Control's prediction request handler
 type httpHandler struct {
 	missionctl.MissionControl
-	rl *resultlog.ResultLogger
+	rl           *resultlog.ResultLogger
+	debugEnabled bool
 }
 
 func (h *httpHandler) error(
@@ -69,26 +78,76 @@ func (h *httpHandler) getPrediction(
 	ctxLogger *zap.SugaredLogger,
 	requestBody []byte,
 ) (mchttp.Response, *errors.TuringError) {
-	// Create response channel to store the response from each step. Allocate buffer size = 4
-	// (max responses possible, from enricher, experiment engine, router and ensembler respectively).
-	respCh := make(chan resultlog.RouterResponse, 4)
+	preStages := h.GetProcessingStages(router.PreprocessingPhase)
+	postStages := h.GetProcessingStages(router.PostprocessingPhase)
+	// Create response channel to store the response from each step. Allocate buffer size = 7 + the number of
+	// processing stages (max responses possible, from enricher, experiment engine, router, shadow routes,
+	// hedged requests, timings, ensembler and each processing stage respectively).
+	respCh := make(chan resultlog.RouterResponse, 7+len(preStages)+len(postStages))
 
 	// Get Turing Request Id
 	turingReqID, _ := turingctx.GetRequestID(ctx)
+	// Get the debug trace, if the request is in debug mode
+	trace, _ := debug.GetTrace(ctx)
+
+	// Collect the responses from the shadow routes, if any, for logging
+	shadowCollector := shadow.NewCollector()
+	ctx = shadow.WithCollector(ctx, shadowCollector)
+	// Collect the hedged requests to the routes, if any, for logging
+	hedgingCollector := hedging.NewCollector()
+	ctx = hedging.WithCollector(ctx, hedgingCollector)
+	// Collect the timings of the calls to the components and the routes, for logging
+	timingCollector := timing.NewCollector()
+	ctx = timing.WithCollector(ctx, timingCollector)
+	// Record the traffic rule, that the request matched, to pick its fallback response if the routes fail
+	selection := fallback.NewSelection()
+	ctx = fallback.WithSelection(ctx, selection)
 
 	// Defer logging request summary
+	logSummary := func() {
+		timestamp := time.Now()
+		h.rl.SendShadowResponsesToLogChannel(respCh, shadowCollector.Wait())
+		h.rl.SendHedgedRequestsToLogChannel(respCh, hedgingCollector.Records())
+		h.rl.SendTimingsToLogChannel(respCh, &resultlog.Timings{
+			Records:     timingCollector.Records(),
+			RouteID:     timingCollector.RouteID(),
+			TrafficRule: selection.TrafficRule(),
+		})
+		// respCh should be closed first before calling logTuringRouterRequestSummary
+		// because logTuringRouterRequestSummary only returns when respCh is closed
+		close(respCh)
+		h.rl.LogTuringRouterRequestSummary(turingReqID, ctxLogger, timestamp, req.Header, requestBody, respCh)
+	}
 	defer func() {
-		go func() {
-			// respCh should be closed first before calling logTuringRouterRequestSummary
-			// because logTuringRouterRequestSummary only returns when respCh is closed
-			close(respCh)
-			h.rl.LogTuringRouterRequestSummary(turingReqID, ctxLogger, time.Now(), req.Header, requestBody, respCh)
-		}()
+		// Shadow routes are dispatched asynchronously and may still be in progress,
+		// wait for their responses without delaying the response to the client
+		if shadowCollector.Pending() {
+			h.rl.LogAsync(logSummary)
+			return
+		}
+		h.rl.Log(logSummary)
 	}()
 
+	// Serve the response from the response cache, if available
+	cacheKey, cachedResp := h.GetCachedResponse(req.Header, requestBody)
+	if cachedResp != nil {
+		key := resultlog.ResultLogKeys.Router
+		if h.IsEnsemblerEnabled() {
+			key = resultlog.ResultLogKeys.Ensembler
+		}
+		h.rl.SendCachedResponseToLogChannel(respCh, key, cachedResp)
+		trace.SetCacheHit()
+		if h.IsEnsemblerEnabled() {
+			trace.SetEnsembler(debugComponent(cachedResp, nil))
+		} else {
+			trace.SetRouter(debugComponent(cachedResp, nil))
+		}
+		return cachedResp, nil
+	}
+
 	// Enrich
-	var resp mchttp.Response
 	// Creates a new map to represent the merged headers from the original request headers + enricher response headers
+	// (and the response headers of the preprocessing stages)
 	postEnrichmentResponseHeader := req.Header.Clone()
 
 	payload := requestBody
@@ -97,6 +156,7 @@ func (h *httpHandler) getPrediction(
 		resp, httpErr := h.Enrich(ctx, req.Header, payload)
 		// Send enricher response/error for logging
 		h.rl.SendResponseToLogChannel(ctx, respCh, resultlog.ResultLogKeys.Enricher, resp, httpErr)
+		trace.SetEnricher(debugComponent(resp, httpErr))
 		// Check error
 		if httpErr != nil {
 			return nil, httpErr
@@ -110,9 +170,17 @@ func (h *httpHandler) getPrediction(
 		enricherResponse = payload
 	}
 
+	// Preprocess
+	resp, httpErr := h.runStages(ctx, respCh, preStages, postEnrichmentResponseHeader,
+		mchttp.NewCachedResponse(payload, nil))
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	payload = resp.Body()
+
 	// Route
 	var expResp *experiment.Response
-	expResp, resp, httpErr := h.Route(ctx, postEnrichmentResponseHeader, payload)
+	expResp, resp, httpErr = h.Route(ctx, postEnrichmentResponseHeader, payload)
 	if expResp != nil {
 		var expErr *errors.TuringError
 		if expResp.Error != "" {
@@ -120,11 +188,17 @@ func (h *httpHandler) getPrediction(
 		}
 		if expResp.Configuration != nil || expErr != nil {
 			h.rl.SendResponseToLogChannel(ctx, respCh, resultlog.ResultLogKeys.Experiment, expResp, expErr)
+			trace.SetExperiment(debugComponent(expResp, expErr))
 		}
 	}
 	h.rl.SendResponseToLogChannel(ctx, respCh, resultlog.ResultLogKeys.Router, resp, httpErr)
+	trace.SetRouter(debugComponent(resp, httpErr))
 	if httpErr != nil {
-		return nil, httpErr
+		reason := fallback.RouterReason
+		if expResp != nil && expResp.Error != "" {
+			reason = fallback.ExperimentReason
+		}
+		return h.fallbackResponse(ctx, selection.TrafficRule(), reason, httpErr)
 	}
 	payload = resp.Body()
 
@@ -132,9 +206,68 @@ func (h *httpHandler) getPrediction(
 	if h.IsEnsemblerEnabled() {
 		resp, httpErr = h.Ensemble(ctx, postEnrichmentResponseHeader, requestBody, payload, enricherResponse)
 		h.rl.SendResponseToLogChannel(ctx, respCh, resultlog.ResultLogKeys.Ensembler, resp, httpErr)
+		trace.SetEnsembler(debugComponent(resp, httpErr))
 		if httpErr != nil {
-			return nil, httpErr
+			return h.fallbackResponse(ctx, selection.TrafficRule(), fallback.EnsemblerReason, httpErr)
+		}
+	}
+
+	// Postprocess
+	resp, httpErr = h.runStages(ctx, respCh, postStages, postEnrichmentResponseHeader, resp)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	h.CacheResponse(cacheKey, resp)
+	return resp, nil
+}
+
+// fallbackResponse returns the fallback response of the given traffic rule, that is served in place of
+// the given error of the router, the experiment engine or the ensembler. The error is returned, if there
+// is no fallback response to serve.
+func (h *httpHandler) fallbackResponse(
+	ctx context.Context,
+	trafficRule string,
+	reason fallback.Reason,
+	httpErr *errors.TuringError,
+) (mchttp.Response, *errors.TuringError) {
+	resp := h.GetFallbackResponse(trafficRule, reason)
+	if resp == nil {
+		return nil, httpErr
+	}
+	log.WithContext(ctx).Warnf("Serving the fallback response in place of the %s error: %s", reason, httpErr.Message)
+	return resp, nil
+}
+
+// runStages runs the given processing stages in order, each one receiving the payload returned by the
+// previous one, and returns the output of the last stage. The response headers of the stages are merged
+// into the given request header. The failure of a stage is handled according to its error policy.
+func (h *httpHandler) runStages(
+	ctx context.Context,
+	respCh chan<- resultlog.RouterResponse,
+	stages router.ProcessingStages,
+	header http.Header,
+	resp mchttp.Response,
+) (mchttp.Response, *errors.TuringError) {
+	trace, _ := debug.GetTrace(ctx)
+	for _, stage := range stages {
+		stageResp, httpErr := h.Process(ctx, stage, header, resp.Body())
+		// Send stage response/error for logging
+		h.rl.SendResponseToLogChannel(ctx, respCh, stage.LogKey(), stageResp, httpErr)
+		trace.AddStage(stage.Name, debugComponent(stageResp, httpErr))
+		if httpErr != nil {
+			switch stage.ErrorPolicy {
+			case router.SkipErrorPolicy:
+				continue
+			case router.UsePreviousPayloadErrorPolicy:
+				return resp, nil
+			default:
+				return nil, httpErr
+			}
+		}
+		for key := range stageResp.Header() {
+			header.Set(key, stageResp.Header().Get(key))
 		}
+		resp = stageResp
 	}
 	return resp, nil
 }
@@ -186,7 +319,18 @@ func (h *httpHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// Collect the debug trace of the request, if requested by the client
+	var trace *debug.Trace
+	if h.debugEnabled && debug.IsRequested(req.Header.Get(debug.HeaderKey)) {
+		trace = debug.NewTrace(turingReqID, debug.EncodeJSONResponse)
+		ctx = debug.WithTrace(ctx, trace)
+	}
+
 	resp, httpErr := h.getPrediction(ctx, req, ctxLogger, requestBody)
+	if trace != nil {
+		h.debugResponse(ctx, rw, resp, httpErr, trace)
+		return
+	}
 	if httpErr != nil {
 		h.error(ctx, rw, httpErr)
 		return
@@ -198,10 +342,63 @@ func (h *httpHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set(key, resp.Header().Get(key))
 	}
 	rw.Header().Set(constant.TuringReqIDHeaderKey, turingReqID)
-	rw.WriteHeader(http.StatusOK)
+	rw.WriteHeader(responseStatus(resp))
 	contentLength, err := rw.Write(payload)
 	if err != nil {
 		ctxLogger.Errorf("Error occurred when copying content: %v", err.Error())
 	}
 	ctxLogger.Debugf("Written %d bytes", contentLength)
 }
+
+// debugResponse writes the debug envelope, that holds the response / error of the router, together with
+// the debug trace of the request
+func (h *httpHandler) debugResponse(
+	ctx context.Context,
+	rw http.ResponseWriter,
+	resp mchttp.Response,
+	httpErr *errors.TuringError,
+	trace *debug.Trace,
+) {
+	// Wait for the calls to the routes, that may still be in progress, so that the trace is complete
+	envelope := debug.Envelope{Debug: trace.Wait()}
+	var status int
+	if httpErr != nil {
+		h.rl.LogTuringRouterRequestError(ctx, httpErr)
+		envelope.Error, status = httpErr.Message, httpErr.Code
+	} else {
+		envelope.Response, status = debug.JSONPayload(resp.Body()), responseStatus(resp)
+		if _, ok := resp.(*fallback.Response); ok {
+			rw.Header().Set(fallback.HeaderKey, "true")
+		}
+	}
+
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		h.error(ctx, rw, errors.NewTuringError(err, fiberProtocol.HTTP))
+		return
+	}
+	turingReqID, _ := turingctx.GetRequestID(ctx)
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set(constant.TuringReqIDHeaderKey, turingReqID)
+	rw.WriteHeader(status)
+	if _, err := rw.Write(payload); err != nil {
+		log.WithContext(ctx).Errorf("Error occurred when copying content: %v", err.Error())
+	}
+}
+
+// responseStatus returns the HTTP status of the given response, which is 200 OK unless it is
+// a fallback response, that is configured with another status
+func responseStatus(resp mchttp.Response) int {
+	if fallbackResp, ok := resp.(*fallback.Response); ok {
+		return fallbackResp.Status
+	}
+	return http.StatusOK
+}
+
+// debugComponent converts the response / error of a Turing component, to be recorded in the debug trace
+func debugComponent(resp mchttp.Response, err *errors.TuringError) *debug.Component {
+	if err != nil {
+		return &debug.Component{Error: err.Message}
+	}
+	return &debug.Component{Response: debug.JSONPayload(resp.Body())}
+}
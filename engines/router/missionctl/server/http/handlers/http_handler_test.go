@@ -8,18 +8,22 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/caraml-dev/turing/engines/router"
 	"github.com/caraml-dev/turing/engines/router/missionctl"
 	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
 	"github.com/caraml-dev/turing/engines/router/missionctl/experiment"
+	"github.com/caraml-dev/turing/engines/router/missionctl/fallback"
 	tu "github.com/caraml-dev/turing/engines/router/missionctl/internal/testutils"
 	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog"
 	mchttp "github.com/caraml-dev/turing/engines/router/missionctl/server/http"
 
+	"github.com/gojek/fiber"
 	fiberProtocol "github.com/gojek/fiber/protocol"
 )
 
@@ -39,6 +43,9 @@ type testSuiteHTTPHandler struct {
 // BaseMockMissionControl is a mock implementation for the missionctl.MissionControl interface
 type BaseMockMissionControl struct {
 	mock.Mock
+	cache    map[string]mchttp.Response
+	stages   router.ProcessingStages
+	fallback *fallback.Response
 }
 
 // IsEnricherEnabled always returns true
@@ -84,6 +91,47 @@ func (mc *BaseMockMissionControl) Ensemble(
 	return modifyRequestBody(routerResponse, map[string]string{}, "Ensemble")
 }
 
+// Process appends ":<stage name>" to the value in the json payload, or returns an error
+// if the stage endpoint is "bad"
+func (mc *BaseMockMissionControl) Process(
+	_ context.Context,
+	stage *router.ProcessingStage,
+	_ http.Header,
+	body []byte,
+) (mchttp.Response, *errors.TuringError) {
+	mc.Called(stage.Name)
+	if stage.Endpoint == "bad" {
+		return nil, errors.NewTuringError(fmt.Errorf("Bad %s Called", stage.Name), fiberProtocol.HTTP)
+	}
+	return modifyRequestBody(body, map[string]string{}, stage.Name)
+}
+
+// GetProcessingStages returns the configured stages of the given phase
+func (mc *BaseMockMissionControl) GetProcessingStages(phase router.StagePhase) router.ProcessingStages {
+	return mc.stages.Phase(phase)
+}
+
+// GetCachedResponse returns the response cached for the request body, if any
+func (mc *BaseMockMissionControl) GetCachedResponse(_ http.Header, body []byte) (string, mchttp.Response) {
+	return string(body), mc.cache[string(body)]
+}
+
+// CacheResponse caches the response by the request body
+func (mc *BaseMockMissionControl) CacheResponse(key string, resp mchttp.Response) {
+	if mc.cache == nil {
+		mc.cache = map[string]mchttp.Response{}
+	}
+	mc.cache[key] = resp
+}
+
+// GetFallbackResponse returns the configured fallback response, if any
+func (mc *BaseMockMissionControl) GetFallbackResponse(string, fallback.Reason) *fallback.Response {
+	return mc.fallback
+}
+
+// SetFiberRouter is not used by the handlers
+func (mc *BaseMockMissionControl) SetFiberRouter(fiber.Component) {}
+
 // MockMissionControl simply inherits from BaseMockMissionControl
 type MockMissionControl struct {
 	BaseMockMissionControl
@@ -170,6 +218,27 @@ func TestHTTPService(t *testing.T) {
 			"Turing-Req-Id": []string{rr.Header().Get("Turing-Req-Id")}})
 }
 
+// TestHTTPServiceCachedResponse tests that the cached response is served without calling the components
+func TestHTTPServiceCachedResponse(t *testing.T) {
+	expectedResponse := string(`{"value": "Init:Enrich:Route:Ensemble"}`)
+	requestPayload, err := json.Marshal(testBody{Value: "Init"})
+	tu.FailOnError(t, err)
+
+	mc := &MockMissionControl{BaseMockMissionControl: *createTestBaseMissionControl()}
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		doTestRequest(mc, createTestRequest(requestPayload, t), rr)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, expectedResponse, rr.Body.String(), "Response body mismatch.")
+	}
+
+	// The second response is served from the cache
+	mc.AssertNumberOfCalls(t, "Enrich", 1)
+	mc.AssertNumberOfCalls(t, "Route", 1)
+	mc.AssertNumberOfCalls(t, "Ensemble", 1)
+}
+
 // TestHTTPServiceBadRequest tests for a HTTP InternalServerError on bad
 // Enrich / Route / Ensemble, with unclassified.
 func TestHTTPServiceBadRequest(t *testing.T) {
@@ -234,11 +303,211 @@ func TestHTTPServiceBadRequest(t *testing.T) {
 	}
 }
 
+// TestHTTPServiceFallbackResponse tests that the fallback response is served in place of the errors
+// of the router and the ensembler, but not of the other components
+func TestHTTPServiceFallbackResponse(t *testing.T) {
+	tests := map[string]struct {
+		mc               missionctl.MissionControl
+		expectedStatus   int
+		expectedResponse string
+		expectedFallback bool
+	}{
+		"bad route": {
+			mc:               &MockMissionControlBadRoute{BaseMockMissionControl: *createTestFallbackMissionControl()},
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedResponse: `{"value": "Fallback"}`,
+			expectedFallback: true,
+		},
+		"bad ensemble": {
+			mc:               &MockMissionControlBadEnsemble{BaseMockMissionControl: *createTestFallbackMissionControl()},
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedResponse: `{"value": "Fallback"}`,
+			expectedFallback: true,
+		},
+		"bad enrich": {
+			mc:               &MockMissionControlBadEnrich{BaseMockMissionControl: *createTestFallbackMissionControl()},
+			expectedStatus:   http.StatusInternalServerError,
+			expectedResponse: "Bad Enrich Called\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			doTestRequest(tt.mc, createTestRequest([]byte(`{"value": "Init"}`), t), rr)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedFallback {
+				assert.JSONEq(t, tt.expectedResponse, rr.Body.String())
+				assert.Equal(t, "true", rr.Header().Get("Turing-Fallback"))
+				assert.NotEmpty(t, rr.Header().Get("Turing-Req-Id"))
+			} else {
+				assert.Equal(t, tt.expectedResponse, rr.Body.String())
+				assert.Empty(t, rr.Header().Get("Turing-Fallback"))
+			}
+		})
+	}
+}
+
+// TestHTTPServiceDebugResponse tests that the debug envelope is returned, only if requested by the client
+// and enabled for the router
+func TestHTTPServiceDebugResponse(t *testing.T) {
+	tests := map[string]struct {
+		mc               missionctl.MissionControl
+		debugEnabled     bool
+		debugHeader      string
+		expectedStatus   int
+		expectedResponse string
+	}{
+		"success | debug requested": {
+			mc:             &MockMissionControl{BaseMockMissionControl: *createTestBaseMissionControl()},
+			debugEnabled:   true,
+			debugHeader:    "true",
+			expectedStatus: http.StatusOK,
+			expectedResponse: `{
+				"response": {"value": "Init:Enrich:Route:Ensemble"},
+				"debug": {
+					"turing_req_id": "%s",
+					"enricher": {"response": {"value": "Init:Enrich"}},
+					"routes": [],
+					"router": {"response": {"value": "Init:Enrich:Route"}},
+					"ensembler": {"response": {"value": "Init:Enrich:Route:Ensemble"}}
+				}
+			}`,
+		},
+		"failure | debug requested": {
+			mc:             &MockMissionControlBadRoute{BaseMockMissionControl: *createTestBaseMissionControl()},
+			debugEnabled:   true,
+			debugHeader:    "true",
+			expectedStatus: http.StatusInternalServerError,
+			expectedResponse: `{
+				"error": "Bad Route Called",
+				"debug": {
+					"turing_req_id": "%s",
+					"enricher": {"response": {"value": "Init:Enrich"}},
+					"routes": [],
+					"router": {"error": "Bad Route Called"}
+				}
+			}`,
+		},
+		"success | debug not requested": {
+			mc:               &MockMissionControl{BaseMockMissionControl: *createTestBaseMissionControl()},
+			debugEnabled:     true,
+			debugHeader:      "false",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"value": "Init:Enrich:Route:Ensemble"}`,
+		},
+		"success | debug not enabled": {
+			mc:               &MockMissionControl{BaseMockMissionControl: *createTestBaseMissionControl()},
+			debugHeader:      "true",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"value": "Init:Enrich:Route:Ensemble"}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := createTestRequest([]byte(`{"value": "Init"}`), t)
+			req.Header.Set("Turing-Debug", tt.debugHeader)
+			rr := httptest.NewRecorder()
+
+			resultLogger := resultlog.InitTuringResultLogger("", resultlog.NewNopLogger(), nil)
+			handler := NewHTTPHandler(tt.mc, resultLogger, tt.debugEnabled)
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			expectedResponse := tt.expectedResponse
+			if strings.Contains(expectedResponse, "%s") {
+				expectedResponse = fmt.Sprintf(expectedResponse, rr.Header().Get("Turing-Req-Id"))
+			}
+			assert.JSONEq(t, expectedResponse, rr.Body.String())
+		})
+	}
+}
+
+// TestHTTPServiceProcessingStages tests that the processing stages run in order, and that their
+// failures are handled according to their error policies
+func TestHTTPServiceProcessingStages(t *testing.T) {
+	tests := map[string]struct {
+		stages           router.ProcessingStages
+		expectedStatus   int
+		expectedResponse string
+		notCalledStages  []string
+	}{
+		"success": {
+			stages: router.ProcessingStages{
+				{Name: "Calibrate", Phase: router.PostprocessingPhase},
+				{Name: "Check", Phase: router.PreprocessingPhase},
+				{Name: "Rules", Phase: router.PostprocessingPhase},
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"value": "Init:Enrich:Check:Route:Ensemble:Calibrate:Rules"}`,
+		},
+		"skip error policy": {
+			stages: router.ProcessingStages{
+				{Name: "Check", Phase: router.PreprocessingPhase, Endpoint: "bad", ErrorPolicy: router.SkipErrorPolicy},
+				{Name: "Features", Phase: router.PreprocessingPhase},
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"value": "Init:Enrich:Features:Route:Ensemble"}`,
+		},
+		"use previous payload error policy": {
+			stages: router.ProcessingStages{
+				{Name: "Calibrate", Phase: router.PostprocessingPhase},
+				{
+					Name:        "Rules",
+					Phase:       router.PostprocessingPhase,
+					Endpoint:    "bad",
+					ErrorPolicy: router.UsePreviousPayloadErrorPolicy,
+				},
+				{Name: "Format", Phase: router.PostprocessingPhase},
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"value": "Init:Enrich:Route:Ensemble:Calibrate"}`,
+			notCalledStages:  []string{"Format"},
+		},
+		"fail error policy": {
+			stages: router.ProcessingStages{
+				{Name: "Check", Phase: router.PreprocessingPhase, Endpoint: "bad"},
+			},
+			expectedStatus:   http.StatusInternalServerError,
+			expectedResponse: "Bad Check Called\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mc := &MockMissionControl{BaseMockMissionControl: *createTestBaseMissionControl()}
+			mc.stages = tt.stages
+			rr := httptest.NewRecorder()
+			doTestRequest(mc, createTestRequest([]byte(`{"value": "Init"}`), t), rr)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.JSONEq(t, tt.expectedResponse, rr.Body.String())
+			} else {
+				assert.Equal(t, tt.expectedResponse, rr.Body.String())
+			}
+			for _, stage := range tt.notCalledStages {
+				mc.AssertNotCalled(t, "Process", stage)
+			}
+		})
+	}
+}
+
 func createTestBaseMissionControl() *BaseMockMissionControl {
 	mc := &BaseMockMissionControl{}
 	mc.On("Enrich").Return(nil)
 	mc.On("Route").Return(nil)
 	mc.On("Ensemble", mock.Anything).Return(nil)
+	mc.On("Process", mock.Anything).Return(nil)
+	return mc
+}
+
+// createTestFallbackMissionControl creates a mock mission control, that serves a fallback response
+func createTestFallbackMissionControl() *BaseMockMissionControl {
+	mc := createTestBaseMissionControl()
+	mc.fallback = &fallback.Response{Status: http.StatusServiceUnavailable, Payload: []byte(`{"value": "Fallback"}`)}
 	return mc
 }
 
@@ -249,7 +518,7 @@ func createTestRequest(payload []byte, t *testing.T) *http.Request {
 }
 
 func doTestRequest(mc missionctl.MissionControl, req *http.Request, rr *httptest.ResponseRecorder) {
-	handler := NewHTTPHandler(mc, resultlog.InitTuringResultLogger("", resultlog.NewNopLogger()))
+	handler := NewHTTPHandler(mc, resultlog.InitTuringResultLogger("", resultlog.NewNopLogger(), nil), false)
 	http.HandlerFunc(handler.ServeHTTP).ServeHTTP(rr, req)
 }
 
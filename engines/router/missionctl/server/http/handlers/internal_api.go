@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -28,24 +29,39 @@ func versionAPI(w http.ResponseWriter, _ *http.Request) {
 // These service URLs will be checked, to ensure they are resolvable, as part of the
 // readiness check. This is because sometimes it can take several seconds for the URL host to
 // be resolvable.
-func NewInternalAPIHandler(serviceURLs []string) http.Handler {
+//
+// The readiness check also fails once the given shuttingDown channel is closed, so that no new
+// traffic is sent to the router while it drains the in-flight requests. A nil channel is never closed.
+func NewInternalAPIHandler(serviceURLs []string, shuttingDown <-chan struct{}) http.Handler {
 	h := http.NewServeMux()
 
-	h.Handle("/", newHealthcheckHandler(serviceURLs))
+	h.Handle("/", newHealthcheckHandler(serviceURLs, shuttingDown))
 	h.HandleFunc("/version", versionAPI)
 
 	return h
 }
 
-func newHealthcheckHandler(serviceURLs []string) healthcheck.Handler {
+func newHealthcheckHandler(serviceURLs []string, shuttingDown <-chan struct{}) healthcheck.Handler {
 	health := healthcheck.NewHandler()
 	for i, serviceURL := range serviceURLs {
 		checkName := fmt.Sprintf("url-resolvable-%d", i)
 		health.AddReadinessCheck(checkName, checkURLResolvable(serviceURL))
 	}
+	health.AddReadinessCheck("not-shutting-down", checkNotShuttingDown(shuttingDown))
 	return health
 }
 
+func checkNotShuttingDown(shuttingDown <-chan struct{}) healthcheck.Check {
+	return func() error {
+		select {
+		case <-shuttingDown:
+			return errors.New("router is shutting down")
+		default:
+			return nil
+		}
+	}
+}
+
 var defaultTimeoutForDNSLookup = 100 * time.Millisecond
 
 func checkURLResolvable(rawURL string) healthcheck.Check {
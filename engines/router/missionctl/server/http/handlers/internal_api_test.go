@@ -26,7 +26,7 @@ func TestVersionAPI(t *testing.T) {
 		internal.VersionInfo = currentVersionInfo
 	}()
 
-	handler := NewInternalAPIHandler(nil)
+	handler := NewInternalAPIHandler(nil, nil)
 	// Request the version API
 	req, err := http.NewRequest(http.MethodGet, "/version", nil)
 	tu.FailOnError(t, err)
@@ -48,8 +48,9 @@ func TestVersionAPI(t *testing.T) {
 
 func TestNewHealthcheckHandler(t *testing.T) {
 	tests := map[string]struct {
-		serviceURLs []string
-		wantCode    int
+		serviceURLs  []string
+		shuttingDown bool
+		wantCode     int
 	}{
 		"Nil seviceURLs": {
 			serviceURLs: nil,
@@ -79,10 +80,19 @@ func TestNewHealthcheckHandler(t *testing.T) {
 			serviceURLs: []string{"invalid-url"},
 			wantCode:    http.StatusServiceUnavailable,
 		},
+		"Shutting down": {
+			serviceURLs:  []string{""},
+			shuttingDown: true,
+			wantCode:     http.StatusServiceUnavailable,
+		},
 	}
 	for testName, tt := range tests {
 		t.Run(testName, func(t *testing.T) {
-			handler := newHealthcheckHandler(tt.serviceURLs).(http.Handler)
+			shuttingDown := make(chan struct{})
+			if tt.shuttingDown {
+				close(shuttingDown)
+			}
+			handler := newHealthcheckHandler(tt.serviceURLs, shuttingDown).(http.Handler)
 			req, err := http.NewRequest("GET", "/ready", nil)
 			if err != nil {
 				t.Fatal(err)
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/ratelimit"
+)
+
+// NewRateLimitMiddleware creates a handler that admits the request with the given limiter, if any, before
+// passing it on to the next handler. The requests over the client's limits are rejected with the 429 status,
+// and the Retry-After header that hints when the client may retry.
+func NewRateLimitMiddleware(limiter *ratelimit.Limiter, next http.Handler) http.Handler {
+	if limiter == nil {
+		return next
+	}
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var body []byte
+		if limiter.NeedsPayload() {
+			var err error
+			if body, err = io.ReadAll(req.Body); err != nil {
+				http.Error(rw, "Failed to read the request body", http.StatusBadRequest)
+				return
+			}
+			// The payload is read again by the next handler
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		release, rejection := limiter.Admit(limiter.HTTPRequestKey(req.Header, body))
+		if rejection != nil {
+			rw.Header().Set("Retry-After", strconv.Itoa(rejection.RetryAfterSeconds()))
+			http.Error(rw, rejection.Error(), http.StatusTooManyRequests)
+			return
+		}
+		defer release()
+		next.ServeHTTP(rw, req)
+	})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/ratelimit"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	limiter := ratelimit.NewLimiter(&config.RateLimitConfig{
+		FieldSource: request.PayloadFieldSource,
+		Field:       "client_id",
+		MaxInFlight: 1,
+		MaxKeys:     10,
+	})
+
+	// The next handler of the first request sends a request of the same client, while it is in flight
+	var receivedBody, nestedBody string
+	var nestedResp *httptest.ResponseRecorder
+	var handler http.Handler
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		if receivedBody == "" {
+			receivedBody = string(body)
+			nestedResp = httptest.NewRecorder()
+			handler.ServeHTTP(nestedResp, httptest.NewRequest(http.MethodPost, "/v1/predict",
+				strings.NewReader(`{"client_id": "client-a"}`)))
+
+			// The requests of the other clients are admitted
+			otherResp := httptest.NewRecorder()
+			handler.ServeHTTP(otherResp, httptest.NewRequest(http.MethodPost, "/v1/predict",
+				strings.NewReader(`{"client_id": "client-b"}`)))
+			nestedBody = otherResp.Body.String()
+		}
+		_, _ = rw.Write(body)
+	})
+	handler = NewRateLimitMiddleware(limiter, next)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/predict",
+		strings.NewReader(`{"client_id": "client-a"}`)))
+
+	// The payload is passed on to the next handler
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"client_id": "client-a"}`, receivedBody)
+	assert.Equal(t, `{"client_id": "client-a"}`, rr.Body.String())
+	assert.Equal(t, `{"client_id": "client-b"}`, nestedBody)
+
+	assert.Equal(t, http.StatusTooManyRequests, nestedResp.Code)
+	assert.Equal(t, "1", nestedResp.Header().Get("Retry-After"))
+	assert.Equal(t, "Too many in-flight requests\n", nestedResp.Body.String())
+
+	// The request is admitted again, after the in-flight request completes
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/predict",
+		strings.NewReader(`{"client_id": "client-a"}`)))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
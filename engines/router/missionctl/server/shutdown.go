@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/log"
+)
+
+// shutdownFunc stops a server from accepting new requests, and waits for its in-flight requests
+// to complete, until the context is done
+type shutdownFunc func(ctx context.Context) error
+
+// resultLogCloser is implemented by the result loggers, which flush the pending result logs when closed
+type resultLogCloser interface {
+	Close(ctx context.Context) error
+}
+
+// serve runs the given server, and sends its error to the given channel if it fails. The error returned
+// by a server once it's shut down is ignored.
+func serve(errCh chan<- error, name string, serveFn func() error) {
+	if err := serveFn(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		errCh <- fmt.Errorf("failed to serve %s: %w", name, err)
+	}
+}
+
+// waitForShutdown blocks until the router receives a termination signal, or one of its servers fails
+func waitForShutdown(serveErrCh <-chan error) {
+	stopCh := make(chan os.Signal, 1)
+	signal.Notify(stopCh, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(stopCh)
+
+	select {
+	case err := <-serveErrCh:
+		log.Glob().Errorf("Shutting down the router: %s", err)
+	case sig := <-stopCh:
+		log.Glob().Infof("Received signal %s, shutting down the router", sig)
+	}
+}
+
+// shutdown stops the router gracefully, within the given grace period. The readiness checks start
+// failing, and after the drain delay, so that the load balancers stop sending new requests to the
+// router, the servers stop accepting new requests and drain the in-flight ones. Finally, the result
+// logs of the drained requests are flushed.
+func shutdown(
+	drainDelay time.Duration,
+	gracePeriod time.Duration,
+	shuttingDown chan<- struct{},
+	resultLogger resultLogCloser,
+	servers ...shutdownFunc,
+) {
+	close(shuttingDown)
+	if drainDelay > 0 {
+		log.Glob().Infof("Waiting %s for the load balancers to stop sending new requests", drainDelay)
+		time.Sleep(drainDelay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, shutdownServer := range servers {
+		wg.Add(1)
+		go func(shutdownServer shutdownFunc) {
+			defer wg.Done()
+			if err := shutdownServer(ctx); err != nil {
+				log.Glob().Errorf("Failed to drain the in-flight requests: %s", err)
+			}
+		}(shutdownServer)
+	}
+	wg.Wait()
+
+	if err := resultLogger.Close(ctx); err != nil {
+		log.Glob().Errorf("Failed to flush the result logs: %s", err)
+	}
+	log.Glob().Info("Router shut down")
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockResultLogCloser struct {
+	closed bool
+}
+
+func (c *mockResultLogCloser) Close(ctx context.Context) error {
+	c.closed = ctx.Err() == nil
+	return nil
+}
+
+func TestServe(t *testing.T) {
+	tests := map[string]struct {
+		serveErr error
+		expected string
+	}{
+		"server shut down": {
+			serveErr: http.ErrServerClosed,
+		},
+		"server failed": {
+			serveErr: errors.New("address already in use"),
+			expected: "failed to serve test server: address already in use",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			errCh := make(chan error, 1)
+			serve(errCh, "test server", func() error { return tt.serveErr })
+			close(errCh)
+
+			err := <-errCh
+			if tt.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	shuttingDown := make(chan struct{})
+	resultLogger := &mockResultLogCloser{}
+
+	var drained []string
+	var startTime time.Time
+	drain := func(name string) shutdownFunc {
+		return func(ctx context.Context) error {
+			// The servers are shut down the drain delay after the readiness checks fail
+			select {
+			case <-shuttingDown:
+				assert.GreaterOrEqual(t, time.Since(startTime), 50*time.Millisecond)
+			default:
+				t.Errorf("%s was shut down before the readiness checks failed", name)
+			}
+			assert.False(t, resultLogger.closed, "result logger was closed before draining the requests")
+			drained = append(drained, name)
+			return nil
+		}
+	}
+
+	startTime = time.Now()
+	shutdown(50*time.Millisecond, time.Second, shuttingDown, resultLogger, drain("server"))
+
+	assert.Equal(t, []string{"server"}, drained)
+	assert.True(t, resultLogger.closed)
+}
@@ -0,0 +1,52 @@
+package interceptors
+
+import (
+	"context"
+
+	fiberProtocol "github.com/gojek/fiber/protocol"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router/missionctl/auth"
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+)
+
+// AuthInterceptor authenticates the caller with the given authenticator, if any, before handling the
+// request. The identity of the authenticated caller is passed on to the Turing components in the caller
+// metadata key, which is always removed from the incoming metadata, so that it cannot be spoofed by the
+// client. The credentials of the caller, i.e. the API key and the JWT, are not passed on.
+func AuthInterceptor(authenticator *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context,
+		req interface{},
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		md = md.Copy()
+		md.Delete(request.CallerHeader)
+
+		if authenticator != nil {
+			caller, err := authenticator.Authenticate(
+				firstValue(md, auth.APIKeyHeader),
+				firstValue(md, auth.AuthorizationHeader),
+			)
+			if err != nil {
+				turingErr := errors.NewTuringError(err, fiberProtocol.GRPC)
+				return nil, status.Error(codes.Code(turingErr.Code), turingErr.Message)
+			}
+			md.Delete(auth.APIKeyHeader)
+			md.Delete(auth.AuthorizationHeader)
+			md.Set(request.CallerHeader, caller.Encode())
+		}
+		return handler(metadata.NewIncomingContext(ctx, md), req)
+	}
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package interceptors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/auth"
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+)
+
+func TestAuthInterceptor(t *testing.T) {
+	apiKeysFile := filepath.Join(t.TempDir(), "api-keys.json")
+	require.NoError(t, os.WriteFile(apiKeysFile, []byte(`{"team-a": "key-a"}`), 0600))
+	authenticator, err := auth.NewAuthenticator(&config.AuthConfig{APIKeysFile: apiKeysFile})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		authenticator *auth.Authenticator
+		md            metadata.MD
+		expectedMD    metadata.MD
+		expectedErr   error
+	}{
+		"success | authenticated": {
+			authenticator: authenticator,
+			md: metadata.Pairs(
+				"x-api-key", "key-a",
+				"authorization", "Bearer token",
+				"turing-caller", `{"id":"spoofed"}`,
+				"key", "value",
+			),
+			expectedMD: metadata.Pairs(
+				"turing-caller", `{"id":"team-a","method":"api_key"}`,
+				"key", "value",
+			),
+		},
+		"success | authentication disabled": {
+			md:         metadata.Pairs("x-api-key", "key-a", "turing-caller", `{"id":"spoofed"}`),
+			expectedMD: metadata.Pairs("x-api-key", "key-a"),
+		},
+		"failure | invalid api key": {
+			authenticator: authenticator,
+			md:            metadata.Pairs("x-api-key", "key-b"),
+			expectedErr:   status.Error(codes.Unauthenticated, "Invalid API key"),
+		},
+		"failure | missing credentials": {
+			authenticator: authenticator,
+			expectedErr:   status.Error(codes.Unauthenticated, "Missing credentials"),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tt.md)
+			}
+
+			var receivedMD metadata.MD
+			_, err := AuthInterceptor(tt.authenticator)(
+				ctx,
+				&upiv1.PredictValuesRequest{},
+				nil,
+				func(ctx context.Context, _ interface{}) (interface{}, error) {
+					receivedMD, _ = metadata.FromIncomingContext(ctx)
+					return &upiv1.PredictValuesResponse{}, nil
+				})
+
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedMD, receivedMD)
+		})
+	}
+}
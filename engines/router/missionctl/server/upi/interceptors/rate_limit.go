@@ -0,0 +1,47 @@
+package interceptors
+
+import (
+	"context"
+
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/ratelimit"
+)
+
+// RateLimitInterceptor admits the request with the given limiter, if any, before handling it. The requests
+// over the client's limits are rejected with the RESOURCE_EXHAUSTED status, and the RetryInfo details that
+// hint when the client may retry.
+func RateLimitInterceptor(limiter *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context,
+		req interface{},
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		if limiter == nil {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		upiReq, ok := req.(*upiv1.PredictValuesRequest)
+		if !ok {
+			upiReq = &upiv1.PredictValuesRequest{}
+		}
+		release, rejection := limiter.Admit(limiter.UPIRequestKey(md, upiReq))
+		if rejection != nil {
+			st := status.New(codes.ResourceExhausted, rejection.Error())
+			if detailed, err := st.WithDetails(&errdetails.RetryInfo{
+				RetryDelay: durationpb.New(rejection.RetryAfter),
+			}); err == nil {
+				st = detailed
+			}
+			return nil, st.Err()
+		}
+		defer release()
+		return handler(ctx, req)
+	}
+}
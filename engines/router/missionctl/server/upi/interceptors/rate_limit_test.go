@@ -0,0 +1,56 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router/missionctl/config"
+	"github.com/caraml-dev/turing/engines/router/missionctl/ratelimit"
+)
+
+func TestRateLimitInterceptor(t *testing.T) {
+	limiter := ratelimit.NewLimiter(&config.RateLimitConfig{
+		FieldSource:    request.HeaderFieldSource,
+		Field:          "X-Client-ID",
+		RequestsPerSec: 1,
+		MaxKeys:        10,
+	})
+	interceptor := RateLimitInterceptor(limiter)
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		return &upiv1.PredictValuesResponse{}, nil
+	}
+	callWithClient := func(clientID string) error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-client-id", clientID))
+		_, err := interceptor(ctx, &upiv1.PredictValuesRequest{}, nil, handler)
+		return err
+	}
+
+	require.NoError(t, callWithClient("client-a"))
+	require.NoError(t, callWithClient("client-b"))
+
+	err := callWithClient("client-a")
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	assert.Equal(t, "Request rate limit exceeded", st.Message())
+	require.Len(t, st.Details(), 1)
+	retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	assert.Greater(t, retryInfo.RetryDelay.AsDuration(), time.Duration(0))
+	assert.LessOrEqual(t, retryInfo.RetryDelay.AsDuration(), time.Second)
+
+	// The requests are not limited, if the limiter is not set
+	resp, err := RateLimitInterceptor(nil)(context.Background(), &upiv1.PredictValuesRequest{}, nil, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, &upiv1.PredictValuesResponse{}, resp)
+}
@@ -2,10 +2,8 @@ package upi
 
 import (
 	"context"
+	"encoding/json"
 	"net"
-	"os"
-	"os/signal"
-	"syscall"
 
 	"github.com/opentracing/opentracing-go"
 	"google.golang.org/grpc"
@@ -13,23 +11,32 @@ import (
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/caraml-dev/mlp/api/pkg/instrumentation/metrics"
 	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"github.com/gojek/fiber"
 	fiberGrpc "github.com/gojek/fiber/grpc"
 	fiberProtocol "github.com/gojek/fiber/protocol"
 
+	"github.com/caraml-dev/turing/engines/router"
 	"github.com/caraml-dev/turing/engines/router/missionctl"
+	"github.com/caraml-dev/turing/engines/router/missionctl/auth"
+	"github.com/caraml-dev/turing/engines/router/missionctl/debug"
 	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
 	"github.com/caraml-dev/turing/engines/router/missionctl/experiment"
+	"github.com/caraml-dev/turing/engines/router/missionctl/fallback"
+	"github.com/caraml-dev/turing/engines/router/missionctl/hedging"
 	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation"
 	"github.com/caraml-dev/turing/engines/router/missionctl/instrumentation/tracing"
 	"github.com/caraml-dev/turing/engines/router/missionctl/log"
 	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog"
+	"github.com/caraml-dev/turing/engines/router/missionctl/ratelimit"
 	"github.com/caraml-dev/turing/engines/router/missionctl/server/constant"
 	"github.com/caraml-dev/turing/engines/router/missionctl/server/upi/interceptors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/timing"
 	"github.com/caraml-dev/turing/engines/router/missionctl/turingctx"
 )
 
@@ -40,43 +47,61 @@ type Server struct {
 
 	missionControl missionctl.MissionControlUPI
 	resultLogger   *resultlog.UPIResultLogger
+	grpcServer     *grpc.Server
+	debugEnabled   bool
 }
 
-func NewUPIServer(mc missionctl.MissionControlUPI, rl *resultlog.UPIResultLogger) *Server {
-	return &Server{
+// NewUPIServer creates the UPI server of the router. If debugEnabled is set, the clients can request
+// the debug trace of a request, with the debug request metadata, which is returned in the response header.
+// If the authenticator is set, the callers are authenticated before their requests are handled, and
+// if the limiter is set, the requests over the limits of their clients are rejected.
+func NewUPIServer(
+	mc missionctl.MissionControlUPI,
+	rl *resultlog.UPIResultLogger,
+	debugEnabled bool,
+	authenticator *auth.Authenticator,
+	limiter *ratelimit.Limiter,
+) *Server {
+	us := &Server{
 		missionControl: mc,
 		resultLogger:   rl,
+		grpcServer: grpc.NewServer(grpc.ChainUnaryInterceptor(
+			interceptors.PanicRecoveryInterceptor(),
+			interceptors.AuthInterceptor(authenticator),
+			interceptors.RateLimitInterceptor(limiter),
+		)),
+		debugEnabled: debugEnabled,
 	}
+	upiv1.RegisterUniversalPredictionServiceServer(us.grpcServer, us)
+	reflection.Register(us.grpcServer)
+	return us
 }
 
-func (us *Server) Run(listener net.Listener) {
-
-	s := grpc.NewServer(grpc.UnaryInterceptor(interceptors.PanicRecoveryInterceptor()))
-	upiv1.RegisterUniversalPredictionServiceServer(s, us)
-	reflection.Register(s)
-
-	errChan := make(chan error, 1)
-	stopChan := make(chan os.Signal, 1)
-
-	// bind OS events to the signal channel
-	signal.Notify(stopChan, syscall.SIGTERM, syscall.SIGINT)
+// Run serves the UPI requests on the given listener. It blocks until the server is shut down,
+// or fails to serve.
+func (us *Server) Run(listener net.Listener) error {
+	// The server may already have been shut down, before it started serving
+	if err := us.grpcServer.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+		log.Glob().Errorf("Failed to start Turing Mission Control API: %s", err)
+		return err
+	}
+	return nil
+}
 
+// Shutdown stops the server from accepting new requests, and waits for the in-flight requests
+// to complete. If the context is done first, the remaining requests are cancelled.
+func (us *Server) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
 	go func() {
-		if err := s.Serve(listener); err != nil {
-			errChan <- err
-		}
-	}()
-
-	defer func() {
-		s.GracefulStop()
+		us.grpcServer.GracefulStop()
+		close(stopped)
 	}()
-
-	// block until either OS signal, or server fatal error
 	select {
-	case err := <-errChan:
-		log.Glob().Errorf("Failed to start Turing Mission Control API: %s", err)
-	case <-stopChan:
-		log.Glob().Info("Signal to stop server")
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		us.grpcServer.Stop()
+		return ctx.Err()
 	}
 }
 
@@ -124,7 +149,17 @@ func (us *Server) PredictValues(ctx context.Context, req *upiv1.PredictValuesReq
 		}
 	}
 
+	// Collect the debug trace of the request, if requested by the client
+	var trace *debug.Trace
+	if us.debugEnabled && isDebugRequested(md) {
+		trace = debug.NewTrace(turingReqID, encodeUPIResponse)
+		ctx = debug.WithTrace(ctx, trace)
+	}
+
 	resp, predictionErr := us.getPrediction(ctx, req, md, turingReqID)
+	if trace != nil {
+		sendDebugTrace(ctx, trace)
+	}
 	if predictionErr != nil {
 		us.resultLogger.LogTuringRouterRequestError(ctx, predictionErr)
 		return nil, status.Error(codes.Code(predictionErr.Code), predictionErr.Message)
@@ -139,12 +174,83 @@ func (us *Server) getPrediction(
 	turingReqID string) (
 	*upiv1.PredictValuesResponse, *errors.TuringError) {
 
-	// Create response channel to store the response from each step. 1 for route now,
-	// should be 4 when experiment engine, enricher and ensembler are added
-	respCh := make(chan resultlog.GrpcRouterResponse, 1)
+	preStages := us.missionControl.GetProcessingStages(router.PreprocessingPhase)
+	postStages := us.missionControl.GetProcessingStages(router.PostprocessingPhase)
+	// Create response channel to store the response from each step. Allocate buffer size = 5 + the number of
+	// processing stages (max responses possible, from enricher, router, hedged requests, timings, ensembler
+	// and each processing stage respectively).
+	respCh := make(chan resultlog.GrpcRouterResponse, 5+len(preStages)+len(postStages))
 
 	req = populateRequestMetadata(req, turingReqID)
-	requestByte, err := proto.Marshal(req)
+	// Get the debug trace, if the request is in debug mode
+	trace, _ := debug.GetTrace(ctx)
+
+	// Collect the hedged requests to the routes, if any, for logging
+	hedgingCollector := hedging.NewCollector()
+	ctx = hedging.WithCollector(ctx, hedgingCollector)
+	// Collect the timings of the calls to the components and the routes, for logging
+	timingCollector := timing.NewCollector()
+	ctx = timing.WithCollector(ctx, timingCollector)
+	// Record the traffic rule, that the request matched, to pick its fallback response if the routes fail
+	selection := fallback.NewSelection()
+	ctx = fallback.WithSelection(ctx, selection)
+
+	// Defer logging req summary
+	defer us.resultLogger.Log(func() {
+		us.resultLogger.SendHedgedRequestsToLogChannel(respCh, hedgingCollector.Records())
+		us.resultLogger.SendTimingsToLogChannel(respCh, &resultlog.Timings{
+			Records:     timingCollector.Records(),
+			RouteID:     timingCollector.RouteID(),
+			TrafficRule: selection.TrafficRule(),
+		})
+		close(respCh)
+		us.resultLogger.LogTuringRouterRequestSummary(md, req, respCh)
+	})
+
+	// Serve the response from the response cache, if available
+	cacheKey, cachedResp := us.missionControl.GetCachedResponse(req, md)
+	if cachedResp != nil {
+		key := resultlog.ResultLogKeys.Router
+		if us.missionControl.IsEnsemblerEnabled() {
+			key = resultlog.ResultLogKeys.Ensembler
+		}
+		us.resultLogger.SendCachedResponseToLogChannel(respCh, key, cachedResp)
+		trace.SetCacheHit()
+		if us.missionControl.IsEnsemblerEnabled() {
+			trace.SetEnsembler(debugComponent(cachedResp, nil))
+		} else {
+			trace.SetRouter(debugComponent(cachedResp, nil))
+		}
+		return populateResponseMetadata(cachedResp, turingReqID, nil), nil
+	}
+
+	// Enrich
+	routerReq := req
+	if us.missionControl.IsEnricherEnabled() {
+		enricherResp, enricherMd, turingError := us.missionControl.Enrich(ctx, req, md)
+		// Send enricher response/error for logging
+		us.resultLogger.SendResponseToLogChannel(
+			respCh,
+			resultlog.ResultLogKeys.Enricher,
+			enricherMd,
+			enricherResp,
+			turingError)
+		trace.SetEnricher(debugComponent(enricherResp, turingError))
+		if turingError != nil {
+			return nil, turingError
+		}
+		routerReq = makeEnrichedRequest(req, enricherResp)
+		// Merge enricher response metadata with the original request metadata
+		md = metadata.Join(md, enricherMd)
+	}
+
+	// Preprocess
+	routerReq, md, turingError := us.runPreprocessingStages(ctx, respCh, preStages, routerReq, md)
+	if turingError != nil {
+		return nil, turingError
+	}
+
+	requestByte, err := proto.Marshal(routerReq)
 	if err != nil {
 		turingError := errors.NewTuringError(
 			errors.Newf(errors.BadInput, "unable to parse request into byte"), fiberProtocol.GRPC,
@@ -152,15 +258,7 @@ func (us *Server) getPrediction(
 		return nil, turingError
 	}
 
-	upiRequest := fiberGrpc.NewRequest(md, requestByte, req)
-
-	// Defer logging req summary
-	defer func() {
-		go func() {
-			close(respCh)
-			us.resultLogger.LogTuringRouterRequestSummary(md, req, respCh)
-		}()
-	}()
+	upiRequest := fiberGrpc.NewRequest(md, requestByte, routerReq)
 
 	// Create a channel for experiment treatment response and add to context
 	ch := make(chan *experiment.Response, 1)
@@ -169,29 +267,36 @@ func (us *Server) getPrediction(
 	// Calling Routes via fiber
 	resp, turingError := us.missionControl.Route(ctx, upiRequest)
 	if turingError != nil {
-		return nil, turingError
+		us.resultLogger.SendResponseToLogChannel(respCh, resultlog.ResultLogKeys.Router, nil, nil, turingError)
+		trace.SetRouter(debugComponent(nil, turingError))
+		experimentResponse := getExperimentResponse(ctx)
+		reason := fallback.RouterReason
+		if experimentResponse != nil && experimentResponse.Error != "" {
+			reason = fallback.ExperimentReason
+		}
+		return us.fallbackResponse(ctx, selection.TrafficRule(), reason, turingReqID, experimentResponse, turingError)
 	}
 	// type assert to grpc response to get metadata
 	grpcResp := resp.(*fiberGrpc.Response)
 
+	// Get the experiment treatment channel from the request context, read result
+	experimentResponse := getExperimentResponse(ctx)
+
 	predictResponse := &upiv1.PredictValuesResponse{}
 	err = proto.Unmarshal(grpcResp.Payload(), predictResponse)
 	if err != nil {
 		turingError = errors.NewTuringError(
 			errors.Newf(errors.BadResponse, "unable to unmarshal into expected response proto"), fiberProtocol.GRPC,
 		)
-		return nil, turingError
+		return us.fallbackResponse(
+			ctx, selection.TrafficRule(), fallback.RouterReason, turingReqID, experimentResponse, turingError)
 	}
 
-	// Get the experiment treatment channel from the request context, read result
-	var experimentResponse *experiment.Response
-	expResultCh, err := experiment.GetExperimentResponseChannel(ctx)
-	if err == nil {
-		select {
-		case experimentResponse = <-expResultCh:
-		default:
-			break
-		}
+	if experimentResponse != nil {
+		trace.SetExperiment(&debug.Component{
+			Response: debug.JSONPayload(experimentResponse.Body()),
+			Error:    experimentResponse.Error,
+		})
 	}
 
 	// Creates ResponseMetadata if its nil
@@ -203,9 +308,155 @@ func (us *Server) getPrediction(
 		grpcResp.Metadata,
 		predictResponse,
 		turingError)
+	trace.SetRouter(debugComponent(predictResponse, nil))
+
+	// Ensemble
+	if us.missionControl.IsEnsemblerEnabled() {
+		ensemblerResp, ensemblerMd, turingError := us.missionControl.Ensemble(ctx, routerReq, predictResponse, md)
+		us.resultLogger.SendResponseToLogChannel(
+			respCh,
+			resultlog.ResultLogKeys.Ensembler,
+			ensemblerMd,
+			ensemblerResp,
+			turingError)
+		trace.SetEnsembler(debugComponent(ensemblerResp, turingError))
+		if turingError != nil {
+			return us.fallbackResponse(
+				ctx, selection.TrafficRule(), fallback.EnsemblerReason, turingReqID, experimentResponse, turingError)
+		}
+		predictResponse = populateResponseMetadata(ensemblerResp, turingReqID, experimentResponse)
+	}
+
+	// Postprocess
+	if len(postStages) > 0 {
+		stagesResp, turingError := us.runPostprocessingStages(ctx, respCh, postStages, routerReq, predictResponse, md)
+		if turingError != nil {
+			return nil, turingError
+		}
+		predictResponse = populateResponseMetadata(stagesResp, turingReqID, experimentResponse)
+	}
+	us.missionControl.CacheResponse(cacheKey, predictResponse)
 	return predictResponse, nil
 }
 
+// fallbackResponse returns the fallback response of the given traffic rule, that is served in place of
+// the given error of the router, the experiment engine or the ensembler, and marks it in the response
+// metadata. The error is returned, if there is no fallback response to serve.
+func (us *Server) fallbackResponse(
+	ctx context.Context,
+	trafficRule string,
+	reason fallback.Reason,
+	turingReqID string,
+	experimentResponse *experiment.Response,
+	turingError *errors.TuringError,
+) (*upiv1.PredictValuesResponse, *errors.TuringError) {
+	resp := us.missionControl.GetFallbackResponse(trafficRule, reason)
+	if resp == nil {
+		return nil, turingError
+	}
+	ctxLogger := log.WithContext(ctx)
+	ctxLogger.Warnf("Serving the fallback response in place of the %s error: %s", reason, turingError.Message)
+	if err := grpc.SetHeader(ctx, metadata.Pairs(fallback.HeaderKey, "true")); err != nil {
+		ctxLogger.Errorf("Failed to mark the fallback response: %s", err.Error())
+	}
+	return populateResponseMetadata(resp, turingReqID, experimentResponse), nil
+}
+
+// getExperimentResponse returns the experiment treatment from the channel in the request context,
+// if the experiment engine was called
+func getExperimentResponse(ctx context.Context) *experiment.Response {
+	expResultCh, err := experiment.GetExperimentResponseChannel(ctx)
+	if err != nil {
+		return nil
+	}
+	select {
+	case experimentResponse := <-expResultCh:
+		return experimentResponse
+	default:
+		return nil
+	}
+}
+
+// runPreprocessingStages runs the given preprocessing stages in order, each one receiving the request enriched
+// with the response of the previous one, and returns the request to be routed, together with its metadata.
+// The failure of a stage is handled according to its error policy.
+func (us *Server) runPreprocessingStages(
+	ctx context.Context,
+	respCh chan<- resultlog.GrpcRouterResponse,
+	stages router.ProcessingStages,
+	req *upiv1.PredictValuesRequest,
+	md metadata.MD,
+) (*upiv1.PredictValuesRequest, metadata.MD, *errors.TuringError) {
+	trace, _ := debug.GetTrace(ctx)
+	for _, stage := range stages {
+		stageResp, stageMd, turingError := us.missionControl.Process(ctx, stage, req, md)
+		// Send stage response/error for logging
+		us.resultLogger.SendStageResponseToLogChannel(respCh, stage, stageMd, stageResp, turingError)
+		trace.AddStage(stage.Name, debugComponent(stageResp, turingError))
+		if turingError != nil {
+			switch stage.ErrorPolicy {
+			case router.SkipErrorPolicy:
+				continue
+			case router.UsePreviousPayloadErrorPolicy:
+				return req, md, nil
+			default:
+				return nil, nil, turingError
+			}
+		}
+		req = makeEnrichedRequest(req, stageResp)
+		md = metadata.Join(md, stageMd)
+	}
+	return req, md, nil
+}
+
+// runPostprocessingStages runs the given postprocessing stages in order, and returns the response of the last one.
+// The request to each stage is the routed request, with the prediction table replaced by the prediction result
+// table of the previous response. The failure of a stage is handled according to its error policy.
+func (us *Server) runPostprocessingStages(
+	ctx context.Context,
+	respCh chan<- resultlog.GrpcRouterResponse,
+	stages router.ProcessingStages,
+	req *upiv1.PredictValuesRequest,
+	resp *upiv1.PredictValuesResponse,
+	md metadata.MD,
+) (*upiv1.PredictValuesResponse, *errors.TuringError) {
+	trace, _ := debug.GetTrace(ctx)
+	for _, stage := range stages {
+		stageResp, stageMd, turingError := us.missionControl.Process(ctx, stage, makeEnrichedRequest(req, resp), md)
+		// Send stage response/error for logging
+		us.resultLogger.SendStageResponseToLogChannel(respCh, stage, stageMd, stageResp, turingError)
+		trace.AddStage(stage.Name, debugComponent(stageResp, turingError))
+		if turingError != nil {
+			switch stage.ErrorPolicy {
+			case router.SkipErrorPolicy:
+				continue
+			case router.UsePreviousPayloadErrorPolicy:
+				return resp, nil
+			default:
+				return nil, turingError
+			}
+		}
+		md = metadata.Join(md, stageMd)
+		resp = stageResp
+	}
+	return resp, nil
+}
+
+// makeEnrichedRequest creates the request to be routed from the original request and the enricher (or stage)
+// response. The prediction table is replaced with the enricher's prediction result table, if set,
+// and the prediction context from the enricher is appended to the request's prediction context.
+func makeEnrichedRequest(
+	req *upiv1.PredictValuesRequest,
+	enricherResp *upiv1.PredictValuesResponse,
+) *upiv1.PredictValuesRequest {
+	enrichedReq := proto.Clone(req).(*upiv1.PredictValuesRequest)
+	if enricherResp.GetPredictionResultTable() != nil {
+		enrichedReq.PredictionTable = enricherResp.GetPredictionResultTable()
+	}
+	enrichedReq.PredictionContext = append(enrichedReq.PredictionContext, enricherResp.GetPredictionContext()...)
+	return enrichedReq
+}
+
 func populateRequestMetadata(req *upiv1.PredictValuesRequest, id string) *upiv1.PredictValuesRequest {
 	if req.Metadata == nil {
 		req.Metadata = &upiv1.RequestMetadata{}
@@ -240,3 +491,40 @@ func populateResponseMetadata(
 	resp.Metadata.PredictionId = id
 	return resp
 }
+
+// isDebugRequested returns whether the debug trace of the request is requested in the request metadata
+func isDebugRequested(md metadata.MD) bool {
+	values := md.Get(debug.HeaderKey)
+	return len(values) > 0 && debug.IsRequested(values[0])
+}
+
+// sendDebugTrace sends the debug trace of the request to the client, in the response header
+func sendDebugTrace(ctx context.Context, trace *debug.Trace) {
+	// Wait for the calls to the routes, that may still be in progress, so that the trace is complete
+	info, err := json.Marshal(trace.Wait())
+	if err == nil {
+		err = grpc.SetHeader(ctx, metadata.Pairs(debug.ResponseMetadataKey, string(info)))
+	}
+	if err != nil {
+		log.WithContext(ctx).Errorf("Failed to send the debug trace: %s", err.Error())
+	}
+}
+
+// encodeUPIResponse converts the payload of a route response to JSON
+func encodeUPIResponse(resp fiber.Response) json.RawMessage {
+	predictResponse := &upiv1.PredictValuesResponse{}
+	if !resp.IsSuccess() || proto.Unmarshal(resp.Payload(), predictResponse) != nil {
+		return debug.JSONPayload(resp.Payload())
+	}
+	payload, _ := protojson.Marshal(predictResponse)
+	return payload
+}
+
+// debugComponent converts the response / error of a Turing component, to be recorded in the debug trace
+func debugComponent(resp *upiv1.PredictValuesResponse, err *errors.TuringError) *debug.Component {
+	if err != nil {
+		return &debug.Component{Error: err.Message}
+	}
+	payload, _ := protojson.Marshal(resp)
+	return &debug.Component{Response: payload}
+}
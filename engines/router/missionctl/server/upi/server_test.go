@@ -2,6 +2,7 @@ package upi
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"testing"
@@ -11,11 +12,18 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/caraml-dev/turing/engines/router"
+	"github.com/caraml-dev/turing/engines/router/missionctl/debug"
 	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/fallback"
 	"github.com/caraml-dev/turing/engines/router/missionctl/internal/mocks"
 	"github.com/caraml-dev/turing/engines/router/missionctl/log"
 	"github.com/caraml-dev/turing/engines/router/missionctl/log/resultlog"
@@ -32,6 +40,24 @@ var mockResponse = &upiv1.PredictValuesResponse{
 	},
 }
 
+var enricherResponse = &upiv1.PredictValuesResponse{
+	PredictionResultTable: &upiv1.Table{
+		Name: "enriched_table",
+	},
+}
+
+var ensemblerResponse = &upiv1.PredictValuesResponse{
+	PredictionResultTable: &upiv1.Table{
+		Name: "ensembled_table",
+	},
+}
+
+var fallbackResponse = &upiv1.PredictValuesResponse{
+	PredictionResultTable: &upiv1.Table{
+		Name: "fallback_table",
+	},
+}
+
 func TestUPIServer_PredictValues(t *testing.T) {
 
 	appName := "name-3.proj"
@@ -43,6 +69,13 @@ func TestUPIServer_PredictValues(t *testing.T) {
 		expected    *upiv1.PredictValuesResponse
 		expectedErr error
 		mockReturn  func() (fiber.Response, *errors.TuringError)
+		// enricher and ensembler are disabled when the corresponding mock returns are not set
+		enricherReturn  func() (*upiv1.PredictValuesResponse, metadata.MD, *errors.TuringError)
+		ensemblerReturn func() (*upiv1.PredictValuesResponse, metadata.MD, *errors.TuringError)
+		// the router is not called, when the cached response is set
+		cachedResponse *upiv1.PredictValuesResponse
+		// the fallback response is served in place of the errors of the router and the ensembler, if set
+		fallbackResponse *upiv1.PredictValuesResponse
 	}{
 		{
 			name:        "ok",
@@ -55,6 +88,12 @@ func TestUPIServer_PredictValues(t *testing.T) {
 				}, nil
 			},
 		},
+		{
+			name:           "ok from cache",
+			request:        &upiv1.PredictValuesRequest{},
+			expected:       mockResponse,
+			cachedResponse: proto.Clone(mockResponse).(*upiv1.PredictValuesResponse),
+		},
 		{
 			name:        "error",
 			request:     &upiv1.PredictValuesRequest{},
@@ -66,6 +105,18 @@ func TestUPIServer_PredictValues(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:             "ok from fallback on router error",
+			request:          &upiv1.PredictValuesRequest{},
+			expected:         fallbackResponse,
+			fallbackResponse: proto.Clone(fallbackResponse).(*upiv1.PredictValuesResponse),
+			mockReturn: func() (fiber.Response, *errors.TuringError) {
+				return nil, &errors.TuringError{
+					Code:    int(codes.DeadlineExceeded),
+					Message: "route timeout",
+				}
+			},
+		},
 		{
 			name:        "error wrong response payload type",
 			request:     &upiv1.PredictValuesRequest{},
@@ -76,30 +127,115 @@ func TestUPIServer_PredictValues(t *testing.T) {
 				}, nil
 			},
 		},
+		{
+			name:     "ok with enricher and ensembler",
+			request:  &upiv1.PredictValuesRequest{},
+			expected: ensemblerResponse,
+			mockReturn: func() (fiber.Response, *errors.TuringError) {
+				return &fiberGrpc.Response{
+					Message: responseByte,
+				}, nil
+			},
+			enricherReturn: func() (*upiv1.PredictValuesResponse, metadata.MD, *errors.TuringError) {
+				return enricherResponse, metadata.Pairs("enricher-key", "value"), nil
+			},
+			ensemblerReturn: func() (*upiv1.PredictValuesResponse, metadata.MD, *errors.TuringError) {
+				return ensemblerResponse, nil, nil
+			},
+		},
+		{
+			name:        "error enricher",
+			request:     &upiv1.PredictValuesRequest{},
+			expectedErr: status.Error(codes.DeadlineExceeded, "enricher timeout"),
+			enricherReturn: func() (*upiv1.PredictValuesResponse, metadata.MD, *errors.TuringError) {
+				return nil, nil, &errors.TuringError{
+					Code:    int(codes.DeadlineExceeded),
+					Message: "enricher timeout",
+				}
+			},
+		},
+		{
+			name:        "error ensembler",
+			request:     &upiv1.PredictValuesRequest{},
+			expectedErr: status.Error(codes.Unavailable, "ensembler unavailable"),
+			mockReturn: func() (fiber.Response, *errors.TuringError) {
+				return &fiberGrpc.Response{
+					Message: responseByte,
+				}, nil
+			},
+			ensemblerReturn: func() (*upiv1.PredictValuesResponse, metadata.MD, *errors.TuringError) {
+				return nil, nil, &errors.TuringError{
+					Code:    int(codes.Unavailable),
+					Message: "ensembler unavailable",
+				}
+			},
+		},
+		{
+			name:             "ok from fallback on ensembler error",
+			request:          &upiv1.PredictValuesRequest{},
+			expected:         fallbackResponse,
+			fallbackResponse: proto.Clone(fallbackResponse).(*upiv1.PredictValuesResponse),
+			mockReturn: func() (fiber.Response, *errors.TuringError) {
+				return &fiberGrpc.Response{
+					Message: responseByte,
+				}, nil
+			},
+			ensemblerReturn: func() (*upiv1.PredictValuesResponse, metadata.MD, *errors.TuringError) {
+				return nil, nil, &errors.TuringError{
+					Code:    int(codes.Unavailable),
+					Message: "ensembler unavailable",
+				}
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			expectedPredictionTable := tt.request.PredictionTable
+
 			mockMc := &mocks.MissionControlUPI{}
-			mockMc.On("Route", mock.Anything, mock.Anything).Return(tt.mockReturn()).Run(func(args mock.Arguments) {
-				fiberRequest, ok := args.Get(1).(*fiberGrpc.Request)
-				require.True(t, ok, "not fiber grpc request")
+			mockMc.On("IsEnricherEnabled").Return(tt.enricherReturn != nil)
+			mockMc.On("IsEnsemblerEnabled").Return(tt.ensemblerReturn != nil)
+			mockMc.On("GetProcessingStages", mock.Anything).Return(router.ProcessingStages{})
+			mockMc.On("GetCachedResponse", mock.Anything, mock.Anything).Return("cache-key", tt.cachedResponse)
+			mockMc.On("CacheResponse", "cache-key", mock.Anything)
+			mockMc.On("GetFallbackResponse", mock.Anything, mock.Anything).Return(tt.fallbackResponse)
+			if tt.enricherReturn != nil {
+				mockMc.On("Enrich", mock.Anything, mock.Anything, mock.Anything).Return(tt.enricherReturn())
+				expectedPredictionTable = enricherResponse.PredictionResultTable
+			}
+			if tt.ensemblerReturn != nil {
+				mockMc.On("Ensemble", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return(tt.ensemblerReturn()).
+					Run(func(args mock.Arguments) {
+						routerResp, ok := args.Get(2).(*upiv1.PredictValuesResponse)
+						require.True(t, ok, "not upi response")
+						require.True(t, proto.Equal(routerResp.PredictionResultTable, mockResponse.PredictionResultTable),
+							"invalid router response")
+					})
+			}
+			if tt.mockReturn != nil {
+				mockMc.On("Route", mock.Anything, mock.Anything).Return(tt.mockReturn()).Run(func(args mock.Arguments) {
+					fiberRequest, ok := args.Get(1).(*fiberGrpc.Request)
+					require.True(t, ok, "not fiber grpc request")
 
-				upiReq, ok := fiberRequest.Proto.(*upiv1.PredictValuesRequest)
-				require.True(t, ok, "not upi request")
+					upiReq, ok := fiberRequest.Proto.(*upiv1.PredictValuesRequest)
+					require.True(t, ok, "not upi request")
 
-				require.True(t, proto.Equal(upiReq.PredictionTable, tt.request.PredictionTable), "invalid prediction table")
-				require.NotEmpty(t, upiReq.Metadata.PredictionId, "prediction id is empty")
-				require.NotEmpty(t, upiReq.Metadata.RequestTimestamp, "request timestamp is empty")
-			})
+					require.True(t, proto.Equal(upiReq.PredictionTable, expectedPredictionTable), "invalid prediction table")
+					require.NotEmpty(t, upiReq.Metadata.PredictionId, "prediction id is empty")
+					require.NotEmpty(t, upiReq.Metadata.RequestTimestamp, "request timestamp is empty")
+				})
+			}
 
 			resultLogger, err := resultlog.InitUPIResultLogger(
 				appName,
-				"nop",
 				nil,
-				resultlog.InitTuringResultLogger("appName", resultlog.NewNopLogger()))
+				resultlog.InitTuringResultLogger("appName", resultlog.NewNopLogger(), nil),
+				nil,
+			)
 			require.NoError(t, err)
 
-			upiServer := NewUPIServer(mockMc, resultLogger)
+			upiServer := NewUPIServer(mockMc, resultLogger, false, nil, nil)
 			ctx := context.Background()
 			resp, err := upiServer.PredictValues(ctx, tt.request)
 			if tt.expectedErr != nil {
@@ -110,10 +246,77 @@ func TestUPIServer_PredictValues(t *testing.T) {
 			require.True(t, proto.Equal(resp.PredictionResultTable, tt.expected.PredictionResultTable),
 				"response not equal to expected")
 			require.NotEmpty(t, resp.Metadata.PredictionId, "prediction id is empty")
+			if tt.cachedResponse != nil {
+				mockMc.AssertNotCalled(t, "Route", mock.Anything, mock.Anything)
+				mockMc.AssertNotCalled(t, "CacheResponse", mock.Anything, mock.Anything)
+			} else if tt.fallbackResponse != nil {
+				mockMc.AssertNotCalled(t, "CacheResponse", mock.Anything, mock.Anything)
+			} else {
+				mockMc.AssertCalled(t, "CacheResponse", "cache-key", resp)
+			}
 		})
 	}
 }
 
+// TestUPIServerProcessingStages tests that the preprocessing stages run before the router, and that the
+// postprocessing stages run on the router response, with their failures handled by their error policies
+func TestUPIServerProcessingStages(t *testing.T) {
+	responseByte, err := proto.Marshal(mockResponse)
+	require.NoError(t, err)
+
+	checkStage := &router.ProcessingStage{Name: "check", Phase: router.PreprocessingPhase}
+	calibrateStage := &router.ProcessingStage{
+		Name:        "calibrate",
+		Phase:       router.PostprocessingPhase,
+		ErrorPolicy: router.SkipErrorPolicy,
+	}
+	rulesStage := &router.ProcessingStage{Name: "rules", Phase: router.PostprocessingPhase}
+	checkedResponse := &upiv1.PredictValuesResponse{PredictionResultTable: &upiv1.Table{Name: "checked_table"}}
+	finalResponse := &upiv1.PredictValuesResponse{PredictionResultTable: &upiv1.Table{Name: "final_table"}}
+
+	mockMc := &mocks.MissionControlUPI{}
+	mockMc.On("IsEnricherEnabled").Return(false)
+	mockMc.On("IsEnsemblerEnabled").Return(false)
+	mockMc.On("GetProcessingStages", router.PreprocessingPhase).Return(router.ProcessingStages{checkStage})
+	mockMc.On("GetProcessingStages", router.PostprocessingPhase).
+		Return(router.ProcessingStages{calibrateStage, rulesStage})
+	mockMc.On("GetCachedResponse", mock.Anything, mock.Anything).Return("cache-key", nil)
+	mockMc.On("CacheResponse", "cache-key", mock.Anything)
+	mockMc.On("Process", mock.Anything, checkStage, mock.Anything, mock.Anything).Return(checkedResponse, nil, nil)
+	mockMc.On("Process", mock.Anything, calibrateStage, mock.Anything, mock.Anything).
+		Return(nil, nil, &errors.TuringError{Code: int(codes.Unavailable), Message: "calibrate unavailable"})
+	mockMc.On("Process", mock.Anything, rulesStage, mock.Anything, mock.Anything).
+		Return(finalResponse, nil, nil).
+		Run(func(args mock.Arguments) {
+			// The failed calibrate stage is skipped, so the rules stage receives the router response
+			req, ok := args.Get(2).(*upiv1.PredictValuesRequest)
+			require.True(t, ok, "not upi request")
+			require.True(t, proto.Equal(req.PredictionTable, mockResponse.PredictionResultTable),
+				"invalid prediction table")
+		})
+	mockMc.On("Route", mock.Anything, mock.Anything).
+		Return(&fiberGrpc.Response{Message: responseByte}, nil).
+		Run(func(args mock.Arguments) {
+			fiberRequest, ok := args.Get(1).(*fiberGrpc.Request)
+			require.True(t, ok, "not fiber grpc request")
+			upiReq, ok := fiberRequest.Proto.(*upiv1.PredictValuesRequest)
+			require.True(t, ok, "not upi request")
+			require.True(t, proto.Equal(upiReq.PredictionTable, checkedResponse.PredictionResultTable),
+				"invalid prediction table")
+		})
+
+	resultLogger, err := resultlog.InitUPIResultLogger(
+		"name-3.proj", nil, resultlog.InitTuringResultLogger("app", resultlog.NewNopLogger(), nil), nil)
+	require.NoError(t, err)
+
+	upiServer := NewUPIServer(mockMc, resultLogger, false, nil, nil)
+	resp, err := upiServer.PredictValues(context.Background(), &upiv1.PredictValuesRequest{})
+	require.NoError(t, err)
+	require.True(t, proto.Equal(resp.PredictionResultTable, finalResponse.PredictionResultTable),
+		"response not equal to expected")
+	mockMc.AssertNumberOfCalls(t, "Process", 3)
+}
+
 func TestNewUpiServer(t *testing.T) {
 	_, logs := observer.New(zap.ErrorLevel)
 
@@ -124,10 +327,139 @@ func TestNewUpiServer(t *testing.T) {
 	}
 
 	mockMc := &mocks.MissionControlUPI{}
-	upiServer := NewUPIServer(mockMc, nil)
+	upiServer := NewUPIServer(mockMc, nil, false, nil, nil)
 	go upiServer.Run(l)
 
 	// Wait for server to run and check that there are no error logs
 	time.Sleep(2 * time.Second)
 	require.Zero(t, logs.Len())
 }
+
+func TestUpiServerShutdown(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	upiServer := NewUPIServer(&mocks.MissionControlUPI{}, nil, false, nil, nil)
+	runErrCh := make(chan error, 1)
+	go func() {
+		runErrCh <- upiServer.Run(l)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, upiServer.Shutdown(ctx))
+
+	// Run returns without an error, once the server is shut down
+	select {
+	case err := <-runErrCh:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("UPI server is still running after shutdown")
+	}
+}
+
+func TestUPIServerDebugTrace(t *testing.T) {
+	responseByte, err := proto.Marshal(mockResponse)
+	require.NoError(t, err)
+
+	mockMc := &mocks.MissionControlUPI{}
+	mockMc.On("IsEnricherEnabled").Return(false)
+	mockMc.On("IsEnsemblerEnabled").Return(false)
+	mockMc.On("GetProcessingStages", mock.Anything).Return(router.ProcessingStages{})
+	mockMc.On("GetCachedResponse", mock.Anything, mock.Anything).Return("cache-key", nil)
+	mockMc.On("CacheResponse", "cache-key", mock.Anything)
+	mockMc.On("Route", mock.Anything, mock.Anything).Return(&fiberGrpc.Response{Message: responseByte}, nil)
+
+	resultLogger, err := resultlog.InitUPIResultLogger(
+		"name-3.proj", nil, resultlog.InitTuringResultLogger("app", resultlog.NewNopLogger(), nil), nil)
+	require.NoError(t, err)
+
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	upiServer := NewUPIServer(mockMc, resultLogger, true, nil, nil)
+	go func() {
+		_ = upiServer.Run(l)
+	}()
+	defer func() {
+		_ = upiServer.Shutdown(context.Background())
+	}()
+
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := upiv1.NewUniversalPredictionServiceClient(conn)
+
+	tests := map[string]struct {
+		debugMetadata string
+		expectTrace   bool
+	}{
+		"debug requested": {
+			debugMetadata: "true",
+			expectTrace:   true,
+		},
+		"debug not requested": {
+			debugMetadata: "false",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "turing-debug", tt.debugMetadata)
+			var header metadata.MD
+			resp, err := client.PredictValues(ctx, &upiv1.PredictValuesRequest{}, grpc.Header(&header))
+			require.NoError(t, err)
+
+			values := header.Get("turing-debug-bin")
+			if !tt.expectTrace {
+				require.Empty(t, values)
+				return
+			}
+			require.Len(t, values, 1)
+			var info debug.Info
+			require.NoError(t, json.Unmarshal([]byte(values[0]), &info))
+			require.Equal(t, resp.Metadata.PredictionId, info.TuringReqID)
+			require.NotNil(t, info.Router)
+
+			routerResp := &upiv1.PredictValuesResponse{}
+			require.NoError(t, protojson.Unmarshal(info.Router.Response, routerResp))
+			require.True(t, proto.Equal(mockResponse.PredictionResultTable, routerResp.PredictionResultTable))
+		})
+	}
+}
+
+func TestUPIServerFallbackMetadata(t *testing.T) {
+	mockMc := &mocks.MissionControlUPI{}
+	mockMc.On("IsEnricherEnabled").Return(false)
+	mockMc.On("IsEnsemblerEnabled").Return(false)
+	mockMc.On("GetProcessingStages", mock.Anything).Return(router.ProcessingStages{})
+	mockMc.On("GetCachedResponse", mock.Anything, mock.Anything).Return("cache-key", nil)
+	mockMc.On("Route", mock.Anything, mock.Anything).
+		Return(nil, &errors.TuringError{Code: int(codes.Unavailable), Message: "routes unavailable"})
+	mockMc.On("GetFallbackResponse", "", fallback.RouterReason).
+		Return(proto.Clone(fallbackResponse).(*upiv1.PredictValuesResponse))
+
+	resultLogger, err := resultlog.InitUPIResultLogger(
+		"name-3.proj", nil, resultlog.InitTuringResultLogger("app", resultlog.NewNopLogger(), nil), nil)
+	require.NoError(t, err)
+
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	upiServer := NewUPIServer(mockMc, resultLogger, false, nil, nil)
+	go func() {
+		_ = upiServer.Run(l)
+	}()
+	defer func() {
+		_ = upiServer.Shutdown(context.Background())
+	}()
+
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := upiv1.NewUniversalPredictionServiceClient(conn)
+
+	var header metadata.MD
+	resp, err := client.PredictValues(context.Background(), &upiv1.PredictValuesRequest{}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.True(t, proto.Equal(fallbackResponse.PredictionResultTable, resp.PredictionResultTable))
+	require.NotEmpty(t, resp.Metadata.PredictionId)
+	require.Equal(t, []string{"true"}, header.Get("turing-fallback"))
+}
@@ -0,0 +1,95 @@
+package shadow
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/turingctx"
+)
+
+// Response holds the response / error received from a shadow route
+type Response struct {
+	RouteID string
+	Header  http.Header
+	Body    []byte
+	Error   string
+	Latency time.Duration
+}
+
+// Collector collects the responses from the shadow routes, that are dispatched asynchronously
+// while the request is being processed, so that they can be logged together with the request
+type Collector struct {
+	mu      sync.Mutex
+	closed  bool
+	pending sync.WaitGroup
+	// inProgress is the number of the shadow requests, that are registered and not yet recorded
+	inProgress int
+	responses  []*Response
+}
+
+// NewCollector creates a new Collector
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+// Begin registers a shadow request that is about to be dispatched. It returns false if
+// the collector has already been closed by Wait, in which case the response of the
+// shadow route should not be recorded.
+func (c *Collector) Begin() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+	c.pending.Add(1)
+	c.inProgress++
+	return true
+}
+
+// Record saves the response of a shadow request, previously registered with Begin
+func (c *Collector) Record(resp *Response) {
+	c.mu.Lock()
+	c.responses = append(c.responses, resp)
+	c.inProgress--
+	c.mu.Unlock()
+
+	c.pending.Done()
+}
+
+// Pending returns whether any of the shadow requests, registered with Begin, is still in progress
+func (c *Collector) Pending() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.inProgress > 0
+}
+
+// Wait closes the collector for new shadow requests, waits for all the pending ones
+// to complete and returns their responses
+func (c *Collector) Wait() []*Response {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+
+	c.pending.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*Response{}, c.responses...)
+}
+
+// WithCollector associates the shadow response collector with the given context object
+func WithCollector(ctx context.Context, collector *Collector) context.Context {
+	return context.WithValue(ctx, turingctx.TuringShadowCollectorKey, collector)
+}
+
+// GetCollector returns the shadow response collector from the input context
+func GetCollector(ctx context.Context) (*Collector, error) {
+	if ctxValue, ok := ctx.Value(turingctx.TuringShadowCollectorKey).(*Collector); ok {
+		return ctxValue, nil
+	}
+	return nil, errors.Newf(errors.Unknown, "Shadow response collector not found in the context")
+}
@@ -0,0 +1,45 @@
+package shadow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	collector := NewCollector()
+
+	assert.False(t, collector.Pending())
+	require.True(t, collector.Begin())
+	require.True(t, collector.Begin())
+	assert.True(t, collector.Pending())
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		collector.Record(&Response{RouteID: "shadow-a"})
+	}()
+	collector.Record(&Response{RouteID: "shadow-b", Error: "timeout"})
+
+	responses := collector.Wait()
+	assert.ElementsMatch(t, []*Response{
+		{RouteID: "shadow-a"},
+		{RouteID: "shadow-b", Error: "timeout"},
+	}, responses)
+	assert.False(t, collector.Pending())
+
+	// Shadow requests, that begin after the collector is closed, are not recorded
+	assert.False(t, collector.Begin())
+}
+
+func TestGetCollector(t *testing.T) {
+	_, err := GetCollector(context.Background())
+	assert.EqualError(t, err, "Shadow response collector not found in the context")
+
+	collector := NewCollector()
+	actual, err := GetCollector(WithCollector(context.Background(), collector))
+	require.NoError(t, err)
+	assert.Same(t, collector, actual)
+}
@@ -0,0 +1,106 @@
+package timing
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gojek/fiber"
+
+	"github.com/caraml-dev/turing/engines/router/missionctl/errors"
+	"github.com/caraml-dev/turing/engines/router/missionctl/turingctx"
+)
+
+// RouteComponent is the component of the records of the calls to the individual routes
+const RouteComponent = "route"
+
+// Record holds the timing and the status code of a call to a Turing component, or to a route
+type Record struct {
+	// Component is the result log key of the component, or RouteComponent for the calls to the routes
+	Component string
+	// RouteID is only set for the calls to the routes
+	RouteID   string
+	StartTime time.Time
+	Duration  time.Duration
+	// StatusCode is the HTTP status code of the response, or the gRPC status code for the UPI routers
+	StatusCode int
+
+	// response is the response returned by the route, used to identify the route whose response was used
+	response fiber.Response
+}
+
+// Collector collects the timings of the calls to the Turing components and to the routes, while
+// the request is being processed, so that they can be logged together with the request
+type Collector struct {
+	mu      sync.Mutex
+	records []*Record
+	// response is the response of the router, which is that of one of the routes, unless the routes
+	// failed or their responses were combined
+	response fiber.Response
+}
+
+// NewCollector creates a new Collector
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+// Record saves the timing of a call to a Turing component
+func (c *Collector) Record(record *Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records = append(c.records, record)
+}
+
+// RecordRoute saves the timing of a call to a route, together with the response it returned
+func (c *Collector) RecordRoute(record *Record, response fiber.Response) {
+	record.response = response
+	c.Record(record)
+}
+
+// SetResponse saves the response of the router
+func (c *Collector) SetResponse(response fiber.Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.response = response
+}
+
+// Records returns the timings recorded so far, in the order in which the calls started
+func (c *Collector) Records() []*Record {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	records := append([]*Record{}, c.records...)
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].StartTime.Before(records[j].StartTime)
+	})
+	return records
+}
+
+// RouteID returns the ID of the route, whose response was returned by the router, which is empty
+// if the router didn't return the response of a route
+func (c *Collector) RouteID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.response == nil {
+		return ""
+	}
+	for _, record := range c.records {
+		if record.response == c.response {
+			return record.RouteID
+		}
+	}
+	return ""
+}
+
+// WithCollector associates the timing collector with the given context object
+func WithCollector(ctx context.Context, collector *Collector) context.Context {
+	return context.WithValue(ctx, turingctx.TuringTimingCollectorKey, collector)
+}
+
+// GetCollector returns the timing collector from the input context
+func GetCollector(ctx context.Context) (*Collector, error) {
+	if ctxValue, ok := ctx.Value(turingctx.TuringTimingCollectorKey).(*Collector); ok {
+		return ctxValue, nil
+	}
+	return nil, errors.Newf(errors.Unknown, "Timing collector not found in the context")
+}
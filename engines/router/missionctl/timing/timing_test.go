@@ -0,0 +1,66 @@
+package timing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	collector := NewCollector()
+	assert.Empty(t, collector.Records())
+	assert.Empty(t, collector.RouteID())
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	enricher := &Record{Component: "enricher", StartTime: start, Duration: time.Millisecond, StatusCode: 200}
+	router := &Record{
+		Component:  "router",
+		StartTime:  start.Add(time.Millisecond),
+		Duration:   30 * time.Millisecond,
+		StatusCode: 200,
+	}
+	routeA := &Record{
+		Component:  RouteComponent,
+		RouteID:    "route-a",
+		StartTime:  start.Add(2 * time.Millisecond),
+		Duration:   10 * time.Millisecond,
+		StatusCode: 500,
+	}
+	routeB := &Record{
+		Component:  RouteComponent,
+		RouteID:    "route-b",
+		StartTime:  start.Add(12 * time.Millisecond),
+		Duration:   15 * time.Millisecond,
+		StatusCode: 200,
+	}
+	respA := fiber.NewErrorResponse(assert.AnError)
+	respB := fiber.NewErrorResponse(assert.AnError)
+
+	// The calls complete in a different order from the one in which they started
+	collector.Record(enricher)
+	collector.RecordRoute(routeA, respA)
+	collector.RecordRoute(routeB, respB)
+	collector.Record(router)
+	assert.Equal(t, []*Record{enricher, router, routeA, routeB}, collector.Records())
+	assert.Empty(t, collector.RouteID())
+
+	// The route is identified by the response returned by the router
+	collector.SetResponse(respB)
+	assert.Equal(t, "route-b", collector.RouteID())
+	collector.SetResponse(fiber.NewErrorResponse(assert.AnError))
+	assert.Empty(t, collector.RouteID())
+}
+
+func TestGetCollector(t *testing.T) {
+	_, err := GetCollector(context.Background())
+	assert.EqualError(t, err, "Timing collector not found in the context")
+
+	collector := NewCollector()
+	actual, err := GetCollector(WithCollector(context.Background(), collector))
+	require.NoError(t, err)
+	assert.Same(t, collector, actual)
+}
@@ -16,6 +16,16 @@ const (
 	turingReqIDKey ctxKeyType = iota
 	// TuringTreatmentChannelKey is used to store a channel to send experiment treatment
 	TuringTreatmentChannelKey
+	// TuringShadowCollectorKey is used to store the collector of the responses from the shadow routes
+	TuringShadowCollectorKey
+	// TuringHedgingCollectorKey is used to store the collector of the hedged requests to the routes
+	TuringHedgingCollectorKey
+	// TuringDebugTraceKey is used to store the debug trace of the request, in debug mode
+	TuringDebugTraceKey
+	// TuringFallbackSelectionKey is used to store the traffic rule selection, that picks the fallback response
+	TuringFallbackSelectionKey
+	// TuringTimingCollectorKey is used to store the collector of the timings of the calls to the components
+	TuringTimingCollectorKey
 )
 
 // NewTuringContext returns a context which holds additional data pertaining
@@ -0,0 +1,105 @@
+package router
+
+import (
+	"reflect"
+
+	fiberConfig "github.com/gojek/fiber/config"
+)
+
+// StagePhase is the part of the router's workflow in which a processing stage runs
+type StagePhase string
+
+const (
+	// PreprocessingPhase stages run after the enricher (if any), and their output is routed
+	PreprocessingPhase StagePhase = "preprocessing"
+	// PostprocessingPhase stages run after the ensembler (if any), and their output is returned to the client
+	PostprocessingPhase StagePhase = "postprocessing"
+)
+
+// StageErrorPolicy is the way in which the router handles the failure of a processing stage
+type StageErrorPolicy string
+
+const (
+	// FailErrorPolicy fails the request, if the stage fails
+	FailErrorPolicy StageErrorPolicy = "fail"
+	// SkipErrorPolicy ignores the failed stage, and passes its input to the next stage
+	SkipErrorPolicy StageErrorPolicy = "skip"
+	// UsePreviousPayloadErrorPolicy stops the remaining stages of the phase, and uses the input of
+	// the failed stage as the output of the phase
+	UsePreviousPayloadErrorPolicy StageErrorPolicy = "use_previous_payload"
+)
+
+// ProcessingStage is an endpoint, that processes the payload of the request (or the response) in
+// the pre / post-processing phase of the router's workflow. The stages of a phase run in the order
+// in which they are configured, each one receiving the output of the previous one.
+type ProcessingStage struct {
+	// Name of the stage, unique within the router
+	Name string `json:"name" validate:"required"`
+	// Phase of the router's workflow in which the stage runs
+	Phase StagePhase `json:"phase" validate:"required,oneof=preprocessing postprocessing"`
+	// Endpoint of the stage, a URL for HTTP routers, or host:port for UPI routers
+	Endpoint string `json:"endpoint" validate:"required"`
+	// Timeout of the request to the stage
+	Timeout fiberConfig.Duration `json:"timeout" validate:"required"`
+	// ErrorPolicy is the way in which the failure of the stage is handled. The request fails, if not set.
+	ErrorPolicy StageErrorPolicy `json:"error_policy,omitempty" validate:"omitempty,oneof=fail skip use_previous_payload"`
+	// ResultLogKey is the key of the stage's response in the result log. The name of the stage is used, if not set.
+	ResultLogKey string `json:"result_log_key,omitempty"`
+}
+
+// ResultLogKeys are the keys of the responses of the Turing components in the result log, and of the
+// timings of the request. They can't be used as the result log keys of the processing stages.
+var ResultLogKeys = struct {
+	Experiment string
+	Enricher   string
+	Router     string
+	Shadow     string
+	Hedging    string
+	Timing     string
+	Ensembler  string
+}{
+	Experiment: "experiment",
+	Enricher:   "enricher",
+	Router:     "router",
+	Shadow:     "shadow",
+	Hedging:    "hedging",
+	Timing:     "timing",
+	Ensembler:  "ensembler",
+}
+
+// ReservedResultLogKeys returns all of the ResultLogKeys
+func ReservedResultLogKeys() []string {
+	keys := reflect.ValueOf(ResultLogKeys)
+	reserved := make([]string, 0, keys.NumField())
+	for i := 0; i < keys.NumField(); i++ {
+		reserved = append(reserved, keys.Field(i).String())
+	}
+	return reserved
+}
+
+// LogKey returns the key of the stage's response in the result log
+func (s *ProcessingStage) LogKey() string {
+	if s.ResultLogKey != "" {
+		return s.ResultLogKey
+	}
+	return s.Name
+}
+
+// IsErrorTolerated returns whether the router continues to process the request, if the stage fails
+func (s *ProcessingStage) IsErrorTolerated() bool {
+	return s.ErrorPolicy == SkipErrorPolicy || s.ErrorPolicy == UsePreviousPayloadErrorPolicy
+}
+
+// ProcessingStages is the ordered list of the processing stages of a router
+type ProcessingStages []*ProcessingStage
+
+// Phase returns the stages of the given phase, in the order in which they run
+func (s ProcessingStages) Phase(phase StagePhase) ProcessingStages {
+	stages := ProcessingStages{}
+	for _, stage := range s {
+		if stage.Phase == phase {
+			stages = append(stages, stage)
+		}
+	}
+	return stages
+}
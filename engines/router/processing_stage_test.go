@@ -0,0 +1,16 @@
+package router_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/caraml-dev/turing/engines/router"
+)
+
+func TestReservedResultLogKeys(t *testing.T) {
+	assert.Equal(t,
+		[]string{"experiment", "enricher", "router", "shadow", "hedging", "timing", "ensembler"},
+		router.ReservedResultLogKeys(),
+	)
+}
@@ -0,0 +1,63 @@
+package router
+
+import (
+	fiberConfig "github.com/gojek/fiber/config"
+)
+
+// RetryPolicy configures the retries of the failed requests to a route
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts to call the route, including the first one
+	MaxAttempts int `json:"max_attempts" validate:"required,min=1"`
+	// Backoff is the delay before the first retry, which is doubled for every subsequent retry
+	Backoff fiberConfig.Duration `json:"backoff,omitempty"`
+	// RetryableStatusCodes are the status codes of the failed responses that should be retried.
+	// All failed responses are retried, if not set.
+	RetryableStatusCodes []int `json:"retryable_status_codes,omitempty"`
+}
+
+// IsRetryable returns whether the failed response with the given status code should be retried
+func (p *RetryPolicy) IsRetryable(statusCode int) bool {
+	if len(p.RetryableStatusCodes) == 0 {
+		return true
+	}
+	for _, code := range p.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// CircuitBreakerConfig configures the circuit breaker of a route. The circuit is opened after the
+// configured number of consecutive failures, and no requests are sent to the route while it's open.
+// After the open interval, the circuit is half-open and a limited number of probe requests are sent
+// to the route, which close the circuit again if all of them succeed.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures, after which the circuit is opened
+	FailureThreshold int `json:"failure_threshold" validate:"required,min=1"`
+	// OpenInterval is the duration for which the circuit stays open
+	OpenInterval fiberConfig.Duration `json:"open_interval" validate:"required"`
+	// HalfOpenProbes is the number of the probe requests sent to the route while the circuit is half-open
+	HalfOpenProbes int `json:"half_open_probes" validate:"required,min=1"`
+}
+
+// HedgingPolicy configures the hedging of the requests to a route. If no response is received from the
+// route within the delay, a duplicate request is sent to the route and the response that arrives first is used.
+type HedgingPolicy struct {
+	// Delay is the time to wait for the response, before sending the duplicate request, e.g. the p90 latency
+	// of the route
+	Delay fiberConfig.Duration `json:"delay" validate:"required"`
+	// MaxHedgePercent caps the duplicate requests in progress, as a percentage of the requests to the route
+	// in progress. At least one duplicate request is allowed at a time.
+	MaxHedgePercent float64 `json:"max_hedge_percent" validate:"required,gt=0,lte=100"`
+}
+
+// ProxyConfig extends the Fiber proxy config with the retry policy, the circuit breaker and the hedging policy
+// of the route. The additional properties are ignored by Fiber, and are applied by the Turing routing strategies
+// instead.
+type ProxyConfig struct {
+	*fiberConfig.ProxyConfig
+	RetryPolicy    *RetryPolicy          `json:"retry_policy,omitempty"`
+	CircuitBreaker *CircuitBreakerConfig `json:"circuit_breaker,omitempty"`
+	HedgingPolicy  *HedgingPolicy        `json:"hedging_policy,omitempty"`
+}
@@ -6,6 +6,7 @@ import (
 	"reflect"
 
 	upiv1 "github.com/caraml-dev/universal-prediction-interface/gen/go/grpc/caraml/upi/v1"
+	"github.com/go-playground/validator/v10"
 	"github.com/gojek/fiber"
 	"google.golang.org/grpc/metadata"
 
@@ -15,8 +16,8 @@ import (
 type TrafficRuleCondition struct {
 	FieldSource request.FieldSource   `json:"field_source" validate:"required,oneof=header payload prediction_context"`
 	Field       string                `json:"field" validate:"required"`
-	Operator    RuleConditionOperator `json:"operator" validate:"required,oneof=in"`
-	Values      []string              `json:"values" validate:"required,notBlank"`
+	Operator    RuleConditionOperator `json:"operator" validate:"required,oneof=in not_in matches prefix exists gt gte lt lte semver_gt semver_gte semver_lt semver_lte"`
+	Values      []string              `json:"values" validate:"dive,notBlank"`
 }
 
 // TestRequest test that the request satisfy the traffic rule condition
@@ -25,21 +26,41 @@ func (c *TrafficRuleCondition) TestRequest(req fiber.Request) (bool, error) {
 	bodyBytes := req.Payload()
 
 	fieldValue, err := request.GetValueFromHTTPRequest(reqHeader, bodyBytes, c.FieldSource, c.Field)
-	if err != nil {
-		return false, err
-	}
-	return c.Operator.Test(fieldValue, c.Values)
+	return c.test(fieldValue, err)
 }
 
 // TestUPIRequest test that the UPI request satisfy the traffic rule condition
 func (c *TrafficRuleCondition) TestUPIRequest(req *upiv1.PredictValuesRequest, header metadata.MD) (bool, error) {
 	fieldValue, err := request.GetValueFromUPIRequest(header, req, c.FieldSource, c.Field)
+	return c.test(fieldValue, err)
+}
+
+func (c *TrafficRuleCondition) test(fieldValue string, err error) (bool, error) {
 	if err != nil {
+		// The field not being present in the request is a valid outcome for the exists operator
+		if c.Operator.String() == ExistsConditionOperator.String() {
+			return c.Operator.Test(nil, c.Values)
+		}
 		return false, err
 	}
 	return c.Operator.Test(fieldValue, c.Values)
 }
 
+// ValidateTrafficRuleCondition is a struct-level validation for TrafficRuleCondition, that checks
+// that the values of the condition are valid for its operator
+func ValidateTrafficRuleCondition(sl validator.StructLevel) {
+	condition := sl.Current().Interface().(TrafficRuleCondition)
+	if condition.Operator.Operator == nil {
+		return
+	}
+
+	if len(condition.Values) == 0 && condition.Operator.String() != ExistsConditionOperator.String() {
+		sl.ReportError(condition.Values, "Values", "Values", "notBlank", "")
+	} else if err := condition.Operator.ValidateValues(condition.Values); err != nil {
+		sl.ReportError(condition.Values, "Values", "Values", err.Error(), "")
+	}
+}
+
 type RuleConditionOperator struct {
 	Operator
 }
@@ -51,6 +72,15 @@ func (o RuleConditionOperator) String() string {
 	return ""
 }
 
+// ValidateValues checks that the given values can be used with the operator, if the
+// operator implements the ValuesValidator interface
+func (o RuleConditionOperator) ValidateValues(values []string) error {
+	if v, ok := o.Operator.(ValuesValidator); ok {
+		return v.ValidateValues(values)
+	}
+	return nil
+}
+
 func (o RuleConditionOperator) MarshalJSON() ([]byte, error) {
 	return json.Marshal(o.String())
 }
@@ -61,12 +91,11 @@ func (o *RuleConditionOperator) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	switch operatorName {
-	case InConditionOperator.String():
-		*o = InConditionOperator
-	default:
+	operator, ok := operators[operatorName]
+	if !ok {
 		return fmt.Errorf("unknown operator: %s", operatorName)
 	}
+	*o = operator
 
 	return nil
 }
@@ -76,6 +105,12 @@ type Operator interface {
 	Test(left interface{}, right interface{}) (bool, error)
 }
 
+// ValuesValidator can be implemented by an Operator, to verify the values
+// of a traffic rule condition before the condition is used
+type ValuesValidator interface {
+	ValidateValues(values []string) error
+}
+
 type inConditionOperator struct{}
 
 func (o *inConditionOperator) String() string {
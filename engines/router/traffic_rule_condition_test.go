@@ -29,6 +29,14 @@ func TestRuleConditionOperator_UnmarshalJSON(t *testing.T) {
 			operator:   router.InConditionOperator,
 			serialized: `"in"`,
 		},
+		"success | not_in": {
+			operator:   router.NotInConditionOperator,
+			serialized: `"not_in"`,
+		},
+		"success | semver_gte": {
+			operator:   router.VersionGreaterThanOrEqualConditionOperator,
+			serialized: `"semver_gte"`,
+		},
 		"failure | unknown operator": {
 			serialized:    `"some-operator"`,
 			expectedError: "unknown operator: some-operator",
@@ -195,6 +203,26 @@ func TestTrafficRuleCondition_TestRequest(t *testing.T) {
 			payload:  `{"parent_field": {"nested": "foo"}}`,
 			expected: true,
 		},
+		"success | header exists": {
+			condition: &router.TrafficRuleCondition{
+				FieldSource: request.HeaderFieldSource,
+				Field:       "Content-Type",
+				Operator:    router.ExistsConditionOperator,
+			},
+			header: http.Header{
+				"Content-Type": []string{"application/json"},
+			},
+			expected: true,
+		},
+		"success | payload field does not exist": {
+			condition: &router.TrafficRuleCondition{
+				FieldSource: request.PayloadFieldSource,
+				Field:       "parent_field.bar",
+				Operator:    router.ExistsConditionOperator,
+			},
+			payload:  `{"parent_field": {"nested": "foo"}}`,
+			expected: false,
+		},
 		"failure | header not found": {
 			condition: &router.TrafficRuleCondition{
 				FieldSource: request.HeaderFieldSource,
@@ -304,6 +332,33 @@ func TestTrafficRuleCondition_TestUPIRequest(t *testing.T) {
 			},
 			expectedError: "Field Session-ID not found in the request header",
 		},
+		"success | prediction context semver": {
+			condition: &router.TrafficRuleCondition{
+				FieldSource: request.PredictionContextSource,
+				Field:       "app_version",
+				Operator:    router.VersionGreaterThanOrEqualConditionOperator,
+				Values:      []string{"4.2"},
+			},
+			payload: &upiv1.PredictValuesRequest{
+				PredictionContext: []*upiv1.Variable{
+					{
+						Name:        "app_version",
+						Type:        upiv1.Type_TYPE_STRING,
+						StringValue: "4.10.1",
+					},
+				},
+			},
+			expected: true,
+		},
+		"success | variable does not exist": {
+			condition: &router.TrafficRuleCondition{
+				FieldSource: request.PredictionContextSource,
+				Field:       "missing-variable",
+				Operator:    router.ExistsConditionOperator,
+			},
+			payload:  &upiv1.PredictValuesRequest{},
+			expected: false,
+		},
 		"failure | variable not found": {
 			condition: &router.TrafficRuleCondition{
 				FieldSource: request.PredictionContextSource,
@@ -0,0 +1,354 @@
+package router
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type notInConditionOperator struct {
+	inConditionOperator
+}
+
+func (o *notInConditionOperator) String() string {
+	return "not_in"
+}
+
+func (o *notInConditionOperator) Test(left interface{}, right interface{}) (bool, error) {
+	in, err := o.inConditionOperator.Test(left, right)
+	if err != nil {
+		return false, err
+	}
+	return !in, nil
+}
+
+// matchesConditionOperator tests that the value matches at least one of the regular expressions
+type matchesConditionOperator struct {
+	// compiled regular expressions, cached by their pattern
+	patterns sync.Map
+}
+
+func (o *matchesConditionOperator) String() string {
+	return "matches"
+}
+
+func (o *matchesConditionOperator) Test(left interface{}, right interface{}) (bool, error) {
+	value, patterns, err := toStringArgs(left, right)
+	if err != nil {
+		return false, err
+	}
+
+	for _, pattern := range patterns {
+		re, err := o.compile(pattern)
+		if err != nil {
+			return false, err
+		}
+		if re.MatchString(value) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (o *matchesConditionOperator) ValidateValues(values []string) error {
+	for _, pattern := range values {
+		if _, err := o.compile(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *matchesConditionOperator) compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := o.patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q", pattern)
+	}
+	o.patterns.Store(pattern, re)
+	return re, nil
+}
+
+// prefixConditionOperator tests that the value starts with at least one of the prefixes
+type prefixConditionOperator struct{}
+
+func (o *prefixConditionOperator) String() string {
+	return "prefix"
+}
+
+func (o *prefixConditionOperator) Test(left interface{}, right interface{}) (bool, error) {
+	value, prefixes, err := toStringArgs(left, right)
+	if err != nil {
+		return false, err
+	}
+
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// existsConditionOperator tests that the field is present in the request. The value
+// of a missing field is expected to be passed as nil.
+type existsConditionOperator struct{}
+
+func (o *existsConditionOperator) String() string {
+	return "exists"
+}
+
+func (o *existsConditionOperator) Test(left interface{}, _ interface{}) (bool, error) {
+	return left != nil, nil
+}
+
+func (o *existsConditionOperator) ValidateValues(values []string) error {
+	if len(values) != 0 {
+		return fmt.Errorf("no values are expected for the %s operator", o.String())
+	}
+	return nil
+}
+
+// comparisonConditionOperator compares the value against a single value of the condition,
+// with both values being parsed into the type of the operator (number or semantic version)
+type comparisonConditionOperator struct {
+	name string
+	// compare parses both values and returns -1, 0 or +1 if a is less than, equal to or greater than b
+	compare func(a, b string) (int, error)
+	// accept reports whether the result of the comparison satisfies the operator
+	accept func(cmp int) bool
+}
+
+func (o *comparisonConditionOperator) String() string {
+	return o.name
+}
+
+func (o *comparisonConditionOperator) Test(left interface{}, right interface{}) (bool, error) {
+	value, values, err := toStringArgs(left, right)
+	if err != nil {
+		return false, err
+	}
+	if len(values) != 1 {
+		return false, fmt.Errorf("exactly one value is expected for the %s operator", o.name)
+	}
+
+	cmp, err := o.compare(value, values[0])
+	if err != nil {
+		return false, err
+	}
+	return o.accept(cmp), nil
+}
+
+func (o *comparisonConditionOperator) ValidateValues(values []string) error {
+	if len(values) != 1 {
+		return fmt.Errorf("exactly one value is expected for the %s operator", o.name)
+	}
+	_, err := o.compare(values[0], values[0])
+	return err
+}
+
+// CompareNumbers parses both values as numbers and compares them
+func CompareNumbers(a, b string) (int, error) {
+	x, err := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a valid number", a)
+	}
+	y, err := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a valid number", b)
+	}
+
+	switch {
+	case x < y:
+		return -1, nil
+	case x > y:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// CompareVersions parses both values as semantic versions and compares them,
+// following the precedence rules of https://semver.org
+func CompareVersions(a, b string) (int, error) {
+	x, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	return x.compare(y), nil
+}
+
+type version struct {
+	core       [3]uint64
+	prerelease []string
+}
+
+// parseVersion parses a semantic version, with an optional "v" prefix. Minor and patch
+// components may be omitted, in which case they default to 0 (i.e. "4.2" is "4.2.0").
+func parseVersion(s string) (*version, error) {
+	invalid := fmt.Errorf("%q is not a valid semantic version", s)
+
+	str := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	// Build metadata is ignored when determining precedence
+	if idx := strings.Index(str, "+"); idx >= 0 {
+		str = str[:idx]
+	}
+
+	v := &version{}
+	if idx := strings.Index(str, "-"); idx >= 0 {
+		v.prerelease = strings.Split(str[idx+1:], ".")
+		for _, id := range v.prerelease {
+			if id == "" {
+				return nil, invalid
+			}
+		}
+		str = str[:idx]
+	}
+
+	parts := strings.Split(str, ".")
+	if len(parts) > 3 {
+		return nil, invalid
+	}
+	for i, part := range parts {
+		num, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, invalid
+		}
+		v.core[i] = num
+	}
+	return v, nil
+}
+
+func (v *version) compare(other *version) int {
+	for i := range v.core {
+		if v.core[i] != other.core[i] {
+			if v.core[i] < other.core[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	// A version without pre-release identifiers has higher precedence
+	switch {
+	case len(v.prerelease) == 0 && len(other.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(other.prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		if cmp := comparePrereleaseIdentifiers(v.prerelease[i], other.prerelease[i]); cmp != 0 {
+			return cmp
+		}
+	}
+
+	switch {
+	case len(v.prerelease) < len(other.prerelease):
+		return -1
+	case len(v.prerelease) > len(other.prerelease):
+		return 1
+	default:
+		return 0
+	}
+}
+
+func comparePrereleaseIdentifiers(a, b string) int {
+	x, errX := strconv.ParseUint(a, 10, 64)
+	y, errY := strconv.ParseUint(b, 10, 64)
+
+	switch {
+	case errX == nil && errY == nil:
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+		return 0
+	case errX == nil:
+		// Numeric identifiers have lower precedence than alphanumeric ones
+		return -1
+	case errY == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func toStringArgs(left interface{}, right interface{}) (string, []string, error) {
+	value, ok := left.(string)
+	if !ok {
+		return "", nil, fmt.Errorf("invalid type of left argument: string is expected")
+	}
+	values, ok := right.([]string)
+	if !ok {
+		return "", nil, fmt.Errorf("invalid type of right argument: slice of strings is expected")
+	}
+	return value, values, nil
+}
+
+func newComparisonOperator(
+	name string,
+	compare func(a, b string) (int, error),
+	accept func(cmp int) bool,
+) RuleConditionOperator {
+	return RuleConditionOperator{&comparisonConditionOperator{name: name, compare: compare, accept: accept}}
+}
+
+var (
+	NotInConditionOperator   = RuleConditionOperator{&notInConditionOperator{}}
+	MatchesConditionOperator = RuleConditionOperator{&matchesConditionOperator{}}
+	PrefixConditionOperator  = RuleConditionOperator{&prefixConditionOperator{}}
+	ExistsConditionOperator  = RuleConditionOperator{&existsConditionOperator{}}
+
+	GreaterThanConditionOperator = newComparisonOperator("gt", CompareNumbers,
+		func(cmp int) bool { return cmp > 0 })
+	GreaterThanOrEqualConditionOperator = newComparisonOperator("gte", CompareNumbers,
+		func(cmp int) bool { return cmp >= 0 })
+	LessThanConditionOperator = newComparisonOperator("lt", CompareNumbers,
+		func(cmp int) bool { return cmp < 0 })
+	LessThanOrEqualConditionOperator = newComparisonOperator("lte", CompareNumbers,
+		func(cmp int) bool { return cmp <= 0 })
+
+	VersionGreaterThanConditionOperator = newComparisonOperator("semver_gt", CompareVersions,
+		func(cmp int) bool { return cmp > 0 })
+	VersionGreaterThanOrEqualConditionOperator = newComparisonOperator("semver_gte", CompareVersions,
+		func(cmp int) bool { return cmp >= 0 })
+	VersionLessThanConditionOperator = newComparisonOperator("semver_lt", CompareVersions,
+		func(cmp int) bool { return cmp < 0 })
+	VersionLessThanOrEqualConditionOperator = newComparisonOperator("semver_lte", CompareVersions,
+		func(cmp int) bool { return cmp <= 0 })
+)
+
+// operators contains all the supported operators, by their name
+var operators = func() map[string]RuleConditionOperator {
+	all := map[string]RuleConditionOperator{}
+	for _, operator := range []RuleConditionOperator{
+		InConditionOperator,
+		NotInConditionOperator,
+		MatchesConditionOperator,
+		PrefixConditionOperator,
+		ExistsConditionOperator,
+		GreaterThanConditionOperator,
+		GreaterThanOrEqualConditionOperator,
+		LessThanConditionOperator,
+		LessThanOrEqualConditionOperator,
+		VersionGreaterThanConditionOperator,
+		VersionGreaterThanOrEqualConditionOperator,
+		VersionLessThanConditionOperator,
+		VersionLessThanOrEqualConditionOperator,
+	} {
+		all[operator.String()] = operator
+	}
+	return all
+}()
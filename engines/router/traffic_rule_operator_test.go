@@ -0,0 +1,236 @@
+package router_test
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/go-playground/validator/v10/non-standard/validators"
+	"github.com/stretchr/testify/require"
+
+	"github.com/caraml-dev/turing/engines/experiment/pkg/request"
+	"github.com/caraml-dev/turing/engines/router"
+)
+
+type operatorTestCase struct {
+	operator      router.RuleConditionOperator
+	left          interface{}
+	right         interface{}
+	expected      bool
+	expectedError string
+}
+
+func TestConditionOperators_Test(t *testing.T) {
+	suite := map[string]operatorTestCase{
+		"not_in | ok": {
+			operator: router.NotInConditionOperator,
+			left:     "ID",
+			right:    []string{"SG", "TH"},
+			expected: true,
+		},
+		"not_in | nok": {
+			operator: router.NotInConditionOperator,
+			left:     "SG",
+			right:    []string{"SG", "TH"},
+			expected: false,
+		},
+		"matches | ok": {
+			operator: router.MatchesConditionOperator,
+			left:     "test-user-1",
+			right:    []string{"^admin-", "^test-"},
+			expected: true,
+		},
+		"matches | nok": {
+			operator: router.MatchesConditionOperator,
+			left:     "user-1",
+			right:    []string{"^test-"},
+			expected: false,
+		},
+		"matches | invalid pattern": {
+			operator:      router.MatchesConditionOperator,
+			left:          "user-1",
+			right:         []string{"(test"},
+			expectedError: `invalid regular expression "(test"`,
+		},
+		"prefix | ok": {
+			operator: router.PrefixConditionOperator,
+			left:     "application/json",
+			right:    []string{"text/", "application/"},
+			expected: true,
+		},
+		"prefix | nok": {
+			operator: router.PrefixConditionOperator,
+			left:     "application/json",
+			right:    []string{"text/"},
+			expected: false,
+		},
+		"prefix | incompatible type": {
+			operator:      router.PrefixConditionOperator,
+			left:          "application/json",
+			right:         "text/",
+			expectedError: "invalid type of right argument: slice of strings is expected",
+		},
+		"exists | ok": {
+			operator: router.ExistsConditionOperator,
+			left:     "",
+			expected: true,
+		},
+		"exists | nok": {
+			operator: router.ExistsConditionOperator,
+			left:     nil,
+			expected: false,
+		},
+		"gt | ok": {
+			operator: router.GreaterThanConditionOperator,
+			left:     "10",
+			right:    []string{"9.5"},
+			expected: true,
+		},
+		"gte | ok | equal": {
+			operator: router.GreaterThanOrEqualConditionOperator,
+			left:     "4.2",
+			right:    []string{"4.20"},
+			expected: true,
+		},
+		"lt | nok": {
+			operator: router.LessThanConditionOperator,
+			left:     "4.2",
+			right:    []string{"4.2"},
+			expected: false,
+		},
+		"lte | not a number": {
+			operator:      router.LessThanOrEqualConditionOperator,
+			left:          "abc",
+			right:         []string{"4.2"},
+			expectedError: `"abc" is not a valid number`,
+		},
+		"gt | too many values": {
+			operator:      router.GreaterThanConditionOperator,
+			left:          "10",
+			right:         []string{"1", "2"},
+			expectedError: "exactly one value is expected for the gt operator",
+		},
+		"semver_gt | ok": {
+			operator: router.VersionGreaterThanConditionOperator,
+			left:     "4.10.0",
+			right:    []string{"4.2"},
+			expected: true,
+		},
+		"semver_gte | prerelease": {
+			operator: router.VersionGreaterThanOrEqualConditionOperator,
+			left:     "v4.2.0-rc.1",
+			right:    []string{"4.2.0"},
+			expected: false,
+		},
+		"semver_lt | ok": {
+			operator: router.VersionLessThanConditionOperator,
+			left:     "1.0.0-alpha",
+			right:    []string{"1.0.0-alpha.1"},
+			expected: true,
+		},
+		"semver_lte | invalid version": {
+			operator:      router.VersionLessThanOrEqualConditionOperator,
+			left:          "4.2.x",
+			right:         []string{"4.2.0"},
+			expectedError: `"4.2.x" is not a valid semantic version`,
+		},
+	}
+
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			actual, err := tt.operator.Test(tt.left, tt.right)
+
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, actual)
+			} else {
+				require.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	// Ordering example from https://semver.org/#spec-item-11
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1+build.5", "1.2", "v1.10.0", "2",
+	}
+
+	for i := 0; i < len(ordered)-1; i++ {
+		cmp, err := router.CompareVersions(ordered[i], ordered[i+1])
+		require.NoError(t, err)
+		require.Equal(t, -1, cmp, "expected %s < %s", ordered[i], ordered[i+1])
+
+		cmp, err = router.CompareVersions(ordered[i+1], ordered[i])
+		require.NoError(t, err)
+		require.Equal(t, 1, cmp, "expected %s > %s", ordered[i+1], ordered[i])
+	}
+
+	cmp, err := router.CompareVersions("1.2.0+build.1", "v1.2")
+	require.NoError(t, err)
+	require.Equal(t, 0, cmp)
+}
+
+func TestValidateTrafficRuleCondition(t *testing.T) {
+	validate := validator.New()
+	require.NoError(t, validate.RegisterValidation("notBlank", validators.NotBlank))
+	validate.RegisterStructValidation(router.ValidateTrafficRuleCondition, router.TrafficRuleCondition{})
+
+	suite := map[string]struct {
+		operator      router.RuleConditionOperator
+		values        []string
+		expectedError string
+	}{
+		"success | in": {
+			operator: router.InConditionOperator,
+			values:   []string{"a", "b"},
+		},
+		"success | exists": {
+			operator: router.ExistsConditionOperator,
+		},
+		"success | semver_lt": {
+			operator: router.VersionLessThanConditionOperator,
+			values:   []string{"v4.2"},
+		},
+		"failure | missing values": {
+			operator: router.NotInConditionOperator,
+			values:   []string{},
+			expectedError: "Key: 'TrafficRuleCondition.Values' " +
+				"Error:Field validation for 'Values' failed on the 'notBlank' tag",
+		},
+		"failure | exists with values": {
+			operator: router.ExistsConditionOperator,
+			values:   []string{"a"},
+			expectedError: "Key: 'TrafficRuleCondition.Values' " +
+				"Error:Field validation for 'Values' failed on the 'no values are expected for the exists operator' tag",
+		},
+		"failure | invalid number": {
+			operator: router.GreaterThanConditionOperator,
+			values:   []string{"four"},
+			expectedError: "Key: 'TrafficRuleCondition.Values' " +
+				"Error:Field validation for 'Values' failed on the '\"four\" is not a valid number' tag",
+		},
+		"failure | invalid regex": {
+			operator: router.MatchesConditionOperator,
+			values:   []string{"[a-"},
+			expectedError: "Key: 'TrafficRuleCondition.Values' " +
+				"Error:Field validation for 'Values' failed on the 'invalid regular expression \"[a-\"' tag",
+		},
+	}
+
+	for name, tt := range suite {
+		t.Run(name, func(t *testing.T) {
+			err := validate.Struct(&router.TrafficRuleCondition{
+				FieldSource: request.HeaderFieldSource,
+				Field:       "X-Field",
+				Operator:    tt.operator,
+				Values:      tt.values,
+			})
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}